@@ -1,8 +1,10 @@
 package api
 
 import (
+	"PORTal/backend"
 	"PORTal/types"
 	"context"
+	"io"
 	"log/slog"
 	"net/http"
 	"time"
@@ -11,51 +13,144 @@ import (
 const JWTCookieName = "identity"
 
 type Backend interface {
-	AddMember(m types.Member) (types.Member, error)
-	GetMember(identifier string) (types.Member, error)
-	GetAllMembers() ([]types.Member, error)
-	GetSubordinates(memberID string) ([]types.Member, error)
-	UpdateMember(m types.Member) (types.Member, error)
-	DeleteMember(id string) error
-
-	AddQualification(q types.Qualification) (types.Qualification, error)
-	GetQualification(id string) (types.Qualification, error)
-	GetAllQualifications() ([]types.Qualification, error)
-	UpdateQualification(q types.Qualification, forceExpirationUpdate bool) (types.Qualification, error)
-	DeleteQualification(id string) error
-
-	AssignMemberQualification(memberID, qualID string) error
-	GetMemberQualification(memberID string, qualificationID string) (types.Qualification, error)
-	GetMemberQualifications(memberID string) ([]types.Qualification, error)
-	RemoveMemberQualification(memberID, qualificationID string) error
-
-	AddRequirement(r types.Requirement) (types.Requirement, error)
-	GetRequirement(id string) (types.Requirement, error)
-	GetAllRequirements() ([]types.Requirement, error)
-	UpdateRequirement(r types.Requirement) (types.Requirement, error)
-	DeleteRequirement(id string) error
-
-	AddReference(r types.Reference) (types.Reference, error)
-	GetReference(id string) (types.Reference, error)
-	GetReferences() ([]types.Reference, error)
-	UpdateReference(reference types.Reference, overrideNoVolume bool) (types.Reference, error)
-	DeleteReference(id string) error
-
-	Login(username, password string) (types.Member, error)
+	AddMember(ctx context.Context, m types.Member) (types.Member, error)
+	GetMember(ctx context.Context, identifier string) (types.Member, error)
+	ListMembers(ctx context.Context, opts types.MemberListOptions) (types.Page[types.Member], error)
+	GetSubordinates(ctx context.Context, memberID string) ([]types.Member, error)
+	ImportMembers(ctx context.Context, r io.Reader, dryRun bool) (types.MemberImportReport, error)
+
+	AddImportProfile(ctx context.Context, profile types.ImportProfile) (types.ImportProfile, error)
+	GetImportProfile(ctx context.Context, id string) (types.ImportProfile, error)
+	GetImportProfiles(ctx context.Context) ([]types.ImportProfile, error)
+	DeleteImportProfile(ctx context.Context, id string) error
+	StageCompletionImport(ctx context.Context, uploaderID, profileID, fileName string, r io.Reader) (types.ImportBatch, error)
+	GetImportBatch(ctx context.Context, id string) (types.ImportBatch, error)
+	UpdateStagedRow(ctx context.Context, batchID, rowID string, update types.StagedRowUpdate) (types.StagedRow, error)
+	CommitImportBatch(ctx context.Context, actorID, batchID string) (types.ImportBatch, error)
+	DiscardImportBatch(ctx context.Context, id string) error
+	UpdateMember(ctx context.Context, m types.Member) (types.Member, error)
+	PatchMember(ctx context.Context, id string, version int, patch []byte) (types.Member, error)
+	ArchiveMember(ctx context.Context, actorID, memberID string, archive types.MemberArchive) (types.Member, error)
+	RestoreMember(ctx context.Context, actorID, memberID string) (types.Member, error)
+	GetArchivedMembers(ctx context.Context) ([]types.Member, error)
+	PurgeMember(ctx context.Context, actorID, identifier string) error
+	PurgeArchivedMembers(ctx context.Context, actorID string) ([]string, error)
+	SetMemberUnit(ctx context.Context, memberID, unitID string) (types.Member, error)
+	ExportMemberTransfer(ctx context.Context, actorID, memberID string) (types.SignedTransferPackage, error)
+	ImportMemberTransfer(ctx context.Context, actorID string, signed types.SignedTransferPackage) (types.TransferImportReport, error)
+
+	AddQualification(ctx context.Context, q types.Qualification) (types.Qualification, error)
+	GetQualification(ctx context.Context, id string) (types.Qualification, error)
+	ListQualifications(ctx context.Context, opts types.ListOptions) (types.Page[types.Qualification], error)
+	UpdateQualification(ctx context.Context, q types.Qualification, forceExpirationUpdate bool) (types.Qualification, error)
+	PatchQualification(ctx context.Context, id string, version int, patch []byte) (types.Qualification, error)
+	DeleteQualification(ctx context.Context, id string, version int) error
+	GetPrerequisites(ctx context.Context, id string) ([]types.Qualification, error)
+
+	AssignMemberQualification(ctx context.Context, actorID, memberID, qualID string) error
+	GetMemberQualification(ctx context.Context, memberID string, qualificationID string) (types.Qualification, error)
+	GetMemberQualifications(ctx context.Context, memberID string) ([]types.Qualification, error)
+	RemoveMemberQualification(ctx context.Context, actorID, memberID, qualificationID string) error
+	GetQualificationHistory(ctx context.Context, memberID, qualificationID string) ([]types.QualificationEvent, error)
+	BulkAssignQualifications(ctx context.Context, actorID string, req types.BulkQualificationRequest) ([]types.BulkItemResult, error)
+	BulkRemoveQualifications(ctx context.Context, actorID string, req types.BulkQualificationRequest) ([]types.BulkItemResult, error)
+	GetMemberQualificationStatus(ctx context.Context, memberID, qualificationID string) (types.QualificationStatus, error)
+	GetMemberQualificationStatuses(ctx context.Context, memberID string) ([]types.QualificationStatus, error)
+
+	AddRequirement(ctx context.Context, r types.Requirement) (types.Requirement, error)
+	GetRequirement(ctx context.Context, id string) (types.Requirement, error)
+	ListRequirements(ctx context.Context, opts types.ListOptions) (types.Page[types.Requirement], error)
+	UpdateRequirement(ctx context.Context, r types.Requirement) (types.Requirement, error)
+	PatchRequirement(ctx context.Context, id string, version int, patch []byte) (types.Requirement, error)
+	DeleteRequirement(ctx context.Context, id string, version int) error
+
+	AddReference(ctx context.Context, r types.Reference) (types.Reference, error)
+	GetReference(ctx context.Context, id string) (types.Reference, error)
+	GetReferences(ctx context.Context) ([]types.Reference, error)
+	UpdateReference(ctx context.Context, reference types.Reference, overrideNoVolume bool) (types.Reference, error)
+	PatchReference(ctx context.Context, id string, version int, patch []byte) (types.Reference, error)
+	DeleteReference(ctx context.Context, id string) error
+
+	AddCertifier(ctx context.Context, requirementID, memberID string) error
+	GetCertifiers(ctx context.Context, requirementID string) ([]types.Member, error)
+	RemoveCertifier(ctx context.Context, requirementID, memberID string) error
+	SubmitCompletion(ctx context.Context, submitterID string, c types.Completion) (types.Completion, error)
+	GetCompletion(ctx context.Context, id string) (types.Completion, error)
+	GetMemberCompletions(ctx context.Context, memberID string) ([]types.Completion, error)
+	GetPendingCompletions(ctx context.Context, certifierID string) ([]types.Completion, error)
+	ReviewCompletion(ctx context.Context, certifierID, completionID string, approve bool, comments string) (types.Completion, error)
+
+	GrantWaiver(ctx context.Context, approverID string, w types.Waiver) (types.Waiver, error)
+	GetWaiver(ctx context.Context, id string) (types.Waiver, error)
+	GetMemberWaivers(ctx context.Context, memberID string) ([]types.Waiver, error)
+	GetWaiverMemo(ctx context.Context, id string) (types.WaiverMemo, error)
+	RevokeWaiver(ctx context.Context, actorID, id, reason string) (types.Waiver, error)
+	GetAuditEntries(ctx context.Context, entityType, entityID string) ([]types.AuditEntry, error)
+
+	AddDutyPosition(ctx context.Context, d types.DutyPosition) (types.DutyPosition, error)
+	GetDutyPosition(ctx context.Context, id string) (types.DutyPosition, error)
+	GetDutyPositions(ctx context.Context) ([]types.DutyPosition, error)
+	UpdateDutyPosition(ctx context.Context, actorID string, d types.DutyPosition, apply bool) (types.DutyPositionDiff, error)
+	DeleteDutyPosition(ctx context.Context, id string, version int) error
+	AssignMemberDutyPosition(ctx context.Context, actorID, memberID, positionID string) ([]string, error)
+	GetMemberDutyPositions(ctx context.Context, memberID string) ([]types.DutyPosition, error)
+	RemoveMemberDutyPosition(ctx context.Context, actorID, memberID, positionID string, removeQualifications bool) (types.DutyPositionRemoval, error)
+
+	AddUnit(ctx context.Context, u types.Unit) (types.Unit, error)
+	GetUnit(ctx context.Context, id string) (types.Unit, error)
+	GetUnits(ctx context.Context) ([]types.Unit, error)
+	UpdateUnit(ctx context.Context, u types.Unit) (types.Unit, error)
+	DeleteUnit(ctx context.Context, id string, version int) error
+	GetUnitMembers(ctx context.Context, unitID string) ([]types.Member, error)
+	GetUnitReport(ctx context.Context, unitID string) (types.UnitReport, error)
+	AddUnitAdmin(ctx context.Context, unitID, memberID string) error
+	GetUnitAdmins(ctx context.Context, unitID string) ([]types.Member, error)
+	RemoveUnitAdmin(ctx context.Context, unitID, memberID string) error
+	IsUnitAdmin(ctx context.Context, memberID, unitID string) (bool, error)
+
+	Login(ctx context.Context, username, password string) (types.Member, error)
+	LoginWithCertificate(ctx context.Context, certificateID string) (types.Member, error)
+	BindMemberCertificate(ctx context.Context, memberID, certificateID string) (types.Member, error)
+
+	GetRolePermissions(ctx context.Context, role types.Role) ([]types.Permission, error)
+	GetRoles(ctx context.Context) ([]types.RoleDefinition, error)
+	UpdateRolePermissions(ctx context.Context, role types.Role, permissions []types.Permission) (types.RoleDefinition, error)
+
+	CreateAPIToken(ctx context.Context, memberID, name string, scope types.TokenScope) (types.APIToken, error)
+	GetAPITokens(ctx context.Context, memberID string) ([]types.APIToken, error)
+	RevokeAPIToken(ctx context.Context, memberID, tokenID string) error
+	AuthenticateAPIToken(ctx context.Context, token string) (types.APIToken, types.Member, error)
 }
 
 type Config struct {
-	Domain        string        `yaml:"domain"`
-	JWTExpiration time.Duration `yaml:"JWTExpiration"`
-	JWTSecret     string        `yaml:"JWTSecret"`
-	Port          int           `yaml:"port"`
+	Domain            string        `yaml:"domain"`
+	JWTExpiration     time.Duration `yaml:"JWTExpiration"`
+	JWTSecret         string        `yaml:"JWTSecret"`
+	Port              int           `yaml:"port"`
+	TLSCertFile       string        `yaml:"TLSCertFile"`
+	TLSKeyFile        string        `yaml:"TLSKeyFile"`
+	ClientCAFile      string        `yaml:"ClientCAFile"`
+	RequireClientCert bool          `yaml:"RequireClientCert"`
+	// MultiTenant hosts several organizations, each reached at its own subdomain of Domain.
+	MultiTenant bool `yaml:"MultiTenant"`
 }
 
 func New(logger *slog.Logger, backend Backend, dev bool, config Config) Server {
+	return newServer(logger, backend, nil, dev, config)
+}
+
+// NewMultiTenant creates a server hosting every tenant in the directory. Each request is served by the backend of the
+// tenant it resolves to, see resolveTenant.
+func NewMultiTenant(logger *slog.Logger, tenants Tenants, dev bool, config Config) Server {
+	return newServer(logger, tenants.ForTenant(types.DefaultTenantID), tenants, dev, config)
+}
+
+func newServer(logger *slog.Logger, backend Backend, tenants Tenants, dev bool, config Config) Server {
 	logger.LogAttrs(context.Background(), slog.LevelInfo, "Creating new api server")
 	s := Server{
 		logger:  logger,
 		backend: backend,
+		tenants: tenants,
 		mux:     http.NewServeMux(),
 		dev:     dev,
 		config:  config,
@@ -63,37 +158,138 @@ func New(logger *slog.Logger, backend Backend, dev bool, config Config) Server {
 	logger.LogAttrs(context.Background(), slog.LevelInfo, "Registering routes...")
 
 	// Member CRUD routes
-	s.mux.Handle("POST /api/member", http.HandlerFunc(s.addMember))
-	s.mux.Handle("GET /api/member/{id}", http.HandlerFunc(s.getMember))
-	s.mux.Handle("GET /api/members", http.HandlerFunc(s.getAllMembers))
-	s.mux.Handle("PUT /api/member/{id}", http.HandlerFunc(s.updateMember))
-	s.mux.Handle("DELETE /api/member/{id}", http.HandlerFunc(s.deleteMember))
+	s.mux.Handle("POST /api/member", s.requirePermission(types.PermManageMembers, s.addMember))
+	s.mux.Handle("GET /api/member/{id}", s.authenticated(s.getMember))
+	s.mux.Handle("GET /api/members", s.authenticated(s.listMembers))
+	s.mux.Handle("PUT /api/member/{id}", s.requireSelfOrPermission(types.PermManageMembers, s.updateMember))
+	s.mux.Handle("PATCH /api/member/{id}", s.requireSelfOrPermission(types.PermManageMembers, s.patchMember))
+	// Binding a certificate lets it log in as the member, so like the admin role only admins may
+	s.mux.Handle("PUT /api/member/{id}/certificate", s.requirePermission(types.PermManageRoles, s.bindMemberCertificate))
+	s.mux.Handle("DELETE /api/member/{id}/certificate", s.requirePermission(types.PermManageRoles, s.unbindMemberCertificate))
+
+	// Archival routes, members who leave are archived rather than deleted and only admins can purge them for good
+	s.mux.Handle("POST /api/member/{id}/archive", s.requirePermission(types.PermDeleteMembers, s.archiveMember))
+	s.mux.Handle("POST /api/member/{id}/restore", s.requirePermission(types.PermDeleteMembers, s.restoreMember))
+	s.mux.Handle("GET /api/members/archived", s.requirePermission(types.PermDeleteMembers, s.getArchivedMembers))
+	s.mux.Handle("DELETE /api/admin/member/{id}", s.requirePermission(types.PermManageRoles, s.purgeMember))
+	s.mux.Handle("POST /api/admin/members/purge", s.requirePermission(types.PermManageRoles, s.purgeArchivedMembers))
+
+	// Transfer routes, packages carry a member's records to the instance they're moving to
+	s.mux.Handle("GET /api/member/{id}/transfer", s.requirePermission(types.PermManageMembers, s.exportMemberTransfer))
+	s.mux.Handle("POST /api/admin/import/transfer", s.requirePermission(types.PermManageRoles, s.importMemberTransfer))
+
+	// Import routes, rosters can create admins so importing needs the same permission as assigning the admin role
+	s.mux.Handle("POST /api/admin/import/members", s.requirePermission(types.PermManageRoles, s.importMembers))
+	s.mux.Handle("POST /api/admin/import/profile", s.requirePermission(types.PermManageRoles, s.addImportProfile))
+	s.mux.Handle("GET /api/admin/import/profiles", s.requirePermission(types.PermManageRoles, s.getImportProfiles))
+	s.mux.Handle("GET /api/admin/import/profile/{id}", s.requirePermission(types.PermManageRoles, s.getImportProfile))
+	s.mux.Handle("DELETE /api/admin/import/profile/{id}", s.requirePermission(types.PermManageRoles, s.deleteImportProfile))
+	s.mux.Handle("POST /api/admin/import/completions", s.requirePermission(types.PermManageRoles, s.stageCompletionImport))
+	s.mux.Handle("GET /api/admin/import/completions/{id}", s.requirePermission(types.PermManageRoles, s.getImportBatch))
+	s.mux.Handle("PUT /api/admin/import/completions/{id}/row/{rowID}", s.requirePermission(types.PermManageRoles, s.updateStagedRow))
+	s.mux.Handle("POST /api/admin/import/completions/{id}/commit", s.requirePermission(types.PermManageRoles, s.commitImportBatch))
+	s.mux.Handle("DELETE /api/admin/import/completions/{id}", s.requirePermission(types.PermManageRoles, s.discardImportBatch))
 
 	// Qualification CRUD routes
-	s.mux.Handle("POST /api/qualification", http.HandlerFunc(s.addQualification))
-	s.mux.Handle("GET /api/qualification/{id}", http.HandlerFunc(s.getQualification))
-	s.mux.Handle("GET /api/qualifications", http.HandlerFunc(s.getAllQualifications))
-	s.mux.Handle("PUT /api/qualification/{id}", http.HandlerFunc(s.updateQualification))
-	s.mux.Handle("DELETE /api/qualification/{id}", http.HandlerFunc(s.deleteQualification))
+	s.mux.Handle("POST /api/qualification", s.requirePermission(types.PermManageQualifications, s.addQualification))
+	s.mux.Handle("GET /api/qualification/{id}", s.authenticated(s.getQualification))
+	s.mux.Handle("GET /api/qualifications", s.authenticated(s.listQualifications))
+	s.mux.Handle("GET /api/qualification/{id}/prerequisites", s.authenticated(s.getPrerequisites))
+	s.mux.Handle("PUT /api/qualification/{id}", s.requirePermission(types.PermManageQualifications, s.updateQualification))
+	s.mux.Handle("PATCH /api/qualification/{id}", s.requirePermission(types.PermManageQualifications, s.patchQualification))
+	s.mux.Handle("DELETE /api/qualification/{id}", s.requirePermission(types.PermManageQualifications, s.deleteQualification))
 
 	// Requirement CRUD routes
-	s.mux.Handle("POST /api/requirement", http.HandlerFunc(s.addRequirement))
-	s.mux.Handle("GET /api/requirement/{id}", http.HandlerFunc(s.getRequirement))
-	s.mux.Handle("GET /api/requirements", http.HandlerFunc(s.getAllRequirements))
-	s.mux.Handle("PUT /api/requirement/{id}", http.HandlerFunc(s.updateRequirement))
-	s.mux.Handle("DELETE /api/requirement/{id}", http.HandlerFunc(s.deleteRequirement))
+	s.mux.Handle("POST /api/requirement", s.requirePermission(types.PermManageQualifications, s.addRequirement))
+	s.mux.Handle("GET /api/requirement/{id}", s.authenticated(s.getRequirement))
+	s.mux.Handle("GET /api/requirements", s.authenticated(s.listRequirements))
+	s.mux.Handle("PUT /api/requirement/{id}", s.requirePermission(types.PermManageQualifications, s.updateRequirement))
+	s.mux.Handle("PATCH /api/requirement/{id}", s.requirePermission(types.PermManageQualifications, s.patchRequirement))
+	s.mux.Handle("DELETE /api/requirement/{id}", s.requirePermission(types.PermManageQualifications, s.deleteRequirement))
+
+	// Reference routes
+	s.mux.Handle("PATCH /api/reference/{id}", s.requirePermission(types.PermManageQualifications, s.patchReference))
 
 	// Member-Qualification routes
-	s.mux.Handle("POST /api/member/{id}/qualification/{qualID}", http.HandlerFunc(s.assignMemberQualification))
-	s.mux.Handle("GET /api/member/{id}/qualifications", http.HandlerFunc(s.getMemberQualifications))
-	s.mux.Handle("GET /api/member/{id}/qualification/{qualID}", http.HandlerFunc(s.getMemberQualification))
-	s.mux.Handle("DELETE /api/member/{id}/qualification/{qualID}", http.HandlerFunc(s.removeMemberQualification))
+	s.mux.Handle("POST /api/member/{id}/qualification/{qualID}", s.requirePermission(types.PermAssignQualifications, s.assignMemberQualification))
+	s.mux.Handle("GET /api/member/{id}/qualifications", s.authenticated(s.getMemberQualifications))
+	s.mux.Handle("GET /api/member/{id}/qualification/{qualID}", s.authenticated(s.getMemberQualification))
+	s.mux.Handle("GET /api/member/{id}/qualifications/status", s.authenticated(s.getMemberQualificationStatuses))
+	s.mux.Handle("GET /api/member/{id}/qualification/{qualID}/status", s.authenticated(s.getMemberQualificationStatus))
+	s.mux.Handle("GET /api/member/{id}/qualification/{qualID}/history", s.authenticated(s.getQualificationHistory))
+	s.mux.Handle("DELETE /api/member/{id}/qualification/{qualID}", s.requirePermission(types.PermAssignQualifications, s.removeMemberQualification))
+	s.mux.Handle("POST /api/members/qualifications/assign", s.requirePermission(types.PermAssignQualifications, s.bulkMemberQualifications(Backend.BulkAssignQualifications)))
+	s.mux.Handle("POST /api/members/qualifications/remove", s.requirePermission(types.PermAssignQualifications, s.bulkMemberQualifications(Backend.BulkRemoveQualifications)))
+
+	// Completion sign-off routes
+	s.mux.Handle("POST /api/member/{id}/requirement/{reqID}/completion", s.requireSelfOrPermission(types.PermSignOffCompletions, s.submitCompletion))
+	s.mux.Handle("GET /api/member/{id}/completions", s.authenticated(s.getMemberCompletions))
+	s.mux.Handle("GET /api/completion/{id}", s.authenticated(s.getCompletion))
+	s.mux.Handle("GET /api/completions/pending", s.authenticated(s.getPendingCompletions))
+	s.mux.Handle("POST /api/completion/{id}/approve", s.authenticated(s.reviewCompletion(true)))
+	s.mux.Handle("POST /api/completion/{id}/reject", s.authenticated(s.reviewCompletion(false)))
+	s.mux.Handle("GET /api/requirement/{id}/certifiers", s.authenticated(s.getCertifiers))
+	s.mux.Handle("PUT /api/requirement/{id}/certifier/{memberID}", s.requirePermission(types.PermManageQualifications, s.addCertifier))
+	s.mux.Handle("DELETE /api/requirement/{id}/certifier/{memberID}", s.requirePermission(types.PermManageQualifications, s.removeCertifier))
+
+	// Duty position routes
+	s.mux.Handle("POST /api/position", s.requirePermission(types.PermManageQualifications, s.addDutyPosition))
+	s.mux.Handle("GET /api/position/{id}", s.authenticated(s.getDutyPosition))
+	s.mux.Handle("GET /api/positions", s.authenticated(s.getDutyPositions))
+	s.mux.Handle("PUT /api/position/{id}", s.requirePermission(types.PermManageQualifications, s.updateDutyPosition))
+	s.mux.Handle("DELETE /api/position/{id}", s.requirePermission(types.PermManageQualifications, s.deleteDutyPosition))
+	s.mux.Handle("POST /api/member/{id}/position/{positionID}", s.requirePermission(types.PermAssignQualifications, s.assignMemberDutyPosition))
+	s.mux.Handle("GET /api/member/{id}/positions", s.authenticated(s.getMemberDutyPositions))
+	s.mux.Handle("DELETE /api/member/{id}/position/{positionID}", s.requirePermission(types.PermAssignQualifications, s.removeMemberDutyPosition))
+
+	// Org unit routes, unit admins look after the units under them without needing the role wide permission
+	s.mux.Handle("POST /api/unit", s.requirePermission(types.PermManageMembers, s.addUnit))
+	s.mux.Handle("GET /api/unit/{id}", s.authenticated(s.getUnit))
+	s.mux.Handle("GET /api/units", s.authenticated(s.getUnits))
+	s.mux.Handle("PUT /api/unit/{id}", s.requirePermission(types.PermManageMembers, s.updateUnit))
+	s.mux.Handle("DELETE /api/unit/{id}", s.requirePermission(types.PermManageMembers, s.deleteUnit))
+	s.mux.Handle("PUT /api/unit/{id}/qualifications", s.requirePermissionOrUnitAdmin(types.PermManageQualifications, pathUnit, s.setUnitQualifications))
+	s.mux.Handle("GET /api/unit/{id}/members", s.authenticated(s.getUnitMembers))
+	s.mux.Handle("GET /api/unit/{id}/report", s.requirePermissionOrUnitAdmin(types.PermViewReports, pathUnit, s.getUnitReport))
+	s.mux.Handle("GET /api/unit/{id}/admins", s.authenticated(s.getUnitAdmins))
+	s.mux.Handle("PUT /api/unit/{id}/admin/{memberID}", s.requirePermission(types.PermManageRoles, s.addUnitAdmin))
+	s.mux.Handle("DELETE /api/unit/{id}/admin/{memberID}", s.requirePermission(types.PermManageRoles, s.removeUnitAdmin))
+	s.mux.Handle("PUT /api/member/{id}/unit/{unitID}", s.requirePermissionOrUnitAdmin(types.PermManageMembers, func(r *http.Request) string { return r.PathValue("unitID") }, s.setMemberUnit))
+	s.mux.Handle("DELETE /api/member/{id}/unit", s.requirePermissionOrUnitAdmin(types.PermManageMembers, s.memberUnit, s.setMemberUnit))
+
+	// Waiver routes
+	s.mux.Handle("POST /api/member/{id}/waiver", s.requirePermission(types.PermGrantWaivers, s.grantWaiver))
+	s.mux.Handle("GET /api/member/{id}/waivers", s.authenticated(s.getMemberWaivers))
+	s.mux.Handle("GET /api/waiver/{id}", s.authenticated(s.getWaiver))
+	s.mux.Handle("GET /api/waiver/{id}/memo", s.authenticated(s.getWaiverMemo))
+	s.mux.Handle("POST /api/waiver/{id}/revoke", s.requirePermission(types.PermGrantWaivers, s.revokeWaiver))
+	s.mux.Handle("GET /api/waiver/{id}/audit", s.requirePermission(types.PermViewReports, s.getWaiverAudit))
 
 	// Authentication routes
 	s.mux.Handle("POST /api/login", http.HandlerFunc(s.login))
+	s.mux.Handle("POST /api/login/certificate", http.HandlerFunc(s.certificateLogin))
 	s.mux.Handle("GET /api/logout", http.HandlerFunc(s.logout))
 	s.mux.Handle("GET /api/checkAdmin", http.HandlerFunc(s.checkAdmin))
 
+	// API token routes
+	s.mux.Handle("POST /api/tokens", s.authenticated(s.createAPIToken))
+	s.mux.Handle("GET /api/tokens", s.authenticated(s.getAPITokens))
+	s.mux.Handle("DELETE /api/tokens/{id}", s.authenticated(s.revokeAPIToken))
+
+	// Role routes
+	s.mux.Handle("GET /api/roles", s.authenticated(s.getRoles))
+	s.mux.Handle("PUT /api/role/{role}", s.requirePermission(types.PermManageRoles, s.updateRolePermissions))
+
+	// Documentation routes, so clients can be generated for other tools
+	s.mux.Handle("GET /api/openapi.json", http.HandlerFunc(s.getOpenAPI))
+
+	// Tenant routes, only the admins running the deployment can add organizations to it
+	if tenants != nil {
+		s.mux.Handle("GET /api/tenant", http.HandlerFunc(s.getTenant))
+		s.mux.Handle("POST /api/admin/tenant", s.requireDeploymentAdmin(s.addTenant))
+		s.mux.Handle("GET /api/admin/tenants", s.requireDeploymentAdmin(s.getTenants))
+	}
+
 	logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully registered routes")
 	if dev {
 		logger.LogAttrs(context.Background(), slog.LevelInfo, "Registering frontend from build folder")
@@ -108,16 +304,32 @@ func New(logger *slog.Logger, backend Backend, dev bool, config Config) Server {
 type Server struct {
 	logger  *slog.Logger
 	backend Backend
+	// tenants is nil for single tenant deployments
+	tenants Tenants
 	mux     *http.ServeMux
 	dev     bool
 	config  Config
 }
 
 func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r = withRequestID(w, r)
 	if s.dev {
-		s.logger.LogAttrs(context.Background(), slog.LevelInfo, "Development mode, setting CORS to http://localhost:5173")
+		s.logger.LogAttrs(r.Context(), slog.LevelInfo, "Development mode, setting CORS to http://localhost:5173")
 		w.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 	}
+	r, status := s.resolveTenant(r)
+	if status != http.StatusOK {
+		writeError(w, status, backend.ErrTenantNotFound)
+		return
+	}
+	r, status = s.authenticateBearer(r)
+	if status == http.StatusForbidden {
+		writeError(w, status, backend.ErrInsufficientPermissions)
+		return
+	} else if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
 	s.mux.ServeHTTP(w, r)
 }
@@ -1,3 +1,16 @@
 package api
 
-var CreateToken = createToken
+import (
+	"PORTal/types"
+	"sync"
+	"time"
+)
+
+// SignedIn holds the members CreateToken has issued cookies for by ID, so a mock backend can return the member a
+// cookie belongs to when the server re-reads them.
+var SignedIn sync.Map
+
+func CreateToken(member types.Member, tenantID string, permissions []types.Permission, expiration time.Duration, key []byte) (string, error) {
+	SignedIn.Store(member.ID, member)
+	return createToken(member, tenantID, permissions, expiration, key)
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+func (s Server) archiveMember(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	var req ArchiveMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid archive JSON sent from client", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	m, err := s.backendFor(r).ArchiveMember(r.Context(), caller.MemberID, r.PathValue("id"), types.MemberArchive{Reason: req.Reason})
+	if errors.Is(err, backend.ErrMemberNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrMissingArgs) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, backend.ErrMemberArchived) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(m.ToApiMember()); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing archived member to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) restoreMember(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	m, err := s.backendFor(r).RestoreMember(r.Context(), caller.MemberID, r.PathValue("id"))
+	if errors.Is(err, backend.ErrMemberNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrMemberNotArchived) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(m.ToApiMember()); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing restored member to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getArchivedMembers(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	members, err := s.backendFor(r).GetArchivedMembers(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	apiMembers := []types.ApiMember{}
+	for _, m := range members {
+		apiMembers = append(apiMembers, m.ToApiMember())
+	}
+	if err = json.NewEncoder(w).Encode(apiMembers); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing archived members to client", slog.String("error", err.Error()))
+	}
+}
+
+// purgeMember permanently deletes an archived member once their retention period is up. Members can be identified by
+// ID or username.
+func (s Server) purgeMember(w http.ResponseWriter, r *http.Request) {
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	err := s.backendFor(r).PurgeMember(r.Context(), caller.MemberID, r.PathValue("id"))
+	if errors.Is(err, backend.ErrMemberNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrMemberNotArchived) || errors.Is(err, backend.ErrRetentionPeriod) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s Server) purgeArchivedMembers(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	purged, err := s.backendFor(r).PurgeArchivedMembers(r.Context(), caller.MemberID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(PurgeMembersResponse{Purged: purged}); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing purged members to client", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,217 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/backend"
+	"PORTal/types"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestArchiveMember(t *testing.T) {
+	goodID := uuid.NewString()
+	archivedID := uuid.NewString()
+	date := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	b := newMockBackend()
+	b.archiveMemberOverride = func(actorID, memberID string, archive types.MemberArchive) (types.Member, error) {
+		if archive.Reason == "" {
+			return types.Member{}, backend.ErrMissingArgs
+		}
+		switch memberID {
+		case goodID:
+			if actorID == "" {
+				t.Errorf("Expected the caller to be passed as the actor")
+			}
+			if !archive.Date.IsZero() {
+				t.Errorf("Expected the client's archive date to be ignored, got %s", archive.Date)
+			}
+			m := types.Member{}
+			m.ID = memberID
+			archive.ArchivedBy = actorID
+			archive.Date = date
+			m.Archive = &archive
+			return m, nil
+		case archivedID:
+			return types.Member{}, backend.ErrMemberArchived
+		case "notfound":
+			return types.Member{}, backend.ErrMemberNotFound
+		}
+		return types.Member{}, errors.New("unexpected case")
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		memberID   string
+		body       string
+		cookie     *http.Cookie
+		statusCode int
+	}{
+		{
+			name:       "Successful archive",
+			memberID:   goodID,
+			body:       fmt.Sprintf(`{"reason": "PCS to Ramstein", "date": %q}`, date.Format(time.RFC3339)),
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Missing reason",
+			memberID:   goodID,
+			body:       `{}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid JSON",
+			memberID:   goodID,
+			body:       `{"reason":`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Already archived",
+			memberID:   archivedID,
+			body:       `{"reason": "Separated"}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "Member not found",
+			memberID:   "notfound",
+			body:       `{"reason": "Separated"}`,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Backend error",
+			memberID:   "bad",
+			body:       `{"reason": "Separated"}`,
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name:       "Supervisors can't archive",
+			memberID:   goodID,
+			body:       `{"reason": "Separated"}`,
+			cookie:     roleCookie(t, types.RoleSupervisor),
+			statusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/member/%s/archive", tt.memberID), bytes.NewBufferString(tt.body))
+			if tt.cookie == nil {
+				tt.cookie = adminCookie(t)
+			}
+			r.AddCookie(tt.cookie)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+			var m types.ApiMember
+			if err := json.NewDecoder(w.Body).Decode(&m); err != nil {
+				t.Fatalf("Error decoding archived member: %s", err.Error())
+			}
+			if m.Archive == nil || m.Archive.Reason != "PCS to Ramstein" || !m.Archive.Date.Equal(date) {
+				t.Errorf("Expected archive details in response, got %+v", m.Archive)
+			}
+		})
+	}
+}
+
+func TestRestoreMember(t *testing.T) {
+	goodID := uuid.NewString()
+	b := newMockBackend()
+	b.restoreMemberOverride = func(actorID, memberID string) (types.Member, error) {
+		switch memberID {
+		case goodID:
+			m := types.Member{}
+			m.ID = memberID
+			return m, nil
+		case "active":
+			return types.Member{}, backend.ErrMemberNotArchived
+		case "notfound":
+			return types.Member{}, backend.ErrMemberNotFound
+		}
+		return types.Member{}, errors.New("unexpected case")
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		memberID   string
+		statusCode int
+	}{
+		{name: "Successful restore", memberID: goodID, statusCode: http.StatusOK},
+		{name: "Member not archived", memberID: "active", statusCode: http.StatusConflict},
+		{name: "Member not found", memberID: "notfound", statusCode: http.StatusNotFound},
+		{name: "Backend error", memberID: "bad", statusCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/member/%s/restore", tt.memberID), nil)
+			r.AddCookie(adminCookie(t))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestPurgeMember(t *testing.T) {
+	goodID := uuid.NewString()
+	b := newMockBackend()
+	b.purgeMemberOverride = func(actorID, identifier string) error {
+		switch identifier {
+		case goodID:
+			return nil
+		case "active":
+			return backend.ErrMemberNotArchived
+		case "retained":
+			return backend.ErrRetentionPeriod
+		case "notfound":
+			return backend.ErrMemberNotFound
+		}
+		return errors.New("unexpected case")
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		memberID   string
+		cookie     *http.Cookie
+		statusCode int
+	}{
+		{name: "Successful purge", memberID: goodID, statusCode: http.StatusOK},
+		{name: "Member not archived", memberID: "active", statusCode: http.StatusConflict},
+		{name: "Within retention period", memberID: "retained", statusCode: http.StatusConflict},
+		{name: "Member not found", memberID: "notfound", statusCode: http.StatusNotFound},
+		{name: "Backend error", memberID: "bad", statusCode: http.StatusInternalServerError},
+		{name: "Only admins can purge", memberID: goodID, cookie: roleCookie(t, types.RoleTrainingManager), statusCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/admin/member/%s", tt.memberID), nil)
+			if tt.cookie == nil {
+				tt.cookie = adminCookie(t)
+			}
+			r.AddCookie(tt.cookie)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
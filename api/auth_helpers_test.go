@@ -0,0 +1,30 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/testutils"
+	"PORTal/types"
+	"github.com/google/uuid"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// roleCookie returns an identity cookie signed with the "test" secret for a member holding the given role.
+func roleCookie(t *testing.T, role types.Role) *http.Cookie {
+	t.Helper()
+	m := testutils.RandomMember(role == types.RoleAdmin)
+	m.ID = uuid.NewString()
+	m.Role = role
+	token, err := api.CreateToken(m, types.DefaultTenantID, types.DefaultRolePermissions[role], time.Hour, []byte("test"))
+	if err != nil {
+		t.Fatalf("Error creating token for %s: %s", role, err.Error())
+	}
+	return &http.Cookie{Name: api.JWTCookieName, Value: token}
+}
+
+// adminCookie returns an identity cookie for a member holding every permission.
+func adminCookie(t *testing.T) *http.Cookie {
+	t.Helper()
+	return roleCookie(t, types.RoleAdmin)
+}
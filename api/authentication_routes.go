@@ -1,41 +1,103 @@
 package api
 
 import (
+	"PORTal/backend"
+	"PORTal/types"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"time"
 )
 
 func (s Server) login(w http.ResponseWriter, r *http.Request) {
-	var res LoginResponse
 	s.logger.LogAttrs(r.Context(), slog.LevelInfo, "Deserializing body into types.Credentials")
 	var creds Credentials
 	err := json.NewDecoder(r.Body).Decode(&creds)
 	if err != nil {
 		s.logger.LogAttrs(r.Context(), slog.LevelWarn, "Error deserializing credentials from client", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
 	}
-	member, err := s.backend.Login(creds.Username, creds.Password)
+	if creds.Tenant != "" && s.tenants != nil {
+		var status int
+		if r, status = s.loginTenant(r, creds.Tenant); status != http.StatusOK {
+			writeError(w, status, backend.ErrAuthenticationFailed)
+			return
+		}
+	}
+	member, err := s.backendFor(r).Login(r.Context(), creds.Username, creds.Password)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+	s.completeLogin(w, r, member)
+}
+
+func (s Server) certificateLogin(w http.ResponseWriter, r *http.Request) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.PeerCertificates) == 0 {
+		s.logger.LogAttrs(r.Context(), slog.LevelInfo, "No verified client certificate presented")
+		writeError(w, http.StatusUnauthorized, backend.ErrAuthenticationFailed)
 		return
 	}
+	certificateID, err := certificateIdentifier(r.TLS.PeerCertificates[0])
+	if err != nil {
+		s.logger.LogAttrs(r.Context(), slog.LevelWarn, "Unable to determine identifier from client certificate", slog.String("error", err.Error()))
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+	member, err := s.backendFor(r).LoginWithCertificate(r.Context(), certificateID)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+	s.completeLogin(w, r, member)
+}
+
+// loginTenant scopes a login on the bare domain to the tenant with the given subdomain. Logins on a tenant's subdomain
+// can't name a different one.
+func (s Server) loginTenant(r *http.Request, subdomain string) (*http.Request, int) {
+	if hostSubdomain, ok := s.subdomain(r); ok {
+		if hostSubdomain != subdomain {
+			s.logger.LogAttrs(r.Context(), slog.LevelInfo, "Login names a different tenant than the host", slog.String("tenant", subdomain))
+			return r, http.StatusBadRequest
+		}
+		return r, http.StatusOK
+	}
+	t, err := s.tenants.GetTenantBySubdomain(r.Context(), subdomain)
+	if errors.Is(err, backend.ErrTenantNotFound) {
+		return r, http.StatusUnauthorized
+	} else if err != nil {
+		return r, http.StatusInternalServerError
+	}
+	return s.withTenant(r, t.ID), http.StatusOK
+}
+
+// completeLogin issues the identity cookie for an authenticated member and writes the LoginResponse.
+func (s Server) completeLogin(w http.ResponseWriter, r *http.Request, member types.Member) {
+	var res LoginResponse
+	var err error
 	res.Member = member.ToApiMember()
-	res.Qualifications, err = s.backend.GetMemberQualifications(res.Member.ID)
+	res.Qualifications, err = s.backendFor(r).GetMemberQualifications(r.Context(), res.Member.ID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
-	subordinates, err := s.backend.GetSubordinates(res.Member.ID)
+	subordinates, err := s.backendFor(r).GetSubordinates(r.Context(), res.Member.ID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	for _, subordinate := range subordinates {
 		res.Subordinates = append(res.Subordinates, subordinate.ToApiMember())
 	}
-	token, err := createToken(member, s.config.JWTExpiration*time.Hour, []byte(s.config.JWTSecret))
+	permissions, err := s.backendFor(r).GetRolePermissions(r.Context(), member.Role)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	res.Permissions = permissions
+	token, err := createToken(member, tenantOf(r), permissions, s.config.JWTExpiration*time.Hour, []byte(s.config.JWTSecret))
 	if err != nil {
 		s.logger.LogAttrs(r.Context(), slog.LevelError, "Error creating JWT, still logging in", slog.String("error", err.Error()))
 	}
@@ -55,7 +117,6 @@ func (s Server) login(w http.ResponseWriter, r *http.Request) {
 	err = json.NewEncoder(w).Encode(res)
 	if err != nil {
 		s.logger.LogAttrs(r.Context(), slog.LevelError, "Error serializing response to client", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
 
@@ -71,26 +132,14 @@ func (s Server) logout(w http.ResponseWriter, r *http.Request) {
 
 func (s Server) checkAdmin(w http.ResponseWriter, r *http.Request) {
 	s.logger.LogAttrs(r.Context(), slog.LevelInfo, "Validating member's admin permissions")
-	tokenCookie, err := r.Cookie(JWTCookieName)
-	if err != nil {
-		s.logger.LogAttrs(r.Context(), slog.LevelWarn, "Error getting token cookie", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusUnauthorized)
-		return
-	}
-	token, err := validateToken(tokenCookie.Value, s.jwtKeyFunc, s.logger)
-	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		return
-	}
-	customClaims, ok := token.Claims.(*CustomClaims)
-	if !ok {
-		s.logger.LogAttrs(r.Context(), slog.LevelError, "Error casting claims to CustomClaims")
-		w.WriteHeader(http.StatusInternalServerError)
+	id, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
 		return
 	}
-	if !customClaims.Admin {
-		s.logger.LogAttrs(r.Context(), slog.LevelInfo, "User is not admin", slog.String("member_id", customClaims.Subject))
-		w.WriteHeader(http.StatusUnauthorized)
+	if !id.Admin {
+		s.logger.LogAttrs(r.Context(), slog.LevelInfo, "User is not admin", slog.String("member_id", id.MemberID))
+		writeError(w, http.StatusUnauthorized, backend.ErrInsufficientPermissions)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
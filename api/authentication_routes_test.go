@@ -4,6 +4,8 @@ import (
 	"PORTal/api"
 	"PORTal/testutils"
 	"PORTal/types"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -94,7 +96,7 @@ func TestLogout(t *testing.T) {
 	member := testutils.RandomMember(false)
 	member.ID = uuid.NewString()
 
-	token, err := api.CreateToken(member, time.Hour, []byte("supersecret"))
+	token, err := api.CreateToken(member, types.DefaultTenantID, types.DefaultRolePermissions[member.Role], time.Hour, []byte("supersecret"))
 	if err != nil {
 		t.Fatalf("Error creating token for TestLogout: %s", err.Error())
 	}
@@ -147,22 +149,22 @@ func TestCheckAdmin(t *testing.T) {
 	normalMember := testutils.RandomMember(false)
 	normalMember.ID = uuid.NewString()
 
-	adminToken, err := api.CreateToken(adminMember, time.Hour, []byte("supersecret"))
+	adminToken, err := api.CreateToken(adminMember, types.DefaultTenantID, types.DefaultRolePermissions[adminMember.Role], time.Hour, []byte("supersecret"))
 	if err != nil {
 		t.Fatalf("Error creating adminToken for TestCheckAdmin: %s", err.Error())
 	}
 
-	normalToken, err := api.CreateToken(normalMember, time.Hour, []byte("supersecret"))
+	normalToken, err := api.CreateToken(normalMember, types.DefaultTenantID, types.DefaultRolePermissions[normalMember.Role], time.Hour, []byte("supersecret"))
 	if err != nil {
 		t.Fatalf("Error creating normalToken for TestCheckAdmin: %s", err.Error())
 	}
 
-	invalidSignatureToken, err := api.CreateToken(adminMember, time.Hour, []byte("differentsecret"))
+	invalidSignatureToken, err := api.CreateToken(adminMember, types.DefaultTenantID, types.DefaultRolePermissions[adminMember.Role], time.Hour, []byte("differentsecret"))
 	if err != nil {
 		t.Fatalf("Error creating invalidSignatureToken for TestCheckAdmin: %s", err.Error())
 	}
 
-	expiredToken, err := api.CreateToken(adminMember, time.Millisecond, []byte("supersecret"))
+	expiredToken, err := api.CreateToken(adminMember, types.DefaultTenantID, types.DefaultRolePermissions[adminMember.Role], time.Millisecond, []byte("supersecret"))
 	if err != nil {
 		t.Fatalf("Error creating expiredToken for TestCheckAdmin: %s", err.Error())
 	}
@@ -212,3 +214,109 @@ func TestCheckAdmin(t *testing.T) {
 		})
 	}
 }
+
+func TestCookieIdentityReread(t *testing.T) {
+	admin := testutils.RandomMember(true)
+	admin.ID = uuid.NewString()
+	admin.Role = types.RoleAdmin
+	token, err := api.CreateToken(admin, types.DefaultTenantID, types.DefaultRolePermissions[types.RoleAdmin], time.Hour, []byte("test"))
+	if err != nil {
+		t.Fatalf("Error creating token: %s", err.Error())
+	}
+	t.Cleanup(func() { api.SignedIn.Delete(admin.ID) })
+	s := api.New(slog.Default(), newMockBackend(), false, api.Config{JWTSecret: "test"})
+
+	demoted := admin
+	demoted.Role = types.RoleMember
+	demoted.Admin = false
+	archived := admin
+	archived.Archive = &types.MemberArchive{Date: time.Now(), Reason: "PCS"}
+	tc := []struct {
+		name       string
+		stored     types.Member
+		statusCode int
+	}{
+		{name: "Current role", stored: admin, statusCode: http.StatusOK},
+		{name: "Demoted since login", stored: demoted, statusCode: http.StatusForbidden},
+		{name: "Archived since login", stored: archived, statusCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			api.SignedIn.Store(admin.ID, tt.stored)
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/admin/import/profiles", nil)
+			r.AddCookie(&http.Cookie{Name: api.JWTCookieName, Value: token})
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestCertificateLogin(t *testing.T) {
+	member := testutils.RandomMember(false)
+	member.ID = uuid.NewString()
+	m := newMockBackend()
+	m.loginWithCertificateOverride = func(certificateID string) (types.Member, error) {
+		if certificateID == "1234567890" {
+			return member, nil
+		}
+		return types.Member{}, errors.New("generic error")
+	}
+	s := api.New(slog.Default(), m, false, api.Config{JWTSecret: "test", JWTExpiration: 1})
+
+	boundCert, ca := testutils.GenerateClientCertificate(t, "DOE.JOHN.A.1234567890", "1234567890@mil")
+	unboundCert, _ := testutils.GenerateClientCertificate(t, "DOE.JANE.A.1111111111", "")
+
+	tc := []struct {
+		Name       string
+		TLS        *tls.ConnectionState
+		StatusCode int
+	}{
+		{
+			Name:       "Successful login",
+			TLS:        &tls.ConnectionState{PeerCertificates: []*x509.Certificate{boundCert}, VerifiedChains: [][]*x509.Certificate{{boundCert, ca}}},
+			StatusCode: http.StatusOK,
+		},
+		{
+			Name:       "Certificate not bound",
+			TLS:        &tls.ConnectionState{PeerCertificates: []*x509.Certificate{unboundCert}, VerifiedChains: [][]*x509.Certificate{{unboundCert, ca}}},
+			StatusCode: http.StatusUnauthorized,
+		},
+		{
+			Name:       "Certificate not verified",
+			TLS:        &tls.ConnectionState{PeerCertificates: []*x509.Certificate{boundCert}},
+			StatusCode: http.StatusUnauthorized,
+		},
+		{
+			Name:       "No TLS",
+			TLS:        nil,
+			StatusCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.Name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/login/certificate", nil)
+			r.TLS = tt.TLS
+			s.ServeHTTP(w, r)
+			if w.Code != tt.StatusCode {
+				t.Errorf("Expected response code: %d, got: %d", tt.StatusCode, w.Code)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var res api.LoginResponse
+			if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+				t.Fatalf("Error decoding login response: %s", err.Error())
+			}
+			if res.Member.ID != member.ID {
+				t.Errorf("Expected member ID: %s, got: %s", member.ID, res.Member.ID)
+			}
+			if w.Header().Get("Set-Cookie") == "" {
+				t.Errorf("Expected Set-Cookie header to be set, but it wasn't")
+			}
+		})
+	}
+}
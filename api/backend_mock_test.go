@@ -3,178 +3,383 @@ package api_test
 import (
 	"PORTal/api"
 	"PORTal/types"
+	"context"
+	"io"
 )
 
 var _ api.Backend = (*mockBackend)(nil)
 
 func newMockBackend() *mockBackend {
 	return &mockBackend{
-		addMemberOverride:            func(m types.Member) (types.Member, error) { return types.Member{}, nil },
-		getMemberOverride:            func(id string) (types.Member, error) { return types.Member{}, nil },
-		getAllMembersOverride:        func() ([]types.Member, error) { return []types.Member{}, nil },
-		getSubordinatesOverride:      func(id string) ([]types.Member, error) { return nil, nil },
-		updateMemberOverride:         func(m types.Member) (types.Member, error) { return types.Member{}, nil },
-		deleteMemberOverride:         func(id string) error { return nil },
-		addQualificationOverride:     func(q types.Qualification) (types.Qualification, error) { return types.Qualification{}, nil },
-		getQualificationOverride:     func(id string) (types.Qualification, error) { return types.Qualification{}, nil },
-		getAllQualificationsOverride: func() ([]types.Qualification, error) { return []types.Qualification{}, nil },
+		addMemberOverride: func(m types.Member) (types.Member, error) { return types.Member{}, nil },
+		getMemberOverride: func(id string) (types.Member, error) { return types.Member{}, nil },
+		listMembersOverride: func(opts types.MemberListOptions) (types.Page[types.Member], error) {
+			return types.Page[types.Member]{Items: []types.Member{}}, nil
+		},
+		getSubordinatesOverride:  func(id string) ([]types.Member, error) { return nil, nil },
+		updateMemberOverride:     func(m types.Member) (types.Member, error) { return types.Member{}, nil },
+		patchMemberOverride:      func(id string, patch []byte) (types.Member, error) { return types.Member{}, nil },
+		addQualificationOverride: func(q types.Qualification) (types.Qualification, error) { return types.Qualification{}, nil },
+		getQualificationOverride: func(id string) (types.Qualification, error) { return types.Qualification{}, nil },
+		listQualificationsOverride: func(opts types.ListOptions) (types.Page[types.Qualification], error) {
+			return types.Page[types.Qualification]{Items: []types.Qualification{}}, nil
+		},
 		updateQualificationOverride: func(q types.Qualification, forceUpdateExpiration bool) (types.Qualification, error) {
 			return types.Qualification{}, nil
 		},
-		getRequirementOverride:            func(id string) (types.Requirement, error) { return types.Requirement{}, nil },
-		deleteQualificationOverride:       func(id string) error { return nil },
-		addRequirementOverride:            func(r types.Requirement) (types.Requirement, error) { return types.Requirement{}, nil },
-		getAllRequirementsOverride:        func() ([]types.Requirement, error) { return []types.Requirement{}, nil },
+		patchQualificationOverride: func(id string, patch []byte) (types.Qualification, error) {
+			return types.Qualification{}, nil
+		},
+		getRequirementOverride:      func(id string) (types.Requirement, error) { return types.Requirement{}, nil },
+		deleteQualificationOverride: func(id string) error { return nil },
+		addRequirementOverride:      func(r types.Requirement) (types.Requirement, error) { return types.Requirement{}, nil },
+		listRequirementsOverride: func(opts types.ListOptions) (types.Page[types.Requirement], error) {
+			return types.Page[types.Requirement]{Items: []types.Requirement{}}, nil
+		},
 		updateRequirementOverride:         func(r types.Requirement) (types.Requirement, error) { return types.Requirement{}, nil },
+		patchRequirementOverride:          func(id string, patch []byte) (types.Requirement, error) { return types.Requirement{}, nil },
 		deleteRequirementOverride:         func(id string) error { return nil },
-		assignMemberQualificationOverride: func(qualID, memberID string) error { return nil },
+		assignMemberQualificationOverride: func(actorID, memberID, qualID string) error { return nil },
 		getMemberQualificationOverride:    func(memberID, qualID string) (types.Qualification, error) { return types.Qualification{}, nil },
 		getMemberQualificationsOverride:   func(memberID string) ([]types.Qualification, error) { return nil, nil },
-		removeMemberQualificationOverride: func(memberID, qualId string) error { return nil },
+		removeMemberQualificationOverride: func(actorID, memberID, qualID string) error { return nil },
 		addReferenceOverride:              func(r types.Reference) (types.Reference, error) { return types.Reference{}, nil },
 		getReferenceOverride:              func(id string) (types.Reference, error) { return types.Reference{}, nil },
 		getReferencesOverride:             func() ([]types.Reference, error) { return nil, nil },
 		updateReferenceOverride:           func(r types.Reference, overrideNoVolume bool) (types.Reference, error) { return types.Reference{}, nil },
+		patchReferenceOverride:            func(id string, patch []byte) (types.Reference, error) { return types.Reference{}, nil },
 		deleteReferenceOverride:           func(id string) error { return nil },
 		addSessionOverride:                func(memberID, userAgent string) (types.Session, error) { return types.Session{}, nil },
 		validateSessionOverride:           func(sessionID, memberID, ipAddress string) error { return nil },
 		loginOverride:                     func(username, password string) (types.Member, error) { return types.Member{}, nil },
+		loginWithCertificateOverride:      func(certificateID string) (types.Member, error) { return types.Member{}, nil },
+		bindMemberCertificateOverride:     func(memberID, certificateID string) (types.Member, error) { return types.Member{}, nil },
+		createAPITokenOverride: func(memberID, name string, scope types.TokenScope) (types.APIToken, error) {
+			return types.APIToken{}, nil
+		},
+		getAPITokensOverride:         func(memberID string) ([]types.APIToken, error) { return nil, nil },
+		revokeAPITokenOverride:       func(memberID, tokenID string) error { return nil },
+		authenticateAPITokenOverride: func(token string) (types.APIToken, types.Member, error) { return types.APIToken{}, types.Member{}, nil },
+		getRolePermissionsOverride:   func(role types.Role) ([]types.Permission, error) { return types.DefaultRolePermissions[role], nil },
+		getRolesOverride:             func() ([]types.RoleDefinition, error) { return nil, nil },
+		updateRolePermissionsOverride: func(role types.Role, permissions []types.Permission) (types.RoleDefinition, error) {
+			return types.RoleDefinition{}, nil
+		},
+		addCertifierOverride:          func(requirementID, memberID string) error { return nil },
+		getCertifiersOverride:         func(requirementID string) ([]types.Member, error) { return []types.Member{}, nil },
+		removeCertifierOverride:       func(requirementID, memberID string) error { return nil },
+		submitCompletionOverride:      func(submitterID string, c types.Completion) (types.Completion, error) { return c, nil },
+		getCompletionOverride:         func(id string) (types.Completion, error) { return types.Completion{ID: id}, nil },
+		getMemberCompletionsOverride:  func(memberID string) ([]types.Completion, error) { return []types.Completion{}, nil },
+		getPendingCompletionsOverride: func(certifierID string) ([]types.Completion, error) { return []types.Completion{}, nil },
+		reviewCompletionOverride: func(certifierID, completionID string, approve bool, comments string) (types.Completion, error) {
+			return types.Completion{ID: completionID, CertifierID: certifierID}, nil
+		},
+		getPrerequisitesOverride: func(id string) ([]types.Qualification, error) { return []types.Qualification{}, nil },
+		getMemberQualificationStatusOverride: func(memberID, qualificationID string) (types.QualificationStatus, error) {
+			return types.QualificationStatus{MemberID: memberID, QualificationID: qualificationID, State: types.StateQualified}, nil
+		},
+		getMemberQualificationStatusesOverride: func(memberID string) ([]types.QualificationStatus, error) { return []types.QualificationStatus{}, nil },
+		grantWaiverOverride:                    func(approverID string, w types.Waiver) (types.Waiver, error) { return w, nil },
+		getWaiverOverride:                      func(id string) (types.Waiver, error) { return types.Waiver{ID: id}, nil },
+		getMemberWaiversOverride:               func(memberID string) ([]types.Waiver, error) { return []types.Waiver{}, nil },
+		getWaiverMemoOverride:                  func(id string) (types.WaiverMemo, error) { return types.WaiverMemo{}, nil },
+		revokeWaiverOverride:                   func(actorID, id, reason string) (types.Waiver, error) { return types.Waiver{ID: id}, nil },
+		getAuditEntriesOverride:                func(entityType, entityID string) ([]types.AuditEntry, error) { return []types.AuditEntry{}, nil },
+		addDutyPositionOverride:                func(d types.DutyPosition) (types.DutyPosition, error) { return types.DutyPosition{}, nil },
+		getDutyPositionOverride:                func(id string) (types.DutyPosition, error) { return types.DutyPosition{}, nil },
+		getDutyPositionsOverride:               func() ([]types.DutyPosition, error) { return nil, nil },
+		updateDutyPositionOverride: func(actorID string, d types.DutyPosition, apply bool) (types.DutyPositionDiff, error) {
+			return types.DutyPositionDiff{}, nil
+		},
+		deleteDutyPositionOverride:       func(id string) error { return nil },
+		assignMemberDutyPositionOverride: func(actorID string, memberID string, positionID string) ([]string, error) { return nil, nil },
+		getMemberDutyPositionsOverride:   func(memberID string) ([]types.DutyPosition, error) { return nil, nil },
+		removeMemberDutyPositionOverride: func(actorID string, memberID string, positionID string, removeQualifications bool) (types.DutyPositionRemoval, error) {
+			return types.DutyPositionRemoval{}, nil
+		},
+		bulkAssignQualificationsOverride: func(actorID string, req types.BulkQualificationRequest) ([]types.BulkItemResult, error) {
+			return nil, nil
+		},
+		bulkRemoveQualificationsOverride: func(actorID string, req types.BulkQualificationRequest) ([]types.BulkItemResult, error) {
+			return nil, nil
+		},
+		importMembersOverride: func(r io.Reader, dryRun bool) (types.MemberImportReport, error) {
+			return types.MemberImportReport{}, nil
+		},
+		addImportProfileOverride:    func(profile types.ImportProfile) (types.ImportProfile, error) { return types.ImportProfile{}, nil },
+		getImportProfileOverride:    func(id string) (types.ImportProfile, error) { return types.ImportProfile{}, nil },
+		getImportProfilesOverride:   func() ([]types.ImportProfile, error) { return nil, nil },
+		deleteImportProfileOverride: func(id string) error { return nil },
+		stageCompletionImportOverride: func(uploaderID string, profileID string, fileName string, r io.Reader) (types.ImportBatch, error) {
+			return types.ImportBatch{}, nil
+		},
+		getImportBatchOverride: func(id string) (types.ImportBatch, error) { return types.ImportBatch{}, nil },
+		updateStagedRowOverride: func(batchID string, rowID string, update types.StagedRowUpdate) (types.StagedRow, error) {
+			return types.StagedRow{}, nil
+		},
+		commitImportBatchOverride:       func(actorID string, batchID string) (types.ImportBatch, error) { return types.ImportBatch{}, nil },
+		discardImportBatchOverride:      func(id string) error { return nil },
+		getQualificationHistoryOverride: func(memberID string, qualificationID string) ([]types.QualificationEvent, error) { return nil, nil },
+		archiveMemberOverride: func(actorID string, memberID string, archive types.MemberArchive) (types.Member, error) {
+			return types.Member{}, nil
+		},
+		restoreMemberOverride:        func(actorID string, memberID string) (types.Member, error) { return types.Member{}, nil },
+		getArchivedMembersOverride:   func() ([]types.Member, error) { return nil, nil },
+		purgeMemberOverride:          func(actorID string, identifier string) error { return nil },
+		purgeArchivedMembersOverride: func(actorID string) ([]string, error) { return nil, nil },
+		exportMemberTransferOverride: func(actorID string, memberID string) (types.SignedTransferPackage, error) {
+			return types.SignedTransferPackage{}, nil
+		},
+		importMemberTransferOverride: func(actorID string, signed types.SignedTransferPackage) (types.TransferImportReport, error) {
+			return types.TransferImportReport{}, nil
+		},
+		setMemberUnitOverride:   func(memberID string, unitID string) (types.Member, error) { return types.Member{}, nil },
+		addUnitOverride:         func(u types.Unit) (types.Unit, error) { return u, nil },
+		getUnitOverride:         func(id string) (types.Unit, error) { return types.Unit{}, nil },
+		getUnitsOverride:        func() ([]types.Unit, error) { return []types.Unit{}, nil },
+		updateUnitOverride:      func(u types.Unit) (types.Unit, error) { return u, nil },
+		deleteUnitOverride:      func(id string) error { return nil },
+		getUnitMembersOverride:  func(unitID string) ([]types.Member, error) { return []types.Member{}, nil },
+		getUnitReportOverride:   func(unitID string) (types.UnitReport, error) { return types.UnitReport{}, nil },
+		addUnitAdminOverride:    func(unitID string, memberID string) error { return nil },
+		getUnitAdminsOverride:   func(unitID string) ([]types.Member, error) { return []types.Member{}, nil },
+		removeUnitAdminOverride: func(unitID string, memberID string) error { return nil },
+		isUnitAdminOverride:     func(memberID string, unitID string) (bool, error) { return false, nil },
 	}
 }
 
 type mockBackend struct {
 	addMemberOverride       func(m types.Member) (types.Member, error)
 	getMemberOverride       func(id string) (types.Member, error)
-	getAllMembersOverride   func() ([]types.Member, error)
+	listMembersOverride     func(opts types.MemberListOptions) (types.Page[types.Member], error)
 	getSubordinatesOverride func(id string) ([]types.Member, error)
 	updateMemberOverride    func(m types.Member) (types.Member, error)
-	deleteMemberOverride    func(id string) error
-
-	addQualificationOverride     func(q types.Qualification) (types.Qualification, error)
-	getQualificationOverride     func(id string) (types.Qualification, error)
-	getAllQualificationsOverride func() ([]types.Qualification, error)
-	updateQualificationOverride  func(q types.Qualification, forceUpdateExpiration bool) (types.Qualification, error)
-	deleteQualificationOverride  func(id string) error
-
-	addRequirementOverride     func(r types.Requirement) (types.Requirement, error)
-	getRequirementOverride     func(id string) (types.Requirement, error)
-	getAllRequirementsOverride func() ([]types.Requirement, error)
-	updateRequirementOverride  func(r types.Requirement) (types.Requirement, error)
-	deleteRequirementOverride  func(id string) error
-
-	assignMemberQualificationOverride func(qualID, memberID string) error
+	patchMemberOverride     func(id string, patch []byte) (types.Member, error)
+
+	addQualificationOverride    func(q types.Qualification) (types.Qualification, error)
+	getQualificationOverride    func(id string) (types.Qualification, error)
+	listQualificationsOverride  func(opts types.ListOptions) (types.Page[types.Qualification], error)
+	updateQualificationOverride func(q types.Qualification, forceUpdateExpiration bool) (types.Qualification, error)
+	patchQualificationOverride  func(id string, patch []byte) (types.Qualification, error)
+	deleteQualificationOverride func(id string) error
+
+	addRequirementOverride    func(r types.Requirement) (types.Requirement, error)
+	getRequirementOverride    func(id string) (types.Requirement, error)
+	listRequirementsOverride  func(opts types.ListOptions) (types.Page[types.Requirement], error)
+	updateRequirementOverride func(r types.Requirement) (types.Requirement, error)
+	patchRequirementOverride  func(id string, patch []byte) (types.Requirement, error)
+	deleteRequirementOverride func(id string) error
+
+	assignMemberQualificationOverride func(actorID, memberID, qualID string) error
 	getMemberQualificationOverride    func(memberID, qualID string) (types.Qualification, error)
 	getMemberQualificationsOverride   func(memberID string) ([]types.Qualification, error)
-	removeMemberQualificationOverride func(memberID, qualID string) error
+	removeMemberQualificationOverride func(actorID, memberID, qualID string) error
 
 	addReferenceOverride    func(r types.Reference) (types.Reference, error)
 	getReferenceOverride    func(id string) (types.Reference, error)
 	getReferencesOverride   func() ([]types.Reference, error)
 	updateReferenceOverride func(r types.Reference, overrideNoVolume bool) (types.Reference, error)
+	patchReferenceOverride  func(id string, patch []byte) (types.Reference, error)
 	deleteReferenceOverride func(id string) error
 
 	addSessionOverride      func(memberID, userAgent string) (types.Session, error)
 	validateSessionOverride func(sessionID, memberID, ipAddress string) error
 	loginOverride           func(username, password string) (types.Member, error)
+
+	loginWithCertificateOverride  func(certificateID string) (types.Member, error)
+	bindMemberCertificateOverride func(memberID, certificateID string) (types.Member, error)
+
+	createAPITokenOverride       func(memberID, name string, scope types.TokenScope) (types.APIToken, error)
+	getAPITokensOverride         func(memberID string) ([]types.APIToken, error)
+	revokeAPITokenOverride       func(memberID, tokenID string) error
+	authenticateAPITokenOverride func(token string) (types.APIToken, types.Member, error)
+
+	getRolePermissionsOverride    func(role types.Role) ([]types.Permission, error)
+	getRolesOverride              func() ([]types.RoleDefinition, error)
+	updateRolePermissionsOverride func(role types.Role, permissions []types.Permission) (types.RoleDefinition, error)
+
+	addCertifierOverride          func(requirementID, memberID string) error
+	getCertifiersOverride         func(requirementID string) ([]types.Member, error)
+	removeCertifierOverride       func(requirementID, memberID string) error
+	submitCompletionOverride      func(submitterID string, c types.Completion) (types.Completion, error)
+	getCompletionOverride         func(id string) (types.Completion, error)
+	getMemberCompletionsOverride  func(memberID string) ([]types.Completion, error)
+	getPendingCompletionsOverride func(certifierID string) ([]types.Completion, error)
+	reviewCompletionOverride      func(certifierID, completionID string, approve bool, comments string) (types.Completion, error)
+
+	getPrerequisitesOverride               func(id string) ([]types.Qualification, error)
+	getMemberQualificationStatusOverride   func(memberID, qualificationID string) (types.QualificationStatus, error)
+	getMemberQualificationStatusesOverride func(memberID string) ([]types.QualificationStatus, error)
+
+	grantWaiverOverride      func(approverID string, w types.Waiver) (types.Waiver, error)
+	getWaiverOverride        func(id string) (types.Waiver, error)
+	getMemberWaiversOverride func(memberID string) ([]types.Waiver, error)
+	getWaiverMemoOverride    func(id string) (types.WaiverMemo, error)
+	revokeWaiverOverride     func(actorID, id, reason string) (types.Waiver, error)
+	getAuditEntriesOverride  func(entityType, entityID string) ([]types.AuditEntry, error)
+
+	addDutyPositionOverride          func(d types.DutyPosition) (types.DutyPosition, error)
+	getDutyPositionOverride          func(id string) (types.DutyPosition, error)
+	getDutyPositionsOverride         func() ([]types.DutyPosition, error)
+	updateDutyPositionOverride       func(actorID string, d types.DutyPosition, apply bool) (types.DutyPositionDiff, error)
+	deleteDutyPositionOverride       func(id string) error
+	assignMemberDutyPositionOverride func(actorID string, memberID string, positionID string) ([]string, error)
+	getMemberDutyPositionsOverride   func(memberID string) ([]types.DutyPosition, error)
+	removeMemberDutyPositionOverride func(actorID string, memberID string, positionID string, removeQualifications bool) (types.DutyPositionRemoval, error)
+
+	bulkAssignQualificationsOverride func(actorID string, req types.BulkQualificationRequest) ([]types.BulkItemResult, error)
+	bulkRemoveQualificationsOverride func(actorID string, req types.BulkQualificationRequest) ([]types.BulkItemResult, error)
+
+	importMembersOverride func(r io.Reader, dryRun bool) (types.MemberImportReport, error)
+
+	addImportProfileOverride      func(profile types.ImportProfile) (types.ImportProfile, error)
+	getImportProfileOverride      func(id string) (types.ImportProfile, error)
+	getImportProfilesOverride     func() ([]types.ImportProfile, error)
+	deleteImportProfileOverride   func(id string) error
+	stageCompletionImportOverride func(uploaderID string, profileID string, fileName string, r io.Reader) (types.ImportBatch, error)
+	getImportBatchOverride        func(id string) (types.ImportBatch, error)
+	updateStagedRowOverride       func(batchID string, rowID string, update types.StagedRowUpdate) (types.StagedRow, error)
+	commitImportBatchOverride     func(actorID string, batchID string) (types.ImportBatch, error)
+	discardImportBatchOverride    func(id string) error
+
+	getQualificationHistoryOverride func(memberID string, qualificationID string) ([]types.QualificationEvent, error)
+
+	archiveMemberOverride        func(actorID string, memberID string, archive types.MemberArchive) (types.Member, error)
+	restoreMemberOverride        func(actorID string, memberID string) (types.Member, error)
+	getArchivedMembersOverride   func() ([]types.Member, error)
+	purgeMemberOverride          func(actorID string, identifier string) error
+	purgeArchivedMembersOverride func(actorID string) ([]string, error)
+
+	exportMemberTransferOverride func(actorID string, memberID string) (types.SignedTransferPackage, error)
+	importMemberTransferOverride func(actorID string, signed types.SignedTransferPackage) (types.TransferImportReport, error)
+
+	setMemberUnitOverride   func(memberID string, unitID string) (types.Member, error)
+	addUnitOverride         func(u types.Unit) (types.Unit, error)
+	getUnitOverride         func(id string) (types.Unit, error)
+	getUnitsOverride        func() ([]types.Unit, error)
+	updateUnitOverride      func(u types.Unit) (types.Unit, error)
+	deleteUnitOverride      func(id string) error
+	getUnitMembersOverride  func(unitID string) ([]types.Member, error)
+	getUnitReportOverride   func(unitID string) (types.UnitReport, error)
+	addUnitAdminOverride    func(unitID string, memberID string) error
+	getUnitAdminsOverride   func(unitID string) ([]types.Member, error)
+	removeUnitAdminOverride func(unitID string, memberID string) error
+	isUnitAdminOverride     func(memberID string, unitID string) (bool, error)
 }
 
-func (m *mockBackend) AddMember(me types.Member) (types.Member, error) {
+func (m *mockBackend) AddMember(ctx context.Context, me types.Member) (types.Member, error) {
 	return m.addMemberOverride(me)
 }
 
-func (m *mockBackend) GetMember(id string) (types.Member, error) {
+func (m *mockBackend) GetMember(ctx context.Context, id string) (types.Member, error) {
+	if member, ok := api.SignedIn.Load(id); ok {
+		return member.(types.Member), nil
+	}
 	return m.getMemberOverride(id)
 }
 
-func (m *mockBackend) GetAllMembers() ([]types.Member, error) {
-	return m.getAllMembersOverride()
+func (m *mockBackend) ListMembers(ctx context.Context, opts types.MemberListOptions) (types.Page[types.Member], error) {
+	return m.listMembersOverride(opts)
 }
 
-func (m *mockBackend) GetSubordinates(id string) ([]types.Member, error) {
+func (m *mockBackend) GetSubordinates(ctx context.Context, id string) ([]types.Member, error) {
 	return m.getSubordinatesOverride(id)
 }
 
-func (m *mockBackend) UpdateMember(me types.Member) (types.Member, error) {
+func (m *mockBackend) UpdateMember(ctx context.Context, me types.Member) (types.Member, error) {
 	return m.updateMemberOverride(me)
 }
 
-func (m *mockBackend) DeleteMember(id string) error {
-	return m.deleteMemberOverride(id)
+func (m *mockBackend) PatchMember(ctx context.Context, id string, version int, patch []byte) (types.Member, error) {
+	return m.patchMemberOverride(id, patch)
 }
 
-func (m *mockBackend) AddQualification(q types.Qualification) (types.Qualification, error) {
+func (m *mockBackend) AddQualification(ctx context.Context, q types.Qualification) (types.Qualification, error) {
 	return m.addQualificationOverride(q)
 }
 
-func (m *mockBackend) GetQualification(id string) (types.Qualification, error) {
+func (m *mockBackend) GetQualification(ctx context.Context, id string) (types.Qualification, error) {
 	return m.getQualificationOverride(id)
 }
 
-func (m *mockBackend) GetAllQualifications() ([]types.Qualification, error) {
-	return m.getAllQualificationsOverride()
+func (m *mockBackend) ListQualifications(ctx context.Context, opts types.ListOptions) (types.Page[types.Qualification], error) {
+	return m.listQualificationsOverride(opts)
 }
 
-func (m *mockBackend) UpdateQualification(q types.Qualification, forceUpdateExpiration bool) (types.Qualification, error) {
+func (m *mockBackend) UpdateQualification(ctx context.Context, q types.Qualification, forceUpdateExpiration bool) (types.Qualification, error) {
 	return m.updateQualificationOverride(q, forceUpdateExpiration)
 }
 
-func (m *mockBackend) DeleteQualification(id string) error {
+func (m *mockBackend) PatchQualification(ctx context.Context, id string, version int, patch []byte) (types.Qualification, error) {
+	return m.patchQualificationOverride(id, patch)
+}
+
+func (m *mockBackend) DeleteQualification(ctx context.Context, id string, version int) error {
 	return m.deleteQualificationOverride(id)
 }
 
-func (m *mockBackend) AddRequirement(r types.Requirement) (types.Requirement, error) {
+func (m *mockBackend) AddRequirement(ctx context.Context, r types.Requirement) (types.Requirement, error) {
 	return m.addRequirementOverride(r)
 }
 
-func (m *mockBackend) GetRequirement(id string) (types.Requirement, error) {
+func (m *mockBackend) GetRequirement(ctx context.Context, id string) (types.Requirement, error) {
 	return m.getRequirementOverride(id)
 }
 
-func (m *mockBackend) GetAllRequirements() ([]types.Requirement, error) {
-	return m.getAllRequirementsOverride()
+func (m *mockBackend) ListRequirements(ctx context.Context, opts types.ListOptions) (types.Page[types.Requirement], error) {
+	return m.listRequirementsOverride(opts)
 }
 
-func (m *mockBackend) UpdateRequirement(r types.Requirement) (types.Requirement, error) {
+func (m *mockBackend) UpdateRequirement(ctx context.Context, r types.Requirement) (types.Requirement, error) {
 	return m.updateRequirementOverride(r)
 }
 
-func (m *mockBackend) DeleteRequirement(id string) error {
+func (m *mockBackend) PatchRequirement(ctx context.Context, id string, version int, patch []byte) (types.Requirement, error) {
+	return m.patchRequirementOverride(id, patch)
+}
+
+func (m *mockBackend) DeleteRequirement(ctx context.Context, id string, version int) error {
 	return m.deleteRequirementOverride(id)
 }
 
-func (m *mockBackend) AssignMemberQualification(qualID, memberID string) error {
-	return m.assignMemberQualificationOverride(qualID, memberID)
+func (m *mockBackend) AssignMemberQualification(ctx context.Context, actorID, memberID, qualID string) error {
+	return m.assignMemberQualificationOverride(actorID, memberID, qualID)
 }
 
-func (m *mockBackend) GetMemberQualification(memberID, qualID string) (types.Qualification, error) {
+func (m *mockBackend) GetMemberQualification(ctx context.Context, memberID, qualID string) (types.Qualification, error) {
 	return m.getMemberQualificationOverride(memberID, qualID)
 }
 
-func (m *mockBackend) GetMemberQualifications(memberID string) ([]types.Qualification, error) {
+func (m *mockBackend) GetMemberQualifications(ctx context.Context, memberID string) ([]types.Qualification, error) {
 	return m.getMemberQualificationsOverride(memberID)
 }
 
-func (m *mockBackend) RemoveMemberQualification(memberID, qualID string) error {
-	return m.removeMemberQualificationOverride(memberID, qualID)
+func (m *mockBackend) RemoveMemberQualification(ctx context.Context, actorID, memberID, qualID string) error {
+	return m.removeMemberQualificationOverride(actorID, memberID, qualID)
 }
 
-func (m *mockBackend) AddReference(r types.Reference) (types.Reference, error) {
+func (m *mockBackend) AddReference(ctx context.Context, r types.Reference) (types.Reference, error) {
 	return m.addReferenceOverride(r)
 }
 
-func (m *mockBackend) GetReference(id string) (types.Reference, error) {
+func (m *mockBackend) GetReference(ctx context.Context, id string) (types.Reference, error) {
 	return m.getReferenceOverride(id)
 }
 
-func (m *mockBackend) GetReferences() ([]types.Reference, error) {
+func (m *mockBackend) GetReferences(ctx context.Context) ([]types.Reference, error) {
 	return m.getReferencesOverride()
 }
 
-func (m *mockBackend) UpdateReference(r types.Reference, overrideNoVolume bool) (types.Reference, error) {
+func (m *mockBackend) UpdateReference(ctx context.Context, r types.Reference, overrideNoVolume bool) (types.Reference, error) {
 	return m.updateReferenceOverride(r, overrideNoVolume)
 }
 
-func (m *mockBackend) DeleteReference(id string) error {
+func (m *mockBackend) PatchReference(ctx context.Context, id string, version int, patch []byte) (types.Reference, error) {
+	return m.patchReferenceOverride(id, patch)
+}
+
+func (m *mockBackend) DeleteReference(ctx context.Context, id string) error {
 	return m.deleteReferenceOverride(id)
 }
 
@@ -186,6 +391,270 @@ func (m *mockBackend) ValidateSession(sessionID, memberID, ipAddress string) err
 	return m.validateSessionOverride(sessionID, memberID, ipAddress)
 }
 
-func (m *mockBackend) Login(username, password string) (types.Member, error) {
+func (m *mockBackend) Login(ctx context.Context, username, password string) (types.Member, error) {
 	return m.loginOverride(username, password)
 }
+
+func (m *mockBackend) LoginWithCertificate(ctx context.Context, certificateID string) (types.Member, error) {
+	return m.loginWithCertificateOverride(certificateID)
+}
+
+func (m *mockBackend) BindMemberCertificate(ctx context.Context, memberID, certificateID string) (types.Member, error) {
+	return m.bindMemberCertificateOverride(memberID, certificateID)
+}
+
+func (m *mockBackend) CreateAPIToken(ctx context.Context, memberID, name string, scope types.TokenScope) (types.APIToken, error) {
+	return m.createAPITokenOverride(memberID, name, scope)
+}
+
+func (m *mockBackend) GetAPITokens(ctx context.Context, memberID string) ([]types.APIToken, error) {
+	return m.getAPITokensOverride(memberID)
+}
+
+func (m *mockBackend) RevokeAPIToken(ctx context.Context, memberID, tokenID string) error {
+	return m.revokeAPITokenOverride(memberID, tokenID)
+}
+
+func (m *mockBackend) AuthenticateAPIToken(ctx context.Context, token string) (types.APIToken, types.Member, error) {
+	return m.authenticateAPITokenOverride(token)
+}
+
+func (m *mockBackend) GetRolePermissions(ctx context.Context, role types.Role) ([]types.Permission, error) {
+	return m.getRolePermissionsOverride(role)
+}
+
+func (m *mockBackend) GetRoles(ctx context.Context) ([]types.RoleDefinition, error) {
+	return m.getRolesOverride()
+}
+
+func (m *mockBackend) UpdateRolePermissions(ctx context.Context, role types.Role, permissions []types.Permission) (types.RoleDefinition, error) {
+	return m.updateRolePermissionsOverride(role, permissions)
+}
+
+func (m *mockBackend) AddCertifier(ctx context.Context, requirementID, memberID string) error {
+	return m.addCertifierOverride(requirementID, memberID)
+}
+
+func (m *mockBackend) GetCertifiers(ctx context.Context, requirementID string) ([]types.Member, error) {
+	return m.getCertifiersOverride(requirementID)
+}
+
+func (m *mockBackend) RemoveCertifier(ctx context.Context, requirementID, memberID string) error {
+	return m.removeCertifierOverride(requirementID, memberID)
+}
+
+func (m *mockBackend) SubmitCompletion(ctx context.Context, submitterID string, c types.Completion) (types.Completion, error) {
+	return m.submitCompletionOverride(submitterID, c)
+}
+
+func (m *mockBackend) GetCompletion(ctx context.Context, id string) (types.Completion, error) {
+	return m.getCompletionOverride(id)
+}
+
+func (m *mockBackend) GetMemberCompletions(ctx context.Context, memberID string) ([]types.Completion, error) {
+	return m.getMemberCompletionsOverride(memberID)
+}
+
+func (m *mockBackend) GetPendingCompletions(ctx context.Context, certifierID string) ([]types.Completion, error) {
+	return m.getPendingCompletionsOverride(certifierID)
+}
+
+func (m *mockBackend) ReviewCompletion(ctx context.Context, certifierID, completionID string, approve bool, comments string) (types.Completion, error) {
+	return m.reviewCompletionOverride(certifierID, completionID, approve, comments)
+}
+
+func (m *mockBackend) GetPrerequisites(ctx context.Context, id string) ([]types.Qualification, error) {
+	return m.getPrerequisitesOverride(id)
+}
+
+func (m *mockBackend) GetMemberQualificationStatus(ctx context.Context, memberID, qualificationID string) (types.QualificationStatus, error) {
+	return m.getMemberQualificationStatusOverride(memberID, qualificationID)
+}
+
+func (m *mockBackend) GetMemberQualificationStatuses(ctx context.Context, memberID string) ([]types.QualificationStatus, error) {
+	return m.getMemberQualificationStatusesOverride(memberID)
+}
+
+func (m *mockBackend) GrantWaiver(ctx context.Context, approverID string, w types.Waiver) (types.Waiver, error) {
+	return m.grantWaiverOverride(approverID, w)
+}
+
+func (m *mockBackend) GetWaiver(ctx context.Context, id string) (types.Waiver, error) {
+	return m.getWaiverOverride(id)
+}
+
+func (m *mockBackend) GetMemberWaivers(ctx context.Context, memberID string) ([]types.Waiver, error) {
+	return m.getMemberWaiversOverride(memberID)
+}
+
+func (m *mockBackend) GetWaiverMemo(ctx context.Context, id string) (types.WaiverMemo, error) {
+	return m.getWaiverMemoOverride(id)
+}
+
+func (m *mockBackend) RevokeWaiver(ctx context.Context, actorID, id, reason string) (types.Waiver, error) {
+	return m.revokeWaiverOverride(actorID, id, reason)
+}
+
+func (m *mockBackend) GetAuditEntries(ctx context.Context, entityType, entityID string) ([]types.AuditEntry, error) {
+	return m.getAuditEntriesOverride(entityType, entityID)
+}
+
+func (m *mockBackend) AddDutyPosition(ctx context.Context, d types.DutyPosition) (types.DutyPosition, error) {
+	return m.addDutyPositionOverride(d)
+}
+
+func (m *mockBackend) GetDutyPosition(ctx context.Context, id string) (types.DutyPosition, error) {
+	return m.getDutyPositionOverride(id)
+}
+
+func (m *mockBackend) GetDutyPositions(ctx context.Context) ([]types.DutyPosition, error) {
+	return m.getDutyPositionsOverride()
+}
+
+func (m *mockBackend) UpdateDutyPosition(ctx context.Context, actorID string, d types.DutyPosition, apply bool) (types.DutyPositionDiff, error) {
+	return m.updateDutyPositionOverride(actorID, d, apply)
+}
+
+func (m *mockBackend) DeleteDutyPosition(ctx context.Context, id string, version int) error {
+	return m.deleteDutyPositionOverride(id)
+}
+
+func (m *mockBackend) AssignMemberDutyPosition(ctx context.Context, actorID string, memberID string, positionID string) ([]string, error) {
+	return m.assignMemberDutyPositionOverride(actorID, memberID, positionID)
+}
+
+func (m *mockBackend) GetMemberDutyPositions(ctx context.Context, memberID string) ([]types.DutyPosition, error) {
+	return m.getMemberDutyPositionsOverride(memberID)
+}
+
+func (m *mockBackend) RemoveMemberDutyPosition(ctx context.Context, actorID string, memberID string, positionID string, removeQualifications bool) (types.DutyPositionRemoval, error) {
+	return m.removeMemberDutyPositionOverride(actorID, memberID, positionID, removeQualifications)
+}
+
+func (m *mockBackend) BulkAssignQualifications(ctx context.Context, actorID string, req types.BulkQualificationRequest) ([]types.BulkItemResult, error) {
+	return m.bulkAssignQualificationsOverride(actorID, req)
+}
+
+func (m *mockBackend) BulkRemoveQualifications(ctx context.Context, actorID string, req types.BulkQualificationRequest) ([]types.BulkItemResult, error) {
+	return m.bulkRemoveQualificationsOverride(actorID, req)
+}
+
+func (m *mockBackend) ImportMembers(ctx context.Context, r io.Reader, dryRun bool) (types.MemberImportReport, error) {
+	return m.importMembersOverride(r, dryRun)
+}
+
+func (m *mockBackend) AddImportProfile(ctx context.Context, profile types.ImportProfile) (types.ImportProfile, error) {
+	return m.addImportProfileOverride(profile)
+}
+
+func (m *mockBackend) GetImportProfile(ctx context.Context, id string) (types.ImportProfile, error) {
+	return m.getImportProfileOverride(id)
+}
+
+func (m *mockBackend) GetImportProfiles(ctx context.Context) ([]types.ImportProfile, error) {
+	return m.getImportProfilesOverride()
+}
+
+func (m *mockBackend) DeleteImportProfile(ctx context.Context, id string) error {
+	return m.deleteImportProfileOverride(id)
+}
+
+func (m *mockBackend) StageCompletionImport(ctx context.Context, uploaderID string, profileID string, fileName string, r io.Reader) (types.ImportBatch, error) {
+	return m.stageCompletionImportOverride(uploaderID, profileID, fileName, r)
+}
+
+func (m *mockBackend) GetImportBatch(ctx context.Context, id string) (types.ImportBatch, error) {
+	return m.getImportBatchOverride(id)
+}
+
+func (m *mockBackend) UpdateStagedRow(ctx context.Context, batchID string, rowID string, update types.StagedRowUpdate) (types.StagedRow, error) {
+	return m.updateStagedRowOverride(batchID, rowID, update)
+}
+
+func (m *mockBackend) CommitImportBatch(ctx context.Context, actorID string, batchID string) (types.ImportBatch, error) {
+	return m.commitImportBatchOverride(actorID, batchID)
+}
+
+func (m *mockBackend) DiscardImportBatch(ctx context.Context, id string) error {
+	return m.discardImportBatchOverride(id)
+}
+
+func (m *mockBackend) GetQualificationHistory(ctx context.Context, memberID string, qualificationID string) ([]types.QualificationEvent, error) {
+	return m.getQualificationHistoryOverride(memberID, qualificationID)
+}
+
+func (m *mockBackend) ArchiveMember(ctx context.Context, actorID string, memberID string, archive types.MemberArchive) (types.Member, error) {
+	return m.archiveMemberOverride(actorID, memberID, archive)
+}
+
+func (m *mockBackend) RestoreMember(ctx context.Context, actorID string, memberID string) (types.Member, error) {
+	return m.restoreMemberOverride(actorID, memberID)
+}
+
+func (m *mockBackend) GetArchivedMembers(ctx context.Context) ([]types.Member, error) {
+	return m.getArchivedMembersOverride()
+}
+
+func (m *mockBackend) PurgeMember(ctx context.Context, actorID string, identifier string) error {
+	return m.purgeMemberOverride(actorID, identifier)
+}
+
+func (m *mockBackend) PurgeArchivedMembers(ctx context.Context, actorID string) ([]string, error) {
+	return m.purgeArchivedMembersOverride(actorID)
+}
+
+func (m *mockBackend) ExportMemberTransfer(ctx context.Context, actorID string, memberID string) (types.SignedTransferPackage, error) {
+	return m.exportMemberTransferOverride(actorID, memberID)
+}
+
+func (m *mockBackend) ImportMemberTransfer(ctx context.Context, actorID string, signed types.SignedTransferPackage) (types.TransferImportReport, error) {
+	return m.importMemberTransferOverride(actorID, signed)
+}
+
+func (m *mockBackend) SetMemberUnit(ctx context.Context, memberID string, unitID string) (types.Member, error) {
+	return m.setMemberUnitOverride(memberID, unitID)
+}
+
+func (m *mockBackend) AddUnit(ctx context.Context, u types.Unit) (types.Unit, error) {
+	return m.addUnitOverride(u)
+}
+
+func (m *mockBackend) GetUnit(ctx context.Context, id string) (types.Unit, error) {
+	return m.getUnitOverride(id)
+}
+
+func (m *mockBackend) GetUnits(ctx context.Context) ([]types.Unit, error) {
+	return m.getUnitsOverride()
+}
+
+func (m *mockBackend) UpdateUnit(ctx context.Context, u types.Unit) (types.Unit, error) {
+	return m.updateUnitOverride(u)
+}
+
+func (m *mockBackend) DeleteUnit(ctx context.Context, id string, version int) error {
+	return m.deleteUnitOverride(id)
+}
+
+func (m *mockBackend) GetUnitMembers(ctx context.Context, unitID string) ([]types.Member, error) {
+	return m.getUnitMembersOverride(unitID)
+}
+
+func (m *mockBackend) GetUnitReport(ctx context.Context, unitID string) (types.UnitReport, error) {
+	return m.getUnitReportOverride(unitID)
+}
+
+func (m *mockBackend) AddUnitAdmin(ctx context.Context, unitID string, memberID string) error {
+	return m.addUnitAdminOverride(unitID, memberID)
+}
+
+func (m *mockBackend) GetUnitAdmins(ctx context.Context, unitID string) ([]types.Member, error) {
+	return m.getUnitAdminsOverride(unitID)
+}
+
+func (m *mockBackend) RemoveUnitAdmin(ctx context.Context, unitID string, memberID string) error {
+	return m.removeUnitAdminOverride(unitID, memberID)
+}
+
+func (m *mockBackend) IsUnitAdmin(ctx context.Context, memberID string, unitID string) (bool, error) {
+	return m.isUnitAdminOverride(memberID, unitID)
+}
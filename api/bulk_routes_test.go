@@ -0,0 +1,89 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/backend"
+	"PORTal/types"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBulkAssignQualifications(t *testing.T) {
+	b := newMockBackend()
+	b.bulkAssignQualificationsOverride = func(actorID string, req types.BulkQualificationRequest) ([]types.BulkItemResult, error) {
+		if len(req.QualificationIDs) == 0 {
+			return nil, backend.ErrMissingArgs
+		}
+		if req.SupervisorID == "missing" {
+			return nil, backend.ErrSupervisorNotFound
+		}
+		return []types.BulkItemResult{
+			{MemberQualificationPair: types.MemberQualificationPair{MemberID: "a", QualificationID: "q"}, Status: types.BulkItemAssigned},
+			{MemberQualificationPair: types.MemberQualificationPair{MemberID: "b", QualificationID: "q"}, Status: types.BulkItemSkipped, Error: backend.ErrQualificationAlreadyAssigned.Error()},
+		}, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		cookie     *http.Cookie
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "Successful bulk assign",
+			cookie:     roleCookie(t, types.RoleSupervisor),
+			body:       `{"member_ids":["a","b"],"qualification_ids":["q"]}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Missing qualifications",
+			cookie:     roleCookie(t, types.RoleSupervisor),
+			body:       `{"member_ids":["a","b"]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Supervisor not found",
+			cookie:     roleCookie(t, types.RoleSupervisor),
+			body:       `{"supervisor_id":"missing","qualification_ids":["q"]}`,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Trainer can't assign",
+			cookie:     roleCookie(t, types.RoleTrainer),
+			body:       `{"member_ids":["a","b"],"qualification_ids":["q"]}`,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Malformed body",
+			cookie:     roleCookie(t, types.RoleSupervisor),
+			body:       `{"member_ids":`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/members/qualifications/assign", strings.NewReader(tt.body))
+			r.AddCookie(tt.cookie)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var results []types.BulkItemResult
+			if err := json.NewDecoder(w.Body).Decode(&results); err != nil {
+				t.Fatalf("Error decoding bulk results: %s", err.Error())
+			}
+			if len(results) != 2 || results[1].Status != types.BulkItemSkipped || results[1].Error == "" {
+				t.Errorf("Expected per-item results with a skipped item, got: %+v", results)
+			}
+		})
+	}
+}
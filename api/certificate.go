@@ -0,0 +1,103 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+var (
+	errNoCertificateIdentifier = errors.New("no identifier found in client certificate")
+
+	oidSubjectAltName = asn1.ObjectIdentifier{2, 5, 29, 17}
+	// oidUserPrincipalName is the Microsoft UPN otherName used by CACs to carry the EDIPI (e.g. 1234567890@mil).
+	oidUserPrincipalName = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3}
+)
+
+type otherName struct {
+	TypeID asn1.ObjectIdentifier
+	Value  asn1.RawValue `asn1:"tag:0,explicit"`
+}
+
+// TLSConfig builds the server TLS configuration. When ClientCAFile is set, client certificates are verified against
+// that bundle; they are only mandatory when RequireClientCert is true so password logins keep working.
+func (c Config) TLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.ClientCAFile == "" {
+		return tlsConfig, nil
+	}
+	pem, err := os.ReadFile(c.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading client CA bundle: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in client CA bundle %s", c.ClientCAFile)
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	if c.RequireClientCert {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// certificateIdentifier extracts the identifier a member is bound to from a client certificate. The UPN in the
+// subject alternative name is preferred, falling back to the trailing EDIPI in a LAST.FIRST.MI.EDIPI common name.
+func certificateIdentifier(cert *x509.Certificate) (string, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidSubjectAltName) {
+			continue
+		}
+		upn, err := userPrincipalName(ext.Value)
+		if err != nil {
+			return "", err
+		}
+		if upn != "" {
+			id, _, _ := strings.Cut(upn, "@")
+			return id, nil
+		}
+	}
+	parts := strings.Split(cert.Subject.CommonName, ".")
+	if last := parts[len(parts)-1]; last != "" && strings.IndexFunc(last, func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+		return last, nil
+	}
+	return "", errNoCertificateIdentifier
+}
+
+func userPrincipalName(san []byte) (string, error) {
+	var names asn1.RawValue
+	if _, err := asn1.Unmarshal(san, &names); err != nil {
+		return "", fmt.Errorf("error parsing subject alternative name: %w", err)
+	}
+	rest := names.Bytes
+	for len(rest) > 0 {
+		var name asn1.RawValue
+		var err error
+		rest, err = asn1.Unmarshal(rest, &name)
+		if err != nil {
+			return "", fmt.Errorf("error parsing general name: %w", err)
+		}
+		if name.Class != asn1.ClassContextSpecific || name.Tag != 0 {
+			continue
+		}
+		var on otherName
+		if _, err = asn1.UnmarshalWithParams(name.FullBytes, &on, "tag:0"); err != nil {
+			return "", fmt.Errorf("error parsing other name: %w", err)
+		}
+		if !on.TypeID.Equal(oidUserPrincipalName) {
+			continue
+		}
+		var upn string
+		if _, err = asn1.UnmarshalWithParams(on.Value.Bytes, &upn, "utf8"); err != nil {
+			return "", fmt.Errorf("error parsing user principal name: %w", err)
+		}
+		return upn, nil
+	}
+	return "", nil
+}
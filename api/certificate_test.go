@@ -0,0 +1,49 @@
+package api
+
+import (
+	"PORTal/testutils"
+	"errors"
+	"testing"
+)
+
+func TestCertificateIdentifier(t *testing.T) {
+	tc := []struct {
+		Name          string
+		CommonName    string
+		UPN           string
+		ExpectedID    string
+		ExpectedError error
+	}{
+		{
+			Name:       "EDIPI from UPN",
+			CommonName: "DOE.JOHN.A.1234567890",
+			UPN:        "1098765432@mil",
+			ExpectedID: "1098765432",
+		},
+		{
+			Name:       "EDIPI from common name",
+			CommonName: "DOE.JOHN.A.1234567890",
+			ExpectedID: "1234567890",
+		},
+		{
+			Name:          "No identifier",
+			CommonName:    "John Doe",
+			ExpectedError: errNoCertificateIdentifier,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.Name, func(t *testing.T) {
+			cert, _ := testutils.GenerateClientCertificate(t, tt.CommonName, tt.UPN)
+			id, err := certificateIdentifier(cert)
+			if tt.ExpectedError != nil && !errors.Is(err, tt.ExpectedError) {
+				t.Errorf("Expected error: %v, got: %v", tt.ExpectedError, err)
+			}
+			if tt.ExpectedError == nil && err != nil {
+				t.Errorf("Expected no error but got: %s", err.Error())
+			}
+			if id != tt.ExpectedID {
+				t.Errorf("Expected identifier: %s, got: %s", tt.ExpectedID, id)
+			}
+		})
+	}
+}
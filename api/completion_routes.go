@@ -0,0 +1,179 @@
+package api
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+func (s Server) submitCompletion(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	var req CompletionRequest
+	// The body is optional, a bare POST submits a completion dated now with no trainer
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid completion JSON sent from client", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	c, err := s.backendFor(r).SubmitCompletion(r.Context(), caller.MemberID, types.Completion{
+		MemberID:      r.PathValue("id"),
+		RequirementID: r.PathValue("reqID"),
+		TrainerID:     req.TrainerID,
+		CompletedDate: req.CompletedDate,
+		Comments:      req.Comments,
+	})
+	if errors.Is(err, backend.ErrMemberNotFound) || errors.Is(err, backend.ErrRequirementNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrMissingArgs) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(c); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing completion to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getCompletion(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	c, err := s.backendFor(r).GetCompletion(r.Context(), r.PathValue("id"))
+	if errors.Is(err, backend.ErrCompletionNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(c); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing completion to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getMemberCompletions(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	completions, err := s.backendFor(r).GetMemberCompletions(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(completions); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing completions to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getPendingCompletions(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	completions, err := s.backendFor(r).GetPendingCompletions(r.Context(), caller.MemberID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(completions); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing completions to client", slog.String("error", err.Error()))
+	}
+}
+
+// reviewCompletion returns the handler that approves or rejects the {id} completion on behalf of the caller.
+func (s Server) reviewCompletion(approve bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+		caller, status := s.requestIdentity(r)
+		if status != http.StatusOK {
+			writeError(w, status, errUnauthenticated)
+			return
+		}
+		var req ReviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid review JSON sent from client", slog.String("error", err.Error()))
+			writeError(w, http.StatusBadRequest, invalidBody(err))
+			return
+		}
+		defer r.Body.Close()
+		c, err := s.backendFor(r).ReviewCompletion(r.Context(), caller.MemberID, r.PathValue("id"), approve, req.Comments)
+		if errors.Is(err, backend.ErrCompletionNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		} else if errors.Is(err, backend.ErrInsufficientPermissions) || errors.Is(err, backend.ErrSelfCertification) {
+			writeError(w, http.StatusForbidden, err)
+			return
+		} else if errors.Is(err, backend.ErrCompletionAlreadyReviewed) {
+			writeError(w, http.StatusConflict, err)
+			return
+		} else if errors.Is(err, backend.ErrMissingArgs) {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		} else if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(c); err != nil {
+			l.LogAttrs(r.Context(), slog.LevelError, "Error serializing completion to client", slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (s Server) getCertifiers(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	certifiers, err := s.backendFor(r).GetCertifiers(r.Context(), r.PathValue("id"))
+	if errors.Is(err, backend.ErrRequirementNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	apiCertifiers := make([]types.ApiMember, 0, len(certifiers))
+	for _, c := range certifiers {
+		apiCertifiers = append(apiCertifiers, c.ToApiMember())
+	}
+	if err = json.NewEncoder(w).Encode(apiCertifiers); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing certifiers to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) addCertifier(w http.ResponseWriter, r *http.Request) {
+	err := s.backendFor(r).AddCertifier(r.Context(), r.PathValue("id"), r.PathValue("memberID"))
+	if errors.Is(err, backend.ErrMemberNotFound) || errors.Is(err, backend.ErrRequirementNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrCertifierAlreadyDesignated) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s Server) removeCertifier(w http.ResponseWriter, r *http.Request) {
+	err := s.backendFor(r).RemoveCertifier(r.Context(), r.PathValue("id"), r.PathValue("memberID"))
+	if errors.Is(err, backend.ErrCertifierNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
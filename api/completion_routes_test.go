@@ -0,0 +1,180 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/backend"
+	"PORTal/types"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSubmitCompletion(t *testing.T) {
+	b := newMockBackend()
+	b.submitCompletionOverride = func(submitterID string, c types.Completion) (types.Completion, error) {
+		if c.RequirementID == "missing" {
+			return types.Completion{}, backend.ErrRequirementNotFound
+		}
+		c.SubmittedBy = submitterID
+		c.Status = types.CompletionPending
+		return c, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		cookie     *http.Cookie
+		memberID   string
+		reqID      string
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "Trainer submits for trainee",
+			cookie:     roleCookie(t, types.RoleTrainer),
+			memberID:   uuid.NewString(),
+			reqID:      uuid.NewString(),
+			body:       `{"completed_date":"2024-03-01T00:00:00Z"}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "Empty body",
+			cookie:     roleCookie(t, types.RoleTrainer),
+			memberID:   uuid.NewString(),
+			reqID:      uuid.NewString(),
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "Member submits for someone else",
+			cookie:     roleCookie(t, types.RoleMember),
+			memberID:   uuid.NewString(),
+			reqID:      uuid.NewString(),
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Requirement not found",
+			cookie:     roleCookie(t, types.RoleTrainer),
+			memberID:   uuid.NewString(),
+			reqID:      "missing",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Malformed body",
+			cookie:     roleCookie(t, types.RoleTrainer),
+			memberID:   uuid.NewString(),
+			reqID:      uuid.NewString(),
+			body:       `{"completed_date":`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/member/%s/requirement/%s/completion", tt.memberID, tt.reqID), strings.NewReader(tt.body))
+			r.AddCookie(tt.cookie)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if w.Code != http.StatusCreated {
+				return
+			}
+			var c types.Completion
+			if err := json.NewDecoder(w.Body).Decode(&c); err != nil {
+				t.Fatalf("Error decoding completion: %s", err.Error())
+			}
+			if c.Status != types.CompletionPending || c.MemberID != tt.memberID || c.RequirementID != tt.reqID {
+				t.Errorf("Expected pending completion for member and requirement, got: %+v", c)
+			}
+		})
+	}
+}
+
+func TestReviewCompletion(t *testing.T) {
+	b := newMockBackend()
+	b.reviewCompletionOverride = func(certifierID, completionID string, approve bool, comments string) (types.Completion, error) {
+		switch completionID {
+		case "missing":
+			return types.Completion{}, backend.ErrCompletionNotFound
+		case "not-certifier":
+			return types.Completion{}, fmt.Errorf("%w: %w", backend.ErrInsufficientPermissions, backend.ErrCertifierNotFound)
+		case "own":
+			return types.Completion{}, backend.ErrSelfCertification
+		case "reviewed":
+			return types.Completion{}, backend.ErrCompletionAlreadyReviewed
+		}
+		if !approve && comments == "" {
+			return types.Completion{}, backend.ErrMissingArgs
+		}
+		return types.Completion{ID: completionID, CertifierID: certifierID, Status: types.CompletionApproved}, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name         string
+		completionID string
+		action       string
+		body         string
+		statusCode   int
+	}{
+		{
+			name:         "Successful approval",
+			completionID: uuid.NewString(),
+			action:       "approve",
+			statusCode:   http.StatusOK,
+		},
+		{
+			name:         "Successful rejection",
+			completionID: uuid.NewString(),
+			action:       "reject",
+			body:         `{"comments":"Redo the practical"}`,
+			statusCode:   http.StatusOK,
+		},
+		{
+			name:         "Rejection without comments",
+			completionID: uuid.NewString(),
+			action:       "reject",
+			statusCode:   http.StatusBadRequest,
+		},
+		{
+			name:         "Completion not found",
+			completionID: "missing",
+			action:       "approve",
+			statusCode:   http.StatusNotFound,
+		},
+		{
+			name:         "Not a certifier",
+			completionID: "not-certifier",
+			action:       "approve",
+			statusCode:   http.StatusForbidden,
+		},
+		{
+			name:         "Own completion",
+			completionID: "own",
+			action:       "approve",
+			statusCode:   http.StatusForbidden,
+		},
+		{
+			name:         "Already reviewed",
+			completionID: "reviewed",
+			action:       "approve",
+			statusCode:   http.StatusConflict,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/completion/%s/%s", tt.completionID, tt.action), strings.NewReader(tt.body))
+			r.AddCookie(roleCookie(t, types.RoleTrainer))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
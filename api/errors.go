@@ -0,0 +1,152 @@
+package api
+
+import (
+	"PORTal/backend"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	errInvalidBody          = errors.New("request body couldn't be read")
+	errInvalidID            = errors.New("id isn't a valid uuid")
+	errInvalidQuery         = errors.New("invalid query parameter")
+	errPreconditionRequired = errors.New("request must say which version it changes")
+	errUnauthenticated      = errors.New("request isn't authenticated")
+	errUnsupportedMediaType = errors.New("unsupported content type")
+)
+
+// Problem is the RFC 9457 problem details body of every error response. Code is stable so clients can branch on it,
+// while Title and Detail are for people.
+type Problem struct {
+	Code   string `json:"code"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Fields are the request fields that were missing, when Code is missing_args.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// problemCodes maps each error a handler can respond with to its code. Codes are part of the api, so once released
+// they must not change.
+var problemCodes = []struct {
+	err  error
+	code string
+}{
+	{errInvalidBody, "invalid_body"},
+	{errInvalidID, "invalid_id"},
+	{errInvalidQuery, "invalid_query"},
+	{errPreconditionRequired, "precondition_required"},
+	{errUnauthenticated, "unauthenticated"},
+	{errUnsupportedMediaType, "unsupported_media_type"},
+	{backend.ErrAPITokenNotFound, "api_token_not_found"},
+	{backend.ErrAuthenticationFailed, "authentication_failed"},
+	{backend.ErrBadUpdate, "bad_update"},
+	{backend.ErrCertifierAlreadyDesignated, "certifier_already_designated"},
+	{backend.ErrCertifierNotFound, "certifier_not_found"},
+	{backend.ErrCompletionAlreadyReviewed, "completion_already_reviewed"},
+	{backend.ErrCompletionNotFound, "completion_not_found"},
+	{backend.ErrDuplicateCertificate, "duplicate_certificate"},
+	{backend.ErrDuplicateDutyPosition, "duplicate_duty_position"},
+	{backend.ErrDuplicateImportProfile, "duplicate_import_profile"},
+	{backend.ErrDuplicateReference, "duplicate_reference"},
+	{backend.ErrDuplicateRequirement, "duplicate_requirement"},
+	{backend.ErrDuplicateTenant, "duplicate_tenant"},
+	{backend.ErrDuplicateUsername, "duplicate_username"},
+	{backend.ErrDutyPositionAlreadyAssigned, "duty_position_already_assigned"},
+	{backend.ErrDutyPositionNotFound, "duty_position_not_found"},
+	{backend.ErrImportBatchCommitted, "import_batch_committed"},
+	{backend.ErrImportBatchNotFound, "import_batch_not_found"},
+	{backend.ErrImportProfileNotFound, "import_profile_not_found"},
+	{backend.ErrImportRowNotFound, "import_row_not_found"},
+	{backend.ErrInsufficientPermissions, "insufficient_permissions"},
+	{backend.ErrInvalidImport, "invalid_import"},
+	{backend.ErrInvalidListOptions, "invalid_list_options"},
+	{backend.ErrInvalidPatch, "invalid_patch"},
+	{backend.ErrInvalidPermission, "invalid_permission"},
+	{backend.ErrInvalidQualExpiration, "invalid_qualification_expiration"},
+	{backend.ErrInvalidRole, "invalid_role"},
+	{backend.ErrInvalidTenant, "invalid_tenant"},
+	{backend.ErrInvalidTokenScope, "invalid_token_scope"},
+	{backend.ErrInvalidTransfer, "invalid_transfer"},
+	{backend.ErrInvalidTransferSignature, "invalid_transfer_signature"},
+	{backend.ErrInvalidUnit, "invalid_unit"},
+	{backend.ErrInvalidWaiver, "invalid_waiver"},
+	{backend.ErrMemberArchived, "member_archived"},
+	{backend.ErrMemberDutyPositionNotFound, "member_duty_position_not_found"},
+	{backend.ErrMemberNotArchived, "member_not_archived"},
+	{backend.ErrMemberNotFound, "member_not_found"},
+	{backend.ErrMemberQualificationNotFound, "member_qualification_not_found"},
+	{backend.ErrMissingArgs, "missing_args"},
+	{backend.ErrPasswordTooLong, "password_too_long"},
+	{backend.ErrPrerequisiteCycle, "prerequisite_cycle"},
+	{backend.ErrQualificationAlreadyAssigned, "qualification_already_assigned"},
+	{backend.ErrQualificationNotFound, "qualification_not_found"},
+	{backend.ErrReferenceNotFound, "reference_not_found"},
+	{backend.ErrRequirementInUse, "requirement_in_use"},
+	{backend.ErrRequirementNotFound, "requirement_not_found"},
+	{backend.ErrRetentionPeriod, "within_retention_period"},
+	{backend.ErrSelfCertification, "self_certification"},
+	{backend.ErrSessionValidationFailed, "session_validation_failed"},
+	{backend.ErrSupervisorNotFound, "supervisor_not_found"},
+	{backend.ErrTenantNotFound, "tenant_not_found"},
+	{backend.ErrTenantsNotEnabled, "tenants_not_enabled"},
+	{backend.ErrTransferAlreadyImported, "transfer_already_imported"},
+	{backend.ErrTransferKeyNotConfigured, "transfer_key_not_configured"},
+	{backend.ErrUnitAdminAlreadyDesignated, "unit_admin_already_designated"},
+	{backend.ErrUnitAdminNotFound, "unit_admin_not_found"},
+	{backend.ErrUnitHasSubunits, "unit_has_subunits"},
+	{backend.ErrUnitNotFound, "unit_not_found"},
+	{backend.ErrVersionConflict, "version_conflict"},
+	{backend.ErrWaiverMemoNotFound, "waiver_memo_not_found"},
+	{backend.ErrWaiverNotFound, "waiver_not_found"},
+	{backend.ErrWeakPassword, "weak_password"},
+}
+
+// invalidBody wraps a decoding error so the client is told what was wrong with what it sent.
+func invalidBody(err error) error {
+	return fmt.Errorf("%w: %s", errInvalidBody, err.Error())
+}
+
+// invalidQuery is returned when the named query parameter can't be parsed.
+func invalidQuery(name string) error {
+	return fmt.Errorf("%w: %s", errInvalidQuery, name)
+}
+
+// problemFor builds the body describing err. Server errors never include the underlying error, which may leak
+// details about the database.
+func problemFor(status int, err error) Problem {
+	p := Problem{
+		Code:   strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_"),
+		Title:  http.StatusText(status),
+		Status: status,
+	}
+	if status >= http.StatusInternalServerError || err == nil {
+		return p
+	}
+	for _, c := range problemCodes {
+		if errors.Is(err, c.err) {
+			p.Code = c.code
+			p.Title = c.err.Error()
+			break
+		}
+	}
+	if detail := err.Error(); detail != p.Title {
+		p.Detail = detail
+	}
+	var missing backend.MissingArgsError
+	if errors.As(err, &missing) {
+		p.Fields = missing.Fields
+	}
+	return p
+}
+
+// writeError responds with status and a problem details body describing err. Every handler reports errors through
+// here so the UI can tell failures apart.
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(problemFor(status, err))
+}
@@ -0,0 +1,183 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/backend"
+	"PORTal/types"
+	"encoding/json"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestProblemCodesCoverErrors makes sure a new backend error can't be added without giving it a code for the UI.
+func TestProblemCodesCoverErrors(t *testing.T) {
+	backendErrors, err := parser.ParseFile(token.NewFileSet(), "../backend/errors.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Error parsing backend/errors.go: %s", err.Error())
+	}
+	apiErrors, err := parser.ParseFile(token.NewFileSet(), "errors.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Error parsing api/errors.go: %s", err.Error())
+	}
+	mapped := map[string]bool{}
+	codes := map[string]string{}
+	ast.Inspect(apiErrors, func(n ast.Node) bool {
+		lit, ok := n.(*ast.CompositeLit)
+		if !ok || len(lit.Elts) != 2 {
+			return true
+		}
+		code, ok := lit.Elts[1].(*ast.BasicLit)
+		if !ok || code.Kind != token.STRING {
+			return true
+		}
+		var name string
+		switch e := lit.Elts[0].(type) {
+		case *ast.SelectorExpr:
+			name = e.Sel.Name
+			mapped[name] = true
+		case *ast.Ident:
+			name = e.Name
+		default:
+			return true
+		}
+		c, _ := strconv.Unquote(code.Value)
+		if other, ok := codes[c]; ok {
+			t.Errorf("Code %q is used by both %s and %s", c, other, name)
+		}
+		codes[c] = name
+		return true
+	})
+	for _, decl := range backendErrors.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				if strings.HasPrefix(name.Name, "Err") && !mapped[name.Name] {
+					t.Errorf("backend.%s has no problem code", name.Name)
+				}
+			}
+		}
+	}
+}
+
+func TestErrorResponses(t *testing.T) {
+	b := newMockBackend()
+	b.addMemberOverride = func(m types.Member) (types.Member, error) {
+		switch m.FirstName {
+		case "duplicate":
+			return types.Member{}, backend.ErrDuplicateUsername
+		case "bad":
+			return types.Member{}, errors.New("connection refused by db.internal")
+		}
+		return m, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		anonymous  bool
+		statusCode int
+		code       string
+		fields     []string
+	}{
+		{
+			name:       "Missing fields",
+			method:     http.MethodPost,
+			path:       "/api/member",
+			body:       `{"rank":"TSgt"}`,
+			statusCode: http.StatusBadRequest,
+			code:       "missing_args",
+			fields:     []string{"FirstName", "LastName"},
+		},
+		{
+			name:       "Duplicate username",
+			method:     http.MethodPost,
+			path:       "/api/member",
+			body:       `{"first_name":"duplicate","last_name":"member","rank":"TSgt"}`,
+			statusCode: http.StatusConflict,
+			code:       "duplicate_username",
+		},
+		{
+			name:       "Malformed body",
+			method:     http.MethodPost,
+			path:       "/api/member",
+			body:       `{"first_name":`,
+			statusCode: http.StatusBadRequest,
+			code:       "invalid_body",
+		},
+		{
+			name:       "Invalid ID",
+			method:     http.MethodGet,
+			path:       "/api/member/not-a-uuid",
+			statusCode: http.StatusBadRequest,
+			code:       "invalid_id",
+		},
+		{
+			name:       "Invalid query",
+			method:     http.MethodGet,
+			path:       "/api/members?limit=none",
+			statusCode: http.StatusBadRequest,
+			code:       "invalid_query",
+		},
+		{
+			name:       "Unauthenticated",
+			method:     http.MethodGet,
+			path:       "/api/members",
+			anonymous:  true,
+			statusCode: http.StatusUnauthorized,
+			code:       "unauthenticated",
+		},
+		{
+			name:       "Server error",
+			method:     http.MethodPost,
+			path:       "/api/member",
+			body:       `{"first_name":"bad","last_name":"member","rank":"TSgt"}`,
+			statusCode: http.StatusInternalServerError,
+			code:       "internal_server_error",
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if !tt.anonymous {
+				r.AddCookie(adminCookie(t))
+			}
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if contentType := w.Header().Get("Content-Type"); contentType != "application/problem+json" {
+				t.Errorf("Expected problem details, got %q", contentType)
+			}
+			var p api.Problem
+			if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+				t.Fatalf("Error decoding problem: %s", err.Error())
+			}
+			if p.Code != tt.code || p.Status != tt.statusCode {
+				t.Errorf("Expected %s (%d), got %s (%d)", tt.code, tt.statusCode, p.Code, p.Status)
+			}
+			if !slices.Equal(p.Fields, tt.fields) {
+				t.Errorf("Expected fields %v, got %v", tt.fields, p.Fields)
+			}
+			if tt.statusCode >= http.StatusInternalServerError && p.Detail != "" {
+				t.Errorf("Server errors shouldn't leak details, got %q", p.Detail)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"PORTal/backend"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// versionTag is the strong ETag of a record at version, which GET, PUT and PATCH routes return so clients can send it
+// back as If-Match.
+func versionTag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// setVersionTag sets the ETag header of a response carrying a record at version.
+func setVersionTag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", versionTag(version))
+}
+
+// ifMatch reads the version a PUT, PATCH or DELETE was made against from its If-Match header, which is required so
+// two people editing the same record can't silently overwrite each other. * gives version zero, which overwrites
+// whatever is there. Only a single ETag is understood; anything else can't match.
+func ifMatch(r *http.Request) (int, int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, http.StatusPreconditionRequired, fmt.Errorf("%w: send the ETag the record was read with as If-Match, or * to overwrite it", errPreconditionRequired)
+	}
+	if header == "*" {
+		return 0, http.StatusOK, nil
+	}
+	version, err := strconv.Atoi(strings.Trim(header, `"`))
+	if err != nil || version < 1 || header != versionTag(version) {
+		return 0, http.StatusPreconditionFailed, fmt.Errorf("%w: If-Match %s isn't an ETag from this api", backend.ErrVersionConflict, header)
+	}
+	return version, http.StatusOK, nil
+}
+
+// writeTagged encodes a list with an ETag hashing its JSON, so the UI can poll with If-None-Match and get a bodiless
+// 304 Not Modified until something in the list changes.
+func writeTagged(w http.ResponseWriter, r *http.Request, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(body)
+	tag := fmt.Sprintf(`"%x"`, sum[:16])
+	w.Header().Set("ETag", tag)
+	if noneMatch(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	_, err = w.Write(append(body, '\n'))
+	return err
+}
+
+// noneMatch reports whether an If-None-Match header matches tag. Weak tags match their strong counterparts, as they
+// do for GET requests.
+func noneMatch(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/backend"
+	"PORTal/types"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestQualificationVersionPreconditions(t *testing.T) {
+	const stored = 3
+	b := newMockBackend()
+	b.getQualificationOverride = func(id string) (types.Qualification, error) {
+		return types.Qualification{ID: id, Name: "Qual", Version: stored}, nil
+	}
+	b.updateQualificationOverride = func(q types.Qualification, forceUpdateExpiration bool) (types.Qualification, error) {
+		if q.Version != 0 && q.Version != stored {
+			return types.Qualification{}, fmt.Errorf("%w: at version %d, not %d", backend.ErrVersionConflict, stored, q.Version)
+		}
+		q.Version = stored + 1
+		return q, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/qualification/qual", nil)
+	r.AddCookie(adminCookie(t))
+	s.ServeHTTP(w, r)
+	if etag := w.Header().Get("ETag"); etag != `"3"` {
+		t.Errorf(`Expected ETag "3" getting qualification, got %s`, etag)
+	}
+
+	tc := []struct {
+		name       string
+		ifMatch    string
+		statusCode int
+		etag       string
+	}{
+		{name: "Current version", ifMatch: `"3"`, statusCode: http.StatusOK, etag: `"4"`},
+		{name: "Any version", ifMatch: "*", statusCode: http.StatusOK, etag: `"4"`},
+		{name: "Stale version", ifMatch: `"2"`, statusCode: http.StatusPreconditionFailed},
+		{name: "Unquoted version", ifMatch: "3", statusCode: http.StatusPreconditionFailed},
+		{name: "Weak ETag", ifMatch: `W/"3"`, statusCode: http.StatusPreconditionFailed},
+		{name: "Missing If-Match", statusCode: http.StatusPreconditionRequired},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, "/api/qualification/qual", strings.NewReader(`{"id":"qual","name":"Qual","notes":"changed"}`))
+			if tt.ifMatch != "" {
+				r.Header.Set("If-Match", tt.ifMatch)
+			}
+			r.AddCookie(adminCookie(t))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected response code %d, got %d: %s", tt.statusCode, w.Code, w.Body.String())
+			}
+			if etag := w.Header().Get("ETag"); etag != tt.etag {
+				t.Errorf("Expected ETag %q, got %q", tt.etag, etag)
+			}
+		})
+	}
+}
+
+func TestListNotModified(t *testing.T) {
+	b := newMockBackend()
+	b.listQualificationsOverride = func(opts types.ListOptions) (types.Page[types.Qualification], error) {
+		return types.Page[types.Qualification]{Items: []types.Qualification{{ID: "qual", Name: "Qual", Version: 1}}, Total: 1}, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+	list := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/qualifications", nil)
+		if ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		r.AddCookie(adminCookie(t))
+		s.ServeHTTP(w, r)
+		return w
+	}
+
+	first := list("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || first.Body.Len() == 0 {
+		t.Fatalf("Expected a tagged list, got %d with ETag %q", first.Code, etag)
+	}
+	for _, ifNoneMatch := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		if w := list(ifNoneMatch); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("Expected 304 without a body for If-None-Match %s, got %d", ifNoneMatch, w.Code)
+		}
+	}
+	if w := list(`"other"`); w.Code != http.StatusOK || w.Header().Get("ETag") != etag {
+		t.Errorf("Expected the list again for a different ETag, got %d", w.Code)
+	}
+
+	b.listQualificationsOverride = func(opts types.ListOptions) (types.Page[types.Qualification], error) {
+		return types.Page[types.Qualification]{Items: []types.Qualification{{ID: "qual", Name: "Qual", Version: 2}}, Total: 1}, nil
+	}
+	if w := list(etag); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("Expected a changed list to get a new ETag, got %d with %s", w.Code, w.Header().Get("ETag"))
+	}
+}
@@ -17,7 +17,7 @@ func (s Server) frontendHandler(source string) func(w http.ResponseWriter, r *ht
 			if err != nil {
 				if !os.IsNotExist(err) {
 					s.logger.LogAttrs(r.Context(), slog.LevelError, "Error when statting file", slog.String("error", err.Error()))
-					w.WriteHeader(http.StatusInternalServerError)
+					writeError(w, http.StatusInternalServerError, err)
 					return
 				}
 				// Requested file does not exist so we return the default (resolves to index.html)
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+type identityKey struct{}
+
+// identity is the authenticated caller of a request, resolved from either the identity cookie or an api token.
+type identity struct {
+	MemberID    string
+	Admin       bool
+	Role        types.Role
+	Permissions []types.Permission
+	// Scope is empty when the request was authenticated with the identity cookie.
+	Scope types.TokenScope
+}
+
+func (i identity) can(permission types.Permission) bool {
+	return slices.Contains(i.Permissions, permission)
+}
+
+// scoped reports whether the caller's api token scope covers the permission, which is always true for the identity
+// cookie. It keeps tokens from doing to their own member what the scope wouldn't let them do to anyone else.
+func (i identity) scoped(permission types.Permission) bool {
+	return i.Scope == "" || slices.Contains(types.ScopePermissions[i.Scope], permission)
+}
+
+// authenticateBearer resolves an Authorization: Bearer token before routing so every handler sees the same identity
+// and read-only tokens can't reach mutating routes.
+func (s Server) authenticateBearer(r *http.Request) (*http.Request, int) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return r, http.StatusOK
+	}
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		s.logger.LogAttrs(r.Context(), slog.LevelInfo, "Malformed authorization header")
+		return r, http.StatusUnauthorized
+	}
+	t, member, err := s.backendFor(r).AuthenticateAPIToken(r.Context(), token)
+	if err != nil {
+		return r, http.StatusUnauthorized
+	}
+	if t.Scope == types.ScopeReadOnly && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.logger.LogAttrs(r.Context(), slog.LevelInfo, "Read-only api token used for mutating request", slog.String("token_id", t.ID))
+		return r, http.StatusForbidden
+	}
+	permissions, err := s.backendFor(r).GetRolePermissions(r.Context(), member.Role)
+	if err != nil {
+		return r, http.StatusInternalServerError
+	}
+	// A token can only do what both its scope and its member's role allow
+	permissions = slices.DeleteFunc(slices.Clone(permissions), func(p types.Permission) bool {
+		return !slices.Contains(types.ScopePermissions[t.Scope], p)
+	})
+	id := identity{
+		MemberID:    member.ID,
+		Admin:       member.Admin && t.Scope == types.ScopeAdmin,
+		Role:        member.Role,
+		Permissions: permissions,
+		Scope:       t.Scope,
+	}
+	return r.WithContext(context.WithValue(r.Context(), identityKey{}, id)), http.StatusOK
+}
+
+// requestIdentity returns the caller of the request, preferring an api token resolved by authenticateBearer and
+// otherwise validating the identity cookie. The cookie only says who the caller was at login, so their role and archive
+// state are re-read and a demotion or archival takes effect on the next request. The status code to respond with is
+// returned when there is no valid caller.
+func (s Server) requestIdentity(r *http.Request) (identity, int) {
+	if id, ok := r.Context().Value(identityKey{}).(identity); ok {
+		return id, http.StatusOK
+	}
+	tokenCookie, err := r.Cookie(JWTCookieName)
+	if err != nil {
+		s.logger.LogAttrs(r.Context(), slog.LevelWarn, "Error getting token cookie", slog.String("error", err.Error()))
+		return identity{}, http.StatusUnauthorized
+	}
+	token, err := validateToken(r.Context(), tokenCookie.Value, s.jwtKeyFunc, s.logger)
+	if err != nil {
+		return identity{}, http.StatusUnauthorized
+	}
+	customClaims, ok := token.Claims.(*CustomClaims)
+	if !ok {
+		s.logger.LogAttrs(r.Context(), slog.LevelError, "Error casting claims to CustomClaims")
+		return identity{}, http.StatusInternalServerError
+	}
+	if s.tenants != nil && customClaims.Tenant != tenantOf(r) {
+		s.logger.LogAttrs(r.Context(), slog.LevelWarn, "Identity cookie issued for a different tenant",
+			slog.String("token_tenant", customClaims.Tenant), slog.String("request_tenant", tenantOf(r)))
+		return identity{}, http.StatusUnauthorized
+	}
+	member, err := s.backendFor(r).GetMember(r.Context(), customClaims.Subject)
+	if errors.Is(err, backend.ErrMemberNotFound) {
+		s.logger.LogAttrs(r.Context(), slog.LevelWarn, "Identity cookie for a member that no longer exists", slog.String("member_id", customClaims.Subject))
+		return identity{}, http.StatusUnauthorized
+	} else if err != nil {
+		s.logger.LogAttrs(r.Context(), slog.LevelError, "Error getting member for identity cookie", slog.String("error", err.Error()))
+		return identity{}, http.StatusInternalServerError
+	}
+	if member.Archive != nil {
+		s.logger.LogAttrs(r.Context(), slog.LevelWarn, "Identity cookie for an archived member", slog.String("member_id", member.ID))
+		return identity{}, http.StatusUnauthorized
+	}
+	permissions, err := s.backendFor(r).GetRolePermissions(r.Context(), member.Role)
+	if err != nil {
+		s.logger.LogAttrs(r.Context(), slog.LevelError, "Error getting permissions for identity cookie", slog.String("error", err.Error()))
+		return identity{}, http.StatusInternalServerError
+	}
+	return identity{
+		MemberID:    member.ID,
+		Admin:       member.Admin,
+		Role:        member.Role,
+		Permissions: permissions,
+	}, http.StatusOK
+}
+
+// authenticated only passes requests with a valid caller through to the handler.
+func (s Server) authenticated(h http.HandlerFunc) http.Handler {
+	return s.authorized(func(identity, *http.Request) bool { return true }, h)
+}
+
+// requirePermission only passes requests through when the caller's role grants the permission.
+func (s Server) requirePermission(permission types.Permission, h http.HandlerFunc) http.Handler {
+	return s.authorized(func(id identity, _ *http.Request) bool { return id.can(permission) }, h)
+}
+
+// requireSelfOrPermission lets members act on their own {id} without holding the permission, as long as an api token's
+// scope would allow it.
+func (s Server) requireSelfOrPermission(permission types.Permission, h http.HandlerFunc) http.Handler {
+	return s.authorized(func(id identity, r *http.Request) bool {
+		return (id.MemberID == r.PathValue("id") && id.scoped(permission)) || id.can(permission)
+	}, h)
+}
+
+// requirePermissionOrUnitAdmin also lets admins of the unit returned by unitID, or of any unit above it, through without
+// holding the permission.
+func (s Server) requirePermissionOrUnitAdmin(permission types.Permission, unitID func(*http.Request) string, h http.HandlerFunc) http.Handler {
+	return s.authorized(func(id identity, r *http.Request) bool {
+		if id.can(permission) {
+			return true
+		}
+		admin, err := s.backendFor(r).IsUnitAdmin(r.Context(), id.MemberID, unitID(r))
+		if err != nil {
+			s.logger.LogAttrs(r.Context(), slog.LevelError, "Error checking unit admin", slog.String("error", err.Error()))
+		}
+		return admin
+	}, h)
+}
+
+// pathUnit is the {id} of unit routes.
+func pathUnit(r *http.Request) string {
+	return r.PathValue("id")
+}
+
+func (s Server) authorized(allowed func(identity, *http.Request) bool, h http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, status := s.requestIdentity(r)
+		if status != http.StatusOK {
+			writeError(w, status, errUnauthenticated)
+			return
+		}
+		if !allowed(id, r) {
+			s.logger.LogAttrs(r.Context(), slog.LevelInfo, "Member not permitted to access route",
+				slog.String("member_id", id.MemberID), slog.String("role", string(id.Role)), slog.String("path", r.URL.Path))
+			writeError(w, http.StatusForbidden, backend.ErrInsufficientPermissions)
+			return
+		}
+		h(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, id)))
+	})
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+)
+
+// importMembers accepts the roster either as the raw request body or as the "file" field of a multipart form.
+// ?dry_run=true only validates it. The row-level report is returned whether or not the import is valid.
+func (s Server) importMembers(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	dryRun, ok := boolQuery(r, "dry_run")
+	if !ok {
+		writeError(w, http.StatusBadRequest, invalidQuery("dry_run"))
+		return
+	}
+	body, _, err := uploadedFile(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Missing file in member import form", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer body.Close()
+	report, err := s.backendFor(r).ImportMembers(r.Context(), body, dryRun)
+	status := http.StatusOK
+	if errors.Is(err, backend.ErrInvalidImport) || errors.Is(err, backend.ErrDuplicateUsername) || errors.Is(err, backend.ErrDuplicateCertificate) {
+		status = http.StatusBadRequest
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	} else if report.Committed {
+		status = http.StatusCreated
+	}
+	w.WriteHeader(status)
+	if err = json.NewEncoder(w).Encode(report); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing import report to client", slog.String("error", err.Error()))
+	}
+}
+
+// uploadedFile returns the file uploaded as the "file" field of a multipart form along with its name, or the raw
+// request body and the file_name query parameter for any other content type.
+func uploadedFile(r *http.Request) (io.ReadCloser, string, error) {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return nil, "", err
+		}
+		return file, header.Filename, nil
+	}
+	return r.Body, r.URL.Query().Get("file_name"), nil
+}
+
+func (s Server) addImportProfile(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	var profile types.ImportProfile
+	if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid import profile JSON sent from client", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	profile, err := s.backendFor(r).AddImportProfile(r.Context(), profile)
+	if errors.Is(err, backend.ErrMissingArgs) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, backend.ErrDuplicateImportProfile) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(profile); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing import profile to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getImportProfile(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	profile, err := s.backendFor(r).GetImportProfile(r.Context(), r.PathValue("id"))
+	if errors.Is(err, backend.ErrImportProfileNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(profile); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing import profile to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getImportProfiles(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	profiles, err := s.backendFor(r).GetImportProfiles(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(profiles); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing import profiles to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) deleteImportProfile(w http.ResponseWriter, r *http.Request) {
+	err := s.backendFor(r).DeleteImportProfile(r.Context(), r.PathValue("id"))
+	if errors.Is(err, backend.ErrImportProfileNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// stageCompletionImport takes the spreadsheet the same way importMembers does, mapped by ?profile_id=.
+func (s Server) stageCompletionImport(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	profileID := r.URL.Query().Get("profile_id")
+	if profileID == "" {
+		writeError(w, http.StatusBadRequest, backend.MissingArgsError{Fields: []string{"profile_id"}})
+		return
+	}
+	body, fileName, err := uploadedFile(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Missing file in completion import form", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer body.Close()
+	batch, err := s.backendFor(r).StageCompletionImport(r.Context(), caller.MemberID, profileID, fileName, body)
+	if errors.Is(err, backend.ErrInvalidImport) || errors.Is(err, backend.ErrImportProfileNotFound) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(batch); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing import batch to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getImportBatch(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	batch, err := s.backendFor(r).GetImportBatch(r.Context(), r.PathValue("id"))
+	if errors.Is(err, backend.ErrImportBatchNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(batch); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing import batch to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) updateStagedRow(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	var update types.StagedRowUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid staged row JSON sent from client", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	row, err := s.backendFor(r).UpdateStagedRow(r.Context(), r.PathValue("id"), r.PathValue("rowID"), update)
+	if errors.Is(err, backend.ErrImportBatchNotFound) || errors.Is(err, backend.ErrImportRowNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrMemberNotFound) || errors.Is(err, backend.ErrRequirementNotFound) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, backend.ErrImportBatchCommitted) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(row); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing staged row to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) commitImportBatch(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	batch, err := s.backendFor(r).CommitImportBatch(r.Context(), caller.MemberID, r.PathValue("id"))
+	if errors.Is(err, backend.ErrImportBatchNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrInvalidImport) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, backend.ErrImportBatchCommitted) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(batch); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing import batch to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) discardImportBatch(w http.ResponseWriter, r *http.Request) {
+	err := s.backendFor(r).DiscardImportBatch(r.Context(), r.PathValue("id"))
+	if errors.Is(err, backend.ErrImportBatchNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrImportBatchCommitted) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
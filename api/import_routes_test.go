@@ -0,0 +1,195 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/backend"
+	"PORTal/types"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestImportMembers(t *testing.T) {
+	b := newMockBackend()
+	b.importMembersOverride = func(r io.Reader, dryRun bool) (types.MemberImportReport, error) {
+		body, _ := io.ReadAll(r)
+		report := types.MemberImportReport{DryRun: dryRun, Rows: []types.MemberImportRow{{Line: 1, Username: "jdoe"}}}
+		if strings.Contains(string(body), "invalid") {
+			report.Rows[0].Errors = []string{"invalid rank: invalid"}
+			if dryRun {
+				return report, nil
+			}
+			return report, backend.ErrInvalidImport
+		}
+		report.Committed = !dryRun
+		return report, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	form := &bytes.Buffer{}
+	mw := multipart.NewWriter(form)
+	fw, _ := mw.CreateFormFile("file", "roster.csv")
+	fw.Write([]byte("SSgt,John,Doe,jdoe\n"))
+	mw.Close()
+
+	tc := []struct {
+		name        string
+		cookie      *http.Cookie
+		query       string
+		contentType string
+		body        string
+		statusCode  int
+		committed   bool
+	}{
+		{
+			name:       "Successful import",
+			cookie:     adminCookie(t),
+			body:       "SSgt,John,Doe,jdoe\n",
+			statusCode: http.StatusCreated,
+			committed:  true,
+		},
+		{
+			name:        "Successful multipart import",
+			cookie:      adminCookie(t),
+			contentType: mw.FormDataContentType(),
+			body:        form.String(),
+			statusCode:  http.StatusCreated,
+			committed:   true,
+		},
+		{
+			name:       "Dry run",
+			cookie:     adminCookie(t),
+			query:      "?dry_run=true",
+			body:       "SSgt,John,Doe,jdoe\n",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Dry run with invalid rows",
+			cookie:     adminCookie(t),
+			query:      "?dry_run=true",
+			body:       "invalid,John,Doe,jdoe\n",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Invalid rows",
+			cookie:     adminCookie(t),
+			body:       "invalid,John,Doe,jdoe\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Training manager can't import",
+			cookie:     roleCookie(t, types.RoleTrainingManager),
+			body:       "SSgt,John,Doe,jdoe\n",
+			statusCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/admin/import/members"+tt.query, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			r.AddCookie(tt.cookie)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if w.Code == http.StatusForbidden {
+				return
+			}
+			var report types.MemberImportReport
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatalf("Error decoding import report: %s", err.Error())
+			}
+			if report.Committed != tt.committed || len(report.Rows) != 1 {
+				t.Errorf("Unexpected import report: %+v", report)
+			}
+		})
+	}
+}
+
+func TestCommitImportBatch(t *testing.T) {
+	b := newMockBackend()
+	b.commitImportBatchOverride = func(actorID, batchID string) (types.ImportBatch, error) {
+		switch batchID {
+		case "invalid":
+			return types.ImportBatch{}, backend.ErrInvalidImport
+		case "committed":
+			return types.ImportBatch{}, backend.ErrImportBatchCommitted
+		case "missing":
+			return types.ImportBatch{}, backend.ErrImportBatchNotFound
+		}
+		return types.ImportBatch{ID: batchID, Status: types.ImportBatchCommitted}, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		cookie     *http.Cookie
+		batchID    string
+		statusCode int
+	}{
+		{name: "Successful commit", cookie: adminCookie(t), batchID: "staged", statusCode: http.StatusOK},
+		{name: "Invalid rows", cookie: adminCookie(t), batchID: "invalid", statusCode: http.StatusBadRequest},
+		{name: "Already committed", cookie: adminCookie(t), batchID: "committed", statusCode: http.StatusConflict},
+		{name: "Batch not found", cookie: adminCookie(t), batchID: "missing", statusCode: http.StatusNotFound},
+		{name: "Training manager can't commit", cookie: roleCookie(t, types.RoleTrainingManager), batchID: "staged", statusCode: http.StatusForbidden},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/admin/import/completions/"+tt.batchID+"/commit", nil)
+			r.AddCookie(tt.cookie)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestStageCompletionImport(t *testing.T) {
+	b := newMockBackend()
+	var gotFileName string
+	b.stageCompletionImportOverride = func(uploaderID, profileID, fileName string, r io.Reader) (types.ImportBatch, error) {
+		if profileID != "legacy" {
+			return types.ImportBatch{}, backend.ErrImportProfileNotFound
+		}
+		gotFileName = fileName
+		return types.ImportBatch{ID: "batch", FileName: fileName, Status: types.ImportBatchStaged}, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name             string
+		query            string
+		statusCode       int
+		expectedFileName string
+	}{
+		{name: "Successful upload", query: "?profile_id=legacy&file_name=history.csv", statusCode: http.StatusCreated, expectedFileName: "history.csv"},
+		{name: "Missing profile", query: "", statusCode: http.StatusBadRequest},
+		{name: "Profile not found", query: "?profile_id=other", statusCode: http.StatusBadRequest},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFileName = ""
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/admin/import/completions"+tt.query, strings.NewReader("Username,Course,Completed\n"))
+			r.AddCookie(adminCookie(t))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if gotFileName != tt.expectedFileName {
+				t.Errorf("Expected file name %q, got %q", tt.expectedFileName, gotFileName)
+			}
+		})
+	}
+}
@@ -11,14 +11,18 @@ import (
 
 type CustomClaims struct {
 	jwt.RegisteredClaims
-	Admin bool `json:"admin"`
+	Admin       bool               `json:"admin"`
+	Role        types.Role         `json:"role"`
+	Permissions []types.Permission `json:"permissions"`
+	// Tenant is the tenant the member belongs to, a token is only accepted on requests for that tenant.
+	Tenant string `json:"tenant,omitempty"`
 }
 
 func (s Server) jwtKeyFunc(t *jwt.Token) (interface{}, error) {
 	return []byte(s.config.JWTSecret), nil
 }
 
-func createToken(member types.Member, expiration time.Duration, key []byte) (string, error) {
+func createToken(member types.Member, tenantID string, permissions []types.Permission, expiration time.Duration, key []byte) (string, error) {
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, CustomClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    "test",
@@ -29,7 +33,10 @@ func createToken(member types.Member, expiration time.Duration, key []byte) (str
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ID:        uuid.NewString(),
 		},
-		Admin: member.Admin,
+		Admin:       member.Admin,
+		Role:        member.Role,
+		Permissions: permissions,
+		Tenant:      tenantID,
 	})
 	signedToken, err := t.SignedString([]byte(key))
 	if err != nil {
@@ -38,10 +45,10 @@ func createToken(member types.Member, expiration time.Duration, key []byte) (str
 	return signedToken, nil
 }
 
-func validateToken(token string, keyFunc jwt.Keyfunc, logger *slog.Logger) (*jwt.Token, error) {
+func validateToken(ctx context.Context, token string, keyFunc jwt.Keyfunc, logger *slog.Logger) (*jwt.Token, error) {
 	t, err := jwt.ParseWithClaims(token, &CustomClaims{}, keyFunc)
 	if err != nil {
-		logger.LogAttrs(context.Background(), slog.LevelInfo, "Error validating token", slog.String("error", err.Error()))
+		logger.LogAttrs(ctx, slog.LevelInfo, "Error validating token", slog.String("error", err.Error()))
 		return nil, err
 	}
 	return t, err
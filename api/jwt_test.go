@@ -2,9 +2,11 @@ package api
 
 import (
 	"PORTal/testutils"
+	"PORTal/types"
 	"errors"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -18,7 +20,7 @@ func TestCreateAndValidateUserToken(t *testing.T) {
 	key, _ := k.([]byte)
 	normalMember := testutils.RandomMember(false)
 	normalMember.ID = uuid.NewString()
-	token, err := createToken(normalMember, time.Hour, key)
+	token, err := createToken(normalMember, types.DefaultTenantID, types.DefaultRolePermissions[normalMember.Role], time.Hour, key)
 	if err != nil {
 		t.Fatalf("Error when creating token: %s", err.Error())
 	}
@@ -46,7 +48,7 @@ func TestCreateAndValidateAdminToken(t *testing.T) {
 	key, _ := k.([]byte)
 	adminMember := testutils.RandomMember(true)
 	adminMember.ID = uuid.NewString()
-	token, err := createToken(adminMember, time.Hour, key)
+	token, err := createToken(adminMember, types.DefaultTenantID, types.DefaultRolePermissions[adminMember.Role], time.Hour, key)
 	if err != nil {
 		t.Fatalf("Error when creating token: %s", err.Error())
 	}
@@ -64,6 +66,9 @@ func TestCreateAndValidateAdminToken(t *testing.T) {
 	if cc.Admin != true {
 		t.Error("Expected admin to be true, but got false")
 	}
+	if cc.Role != types.RoleAdmin || !reflect.DeepEqual(cc.Permissions, types.Permissions) {
+		t.Errorf("Expected admin role with all permissions, got role %s with: %v", cc.Role, cc.Permissions)
+	}
 	if cc.ExpiresAt.Time.Before(time.Now().Add(59*time.Minute)) && cc.ExpiresAt.Time.After(time.Now().Add(61*time.Minute)) {
 		t.Error("Expected JWT to last an hour, but it didn't")
 	}
@@ -74,7 +79,7 @@ func TestExpiredJWT(t *testing.T) {
 	key, _ := k.([]byte)
 	adminMember := testutils.RandomMember(true)
 	adminMember.ID = uuid.NewString()
-	token, err := createToken(adminMember, time.Millisecond, key)
+	token, err := createToken(adminMember, types.DefaultTenantID, types.DefaultRolePermissions[adminMember.Role], time.Millisecond, key)
 	if err != nil {
 		t.Fatalf("Error when creating token: %s", err.Error())
 	}
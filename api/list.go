@@ -0,0 +1,54 @@
+package api
+
+import (
+	"PORTal/types"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// parseListOptions reads ?cursor=, ?limit= and ?sort= from a list request. Sorting by -name sorts by name descending.
+func parseListOptions(r *http.Request) (types.ListOptions, error) {
+	query := r.URL.Query()
+	opts := types.ListOptions{Cursor: query.Get("cursor")}
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return opts, fmt.Errorf("%w: limit must be a positive number, got %q", errInvalidQuery, limit)
+		}
+		opts.Limit = n
+	}
+	sort, descending := strings.CutPrefix(query.Get("sort"), "-")
+	opts.Sort, opts.Descending = types.SortKey(sort), descending
+	return opts, nil
+}
+
+// parseMemberListOptions adds the member filters, ?rank=, ?supervisor_id=, ?admin=, ?qualification_id=, ?archived= and
+// ?unit_id=, which can be given more than once.
+func parseMemberListOptions(r *http.Request) (types.MemberListOptions, error) {
+	var opts types.MemberListOptions
+	var err error
+	if opts.ListOptions, err = parseListOptions(r); err != nil {
+		return opts, err
+	}
+	query := r.URL.Query()
+	if rank := query.Get("rank"); rank != "" {
+		var ok bool
+		if opts.Rank, ok = types.ParseRank(rank); !ok {
+			return opts, fmt.Errorf("%w: unknown rank %q", errInvalidQuery, rank)
+		}
+	}
+	if admin := query.Get("admin"); admin != "" {
+		a, err := strconv.ParseBool(admin)
+		if err != nil {
+			return opts, fmt.Errorf("%w: admin must be true or false, got %q", errInvalidQuery, admin)
+		}
+		opts.Admin = &a
+	}
+	opts.SupervisorID = query.Get("supervisor_id")
+	opts.QualificationID = query.Get("qualification_id")
+	opts.Archived = types.ArchiveFilter(query.Get("archived"))
+	opts.UnitIDs = query["unit_id"]
+	return opts, nil
+}
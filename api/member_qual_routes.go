@@ -2,8 +2,11 @@ package api
 
 import (
 	"PORTal/backend"
+	"PORTal/types"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 )
@@ -11,21 +14,26 @@ import (
 func (s Server) assignMemberQualification(w http.ResponseWriter, r *http.Request) {
 	memberID := r.PathValue("id")
 	qualID := r.PathValue("qualID")
-	err := s.backend.AssignMemberQualification(memberID, qualID)
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	err := s.backendFor(r).AssignMemberQualification(r.Context(), caller.MemberID, memberID, qualID)
 	if errors.Is(err, backend.ErrMemberNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
 		return
 	}
 	if errors.Is(err, backend.ErrQualificationNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
 		return
 	}
 	if errors.Is(err, backend.ErrQualificationAlreadyAssigned) {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -34,46 +42,127 @@ func (s Server) assignMemberQualification(w http.ResponseWriter, r *http.Request
 func (s Server) getMemberQualification(w http.ResponseWriter, r *http.Request) {
 	memberID := r.PathValue("id")
 	qualID := r.PathValue("qualID")
-	qual, err := s.backend.GetMemberQualification(memberID, qualID)
-	if errors.Is(err, backend.ErrMemberNotFound) || errors.Is(err, backend.ErrQualificationNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+	qual, err := s.backendFor(r).GetMemberQualification(r.Context(), memberID, qualID)
+	if errors.Is(err, backend.ErrMemberNotFound) || errors.Is(err, backend.ErrQualificationNotFound) ||
+		errors.Is(err, backend.ErrMemberQualificationNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	if err = json.NewEncoder(w).Encode(qual); err != nil {
 		s.logger.LogAttrs(r.Context(), slog.LevelError, "Error serializing qualification to client", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
 
 func (s Server) getMemberQualifications(w http.ResponseWriter, r *http.Request) {
 	memberID := r.PathValue("id")
-	reqs, err := s.backend.GetMemberQualifications(memberID)
+	reqs, err := s.backendFor(r).GetMemberQualifications(r.Context(), memberID)
 	if errors.Is(err, backend.ErrMemberNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
 		return
 	}
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	err = json.NewEncoder(w).Encode(reqs)
 	if err != nil {
 		s.logger.LogAttrs(r.Context(), slog.LevelError, "Error serializing slice of MemberQualifications to client", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
 
 func (s Server) removeMemberQualification(w http.ResponseWriter, r *http.Request) {
 	memberID := r.PathValue("id")
 	qualID := r.PathValue("qualID")
-	err := s.backend.RemoveMemberQualification(memberID, qualID)
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	err := s.backendFor(r).RemoveMemberQualification(r.Context(), caller.MemberID, memberID, qualID)
 	if errors.Is(err, backend.ErrMemberNotFound) || errors.Is(err, backend.ErrMemberQualificationNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
 		return
 	}
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
+
+func (s Server) getMemberQualificationStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.backendFor(r).GetMemberQualificationStatus(r.Context(), r.PathValue("id"), r.PathValue("qualID"))
+	if errors.Is(err, backend.ErrMemberQualificationNotFound) || errors.Is(err, backend.ErrQualificationNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(status); err != nil {
+		s.logger.LogAttrs(r.Context(), slog.LevelError, "Error serializing qualification status to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getMemberQualificationStatuses(w http.ResponseWriter, r *http.Request) {
+	statuses, err := s.backendFor(r).GetMemberQualificationStatuses(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(statuses); err != nil {
+		s.logger.LogAttrs(r.Context(), slog.LevelError, "Error serializing qualification statuses to client", slog.String("error", err.Error()))
+	}
+}
+
+// bulkMemberQualifications handles both bulk endpoints. Per-item failures are reported in the body, so anything short
+// of a bad request comes back 200.
+func (s Server) bulkMemberQualifications(apply func(Backend, context.Context, string, types.BulkQualificationRequest) ([]types.BulkItemResult, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+		caller, status := s.requestIdentity(r)
+		if status != http.StatusOK {
+			writeError(w, status, errUnauthenticated)
+			return
+		}
+		var req types.BulkQualificationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid bulk qualification JSON sent from client", slog.String("error", err.Error()))
+			writeError(w, http.StatusBadRequest, invalidBody(err))
+			return
+		}
+		defer r.Body.Close()
+		results, err := apply(s.backendFor(r), r.Context(), caller.MemberID, req)
+		if errors.Is(err, backend.ErrMissingArgs) {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		} else if errors.Is(err, backend.ErrSupervisorNotFound) {
+			writeError(w, http.StatusNotFound, err)
+			return
+		} else if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if err = json.NewEncoder(w).Encode(results); err != nil {
+			l.LogAttrs(r.Context(), slog.LevelError, "Error serializing bulk results to client", slog.String("error", err.Error()))
+		}
+	}
+}
+
+func (s Server) getQualificationHistory(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	history, err := s.backendFor(r).GetQualificationHistory(r.Context(), r.PathValue("id"), r.PathValue("qualID"))
+	if errors.Is(err, backend.ErrMemberNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(history); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing qualification history to client", slog.String("error", err.Error()))
+	}
+}
@@ -18,7 +18,7 @@ import (
 
 func TestAddMemberQualification(t *testing.T) {
 	b := newMockBackend()
-	b.assignMemberQualificationOverride = func(memberID, qualID string) error {
+	b.assignMemberQualificationOverride = func(actorID, memberID, qualID string) error {
 		if qualID == "good" && memberID == "good" {
 			return nil
 		}
@@ -80,6 +80,7 @@ func TestAddMemberQualification(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/member/%s/qualification/%s", tt.memberId, tt.qualificationID), nil)
+			r.AddCookie(adminCookie(t))
 			s.ServeHTTP(w, r)
 
 			if w.Code != tt.statusCode {
@@ -90,7 +91,53 @@ func TestAddMemberQualification(t *testing.T) {
 }
 
 func TestGetMemberQualification(t *testing.T) {
+	qual := testutils.RandomQualification()
+	qual.ID = uuid.NewString()
+	b := newMockBackend()
+	b.getMemberQualificationOverride = func(memberID, qualID string) (types.Qualification, error) {
+		switch {
+		case memberID == "notfound":
+			return types.Qualification{}, backend.ErrMemberNotFound
+		case qualID == "notfound":
+			return types.Qualification{}, backend.ErrQualificationNotFound
+		case qualID == "unassigned":
+			return types.Qualification{}, backend.ErrMemberQualificationNotFound
+		case qualID == qual.ID:
+			return qual, nil
+		}
+		return types.Qualification{}, errors.New("unexpected case")
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
 
+	tc := []struct {
+		name       string
+		memberID   string
+		qualID     string
+		statusCode int
+	}{
+		{name: "Successful get", memberID: "good", qualID: qual.ID, statusCode: http.StatusOK},
+		{name: "Member not found", memberID: "notfound", qualID: qual.ID, statusCode: http.StatusNotFound},
+		{name: "Qualification not found", memberID: "good", qualID: "notfound", statusCode: http.StatusNotFound},
+		{name: "Qualification not assigned", memberID: "good", qualID: "unassigned", statusCode: http.StatusNotFound},
+		{name: "Backend error", memberID: "good", qualID: "bad", statusCode: http.StatusInternalServerError},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/member/%s/qualification/%s", tt.memberID, tt.qualID), nil)
+			r.AddCookie(adminCookie(t))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected response code %d, got %d", tt.statusCode, w.Code)
+			}
+			if tt.statusCode != http.StatusOK {
+				var p api.Problem
+				if err := json.NewDecoder(w.Body).Decode(&p); err != nil || p.Status != tt.statusCode {
+					t.Errorf("Expected problem details with status %d, got %+v (%v)", tt.statusCode, p, err)
+				}
+			}
+		})
+	}
 }
 
 func TestGetMemberQualifications(t *testing.T) {
@@ -157,6 +204,7 @@ func TestGetMemberQualifications(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/member/%s/qualifications", tt.memberID), nil)
+			r.AddCookie(adminCookie(t))
 			s.ServeHTTP(w, r)
 
 			if w.Code != tt.statusCode {
@@ -188,7 +236,7 @@ func TestDeleteMemberQualification(t *testing.T) {
 	goodMemberID := uuid.NewString()
 	goodQualID := uuid.NewString()
 	b := newMockBackend()
-	b.removeMemberQualificationOverride = func(memberID, qualID string) error {
+	b.removeMemberQualificationOverride = func(actorID, memberID, qualID string) error {
 		if qualID == goodQualID && memberID == goodMemberID {
 			return nil
 		}
@@ -239,6 +287,7 @@ func TestDeleteMemberQualification(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/member/%s/qualification/%s", tt.memberID, tt.qualID), nil)
+			r.AddCookie(adminCookie(t))
 			s.ServeHTTP(w, r)
 			if w.Code != tt.statusCode {
 				t.Errorf("Expected response code %d, got %d", tt.statusCode, w.Code)
@@ -246,3 +295,70 @@ func TestDeleteMemberQualification(t *testing.T) {
 		})
 	}
 }
+
+func TestGetQualificationHistory(t *testing.T) {
+	goodMemberID := uuid.NewString()
+	qualID := uuid.NewString()
+	events := []types.QualificationEvent{
+		{ID: uuid.NewString(), MemberID: goodMemberID, QualificationID: qualID, Kind: types.QualificationAssigned, ActorID: uuid.NewString()},
+		{ID: uuid.NewString(), MemberID: goodMemberID, QualificationID: qualID, Kind: types.QualificationQualified},
+	}
+	b := newMockBackend()
+	b.getQualificationHistoryOverride = func(memberID, qualificationID string) ([]types.QualificationEvent, error) {
+		if memberID == goodMemberID {
+			return events, nil
+		}
+		if memberID == "notfound" {
+			return nil, backend.ErrMemberNotFound
+		}
+		return nil, errors.New("unexpected case")
+	}
+
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		memberID   string
+		statusCode int
+		expected   []types.QualificationEvent
+	}{
+		{
+			name:       "Successful get",
+			memberID:   goodMemberID,
+			statusCode: http.StatusOK,
+			expected:   events,
+		},
+		{
+			name:       "Member not found",
+			memberID:   "notfound",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Backend error",
+			memberID:   "bad",
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/member/%s/qualification/%s/history", tt.memberID, qualID), nil)
+			r.AddCookie(roleCookie(t, types.RoleMember))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected response code %d, got %d", tt.statusCode, w.Code)
+			}
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+			var history []types.QualificationEvent
+			if err := json.NewDecoder(w.Body).Decode(&history); err != nil {
+				t.Fatalf("Error decoding history: %s", err.Error())
+			}
+			if !reflect.DeepEqual(history, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, history)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package api
 import (
 	"PORTal/backend"
 	"PORTal/types"
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,22 +17,37 @@ func (s Server) addMember(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&m)
 	if err != nil {
 		l.LogAttrs(r.Context(), slog.LevelError, "Error deserializing body into member struct", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, invalidBody(err))
 		return
 	}
 	defer r.Body.Close()
 	if err = validateMember(m); err != nil {
 		l.LogAttrs(r.Context(), slog.LevelInfo, "Incomplete create member request", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
-	insertedMember, err := s.backend.AddMember(m)
-	if errors.Is(err, backend.ErrSupervisorNotFound) {
-		w.WriteHeader(http.StatusBadRequest)
+	// Certificates are only bound through PUT /api/member/{id}/certificate, which checks who may bind them
+	m.CertificateID = ""
+	requestedRole := m.Role
+	if m.Admin && requestedRole == "" {
+		requestedRole = types.RoleAdmin
+	}
+	if caller, _ := s.requestIdentity(r); !canAssignRole(caller, types.RoleMember, requestedRole) {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Member not permitted to assign role", slog.String("member_id", caller.MemberID))
+		writeError(w, http.StatusForbidden, backend.ErrInsufficientPermissions)
+		return
+	}
+	insertedMember, err := s.backendFor(r).AddMember(r.Context(), m)
+	if errors.Is(err, backend.ErrSupervisorNotFound) || errors.Is(err, backend.ErrInvalidRole) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if errors.Is(err, backend.ErrDuplicateUsername) {
+		writeError(w, http.StatusConflict, err)
 		return
 	}
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
@@ -48,95 +62,253 @@ func (s Server) getMember(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if _, err := uuid.Parse(id); err != nil {
 		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid UUID passed to get member", slog.String("id", id))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, errInvalidID)
 		return
 	}
-	m, err := s.backend.GetMember(id)
+	m, err := s.backendFor(r).GetMember(r.Context(), id)
 	if errors.Is(err, backend.ErrMemberNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
 		return
 	} else if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
+	setVersionTag(w, m.Version)
 	err = json.NewEncoder(w).Encode(m.ToApiMember())
 	if err != nil {
-		l.LogAttrs(context.Background(), slog.LevelError, "Error serializing ApiMember to client", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusInternalServerError)
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing ApiMember to client", slog.String("error", err.Error()))
 	}
 }
 
-func (s Server) getAllMembers(w http.ResponseWriter, r *http.Request) {
+// listMembers returns a page of active members, see parseMemberListOptions for the sorts and filters. Listing archived
+// members takes the same permission as GET /api/members/archived.
+func (s Server) listMembers(w http.ResponseWriter, r *http.Request) {
 	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
-	members, err := s.backend.GetAllMembers()
+	opts, err := parseMemberListOptions(r)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Invalid list members request", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
-	var apiMembers []types.ApiMember
-	for _, m := range members {
-		apiMembers = append(apiMembers, m.ToApiMember())
+	if caller, _ := s.requestIdentity(r); opts.Archived != "" && opts.Archived != types.MemberActive && !caller.can(types.PermDeleteMembers) {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Member not permitted to list archived members", slog.String("member_id", caller.MemberID))
+		writeError(w, http.StatusForbidden, backend.ErrInsufficientPermissions)
+		return
 	}
-	err = json.NewEncoder(w).Encode(apiMembers)
-	if err != nil {
-		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing list of ApiMember to client", slog.String("error", err.Error()))
+	page, err := s.backendFor(r).ListMembers(r.Context(), opts)
+	if errors.Is(err, backend.ErrInvalidListOptions) || errors.Is(err, backend.ErrUnitNotFound) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	apiMembers := types.Page[types.ApiMember]{Items: []types.ApiMember{}, Total: page.Total, NextCursor: page.NextCursor}
+	for _, m := range page.Items {
+		apiMembers.Items = append(apiMembers.Items, m.ToApiMember())
+	}
+	if err = writeTagged(w, r, apiMembers); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing page of ApiMember to client", slog.String("error", err.Error()))
 	}
 }
 
 func (s Server) updateMember(w http.ResponseWriter, r *http.Request) {
 	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	version, status, err := ifMatch(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Missing or invalid If-Match updating member", slog.String("error", err.Error()))
+		writeError(w, status, err)
+		return
+	}
 	m := types.Member{}
-	err := json.NewDecoder(r.Body).Decode(&m)
+	err = json.NewDecoder(r.Body).Decode(&m)
 	if err != nil {
 		l.LogAttrs(r.Context(), slog.LevelError, "Error deserializing request body into member struct", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, invalidBody(err))
 		return
 	}
-	existingMember, err := s.backend.GetMember(m.ID)
+	m.Version = version
+	// Routing authorized the caller for the member in the path, so that's the only member the body may update
+	existingMember, err := s.backendFor(r).GetMember(r.Context(), r.PathValue("id"))
 	if errors.Is(err, backend.ErrMemberNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
 		return
 	} else if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
-	//TODO: Implement authorization so that only the correct user is allowed to update an account
 	if existingMember.ID != m.ID {
 		l.LogAttrs(r.Context(), slog.LevelWarn, "User requesting to update ID", slog.Any("update_request", m))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: ID doesn't match path", backend.ErrBadUpdate))
+		return
+	}
+	caller, _ := s.requestIdentity(r)
+	if m.Role != "" && !canAssignRole(caller, existingMember.Role, m.Role) {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Member not permitted to change role", slog.String("member_id", caller.MemberID))
+		writeError(w, http.StatusForbidden, backend.ErrInsufficientPermissions)
 		return
 	}
-	member, err := s.backend.UpdateMember(m)
+	if m.Password != "" && !canChangeCredentials(caller, existingMember) {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Member not permitted to change an admin's password", slog.String("member_id", caller.MemberID))
+		writeError(w, http.StatusForbidden, backend.ErrInsufficientPermissions)
+		return
+	}
+	member, err := s.backendFor(r).UpdateMember(r.Context(), m)
 	if errors.Is(err, backend.ErrMemberNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrSupervisorNotFound) || errors.Is(err, backend.ErrInvalidRole) {
+		writeError(w, http.StatusBadRequest, err)
 		return
-	} else if errors.Is(err, backend.ErrSupervisorNotFound) {
-		w.WriteHeader(http.StatusBadRequest)
+	} else if errors.Is(err, backend.ErrDuplicateUsername) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if errors.Is(err, backend.ErrVersionConflict) {
+		writeError(w, http.StatusPreconditionFailed, err)
 		return
 	} else if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
-	err = json.NewEncoder(w).Encode(member)
+	setVersionTag(w, member.Version)
+	err = json.NewEncoder(w).Encode(member.ToApiMember())
 	if err != nil {
 		s.logger.LogAttrs(r.Context(), slog.LevelError, "Error encoding member to client", slog.String("error", err.Error()))
 	}
 }
 
-func (s Server) deleteMember(w http.ResponseWriter, r *http.Request) {
+func (s Server) bindMemberCertificate(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	var binding CertificateBinding
+	if err := json.NewDecoder(r.Body).Decode(&binding); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Error deserializing certificate binding", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	if binding.CertificateID == "" {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Empty certificate ID supplied")
+		writeError(w, http.StatusBadRequest, backend.MissingArgsError{Fields: []string{"CertificateID"}})
+		return
+	}
+	s.setMemberCertificate(w, r, binding.CertificateID)
+}
+
+func (s Server) unbindMemberCertificate(w http.ResponseWriter, r *http.Request) {
+	s.setMemberCertificate(w, r, "")
+}
+
+func (s Server) setMemberCertificate(w http.ResponseWriter, r *http.Request, certificateID string) {
 	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
 	id := r.PathValue("id")
 	if _, err := uuid.Parse(id); err != nil {
 		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid UUID provided", slog.String("id", id))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, errInvalidID)
 		return
 	}
-	err := s.backend.DeleteMember(id)
+	m, err := s.backendFor(r).BindMemberCertificate(r.Context(), id, certificateID)
+	if errors.Is(err, backend.ErrMemberNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrDuplicateCertificate) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(m.ToApiMember()); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing ApiMember to client", slog.String("error", err.Error()))
+	}
+}
+
+// patchMember applies a JSON merge patch to a member, so null clears fields like supervisor_id that PUT can't.
+func (s Server) patchMember(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid UUID passed to patch member", slog.String("id", id))
+		writeError(w, http.StatusBadRequest, errInvalidID)
+		return
+	}
+	version, status, err := ifMatch(r)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Missing or invalid If-Match patching member", slog.String("error", err.Error()))
+		writeError(w, status, err)
+		return
+	}
+	patch, status, err := readMergePatch(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Error reading member patch", slog.String("error", err.Error()))
+		writeError(w, status, err)
+		return
+	}
+	existingMember, err := s.backendFor(r).GetMember(r.Context(), id)
+	if errors.Is(err, backend.ErrMemberNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
+	var guarded struct {
+		Role     *types.Role `json:"role"`
+		Password *string     `json:"password"`
+	}
+	if json.Unmarshal(patch, &guarded) == nil {
+		caller, _ := s.requestIdentity(r)
+		if guarded.Role != nil && !canAssignRole(caller, existingMember.Role, *guarded.Role) {
+			l.LogAttrs(r.Context(), slog.LevelWarn, "Member not permitted to change role", slog.String("member_id", caller.MemberID))
+			writeError(w, http.StatusForbidden, backend.ErrInsufficientPermissions)
+			return
+		}
+		if guarded.Password != nil && !canChangeCredentials(caller, existingMember) {
+			l.LogAttrs(r.Context(), slog.LevelWarn, "Member not permitted to change an admin's password", slog.String("member_id", caller.MemberID))
+			writeError(w, http.StatusForbidden, backend.ErrInsufficientPermissions)
+			return
+		}
+	}
+	member, err := s.backendFor(r).PatchMember(r.Context(), id, version, patch)
+	if errors.Is(err, backend.ErrMemberNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrVersionConflict) {
+		writeError(w, http.StatusPreconditionFailed, err)
+		return
+	} else if errors.Is(err, backend.ErrInvalidPatch) || errors.Is(err, backend.ErrBadUpdate) || errors.Is(err, backend.ErrMissingArgs) ||
+		errors.Is(err, backend.ErrInvalidRole) || errors.Is(err, backend.ErrSupervisorNotFound) ||
+		errors.Is(err, backend.ErrWeakPassword) || errors.Is(err, backend.ErrPasswordTooLong) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	setVersionTag(w, member.Version)
+	if err = json.NewEncoder(w).Encode(member.ToApiMember()); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error encoding member to client", slog.String("error", err.Error()))
+	}
+}
+
+// canAssignRole reports whether the caller may move a member from one role to another. Granting or revoking admin
+// requires role management on top of member management.
+func canAssignRole(caller identity, from, to types.Role) bool {
+	if to == "" || from == to {
+		return true
+	}
+	if !caller.can(types.PermManageMembers) {
+		return false
+	}
+	if from == types.RoleAdmin || to == types.RoleAdmin {
+		return caller.can(types.PermManageRoles)
+	}
+	return true
+}
+
+// canChangeCredentials reports whether the caller may set the password of a member. Whoever can log in as an admin can
+// do anything, so only admins themselves and members who can manage roles may change an admin's.
+func canChangeCredentials(caller identity, m types.Member) bool {
+	return caller.MemberID == m.ID || m.Role != types.RoleAdmin || caller.can(types.PermManageRoles)
 }
 
 func validateMember(m types.Member) error {
@@ -151,7 +323,7 @@ func validateMember(m types.Member) error {
 		errs = append(errs, "Rank")
 	}
 	if len(errs) > 0 {
-		return fmt.Errorf("missing required values: %s", errs)
+		return backend.MissingArgsError{Fields: errs}
 	}
 	return nil
 }
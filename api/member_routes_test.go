@@ -3,6 +3,7 @@ package api_test
 import (
 	"PORTal/api"
 	"PORTal/backend"
+	"PORTal/testutils"
 	"PORTal/types"
 	"bytes"
 	"encoding/json"
@@ -12,8 +13,10 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestAddMember(t *testing.T) {
@@ -23,6 +26,8 @@ func TestAddMember(t *testing.T) {
 			return types.Member{}, errors.New("error")
 		} else if m.SupervisorID == "bad" {
 			return types.Member{}, backend.ErrSupervisorNotFound
+		} else if m.CertificateID != "" {
+			return types.Member{}, errors.New("certificate bound on create")
 		}
 		m.ID = uuid.NewString()
 		return m, nil
@@ -40,6 +45,11 @@ func TestAddMember(t *testing.T) {
 			body:       `{"first_name":"test","last_name":"member","rank":"TSgt","qualifications":null,"supervisor_id":"random"}`,
 			statusCode: http.StatusCreated,
 		},
+		{
+			name:       "Certificate ignored",
+			body:       `{"first_name":"test","last_name":"member","rank":"TSgt","supervisor_id":"random","certificate_id":"CN=someone"}`,
+			statusCode: http.StatusCreated,
+		},
 		{
 			name:       "Missing first name",
 			body:       `{"last_name":"member","rank":"TSgt","qualifications":null,"supervisor_id":"random"}`,
@@ -76,6 +86,7 @@ func TestAddMember(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodPost, "/api/member", strings.NewReader(tt.body))
+			r.AddCookie(adminCookie(t))
 			s.ServeHTTP(w, r)
 			if w.Code != tt.statusCode {
 				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
@@ -161,6 +172,7 @@ func TestGetMember(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/member/%s", tt.id), nil)
+			r.AddCookie(adminCookie(t))
 			s.ServeHTTP(w, r)
 			if w.Code != tt.statusCode {
 				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
@@ -176,7 +188,7 @@ func TestGetMember(t *testing.T) {
 	}
 }
 
-func TestGetAllMembers(t *testing.T) {
+func TestListMembers(t *testing.T) {
 	testMember := types.Member{
 		ApiMember: types.ApiMember{
 			ID:           uuid.NewString(),
@@ -201,11 +213,11 @@ func TestGetAllMembers(t *testing.T) {
 	}
 	b := newMockBackend()
 	shouldSucceed := false
-	b.getAllMembersOverride = func() ([]types.Member, error) {
+	b.listMembersOverride = func(opts types.MemberListOptions) (types.Page[types.Member], error) {
 		if shouldSucceed {
-			return []types.Member{testMember, testMember2}, nil
+			return types.Page[types.Member]{Items: []types.Member{testMember, testMember2}, Total: 3, NextCursor: "next"}, nil
 		} else {
-			return nil, errors.New("generic error")
+			return types.Page[types.Member]{}, errors.New("generic error")
 		}
 	}
 	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
@@ -234,16 +246,17 @@ func TestGetAllMembers(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodGet, "/api/members", nil)
+			r.AddCookie(adminCookie(t))
 			shouldSucceed = tt.shouldSucceed
 			s.ServeHTTP(w, r)
 			if tt.statusCode != w.Code {
 				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
 			}
 			if shouldSucceed {
-				var am []types.ApiMember
+				am := types.Page[types.ApiMember]{Total: 3, NextCursor: "next"}
 				b := &bytes.Buffer{}
 				for _, m := range tt.expectedMembers {
-					am = append(am, m.ToApiMember())
+					am.Items = append(am.Items, m.ToApiMember())
 				}
 				json.NewEncoder(b).Encode(am)
 				if b.String() != w.Body.String() {
@@ -254,6 +267,71 @@ func TestGetAllMembers(t *testing.T) {
 	}
 }
 
+func TestListMembersOptions(t *testing.T) {
+	admin := true
+	b := newMockBackend()
+	var got types.MemberListOptions
+	b.listMembersOverride = func(opts types.MemberListOptions) (types.Page[types.Member], error) {
+		got = opts
+		if opts.Sort == "name" {
+			return types.Page[types.Member]{}, backend.ErrInvalidListOptions
+		}
+		return types.Page[types.Member]{Items: []types.Member{}}, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		query      string
+		role       types.Role
+		statusCode int
+		expected   types.MemberListOptions
+	}{
+		{
+			name:       "Every option",
+			query:      "?sort=-rank&limit=10&cursor=abc&rank=E-5&supervisor_id=sup&admin=true&qualification_id=qual&unit_id=a&unit_id=b",
+			role:       types.RoleMember,
+			statusCode: http.StatusOK,
+			expected: types.MemberListOptions{
+				ListOptions:     types.ListOptions{Sort: types.SortByRank, Descending: true, Limit: 10, Cursor: "abc"},
+				Rank:            types.E5,
+				SupervisorID:    "sup",
+				Admin:           &admin,
+				QualificationID: "qual",
+				UnitIDs:         []string{"a", "b"},
+			},
+		},
+		{name: "Invalid limit", query: "?limit=ten", role: types.RoleMember, statusCode: http.StatusBadRequest},
+		{name: "Unknown rank", query: "?rank=E12", role: types.RoleMember, statusCode: http.StatusBadRequest},
+		{name: "Invalid admin", query: "?admin=maybe", role: types.RoleMember, statusCode: http.StatusBadRequest},
+		{name: "Unsortable key", query: "?sort=name", role: types.RoleMember, statusCode: http.StatusBadRequest},
+		{name: "Archived as member", query: "?archived=true", role: types.RoleMember, statusCode: http.StatusForbidden},
+		{
+			name:       "Archived as admin",
+			query:      "?archived=true",
+			role:       types.RoleAdmin,
+			statusCode: http.StatusOK,
+			expected:   types.MemberListOptions{Archived: types.MemberArchived},
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			got = types.MemberListOptions{}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/members"+tt.query, nil)
+			r.AddCookie(roleCookie(t, tt.role))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if w.Code == http.StatusOK && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected options %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
 func TestUpdateMember(t *testing.T) {
 	b := newMockBackend()
 	b.updateMemberOverride = func(m types.Member) (types.Member, error) {
@@ -264,6 +342,7 @@ func TestUpdateMember(t *testing.T) {
 		} else if m.SupervisorID == "not found" {
 			return types.Member{}, backend.ErrSupervisorNotFound
 		}
+		m.Hash = "secret"
 		return m, nil
 	}
 	b.getMemberOverride = func(id string) (types.Member, error) {
@@ -322,62 +401,338 @@ func TestUpdateMember(t *testing.T) {
 	for _, tt := range tc {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest(http.MethodPut, "/api/member/irrelevant", strings.NewReader(tt.body))
+			r := httptest.NewRequest(http.MethodPut, "/api/member/old", strings.NewReader(tt.body))
+			r.Header.Set("If-Match", "*")
+			r.AddCookie(adminCookie(t))
 			s.ServeHTTP(w, r)
 			if w.Code != tt.statusCode {
 				t.Errorf("Expected status code: %d, got: %d", tt.statusCode, w.Code)
 			}
+			if tt.statusCode == http.StatusOK && strings.Contains(w.Body.String(), "secret") {
+				t.Errorf("Password hash returned to client: %s", w.Body.String())
+			}
 		})
 	}
 }
 
-func TestDeleteMember(t *testing.T) {
-	goodId := uuid.NewString()
-	badId := uuid.NewString()
+func TestPatchMember(t *testing.T) {
+	memberID := uuid.NewString()
+	b := newMockBackend()
+	b.getMemberOverride = func(id string) (types.Member, error) {
+		if id != memberID {
+			return types.Member{}, backend.ErrMemberNotFound
+		}
+		return types.Member{ApiMember: types.ApiMember{ID: memberID, FirstName: "old", Role: types.RoleMember}}, nil
+	}
+	var received string
+	b.patchMemberOverride = func(id string, patch []byte) (types.Member, error) {
+		received = string(patch)
+		switch {
+		case strings.Contains(received, "missing"):
+			return types.Member{}, backend.MissingArgsError{Fields: []string{"LastName"}}
+		case strings.Contains(received, "bad"):
+			return types.Member{}, errors.New("generic error")
+		}
+		return types.Member{ApiMember: types.ApiMember{ID: id, FirstName: "new"}, Hash: "secret"}, nil
+	}
+
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name        string
+		id          string
+		body        string
+		contentType string
+		cookie      *http.Cookie
+		statusCode  int
+	}{
+		{
+			name:        "Successful patch",
+			id:          memberID,
+			body:        `{"first_name":"new","supervisor_id":null}`,
+			contentType: "application/merge-patch+json",
+			statusCode:  http.StatusOK,
+		},
+		{
+			name:       "Plain JSON is accepted",
+			id:         memberID,
+			body:       `{"first_name":"new"}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:        "Unsupported content type",
+			id:          memberID,
+			body:        `[{"op":"remove","path":"/supervisor_id"}]`,
+			contentType: "application/json-patch+json",
+			statusCode:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name:       "Invalid ID",
+			id:         "invalid",
+			body:       `{}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Member not found",
+			id:         uuid.NewString(),
+			body:       `{}`,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Required field cleared",
+			id:         memberID,
+			body:       `{"last_name":null,"missing":true}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Role escalation",
+			id:         memberID,
+			body:       `{"role":"admin"}`,
+			cookie:     roleCookie(t, types.RoleTrainingManager),
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Backend error",
+			id:         memberID,
+			body:       `{"first_name":"bad"}`,
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			received = ""
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/api/member/%s", tt.id), strings.NewReader(tt.body))
+			r.Header.Set("If-Match", "*")
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.cookie == nil {
+				tt.cookie = adminCookie(t)
+			}
+			r.AddCookie(tt.cookie)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code: %d, got: %d", tt.statusCode, w.Code)
+			}
+			if tt.statusCode == http.StatusOK {
+				if received != tt.body {
+					t.Errorf("Expected the patch to reach the backend as sent, got %q", received)
+				}
+				if strings.Contains(w.Body.String(), "secret") {
+					t.Errorf("Password hash returned to client: %s", w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestBindMemberCertificate(t *testing.T) {
+	memberID := uuid.NewString()
+	boundID := uuid.NewString()
 	b := newMockBackend()
-	b.deleteMemberOverride = func(id string) error {
+	b.bindMemberCertificateOverride = func(id, certificateID string) (types.Member, error) {
 		switch id {
-		case goodId:
-			return nil
-		case badId:
-			return errors.New("generic error")
+		case memberID:
+			m := testutils.RandomMember(false)
+			m.ID = id
+			m.CertificateID = certificateID
+			return m, nil
+		case boundID:
+			return types.Member{}, backend.ErrDuplicateCertificate
 		default:
-			t.Errorf("Unexpected case")
-			return errors.New("unexpected case")
+			return types.Member{}, backend.ErrMemberNotFound
 		}
 	}
 	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
 
+	admin := testutils.RandomMember(true)
+	admin.ID = uuid.NewString()
+	adminToken, err := api.CreateToken(admin, types.DefaultTenantID, types.DefaultRolePermissions[admin.Role], time.Hour, []byte("test"))
+	if err != nil {
+		t.Fatalf("Error creating token for TestBindMemberCertificate: %s", err.Error())
+	}
+	normal := testutils.RandomMember(false)
+	normal.ID = uuid.NewString()
+	normalToken, err := api.CreateToken(normal, types.DefaultTenantID, types.DefaultRolePermissions[normal.Role], time.Hour, []byte("test"))
+	if err != nil {
+		t.Fatalf("Error creating token for TestBindMemberCertificate: %s", err.Error())
+	}
+
 	tc := []struct {
 		name       string
+		method     string
 		id         string
+		body       string
+		token      string
 		statusCode int
 	}{
 		{
-			name:       "Successful delete",
-			id:         goodId,
+			name:       "Successful bind",
+			method:     http.MethodPut,
+			id:         memberID,
+			body:       `{"certificate_id":"1234567890"}`,
+			token:      adminToken,
 			statusCode: http.StatusOK,
 		},
 		{
-			name:       "Backend error",
-			id:         badId,
-			statusCode: http.StatusInternalServerError,
+			name:       "Successful unbind",
+			method:     http.MethodDelete,
+			id:         memberID,
+			token:      adminToken,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Not admin",
+			method:     http.MethodPut,
+			id:         memberID,
+			body:       `{"certificate_id":"1234567890"}`,
+			token:      normalToken,
+			statusCode: http.StatusForbidden,
 		},
 		{
-			name:       "Invalid UUID",
-			id:         goodId[1:],
+			name:       "Not logged in",
+			method:     http.MethodPut,
+			id:         memberID,
+			body:       `{"certificate_id":"1234567890"}`,
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "Missing certificate ID",
+			method:     http.MethodPut,
+			id:         memberID,
+			body:       `{}`,
+			token:      adminToken,
 			statusCode: http.StatusBadRequest,
 		},
+		{
+			name:       "Certificate already bound",
+			method:     http.MethodPut,
+			id:         boundID,
+			body:       `{"certificate_id":"1234567890"}`,
+			token:      adminToken,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "Member not found",
+			method:     http.MethodPut,
+			id:         uuid.NewString(),
+			body:       `{"certificate_id":"1234567890"}`,
+			token:      adminToken,
+			statusCode: http.StatusNotFound,
+		},
 	}
-
 	for _, tt := range tc {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/member/%s", tt.id), nil)
+			r := httptest.NewRequest(tt.method, fmt.Sprintf("/api/member/%s/certificate", tt.id), strings.NewReader(tt.body))
+			if tt.token != "" {
+				r.AddCookie(&http.Cookie{Name: api.JWTCookieName, Value: tt.token})
+			}
 			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestAdminCredentialsProtected(t *testing.T) {
+	adminID := uuid.NewString()
+	b := newMockBackend()
+	b.getMemberOverride = func(id string) (types.Member, error) {
+		return types.Member{ApiMember: types.ApiMember{ID: id, FirstName: "admin", LastName: "member", Rank: "MSgt", Role: types.RoleAdmin, Admin: true}}, nil
+	}
+	b.updateMemberOverride = func(m types.Member) (types.Member, error) { return m, nil }
+	b.patchMemberOverride = func(id string, patch []byte) (types.Member, error) {
+		return types.Member{ApiMember: types.ApiMember{ID: id}}, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
 
+	selfCookie := func(t *testing.T) *http.Cookie {
+		m := testutils.RandomMember(true)
+		m.ID = adminID
+		m.Role = types.RoleAdmin
+		token, err := api.CreateToken(m, types.DefaultTenantID, types.DefaultRolePermissions[types.RoleAdmin], time.Hour, []byte("test"))
+		if err != nil {
+			t.Fatalf("Error creating token: %s", err.Error())
+		}
+		return &http.Cookie{Name: api.JWTCookieName, Value: token}
+	}
+	tc := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		cookie     func(*testing.T) *http.Cookie
+		statusCode int
+	}{
+		{
+			name:       "Training manager can't set an admin's password with PUT",
+			method:     http.MethodPut,
+			path:       "/api/member/" + adminID,
+			body:       fmt.Sprintf(`{"id":%q,"password":"takeover1"}`, adminID),
+			cookie:     func(t *testing.T) *http.Cookie { return roleCookie(t, types.RoleTrainingManager) },
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Training manager can't set an admin's password with PATCH",
+			method:     http.MethodPatch,
+			path:       "/api/member/" + adminID,
+			body:       `{"password":"takeover1"}`,
+			cookie:     func(t *testing.T) *http.Cookie { return roleCookie(t, types.RoleTrainingManager) },
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Training manager can't bind a certificate",
+			method:     http.MethodPut,
+			path:       fmt.Sprintf("/api/member/%s/certificate", adminID),
+			body:       `{"certificate_id":"1234567890"}`,
+			cookie:     func(t *testing.T) *http.Cookie { return roleCookie(t, types.RoleTrainingManager) },
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Training manager can't unbind a certificate",
+			method:     http.MethodDelete,
+			path:       fmt.Sprintf("/api/member/%s/certificate", adminID),
+			cookie:     func(t *testing.T) *http.Cookie { return roleCookie(t, types.RoleTrainingManager) },
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Training manager can still update an admin's details",
+			method:     http.MethodPatch,
+			path:       "/api/member/" + adminID,
+			body:       `{"email":"admin@example.com"}`,
+			cookie:     func(t *testing.T) *http.Cookie { return roleCookie(t, types.RoleTrainingManager) },
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Admin can set their own password",
+			method:     http.MethodPatch,
+			path:       "/api/member/" + adminID,
+			body:       `{"password":"newpassword1"}`,
+			cookie:     selfCookie,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Admin can set another admin's password",
+			method:     http.MethodPut,
+			path:       "/api/member/" + adminID,
+			body:       fmt.Sprintf(`{"id":%q,"password":"newpassword1"}`, adminID),
+			cookie:     adminCookie,
+			statusCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			r.Header.Set("If-Match", "*")
+			r.AddCookie(tt.cookie(t))
+			s.ServeHTTP(w, r)
 			if w.Code != tt.statusCode {
-				t.Errorf("Expected status code: %d, got: %d", tt.statusCode, w.Code)
+				t.Errorf("Expected status code %d, got %d: %s", tt.statusCode, w.Code, w.Body.String())
 			}
 		})
 	}
@@ -0,0 +1,434 @@
+package api
+
+import (
+	"PORTal/types"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// operation documents a route in the OpenAPI document served at /api/openapi.json. Every route registered in newServer
+// needs one, keyed by the same pattern.
+type operation struct {
+	// id names the operation in generated clients, the name of the handler serving it
+	id      string
+	summary string
+	// request and response are zero values of the JSON the route reads and writes, nil when there's none, or upload and
+	// download for routes taking or returning a file
+	request, response any
+	// optionalBody routes accept an empty body as well as request
+	optionalBody bool
+	// status is the status of a successful response, 200 when zero
+	status int
+	query  []queryParameter
+	// versioned routes return the version of the record as its ETag, and conditional ones only change the record at the
+	// version in their If-Match header, see ifMatch
+	versioned, conditional bool
+	// tagged lists return an ETag of their contents for If-None-Match, see writeTagged
+	tagged bool
+	// public routes can be called without the identity cookie or an api token
+	public bool
+	// multiTenant routes are only registered when hosting several tenants
+	multiTenant bool
+}
+
+type queryParameter struct {
+	name, description string
+	// kind is the parameter's JSON schema type, string when empty
+	kind string
+	// repeated parameters can be given more than once
+	repeated bool
+}
+
+// upload is the request of routes taking a file, either as the file field of a multipart form or as the raw body, see
+// uploadedFile.
+type upload struct{}
+
+// download is the response of routes returning a file.
+type download struct{}
+
+// mergePatch is the request of PATCH routes, a JSON merge patch of the wrapped value, see readMergePatch.
+type mergePatch struct {
+	of any
+}
+
+var listParameters = []queryParameter{
+	{name: "cursor", description: "next_cursor of the previous page, omitted for the first page"},
+	{name: "limit", description: fmt.Sprintf("Most items on the page, %d by default and at most %d", types.DefaultPageSize, types.MaxPageSize), kind: "integer"},
+	{name: "sort", description: "Key to sort by, prefixed with - to sort descending"},
+}
+
+var memberListParameters = append(listParameters[:len(listParameters):len(listParameters)],
+	queryParameter{name: "rank", description: "Only members holding the rank, as the abbreviation or pay grade"},
+	queryParameter{name: "supervisor_id", description: "Only members with the supervisor"},
+	queryParameter{name: "admin", description: "Only admins, or only members who aren't", kind: "boolean"},
+	queryParameter{name: "qualification_id", description: "Only members assigned the qualification"},
+	queryParameter{name: "archived", description: "false (the default) for active members, true for archived members or any for both. Members can't be filtered on qualification status, see getMemberQualificationStatuses"},
+	queryParameter{name: "unit_id", description: "Only members of the unit or a unit under it", repeated: true},
+)
+
+var operations = map[string]operation{
+	// Members, their qualifications, positions and waivers, imports and transfers
+	"POST /api/member":                                     {id: "addMember", summary: "Add a member", request: types.Member{}, status: http.StatusCreated, response: types.ApiMember{}},
+	"GET /api/member/{id}":                                 {id: "getMember", summary: "Get a member", versioned: true, response: types.ApiMember{}},
+	"GET /api/members":                                     {id: "listMembers", summary: "List members by last_name (default) or rank", tagged: true, query: memberListParameters, response: types.Page[types.ApiMember]{}},
+	"PUT /api/member/{id}":                                 {id: "updateMember", summary: "Update a member, leaving empty fields and the username unchanged", versioned: true, conditional: true, request: types.Member{}, response: types.ApiMember{}},
+	"PATCH /api/member/{id}":                               {id: "patchMember", summary: "Patch a member with a JSON merge patch", versioned: true, conditional: true, request: mergePatch{types.ApiMember{}}, response: types.ApiMember{}},
+	"PUT /api/member/{id}/certificate":                     {id: "bindMemberCertificate", summary: "Bind a CAC certificate to a member", request: CertificateBinding{}, response: types.ApiMember{}},
+	"DELETE /api/member/{id}/certificate":                  {id: "unbindMemberCertificate", summary: "Unbind a member's certificate", response: types.ApiMember{}},
+	"POST /api/member/{id}/archive":                        {id: "archiveMember", summary: "Archive a member who PCSed or separated", request: ArchiveMemberRequest{}, response: types.ApiMember{}},
+	"POST /api/member/{id}/restore":                        {id: "restoreMember", summary: "Restore an archived member", response: types.ApiMember{}},
+	"GET /api/members/archived":                            {id: "getArchivedMembers", summary: "List archived members", response: []types.ApiMember{}},
+	"DELETE /api/admin/member/{id}":                        {id: "purgeMember", summary: "Delete an archived member and their records for good"},
+	"POST /api/admin/members/purge":                        {id: "purgeArchivedMembers", summary: "Delete every archived member past retention", response: PurgeMembersResponse{}},
+	"GET /api/member/{id}/transfer":                        {id: "exportMemberTransfer", summary: "Export a signed package of a member's records", response: types.SignedTransferPackage{}},
+	"POST /api/admin/import/transfer":                      {id: "importMemberTransfer", summary: "Import a member from another instance's transfer package", request: types.SignedTransferPackage{}, status: http.StatusCreated, response: types.TransferImportReport{}},
+	"PUT /api/member/{id}/unit/{unitID}":                   {id: "setMemberUnit", summary: "Move a member into a unit", response: types.ApiMember{}},
+	"DELETE /api/member/{id}/unit":                         {id: "removeMemberUnit", summary: "Take a member out of their unit", response: types.ApiMember{}},
+	"POST /api/admin/import/members":                       {id: "importMembers", summary: "Import members from a CSV roster", request: upload{}, query: []queryParameter{{name: "dry_run", description: "Only validate the roster", kind: "boolean"}}, response: types.MemberImportReport{}},
+	"POST /api/admin/import/profile":                       {id: "addImportProfile", summary: "Add a completion import profile", request: types.ImportProfile{}, status: http.StatusCreated, response: types.ImportProfile{}},
+	"GET /api/admin/import/profiles":                       {id: "getImportProfiles", summary: "List completion import profiles", response: []types.ImportProfile{}},
+	"GET /api/admin/import/profile/{id}":                   {id: "getImportProfile", summary: "Get a completion import profile", response: types.ImportProfile{}},
+	"DELETE /api/admin/import/profile/{id}":                {id: "deleteImportProfile", summary: "Delete a completion import profile"},
+	"POST /api/admin/import/completions":                   {id: "stageCompletionImport", summary: "Stage completions from a spreadsheet for review", request: upload{}, query: []queryParameter{{name: "profile_id", description: "Import profile mapping the spreadsheet's columns"}}, status: http.StatusCreated, response: types.ImportBatch{}},
+	"GET /api/admin/import/completions/{id}":               {id: "getImportBatch", summary: "Get a staged completion import", response: types.ImportBatch{}},
+	"PUT /api/admin/import/completions/{id}/row/{rowID}":   {id: "updateStagedRow", summary: "Fix a row of a staged completion import", request: types.StagedRowUpdate{}, response: types.StagedRow{}},
+	"POST /api/admin/import/completions/{id}/commit":       {id: "commitImportBatch", summary: "Record the completions of a staged import", response: types.ImportBatch{}},
+	"DELETE /api/admin/import/completions/{id}":            {id: "discardImportBatch", summary: "Discard a staged completion import"},
+	"POST /api/member/{id}/qualification/{qualID}":         {id: "assignMemberQualification", summary: "Assign a qualification to a member"},
+	"GET /api/member/{id}/qualifications":                  {id: "getMemberQualifications", summary: "List a member's qualifications", response: []types.Qualification{}},
+	"GET /api/member/{id}/qualification/{qualID}":          {id: "getMemberQualification", summary: "Get a qualification assigned to a member", response: types.Qualification{}},
+	"GET /api/member/{id}/qualifications/status":           {id: "getMemberQualificationStatuses", summary: "Get the status of each of a member's qualifications", response: []types.QualificationStatus{}},
+	"GET /api/member/{id}/qualification/{qualID}/status":   {id: "getMemberQualificationStatus", summary: "Get the status of a member's qualification", response: types.QualificationStatus{}},
+	"GET /api/member/{id}/qualification/{qualID}/history":  {id: "getQualificationHistory", summary: "List changes to a member's qualification", response: []types.QualificationEvent{}},
+	"DELETE /api/member/{id}/qualification/{qualID}":       {id: "removeMemberQualification", summary: "Remove a qualification from a member"},
+	"POST /api/members/qualifications/assign":              {id: "bulkAssignQualifications", summary: "Assign qualifications to several members", request: types.BulkQualificationRequest{}, response: []types.BulkItemResult{}},
+	"POST /api/members/qualifications/remove":              {id: "bulkRemoveQualifications", summary: "Remove qualifications from several members", request: types.BulkQualificationRequest{}, response: []types.BulkItemResult{}},
+	"POST /api/member/{id}/requirement/{reqID}/completion": {id: "submitCompletion", summary: "Submit a completed requirement for sign-off", request: CompletionRequest{}, optionalBody: true, status: http.StatusCreated, response: types.Completion{}},
+	"GET /api/member/{id}/completions":                     {id: "getMemberCompletions", summary: "List a member's completions", response: []types.Completion{}},
+	"GET /api/member/{id}/positions":                       {id: "getMemberDutyPositions", summary: "List a member's duty positions", response: []types.DutyPosition{}},
+	"POST /api/member/{id}/position/{positionID}":          {id: "assignMemberDutyPosition", summary: "Assign a duty position and its qualifications to a member", response: AssignDutyPositionResponse{}},
+	"DELETE /api/member/{id}/position/{positionID}":        {id: "removeMemberDutyPosition", summary: "Remove a duty position from a member", query: []queryParameter{{name: "remove_qualifications", description: "Also remove the qualifications the position assigned that no other held position needs", kind: "boolean"}}, response: types.DutyPositionRemoval{}},
+	"POST /api/member/{id}/waiver":                         {id: "grantWaiver", summary: "Grant a member a waiver", request: types.Waiver{}, status: http.StatusCreated, response: types.Waiver{}},
+	"GET /api/member/{id}/waivers":                         {id: "getMemberWaivers", summary: "List a member's waivers", response: []types.Waiver{}},
+
+	// Qualifications and requirements
+	"POST /api/qualification":                           {id: "addQualification", summary: "Add a qualification", request: types.Qualification{}, status: http.StatusCreated, response: types.Qualification{}},
+	"GET /api/qualification/{id}":                       {id: "getQualification", summary: "Get a qualification", versioned: true, response: types.Qualification{}},
+	"GET /api/qualifications":                           {id: "listQualifications", summary: "List qualifications by name", tagged: true, query: listParameters, response: types.Page[types.Qualification]{}},
+	"GET /api/qualification/{id}/prerequisites":         {id: "getPrerequisites", summary: "List every qualification needed before this one", response: []types.Qualification{}},
+	"PUT /api/qualification/{id}":                       {id: "updateQualification", summary: "Update a qualification", versioned: true, conditional: true, request: types.Qualification{}, response: types.Qualification{}},
+	"PATCH /api/qualification/{id}":                     {id: "patchQualification", summary: "Patch a qualification with a JSON merge patch", versioned: true, conditional: true, request: mergePatch{types.Qualification{}}, response: types.Qualification{}},
+	"DELETE /api/qualification/{id}":                    {id: "deleteQualification", summary: "Delete a qualification", conditional: true},
+	"POST /api/requirement":                             {id: "addRequirement", summary: "Add a requirement", request: types.Requirement{}, status: http.StatusCreated, response: types.Requirement{}},
+	"GET /api/requirement/{id}":                         {id: "getRequirement", summary: "Get a requirement", versioned: true, response: types.Requirement{}},
+	"GET /api/requirements":                             {id: "listRequirements", summary: "List requirements by name", tagged: true, query: listParameters, response: types.Page[types.Requirement]{}},
+	"PUT /api/requirement/{id}":                         {id: "updateRequirement", summary: "Update a requirement", versioned: true, conditional: true, request: types.Requirement{}, response: types.Requirement{}},
+	"PATCH /api/requirement/{id}":                       {id: "patchRequirement", summary: "Patch a requirement with a JSON merge patch", versioned: true, conditional: true, request: mergePatch{types.Requirement{}}, response: types.Requirement{}},
+	"DELETE /api/requirement/{id}":                      {id: "deleteRequirement", summary: "Delete a requirement", conditional: true},
+	"GET /api/requirement/{id}/certifiers":              {id: "getCertifiers", summary: "List who can sign off a requirement", response: []types.ApiMember{}},
+	"PUT /api/requirement/{id}/certifier/{memberID}":    {id: "addCertifier", summary: "Let a member sign off a requirement"},
+	"DELETE /api/requirement/{id}/certifier/{memberID}": {id: "removeCertifier", summary: "Stop a member signing off a requirement"},
+
+	// References
+	"PATCH /api/reference/{id}": {id: "patchReference", summary: "Patch a reference with a JSON merge patch", versioned: true, conditional: true, request: mergePatch{types.Reference{}}, response: types.Reference{}},
+
+	// Completions
+	"GET /api/completion/{id}":          {id: "getCompletion", summary: "Get a completion", response: types.Completion{}},
+	"GET /api/completions/pending":      {id: "getPendingCompletions", summary: "List completions waiting on the caller's sign-off", response: []types.Completion{}},
+	"POST /api/completion/{id}/approve": {id: "approveCompletion", summary: "Sign off a completion", request: ReviewRequest{}, optionalBody: true, response: types.Completion{}},
+	"POST /api/completion/{id}/reject":  {id: "rejectCompletion", summary: "Reject a completion", request: ReviewRequest{}, optionalBody: true, response: types.Completion{}},
+
+	// Duty positions
+	"POST /api/position":        {id: "addDutyPosition", summary: "Add a duty position", request: types.DutyPosition{}, status: http.StatusCreated, response: types.DutyPosition{}},
+	"GET /api/position/{id}":    {id: "getDutyPosition", summary: "Get a duty position", versioned: true, response: types.DutyPosition{}},
+	"GET /api/positions":        {id: "getDutyPositions", summary: "List duty positions", tagged: true, response: []types.DutyPosition{}},
+	"PUT /api/position/{id}":    {id: "updateDutyPosition", summary: "Update a duty position and the members holding it", versioned: true, conditional: true, request: types.DutyPosition{}, query: []queryParameter{{name: "preview", description: "Only report what would change", kind: "boolean"}}, response: types.DutyPositionDiff{}},
+	"DELETE /api/position/{id}": {id: "deleteDutyPosition", summary: "Delete a duty position", conditional: true},
+
+	// Units
+	"POST /api/unit":                         {id: "addUnit", summary: "Add a unit", request: types.Unit{}, status: http.StatusCreated, response: types.Unit{}},
+	"GET /api/unit/{id}":                     {id: "getUnit", summary: "Get a unit", versioned: true, response: types.Unit{}},
+	"GET /api/units":                         {id: "getUnits", summary: "List units", tagged: true, response: []types.Unit{}},
+	"PUT /api/unit/{id}":                     {id: "updateUnit", summary: "Update a unit", versioned: true, conditional: true, request: types.Unit{}, response: types.Unit{}},
+	"DELETE /api/unit/{id}":                  {id: "deleteUnit", summary: "Delete a unit", conditional: true},
+	"PUT /api/unit/{id}/qualifications":      {id: "setUnitQualifications", summary: "Set the qualifications mandatory in a unit", request: UnitQualificationsRequest{}, response: types.Unit{}},
+	"GET /api/unit/{id}/members":             {id: "getUnitMembers", summary: "List the members of a unit and the units under it", response: []types.ApiMember{}},
+	"GET /api/unit/{id}/report":              {id: "getUnitReport", summary: "Report on a unit's training", response: types.UnitReport{}},
+	"GET /api/unit/{id}/admins":              {id: "getUnitAdmins", summary: "List a unit's admins", response: []types.ApiMember{}},
+	"PUT /api/unit/{id}/admin/{memberID}":    {id: "addUnitAdmin", summary: "Make a member a unit admin"},
+	"DELETE /api/unit/{id}/admin/{memberID}": {id: "removeUnitAdmin", summary: "Remove a unit admin"},
+
+	// Waivers
+	"GET /api/waiver/{id}":         {id: "getWaiver", summary: "Get a waiver", response: types.Waiver{}},
+	"GET /api/waiver/{id}/memo":    {id: "getWaiverMemo", summary: "Download a waiver's memo", response: download{}},
+	"POST /api/waiver/{id}/revoke": {id: "revokeWaiver", summary: "Revoke a waiver", request: RevokeWaiverRequest{}, optionalBody: true, response: types.Waiver{}},
+	"GET /api/waiver/{id}/audit":   {id: "getWaiverAudit", summary: "List changes to a waiver", response: []types.AuditEntry{}},
+
+	// Authentication, tokens and roles
+	"POST /api/login":             {id: "login", summary: "Log in with a username and password", request: Credentials{}, response: LoginResponse{}, public: true},
+	"POST /api/login/certificate": {id: "certificateLogin", summary: "Log in with the client certificate of the TLS connection", response: LoginResponse{}, public: true},
+	"GET /api/logout":             {id: "logout", summary: "Clear the identity cookie", public: true},
+	"GET /api/checkAdmin":         {id: "checkAdmin", summary: "Check the caller is an admin"},
+	"POST /api/tokens":            {id: "createAPIToken", summary: "Create an api token, only returned in full once", request: CreateTokenRequest{}, status: http.StatusCreated, response: types.APIToken{}},
+	"GET /api/tokens":             {id: "getAPITokens", summary: "List the caller's api tokens", response: []types.APIToken{}},
+	"DELETE /api/tokens/{id}":     {id: "revokeAPIToken", summary: "Revoke one of the caller's api tokens"},
+	"GET /api/roles":              {id: "getRoles", summary: "List roles and their permissions", response: []types.RoleDefinition{}},
+	"PUT /api/role/{role}":        {id: "updateRolePermissions", summary: "Set a role's permissions", request: RolePermissionsRequest{}, response: types.RoleDefinition{}},
+	"GET /api/openapi.json":       {id: "getOpenAPI", summary: "Get this document", response: map[string]any{}, public: true},
+
+	// Tenants
+	"GET /api/tenant":        {id: "getTenant", summary: "Get the tenant the request resolved to", response: types.Tenant{}, public: true, multiTenant: true},
+	"POST /api/admin/tenant": {id: "addTenant", summary: "Add a tenant along with its first admin", request: AddTenantRequest{}, status: http.StatusCreated, response: AddTenantResponse{}, multiTenant: true},
+	"GET /api/admin/tenants": {id: "getTenants", summary: "List tenants", response: []types.Tenant{}, multiTenant: true},
+}
+
+// enums lists the values of string types that only take a fixed set of them.
+var enums = map[reflect.Type][]string{
+	reflect.TypeOf(types.Rank("")):       enumValues(types.Ranks),
+	reflect.TypeOf(types.Role("")):       enumValues(types.Roles),
+	reflect.TypeOf(types.Permission("")): enumValues(types.Permissions),
+}
+
+func enumValues[T ~string](values []T) []string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = string(v)
+	}
+	return s
+}
+
+var pathParameterPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// openAPIDocument describes the routes the server registers as an OpenAPI 3 document. Schemas are derived from the
+// types routes read and write, the same way encoding/json sees them.
+func openAPIDocument(multiTenant bool) map[string]any {
+	c := components{}
+	paths := map[string]map[string]any{}
+	for pattern, op := range operations {
+		if op.multiTenant && !multiTenant {
+			continue
+		}
+		method, route, _ := strings.Cut(pattern, " ")
+		if paths[route] == nil {
+			paths[route] = map[string]any{}
+		}
+		paths[route][strings.ToLower(method)] = c.operation(route, op)
+	}
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info":    map[string]any{"title": "PORTal", "version": "1"},
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": c,
+			"securitySchemes": map[string]any{
+				"cookie": map[string]any{"type": "apiKey", "in": "cookie", "name": JWTCookieName},
+				"bearer": map[string]any{"type": "http", "scheme": "bearer", "description": "API token"},
+			},
+		},
+		"security": []any{map[string]any{"cookie": []string{}}, map[string]any{"bearer": []string{}}},
+	}
+}
+
+// components holds the schemas of named types, referred to from the rest of the document.
+type components map[string]any
+
+func (c components) operation(route string, op operation) map[string]any {
+	var parameters []any
+	for _, match := range pathParameterPattern.FindAllStringSubmatch(route, -1) {
+		parameters = append(parameters, map[string]any{"name": match[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"}})
+	}
+	for _, q := range op.query {
+		schema := map[string]any{"type": "string"}
+		if q.kind != "" {
+			schema["type"] = q.kind
+		}
+		if q.repeated {
+			schema = map[string]any{"type": "array", "items": schema}
+		}
+		parameters = append(parameters, map[string]any{"name": q.name, "in": "query", "description": q.description, "schema": schema})
+	}
+	if op.conditional {
+		parameters = append(parameters, map[string]any{
+			"name": "If-Match", "in": "header", "required": true, "schema": map[string]any{"type": "string"},
+			"description": "ETag the record was read with, or * to change it whatever its version. 412 if it was changed since, 428 if left out",
+		})
+	}
+	if op.tagged {
+		parameters = append(parameters, map[string]any{
+			"name": "If-None-Match", "in": "header", "schema": map[string]any{"type": "string"},
+			"description": "ETag of a previous response, 304 without a body if nothing changed since",
+		})
+	}
+	status := op.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := map[string]any{"description": http.StatusText(status)}
+	switch op.response.(type) {
+	case nil:
+	case download:
+		success["content"] = map[string]any{"application/octet-stream": map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}}
+	default:
+		success["content"] = map[string]any{"application/json": map[string]any{"schema": c.schema(reflect.TypeOf(op.response))}}
+	}
+	if op.versioned || op.tagged {
+		success["headers"] = map[string]any{"ETag": map[string]any{"schema": map[string]any{"type": "string"}}}
+	}
+	responses := map[string]any{
+		strconv.Itoa(status): success,
+		"default": map[string]any{
+			"description": "The request failed, the code says why",
+			"content":     map[string]any{"application/problem+json": map[string]any{"schema": c.schema(reflect.TypeOf(Problem{}))}},
+		},
+	}
+	if op.tagged {
+		responses[strconv.Itoa(http.StatusNotModified)] = map[string]any{"description": http.StatusText(http.StatusNotModified)}
+	}
+	o := map[string]any{
+		"operationId": op.id,
+		"summary":     op.summary,
+		"responses":   responses,
+	}
+	if parameters != nil {
+		o["parameters"] = parameters
+	}
+	switch request := op.request.(type) {
+	case nil:
+	case mergePatch:
+		o["requestBody"] = map[string]any{
+			"required":    true,
+			"description": "Fields left out are unchanged and null clears them",
+			"content":     map[string]any{mergePatchType: map[string]any{"schema": c.schema(reflect.TypeOf(request.of))}},
+		}
+	case upload:
+		file := map[string]any{"type": "string", "format": "binary"}
+		o["requestBody"] = map[string]any{
+			"required": true,
+			"content": map[string]any{
+				"multipart/form-data":      map[string]any{"schema": map[string]any{"type": "object", "properties": map[string]any{"file": file}, "required": []string{"file"}}},
+				"application/octet-stream": map[string]any{"schema": file},
+			},
+		}
+	default:
+		o["requestBody"] = map[string]any{
+			"required": !op.optionalBody,
+			"content":  map[string]any{"application/json": map[string]any{"schema": c.schema(reflect.TypeOf(op.request))}},
+		}
+	}
+	if op.public {
+		o["security"] = []any{}
+	}
+	return o
+}
+
+// schema returns the schema of t, adding named structs to the components and referring to them.
+func (c components) schema(t reflect.Type) map[string]any {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return map[string]any{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(time.Duration(0)):
+		return map[string]any{"type": "integer", "format": "int64", "description": "nanoseconds"}
+	case reflect.TypeOf(json.RawMessage{}):
+		return map[string]any{}
+	}
+	if values, ok := enums[t]; ok {
+		return map[string]any{"type": "string", "enum": values}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return map[string]any{"allOf": []any{c.schema(t.Elem())}, "nullable": true}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": c.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": c.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return c.object(t)
+		}
+		name := schemaName(t)
+		if _, ok := c[name]; !ok {
+			// Placeholder so types referring to themselves don't recurse forever
+			c[name] = nil
+			c[name] = c.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]any{}
+}
+
+// object describes a struct's fields as encoding/json encodes them, with embedded structs' fields promoted.
+func (c components) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			embedded := c.object(f.Type)
+			for field, schema := range embedded["properties"].(map[string]any) {
+				properties[field] = schema
+			}
+			if fields, ok := embedded["required"].([]string); ok {
+				required = append(required, fields...)
+			}
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = c.schema(f.Type)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	o := map[string]any{"type": "object", "properties": properties}
+	if required != nil {
+		o["required"] = required
+	}
+	return o
+}
+
+// schemaName names a type's schema, Page[types.ApiMember] becoming ApiMemberPage.
+func schemaName(t reflect.Type) string {
+	base, args, generic := strings.Cut(t.Name(), "[")
+	if !generic {
+		return base
+	}
+	var name string
+	for _, arg := range strings.Split(strings.TrimSuffix(args, "]"), ",") {
+		name += arg[strings.LastIndex(arg, ".")+1:]
+	}
+	return name + base
+}
+
+func (s Server) getOpenAPI(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(openAPIDocument(s.tenants != nil)); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing OpenAPI document to client", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,136 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// registeredRoutes returns the pattern of every api route newServer registers in api.go.
+func registeredRoutes(t *testing.T) []string {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "api.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Error parsing api.go: %s", err.Error())
+	}
+	var routes []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "Handle" {
+			return true
+		}
+		if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			pattern, _ := strconv.Unquote(lit.Value)
+			if _, route, _ := strings.Cut(pattern, " "); strings.HasPrefix(route, "/api/") {
+				routes = append(routes, pattern)
+			}
+		}
+		return true
+	})
+	if len(routes) == 0 {
+		t.Fatalf("Found no routes in api.go")
+	}
+	return routes
+}
+
+func getOpenAPI(t *testing.T, s api.Server) map[string]any {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	var doc map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+		t.Fatalf("Error decoding OpenAPI document: %s", err.Error())
+	}
+	return doc
+}
+
+// TestOpenAPICoversRoutes checks every route registered in api.go is documented, and nothing else is.
+func TestOpenAPICoversRoutes(t *testing.T) {
+	doc := getOpenAPI(t, api.NewMultiTenant(slog.Default(), newMockTenants(), false, api.Config{Domain: "portal.com", JWTSecret: "test"}))
+	paths := doc["paths"].(map[string]any)
+	documented := map[string]bool{}
+	operationIDs := map[string]bool{}
+	for route, item := range paths {
+		for method, op := range item.(map[string]any) {
+			documented[strings.ToUpper(method)+" "+route] = true
+			id := op.(map[string]any)["operationId"].(string)
+			if operationIDs[id] {
+				t.Errorf("Operation ID %s is used more than once", id)
+			}
+			operationIDs[id] = true
+		}
+	}
+	for _, pattern := range registeredRoutes(t) {
+		if !documented[pattern] {
+			t.Errorf("%s is registered but missing from the OpenAPI document", pattern)
+		}
+		delete(documented, pattern)
+	}
+	for pattern := range documented {
+		t.Errorf("%s is documented but not registered", pattern)
+	}
+
+	single := getOpenAPI(t, api.New(slog.Default(), newMockBackend(), false, api.Config{JWTSecret: "test"}))
+	if _, ok := single["paths"].(map[string]any)["/api/admin/tenants"]; ok {
+		t.Errorf("Expected tenant routes to be left out of single tenant deployments")
+	}
+}
+
+var refPattern = regexp.MustCompile(`"\$ref":"#/components/schemas/(\w+)"`)
+
+// TestOpenAPISchemas checks every reference resolves and the schemas follow the JSON the types encode to.
+func TestOpenAPISchemas(t *testing.T) {
+	doc := getOpenAPI(t, api.New(slog.Default(), newMockBackend(), false, api.Config{JWTSecret: "test"}))
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	raw, _ := json.Marshal(doc)
+	for _, match := range refPattern.FindAllStringSubmatch(string(raw), -1) {
+		if schemas[match[1]] == nil {
+			t.Errorf("Schema %s is referred to but not defined", match[1])
+		}
+	}
+
+	tc := []struct {
+		schema     string
+		properties []string
+	}{
+		{schema: "ApiMember", properties: []string{"id", "first_name", "last_name", "rank", "supervisor_id", "archive", "version"}},
+		{schema: "Member", properties: []string{"id", "last_name", "password"}},
+		{schema: "Qualification", properties: []string{"id", "name", "initial_requirements", "recurring_requirements", "version"}},
+		{schema: "Requirement", properties: []string{"id", "name", "reference", "days_valid_for"}},
+		{schema: "Reference", properties: []string{"id", "name", "volume", "paragraph"}},
+		{schema: "LoginResponse", properties: []string{"member", "qualifications", "subordinates", "permissions"}},
+		{schema: "ApiMemberPage", properties: []string{"items", "total", "next_cursor"}},
+	}
+	for _, tt := range tc {
+		schema, ok := schemas[tt.schema].(map[string]any)
+		if !ok {
+			t.Errorf("Expected a %s schema", tt.schema)
+			continue
+		}
+		properties := schema["properties"].(map[string]any)
+		for _, p := range tt.properties {
+			if _, ok := properties[p]; !ok {
+				t.Errorf("Expected %s to have property %s, got: %v", tt.schema, p, properties)
+			}
+		}
+	}
+	rank := schemas["ApiMember"].(map[string]any)["properties"].(map[string]any)["rank"].(map[string]any)
+	if enum, _ := rank["enum"].([]any); len(enum) != 9 || enum[0] != "AB" {
+		t.Errorf("Expected rank to list the ranks in order, got: %v", rank)
+	}
+}
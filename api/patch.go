@@ -0,0 +1,29 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// mergePatchType is the media type of RFC 7396 JSON merge patches, which every PATCH route accepts. Plain
+// application/json is taken as a merge patch too, since that's what most clients send.
+const mergePatchType = "application/merge-patch+json"
+
+// readMergePatch reads the body of a PATCH request. The patch itself is applied by the backend, which knows which
+// fields may be patched.
+func readMergePatch(r *http.Request) ([]byte, int, error) {
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != mergePatchType && mediaType != "application/json") {
+			return nil, http.StatusUnsupportedMediaType, fmt.Errorf("%w: expected %s, got %s", errUnsupportedMediaType, mergePatchType, contentType)
+		}
+	}
+	defer r.Body.Close()
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, http.StatusBadRequest, invalidBody(err)
+	}
+	return patch, http.StatusOK, nil
+}
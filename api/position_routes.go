@@ -0,0 +1,209 @@
+package api
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+func (s Server) addDutyPosition(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	var position types.DutyPosition
+	if err := json.NewDecoder(r.Body).Decode(&position); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid duty position JSON sent from client", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	position, err := s.backendFor(r).AddDutyPosition(r.Context(), position)
+	if errors.Is(err, backend.ErrMissingArgs) || errors.Is(err, backend.ErrQualificationNotFound) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, backend.ErrDuplicateDutyPosition) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(position); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing duty position to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getDutyPosition(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	position, err := s.backendFor(r).GetDutyPosition(r.Context(), r.PathValue("id"))
+	if errors.Is(err, backend.ErrDutyPositionNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	setVersionTag(w, position.Version)
+	if err = json.NewEncoder(w).Encode(position); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing duty position to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getDutyPositions(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	positions, err := s.backendFor(r).GetDutyPositions(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = writeTagged(w, r, positions); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing duty positions to client", slog.String("error", err.Error()))
+	}
+}
+
+// updateDutyPosition only previews the change when called with ?preview=true.
+func (s Server) updateDutyPosition(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	preview, ok := boolQuery(r, "preview")
+	if !ok {
+		writeError(w, http.StatusBadRequest, invalidQuery("preview"))
+		return
+	}
+	version, status, err := ifMatch(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Missing or invalid If-Match updating duty position", slog.String("error", err.Error()))
+		writeError(w, status, err)
+		return
+	}
+	var position types.DutyPosition
+	if err := json.NewDecoder(r.Body).Decode(&position); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid duty position JSON sent from client", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	position.ID = r.PathValue("id")
+	position.Version = version
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	diff, err := s.backendFor(r).UpdateDutyPosition(r.Context(), caller.MemberID, position, !preview)
+	if errors.Is(err, backend.ErrDutyPositionNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrQualificationNotFound) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, backend.ErrDuplicateDutyPosition) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if errors.Is(err, backend.ErrVersionConflict) {
+		writeError(w, http.StatusPreconditionFailed, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	setVersionTag(w, diff.UpdatedPosition.Version)
+	if err = json.NewEncoder(w).Encode(diff); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing duty position diff to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) deleteDutyPosition(w http.ResponseWriter, r *http.Request) {
+	version, status, err := ifMatch(r)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+	err = s.backendFor(r).DeleteDutyPosition(r.Context(), r.PathValue("id"), version)
+	if errors.Is(err, backend.ErrDutyPositionNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrVersionConflict) {
+		writeError(w, http.StatusPreconditionFailed, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s Server) assignMemberDutyPosition(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	assigned, err := s.backendFor(r).AssignMemberDutyPosition(r.Context(), caller.MemberID, r.PathValue("id"), r.PathValue("positionID"))
+	if errors.Is(err, backend.ErrMemberNotFound) || errors.Is(err, backend.ErrDutyPositionNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrDutyPositionAlreadyAssigned) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(AssignDutyPositionResponse{AssignedQualifications: assigned}); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing assigned qualifications to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getMemberDutyPositions(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	positions, err := s.backendFor(r).GetMemberDutyPositions(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(positions); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing duty positions to client", slog.String("error", err.Error()))
+	}
+}
+
+// removeMemberDutyPosition leaves the position's qualifications in place unless called with
+// ?remove_qualifications=true. Either way the response lists the ones that could be removed.
+func (s Server) removeMemberDutyPosition(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	removeQualifications, ok := boolQuery(r, "remove_qualifications")
+	if !ok {
+		writeError(w, http.StatusBadRequest, invalidQuery("remove_qualifications"))
+		return
+	}
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	removal, err := s.backendFor(r).RemoveMemberDutyPosition(r.Context(), caller.MemberID, r.PathValue("id"), r.PathValue("positionID"), removeQualifications)
+	if errors.Is(err, backend.ErrDutyPositionNotFound) || errors.Is(err, backend.ErrMemberDutyPositionNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(removal); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing duty position removal to client", slog.String("error", err.Error()))
+	}
+}
+
+// boolQuery reads an optional boolean query parameter. ok is false when the parameter is present but isn't a bool.
+func boolQuery(r *http.Request, name string) (value, ok bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return false, true
+	}
+	value, err := strconv.ParseBool(raw)
+	return value, err == nil
+}
@@ -0,0 +1,168 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/backend"
+	"PORTal/types"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUpdateDutyPosition(t *testing.T) {
+	b := newMockBackend()
+	positionID := uuid.NewString()
+	b.updateDutyPositionOverride = func(actorID string, d types.DutyPosition, apply bool) (types.DutyPositionDiff, error) {
+		if d.ID != positionID {
+			return types.DutyPositionDiff{}, backend.ErrDutyPositionNotFound
+		}
+		return types.DutyPositionDiff{PositionID: d.ID, AddedQualifications: d.Qualifications, Applied: apply}, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name            string
+		cookie          *http.Cookie
+		positionID      string
+		query           string
+		statusCode      int
+		expectedApplied bool
+	}{
+		{
+			name:            "Apply change",
+			cookie:          roleCookie(t, types.RoleTrainingManager),
+			positionID:      positionID,
+			statusCode:      http.StatusOK,
+			expectedApplied: true,
+		},
+		{
+			name:       "Preview change",
+			cookie:     roleCookie(t, types.RoleTrainingManager),
+			positionID: positionID,
+			query:      "?preview=true",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Invalid preview flag",
+			cookie:     roleCookie(t, types.RoleTrainingManager),
+			positionID: positionID,
+			query:      "?preview=maybe",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Position not found",
+			cookie:     roleCookie(t, types.RoleTrainingManager),
+			positionID: uuid.NewString(),
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Supervisor can't change bundle",
+			cookie:     roleCookie(t, types.RoleSupervisor),
+			positionID: positionID,
+			statusCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/position/%s%s", tt.positionID, tt.query), strings.NewReader(`{"qualifications":["a"]}`))
+			r.Header.Set("If-Match", "*")
+			r.AddCookie(tt.cookie)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var diff types.DutyPositionDiff
+			if err := json.NewDecoder(w.Body).Decode(&diff); err != nil {
+				t.Fatalf("Error decoding duty position diff: %s", err.Error())
+			}
+			if diff.Applied != tt.expectedApplied {
+				t.Errorf("Expected applied to be %t, got %t", tt.expectedApplied, diff.Applied)
+			}
+		})
+	}
+}
+
+func TestRemoveMemberDutyPosition(t *testing.T) {
+	b := newMockBackend()
+	memberID := uuid.NewString()
+	positionID := uuid.NewString()
+	b.removeMemberDutyPositionOverride = func(actorID, mID, pID string, removeQualifications bool) (types.DutyPositionRemoval, error) {
+		if mID != memberID || pID != positionID {
+			return types.DutyPositionRemoval{}, backend.ErrMemberDutyPositionNotFound
+		}
+		removal := types.DutyPositionRemoval{RemovableQualifications: []string{"a"}, RemovedQualifications: []string{}}
+		if removeQualifications {
+			removal.RemovedQualifications = removal.RemovableQualifications
+		}
+		return removal, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name            string
+		cookie          *http.Cookie
+		memberID        string
+		query           string
+		statusCode      int
+		expectedRemoved []string
+	}{
+		{
+			name:            "Keep qualifications",
+			cookie:          roleCookie(t, types.RoleSupervisor),
+			memberID:        memberID,
+			statusCode:      http.StatusOK,
+			expectedRemoved: []string{},
+		},
+		{
+			name:            "Remove qualifications",
+			cookie:          roleCookie(t, types.RoleSupervisor),
+			memberID:        memberID,
+			query:           "?remove_qualifications=true",
+			statusCode:      http.StatusOK,
+			expectedRemoved: []string{"a"},
+		},
+		{
+			name:       "Position not held",
+			cookie:     roleCookie(t, types.RoleSupervisor),
+			memberID:   uuid.NewString(),
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Member can't remove positions",
+			cookie:     roleCookie(t, types.RoleMember),
+			memberID:   memberID,
+			statusCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/member/%s/position/%s%s", tt.memberID, positionID, tt.query), nil)
+			r.AddCookie(tt.cookie)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var removal types.DutyPositionRemoval
+			if err := json.NewDecoder(w.Body).Decode(&removal); err != nil {
+				t.Fatalf("Error decoding duty position removal: %s", err.Error())
+			}
+			if !reflect.DeepEqual(removal.RemovedQualifications, tt.expectedRemoved) {
+				t.Errorf("Expected removed qualifications: %v, got: %v", tt.expectedRemoved, removal.RemovedQualifications)
+			}
+		})
+	}
+}
@@ -17,113 +17,141 @@ func (s Server) addQualification(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&q)
 	if err != nil {
 		l.LogAttrs(r.Context(), slog.LevelWarn, "Error deserializing body into qualification struct", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, invalidBody(err))
 		return
 	}
 	defer r.Body.Close()
 
 	if err = validateQualification(q); err != nil {
 		l.LogAttrs(r.Context(), slog.LevelWarn, "Incomplete qualification creation request", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
 	id := uuid.NewString()
 	q.ID = id
-	qual, err := s.backend.AddQualification(q)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+	qual, err := s.backendFor(r).AddQualification(r.Context(), q)
+	if errors.Is(err, backend.ErrPrerequisiteCycle) || errors.Is(err, backend.ErrQualificationNotFound) {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid prerequisites for qualification", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
 	if err = json.NewEncoder(w).Encode(qual); err != nil {
 		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing qualification to client", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
 
 func (s Server) getQualification(w http.ResponseWriter, r *http.Request) {
 	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
 	id := r.PathValue("id")
-	q, err := s.backend.GetQualification(id)
+	q, err := s.backendFor(r).GetQualification(r.Context(), id)
 	if errors.Is(err, backend.ErrQualificationNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
 		return
 	}
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
+	setVersionTag(w, q.Version)
 	err = json.NewEncoder(w).Encode(q)
 	if err != nil {
 		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing qualification to client", slog.String("error", err.Error()))
 	}
 }
 
-func (s Server) getAllQualifications(w http.ResponseWriter, r *http.Request) {
+// listQualifications returns a page of qualifications, sorted by name unless ?sort=-name.
+func (s Server) listQualifications(w http.ResponseWriter, r *http.Request) {
 	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
-	quals, err := s.backend.GetAllQualifications()
+	opts, err := parseListOptions(r)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Invalid list qualifications request", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
-	err = json.NewEncoder(w).Encode(quals)
-	if err != nil {
-		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing []types.Qualification to client", slog.String("error", err.Error()))
+	page, err := s.backendFor(r).ListQualifications(r.Context(), opts)
+	if errors.Is(err, backend.ErrInvalidListOptions) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = writeTagged(w, r, page); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing page of qualifications to client", slog.String("error", err.Error()))
 	}
 }
 
 func (s Server) updateQualification(w http.ResponseWriter, r *http.Request) {
 	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	version, status, err := ifMatch(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Missing or invalid If-Match updating qualification", slog.String("error", err.Error()))
+		writeError(w, status, err)
+		return
+	}
 	var q types.Qualification
-	err := json.NewDecoder(r.Body).Decode(&q)
+	err = json.NewDecoder(r.Body).Decode(&q)
 	if err != nil {
 		l.LogAttrs(r.Context(), slog.LevelError, "Error decoding qualification into struct", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, invalidBody(err))
 		return
 	}
+	q.Version = version
 	//TODO: Implement authorization so that only the correct user is allowed to update an account
-	existingQualification, err := s.backend.GetQualification(q.ID)
+	existingQualification, err := s.backendFor(r).GetQualification(r.Context(), q.ID)
 	if errors.Is(err, backend.ErrQualificationNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
 		return
 	} else if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	if existingQualification.ID != q.ID {
 		l.LogAttrs(r.Context(), slog.LevelWarn, "User requesting to update qualification ID", slog.Any("update_request", q))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: ID doesn't match path", backend.ErrBadUpdate))
 		return
 	}
 	//TODO: fix this shit
 	for _, req := range q.InitialRequirements {
 		l.LogAttrs(r.Context(), slog.LevelInfo, "Verifying that all provided initial requirements exist...")
-		_, err := s.backend.GetRequirement(req.ID)
+		_, err := s.backendFor(r).GetRequirement(r.Context(), req.ID)
 		if errors.Is(err, backend.ErrRequirementNotFound) {
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, err)
 			return
 		} else if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, err)
 			return
 		}
 	}
 	for _, req := range q.RecurringRequirements {
 		l.LogAttrs(r.Context(), slog.LevelInfo, "Verifying that all provided recurring requirements exist...")
-		_, err := s.backend.GetRequirement(req.ID)
+		_, err := s.backendFor(r).GetRequirement(r.Context(), req.ID)
 		if errors.Is(err, backend.ErrRequirementNotFound) {
-			w.WriteHeader(http.StatusBadRequest)
+			writeError(w, http.StatusBadRequest, err)
 			return
 		} else if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, err)
 			return
 		}
 	}
 	forceExpiration := q.Expires == false
-	qualification, err := s.backend.UpdateQualification(q, forceExpiration)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+	qualification, err := s.backendFor(r).UpdateQualification(r.Context(), q, forceExpiration)
+	if errors.Is(err, backend.ErrPrerequisiteCycle) || errors.Is(err, backend.ErrQualificationNotFound) {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid prerequisites for qualification", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, backend.ErrVersionConflict) {
+		writeError(w, http.StatusPreconditionFailed, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
+	setVersionTag(w, qualification.Version)
 	err = json.NewEncoder(w).Encode(qualification)
 	if err != nil {
 		s.logger.LogAttrs(r.Context(), slog.LevelError, "Error serializing qualification to client", slog.String("error", err.Error()))
@@ -135,20 +163,88 @@ func (s Server) deleteQualification(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if _, err := uuid.Parse(id); err != nil {
 		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid UUID provided", slog.String("id", id))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, errInvalidID)
+		return
+	}
+	version, status, err := ifMatch(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Missing or invalid If-Match deleting qualification", slog.String("error", err.Error()))
+		writeError(w, status, err)
 		return
 	}
-	err := s.backend.DeleteQualification(id)
+	err = s.backendFor(r).DeleteQualification(r.Context(), id, version)
 	if errors.Is(err, backend.ErrQualificationNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrVersionConflict) {
+		writeError(w, http.StatusPreconditionFailed, err)
 		return
 	} else if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
+func (s Server) getPrerequisites(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	prerequisites, err := s.backendFor(r).GetPrerequisites(r.Context(), r.PathValue("id"))
+	if errors.Is(err, backend.ErrQualificationNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(prerequisites); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing prerequisites to client", slog.String("error", err.Error()))
+	}
+}
+
+// patchQualification applies a JSON merge patch to a qualification, so null clears its notes and requirement lists.
+func (s Server) patchQualification(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	id := r.PathValue("id")
+	version, status, err := ifMatch(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Missing or invalid If-Match patching qualification", slog.String("error", err.Error()))
+		writeError(w, status, err)
+		return
+	}
+	patch, status, err := readMergePatch(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Error reading qualification patch", slog.String("error", err.Error()))
+		writeError(w, status, err)
+		return
+	}
+	// Check the qualification exists up front, a missing prerequisite is also reported as ErrQualificationNotFound
+	if _, err = s.backendFor(r).GetQualification(r.Context(), id); errors.Is(err, backend.ErrQualificationNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	qualification, err := s.backendFor(r).PatchQualification(r.Context(), id, version, patch)
+	if errors.Is(err, backend.ErrVersionConflict) {
+		writeError(w, http.StatusPreconditionFailed, err)
+		return
+	} else if errors.Is(err, backend.ErrInvalidPatch) || errors.Is(err, backend.ErrBadUpdate) || errors.Is(err, backend.ErrMissingArgs) ||
+		errors.Is(err, backend.ErrRequirementNotFound) || errors.Is(err, backend.ErrQualificationNotFound) ||
+		errors.Is(err, backend.ErrPrerequisiteCycle) {
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Invalid qualification patch", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	setVersionTag(w, qualification.Version)
+	if err = json.NewEncoder(w).Encode(qualification); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing qualification to client", slog.String("error", err.Error()))
+	}
+}
+
 func validateQualification(q types.Qualification) error {
 	errs := []string{}
 	if q.Name == "" {
@@ -158,7 +254,7 @@ func validateQualification(q types.Qualification) error {
 		errs = append(errs, "ExpirationDays")
 	}
 	if len(errs) > 0 {
-		return fmt.Errorf("missing required values: %s", errs)
+		return backend.MissingArgsError{Fields: errs}
 	}
 	return nil
 }
@@ -12,6 +12,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -73,6 +74,7 @@ func TestAddQualification(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodPost, "/api/qualification", strings.NewReader(tt.body))
+			r.AddCookie(adminCookie(t))
 
 			s.ServeHTTP(w, r)
 			if w.Code != tt.statusCode {
@@ -142,6 +144,7 @@ func TestGetQualification(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/qualification/%s", tt.id), nil)
+			r.AddCookie(adminCookie(t))
 			s.ServeHTTP(w, r)
 			if w.Code != tt.statusCode {
 				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
@@ -179,11 +182,11 @@ func TestGetAllQualifications(t *testing.T) {
 
 	b := newMockBackend()
 	shouldSucceed := false
-	b.getAllQualificationsOverride = func() ([]types.Qualification, error) {
+	b.listQualificationsOverride = func(opts types.ListOptions) (types.Page[types.Qualification], error) {
 		if shouldSucceed {
-			return []types.Qualification{testQualification, testQualification2}, nil
+			return types.Page[types.Qualification]{Items: []types.Qualification{testQualification, testQualification2}, Total: 2}, nil
 		}
-		return nil, errors.New("generic error")
+		return types.Page[types.Qualification]{}, errors.New("generic error")
 	}
 	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
 
@@ -211,6 +214,7 @@ func TestGetAllQualifications(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodGet, "/api/qualifications", nil)
+			r.AddCookie(adminCookie(t))
 			shouldSucceed = tt.shouldSucceed
 			s.ServeHTTP(w, r)
 
@@ -219,7 +223,7 @@ func TestGetAllQualifications(t *testing.T) {
 			}
 			if shouldSucceed {
 				b := &bytes.Buffer{}
-				json.NewEncoder(b).Encode(tt.expectedResponse)
+				json.NewEncoder(b).Encode(types.Page[types.Qualification]{Items: tt.expectedResponse, Total: len(tt.expectedResponse)})
 				if b.String() != w.Body.String() {
 					t.Errorf("Expected response: %s\nGot: %s", b.String(), w.Body.String())
 				}
@@ -320,6 +324,57 @@ func TestUpdateQualification(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodPut, "/api/qualification/irrelevant", strings.NewReader(tt.body))
+			r.Header.Set("If-Match", "*")
+			r.AddCookie(adminCookie(t))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected response code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestPatchQualification(t *testing.T) {
+	b := newMockBackend()
+	b.getQualificationOverride = func(id string) (types.Qualification, error) {
+		if id == "not-found" {
+			return types.Qualification{}, backend.ErrQualificationNotFound
+		}
+		return types.Qualification{ID: id}, nil
+	}
+	b.patchQualificationOverride = func(id string, patch []byte) (types.Qualification, error) {
+		switch id {
+		case "missing-prerequisite":
+			return types.Qualification{}, backend.ErrQualificationNotFound
+		case "cycle":
+			return types.Qualification{}, backend.ErrPrerequisiteCycle
+		case "error":
+			return types.Qualification{}, errors.New("generic error")
+		}
+		return types.Qualification{ID: id}, nil
+	}
+
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		id         string
+		statusCode int
+	}{
+		{name: "Successful patch", id: "found", statusCode: http.StatusOK},
+		{name: "Qualification not found", id: "not-found", statusCode: http.StatusNotFound},
+		{name: "Prerequisite not found", id: "missing-prerequisite", statusCode: http.StatusBadRequest},
+		{name: "Prerequisite cycle", id: "cycle", statusCode: http.StatusBadRequest},
+		{name: "Backend error", id: "error", statusCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/api/qualification/%s", tt.id), strings.NewReader(`{"notes":null}`))
+			r.Header.Set("If-Match", "*")
+			r.Header.Set("Content-Type", "application/merge-patch+json")
+			r.AddCookie(adminCookie(t))
 			s.ServeHTTP(w, r)
 			if w.Code != tt.statusCode {
 				t.Errorf("Expected response code %d, got %d", tt.statusCode, w.Code)
@@ -380,6 +435,8 @@ func TestDeleteQualification(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/qualification/%s", tt.id), nil)
+			r.Header.Set("If-Match", "*")
+			r.AddCookie(adminCookie(t))
 			s.ServeHTTP(w, r)
 
 			if w.Code != tt.statusCode {
@@ -388,3 +445,77 @@ func TestDeleteQualification(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPrerequisites(t *testing.T) {
+	vehicleOperator := types.Qualification{ID: uuid.NewString(), Name: "Vehicle Operator"}
+	forklift := types.Qualification{ID: uuid.NewString(), Name: "Forklift Operator", Prerequisites: []string{vehicleOperator.ID}}
+	b := newMockBackend()
+	b.getPrerequisitesOverride = func(id string) ([]types.Qualification, error) {
+		if id == forklift.ID {
+			return []types.Qualification{vehicleOperator}, nil
+		}
+		if id == vehicleOperator.ID {
+			return []types.Qualification{}, nil
+		}
+		return nil, backend.ErrQualificationNotFound
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		id         string
+		expected   []types.Qualification
+		statusCode int
+	}{
+		{
+			name:       "Has prerequisites",
+			id:         forklift.ID,
+			expected:   []types.Qualification{vehicleOperator},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "No prerequisites",
+			id:         vehicleOperator.ID,
+			expected:   []types.Qualification{},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Qualification not found",
+			id:         uuid.NewString(),
+			statusCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/qualification/%s/prerequisites", tt.id), nil)
+			r.AddCookie(adminCookie(t))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var got []types.Qualification
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("Error decoding prerequisites: %s", err.Error())
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected prerequisites: %+v\nGot: %+v", tt.expected, got)
+			}
+		})
+	}
+
+	// Cycles are rejected as bad requests
+	b.addQualificationOverride = func(q types.Qualification) (types.Qualification, error) {
+		return types.Qualification{}, backend.ErrPrerequisiteCycle
+	}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/qualification", strings.NewReader(fmt.Sprintf(`{"name":"Loop","prerequisites":["%s"]}`, forklift.ID)))
+	r.AddCookie(adminCookie(t))
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for prerequisite cycle, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"PORTal/backend"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// patchReference applies a JSON merge patch to a reference, so null clears its volume.
+func (s Server) patchReference(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	version, status, err := ifMatch(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Missing or invalid If-Match patching reference", slog.String("error", err.Error()))
+		writeError(w, status, err)
+		return
+	}
+	patch, status, err := readMergePatch(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Error reading reference patch", slog.String("error", err.Error()))
+		writeError(w, status, err)
+		return
+	}
+	reference, err := s.backendFor(r).PatchReference(r.Context(), r.PathValue("id"), version, patch)
+	if errors.Is(err, backend.ErrReferenceNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrInvalidPatch) || errors.Is(err, backend.ErrBadUpdate) || errors.Is(err, backend.ErrMissingArgs) {
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Invalid reference patch", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, backend.ErrVersionConflict) {
+		writeError(w, http.StatusPreconditionFailed, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	setVersionTag(w, reference.Version)
+	if err = json.NewEncoder(w).Encode(reference); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing reference to client", slog.String("error", err.Error()))
+	}
+}
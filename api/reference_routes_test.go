@@ -0,0 +1,61 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/backend"
+	"PORTal/types"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPatchReference(t *testing.T) {
+	b := newMockBackend()
+	b.patchReferenceOverride = func(id string, patch []byte) (types.Reference, error) {
+		switch id {
+		case "not-found":
+			return types.Reference{}, backend.ErrReferenceNotFound
+		case "missing":
+			return types.Reference{}, backend.MissingArgsError{Fields: []string{"Paragraph"}}
+		case "error":
+			return types.Reference{}, errors.New("generic error")
+		}
+		return types.Reference{ID: id}, nil
+	}
+
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		id         string
+		cookie     *http.Cookie
+		statusCode int
+	}{
+		{name: "Successful patch", id: "found", statusCode: http.StatusOK},
+		{name: "Reference not found", id: "not-found", statusCode: http.StatusNotFound},
+		{name: "Required field cleared", id: "missing", statusCode: http.StatusBadRequest},
+		{name: "Backend error", id: "error", statusCode: http.StatusInternalServerError},
+		{name: "Not permitted", id: "found", cookie: roleCookie(t, types.RoleSupervisor), statusCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/api/reference/%s", tt.id), strings.NewReader(`{"volume":null}`))
+			r.Header.Set("If-Match", "*")
+			r.Header.Set("Content-Type", "application/merge-patch+json")
+			if tt.cookie == nil {
+				tt.cookie = adminCookie(t)
+			}
+			r.AddCookie(tt.cookie)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected response code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
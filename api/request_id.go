@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+)
+
+// RequestIDHeader carries the request ID. One sent by the client, e.g. from a proxy in front of the server, is kept so
+// its log lines can be matched up with ours.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength stops clients from stuffing arbitrarily long values into every log line.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// withRequestID tags the request with an ID and echoes it back in the response headers.
+func withRequestID(w http.ResponseWriter, r *http.Request) *http.Request {
+	id := r.Header.Get(RequestIDHeader)
+	if id == "" || len(id) > maxRequestIDLength {
+		id = uuid.NewString()
+	}
+	w.Header().Set(RequestIDHeader, id)
+	return r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+}
+
+// RequestID returns the ID of the request the context belongs to, or "" outside a request.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// logHandler adds the request ID to every record logged with a request's context.
+type logHandler struct {
+	slog.Handler
+}
+
+// NewLogHandler wraps h so log lines written during a request carry its request_id.
+func NewLogHandler(h slog.Handler) slog.Handler {
+	return logHandler{h}
+}
+
+func (h logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return logHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h logHandler) WithGroup(name string) slog.Handler {
+	return logHandler{h.Handler.WithGroup(name)}
+}
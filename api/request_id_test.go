@@ -0,0 +1,94 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	tc := []struct {
+		Name     string
+		Incoming string
+		Keep     bool
+	}{
+		{Name: "Generated when missing", Incoming: "", Keep: false},
+		{Name: "Kept from client", Incoming: "proxy-1234", Keep: true},
+		{Name: "Replaced when too long", Incoming: strings.Repeat("a", 200), Keep: false},
+	}
+	for _, tt := range tc {
+		t.Run(tt.Name, func(t *testing.T) {
+			var logs bytes.Buffer
+			logger := slog.New(api.NewLogHandler(slog.NewJSONHandler(&logs, nil)))
+			s := api.New(logger, newMockBackend(), false, api.Config{JWTSecret: "test"})
+			// malformed JSON gets the add member handler to log a line we can check
+			r := httptest.NewRequest(http.MethodPost, "/api/member", strings.NewReader("{"))
+			r.AddCookie(adminCookie(t))
+			if tt.Incoming != "" {
+				r.Header.Set(api.RequestIDHeader, tt.Incoming)
+			}
+			w := httptest.NewRecorder()
+			s.ServeHTTP(w, r)
+
+			id := w.Header().Get(api.RequestIDHeader)
+			if id == "" {
+				t.Fatalf("Expected a %s response header", api.RequestIDHeader)
+			}
+			if tt.Keep && id != tt.Incoming {
+				t.Errorf("Expected request ID %s, got %s", tt.Incoming, id)
+			}
+			if !tt.Keep && id == tt.Incoming {
+				t.Errorf("Expected a generated request ID, got the client's")
+			}
+			found := false
+			for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+				var record map[string]any
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("Error parsing log line %q: %s", line, err.Error())
+				}
+				if record["msg"] == "Error deserializing body into member struct" {
+					found = true
+					if record["request_id"] != id {
+						t.Errorf("Expected log line to carry request_id %s, got %v", id, record["request_id"])
+					}
+				}
+			}
+			if !found {
+				t.Errorf("Expected the handler to log the bad request")
+			}
+		})
+	}
+}
+
+func TestRequestIDInvalidToken(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(api.NewLogHandler(slog.NewJSONHandler(&logs, nil)))
+	s := api.New(logger, newMockBackend(), false, api.Config{JWTSecret: "test"})
+	r := httptest.NewRequest(http.MethodPost, "/api/member", strings.NewReader("{}"))
+	r.AddCookie(&http.Cookie{Name: api.JWTCookieName, Value: "not-a-token"})
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	id := w.Header().Get(api.RequestIDHeader)
+	found := false
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Error parsing log line %q: %s", line, err.Error())
+		}
+		if record["msg"] == "Error validating token" {
+			found = true
+			if record["request_id"] != id {
+				t.Errorf("Expected token validation log line to carry request_id %s, got %v", id, record["request_id"])
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected the invalid token to be logged")
+	}
+}
@@ -17,19 +17,18 @@ func (s Server) addRequirement(w http.ResponseWriter, r *http.Request) {
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		l.LogAttrs(r.Context(), slog.LevelError, "Invalid requirement JSON sent from client", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, invalidBody(err))
 		return
 	}
 	defer r.Body.Close()
-	req, err = s.backend.AddRequirement(req)
+	req, err = s.backendFor(r).AddRequirement(r.Context(), req)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
 	if err = json.NewEncoder(w).Encode(req); err != nil {
 		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing requirement to client", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
 
@@ -38,68 +37,89 @@ func (s Server) getRequirement(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if _, err := uuid.Parse(id); err != nil {
 		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid UUID supplied by client", slog.String("id", id))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, errInvalidID)
 		return
 	}
-	requirement, err := s.backend.GetRequirement(id)
+	requirement, err := s.backendFor(r).GetRequirement(r.Context(), id)
 	if errors.Is(err, backend.ErrRequirementNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
 		return
 	} else if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
+	setVersionTag(w, requirement.Version)
 	err = json.NewEncoder(w).Encode(requirement)
 	if err != nil {
 		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing requirement to client", slog.String("error", err.Error()))
 	}
 }
 
-func (s Server) getAllRequirements(w http.ResponseWriter, r *http.Request) {
+// listRequirements returns a page of requirements, sorted by name unless ?sort=-name.
+func (s Server) listRequirements(w http.ResponseWriter, r *http.Request) {
 	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
-	reqs, err := s.backend.GetAllRequirements()
+	opts, err := parseListOptions(r)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Invalid list requirements request", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
-	err = json.NewEncoder(w).Encode(reqs)
-	if err != nil {
-		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing slice of requirements to client", slog.String("error", err.Error()))
+	page, err := s.backendFor(r).ListRequirements(r.Context(), opts)
+	if errors.Is(err, backend.ErrInvalidListOptions) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = writeTagged(w, r, page); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing page of requirements to client", slog.String("error", err.Error()))
 	}
 }
 
 func (s Server) updateRequirement(w http.ResponseWriter, r *http.Request) {
 	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	version, status, err := ifMatch(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Missing or invalid If-Match updating requirement", slog.String("error", err.Error()))
+		writeError(w, status, err)
+		return
+	}
 	var req types.Requirement
-	err := json.NewDecoder(r.Body).Decode(&req)
+	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid requirement JSON received from client", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, invalidBody(err))
 		return
 	}
 	defer r.Body.Close()
-	originalRequirement, err := s.backend.GetRequirement(req.ID)
+	req.Version = version
+	originalRequirement, err := s.backendFor(r).GetRequirement(r.Context(), req.ID)
 	if errors.Is(err, backend.ErrRequirementNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
 		return
 	} else if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	err = verifyRequirementUpdate(req, originalRequirement)
 	if err != nil {
 		l.LogAttrs(r.Context(), slog.LevelWarn, "Errors when verifying requirement update", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
-	qualification, err := s.backend.UpdateRequirement(req)
+	qualification, err := s.backendFor(r).UpdateRequirement(r.Context(), req)
 	if errors.Is(err, backend.ErrRequirementNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrVersionConflict) {
+		writeError(w, http.StatusPreconditionFailed, err)
 		return
 	} else if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
+	setVersionTag(w, qualification.Version)
 	err = json.NewEncoder(w).Encode(qualification)
 	if err != nil {
 		s.logger.LogAttrs(r.Context(), slog.LevelError, "Error serializing qualification to client", slog.String("error", err.Error()))
@@ -107,19 +127,68 @@ func (s Server) updateRequirement(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s Server) deleteRequirement(w http.ResponseWriter, r *http.Request) {
-	//l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
 	id := r.PathValue("id")
-	err := s.backend.DeleteRequirement(id)
+	version, status, err := ifMatch(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Missing or invalid If-Match deleting requirement", slog.String("error", err.Error()))
+		writeError(w, status, err)
+		return
+	}
+	err = s.backendFor(r).DeleteRequirement(r.Context(), id, version)
 	if errors.Is(err, backend.ErrRequirementNotFound) {
-		w.WriteHeader(http.StatusNotFound)
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrVersionConflict) {
+		writeError(w, http.StatusPreconditionFailed, err)
 		return
 	} else if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
+// patchRequirement applies a JSON merge patch to a requirement, so null clears its notes.
+func (s Server) patchRequirement(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	version, status, err := ifMatch(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Missing or invalid If-Match patching requirement", slog.String("error", err.Error()))
+		writeError(w, status, err)
+		return
+	}
+	patch, status, err := readMergePatch(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Error reading requirement patch", slog.String("error", err.Error()))
+		writeError(w, status, err)
+		return
+	}
+	requirement, err := s.backendFor(r).PatchRequirement(r.Context(), r.PathValue("id"), version, patch)
+	if errors.Is(err, backend.ErrRequirementNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrInvalidPatch) || errors.Is(err, backend.ErrBadUpdate) || errors.Is(err, backend.ErrMissingArgs) ||
+		errors.Is(err, backend.ErrReferenceNotFound) {
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Invalid requirement patch", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, backend.ErrDuplicateRequirement) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if errors.Is(err, backend.ErrVersionConflict) {
+		writeError(w, http.StatusPreconditionFailed, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	setVersionTag(w, requirement.Version)
+	if err = json.NewEncoder(w).Encode(requirement); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing requirement to client", slog.String("error", err.Error()))
+	}
+}
+
 func verifyRequirementUpdate(new, old types.Requirement) error {
 	errs := []string{}
 	if new.ID != old.ID {
@@ -129,7 +198,7 @@ func verifyRequirementUpdate(new, old types.Requirement) error {
 		errs = append(errs, "requirement valid for days is equal to 0")
 	}
 	if len(errs) > 0 {
-		return fmt.Errorf("errors with requirement update: %s", errs)
+		return fmt.Errorf("%w: %s", backend.ErrBadUpdate, errs)
 	}
 	return nil
 }
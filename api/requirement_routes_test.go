@@ -58,6 +58,7 @@ func TestAddRequirement(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodPost, "/api/requirement", strings.NewReader(tt.body))
+			r.AddCookie(adminCookie(t))
 			s.ServeHTTP(w, r)
 			if w.Code != tt.statusCode {
 				t.Errorf("Expected response code %d, got %d", tt.statusCode, w.Code)
@@ -129,6 +130,7 @@ func TestGetRequirement(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/requirement/%s", tt.id), nil)
+			r.AddCookie(adminCookie(t))
 			s.ServeHTTP(w, r)
 
 			if w.Code != tt.statusCode {
@@ -162,17 +164,21 @@ func TestGetAllRequirements(t *testing.T) {
 	}
 	b := newMockBackend()
 	shouldSucceed := false
-	b.getAllRequirementsOverride = func() ([]types.Requirement, error) {
+	b.listRequirementsOverride = func(opts types.ListOptions) (types.Page[types.Requirement], error) {
+		if opts.Sort != "" && opts.Sort != types.SortByName {
+			return types.Page[types.Requirement]{}, backend.ErrInvalidListOptions
+		}
 		if shouldSucceed {
-			return []types.Requirement{testRequirement, testRequirement2}, nil
+			return types.Page[types.Requirement]{Items: []types.Requirement{testRequirement, testRequirement2}, Total: 2}, nil
 		} else {
-			return nil, errors.New("generic error")
+			return types.Page[types.Requirement]{}, errors.New("generic error")
 		}
 	}
 	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
 
 	tc := []struct {
 		name             string
+		query            string
 		shouldSucceed    bool
 		statusCode       int
 		expectedResponse []types.Requirement
@@ -189,20 +195,33 @@ func TestGetAllRequirements(t *testing.T) {
 			statusCode:       http.StatusInternalServerError,
 			expectedResponse: nil,
 		},
+		{
+			name:          "Unsortable key",
+			query:         "?sort=rank",
+			shouldSucceed: true,
+			statusCode:    http.StatusBadRequest,
+		},
+		{
+			name:          "Invalid limit",
+			query:         "?limit=0",
+			shouldSucceed: true,
+			statusCode:    http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tc {
 		t.Run(tt.name, func(t *testing.T) {
 			shouldSucceed = tt.shouldSucceed
 			w := httptest.NewRecorder()
-			r := httptest.NewRequest(http.MethodGet, "/api/requirements", nil)
+			r := httptest.NewRequest(http.MethodGet, "/api/requirements"+tt.query, nil)
+			r.AddCookie(adminCookie(t))
 			s.ServeHTTP(w, r)
 			if w.Code != tt.statusCode {
 				t.Errorf("Expected response code %d, got %d", tt.statusCode, w.Code)
 			}
 			if tt.statusCode == http.StatusOK {
 				b := &bytes.Buffer{}
-				json.NewEncoder(b).Encode(tt.expectedResponse)
+				json.NewEncoder(b).Encode(types.Page[types.Requirement]{Items: tt.expectedResponse, Total: len(tt.expectedResponse)})
 				if b.String() != w.Body.String() {
 					t.Errorf("Expected response: %s\nGot: %s", b.String(), w.Body.String())
 				}
@@ -280,6 +299,8 @@ func TestUpdateRequirement(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodPut, "/api/requirement/irrelevant", strings.NewReader(tt.body))
+			r.Header.Set("If-Match", "*")
+			r.AddCookie(adminCookie(t))
 			s.ServeHTTP(w, r)
 
 			if w.Code != tt.statusCode {
@@ -289,6 +310,51 @@ func TestUpdateRequirement(t *testing.T) {
 	}
 }
 
+func TestPatchRequirement(t *testing.T) {
+	b := newMockBackend()
+	b.patchRequirementOverride = func(id string, patch []byte) (types.Requirement, error) {
+		switch id {
+		case "not-found":
+			return types.Requirement{}, backend.ErrRequirementNotFound
+		case "bad-reference":
+			return types.Requirement{}, backend.ErrReferenceNotFound
+		case "invalid":
+			return types.Requirement{}, backend.ErrInvalidPatch
+		case "error":
+			return types.Requirement{}, errors.New("generic error")
+		}
+		return types.Requirement{ID: id, Name: "patched"}, nil
+	}
+
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		id         string
+		statusCode int
+	}{
+		{name: "Successful patch", id: "found", statusCode: http.StatusOK},
+		{name: "Requirement not found", id: "not-found", statusCode: http.StatusNotFound},
+		{name: "Reference not found", id: "bad-reference", statusCode: http.StatusBadRequest},
+		{name: "Invalid patch", id: "invalid", statusCode: http.StatusBadRequest},
+		{name: "Backend error", id: "error", statusCode: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/api/requirement/%s", tt.id), strings.NewReader(`{"notes":null}`))
+			r.Header.Set("If-Match", "*")
+			r.Header.Set("Content-Type", "application/merge-patch+json")
+			r.AddCookie(adminCookie(t))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected response code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
+
 func TestDeleteRequirement(t *testing.T) {
 	goodId := uuid.NewString()
 	badId := uuid.NewString()
@@ -336,6 +402,8 @@ func TestDeleteRequirement(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			r := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/requirement/%s", tt.id), nil)
+			r.Header.Set("If-Match", "*")
+			r.AddCookie(adminCookie(t))
 			s.ServeHTTP(w, r)
 
 			if w.Code != tt.statusCode {
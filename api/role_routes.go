@@ -0,0 +1,48 @@
+package api
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+func (s Server) getRoles(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	roles, err := s.backendFor(r).GetRoles(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(roles); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing roles to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) updateRolePermissions(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	var req RolePermissionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Error deserializing role permissions", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	role, err := s.backendFor(r).UpdateRolePermissions(r.Context(), types.Role(r.PathValue("role")), req.Permissions)
+	if errors.Is(err, backend.ErrInvalidRole) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrInvalidPermission) || errors.Is(err, backend.ErrBadUpdate) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(role); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing role to client", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,198 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/backend"
+	"PORTal/types"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRoutePermissions(t *testing.T) {
+	b := newMockBackend()
+	b.getMemberOverride = func(id string) (types.Member, error) {
+		return types.Member{ApiMember: types.ApiMember{ID: id, Role: types.RoleMember}}, nil
+	}
+	b.updateMemberOverride = func(m types.Member) (types.Member, error) { return m, nil }
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		cookie     *http.Cookie
+		method     string
+		path       string
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "Not logged in",
+			method:     http.MethodGet,
+			path:       "/api/members",
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "Member can read",
+			cookie:     roleCookie(t, types.RoleMember),
+			method:     http.MethodGet,
+			path:       "/api/qualifications",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Training manager can edit qualification definitions",
+			cookie:     roleCookie(t, types.RoleTrainingManager),
+			method:     http.MethodDelete,
+			path:       fmt.Sprintf("/api/qualification/%s", uuid.NewString()),
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Training manager can't archive accounts",
+			cookie:     roleCookie(t, types.RoleTrainingManager),
+			method:     http.MethodPost,
+			path:       fmt.Sprintf("/api/member/%s/archive", uuid.NewString()),
+			body:       `{"reason":"Separated"}`,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Supervisor can assign qualifications",
+			cookie:     roleCookie(t, types.RoleSupervisor),
+			method:     http.MethodPost,
+			path:       fmt.Sprintf("/api/member/%s/qualification/%s", uuid.NewString(), uuid.NewString()),
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Supervisor can't edit qualification definitions",
+			cookie:     roleCookie(t, types.RoleSupervisor),
+			method:     http.MethodPost,
+			path:       "/api/qualification",
+			body:       `{"name":"Forklift"}`,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Member can't create members",
+			cookie:     roleCookie(t, types.RoleMember),
+			method:     http.MethodPost,
+			path:       "/api/member",
+			body:       `{"first_name":"test","last_name":"member","rank":"TSgt"}`,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Training manager can't create admins",
+			cookie:     roleCookie(t, types.RoleTrainingManager),
+			method:     http.MethodPost,
+			path:       "/api/member",
+			body:       `{"first_name":"test","last_name":"member","rank":"TSgt","role":"admin"}`,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Training manager can create supervisors",
+			cookie:     roleCookie(t, types.RoleTrainingManager),
+			method:     http.MethodPost,
+			path:       "/api/member",
+			body:       `{"first_name":"test","last_name":"member","rank":"TSgt","role":"supervisor"}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "Member can't change role",
+			cookie:     roleCookie(t, types.RoleMember),
+			method:     http.MethodPut,
+			path:       fmt.Sprintf("/api/member/%s", uuid.NewString()),
+			body:       `{"role":"admin"}`,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Training manager can't update roles",
+			cookie:     roleCookie(t, types.RoleTrainingManager),
+			method:     http.MethodPut,
+			path:       "/api/role/trainer",
+			body:       `{"permissions":["completions:sign_off"]}`,
+			statusCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			r.Header.Set("If-Match", "*")
+			if tt.cookie != nil {
+				r.AddCookie(tt.cookie)
+			}
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestUpdateRolePermissions(t *testing.T) {
+	b := newMockBackend()
+	b.updateRolePermissionsOverride = func(role types.Role, permissions []types.Permission) (types.RoleDefinition, error) {
+		if !role.Valid() {
+			return types.RoleDefinition{}, backend.ErrInvalidRole
+		}
+		if role == types.RoleAdmin {
+			return types.RoleDefinition{}, backend.ErrBadUpdate
+		}
+		for _, p := range permissions {
+			if !p.Valid() {
+				return types.RoleDefinition{}, backend.ErrInvalidPermission
+			}
+		}
+		return types.RoleDefinition{Role: role, Permissions: permissions}, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		role       string
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "Successful update",
+			role:       "trainer",
+			body:       `{"permissions":["completions:sign_off","reports:view"]}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Role not found",
+			role:       "wizard",
+			body:       `{"permissions":[]}`,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Invalid permission",
+			role:       "trainer",
+			body:       `{"permissions":["everything"]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Admin role",
+			role:       "admin",
+			body:       `{"permissions":[]}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Malformed request",
+			role:       "trainer",
+			body:       `{"permissions":`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/api/role/%s", tt.role), strings.NewReader(tt.body))
+			r.AddCookie(adminCookie(t))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Tenants is the directory of tenants sharing a multi-tenant deployment, see NewMultiTenant.
+type Tenants interface {
+	AddTenant(ctx context.Context, t types.Tenant, admin types.Member) (types.Tenant, types.Member, error)
+	GetTenant(ctx context.Context, id string) (types.Tenant, error)
+	GetTenantBySubdomain(ctx context.Context, subdomain string) (types.Tenant, error)
+	GetTenants(ctx context.Context) ([]types.Tenant, error)
+	// ForTenant returns the backend scoped to the tenant's data.
+	ForTenant(tenantID string) Backend
+}
+
+type tenantKey struct{}
+
+// requestTenant is the tenant a request was resolved to along with the backend scoped to it.
+type requestTenant struct {
+	ID      string
+	backend Backend
+}
+
+// resolveTenant works out which tenant a request is for before anything else looks at it. Requests to a subdomain of
+// the configured domain are for the tenant with that subdomain. Anything else is for the tenant of the identity cookie,
+// or the default tenant when there isn't one.
+func (s Server) resolveTenant(r *http.Request) (*http.Request, int) {
+	if s.tenants == nil {
+		return r, http.StatusOK
+	}
+	tenantID := types.DefaultTenantID
+	if subdomain, ok := s.subdomain(r); ok {
+		t, err := s.tenants.GetTenantBySubdomain(r.Context(), subdomain)
+		if errors.Is(err, backend.ErrTenantNotFound) {
+			s.logger.LogAttrs(r.Context(), slog.LevelInfo, "Request for unknown tenant", slog.String("subdomain", subdomain))
+			return r, http.StatusNotFound
+		} else if err != nil {
+			return r, http.StatusInternalServerError
+		}
+		tenantID = t.ID
+	} else if claims, ok := s.cookieClaims(r); ok && claims.Tenant != "" {
+		tenantID = claims.Tenant
+	}
+	return s.withTenant(r, tenantID), http.StatusOK
+}
+
+func (s Server) withTenant(r *http.Request, tenantID string) *http.Request {
+	t := requestTenant{ID: tenantID, backend: s.tenants.ForTenant(tenantID)}
+	return r.WithContext(context.WithValue(r.Context(), tenantKey{}, t))
+}
+
+// subdomain returns the single label in front of the configured domain in the request's host, if there is one.
+func (s Server) subdomain(r *http.Request) (string, bool) {
+	if s.config.Domain == "" {
+		return "", false
+	}
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	label, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(s.config.Domain))
+	if !ok || label == "" || strings.Contains(label, ".") {
+		return "", false
+	}
+	return label, true
+}
+
+// cookieClaims returns the claims of a valid identity cookie.
+func (s Server) cookieClaims(r *http.Request) (*CustomClaims, bool) {
+	cookie, err := r.Cookie(JWTCookieName)
+	if err != nil {
+		return nil, false
+	}
+	token, err := validateToken(r.Context(), cookie.Value, s.jwtKeyFunc, s.logger)
+	if err != nil {
+		return nil, false
+	}
+	claims, ok := token.Claims.(*CustomClaims)
+	return claims, ok
+}
+
+// backendFor returns the backend scoped to the request's tenant.
+func (s Server) backendFor(r *http.Request) Backend {
+	if t, ok := r.Context().Value(tenantKey{}).(requestTenant); ok {
+		return t.backend
+	}
+	return s.backend
+}
+
+// tenantOf returns the ID of the request's tenant.
+func tenantOf(r *http.Request) string {
+	if t, ok := r.Context().Value(tenantKey{}).(requestTenant); ok {
+		return t.ID
+	}
+	return types.DefaultTenantID
+}
+
+// requireDeploymentAdmin only lets admins of the default tenant through, they're the ones running the deployment rather
+// than one of the organizations on it.
+func (s Server) requireDeploymentAdmin(h http.HandlerFunc) http.Handler {
+	return s.authorized(func(id identity, r *http.Request) bool {
+		return id.Admin && tenantOf(r) == types.DefaultTenantID
+	}, h)
+}
+
+func (s Server) addTenant(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	var req AddTenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid tenant JSON sent from client", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	tenant, admin, err := s.tenants.AddTenant(r.Context(), req.Tenant, req.Admin)
+	if errors.Is(err, backend.ErrMissingArgs) || errors.Is(err, backend.ErrInvalidTenant) || errors.Is(err, backend.ErrWeakPassword) ||
+		errors.Is(err, backend.ErrPasswordTooLong) || errors.Is(err, backend.ErrInvalidRole) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, backend.ErrDuplicateTenant) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(AddTenantResponse{Tenant: tenant, Admin: admin.ToApiMember()}); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing tenant to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getTenants(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	tenants, err := s.tenants.GetTenants(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(tenants); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing tenants to client", slog.String("error", err.Error()))
+	}
+}
+
+// getTenant returns the tenant the request resolved to so clients on the bare domain know where they've logged in.
+func (s Server) getTenant(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	tenant, err := s.tenants.GetTenant(r.Context(), tenantOf(r))
+	if errors.Is(err, backend.ErrTenantNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(tenant); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing tenant to client", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,225 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/backend"
+	"PORTal/testutils"
+	"PORTal/types"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var _ api.Tenants = mockTenants{}
+
+// mockTenants serves a separate mockBackend for each tenant.
+type mockTenants struct {
+	tenants  []types.Tenant
+	backends map[string]*mockBackend
+}
+
+func (m mockTenants) AddTenant(ctx context.Context, t types.Tenant, admin types.Member) (types.Tenant, types.Member, error) {
+	if t.Subdomain == "taken" {
+		return types.Tenant{}, types.Member{}, backend.ErrDuplicateTenant
+	}
+	if !t.ValidSubdomain() {
+		return types.Tenant{}, types.Member{}, backend.ErrInvalidTenant
+	}
+	t.ID = uuid.NewString()
+	return t, admin, nil
+}
+
+func (m mockTenants) GetTenant(ctx context.Context, id string) (types.Tenant, error) {
+	for _, t := range m.tenants {
+		if t.ID == id {
+			return t, nil
+		}
+	}
+	return types.Tenant{}, backend.ErrTenantNotFound
+}
+
+func (m mockTenants) GetTenantBySubdomain(ctx context.Context, subdomain string) (types.Tenant, error) {
+	for _, t := range m.tenants {
+		if t.Subdomain == subdomain {
+			return t, nil
+		}
+	}
+	return types.Tenant{}, backend.ErrTenantNotFound
+}
+
+func (m mockTenants) GetTenants(ctx context.Context) ([]types.Tenant, error) {
+	return m.tenants, nil
+}
+
+func (m mockTenants) ForTenant(tenantID string) api.Backend {
+	return m.backends[tenantID]
+}
+
+// newMockTenants has the default tenant and "62aps", whose backends each return a single member named after the tenant.
+func newMockTenants() mockTenants {
+	m := mockTenants{
+		tenants:  []types.Tenant{{ID: types.DefaultTenantID, Name: "Default"}, {ID: "t62", Name: "62nd APS", Subdomain: "62aps"}},
+		backends: map[string]*mockBackend{},
+	}
+	for _, t := range m.tenants {
+		b := newMockBackend()
+		tenant := t
+		b.listMembersOverride = func(opts types.MemberListOptions) (types.Page[types.Member], error) {
+			return types.Page[types.Member]{Items: []types.Member{{ApiMember: types.ApiMember{ID: tenant.ID}}}, Total: 1}, nil
+		}
+		b.loginOverride = func(username, password string) (types.Member, error) {
+			return types.Member{ApiMember: types.ApiMember{ID: tenant.ID, Username: username, Role: types.RoleMember}}, nil
+		}
+		m.backends[t.ID] = b
+	}
+	return m
+}
+
+// tenantCookie returns an identity cookie for a member of the tenant holding the given role.
+func tenantCookie(t *testing.T, tenantID string, role types.Role) *http.Cookie {
+	t.Helper()
+	m := testutils.RandomMember(role == types.RoleAdmin)
+	m.ID = uuid.NewString()
+	m.Role = role
+	token, err := api.CreateToken(m, tenantID, types.DefaultRolePermissions[role], time.Hour, []byte("test"))
+	if err != nil {
+		t.Fatalf("Error creating token for %s: %s", tenantID, err.Error())
+	}
+	return &http.Cookie{Name: api.JWTCookieName, Value: token}
+}
+
+func TestTenantResolution(t *testing.T) {
+	s := api.NewMultiTenant(slog.Default(), newMockTenants(), false, api.Config{Domain: "portal.com", JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		host       string
+		cookie     *http.Cookie
+		statusCode int
+		tenantID   string
+	}{
+		{name: "Tenant subdomain", host: "62aps.portal.com", cookie: tenantCookie(t, "t62", types.RoleMember), statusCode: http.StatusOK, tenantID: "t62"},
+		{name: "Tenant subdomain with port", host: "62aps.portal.com:8080", cookie: tenantCookie(t, "t62", types.RoleMember), statusCode: http.StatusOK, tenantID: "t62"},
+		{name: "Bare domain uses the cookie's tenant", host: "portal.com", cookie: tenantCookie(t, "t62", types.RoleMember), statusCode: http.StatusOK, tenantID: "t62"},
+		{name: "Bare domain defaults", host: "portal.com", cookie: tenantCookie(t, types.DefaultTenantID, types.RoleMember), statusCode: http.StatusOK, tenantID: types.DefaultTenantID},
+		{name: "Cookie from another tenant", host: "62aps.portal.com", cookie: tenantCookie(t, types.DefaultTenantID, types.RoleAdmin), statusCode: http.StatusUnauthorized},
+		{name: "Unknown subdomain", host: "unknown.portal.com", cookie: tenantCookie(t, "t62", types.RoleMember), statusCode: http.StatusNotFound},
+		{name: "Nested subdomain isn't a tenant", host: "a.62aps.portal.com", cookie: tenantCookie(t, "t62", types.RoleMember), statusCode: http.StatusOK, tenantID: "t62"},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/api/members", nil)
+			r.Host = tt.host
+			r.AddCookie(tt.cookie)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+			var members types.Page[types.ApiMember]
+			if err := json.NewDecoder(w.Body).Decode(&members); err != nil {
+				t.Fatalf("Error decoding response: %s", err.Error())
+			}
+			if len(members.Items) != 1 || members.Items[0].ID != tt.tenantID {
+				t.Errorf("Expected members of tenant %s, got: %+v", tt.tenantID, members.Items)
+			}
+		})
+	}
+}
+
+func TestTenantLogin(t *testing.T) {
+	s := api.NewMultiTenant(slog.Default(), newMockTenants(), false, api.Config{Domain: "portal.com", JWTSecret: "test", JWTExpiration: 1})
+
+	tc := []struct {
+		name       string
+		host       string
+		tenant     string
+		statusCode int
+		tenantID   string
+	}{
+		{name: "Subdomain", host: "62aps.portal.com", statusCode: http.StatusOK, tenantID: "t62"},
+		{name: "Tenant in body on bare domain", host: "portal.com", tenant: "62aps", statusCode: http.StatusOK, tenantID: "t62"},
+		{name: "Bare domain defaults", host: "portal.com", statusCode: http.StatusOK, tenantID: types.DefaultTenantID},
+		{name: "Body names another tenant", host: "62aps.portal.com", tenant: "other", statusCode: http.StatusBadRequest},
+		{name: "Unknown tenant in body", host: "portal.com", tenant: "other", statusCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			body := fmt.Sprintf(`{"username":"user","password":"password","tenant":"%s"}`, tt.tenant)
+			r := httptest.NewRequest(http.MethodPost, "/api/login", strings.NewReader(body))
+			r.Host = tt.host
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+			var res api.LoginResponse
+			if err := json.NewDecoder(w.Body).Decode(&res); err != nil || res.Member.ID != tt.tenantID {
+				t.Fatalf("Expected login to tenant %s, got: %+v, %v", tt.tenantID, res, err)
+			}
+			// The issued cookie keeps later requests on the bare domain in the same tenant
+			w2 := httptest.NewRecorder()
+			r2 := httptest.NewRequest(http.MethodGet, "/api/members", nil)
+			r2.Host = "portal.com"
+			r2.AddCookie(w.Result().Cookies()[0])
+			s.ServeHTTP(w2, r2)
+			var members types.Page[types.ApiMember]
+			if err := json.NewDecoder(w2.Body).Decode(&members); err != nil || len(members.Items) != 1 || members.Items[0].ID != tt.tenantID {
+				t.Errorf("Expected members of tenant %s, got: %+v, %v", tt.tenantID, members.Items, err)
+			}
+		})
+	}
+}
+
+func TestTenantRoutes(t *testing.T) {
+	s := api.NewMultiTenant(slog.Default(), newMockTenants(), false, api.Config{Domain: "portal.com", JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		host       string
+		cookie     *http.Cookie
+		body       string
+		statusCode int
+	}{
+		{name: "Deployment admin", host: "portal.com", cookie: tenantCookie(t, types.DefaultTenantID, types.RoleAdmin), body: `{"tenant":{"name":"1st APS","subdomain":"1aps"}}`, statusCode: http.StatusCreated},
+		{name: "Tenant admin", host: "62aps.portal.com", cookie: tenantCookie(t, "t62", types.RoleAdmin), body: `{"tenant":{"name":"1st APS","subdomain":"1aps"}}`, statusCode: http.StatusForbidden},
+		{name: "Default tenant member", host: "portal.com", cookie: tenantCookie(t, types.DefaultTenantID, types.RoleMember), body: `{"tenant":{"name":"1st APS","subdomain":"1aps"}}`, statusCode: http.StatusForbidden},
+		{name: "Invalid subdomain", host: "portal.com", cookie: tenantCookie(t, types.DefaultTenantID, types.RoleAdmin), body: `{"tenant":{"name":"1st APS","subdomain":"1.aps"}}`, statusCode: http.StatusBadRequest},
+		{name: "Duplicate subdomain", host: "portal.com", cookie: tenantCookie(t, types.DefaultTenantID, types.RoleAdmin), body: `{"tenant":{"name":"1st APS","subdomain":"taken"}}`, statusCode: http.StatusConflict},
+		{name: "Invalid JSON", host: "portal.com", cookie: tenantCookie(t, types.DefaultTenantID, types.RoleAdmin), body: `{"tenant":`, statusCode: http.StatusBadRequest},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/admin/tenant", strings.NewReader(tt.body))
+			r.Host = tt.host
+			r.AddCookie(tt.cookie)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/tenant", nil)
+	r.Host = "62aps.portal.com"
+	s.ServeHTTP(w, r)
+	var tenant types.Tenant
+	if err := json.NewDecoder(w.Body).Decode(&tenant); err != nil || tenant.ID != "t62" {
+		t.Errorf("Expected tenant t62, got: %+v, %v", tenant, err)
+	}
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"PORTal/backend"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+func (s Server) createAPIToken(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	if caller.Scope != "" {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Attempted to create api token using an api token", slog.String("member_id", caller.MemberID))
+		writeError(w, http.StatusForbidden, backend.ErrInsufficientPermissions)
+		return
+	}
+	var req CreateTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Error deserializing token request", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	token, err := s.backendFor(r).CreateAPIToken(r.Context(), caller.MemberID, req.Name, req.Scope)
+	if errors.Is(err, backend.ErrMissingArgs) || errors.Is(err, backend.ErrInvalidTokenScope) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, backend.ErrInsufficientPermissions) {
+		writeError(w, http.StatusForbidden, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(token); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing api token to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getAPITokens(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	tokens, err := s.backendFor(r).GetAPITokens(r.Context(), caller.MemberID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(tokens); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing api tokens to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) revokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	err := s.backendFor(r).RevokeAPIToken(r.Context(), caller.MemberID, r.PathValue("id"))
+	if errors.Is(err, backend.ErrAPITokenNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
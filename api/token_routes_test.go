@@ -0,0 +1,282 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/backend"
+	"PORTal/testutils"
+	"PORTal/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateAPIToken(t *testing.T) {
+	member := testutils.RandomMember(false)
+	member.ID = uuid.NewString()
+	b := newMockBackend()
+	b.createAPITokenOverride = func(memberID, name string, scope types.TokenScope) (types.APIToken, error) {
+		if memberID != member.ID {
+			return types.APIToken{}, errors.New("wrong member")
+		}
+		switch {
+		case name == "":
+			return types.APIToken{}, backend.ErrMissingArgs
+		case scope == types.ScopeAdmin:
+			return types.APIToken{}, backend.ErrInsufficientPermissions
+		case !scope.Valid():
+			return types.APIToken{}, backend.ErrInvalidTokenScope
+		}
+		return types.APIToken{ID: uuid.NewString(), MemberID: memberID, Name: name, Scope: scope, Token: "portal_secret"}, nil
+	}
+	b.authenticateAPITokenOverride = func(token string) (types.APIToken, types.Member, error) {
+		return types.APIToken{Scope: types.ScopeReadWrite}, member, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+	token, err := api.CreateToken(member, types.DefaultTenantID, types.DefaultRolePermissions[member.Role], time.Hour, []byte("test"))
+	if err != nil {
+		t.Fatalf("Error creating token for TestCreateAPIToken: %s", err.Error())
+	}
+
+	tc := []struct {
+		name       string
+		body       string
+		cookie     string
+		bearer     string
+		statusCode int
+	}{
+		{
+			name:       "Successful create",
+			body:       `{"name":"spreadsheet","scope":"read-only"}`,
+			cookie:     token,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "Missing name",
+			body:       `{"scope":"read-only"}`,
+			cookie:     token,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid scope",
+			body:       `{"name":"spreadsheet","scope":"root"}`,
+			cookie:     token,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Insufficient permissions",
+			body:       `{"name":"spreadsheet","scope":"admin"}`,
+			cookie:     token,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Created with api token",
+			body:       `{"name":"spreadsheet","scope":"read-only"}`,
+			bearer:     "portal_secret",
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Not logged in",
+			body:       `{"name":"spreadsheet","scope":"read-only"}`,
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "Malformed request",
+			body:       `{"name":"spreadsheet"`,
+			cookie:     token,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/tokens", strings.NewReader(tt.body))
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: api.JWTCookieName, Value: tt.cookie})
+			}
+			if tt.bearer != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if w.Code == http.StatusCreated {
+				var res types.APIToken
+				if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+					t.Fatalf("Error decoding api token: %s", err.Error())
+				}
+				if res.Token == "" {
+					t.Errorf("Expected plaintext token in response")
+				}
+			}
+		})
+	}
+}
+
+func TestRevokeAPIToken(t *testing.T) {
+	member := testutils.RandomMember(false)
+	member.ID = uuid.NewString()
+	tokenID := uuid.NewString()
+	b := newMockBackend()
+	b.revokeAPITokenOverride = func(memberID, id string) error {
+		if memberID == member.ID && id == tokenID {
+			return nil
+		}
+		return backend.ErrAPITokenNotFound
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+	token, err := api.CreateToken(member, types.DefaultTenantID, types.DefaultRolePermissions[member.Role], time.Hour, []byte("test"))
+	if err != nil {
+		t.Fatalf("Error creating token for TestRevokeAPIToken: %s", err.Error())
+	}
+
+	tc := []struct {
+		name       string
+		id         string
+		statusCode int
+	}{
+		{
+			name:       "Successful revoke",
+			id:         tokenID,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Token not found",
+			id:         uuid.NewString(),
+			statusCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/tokens/%s", tt.id), nil)
+			r.AddCookie(&http.Cookie{Name: api.JWTCookieName, Value: token})
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestBearerTokenAuthentication(t *testing.T) {
+	admin := testutils.RandomMember(true)
+	admin.ID = uuid.NewString()
+	b := newMockBackend()
+	b.authenticateAPITokenOverride = func(token string) (types.APIToken, types.Member, error) {
+		switch token {
+		case "portal_readonly":
+			return types.APIToken{ID: uuid.NewString(), Scope: types.ScopeReadOnly}, admin, nil
+		case "portal_readwrite":
+			return types.APIToken{ID: uuid.NewString(), Scope: types.ScopeReadWrite}, admin, nil
+		case "portal_admin":
+			return types.APIToken{ID: uuid.NewString(), Scope: types.ScopeAdmin}, admin, nil
+		}
+		return types.APIToken{}, types.Member{}, backend.ErrAuthenticationFailed
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		statusCode    int
+	}{
+		{
+			name:          "Read only token can read",
+			method:        http.MethodGet,
+			path:          "/api/members",
+			authorization: "Bearer portal_readonly",
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "Read only token can't write",
+			method:        http.MethodPost,
+			path:          fmt.Sprintf("/api/member/%s/restore", uuid.NewString()),
+			authorization: "Bearer portal_readonly",
+			statusCode:    http.StatusForbidden,
+		},
+		{
+			name:          "Read write token can write training data",
+			method:        http.MethodPost,
+			path:          fmt.Sprintf("/api/member/%s/qualification/%s", uuid.NewString(), uuid.NewString()),
+			authorization: "Bearer portal_readwrite",
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "Read write token can't archive members",
+			method:        http.MethodPost,
+			path:          fmt.Sprintf("/api/member/%s/restore", uuid.NewString()),
+			authorization: "Bearer portal_readwrite",
+			statusCode:    http.StatusForbidden,
+		},
+		{
+			name:          "Read write token can't administer members",
+			method:        http.MethodPost,
+			path:          "/api/member",
+			authorization: "Bearer portal_readwrite",
+			statusCode:    http.StatusForbidden,
+		},
+		{
+			name:          "Read write token can't change its own member",
+			method:        http.MethodPatch,
+			path:          fmt.Sprintf("/api/member/%s", admin.ID),
+			authorization: "Bearer portal_readwrite",
+			statusCode:    http.StatusForbidden,
+		},
+		{
+			name:          "Admin token can archive members",
+			method:        http.MethodPost,
+			path:          fmt.Sprintf("/api/member/%s/restore", uuid.NewString()),
+			authorization: "Bearer portal_admin",
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "Read write token isn't admin",
+			method:        http.MethodGet,
+			path:          "/api/checkAdmin",
+			authorization: "Bearer portal_readwrite",
+			statusCode:    http.StatusUnauthorized,
+		},
+		{
+			name:          "Admin token is admin",
+			method:        http.MethodGet,
+			path:          "/api/checkAdmin",
+			authorization: "Bearer portal_admin",
+			statusCode:    http.StatusOK,
+		},
+		{
+			name:          "Invalid token",
+			method:        http.MethodGet,
+			path:          "/api/members",
+			authorization: "Bearer portal_invalid",
+			statusCode:    http.StatusUnauthorized,
+		},
+		{
+			name:          "Malformed header",
+			method:        http.MethodGet,
+			path:          "/api/members",
+			authorization: "Basic dXNlcjpwYXNz",
+			statusCode:    http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			r.Header.Set("Authorization", tt.authorization)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
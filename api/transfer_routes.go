@@ -0,0 +1,75 @@
+package api
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+func (s Server) exportMemberTransfer(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	memberID := r.PathValue("id")
+	signed, err := s.backendFor(r).ExportMemberTransfer(r.Context(), caller.MemberID, memberID)
+	if errors.Is(err, backend.ErrMemberNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrTransferKeyNotConfigured) {
+		writeError(w, http.StatusNotImplemented, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"transfer-%s.json\"", memberID))
+	if err = json.NewEncoder(w).Encode(signed); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing transfer package to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) importMemberTransfer(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	body, _, err := uploadedFile(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Missing file in transfer import form", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer body.Close()
+	var signed types.SignedTransferPackage
+	if err = json.NewDecoder(body).Decode(&signed); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid transfer package sent from client", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	report, err := s.backendFor(r).ImportMemberTransfer(r.Context(), caller.MemberID, signed)
+	if errors.Is(err, backend.ErrInvalidTransfer) || errors.Is(err, backend.ErrInvalidTransferSignature) || errors.Is(err, backend.ErrTransferKeyNotConfigured) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, backend.ErrTransferAlreadyImported) || errors.Is(err, backend.ErrMemberNotArchived) || errors.Is(err, backend.ErrDuplicateUsername) ||
+		errors.Is(err, backend.ErrDuplicateCertificate) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(report); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing transfer report to client", slog.String("error", err.Error()))
+	}
+}
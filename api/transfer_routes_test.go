@@ -0,0 +1,129 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/backend"
+	"PORTal/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExportMemberTransfer(t *testing.T) {
+	goodID := uuid.NewString()
+	signed := types.SignedTransferPackage{Package: json.RawMessage(`{"version":1}`), Signature: "abcd"}
+	b := newMockBackend()
+	b.exportMemberTransferOverride = func(actorID, memberID string) (types.SignedTransferPackage, error) {
+		switch memberID {
+		case goodID:
+			return signed, nil
+		case "notfound":
+			return types.SignedTransferPackage{}, backend.ErrMemberNotFound
+		case "nokey":
+			return types.SignedTransferPackage{}, backend.ErrTransferKeyNotConfigured
+		default:
+			return types.SignedTransferPackage{}, errors.New("unexpected case")
+		}
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		memberID   string
+		statusCode int
+	}{
+		{name: "Successful export", memberID: goodID, statusCode: http.StatusOK},
+		{name: "Member not found", memberID: "notfound", statusCode: http.StatusNotFound},
+		{name: "No transfer key", memberID: "nokey", statusCode: http.StatusNotImplemented},
+		{name: "Backend error", memberID: "bad", statusCode: http.StatusInternalServerError},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/member/%s/transfer", tt.memberID), nil)
+			r.AddCookie(adminCookie(t))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+			if !strings.HasPrefix(w.Header().Get("Content-Disposition"), "attachment") {
+				t.Errorf("Expected the package to download as an attachment, got %q", w.Header().Get("Content-Disposition"))
+			}
+			var res types.SignedTransferPackage
+			if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+				t.Fatalf("Error decoding transfer package: %s", err.Error())
+			}
+			if res.Signature != signed.Signature || string(res.Package) != string(signed.Package) {
+				t.Errorf("Expected %+v, got %+v", signed, res)
+			}
+		})
+	}
+}
+
+func TestImportMemberTransfer(t *testing.T) {
+	b := newMockBackend()
+	b.importMemberTransferOverride = func(actorID string, signed types.SignedTransferPackage) (types.TransferImportReport, error) {
+		switch signed.Signature {
+		case "good":
+			return types.TransferImportReport{Issuer: "losing", ImportedCompletions: 3}, nil
+		case "tampered":
+			return types.TransferImportReport{}, backend.ErrInvalidTransferSignature
+		case "nokey":
+			return types.TransferImportReport{}, backend.ErrTransferKeyNotConfigured
+		case "active":
+			return types.TransferImportReport{}, backend.ErrMemberNotArchived
+		case "duplicate":
+			return types.TransferImportReport{}, backend.ErrDuplicateUsername
+		case "replayed":
+			return types.TransferImportReport{}, backend.ErrTransferAlreadyImported
+		default:
+			return types.TransferImportReport{}, errors.New("unexpected case")
+		}
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		body       string
+		statusCode int
+	}{
+		{name: "Successful import", body: `{"package":{},"signature":"good"}`, statusCode: http.StatusCreated},
+		{name: "Invalid JSON", body: `{"package":`, statusCode: http.StatusBadRequest},
+		{name: "Bad signature", body: `{"package":{},"signature":"tampered"}`, statusCode: http.StatusBadRequest},
+		{name: "Unknown issuer", body: `{"package":{},"signature":"nokey"}`, statusCode: http.StatusBadRequest},
+		{name: "Member still active", body: `{"package":{},"signature":"active"}`, statusCode: http.StatusConflict},
+		{name: "Username taken", body: `{"package":{},"signature":"duplicate"}`, statusCode: http.StatusConflict},
+		{name: "Already imported", body: `{"package":{},"signature":"replayed"}`, statusCode: http.StatusConflict},
+		{name: "Backend error", body: `{"package":{},"signature":"bad"}`, statusCode: http.StatusInternalServerError},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/admin/import/transfer", strings.NewReader(tt.body))
+			r.AddCookie(adminCookie(t))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if tt.statusCode != http.StatusCreated {
+				return
+			}
+			var report types.TransferImportReport
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatalf("Error decoding transfer report: %s", err.Error())
+			}
+			if report.Issuer != "losing" || report.ImportedCompletions != 3 {
+				t.Errorf("Unexpected report %+v", report)
+			}
+		})
+	}
+}
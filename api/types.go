@@ -1,10 +1,24 @@
 package api
 
-import "PORTal/types"
+import (
+	"PORTal/types"
+	"time"
+)
 
 type Credentials struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// Tenant is the subdomain of the tenant to log in to when logging in on the bare domain.
+	Tenant string `json:"tenant,omitempty"`
+}
+
+type CertificateBinding struct {
+	CertificateID string `json:"certificate_id"`
+}
+
+type CreateTokenRequest struct {
+	Name  string           `json:"name"`
+	Scope types.TokenScope `json:"scope"`
 }
 
 type ValidateLocalDataRequest struct {
@@ -15,4 +29,49 @@ type LoginResponse struct {
 	Member         types.ApiMember       `json:"member"`
 	Qualifications []types.Qualification `json:"qualifications"`
 	Subordinates   []types.ApiMember     `json:"subordinates"`
+	Permissions    []types.Permission    `json:"permissions"`
+}
+
+type RolePermissionsRequest struct {
+	Permissions []types.Permission `json:"permissions"`
+}
+
+type CompletionRequest struct {
+	TrainerID     string    `json:"trainer_id"`
+	CompletedDate time.Time `json:"completed_date"`
+	Comments      string    `json:"comments"`
+}
+
+type ReviewRequest struct {
+	Comments string `json:"comments"`
+}
+
+type RevokeWaiverRequest struct {
+	Reason string `json:"reason"`
+}
+
+type AssignDutyPositionResponse struct {
+	AssignedQualifications []string `json:"assigned_qualifications"`
+}
+
+type ArchiveMemberRequest struct {
+	Reason string `json:"reason"`
+}
+
+type PurgeMembersResponse struct {
+	Purged []string `json:"purged"`
+}
+
+type UnitQualificationsRequest struct {
+	Qualifications []string `json:"qualifications"`
+}
+
+type AddTenantRequest struct {
+	Tenant types.Tenant `json:"tenant"`
+	Admin  types.Member `json:"admin"`
+}
+
+type AddTenantResponse struct {
+	Tenant types.Tenant    `json:"tenant"`
+	Admin  types.ApiMember `json:"admin"`
 }
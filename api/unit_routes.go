@@ -0,0 +1,249 @@
+package api
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+func (s Server) addUnit(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	var unit types.Unit
+	if err := json.NewDecoder(r.Body).Decode(&unit); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid unit JSON sent from client", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	unit, err := s.backendFor(r).AddUnit(r.Context(), unit)
+	if errors.Is(err, backend.ErrMissingArgs) || errors.Is(err, backend.ErrInvalidUnit) || errors.Is(err, backend.ErrQualificationNotFound) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(unit); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing unit to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getUnit(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	unit, err := s.backendFor(r).GetUnit(r.Context(), r.PathValue("id"))
+	if errors.Is(err, backend.ErrUnitNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	setVersionTag(w, unit.Version)
+	if err = json.NewEncoder(w).Encode(unit); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing unit to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getUnits(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	units, err := s.backendFor(r).GetUnits(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = writeTagged(w, r, units); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing units to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) updateUnit(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	version, status, err := ifMatch(r)
+	if err != nil {
+		l.LogAttrs(r.Context(), slog.LevelInfo, "Missing or invalid If-Match updating unit", slog.String("error", err.Error()))
+		writeError(w, status, err)
+		return
+	}
+	var unit types.Unit
+	if err := json.NewDecoder(r.Body).Decode(&unit); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid unit JSON sent from client", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	unit.ID = r.PathValue("id")
+	unit.Version = version
+	s.writeUpdatedUnit(w, r, unit)
+}
+
+// setUnitQualifications replaces the unit's mandatory qualifications, which unit admins may do for their own units.
+func (s Server) setUnitQualifications(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	var req UnitQualificationsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid unit qualifications JSON sent from client", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	if req.Qualifications == nil {
+		req.Qualifications = []string{}
+	}
+	s.writeUpdatedUnit(w, r, types.Unit{ID: r.PathValue("id"), MandatoryQualifications: req.Qualifications})
+}
+
+func (s Server) writeUpdatedUnit(w http.ResponseWriter, r *http.Request, unit types.Unit) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	unit, err := s.backendFor(r).UpdateUnit(r.Context(), unit)
+	if errors.Is(err, backend.ErrUnitNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrInvalidUnit) || errors.Is(err, backend.ErrBadUpdate) ||
+		errors.Is(err, backend.ErrQualificationNotFound) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, backend.ErrVersionConflict) {
+		writeError(w, http.StatusPreconditionFailed, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	setVersionTag(w, unit.Version)
+	if err = json.NewEncoder(w).Encode(unit); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing unit to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) deleteUnit(w http.ResponseWriter, r *http.Request) {
+	version, status, err := ifMatch(r)
+	if err != nil {
+		writeError(w, status, err)
+		return
+	}
+	err = s.backendFor(r).DeleteUnit(r.Context(), r.PathValue("id"), version)
+	if errors.Is(err, backend.ErrUnitNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrVersionConflict) {
+		writeError(w, http.StatusPreconditionFailed, err)
+		return
+	} else if errors.Is(err, backend.ErrUnitHasSubunits) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s Server) getUnitMembers(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	members, err := s.backendFor(r).GetUnitMembers(r.Context(), r.PathValue("id"))
+	if errors.Is(err, backend.ErrUnitNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	apiMembers := []types.ApiMember{}
+	for _, m := range members {
+		apiMembers = append(apiMembers, m.ToApiMember())
+	}
+	if err = json.NewEncoder(w).Encode(apiMembers); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing unit members to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getUnitReport(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	report, err := s.backendFor(r).GetUnitReport(r.Context(), r.PathValue("id"))
+	if errors.Is(err, backend.ErrUnitNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(report); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing unit report to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getUnitAdmins(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	admins, err := s.backendFor(r).GetUnitAdmins(r.Context(), r.PathValue("id"))
+	if errors.Is(err, backend.ErrUnitNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	apiMembers := []types.ApiMember{}
+	for _, m := range admins {
+		apiMembers = append(apiMembers, m.ToApiMember())
+	}
+	if err = json.NewEncoder(w).Encode(apiMembers); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing unit admins to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) addUnitAdmin(w http.ResponseWriter, r *http.Request) {
+	err := s.backendFor(r).AddUnitAdmin(r.Context(), r.PathValue("id"), r.PathValue("memberID"))
+	if errors.Is(err, backend.ErrUnitNotFound) || errors.Is(err, backend.ErrMemberNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrUnitAdminAlreadyDesignated) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s Server) removeUnitAdmin(w http.ResponseWriter, r *http.Request) {
+	err := s.backendFor(r).RemoveUnitAdmin(r.Context(), r.PathValue("id"), r.PathValue("memberID"))
+	if errors.Is(err, backend.ErrUnitAdminNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// setMemberUnit moves the member into {unitID}, or out of their unit when there isn't one in the path.
+func (s Server) setMemberUnit(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	m, err := s.backendFor(r).SetMemberUnit(r.Context(), r.PathValue("id"), r.PathValue("unitID"))
+	if errors.Is(err, backend.ErrMemberNotFound) || errors.Is(err, backend.ErrUnitNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(m.ToApiMember()); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing member to client", slog.String("error", err.Error()))
+	}
+}
+
+// memberUnit is the unit the {id} member currently belongs to, so their unit admins can take them out of it.
+func (s Server) memberUnit(r *http.Request) string {
+	m, err := s.backendFor(r).GetMember(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return ""
+	}
+	return m.UnitID
+}
@@ -0,0 +1,189 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/backend"
+	"PORTal/testutils"
+	"PORTal/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAddUnit(t *testing.T) {
+	b := newMockBackend()
+	b.addUnitOverride = func(u types.Unit) (types.Unit, error) {
+		switch u.Name {
+		case "":
+			return types.Unit{}, backend.ErrMissingArgs
+		case "orphan":
+			return types.Unit{}, backend.ErrInvalidUnit
+		case "bad":
+			return types.Unit{}, errors.New("generic error")
+		}
+		u.ID = uuid.NewString()
+		return u, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		body       string
+		statusCode int
+	}{
+		{name: "Successful add", body: `{"name":"1st APS","kind":"squadron"}`, statusCode: http.StatusCreated},
+		{name: "Invalid JSON", body: `{"name":`, statusCode: http.StatusBadRequest},
+		{name: "Missing name", body: `{"kind":"squadron"}`, statusCode: http.StatusBadRequest},
+		{name: "Invalid parent", body: `{"name":"orphan","kind":"flight"}`, statusCode: http.StatusBadRequest},
+		{name: "Backend error", body: `{"name":"bad","kind":"squadron"}`, statusCode: http.StatusInternalServerError},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/api/unit", strings.NewReader(tt.body))
+			r.AddCookie(adminCookie(t))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestDeleteUnit(t *testing.T) {
+	b := newMockBackend()
+	b.deleteUnitOverride = func(id string) error {
+		switch id {
+		case "good":
+			return nil
+		case "notfound":
+			return backend.ErrUnitNotFound
+		case "parent":
+			return backend.ErrUnitHasSubunits
+		}
+		return errors.New("unexpected case")
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		id         string
+		statusCode int
+	}{
+		{id: "good", statusCode: http.StatusOK},
+		{id: "notfound", statusCode: http.StatusNotFound},
+		{id: "parent", statusCode: http.StatusConflict},
+		{id: "bad", statusCode: http.StatusInternalServerError},
+	}
+	for _, tt := range tc {
+		t.Run(tt.id, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/unit/%s", tt.id), nil)
+			r.Header.Set("If-Match", "*")
+			r.AddCookie(adminCookie(t))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
+
+func TestGetAllMembersByUnit(t *testing.T) {
+	member := testutils.RandomMember(false)
+	member.ID = uuid.NewString()
+	b := newMockBackend()
+	b.listMembersOverride = func(opts types.MemberListOptions) (types.Page[types.Member], error) {
+		if slices.Equal(opts.UnitIDs, []string{"flight"}) {
+			return types.Page[types.Member]{Items: []types.Member{member}, Total: 1}, nil
+		}
+		return types.Page[types.Member]{}, backend.ErrUnitNotFound
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/members?unit_id=flight", nil)
+	r.AddCookie(roleCookie(t, types.RoleMember))
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	var members types.Page[types.ApiMember]
+	if err := json.NewDecoder(w.Body).Decode(&members); err != nil {
+		t.Fatalf("Error decoding members: %s", err.Error())
+	}
+	if !reflect.DeepEqual(members.Items, []types.ApiMember{member.ToApiMember()}) {
+		t.Errorf("Expected only the flight's member, got %+v", members.Items)
+	}
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/api/members?unit_id=missing", nil)
+	r.AddCookie(roleCookie(t, types.RoleMember))
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d filtering by an unknown unit, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+// TestUnitAdminRoutes checks members without the role wide permission can look after the units they administer and
+// nothing else.
+func TestUnitAdminRoutes(t *testing.T) {
+	unitAdmin := testutils.RandomMember(false)
+	unitAdmin.ID = uuid.NewString()
+	unitAdmin.Role = types.RoleMember
+	token, err := api.CreateToken(unitAdmin, types.DefaultTenantID, types.DefaultRolePermissions[types.RoleMember], time.Hour, []byte("test"))
+	if err != nil {
+		t.Fatalf("Error creating token: %s", err.Error())
+	}
+	cookie := &http.Cookie{Name: api.JWTCookieName, Value: token}
+
+	b := newMockBackend()
+	b.isUnitAdminOverride = func(memberID, unitID string) (bool, error) {
+		return memberID == unitAdmin.ID && unitID == "mine", nil
+	}
+	b.getMemberOverride = func(id string) (types.Member, error) {
+		m := testutils.RandomMember(false)
+		m.ID = id
+		m.UnitID = strings.TrimPrefix(id, "in-")
+		return m, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+	}{
+		{name: "Report on own unit", method: http.MethodGet, path: "/api/unit/mine/report", statusCode: http.StatusOK},
+		{name: "Report on other unit", method: http.MethodGet, path: "/api/unit/other/report", statusCode: http.StatusForbidden},
+		{name: "Qualifications of own unit", method: http.MethodPut, path: "/api/unit/mine/qualifications", body: `{"qualifications":[]}`, statusCode: http.StatusOK},
+		{name: "Qualifications of other unit", method: http.MethodPut, path: "/api/unit/other/qualifications", body: `{"qualifications":[]}`, statusCode: http.StatusForbidden},
+		{name: "Move member into own unit", method: http.MethodPut, path: "/api/member/someone/unit/mine", statusCode: http.StatusOK},
+		{name: "Move member into other unit", method: http.MethodPut, path: "/api/member/someone/unit/other", statusCode: http.StatusForbidden},
+		{name: "Remove member from own unit", method: http.MethodDelete, path: "/api/member/in-mine/unit", statusCode: http.StatusOK},
+		{name: "Remove member from other unit", method: http.MethodDelete, path: "/api/member/in-other/unit", statusCode: http.StatusForbidden},
+		{name: "Rename own unit", method: http.MethodPut, path: "/api/unit/mine", body: `{"name":"Renamed"}`, statusCode: http.StatusForbidden},
+		{name: "Designate unit admin", method: http.MethodPut, path: fmt.Sprintf("/api/unit/mine/admin/%s", uuid.NewString()), statusCode: http.StatusForbidden},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			r.AddCookie(cookie)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Errorf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+)
+
+func (s Server) grantWaiver(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	var waiver types.Waiver
+	if err := json.NewDecoder(r.Body).Decode(&waiver); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid waiver JSON sent from client", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	waiver.MemberID = r.PathValue("id")
+	waiver, err := s.backendFor(r).GrantWaiver(r.Context(), caller.MemberID, waiver)
+	if errors.Is(err, backend.ErrMissingArgs) || errors.Is(err, backend.ErrInvalidWaiver) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	} else if errors.Is(err, backend.ErrMemberNotFound) || errors.Is(err, backend.ErrRequirementNotFound) ||
+		errors.Is(err, backend.ErrQualificationNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(waiver); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing waiver to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getWaiver(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	waiver, err := s.backendFor(r).GetWaiver(r.Context(), r.PathValue("id"))
+	if errors.Is(err, backend.ErrWaiverNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(waiver); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing waiver to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getMemberWaivers(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	waivers, err := s.backendFor(r).GetMemberWaivers(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(waivers); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing waivers to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getWaiverMemo(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	memo, err := s.backendFor(r).GetWaiverMemo(r.Context(), r.PathValue("id"))
+	if errors.Is(err, backend.ErrWaiverNotFound) || errors.Is(err, backend.ErrWaiverMemoNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	contentType := memo.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": memo.Name}))
+	if _, err = w.Write(memo.Data); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error writing waiver memo to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) revokeWaiver(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	caller, status := s.requestIdentity(r)
+	if status != http.StatusOK {
+		writeError(w, status, errUnauthenticated)
+		return
+	}
+	var req RevokeWaiverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		l.LogAttrs(r.Context(), slog.LevelWarn, "Invalid revoke JSON sent from client", slog.String("error", err.Error()))
+		writeError(w, http.StatusBadRequest, invalidBody(err))
+		return
+	}
+	defer r.Body.Close()
+	waiver, err := s.backendFor(r).RevokeWaiver(r.Context(), caller.MemberID, r.PathValue("id"), req.Reason)
+	if errors.Is(err, backend.ErrWaiverNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	} else if errors.Is(err, backend.ErrInvalidWaiver) {
+		writeError(w, http.StatusConflict, err)
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(waiver); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing waiver to client", slog.String("error", err.Error()))
+	}
+}
+
+func (s Server) getWaiverAudit(w http.ResponseWriter, r *http.Request) {
+	l := s.logger.With(slog.String("path", fmt.Sprintf("%s %s", r.Method, r.URL.Path)))
+	entries, err := s.backendFor(r).GetAuditEntries(r.Context(), backend.AuditEntityWaiver, r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err = json.NewEncoder(w).Encode(entries); err != nil {
+		l.LogAttrs(r.Context(), slog.LevelError, "Error serializing audit entries to client", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,135 @@
+package api_test
+
+import (
+	"PORTal/api"
+	"PORTal/backend"
+	"PORTal/types"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGrantWaiver(t *testing.T) {
+	b := newMockBackend()
+	b.grantWaiverOverride = func(approverID string, w types.Waiver) (types.Waiver, error) {
+		if w.Reason == "" {
+			return types.Waiver{}, backend.ErrMissingArgs
+		}
+		if w.RequirementID == "missing" {
+			return types.Waiver{}, backend.ErrRequirementNotFound
+		}
+		w.ID = uuid.NewString()
+		w.ApproverID = approverID
+		return w, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+	memberID := uuid.NewString()
+
+	tc := []struct {
+		name       string
+		cookie     *http.Cookie
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "Successful grant",
+			cookie:     roleCookie(t, types.RoleTrainingManager),
+			body:       `{"requirement_id":"abc","reason":"Deployed","end":"2030-01-01T00:00:00Z","memo":{"name":"memo.pdf","content_type":"application/pdf","data":"JVBERi0xLjQ="}}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "Missing reason",
+			cookie:     roleCookie(t, types.RoleTrainingManager),
+			body:       `{"requirement_id":"abc","end":"2030-01-01T00:00:00Z"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Requirement not found",
+			cookie:     roleCookie(t, types.RoleTrainingManager),
+			body:       `{"requirement_id":"missing","reason":"Deployed","end":"2030-01-01T00:00:00Z"}`,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Supervisor can't grant",
+			cookie:     roleCookie(t, types.RoleSupervisor),
+			body:       `{"requirement_id":"abc","reason":"Deployed","end":"2030-01-01T00:00:00Z"}`,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Malformed body",
+			cookie:     roleCookie(t, types.RoleTrainingManager),
+			body:       `{"reason":`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/member/%s/waiver", memberID), strings.NewReader(tt.body))
+			r.AddCookie(tt.cookie)
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if w.Code != http.StatusCreated {
+				return
+			}
+			var waiver types.Waiver
+			if err := json.NewDecoder(w.Body).Decode(&waiver); err != nil {
+				t.Fatalf("Error decoding waiver: %s", err.Error())
+			}
+			if waiver.MemberID != memberID || waiver.ApproverID == "" {
+				t.Errorf("Expected waiver for %s with approver, got: %+v", memberID, waiver)
+			}
+		})
+	}
+}
+
+func TestGetWaiverMemo(t *testing.T) {
+	b := newMockBackend()
+	b.getWaiverMemoOverride = func(id string) (types.WaiverMemo, error) {
+		switch id {
+		case "missing":
+			return types.WaiverMemo{}, backend.ErrWaiverNotFound
+		case "no-memo":
+			return types.WaiverMemo{}, backend.ErrWaiverMemoNotFound
+		}
+		return types.WaiverMemo{Name: "memo.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4")}, nil
+	}
+	s := api.New(slog.Default(), b, false, api.Config{JWTSecret: "test"})
+
+	tc := []struct {
+		name       string
+		id         string
+		statusCode int
+	}{
+		{name: "Memo attached", id: uuid.NewString(), statusCode: http.StatusOK},
+		{name: "Waiver not found", id: "missing", statusCode: http.StatusNotFound},
+		{name: "No memo attached", id: "no-memo", statusCode: http.StatusNotFound},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/waiver/%s/memo", tt.id), nil)
+			r.AddCookie(roleCookie(t, types.RoleMember))
+			s.ServeHTTP(w, r)
+			if w.Code != tt.statusCode {
+				t.Fatalf("Expected status code %d, got %d", tt.statusCode, w.Code)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			if w.Header().Get("Content-Type") != "application/pdf" || w.Body.String() != "%PDF-1.4" {
+				t.Errorf("Expected pdf memo, got %s: %q", w.Header().Get("Content-Type"), w.Body.String())
+			}
+			if w.Header().Get("Content-Disposition") != `attachment; filename=memo.pdf` {
+				t.Errorf("Unexpected Content-Disposition: %s", w.Header().Get("Content-Disposition"))
+			}
+		})
+	}
+}
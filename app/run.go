@@ -3,7 +3,10 @@ package app
 import (
 	"PORTal/api"
 	"PORTal/backend"
+	"PORTal/providers/memory"
+	"PORTal/providers/postgres"
 	"PORTal/providers/sqlite"
+	"PORTal/types"
 	"context"
 	"fmt"
 	"io"
@@ -18,12 +21,29 @@ type Config struct {
 }
 
 func (c Config) Merge(new Config) Config {
+	if new.Backend.Provider != "" {
+		c.Backend.Provider = new.Backend.Provider
+	}
+	if c.Backend.Provider != ProviderSqlite && c.Backend.Provider != ProviderPostgres && c.Backend.Provider != ProviderMemory {
+		panic(fmt.Sprintf("Provider must be %s, %s or %s in configuration file", ProviderSqlite, ProviderPostgres, ProviderMemory))
+	}
 	if new.Backend.DbFile != "" {
 		c.Backend.DbFile = new.Backend.DbFile
 	}
+	// PostgresDSN is required by the postgres provider
+	if c.Backend.Provider == ProviderPostgres && new.Backend.PostgresDSN == "" {
+		panic("PostgresDSN must be defined in configuration file to use the postgres provider")
+	}
+	c.Backend.PostgresDSN = new.Backend.PostgresDSN
 	if new.Backend.BcryptCost != 0 {
 		c.Backend.BcryptCost = new.Backend.BcryptCost
 	}
+	if new.Backend.ArchiveRetentionDays != 0 {
+		c.Backend.ArchiveRetentionDays = new.Backend.ArchiveRetentionDays
+	}
+	c.Backend.InstanceName = new.Backend.InstanceName
+	c.Backend.TransferKey = new.Backend.TransferKey
+	c.Backend.TransferKeys = new.Backend.TransferKeys
 	// Domain must be provided
 	if new.Api.Domain == "" {
 		panic("Domain must be defined in configuration file")
@@ -40,13 +60,35 @@ func (c Config) Merge(new Config) Config {
 	if new.Api.JWTExpiration != 0 {
 		c.Api.JWTExpiration = new.Api.JWTExpiration
 	}
+	// TLS is optional, but a certificate requires a key and client verification requires TLS
+	if (new.Api.TLSCertFile == "") != (new.Api.TLSKeyFile == "") {
+		panic("TLSCertFile and TLSKeyFile must be defined together in configuration file")
+	}
+	if new.Api.ClientCAFile != "" && new.Api.TLSCertFile == "" {
+		panic("ClientCAFile requires TLSCertFile and TLSKeyFile to be defined in configuration file")
+	}
+	c.Api.TLSCertFile = new.Api.TLSCertFile
+	c.Api.TLSKeyFile = new.Api.TLSKeyFile
+	c.Api.ClientCAFile = new.Api.ClientCAFile
+	c.Api.RequireClientCert = new.Api.RequireClientCert
+	c.Api.MultiTenant = new.Api.MultiTenant
 	return c
 }
 
+// The storage providers Config.Backend.Provider can choose from.
+const (
+	ProviderSqlite   = "sqlite"
+	ProviderPostgres = "postgres"
+	// ProviderMemory keeps nothing after shutdown, it's meant for demos
+	ProviderMemory = "memory"
+)
+
 var DefaultConfig Config = Config{
 	Backend: backend.Config{
-		DbFile:     "PORTal.db",
-		BcryptCost: 16,
+		Provider:             ProviderSqlite,
+		DbFile:               "PORTal.db",
+		BcryptCost:           16,
+		ArchiveRetentionDays: 365,
 	},
 	Api: api.Config{
 		Domain:        "",
@@ -56,14 +98,54 @@ var DefaultConfig Config = Config{
 	},
 }
 
-func New(config Config, dev bool, logDest io.Writer) App {
+// New creates the app, failing when the configured database can't be opened rather than serving without one.
+func New(config Config, dev bool, logDest io.Writer) (App, error) {
 	config = DefaultConfig.Merge(config)
-	l := slog.New(slog.NewTextHandler(logDest, &slog.HandlerOptions{AddSource: true, Level: slog.LevelInfo}))
-	provider, err := sqlite.New(l.With(slog.String("service", "sqlite_provider")), config.Backend.DbFile, 1)
+	l := slog.New(api.NewLogHandler(slog.NewTextHandler(logDest, &slog.HandlerOptions{AddSource: true, Level: slog.LevelInfo})))
+	b, err := newBackend(config, l)
 	if err != nil {
 		l.LogAttrs(context.Background(), slog.LevelError, "Error creating provider", slog.String("error", err.Error()))
+		return App{}, err
+	}
+	var server api.Server
+	if config.Api.MultiTenant {
+		server = api.NewMultiTenant(l.With(slog.String("service", "api_server")), tenantDirectory{b}, dev, config.Api)
+	} else {
+		server = api.New(l.With(slog.String("service", "api_server")), b, dev, config.Api)
+	}
+	a := App{
+		server: server,
+		config: config,
+	}
+	return a, nil
+}
+
+// tenantDirectory hands the api server backends scoped to each tenant.
+type tenantDirectory struct {
+	backend.Backend
+}
+
+func (d tenantDirectory) ForTenant(tenantID string) api.Backend {
+	return d.Backend.ForTenant(tenantID)
+}
+
+// ImportMembers runs a roster import straight against the configured database, for standing up a new instance from
+// the command line. See backend.Backend.ImportMembers.
+func ImportMembers(ctx context.Context, config Config, roster io.Reader, dryRun bool, logDest io.Writer) (types.MemberImportReport, error) {
+	config = DefaultConfig.Merge(config)
+	l := slog.New(api.NewLogHandler(slog.NewTextHandler(logDest, &slog.HandlerOptions{AddSource: true, Level: slog.LevelInfo})))
+	b, err := newBackend(config, l)
+	if err != nil {
+		return types.MemberImportReport{}, err
 	}
+	return b.ImportMembers(ctx, roster, dryRun)
+}
 
+func newBackend(config Config, l *slog.Logger) (backend.Backend, error) {
+	provider, err := newProvider(config, l)
+	if err != nil {
+		return backend.Backend{}, err
+	}
 	b := backend.New(
 		l.With(slog.String("service", "backend")),
 		provider,
@@ -71,12 +153,26 @@ func New(config Config, dev bool, logDest io.Writer) App {
 		provider,
 		config.Backend,
 		nil,
-	)
-	a := App{
-		server: api.New(l.With(slog.String("service", "api_server")), b, dev, config.Api),
-		config: config,
+	).WithTenants(provider)
+	return b, nil
+}
+
+// provider is everything the backend stores, whichever database it's stored in.
+type provider interface {
+	backend.MemberProvider
+	backend.QualificationProvider
+	backend.RequirementProvider
+	backend.TenantProvider
+}
+
+func newProvider(config Config, l *slog.Logger) (provider, error) {
+	switch config.Backend.Provider {
+	case ProviderPostgres:
+		return postgres.New(l.With(slog.String("service", "postgres_provider")), config.Backend.PostgresDSN)
+	case ProviderMemory:
+		return memory.New(l.With(slog.String("service", "memory_provider"))), nil
 	}
-	return a
+	return sqlite.New(l.With(slog.String("service", "sqlite_provider")), config.Backend.DbFile, sqlite.SchemaVersion)
 }
 
 type App struct {
@@ -85,5 +181,18 @@ type App struct {
 }
 
 func (a App) Run() {
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", a.config.Api.Port), a.server))
+	addr := fmt.Sprintf(":%d", a.config.Api.Port)
+	if a.config.Api.TLSCertFile == "" {
+		log.Fatal(http.ListenAndServe(addr, a.server))
+	}
+	tlsConfig, err := a.config.Api.TLSConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	server := &http.Server{
+		Addr:      addr,
+		Handler:   a.server,
+		TLSConfig: tlsConfig,
+	}
+	log.Fatal(server.ListenAndServeTLS(a.config.Api.TLSCertFile, a.config.Api.TLSKeyFile))
 }
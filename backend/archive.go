@@ -0,0 +1,134 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+const (
+	AuditEntityMember = "member"
+)
+
+// ArchiveMember takes a member who PCSed or separated off rosters and blocks them from logging in, keeping their
+// training records so they follow the member if they come back. The archive is always dated now, the retention period
+// before they can be purged runs from it.
+func (b Backend) ArchiveMember(ctx context.Context, actorID, memberID string, archive types.MemberArchive) (types.Member, error) {
+	l := b.logger.With(slog.String("member_id", memberID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Archiving member", slog.String("actor_id", actorID))
+	if archive.Reason == "" {
+		return types.Member{}, MissingArgsError{Fields: []string{"Reason"}}
+	}
+	m, err := b.memberProvider.GetMember(ctx, memberID, ById)
+	if err != nil {
+		return types.Member{}, err
+	}
+	if m.Archive != nil {
+		l.LogAttrs(ctx, slog.LevelInfo, "Member is already archived")
+		return types.Member{}, fmt.Errorf("%w: member_id=%s", ErrMemberArchived, memberID)
+	}
+	archive.Date = b.clock.Now()
+	archive.ArchivedBy = actorID
+	err = b.inTx(ctx, func(tx Backend) error {
+		if err := tx.memberProvider.ArchiveMember(ctx, memberID, archive); err != nil {
+			return err
+		}
+		return tx.audit(ctx, actorID, "archive", AuditEntityMember, memberID, archive.Reason)
+	})
+	if err != nil {
+		return types.Member{}, err
+	}
+	m.Archive = &archive
+	return m, nil
+}
+
+// RestoreMember brings an archived member back onto rosters with their records intact.
+func (b Backend) RestoreMember(ctx context.Context, actorID, memberID string) (types.Member, error) {
+	l := b.logger.With(slog.String("member_id", memberID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Restoring member", slog.String("actor_id", actorID))
+	m, err := b.memberProvider.GetMember(ctx, memberID, ById)
+	if err != nil {
+		return types.Member{}, err
+	}
+	if m.Archive == nil {
+		return types.Member{}, fmt.Errorf("%w: member_id=%s", ErrMemberNotArchived, memberID)
+	}
+	err = b.inTx(ctx, func(tx Backend) error {
+		if err := tx.memberProvider.RestoreMember(ctx, memberID); err != nil {
+			return err
+		}
+		return tx.audit(ctx, actorID, "restore", AuditEntityMember, memberID, "")
+	})
+	if err != nil {
+		return types.Member{}, err
+	}
+	m.Archive = nil
+	return m, nil
+}
+
+func (b Backend) GetArchivedMembers(ctx context.Context) ([]types.Member, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting archived members")
+	return b.memberProvider.GetArchivedMembers(ctx)
+}
+
+// PurgeMember permanently deletes a member and all of their records. Only members who have been archived for longer
+// than the retention period can be purged.
+func (b Backend) PurgeMember(ctx context.Context, actorID, identifier string) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Purging member", slog.String("identifier", identifier), slog.String("actor_id", actorID))
+	method := ById
+	if _, err := uuid.Parse(identifier); err != nil {
+		method = ByUsername
+	}
+	m, err := b.memberProvider.GetMember(ctx, identifier, method)
+	if err != nil {
+		return err
+	}
+	if err = b.purgeable(m); err != nil {
+		return err
+	}
+	return b.purge(ctx, actorID, m)
+}
+
+// PurgeArchivedMembers purges every member whose retention period has passed, returning the IDs of those purged.
+func (b Backend) PurgeArchivedMembers(ctx context.Context, actorID string) ([]string, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Purging archived members past retention", slog.String("actor_id", actorID))
+	archived, err := b.memberProvider.GetArchivedMembers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	purged := []string{}
+	for _, m := range archived {
+		if b.purgeable(m) != nil {
+			continue
+		}
+		if err = b.purge(ctx, actorID, m); err != nil {
+			return purged, err
+		}
+		purged = append(purged, m.ID)
+	}
+	b.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Purged %d archived members", len(purged)))
+	return purged, nil
+}
+
+func (b Backend) purgeable(m types.Member) error {
+	if m.Archive == nil {
+		return fmt.Errorf("%w: member_id=%s", ErrMemberNotArchived, m.ID)
+	}
+	retainUntil := m.Archive.Date.Add(time.Duration(b.config.ArchiveRetentionDays) * types.Day)
+	if b.clock.Now().Before(retainUntil) {
+		return fmt.Errorf("%w: member_id=%s retained until %s", ErrRetentionPeriod, m.ID, retainUntil.Format(time.DateOnly))
+	}
+	return nil
+}
+
+func (b Backend) purge(ctx context.Context, actorID string, m types.Member) error {
+	return b.inTx(ctx, func(tx Backend) error {
+		if err := tx.memberProvider.DeleteMember(ctx, m.ID, ById); err != nil {
+			return err
+		}
+		return tx.audit(ctx, actorID, "purge", AuditEntityMember, m.ID, fmt.Sprintf("%s %s %s (%s)", m.Rank, m.FirstName, m.LastName, m.Username))
+	})
+}
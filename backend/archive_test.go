@@ -0,0 +1,239 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestArchiveAndRestoreMember(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, expireClock{})
+
+	admin, err := b.AddMember(ctx, testutils.RandomMember(true))
+	if err != nil {
+		t.Fatalf("Error adding member for TestArchiveAndRestoreMember: %s", err.Error())
+	}
+	newMember := testutils.RandomMember(false)
+	newMember.SupervisorID = admin.ID
+	password := newMember.Password
+	member, err := b.AddMember(ctx, newMember)
+	if err != nil {
+		t.Fatalf("Error adding member for TestArchiveAndRestoreMember: %s", err.Error())
+	}
+	qual, err := b.AddQualification(ctx, testutils.RandomQualification())
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestArchiveAndRestoreMember: %s", err.Error())
+	}
+	if err = b.AssignMemberQualification(ctx, admin.ID, member.ID, qual.ID); err != nil {
+		t.Fatalf("Error assigning qualification for TestArchiveAndRestoreMember: %s", err.Error())
+	}
+	token, err := b.CreateAPIToken(ctx, member.ID, "sync", types.ScopeReadOnly)
+	if err != nil {
+		t.Fatalf("Error creating api token for TestArchiveAndRestoreMember: %s", err.Error())
+	}
+
+	if _, err = b.ArchiveMember(ctx, admin.ID, member.ID, types.MemberArchive{}); !errors.Is(err, backend.ErrMissingArgs) {
+		t.Errorf("Expected ErrMissingArgs archiving without a reason, got %v", err)
+	}
+	if _, err = b.ArchiveMember(ctx, admin.ID, uuid.NewString(), types.MemberArchive{Reason: "PCS"}); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected ErrMemberNotFound archiving unknown member, got %v", err)
+	}
+	archived, err := b.ArchiveMember(ctx, admin.ID, member.ID, types.MemberArchive{Reason: "PCS to Ramstein"})
+	if err != nil {
+		t.Fatalf("Error archiving member: %s", err.Error())
+	}
+	if archived.Archive == nil || archived.Archive.ArchivedBy != admin.ID || !archived.Archive.Date.Equal(expireClock{}.Now()) {
+		t.Errorf("Expected archive to be dated now and record the actor, got %+v", archived.Archive)
+	}
+	if _, err = b.ArchiveMember(ctx, admin.ID, member.ID, types.MemberArchive{Reason: "PCS"}); !errors.Is(err, backend.ErrMemberArchived) {
+		t.Errorf("Expected ErrMemberArchived archiving twice, got %v", err)
+	}
+
+	// Archived members drop off rosters and can't authenticate, but their records stay
+	members, err := b.GetAllMembers(ctx)
+	if err != nil {
+		t.Fatalf("Error getting members: %s", err.Error())
+	}
+	if slices.ContainsFunc(members, func(m types.Member) bool { return m.ID == member.ID }) {
+		t.Errorf("Expected archived member to be excluded from roster")
+	}
+	subordinates, err := b.GetSubordinates(ctx, admin.ID)
+	if err != nil {
+		t.Fatalf("Error getting subordinates: %s", err.Error())
+	}
+	if len(subordinates) != 0 {
+		t.Errorf("Expected archived member to be excluded from subordinates, got %d", len(subordinates))
+	}
+	if _, err = b.Login(ctx, member.Username, password); !errors.Is(err, backend.ErrMemberArchived) {
+		t.Errorf("Expected archived member login to fail with ErrMemberArchived, got %v", err)
+	}
+	if _, _, err = b.AuthenticateAPIToken(ctx, token.Token); !errors.Is(err, backend.ErrAuthenticationFailed) {
+		t.Errorf("Expected archived member's api token to be rejected, got %v", err)
+	}
+	if quals, err := b.GetMemberQualifications(ctx, member.ID); err != nil || len(quals) != 1 {
+		t.Errorf("Expected archived member to keep their qualification, got %d, %v", len(quals), err)
+	}
+	stored, err := b.GetMember(ctx, member.ID)
+	if err != nil {
+		t.Fatalf("Error getting archived member: %s", err.Error())
+	}
+	if stored.Archive == nil || stored.Archive.Reason != "PCS to Ramstein" || stored.Archive.ArchivedBy != admin.ID {
+		t.Errorf("Expected archive details to be stored, got %+v", stored.Archive)
+	}
+	archivedMembers, err := b.GetArchivedMembers(ctx)
+	if err != nil {
+		t.Fatalf("Error getting archived members: %s", err.Error())
+	}
+	if len(archivedMembers) != 1 || archivedMembers[0].ID != member.ID {
+		t.Errorf("Expected only the archived member, got %+v", archivedMembers)
+	}
+
+	restored, err := b.RestoreMember(ctx, admin.ID, member.ID)
+	if err != nil {
+		t.Fatalf("Error restoring member: %s", err.Error())
+	}
+	if restored.Archive != nil {
+		t.Errorf("Expected restored member to have no archive, got %+v", restored.Archive)
+	}
+	if _, err = b.RestoreMember(ctx, admin.ID, member.ID); !errors.Is(err, backend.ErrMemberNotArchived) {
+		t.Errorf("Expected ErrMemberNotArchived restoring twice, got %v", err)
+	}
+	if _, err = b.Login(ctx, member.Username, password); err != nil {
+		t.Errorf("Expected restored member to be able to login, got %s", err.Error())
+	}
+	if subordinates, err = b.GetSubordinates(ctx, admin.ID); err != nil || len(subordinates) != 1 {
+		t.Errorf("Expected restored member back under their supervisor, got %d, %v", len(subordinates), err)
+	}
+	// Tokens were revoked when archiving, a returning member has to create new ones
+	if _, _, err = b.AuthenticateAPIToken(ctx, token.Token); !errors.Is(err, backend.ErrAuthenticationFailed) {
+		t.Errorf("Expected api token to stay revoked after restore, got %v", err)
+	}
+
+	entries, err := b.GetAuditEntries(ctx, backend.AuditEntityMember, member.ID)
+	if err != nil {
+		t.Fatalf("Error getting audit entries: %s", err.Error())
+	}
+	if len(entries) != 2 || entries[0].Action != "archive" || entries[1].Action != "restore" {
+		t.Errorf("Expected archive and restore to be audited, got %+v", entries)
+	}
+}
+
+func TestPurgeMember(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost, ArchiveRetentionDays: 30}, movingClock{now: &now})
+
+	admin, err := b.AddMember(ctx, testutils.RandomMember(true))
+	if err != nil {
+		t.Fatalf("Error adding member for TestPurgeMember: %s", err.Error())
+	}
+	supervisor, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestPurgeMember: %s", err.Error())
+	}
+	subordinate := testutils.RandomMember(false)
+	subordinate.SupervisorID = supervisor.ID
+	subordinate, err = b.AddMember(ctx, subordinate)
+	if err != nil {
+		t.Fatalf("Error adding member for TestPurgeMember: %s", err.Error())
+	}
+	recent, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestPurgeMember: %s", err.Error())
+	}
+	qual, err := b.AddQualification(ctx, testutils.RandomQualification())
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestPurgeMember: %s", err.Error())
+	}
+	if err = b.AssignMemberQualification(ctx, admin.ID, supervisor.ID, qual.ID); err != nil {
+		t.Fatalf("Error assigning qualification for TestPurgeMember: %s", err.Error())
+	}
+
+	if err = b.PurgeMember(ctx, admin.ID, supervisor.ID); !errors.Is(err, backend.ErrMemberNotArchived) {
+		t.Errorf("Expected ErrMemberNotArchived purging an active member, got %v", err)
+	}
+	if err = b.PurgeMember(ctx, admin.ID, uuid.NewString()); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected ErrMemberNotFound purging unknown member, got %v", err)
+	}
+	// Backdating the archive doesn't shorten the retention period
+	backdated, err := b.ArchiveMember(ctx, admin.ID, supervisor.ID, types.MemberArchive{Reason: "Separated", Date: now.Add(-365 * types.Day)})
+	if err != nil {
+		t.Fatalf("Error archiving member for TestPurgeMember: %s", err.Error())
+	}
+	if !backdated.Archive.Date.Equal(now) {
+		t.Errorf("Expected archive to be dated %s, got %s", now, backdated.Archive.Date)
+	}
+	if err = b.PurgeMember(ctx, admin.ID, supervisor.ID); !errors.Is(err, backend.ErrRetentionPeriod) {
+		t.Errorf("Expected ErrRetentionPeriod purging a backdated archive inside retention, got %v", err)
+	}
+
+	now = now.Add(31 * types.Day)
+	if _, err = b.ArchiveMember(ctx, admin.ID, recent.ID, types.MemberArchive{Reason: "PCS"}); err != nil {
+		t.Fatalf("Error archiving member for TestPurgeMember: %s", err.Error())
+	}
+	if err = b.PurgeMember(ctx, admin.ID, supervisor.Username); err != nil {
+		t.Fatalf("Error purging member by username: %s", err.Error())
+	}
+	if _, err = b.GetMember(ctx, supervisor.ID); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected purged member to be gone, got %v", err)
+	}
+	if history, err := provider.GetQualificationHistory(ctx, supervisor.ID, qual.ID); err != nil || len(history) != 0 {
+		t.Errorf("Expected purged member's qualification history to be deleted, got %d, %v", len(history), err)
+	}
+	if m, err := b.GetMember(ctx, subordinate.ID); err != nil || m.SupervisorID != "" {
+		t.Errorf("Expected purged supervisor to be cleared from subordinate, got %q, %v", m.SupervisorID, err)
+	}
+	entries, err := b.GetAuditEntries(ctx, backend.AuditEntityMember, supervisor.ID)
+	if err != nil || len(entries) != 2 || entries[1].Action != "purge" {
+		t.Errorf("Expected purge to be audited, got %+v, %v", entries, err)
+	}
+
+	// Sweeping only picks up members whose retention has run out
+	if purged, err := b.PurgeArchivedMembers(ctx, admin.ID); err != nil || len(purged) != 0 {
+		t.Errorf("Expected nothing to be purged yet, got %v, %v", purged, err)
+	}
+	now = now.Add(31 * types.Day)
+	purged, err := b.PurgeArchivedMembers(ctx, admin.ID)
+	if err != nil {
+		t.Fatalf("Error purging archived members: %s", err.Error())
+	}
+	if len(purged) != 1 || purged[0] != recent.ID {
+		t.Errorf("Expected only %s to be purged, got %v", recent.ID, purged)
+	}
+}
@@ -0,0 +1,37 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"github.com/google/uuid"
+	"log/slog"
+)
+
+const (
+	AuditEntityWaiver = "waiver"
+)
+
+// audit records an action in the audit log. Callers treat a failure to record as a failure of the action itself.
+func (b Backend) audit(ctx context.Context, actorID, action, entityType, entityID, details string) error {
+	e := types.AuditEntry{
+		ID:         uuid.NewString(),
+		ActorID:    actorID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Time:       b.clock.Now(),
+		Details:    details,
+	}
+	if err := b.memberProvider.AddAuditEntry(ctx, e); err != nil {
+		b.logger.LogAttrs(ctx, slog.LevelError, "Unable to record audit entry",
+			slog.String("action", action), slog.String("entity_id", entityID), slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (b Backend) GetAuditEntries(ctx context.Context, entityType, entityID string) ([]types.AuditEntry, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting audit entries",
+		slog.String("entity_type", entityType), slog.String("entity_id", entityID))
+	return b.memberProvider.GetAuditEntries(ctx, entityType, entityID)
+}
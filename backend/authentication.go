@@ -3,19 +3,41 @@ package backend
 import (
 	"PORTal/types"
 	"context"
+	"fmt"
 	"golang.org/x/crypto/bcrypt"
 	"log/slog"
 )
 
-func (b Backend) Login(username, password string) (types.Member, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Attempting to login member", slog.String("username", username))
-	member, err := b.memberProvider.GetMember(username, ByUsername)
+func (b Backend) Login(ctx context.Context, username, password string) (types.Member, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Attempting to login member", slog.String("username", username))
+	member, err := b.memberProvider.GetMember(ctx, username, ByUsername)
 	if err != nil {
 		return types.Member{}, ErrAuthenticationFailed
 	}
 	if err = bcrypt.CompareHashAndPassword([]byte(member.Hash), []byte(password)); err != nil {
-		b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Password validation failed")
+		b.logger.LogAttrs(ctx, slog.LevelInfo, "Password validation failed")
 		return types.Member{}, ErrAuthenticationFailed
 	}
+	if member.Archive != nil {
+		b.logger.LogAttrs(ctx, slog.LevelInfo, "Archived member attempted to login")
+		return types.Member{}, fmt.Errorf("%w: %w", ErrAuthenticationFailed, ErrMemberArchived)
+	}
+	return member, nil
+}
+
+func (b Backend) LoginWithCertificate(ctx context.Context, certificateID string) (types.Member, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Attempting to login member with client certificate", slog.String("certificate_id", certificateID))
+	if certificateID == "" {
+		return types.Member{}, ErrAuthenticationFailed
+	}
+	member, err := b.memberProvider.GetMember(ctx, certificateID, ByCertificateID)
+	if err != nil {
+		b.logger.LogAttrs(ctx, slog.LevelInfo, "No member bound to certificate")
+		return types.Member{}, ErrAuthenticationFailed
+	}
+	if member.Archive != nil {
+		b.logger.LogAttrs(ctx, slog.LevelInfo, "Archived member attempted to login")
+		return types.Member{}, fmt.Errorf("%w: %w", ErrAuthenticationFailed, ErrMemberArchived)
+	}
 	return member, nil
 }
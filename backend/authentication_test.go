@@ -1,7 +1,19 @@
 package backend_test
 
 import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
 	"time"
 )
 
@@ -14,3 +26,82 @@ func (e expireClock) Now() time.Time {
 	}
 	return t
 }
+
+func TestLoginWithCertificate(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+
+	member, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestLoginWithCertificate: %s", err.Error())
+	}
+	other, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestLoginWithCertificate: %s", err.Error())
+	}
+	if _, err = b.BindMemberCertificate(ctx, member.ID, "1234567890"); err != nil {
+		t.Fatalf("Error binding certificate for TestLoginWithCertificate: %s", err.Error())
+	}
+	if _, err = b.BindMemberCertificate(ctx, other.ID, "1234567890"); !errors.Is(err, backend.ErrDuplicateCertificate) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrDuplicateCertificate, err)
+	}
+	if _, err = b.BindMemberCertificate(ctx, uuid.NewString(), "0987654321"); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMemberNotFound, err)
+	}
+
+	tc := []struct {
+		Name          string
+		CertificateID string
+		ExpectedID    string
+		ExpectedError error
+	}{
+		{
+			Name:          "Successful login",
+			CertificateID: "1234567890",
+			ExpectedID:    member.ID,
+		},
+		{
+			Name:          "Unbound certificate",
+			CertificateID: "0987654321",
+			ExpectedError: backend.ErrAuthenticationFailed,
+		},
+		{
+			Name:          "Empty identifier",
+			CertificateID: "",
+			ExpectedError: backend.ErrAuthenticationFailed,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.Name, func(t *testing.T) {
+			m, err := b.LoginWithCertificate(ctx, tt.CertificateID)
+			if tt.ExpectedError == nil && err != nil {
+				t.Errorf("Expected no error but got: %s", err.Error())
+			}
+			if tt.ExpectedError != nil && !errors.Is(err, tt.ExpectedError) {
+				t.Errorf("Expected error: %s, got: %v", tt.ExpectedError.Error(), err)
+			}
+			if m.ID != tt.ExpectedID {
+				t.Errorf("Expected member ID: %s, got: %s", tt.ExpectedID, m.ID)
+			}
+		})
+	}
+
+	// Unbinding should prevent further logins with the certificate
+	if _, err = b.BindMemberCertificate(ctx, member.ID, ""); err != nil {
+		t.Fatalf("Error unbinding certificate for TestLoginWithCertificate: %s", err.Error())
+	}
+	if _, err = b.LoginWithCertificate(ctx, "1234567890"); !errors.Is(err, backend.ErrAuthenticationFailed) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrAuthenticationFailed, err)
+	}
+}
@@ -14,6 +14,7 @@ type ProviderMethod int
 const (
 	ById ProviderMethod = iota
 	ByUsername
+	ByCertificateID
 )
 
 const MinimumPwLength = 8
@@ -22,44 +23,144 @@ type Backend struct {
 	memberProvider        MemberProvider
 	qualificationProvider QualificationProvider
 	requirementProvider   RequirementProvider
+	tenantProvider        TenantProvider
+	tenantID              string
 	clock                 Clock
 	logger                *slog.Logger
 	config                Config
 }
 
 type MemberProvider interface {
-	AddMember(m types.Member) error
-	GetMember(identifier string, method ProviderMethod) (types.Member, error)
-	GetAllMembers() ([]types.Member, error)
-	GetSubordinates(memberID string) ([]types.Member, error)
-	UpdateMember(member types.Member) error
-	DeleteMember(identifier string, method ProviderMethod) error
-	AssignMemberQualification(memberID, qualificationID string) error
-	GetMemberQualification(memberID, qualificationID string) (types.Qualification, error)
-	GetMemberQualifications(memberID string) ([]types.Qualification, error)
-	RemoveMemberQualification(memberID, qualificationID string) error
+	Transactor
+	AddMember(ctx context.Context, m types.Member) error
+	AddMembers(ctx context.Context, members []types.Member) error
+	GetMember(ctx context.Context, identifier string, method ProviderMethod) (types.Member, error)
+	GetAllMembers(ctx context.Context) ([]types.Member, error)
+	ListMembers(ctx context.Context, opts types.MemberListOptions) (types.Page[types.Member], error)
+	GetSubordinates(ctx context.Context, memberID string) ([]types.Member, error)
+	UpdateMember(ctx context.Context, member types.Member) error
+	DeleteMember(ctx context.Context, identifier string, method ProviderMethod) error
+	ArchiveMember(ctx context.Context, memberID string, archive types.MemberArchive) error
+	RestoreMember(ctx context.Context, memberID string) error
+	GetArchivedMembers(ctx context.Context) ([]types.Member, error)
+	ImportTransfer(ctx context.Context, t types.TransferImport) error
+	IsTransferImported(ctx context.Context, packageID string) (bool, error)
+	GetTransferredMemberID(ctx context.Context, issuer, issuerMemberID string) (string, error)
+	GetMemberTransferOrigins(ctx context.Context, memberID string) (map[string]string, error)
+	AssignMemberQualification(ctx context.Context, memberID, qualificationID string) error
+	GetMemberQualification(ctx context.Context, memberID, qualificationID string) (types.Qualification, error)
+	GetMemberQualifications(ctx context.Context, memberID string) ([]types.Qualification, error)
+	RemoveMemberQualification(ctx context.Context, memberID, qualificationID string) error
+	AssignMemberPositionQualification(ctx context.Context, memberID, qualificationID, positionID string) error
+	GetMemberPositionQualificationIDs(ctx context.Context, memberID string) ([]string, error)
+	AssignMemberQualifications(ctx context.Context, pairs []types.MemberQualificationPair) ([]error, error)
+	RemoveMemberQualifications(ctx context.Context, pairs []types.MemberQualificationPair) ([]error, error)
+	AddQualificationEvent(ctx context.Context, e types.QualificationEvent) error
+	GetQualificationHistory(ctx context.Context, memberID, qualificationID string) ([]types.QualificationEvent, error)
+	GetMemberRequirements(ctx context.Context, memberID string) ([]types.MemberRequirement, error)
+	AssignMemberDutyPosition(ctx context.Context, memberID, positionID string) error
+	GetMemberDutyPositionIDs(ctx context.Context, memberID string) ([]string, error)
+	GetDutyPositionMemberIDs(ctx context.Context, positionID string) ([]string, error)
+	RemoveMemberDutyPosition(ctx context.Context, memberID, positionID string) error
+	AddUnit(ctx context.Context, u types.Unit) error
+	GetUnit(ctx context.Context, id string) (types.Unit, error)
+	GetUnits(ctx context.Context) ([]types.Unit, error)
+	UpdateUnit(ctx context.Context, u types.Unit) error
+	DeleteUnit(ctx context.Context, id string) error
+	SetMemberUnit(ctx context.Context, memberID, unitID string) error
+	GetUnitMembers(ctx context.Context, unitID string) ([]types.Member, error)
+	AddUnitAdmin(ctx context.Context, unitID, memberID string) error
+	GetUnitAdminIDs(ctx context.Context, unitID string) ([]string, error)
+	GetAdministeredUnitIDs(ctx context.Context, memberID string) ([]string, error)
+	RemoveUnitAdmin(ctx context.Context, unitID, memberID string) error
+	AddAPIToken(ctx context.Context, t types.APIToken) error
+	GetAPITokenByHash(ctx context.Context, hash string) (types.APIToken, error)
+	GetAPITokens(ctx context.Context, memberID string) ([]types.APIToken, error)
+	UpdateAPITokenLastUsed(ctx context.Context, id string, lastUsed time.Time) error
+	DeleteAPIToken(ctx context.Context, memberID, id string) error
+	GetRolePermissions(ctx context.Context, role types.Role) ([]types.Permission, error)
+	GetRoles(ctx context.Context) ([]types.RoleDefinition, error)
+	SetRolePermissions(ctx context.Context, role types.Role, permissions []types.Permission) error
+	AddWaiver(ctx context.Context, w types.Waiver) error
+	GetWaiver(ctx context.Context, id string) (types.Waiver, error)
+	GetMemberWaivers(ctx context.Context, memberID string) ([]types.Waiver, error)
+	GetWaiverMemo(ctx context.Context, id string) (types.WaiverMemo, error)
+	UpdateWaiverEnd(ctx context.Context, id string, end time.Time) error
+	AddAuditEntry(ctx context.Context, e types.AuditEntry) error
+	GetAuditEntries(ctx context.Context, entityType, entityID string) ([]types.AuditEntry, error)
 }
 
 type QualificationProvider interface {
-	AddQualification(q types.Qualification) error
-	GetQualification(id string) (types.Qualification, error)
-	GetAllQualifications() ([]types.Qualification, error)
-	UpdateQualification(q types.Qualification) error
-	DeleteQualification(id string) error
+	Transactor
+	AddQualification(ctx context.Context, q types.Qualification) error
+	GetQualification(ctx context.Context, id string) (types.Qualification, error)
+	GetAllQualifications(ctx context.Context) ([]types.Qualification, error)
+	ListQualifications(ctx context.Context, opts types.ListOptions) (types.Page[types.Qualification], error)
+	UpdateQualification(ctx context.Context, q types.Qualification) error
+	DeleteQualification(ctx context.Context, id string) error
+	GetPrerequisiteGraph(ctx context.Context) (map[string][]string, error)
+	AddDutyPosition(ctx context.Context, d types.DutyPosition) error
+	GetDutyPosition(ctx context.Context, id string) (types.DutyPosition, error)
+	GetDutyPositions(ctx context.Context) ([]types.DutyPosition, error)
+	UpdateDutyPosition(ctx context.Context, d types.DutyPosition) error
+	DeleteDutyPosition(ctx context.Context, id string) error
 }
 
 type RequirementProvider interface {
-	AddRequirement(r types.Requirement) error
-	GetRequirement(id string) (types.Requirement, error)
-	GetAllRequirements() ([]types.Requirement, error)
-	GetQualificationIDsForRequirement(requirementID string) ([]string, error)
-	UpdateRequirement(r types.Requirement) error
-	DeleteRequirement(id string) error
-	AddReference(r types.Reference) error
-	GetReference(id string) (types.Reference, error)
-	GetReferences() ([]types.Reference, error)
-	UpdateReference(r types.Reference) error
-	DeleteReference(id string) error
+	Transactor
+	AddRequirement(ctx context.Context, r types.Requirement) error
+	GetRequirement(ctx context.Context, id string) (types.Requirement, error)
+	GetAllRequirements(ctx context.Context) ([]types.Requirement, error)
+	ListRequirements(ctx context.Context, opts types.ListOptions) (types.Page[types.Requirement], error)
+	GetQualificationIDsForRequirement(ctx context.Context, requirementID string) ([]string, error)
+	UpdateRequirement(ctx context.Context, r types.Requirement) error
+	DeleteRequirement(ctx context.Context, id string) error
+	AddReference(ctx context.Context, r types.Reference) error
+	GetReference(ctx context.Context, id string) (types.Reference, error)
+	GetReferences(ctx context.Context) ([]types.Reference, error)
+	UpdateReference(ctx context.Context, r types.Reference) error
+	DeleteReference(ctx context.Context, id string) error
+	AddCertifier(ctx context.Context, requirementID, memberID string) error
+	GetCertifierIDs(ctx context.Context, requirementID string) ([]string, error)
+	IsCertifier(ctx context.Context, requirementID, memberID string) (bool, error)
+	RemoveCertifier(ctx context.Context, requirementID, memberID string) error
+	AddCompletion(ctx context.Context, c types.Completion) error
+	GetCompletion(ctx context.Context, id string) (types.Completion, error)
+	GetMemberCompletions(ctx context.Context, memberID string) ([]types.Completion, error)
+	GetPendingCompletions(ctx context.Context, certifierID string) ([]types.Completion, error)
+	ReviewCompletion(ctx context.Context, c types.Completion) error
+	AddImportProfile(ctx context.Context, profile types.ImportProfile) error
+	GetImportProfile(ctx context.Context, id string) (types.ImportProfile, error)
+	GetImportProfiles(ctx context.Context) ([]types.ImportProfile, error)
+	DeleteImportProfile(ctx context.Context, id string) error
+	AddImportBatch(ctx context.Context, batch types.ImportBatch) error
+	GetImportBatch(ctx context.Context, id string) (types.ImportBatch, error)
+	UpdateImportRow(ctx context.Context, batchID string, row types.StagedRow) error
+	CommitImportBatch(ctx context.Context, batchID string, completions []types.Completion) error
+	DeleteImportBatch(ctx context.Context, id string) error
+}
+
+// Transactor groups provider calls into a unit of work that's committed as a whole or not at all.
+type Transactor interface {
+	// WithTx runs fn against providers sharing one transaction, committing it if fn returns nil and rolling it back
+	// otherwise. Called on providers already in a transaction it joins it, only undoing fn's own changes if fn fails.
+	WithTx(ctx context.Context, fn func(tx Providers) error) error
+}
+
+// Providers are the providers taking part in a transaction, see Transactor.
+type Providers struct {
+	Members        MemberProvider
+	Qualifications QualificationProvider
+	Requirements   RequirementProvider
+}
+
+// TenantProvider keeps the registry of tenants sharing a deployment and scopes the other providers to one of them.
+type TenantProvider interface {
+	AddTenant(ctx context.Context, t types.Tenant, admin types.Member) error
+	GetTenant(ctx context.Context, id string) (types.Tenant, error)
+	GetTenantBySubdomain(ctx context.Context, subdomain string) (types.Tenant, error)
+	GetTenants(ctx context.Context) ([]types.Tenant, error)
+	Scoped(tenantID string) (MemberProvider, QualificationProvider, RequirementProvider)
 }
 
 type Clock interface {
@@ -67,8 +168,21 @@ type Clock interface {
 }
 
 type Config struct {
-	DbFile     string `yaml:"DbFile"`
-	BcryptCost int    `yaml:"BcryptCost"`
+	// Provider is the database everything is stored in, either sqlite, postgres, or memory for demos that don't need
+	// to keep anything.
+	Provider string `yaml:"Provider"`
+	DbFile   string `yaml:"DbFile"`
+	// PostgresDSN is the connection string for the postgres provider.
+	PostgresDSN string `yaml:"PostgresDSN"`
+	BcryptCost  int    `yaml:"BcryptCost"`
+	// ArchiveRetentionDays is how long a member has to be archived before their records can be purged.
+	ArchiveRetentionDays int `yaml:"ArchiveRetentionDays"`
+	// InstanceName identifies this instance as the issuer of the transfer packages it exports.
+	InstanceName string `yaml:"InstanceName"`
+	// TransferKey signs exported transfer packages and verifies imported ones from issuers without their own key.
+	TransferKey string `yaml:"TransferKey"`
+	// TransferKeys are per-instance keys by issuer name, taking precedence over TransferKey.
+	TransferKeys map[string]string `yaml:"TransferKeys"`
 }
 
 type realTime struct{}
@@ -88,8 +202,19 @@ func New(logger *slog.Logger, memberProvider MemberProvider, qualificationProvid
 		memberProvider:        memberProvider,
 		qualificationProvider: qualificationProvider,
 		requirementProvider:   requirementProvider,
+		tenantID:              types.DefaultTenantID,
 		clock:                 clock,
 		logger:                logger,
 		config:                config,
 	}
 }
+
+// inTx runs fn with a copy of the backend whose providers share one transaction, so a write spanning several provider
+// calls, its audit entries and history included, either happens in full or not at all. fn must only use the copy.
+func (b Backend) inTx(ctx context.Context, fn func(tx Backend) error) error {
+	return b.memberProvider.WithTx(ctx, func(p Providers) error {
+		tx := b
+		tx.memberProvider, tx.qualificationProvider, tx.requirementProvider = p.Members, p.Qualifications, p.Requirements
+		return fn(tx)
+	})
+}
@@ -0,0 +1,172 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+// errInjected stands in for the database failing partway through a write.
+var errInjected = errors.New("injected failure")
+
+// failingProvider fails the provider method named by fail, inside transactions as well as outside them.
+type failingProvider struct {
+	sqlite.Provider
+	fail string
+}
+
+func (p failingProvider) WithTx(ctx context.Context, fn func(tx backend.Providers) error) error {
+	return p.Provider.WithTx(ctx, func(tx backend.Providers) error {
+		bound := failingProvider{Provider: tx.Members.(sqlite.Provider), fail: p.fail}
+		return fn(backend.Providers{Members: bound, Qualifications: bound, Requirements: bound})
+	})
+}
+
+func (p failingProvider) AddQualificationEvent(ctx context.Context, e types.QualificationEvent) error {
+	if p.fail == "AddQualificationEvent" {
+		return errInjected
+	}
+	return p.Provider.AddQualificationEvent(ctx, e)
+}
+
+func (p failingProvider) AddAuditEntry(ctx context.Context, e types.AuditEntry) error {
+	if p.fail == "AddAuditEntry" {
+		return errInjected
+	}
+	return p.Provider.AddAuditEntry(ctx, e)
+}
+
+func TestTransactionsRollBack(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+	failing := func(method string) backend.Backend {
+		p := failingProvider{Provider: provider, fail: method}
+		return backend.New(logger, p, p, p, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+	}
+
+	admin, err := b.AddMember(ctx, testutils.RandomMember(true))
+	if err != nil {
+		t.Fatalf("Error adding member for TestTransactionsRollBack: %s", err.Error())
+	}
+	member, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestTransactionsRollBack: %s", err.Error())
+	}
+	first, err := b.AddQualification(ctx, testutils.RandomQualification())
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestTransactionsRollBack: %s", err.Error())
+	}
+	second, err := b.AddQualification(ctx, testutils.RandomQualification())
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestTransactionsRollBack: %s", err.Error())
+	}
+	position, err := b.AddDutyPosition(ctx, types.DutyPosition{Name: testutils.RandomString(), Qualifications: []string{first.ID, second.ID}})
+	if err != nil {
+		t.Fatalf("Error adding duty position for TestTransactionsRollBack: %s", err.Error())
+	}
+	expectNoQualifications := func(t *testing.T) {
+		if quals, err := b.GetMemberQualifications(ctx, member.ID); err != nil || len(quals) != 0 {
+			t.Errorf("Expected member to have no qualifications, got: %v, %v", quals, err)
+		}
+	}
+
+	tc := []struct {
+		Name  string
+		Fail  string
+		Write func(b backend.Backend) error
+		Check func(t *testing.T)
+	}{
+		{
+			Name: "Assigning a qualification without its history",
+			Fail: "AddQualificationEvent",
+			Write: func(b backend.Backend) error {
+				return b.AssignMemberQualification(ctx, admin.ID, member.ID, first.ID)
+			},
+			Check: expectNoQualifications,
+		},
+		{
+			Name: "Assigning a duty position partway through its bundle",
+			Fail: "AddQualificationEvent",
+			Write: func(b backend.Backend) error {
+				_, err := b.AssignMemberDutyPosition(ctx, admin.ID, member.ID, position.ID)
+				return err
+			},
+			Check: func(t *testing.T) {
+				expectNoQualifications(t)
+				if positions, err := b.GetMemberDutyPositions(ctx, member.ID); err != nil || len(positions) != 0 {
+					t.Errorf("Expected member to hold no duty positions, got: %v, %v", positions, err)
+				}
+			},
+		},
+		{
+			Name: "Bulk assigning without history",
+			Fail: "AddQualificationEvent",
+			Write: func(b backend.Backend) error {
+				_, err := b.BulkAssignQualifications(ctx, admin.ID, types.BulkQualificationRequest{
+					MemberIDs:        []string{member.ID},
+					QualificationIDs: []string{first.ID, second.ID},
+				})
+				return err
+			},
+			Check: expectNoQualifications,
+		},
+		{
+			Name: "Archiving without an audit entry",
+			Fail: "AddAuditEntry",
+			Write: func(b backend.Backend) error {
+				_, err := b.ArchiveMember(ctx, admin.ID, member.ID, types.MemberArchive{Reason: "PCS"})
+				return err
+			},
+			Check: func(t *testing.T) {
+				if m, err := b.GetMember(ctx, member.ID); err != nil || m.Archive != nil {
+					t.Errorf("Expected member to stay active, got: %+v, %v", m.Archive, err)
+				}
+			},
+		},
+		{
+			Name: "Granting a waiver without an audit entry",
+			Fail: "AddAuditEntry",
+			Write: func(b backend.Backend) error {
+				_, err := b.GrantWaiver(ctx, admin.ID, types.Waiver{
+					MemberID:        member.ID,
+					QualificationID: first.ID,
+					Reason:          "Deployed",
+					End:             time.Now().Add(time.Hour),
+				})
+				return err
+			},
+			Check: func(t *testing.T) {
+				if waivers, err := b.GetMemberWaivers(ctx, member.ID); err != nil || len(waivers) != 0 {
+					t.Errorf("Expected no waivers to be granted, got: %v, %v", waivers, err)
+				}
+			},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.Name, func(t *testing.T) {
+			if err := tt.Write(failing(tt.Fail)); !errors.Is(err, errInjected) {
+				t.Fatalf("Expected error: %s, got: %v", errInjected, err)
+			}
+			tt.Check(t)
+		})
+	}
+}
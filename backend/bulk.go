@@ -0,0 +1,152 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// BulkAssignQualifications assigns every requested qualification to every requested member in one transaction.
+// Failures are reported per item rather than failing the batch, and already assigned qualifications are skipped.
+func (b Backend) BulkAssignQualifications(ctx context.Context, actorID string, req types.BulkQualificationRequest) ([]types.BulkItemResult, error) {
+	pairs, err := b.bulkPairs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Bulk assigning qualifications", slog.Int("count", len(pairs)))
+	var errs []error
+	err = b.inTx(ctx, func(tx Backend) error {
+		if errs, err = tx.memberProvider.AssignMemberQualifications(ctx, pairs); err != nil {
+			return err
+		}
+		return tx.recordBulkEvents(ctx, actorID, pairs, errs, types.QualificationAssigned)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bulkResults(pairs, errs, types.BulkItemAssigned, ErrQualificationAlreadyAssigned), nil
+}
+
+// BulkRemoveQualifications is the removal counterpart to BulkAssignQualifications. Qualifications the member doesn't
+// hold are skipped.
+func (b Backend) BulkRemoveQualifications(ctx context.Context, actorID string, req types.BulkQualificationRequest) ([]types.BulkItemResult, error) {
+	pairs, err := b.bulkPairs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Bulk removing qualifications", slog.Int("count", len(pairs)))
+	var errs []error
+	err = b.inTx(ctx, func(tx Backend) error {
+		if errs, err = tx.memberProvider.RemoveMemberQualifications(ctx, pairs); err != nil {
+			return err
+		}
+		return tx.recordBulkEvents(ctx, actorID, pairs, errs, types.QualificationRemoved)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bulkResults(pairs, errs, types.BulkItemRemoved, ErrMemberQualificationNotFound), nil
+}
+
+// recordBulkEvents adds the history for every pair that succeeded. Assignments can leave a member qualified straight
+// away, so their statuses are checked as well.
+func (b Backend) recordBulkEvents(ctx context.Context, actorID string, pairs []types.MemberQualificationPair, errs []error, kind types.QualificationEventKind) error {
+	now := b.clock.Now()
+	for i, pair := range pairs {
+		if errs[i] != nil {
+			continue
+		}
+		if err := b.recordQualificationEvent(ctx, actorID, pair.MemberID, pair.QualificationID, kind, now); err != nil {
+			return err
+		}
+		if kind != types.QualificationAssigned {
+			continue
+		}
+		if err := b.recordStatusChanges(ctx, pair.MemberID, pair.QualificationID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bulkResults(pairs []types.MemberQualificationPair, errs []error, success types.BulkItemStatus, skip error) []types.BulkItemResult {
+	results := make([]types.BulkItemResult, len(pairs))
+	for i, pair := range pairs {
+		results[i] = types.BulkItemResult{MemberQualificationPair: pair, Status: success}
+		if errs[i] == nil {
+			continue
+		}
+		results[i].Error = errs[i].Error()
+		if errors.Is(errs[i], skip) {
+			results[i].Status = types.BulkItemSkipped
+		} else {
+			results[i].Status = types.BulkItemFailed
+		}
+	}
+	return results
+}
+
+// bulkPairs expands a bulk request into member/qualification pairs, pulling in the supervisor's whole subtree.
+func (b Backend) bulkPairs(ctx context.Context, req types.BulkQualificationRequest) ([]types.MemberQualificationPair, error) {
+	var missing []string
+	if len(req.MemberIDs) == 0 && req.SupervisorID == "" {
+		missing = append(missing, "MemberIDs")
+	}
+	if len(req.QualificationIDs) == 0 {
+		missing = append(missing, "QualificationIDs")
+	}
+	if len(missing) > 0 {
+		return nil, MissingArgsError{Fields: missing}
+	}
+	memberIDs := dedupe(req.MemberIDs)
+	if req.SupervisorID != "" {
+		subtree, err := b.subordinateTree(ctx, req.SupervisorID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range subtree {
+			if !slices.Contains(memberIDs, id) {
+				memberIDs = append(memberIDs, id)
+			}
+		}
+	}
+	qualificationIDs := dedupe(req.QualificationIDs)
+	pairs := make([]types.MemberQualificationPair, 0, len(memberIDs)*len(qualificationIDs))
+	for _, memberID := range memberIDs {
+		for _, qualificationID := range qualificationIDs {
+			pairs = append(pairs, types.MemberQualificationPair{MemberID: memberID, QualificationID: qualificationID})
+		}
+	}
+	return pairs, nil
+}
+
+// subordinateTree returns the IDs of everyone below the supervisor in the chain, not including the supervisor.
+func (b Backend) subordinateTree(ctx context.Context, supervisorID string) ([]string, error) {
+	if _, err := b.memberProvider.GetMember(ctx, supervisorID, ById); errors.Is(err, ErrMemberNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrSupervisorNotFound, supervisorID)
+	} else if err != nil {
+		return nil, err
+	}
+	visited := map[string]bool{supervisorID: true}
+	var ids []string
+	queue := []string{supervisorID}
+	for len(queue) > 0 {
+		subordinates, err := b.memberProvider.GetSubordinates(ctx, queue[0])
+		if err != nil {
+			return nil, err
+		}
+		queue = queue[1:]
+		for _, s := range subordinates {
+			if visited[s.ID] {
+				continue
+			}
+			visited[s.ID] = true
+			ids = append(ids, s.ID)
+			queue = append(queue, s.ID)
+		}
+	}
+	return ids, nil
+}
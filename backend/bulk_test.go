@@ -0,0 +1,121 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestBulkQualifications(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+
+	supervisor, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestBulkQualifications: %s", err.Error())
+	}
+	// supervisor -> flightLead -> airman, plus an unrelated member
+	flightLeadMember := testutils.RandomMember(false)
+	flightLeadMember.SupervisorID = supervisor.ID
+	flightLead, err := b.AddMember(ctx, flightLeadMember)
+	if err != nil {
+		t.Fatalf("Error adding member for TestBulkQualifications: %s", err.Error())
+	}
+	airmanMember := testutils.RandomMember(false)
+	airmanMember.SupervisorID = flightLead.ID
+	airman, err := b.AddMember(ctx, airmanMember)
+	if err != nil {
+		t.Fatalf("Error adding member for TestBulkQualifications: %s", err.Error())
+	}
+	other, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestBulkQualifications: %s", err.Error())
+	}
+	qual1, err := b.AddQualification(ctx, testutils.RandomQualification())
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestBulkQualifications: %s", err.Error())
+	}
+	qual2, err := b.AddQualification(ctx, testutils.RandomQualification())
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestBulkQualifications: %s", err.Error())
+	}
+	if err = b.AssignMemberQualification(ctx, "", airman.ID, qual1.ID); err != nil {
+		t.Fatalf("Error assigning qualification for TestBulkQualifications: %s", err.Error())
+	}
+
+	missingMember := uuid.NewString()
+	results, err := b.BulkAssignQualifications(ctx, "", types.BulkQualificationRequest{
+		MemberIDs:        []string{other.ID, missingMember},
+		SupervisorID:     supervisor.ID,
+		QualificationIDs: []string{qual1.ID, qual2.ID},
+	})
+	if err != nil {
+		t.Fatalf("Error bulk assigning qualifications: %s", err.Error())
+	}
+	expected := map[types.MemberQualificationPair]types.BulkItemStatus{
+		{MemberID: other.ID, QualificationID: qual1.ID}:      types.BulkItemAssigned,
+		{MemberID: other.ID, QualificationID: qual2.ID}:      types.BulkItemAssigned,
+		{MemberID: missingMember, QualificationID: qual1.ID}: types.BulkItemFailed,
+		{MemberID: missingMember, QualificationID: qual2.ID}: types.BulkItemFailed,
+		{MemberID: flightLead.ID, QualificationID: qual1.ID}: types.BulkItemAssigned,
+		{MemberID: flightLead.ID, QualificationID: qual2.ID}: types.BulkItemAssigned,
+		{MemberID: airman.ID, QualificationID: qual1.ID}:     types.BulkItemSkipped,
+		{MemberID: airman.ID, QualificationID: qual2.ID}:     types.BulkItemAssigned,
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got: %+v", len(expected), results)
+	}
+	for _, res := range results {
+		if res.Status != expected[res.MemberQualificationPair] {
+			t.Errorf("Expected %+v to be %s, got: %s (%s)", res.MemberQualificationPair, expected[res.MemberQualificationPair], res.Status, res.Error)
+		}
+	}
+	if _, err = b.GetMemberQualification(ctx, supervisor.ID, qual1.ID); !errors.Is(err, backend.ErrMemberQualificationNotFound) {
+		t.Errorf("Expected supervisor to be left out of their own subtree, got: %v", err)
+	}
+	for _, id := range []string{other.ID, flightLead.ID, airman.ID} {
+		if quals, err := b.GetMemberQualifications(ctx, id); err != nil || len(quals) != 2 {
+			t.Errorf("Expected member %s to hold both qualifications, got: %+v, %v", id, quals, err)
+		}
+	}
+
+	results, err = b.BulkRemoveQualifications(ctx, "", types.BulkQualificationRequest{
+		MemberIDs:        []string{other.ID, supervisor.ID},
+		QualificationIDs: []string{qual2.ID},
+	})
+	if err != nil {
+		t.Fatalf("Error bulk removing qualifications: %s", err.Error())
+	}
+	if len(results) != 2 || results[0].Status != types.BulkItemRemoved || results[1].Status != types.BulkItemSkipped {
+		t.Errorf("Expected one removal and one skip, got: %+v", results)
+	}
+
+	if _, err = b.BulkAssignQualifications(ctx, "", types.BulkQualificationRequest{MemberIDs: []string{other.ID}}); !errors.Is(err, backend.ErrMissingArgs) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMissingArgs, err)
+	}
+	if _, err = b.BulkAssignQualifications(ctx, "", types.BulkQualificationRequest{SupervisorID: uuid.NewString(), QualificationIDs: []string{qual1.ID}}); !errors.Is(err, backend.ErrSupervisorNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrSupervisorNotFound, err)
+	}
+}
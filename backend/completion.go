@@ -0,0 +1,157 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+func (b Backend) AddCertifier(ctx context.Context, requirementID, memberID string) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Designating certifier for requirement",
+		slog.String("requirement_id", requirementID), slog.String("member_id", memberID))
+	return b.requirementProvider.AddCertifier(ctx, requirementID, memberID)
+}
+
+func (b Backend) GetCertifiers(ctx context.Context, requirementID string) ([]types.Member, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting certifiers for requirement", slog.String("requirement_id", requirementID))
+	if _, err := b.requirementProvider.GetRequirement(ctx, requirementID); err != nil {
+		return nil, err
+	}
+	ids, err := b.requirementProvider.GetCertifierIDs(ctx, requirementID)
+	if err != nil {
+		return nil, err
+	}
+	certifiers := make([]types.Member, 0, len(ids))
+	for _, id := range ids {
+		m, err := b.memberProvider.GetMember(ctx, id, ById)
+		if err != nil {
+			return nil, err
+		}
+		certifiers = append(certifiers, m)
+	}
+	return certifiers, nil
+}
+
+func (b Backend) RemoveCertifier(ctx context.Context, requirementID, memberID string) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Removing certifier from requirement",
+		slog.String("requirement_id", requirementID), slog.String("member_id", memberID))
+	return b.requirementProvider.RemoveCertifier(ctx, requirementID, memberID)
+}
+
+// SubmitCompletion records a pending completion of a requirement. When someone other than the trainee submits it they
+// are recorded as the trainer unless a trainer was named explicitly.
+func (b Backend) SubmitCompletion(ctx context.Context, submitterID string, c types.Completion) (types.Completion, error) {
+	l := b.logger.With(slog.String("member_id", c.MemberID), slog.String("requirement_id", c.RequirementID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Submitting completion", slog.String("submitted_by", submitterID))
+	if c.MemberID == "" || c.RequirementID == "" {
+		return types.Completion{}, MissingArgsError{Fields: []string{"MemberID", "RequirementID"}}
+	}
+	if _, err := b.memberProvider.GetMember(ctx, c.MemberID, ById); err != nil {
+		return types.Completion{}, err
+	}
+	if _, err := b.requirementProvider.GetRequirement(ctx, c.RequirementID); err != nil {
+		return types.Completion{}, err
+	}
+	if c.TrainerID == "" && submitterID != c.MemberID {
+		c.TrainerID = submitterID
+	}
+	if c.TrainerID != "" {
+		if _, err := b.memberProvider.GetMember(ctx, c.TrainerID, ById); err != nil {
+			l.LogAttrs(ctx, slog.LevelWarn, "Trainer for completion doesn't exist", slog.String("trainer_id", c.TrainerID))
+			return types.Completion{}, err
+		}
+	}
+	c.ID = uuid.NewString()
+	c.SubmittedBy = submitterID
+	c.Status = types.CompletionPending
+	c.CertifierID = ""
+	c.Submitted = b.clock.Now()
+	c.Reviewed = time.Time{}
+	if c.CompletedDate.IsZero() {
+		c.CompletedDate = c.Submitted
+	}
+	if err := b.requirementProvider.AddCompletion(ctx, c); err != nil {
+		return types.Completion{}, err
+	}
+	return c, nil
+}
+
+func (b Backend) GetCompletion(ctx context.Context, id string) (types.Completion, error) {
+	return b.requirementProvider.GetCompletion(ctx, id)
+}
+
+func (b Backend) GetMemberCompletions(ctx context.Context, memberID string) ([]types.Completion, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting completions for member", slog.String("member_id", memberID))
+	return b.requirementProvider.GetMemberCompletions(ctx, memberID)
+}
+
+// GetPendingCompletions returns the completions awaiting review on requirements the member is a certifier for.
+func (b Backend) GetPendingCompletions(ctx context.Context, certifierID string) ([]types.Completion, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting pending completions for certifier", slog.String("certifier_id", certifierID))
+	return b.requirementProvider.GetPendingCompletions(ctx, certifierID)
+}
+
+// ReviewCompletion approves or rejects a pending completion. Only members designated as a certifier for the
+// requirement may review it, never their own or one they trained, and rejections must explain why.
+func (b Backend) ReviewCompletion(ctx context.Context, certifierID, completionID string, approve bool, comments string) (types.Completion, error) {
+	l := b.logger.With(slog.String("completion_id", completionID), slog.String("certifier_id", certifierID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Reviewing completion", slog.Bool("approve", approve))
+	c, err := b.requirementProvider.GetCompletion(ctx, completionID)
+	if err != nil {
+		return types.Completion{}, err
+	}
+	if c.Status != types.CompletionPending {
+		l.LogAttrs(ctx, slog.LevelWarn, "Completion has already been reviewed", slog.String("status", string(c.Status)))
+		return types.Completion{}, fmt.Errorf("%w: completion_id=%s", ErrCompletionAlreadyReviewed, completionID)
+	}
+	if c.MemberID == certifierID {
+		l.LogAttrs(ctx, slog.LevelWarn, "Member attempted to certify their own completion")
+		return types.Completion{}, ErrSelfCertification
+	}
+	certifier, err := b.requirementProvider.IsCertifier(ctx, c.RequirementID, certifierID)
+	if err != nil {
+		return types.Completion{}, err
+	}
+	if !certifier {
+		l.LogAttrs(ctx, slog.LevelWarn, "Member is not a certifier for the requirement", slog.String("requirement_id", c.RequirementID))
+		return types.Completion{}, fmt.Errorf("%w: %w", ErrInsufficientPermissions, ErrCertifierNotFound)
+	}
+	if c.TrainerID == certifierID {
+		l.LogAttrs(ctx, slog.LevelWarn, "Trainer attempted to certify a completion they trained")
+		return types.Completion{}, fmt.Errorf("%w: certifier_id=%s trained completion_id=%s", ErrSelfCertification, certifierID, completionID)
+	}
+	if !approve && comments == "" {
+		return types.Completion{}, MissingArgsError{Fields: []string{"Comments"}}
+	}
+	c.CertifierID = certifierID
+	c.Reviewed = b.clock.Now()
+	if comments != "" {
+		c.Comments = comments
+	}
+	c.Status = types.CompletionRejected
+	if approve {
+		c.Status = types.CompletionApproved
+	}
+	err = b.inTx(ctx, func(tx Backend) error {
+		if approve {
+			// Catch any lapse before the approval hides it
+			if err := tx.recordStatusChanges(ctx, c.MemberID); err != nil {
+				return err
+			}
+		}
+		if err := tx.requirementProvider.ReviewCompletion(ctx, c); err != nil {
+			return err
+		}
+		if approve {
+			return tx.recordStatusChanges(ctx, c.MemberID)
+		}
+		return nil
+	})
+	if err != nil {
+		return types.Completion{}, err
+	}
+	return c, nil
+}
@@ -0,0 +1,155 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestCompletionSignOff(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, expireClock{})
+
+	trainee, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestCompletionSignOff: %s", err.Error())
+	}
+	trainer, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestCompletionSignOff: %s", err.Error())
+	}
+	certifier, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestCompletionSignOff: %s", err.Error())
+	}
+	ref, err := b.AddReference(ctx, testutils.RandomReference())
+	if err != nil {
+		t.Fatalf("Error adding reference for TestCompletionSignOff: %s", err.Error())
+	}
+	req, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref))
+	if err != nil {
+		t.Fatalf("Error adding requirement for TestCompletionSignOff: %s", err.Error())
+	}
+
+	if err = b.AddCertifier(ctx, req.ID, certifier.ID); err != nil {
+		t.Fatalf("Error designating certifier for TestCompletionSignOff: %s", err.Error())
+	}
+	if err = b.AddCertifier(ctx, req.ID, certifier.ID); !errors.Is(err, backend.ErrCertifierAlreadyDesignated) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrCertifierAlreadyDesignated, err)
+	}
+	if err = b.AddCertifier(ctx, req.ID, uuid.NewString()); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMemberNotFound, err)
+	}
+	if err = b.AddCertifier(ctx, req.ID, trainee.ID); err != nil {
+		t.Fatalf("Error designating certifier for TestCompletionSignOff: %s", err.Error())
+	}
+	certifiers, err := b.GetCertifiers(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("Error getting certifiers: %s", err.Error())
+	}
+	if len(certifiers) != 2 {
+		t.Errorf("Expected 2 certifiers, got %d", len(certifiers))
+	}
+
+	// Submitting on behalf of the trainee records the submitter as the trainer
+	submitted, err := b.SubmitCompletion(ctx, trainer.ID, types.Completion{MemberID: trainee.ID, RequirementID: req.ID})
+	if err != nil {
+		t.Fatalf("Error submitting completion: %s", err.Error())
+	}
+	if submitted.Status != types.CompletionPending || submitted.TrainerID != trainer.ID {
+		t.Errorf("Expected pending completion trained by %s, got: %+v", trainer.ID, submitted)
+	}
+	if _, err = b.SubmitCompletion(ctx, trainee.ID, types.Completion{MemberID: trainee.ID, RequirementID: uuid.NewString()}); !errors.Is(err, backend.ErrRequirementNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrRequirementNotFound, err)
+	}
+
+	pending, err := b.GetPendingCompletions(ctx, certifier.ID)
+	if err != nil {
+		t.Fatalf("Error getting pending completions: %s", err.Error())
+	}
+	if len(pending) != 1 || pending[0].ID != submitted.ID {
+		t.Errorf("Expected submitted completion to be pending for certifier, got: %+v", pending)
+	}
+
+	if _, err = b.ReviewCompletion(ctx, trainee.ID, submitted.ID, true, ""); !errors.Is(err, backend.ErrSelfCertification) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrSelfCertification, err)
+	}
+	if _, err = b.ReviewCompletion(ctx, trainer.ID, submitted.ID, true, ""); !errors.Is(err, backend.ErrInsufficientPermissions) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrInsufficientPermissions, err)
+	}
+	// Being a certifier doesn't let the trainer sign off their own training
+	if err = b.AddCertifier(ctx, req.ID, trainer.ID); err != nil {
+		t.Fatalf("Error designating certifier for TestCompletionSignOff: %s", err.Error())
+	}
+	if _, err = b.ReviewCompletion(ctx, trainer.ID, submitted.ID, true, ""); !errors.Is(err, backend.ErrSelfCertification) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrSelfCertification, err)
+	}
+	if _, err = b.ReviewCompletion(ctx, certifier.ID, submitted.ID, false, ""); !errors.Is(err, backend.ErrMissingArgs) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMissingArgs, err)
+	}
+	approved, err := b.ReviewCompletion(ctx, certifier.ID, submitted.ID, true, "Demonstrated proficiency")
+	if err != nil {
+		t.Fatalf("Error approving completion: %s", err.Error())
+	}
+	got, err := b.GetCompletion(ctx, approved.ID)
+	if err != nil {
+		t.Fatalf("Error getting completion: %s", err.Error())
+	}
+	if got.Status != types.CompletionApproved || got.MemberID != trainee.ID || got.TrainerID != trainer.ID || got.CertifierID != certifier.ID {
+		t.Errorf("Expected approved completion with trainee, trainer and certifier, got: %+v", got)
+	}
+	if !got.Reviewed.Equal(expireClock{}.Now()) || got.Comments != "Demonstrated proficiency" {
+		t.Errorf("Expected review time and comments to be recorded, got: %+v", got)
+	}
+	if _, err = b.ReviewCompletion(ctx, certifier.ID, submitted.ID, false, "Changed my mind"); !errors.Is(err, backend.ErrCompletionAlreadyReviewed) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrCompletionAlreadyReviewed, err)
+	}
+
+	// Rejections stay on the member's record
+	second, err := b.SubmitCompletion(ctx, trainee.ID, types.Completion{MemberID: trainee.ID, RequirementID: req.ID})
+	if err != nil {
+		t.Fatalf("Error submitting completion: %s", err.Error())
+	}
+	if second.TrainerID != "" {
+		t.Errorf("Expected self-submitted completion to have no trainer, got: %s", second.TrainerID)
+	}
+	if _, err = b.ReviewCompletion(ctx, certifier.ID, second.ID, false, "No trainer present"); err != nil {
+		t.Fatalf("Error rejecting completion: %s", err.Error())
+	}
+	completions, err := b.GetMemberCompletions(ctx, trainee.ID)
+	if err != nil {
+		t.Fatalf("Error getting member completions: %s", err.Error())
+	}
+	if len(completions) != 2 || completions[1].Status != types.CompletionRejected {
+		t.Errorf("Expected approved and rejected completions for member, got: %+v", completions)
+	}
+
+	if err = b.RemoveCertifier(ctx, req.ID, certifier.ID); err != nil {
+		t.Fatalf("Error removing certifier: %s", err.Error())
+	}
+	if err = b.RemoveCertifier(ctx, req.ID, certifier.ID); !errors.Is(err, backend.ErrCertifierNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrCertifierNotFound, err)
+	}
+}
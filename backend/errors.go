@@ -1,24 +1,84 @@
 package backend
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
+	ErrAPITokenNotFound             = errors.New("api token with that id not found")
 	ErrAuthenticationFailed         = errors.New("unable to authenticate user")
 	ErrBadUpdate                    = errors.New("supplied update values are invalid")
+	ErrCertifierAlreadyDesignated   = errors.New("member is already a certifier for that requirement")
+	ErrCertifierNotFound            = errors.New("member is not a certifier for that requirement")
+	ErrCompletionAlreadyReviewed    = errors.New("completion has already been reviewed")
+	ErrCompletionNotFound           = errors.New("completion with that id not found")
+	ErrDuplicateCertificate         = errors.New("certificate is already bound to a member")
+	ErrDuplicateDutyPosition        = errors.New("duty position with that name already exists")
+	ErrDuplicateImportProfile       = errors.New("import profile with that name already exists")
 	ErrDuplicateReference           = errors.New("reference with that name already exists")
 	ErrDuplicateRequirement         = errors.New("requirement with that name already exists")
+	ErrDuplicateTenant              = errors.New("tenant with that subdomain already exists")
 	ErrDuplicateUsername            = errors.New("member with that username already exists")
+	ErrDutyPositionAlreadyAssigned  = errors.New("duty position already assigned to member")
+	ErrDutyPositionNotFound         = errors.New("duty position with that id not found")
+	ErrImportBatchCommitted         = errors.New("import batch has already been committed")
+	ErrImportBatchNotFound          = errors.New("import batch with that ID not found")
+	ErrImportProfileNotFound        = errors.New("import profile with that ID not found")
+	ErrImportRowNotFound            = errors.New("staged row with that ID not found in import batch")
+	ErrInsufficientPermissions      = errors.New("member does not have permission to perform that action")
+	ErrInvalidImport                = errors.New("import contains invalid rows")
+	ErrInvalidListOptions           = errors.New("invalid sort, filter or cursor")
+	ErrInvalidPatch                 = errors.New("invalid json merge patch")
+	ErrInvalidPermission            = errors.New("invalid permission")
 	ErrInvalidQualExpiration        = errors.New("invalid expiration length for qualification")
+	ErrInvalidRole                  = errors.New("invalid role")
+	ErrInvalidTenant                = errors.New("tenant subdomain must be a lowercase DNS label")
+	ErrInvalidTokenScope            = errors.New("invalid api token scope")
+	ErrInvalidTransfer              = errors.New("invalid transfer package")
+	ErrInvalidTransferSignature     = errors.New("transfer package signature doesn't match")
+	ErrInvalidUnit                  = errors.New("unit doesn't fit under that parent")
+	ErrInvalidWaiver                = errors.New("invalid waiver")
+	ErrMemberArchived               = errors.New("member is archived")
+	ErrMemberDutyPositionNotFound   = errors.New("member doesn't hold that duty position")
+	ErrMemberNotArchived            = errors.New("member is not archived")
 	ErrMemberNotFound               = errors.New("member with that id not found")
 	ErrMemberQualificationNotFound  = errors.New("member with given qualification not found")
 	ErrMissingArgs                  = errors.New("missing required arguments")
 	ErrPasswordTooLong              = errors.New("password exceeds maximum length of 72 characters")
+	ErrPrerequisiteCycle            = errors.New("qualification prerequisites would form a cycle")
 	ErrQualificationAlreadyAssigned = errors.New("qualification already assigned to member")
 	ErrQualificationNotFound        = errors.New("qualification with that id not found")
 	ErrReferenceNotFound            = errors.New("unable to find reference with given id")
 	ErrRequirementInUse             = errors.New("requirement is assigned to qualification")
 	ErrRequirementNotFound          = errors.New("requirement with that identifier not found")
+	ErrRetentionPeriod              = errors.New("member's records are still within the retention period")
+	ErrSelfCertification            = errors.New("members can't certify their own completions")
 	ErrSessionValidationFailed      = errors.New("failed to validate session for member")
 	ErrSupervisorNotFound           = errors.New("supervisor with that ID not found")
+	ErrTenantNotFound               = errors.New("tenant not found")
+	ErrTenantsNotEnabled            = errors.New("multi-tenant mode isn't enabled")
+	ErrTransferAlreadyImported      = errors.New("transfer package was already imported")
+	ErrTransferKeyNotConfigured     = errors.New("no transfer key configured for that instance")
+	ErrUnitAdminAlreadyDesignated   = errors.New("member is already an admin of that unit")
+	ErrUnitAdminNotFound            = errors.New("member is not an admin of that unit")
+	ErrUnitHasSubunits              = errors.New("unit still has subunits")
+	ErrUnitNotFound                 = errors.New("unit with that id not found")
+	ErrVersionConflict              = errors.New("record was changed since that version was read")
+	ErrWaiverMemoNotFound           = errors.New("waiver has no memo attached")
+	ErrWaiverNotFound               = errors.New("waiver with that id not found")
 	ErrWeakPassword                 = errors.New("supplied password doesn't meet requirements")
 )
+
+// MissingArgsError is ErrMissingArgs along with the fields that were missing, so they can be pointed out to the client.
+type MissingArgsError struct {
+	Fields []string
+}
+
+func (e MissingArgsError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrMissingArgs, e.Fields)
+}
+
+func (e MissingArgsError) Unwrap() error {
+	return ErrMissingArgs
+}
@@ -0,0 +1,325 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+const (
+	AuditEntityImportBatch = "import_batch"
+
+	defaultImportDateFormat = "2006-01-02"
+)
+
+func (b Backend) AddImportProfile(ctx context.Context, profile types.ImportProfile) (types.ImportProfile, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Adding import profile", slog.String("name", profile.Name))
+	var missing []string
+	if profile.Name == "" {
+		missing = append(missing, "Name")
+	}
+	if profile.MemberColumn == "" {
+		missing = append(missing, "MemberColumn")
+	}
+	if profile.RequirementColumn == "" {
+		missing = append(missing, "RequirementColumn")
+	}
+	if profile.DateColumn == "" {
+		missing = append(missing, "DateColumn")
+	}
+	if len(missing) > 0 {
+		return types.ImportProfile{}, MissingArgsError{Fields: missing}
+	}
+	if profile.DateFormat == "" {
+		profile.DateFormat = defaultImportDateFormat
+	}
+	profile.ID = uuid.NewString()
+	if err := b.requirementProvider.AddImportProfile(ctx, profile); err != nil {
+		return types.ImportProfile{}, err
+	}
+	return profile, nil
+}
+
+func (b Backend) GetImportProfile(ctx context.Context, id string) (types.ImportProfile, error) {
+	return b.requirementProvider.GetImportProfile(ctx, id)
+}
+
+func (b Backend) GetImportProfiles(ctx context.Context) ([]types.ImportProfile, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting all import profiles")
+	return b.requirementProvider.GetImportProfiles(ctx)
+}
+
+func (b Backend) DeleteImportProfile(ctx context.Context, id string) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting import profile", slog.String("profile_id", id))
+	return b.requirementProvider.DeleteImportProfile(ctx, id)
+}
+
+// StageCompletionImport reads a CSV or XLSX export of training history using the profile's column mapping and stages
+// every row for review. Members are looked up by ID or username, and requirement names are fuzzy matched against the
+// existing requirements. Problems are recorded on the rows rather than failing the upload.
+func (b Backend) StageCompletionImport(ctx context.Context, uploaderID, profileID, fileName string, r io.Reader) (types.ImportBatch, error) {
+	l := b.logger.With(slog.String("profile_id", profileID), slog.String("file_name", fileName))
+	l.LogAttrs(ctx, slog.LevelInfo, "Staging completion import")
+	profile, err := b.requirementProvider.GetImportProfile(ctx, profileID)
+	if err != nil {
+		return types.ImportBatch{}, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return types.ImportBatch{}, err
+	}
+	records, err := readSpreadsheet(data)
+	if err != nil {
+		l.LogAttrs(ctx, slog.LevelWarn, "Unable to read spreadsheet", slog.String("error", err.Error()))
+		return types.ImportBatch{}, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+	}
+	if len(records) == 0 {
+		return types.ImportBatch{}, fmt.Errorf("%w: spreadsheet is empty", ErrInvalidImport)
+	}
+	columns := map[string]int{}
+	for i, header := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+	indexes := make([]int, 3)
+	for i, name := range []string{profile.MemberColumn, profile.RequirementColumn, profile.DateColumn} {
+		idx, ok := columns[strings.ToLower(name)]
+		if !ok {
+			return types.ImportBatch{}, fmt.Errorf("%w: missing column %s", ErrInvalidImport, name)
+		}
+		indexes[i] = idx
+	}
+	requirements, err := b.requirementProvider.GetAllRequirements(ctx)
+	if err != nil {
+		return types.ImportBatch{}, err
+	}
+	names := make([]string, len(requirements))
+	for i, req := range requirements {
+		names[i] = req.Name
+	}
+
+	batch := types.ImportBatch{
+		ID:         uuid.NewString(),
+		ProfileID:  profile.ID,
+		FileName:   fileName,
+		UploadedBy: uploaderID,
+		Uploaded:   b.clock.Now(),
+		Status:     types.ImportBatchStaged,
+		Rows:       []types.StagedRow{},
+	}
+	// Members tend to appear on many rows, so only look each one up once
+	members := map[string]string{}
+	for i, record := range records[1:] {
+		field := func(col int) string {
+			if col < len(record) {
+				return strings.TrimSpace(record[col])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+		row := types.StagedRow{
+			ID:               uuid.NewString(),
+			Line:             i + 2,
+			MemberIdentifier: field(indexes[0]),
+			RequirementName:  field(indexes[1]),
+		}
+		if id, ok := members[row.MemberIdentifier]; ok {
+			row.MemberID = id
+		} else if m, err := b.GetMember(ctx, row.MemberIdentifier); err == nil {
+			row.MemberID = m.ID
+			members[row.MemberIdentifier] = m.ID
+		} else if !errors.Is(err, ErrMemberNotFound) {
+			return types.ImportBatch{}, err
+		}
+		if idx, score := matchName(row.RequirementName, names); idx >= 0 {
+			row.RequirementID, row.MatchScore = requirements[idx].ID, score
+		}
+		dateProblem := ""
+		if raw := field(indexes[2]); raw != "" {
+			if row.CompletedDate, err = parseSpreadsheetDate(raw, profile.DateFormat); err != nil {
+				dateProblem = fmt.Sprintf("invalid date: %s", raw)
+			}
+		}
+		row.Errors = stagedRowErrors(row, dateProblem)
+		batch.Rows = append(batch.Rows, row)
+	}
+	if err = b.requirementProvider.AddImportBatch(ctx, batch); err != nil {
+		return types.ImportBatch{}, err
+	}
+	l.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Staged %d rows for review", len(batch.Rows)), slog.String("batch_id", batch.ID))
+	return batch, nil
+}
+
+// stagedRowErrors reports what's keeping a row from being committed. dateProblem explains a missing date when there's
+// something more specific to say than it being missing.
+func stagedRowErrors(row types.StagedRow, dateProblem string) []string {
+	var errs []string
+	if row.MemberID == "" {
+		errs = append(errs, fmt.Sprintf("%s: %s", ErrMemberNotFound, row.MemberIdentifier))
+	}
+	if row.RequirementID == "" || row.MatchScore < RequirementMatchThreshold {
+		errs = append(errs, fmt.Sprintf("no requirement closely matches: %s", row.RequirementName))
+	}
+	if row.CompletedDate.IsZero() && dateProblem != "" {
+		errs = append(errs, dateProblem)
+	} else if row.CompletedDate.IsZero() {
+		errs = append(errs, "missing completion date")
+	}
+	return errs
+}
+
+func (b Backend) GetImportBatch(ctx context.Context, id string) (types.ImportBatch, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting import batch", slog.String("batch_id", id))
+	return b.requirementProvider.GetImportBatch(ctx, id)
+}
+
+// UpdateStagedRow applies a reviewer's correction to a staged row. Choosing a requirement by hand counts as an exact
+// match.
+func (b Backend) UpdateStagedRow(ctx context.Context, batchID, rowID string, update types.StagedRowUpdate) (types.StagedRow, error) {
+	l := b.logger.With(slog.String("batch_id", batchID), slog.String("row_id", rowID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Updating staged row")
+	batch, err := b.requirementProvider.GetImportBatch(ctx, batchID)
+	if err != nil {
+		return types.StagedRow{}, err
+	}
+	if batch.Status != types.ImportBatchStaged {
+		return types.StagedRow{}, fmt.Errorf("%w: batch_id=%s", ErrImportBatchCommitted, batchID)
+	}
+	var row types.StagedRow
+	found := false
+	for _, r := range batch.Rows {
+		if r.ID == rowID {
+			row, found = r, true
+		}
+	}
+	if !found {
+		return types.StagedRow{}, fmt.Errorf("%w: batch_id=%s row_id=%s", ErrImportRowNotFound, batchID, rowID)
+	}
+	if update.MemberID != "" {
+		if _, err = b.memberProvider.GetMember(ctx, update.MemberID, ById); err != nil {
+			return types.StagedRow{}, err
+		}
+		row.MemberID = update.MemberID
+	}
+	if update.RequirementID != "" {
+		if _, err = b.requirementProvider.GetRequirement(ctx, update.RequirementID); err != nil {
+			return types.StagedRow{}, err
+		}
+		row.RequirementID, row.MatchScore = update.RequirementID, 1
+	}
+	if !update.CompletedDate.IsZero() {
+		row.CompletedDate = update.CompletedDate
+	}
+	if update.Excluded != nil {
+		row.Excluded = *update.Excluded
+	}
+	row.Errors = stagedRowErrors(row, "")
+	if err = b.requirementProvider.UpdateImportRow(ctx, batchID, row); err != nil {
+		return types.StagedRow{}, err
+	}
+	return row, nil
+}
+
+// CommitImportBatch writes every row that isn't excluded as an approved completion, with the committing admin recorded
+// as the certifier. Every row has to be valid or excluded first.
+func (b Backend) CommitImportBatch(ctx context.Context, actorID, batchID string) (types.ImportBatch, error) {
+	l := b.logger.With(slog.String("batch_id", batchID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Committing import batch", slog.String("actor_id", actorID))
+	batch, err := b.requirementProvider.GetImportBatch(ctx, batchID)
+	if err != nil {
+		return types.ImportBatch{}, err
+	}
+	if batch.Status != types.ImportBatchStaged {
+		return types.ImportBatch{}, fmt.Errorf("%w: batch_id=%s", ErrImportBatchCommitted, batchID)
+	}
+	now := b.clock.Now()
+	var completions []types.Completion
+	for _, row := range batch.Rows {
+		if row.Excluded {
+			continue
+		}
+		if len(row.Errors) > 0 {
+			l.LogAttrs(ctx, slog.LevelInfo, "Import batch still has invalid rows", slog.Int("line", row.Line))
+			return types.ImportBatch{}, fmt.Errorf("%w: line %d: %s", ErrInvalidImport, row.Line, strings.Join(row.Errors, ", "))
+		}
+		completions = append(completions, types.Completion{
+			ID:            uuid.NewString(),
+			MemberID:      row.MemberID,
+			RequirementID: row.RequirementID,
+			CertifierID:   actorID,
+			SubmittedBy:   actorID,
+			Status:        types.CompletionApproved,
+			CompletedDate: row.CompletedDate,
+			Submitted:     now,
+			Reviewed:      now,
+			Comments:      fmt.Sprintf("Imported from %s line %d", batch.FileName, row.Line),
+		})
+	}
+	// Only the qualifications the imported requirements count towards can change status
+	dependents, err := b.prerequisiteDependents(ctx)
+	if err != nil {
+		return types.ImportBatch{}, err
+	}
+	requirementQualifications := map[string][]string{}
+	// Members are only in affected with at least one qualification, no IDs would re-evaluate all of them
+	affected := map[string][]string{}
+	for _, c := range completions {
+		ids, ok := requirementQualifications[c.RequirementID]
+		if !ok {
+			if ids, err = b.requirementQualificationIDs(ctx, c.RequirementID, dependents); err != nil {
+				return types.ImportBatch{}, err
+			}
+			requirementQualifications[c.RequirementID] = ids
+		}
+		for _, id := range ids {
+			if !slices.Contains(affected[c.MemberID], id) {
+				affected[c.MemberID] = append(affected[c.MemberID], id)
+			}
+		}
+	}
+	err = b.inTx(ctx, func(tx Backend) error {
+		// Lapses have to be caught before the new completions hide them
+		for memberID, ids := range affected {
+			if err := tx.recordStatusChanges(ctx, memberID, ids...); err != nil {
+				return err
+			}
+		}
+		if err := tx.requirementProvider.CommitImportBatch(ctx, batchID, completions); err != nil {
+			return err
+		}
+		if err := tx.audit(ctx, actorID, "commit", AuditEntityImportBatch, batchID, fmt.Sprintf("Imported %d completions from %s", len(completions), batch.FileName)); err != nil {
+			return err
+		}
+		for memberID, ids := range affected {
+			if err := tx.recordStatusChanges(ctx, memberID, ids...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return types.ImportBatch{}, err
+	}
+	batch.Status = types.ImportBatchCommitted
+	return batch, nil
+}
+
+// DiscardImportBatch throws away a staged batch. Committed batches are kept as a record of where completions came from.
+func (b Backend) DiscardImportBatch(ctx context.Context, id string) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Discarding import batch", slog.String("batch_id", id))
+	batch, err := b.requirementProvider.GetImportBatch(ctx, id)
+	if err != nil {
+		return err
+	}
+	if batch.Status != types.ImportBatchStaged {
+		return fmt.Errorf("%w: batch_id=%s", ErrImportBatchCommitted, id)
+	}
+	return b.requirementProvider.DeleteImportBatch(ctx, id)
+}
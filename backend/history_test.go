@@ -0,0 +1,320 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompletionHistoryImport(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+
+	admin, err := b.AddMember(ctx, testutils.RandomMember(true))
+	if err != nil {
+		t.Fatalf("Error adding member for TestCompletionHistoryImport: %s", err.Error())
+	}
+	member, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestCompletionHistoryImport: %s", err.Error())
+	}
+	ref, err := b.AddReference(ctx, testutils.RandomReference())
+	if err != nil {
+		t.Fatalf("Error adding reference for TestCompletionHistoryImport: %s", err.Error())
+	}
+	forkliftReq := testutils.RandomRequirement(ref)
+	forkliftReq.Name = "Forklift Operator Training"
+	forklift, err := b.AddRequirement(ctx, forkliftReq)
+	if err != nil {
+		t.Fatalf("Error adding requirement for TestCompletionHistoryImport: %s", err.Error())
+	}
+	hazmatReq := testutils.RandomRequirement(ref)
+	hazmatReq.Name = "Hazmat Awareness"
+	hazmat, err := b.AddRequirement(ctx, hazmatReq)
+	if err != nil {
+		t.Fatalf("Error adding requirement for TestCompletionHistoryImport: %s", err.Error())
+	}
+
+	if _, err = b.AddImportProfile(ctx, types.ImportProfile{Name: "Legacy"}); !errors.Is(err, backend.ErrMissingArgs) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMissingArgs, err)
+	}
+	profile, err := b.AddImportProfile(ctx, types.ImportProfile{Name: "Legacy", MemberColumn: "Username", RequirementColumn: "Course", DateColumn: "Completed", DateFormat: "01/02/2006"})
+	if err != nil {
+		t.Fatalf("Error adding import profile: %s", err.Error())
+	}
+	if _, err = b.AddImportProfile(ctx, profile); !errors.Is(err, backend.ErrDuplicateImportProfile) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrDuplicateImportProfile, err)
+	}
+
+	csv := fmt.Sprintf(`Username,Course,Completed
+%s,FORKLIFT operator trng,03/15/2021
+%s,Hazmat Awarenes,not a date
+nobody,Hazmat Awareness,03/15/2021
+%s,Underwater Basket Weaving,03/15/2021
+`, member.Username, member.Username, member.Username)
+	batch, err := b.StageCompletionImport(ctx, admin.ID, profile.ID, "history.csv", strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("Error staging completion import: %s", err.Error())
+	}
+	if len(batch.Rows) != 4 {
+		t.Fatalf("Expected 4 staged rows, got: %+v", batch.Rows)
+	}
+	forkliftRow, hazmatRow, unknownMemberRow, unknownReqRow := batch.Rows[0], batch.Rows[1], batch.Rows[2], batch.Rows[3]
+	if forkliftRow.RequirementID != forklift.ID || forkliftRow.MemberID != member.ID || len(forkliftRow.Errors) != 0 {
+		t.Errorf("Expected fuzzy matched valid row, got: %+v", forkliftRow)
+	}
+	if hazmatRow.RequirementID != hazmat.ID || len(hazmatRow.Errors) != 1 {
+		t.Errorf("Expected only an invalid date, got: %+v", hazmatRow)
+	}
+	if unknownMemberRow.MemberID != "" || len(unknownMemberRow.Errors) != 1 {
+		t.Errorf("Expected an unknown member, got: %+v", unknownMemberRow)
+	}
+	if len(unknownReqRow.Errors) != 1 || unknownReqRow.MatchScore >= backend.RequirementMatchThreshold {
+		t.Errorf("Expected no close requirement match, got: %+v", unknownReqRow)
+	}
+
+	// Nothing is written until every row is fixed or excluded
+	if _, err = b.CommitImportBatch(ctx, admin.ID, batch.ID); !errors.Is(err, backend.ErrInvalidImport) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrInvalidImport, err)
+	}
+	excluded := true
+	if _, err = b.UpdateStagedRow(ctx, batch.ID, unknownMemberRow.ID, types.StagedRowUpdate{Excluded: &excluded}); err != nil {
+		t.Fatalf("Error excluding staged row: %s", err.Error())
+	}
+	if _, err = b.UpdateStagedRow(ctx, batch.ID, unknownReqRow.ID, types.StagedRowUpdate{Excluded: &excluded}); err != nil {
+		t.Fatalf("Error excluding staged row: %s", err.Error())
+	}
+	if _, err = b.UpdateStagedRow(ctx, batch.ID, hazmatRow.ID, types.StagedRowUpdate{RequirementID: uuid.NewString()}); !errors.Is(err, backend.ErrRequirementNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrRequirementNotFound, err)
+	}
+	hazmatDate := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+	fixed, err := b.UpdateStagedRow(ctx, batch.ID, hazmatRow.ID, types.StagedRowUpdate{CompletedDate: hazmatDate})
+	if err != nil || len(fixed.Errors) != 0 {
+		t.Fatalf("Expected corrected row to be valid, got: %+v, %v", fixed, err)
+	}
+	if _, err = b.UpdateStagedRow(ctx, batch.ID, uuid.NewString(), types.StagedRowUpdate{}); !errors.Is(err, backend.ErrImportRowNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrImportRowNotFound, err)
+	}
+
+	if _, err = b.CommitImportBatch(ctx, admin.ID, batch.ID); err != nil {
+		t.Fatalf("Error committing import batch: %s", err.Error())
+	}
+	if _, err = b.CommitImportBatch(ctx, admin.ID, batch.ID); !errors.Is(err, backend.ErrImportBatchCommitted) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrImportBatchCommitted, err)
+	}
+	if err = b.DiscardImportBatch(ctx, batch.ID); !errors.Is(err, backend.ErrImportBatchCommitted) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrImportBatchCommitted, err)
+	}
+	completions, err := b.GetMemberCompletions(ctx, member.ID)
+	if err != nil {
+		t.Fatalf("Error getting member completions: %s", err.Error())
+	}
+	dates := map[string]time.Time{}
+	for _, c := range completions {
+		if c.Status != types.CompletionApproved || c.CertifierID != admin.ID {
+			t.Errorf("Expected imported completion to be approved by the importer, got: %+v", c)
+		}
+		dates[c.RequirementID] = c.CompletedDate
+	}
+	if len(completions) != 2 || !dates[forklift.ID].Equal(time.Date(2021, time.March, 15, 0, 0, 0, 0, time.UTC)) || !dates[hazmat.ID].Equal(hazmatDate) {
+		t.Errorf("Expected forklift and hazmat completions, got: %+v", completions)
+	}
+	reqs, err := provider.GetMemberRequirements(ctx, member.ID)
+	if err != nil || len(reqs) != 2 {
+		t.Errorf("Expected completions to count toward requirements, got: %+v, %v", reqs, err)
+	}
+}
+
+func TestCompletionHistoryImportXLSX(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+
+	member, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestCompletionHistoryImportXLSX: %s", err.Error())
+	}
+	ref, err := b.AddReference(ctx, testutils.RandomReference())
+	if err != nil {
+		t.Fatalf("Error adding reference for TestCompletionHistoryImportXLSX: %s", err.Error())
+	}
+	req, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref))
+	if err != nil {
+		t.Fatalf("Error adding requirement for TestCompletionHistoryImportXLSX: %s", err.Error())
+	}
+	profile, err := b.AddImportProfile(ctx, types.ImportProfile{Name: "Excel", MemberColumn: "Member ID", RequirementColumn: "Requirement", DateColumn: "Date"})
+	if err != nil {
+		t.Fatalf("Error adding import profile: %s", err.Error())
+	}
+
+	// Excel stores dates as days since 1899-12-30
+	completed := time.Date(2021, time.March, 15, 0, 0, 0, 0, time.UTC)
+	serial := int(completed.Sub(time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+	xlsx := testutils.XLSX(t, [][]string{
+		{"Requirement", "Member ID", "Date"},
+		{req.Name, member.ID, strconv.Itoa(serial)},
+		{req.Name, member.ID, "2019-07-04"},
+	})
+	batch, err := b.StageCompletionImport(ctx, member.ID, profile.ID, "history.xlsx", bytes.NewReader(xlsx))
+	if err != nil {
+		t.Fatalf("Error staging completion import: %s", err.Error())
+	}
+	if len(batch.Rows) != 2 {
+		t.Fatalf("Expected 2 staged rows, got: %+v", batch.Rows)
+	}
+	for _, row := range batch.Rows {
+		if len(row.Errors) != 0 || row.MatchScore != 1 || row.MemberID != member.ID {
+			t.Errorf("Expected valid exact match, got: %+v", row)
+		}
+	}
+	if !batch.Rows[0].CompletedDate.Equal(completed) {
+		t.Errorf("Expected completion date %s, got: %s", completed, batch.Rows[0].CompletedDate)
+	}
+
+	if _, err = b.StageCompletionImport(ctx, member.ID, profile.ID, "other.csv", strings.NewReader("Name,When\nx,y\n")); !errors.Is(err, backend.ErrInvalidImport) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrInvalidImport, err)
+	}
+	if err = b.DiscardImportBatch(ctx, batch.ID); err != nil {
+		t.Fatalf("Error discarding import batch: %s", err.Error())
+	}
+	if _, err = b.GetImportBatch(ctx, batch.ID); !errors.Is(err, backend.ErrImportBatchNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrImportBatchNotFound, err)
+	}
+}
+
+func TestCommitImportBatchStatusChanges(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, movingClock{now: &now})
+
+	admin, err := b.AddMember(ctx, testutils.RandomMember(true))
+	if err != nil {
+		t.Fatalf("Error adding member for TestCommitImportBatchStatusChanges: %s", err.Error())
+	}
+	member, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestCommitImportBatchStatusChanges: %s", err.Error())
+	}
+	ref, err := b.AddReference(ctx, testutils.RandomReference())
+	if err != nil {
+		t.Fatalf("Error adding reference for TestCommitImportBatchStatusChanges: %s", err.Error())
+	}
+	addRequirement := func(name string) types.Requirement {
+		r := testutils.RandomRequirement(ref)
+		r.Name = name
+		r.DaysValidFor = 1000
+		r, err := b.AddRequirement(ctx, r)
+		if err != nil {
+			t.Fatalf("Error adding requirement for TestCommitImportBatchStatusChanges: %s", err.Error())
+		}
+		return r
+	}
+	forklift := addRequirement("Forklift Operator Training")
+	hazmat := addRequirement("Hazmat Awareness")
+	if err = b.AddCertifier(ctx, hazmat.ID, admin.ID); err != nil {
+		t.Fatalf("Error designating certifier for TestCommitImportBatchStatusChanges: %s", err.Error())
+	}
+	addQualification := func(q types.Qualification) types.Qualification {
+		q, err := b.AddQualification(ctx, q)
+		if err != nil {
+			t.Fatalf("Error adding qualification for TestCommitImportBatchStatusChanges: %s", err.Error())
+		}
+		if err = b.AssignMemberQualification(ctx, admin.ID, member.ID, q.ID); err != nil {
+			t.Fatalf("Error assigning qualification for TestCommitImportBatchStatusChanges: %s", err.Error())
+		}
+		return q
+	}
+	operator := addQualification(types.Qualification{Name: "Forklift Operator", InitialRequirements: []types.Requirement{forklift}})
+	supervisor := addQualification(types.Qualification{Name: "Warehouse Supervisor", Prerequisites: []string{operator.ID}})
+	handler := addQualification(types.Qualification{Name: "Hazmat Handler", Expires: true, ExpirationDays: 365, InitialRequirements: []types.Requirement{hazmat}})
+	c, err := b.SubmitCompletion(ctx, member.ID, types.Completion{MemberID: member.ID, RequirementID: hazmat.ID, CompletedDate: time.Date(1999, 12, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("Error submitting completion for TestCommitImportBatchStatusChanges: %s", err.Error())
+	}
+	if _, err = b.ReviewCompletion(ctx, admin.ID, c.ID, true, ""); err != nil {
+		t.Fatalf("Error approving completion for TestCommitImportBatchStatusChanges: %s", err.Error())
+	}
+	// The hazmat qualification lapses without anything being written for it
+	now = time.Date(2001, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	profile, err := b.AddImportProfile(ctx, types.ImportProfile{Name: "Legacy", MemberColumn: "Username", RequirementColumn: "Course", DateColumn: "Completed", DateFormat: "01/02/2006"})
+	if err != nil {
+		t.Fatalf("Error adding import profile: %s", err.Error())
+	}
+	csv := fmt.Sprintf("Username,Course,Completed\n%s,Forklift Operator Training,05/01/2001\n", member.Username)
+	batch, err := b.StageCompletionImport(ctx, admin.ID, profile.ID, "history.csv", strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("Error staging completion import: %s", err.Error())
+	}
+	if _, err = b.CommitImportBatch(ctx, admin.ID, batch.ID); err != nil {
+		t.Fatalf("Error committing import batch: %s", err.Error())
+	}
+
+	// Only what the imported requirement counts towards, including through prerequisites, is re-evaluated
+	recorded := func(qualificationID string) []types.QualificationEventKind {
+		history, err := provider.GetQualificationHistory(ctx, member.ID, qualificationID)
+		if err != nil {
+			t.Fatalf("Error getting qualification history: %s", err.Error())
+		}
+		kinds := []types.QualificationEventKind{}
+		for _, e := range history {
+			kinds = append(kinds, e.Kind)
+		}
+		return kinds
+	}
+	for _, q := range []types.Qualification{operator, supervisor} {
+		if kinds := recorded(q.ID); len(kinds) == 0 || kinds[len(kinds)-1] != types.QualificationQualified {
+			t.Errorf("Expected %s to be recorded as qualified, got %v", q.Name, kinds)
+		}
+	}
+	if kinds := recorded(handler.ID); slices.Contains(kinds, types.QualificationLapsed) {
+		t.Errorf("Expected the unrelated lapse to be left unrecorded, got %v", kinds)
+	}
+}
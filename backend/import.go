@@ -0,0 +1,187 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"net/mail"
+	"strconv"
+	"strings"
+)
+
+// MemberImportColumns is the expected column order of a roster CSV. Email and admin may be left off.
+var MemberImportColumns = []string{"rank", "first", "last", "username", "supervisor username", "email", "admin"}
+
+type importedMember struct {
+	member             types.Member
+	supervisorUsername string
+}
+
+// ImportMembers validates a roster CSV and, unless dryRun is set, inserts every member in one transaction with a
+// generated temporary password. Supervisors are resolved by username against the file and then the existing members.
+// Nothing is inserted if any row is invalid; ErrInvalidImport is returned along with the row-level report.
+func (b Backend) ImportMembers(ctx context.Context, r io.Reader, dryRun bool) (types.MemberImportReport, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Importing members from CSV", slog.Bool("dry_run", dryRun))
+	report := types.MemberImportReport{DryRun: dryRun, Rows: []types.MemberImportRow{}}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		b.logger.LogAttrs(ctx, slog.LevelWarn, "Error parsing member CSV", slog.String("error", err.Error()))
+		return report, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+	}
+	firstLine := 1
+	if len(records) > 0 && len(records[0]) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), MemberImportColumns[0]) {
+		records = records[1:]
+		firstLine = 2
+	}
+
+	imported := make([]importedMember, len(records))
+	lines := map[string]int{}
+	for i, record := range records {
+		row := types.MemberImportRow{Line: firstLine + i}
+		imported[i], row.Errors = parseImportRecord(record)
+		m := imported[i].member
+		row.Username = m.Username
+		if line, ok := lines[strings.ToLower(m.Username)]; ok && m.Username != "" {
+			row.Errors = append(row.Errors, fmt.Sprintf("username %s is also used on line %d", m.Username, line))
+		} else if m.Username != "" {
+			lines[strings.ToLower(m.Username)] = row.Line
+			if _, err = b.memberProvider.GetMember(ctx, m.Username, ByUsername); err == nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("%s: %s", ErrDuplicateUsername, m.Username))
+			} else if !errors.Is(err, ErrMemberNotFound) {
+				return report, err
+			}
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	// Supervisors can only be resolved once every username in the file is known
+	for i := range imported {
+		supervisor := imported[i].supervisorUsername
+		if supervisor == "" {
+			continue
+		}
+		if strings.EqualFold(supervisor, imported[i].member.Username) {
+			report.Rows[i].Errors = append(report.Rows[i].Errors, "member can't supervise themselves")
+		} else if line, ok := lines[strings.ToLower(supervisor)]; ok {
+			imported[i].member.SupervisorID = imported[line-firstLine].member.ID
+		} else if existing, err := b.memberProvider.GetMember(ctx, supervisor, ByUsername); err == nil {
+			imported[i].member.SupervisorID = existing.ID
+		} else if errors.Is(err, ErrMemberNotFound) {
+			report.Rows[i].Errors = append(report.Rows[i].Errors, fmt.Sprintf("%s: %s", ErrSupervisorNotFound, supervisor))
+		} else {
+			return report, err
+		}
+	}
+
+	if !report.Valid() {
+		b.logger.LogAttrs(ctx, slog.LevelInfo, "Member CSV contains invalid rows")
+		if dryRun {
+			return report, nil
+		}
+		return report, ErrInvalidImport
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	members := make([]types.Member, len(imported))
+	for i, im := range imported {
+		password, err := temporaryPassword()
+		if err != nil {
+			return report, err
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), b.config.BcryptCost)
+		if err != nil {
+			b.logger.LogAttrs(ctx, slog.LevelWarn, "Error hashing temporary password", slog.String("error", err.Error()))
+			return report, err
+		}
+		members[i] = im.member
+		members[i].Hash = string(hash)
+		members[i].Password = ""
+		report.Rows[i].MemberID = im.member.ID
+		report.Rows[i].TemporaryPassword = password
+	}
+	if err = b.memberProvider.AddMembers(ctx, members); err != nil {
+		for i := range report.Rows {
+			report.Rows[i].MemberID, report.Rows[i].TemporaryPassword = "", ""
+		}
+		return report, err
+	}
+	report.Committed = true
+	b.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Imported %d members", len(members)))
+	return report, nil
+}
+
+// parseImportRecord converts a CSV record into a member, returning every problem found with it.
+func parseImportRecord(record []string) (importedMember, []string) {
+	field := func(i int) string {
+		if i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	var problems []string
+	if len(record) > len(MemberImportColumns) {
+		problems = append(problems, fmt.Sprintf("expected at most %d columns, got %d", len(MemberImportColumns), len(record)))
+	}
+	m := types.Member{
+		ApiMember: types.ApiMember{
+			ID:        uuid.NewString(),
+			FirstName: field(1),
+			LastName:  field(2),
+			Username:  field(3),
+			Email:     field(5),
+			Role:      types.RoleMember,
+		},
+		// CheckMemberForMissingArgs requires a password, the real one is generated when the import is committed
+		Password: "temporary",
+	}
+	if raw := field(0); raw != "" {
+		rank, ok := types.ParseRank(raw)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("invalid rank: %s", raw))
+		}
+		m.Rank = rank
+	}
+	if m.Email != "" {
+		if _, err := mail.ParseAddress(m.Email); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid email: %s", m.Email))
+		}
+	}
+	if raw := field(6); raw != "" {
+		admin, err := strconv.ParseBool(raw)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid admin value: %s", raw))
+		}
+		m.Admin = admin
+		if admin {
+			m.Role = types.RoleAdmin
+		}
+	}
+	// An unparseable rank has already been reported, so only check that one was given
+	check := m
+	check.Rank = types.Rank(field(0))
+	if err := CheckMemberForMissingArgs(check); err != nil {
+		problems = append(problems, err.Error())
+	}
+	return importedMember{member: m, supervisorUsername: field(4)}, problems
+}
+
+func temporaryPassword() (string, error) {
+	secret := make([]byte, 12)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(secret), nil
+}
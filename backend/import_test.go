@@ -0,0 +1,168 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestImportMembers(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+
+	commander, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestImportMembers: %s", err.Error())
+	}
+
+	// jdoe is supervised by someone later in the file, and asmith by someone already in the database
+	valid := fmt.Sprintf(`rank,first,last,username,supervisor username,email,admin
+SrA,John,Doe,jdoe,bsmith,jdoe@example.com,false
+E-6,Bob,Smith,bsmith,%s,,
+a1c,Alice,Smith,asmith,%s,,true
+`, commander.Username, commander.Username)
+	invalid := fmt.Sprintf(`Sgt,John,Doe,jdoe,nobody,not-an-email,maybe
+SSgt,,Smith,bsmith,bsmith,,
+SSgt,Carl,Jones,%s,,,
+SSgt,Dan,Jones,djones,,,
+SSgt,Dan,Jones,DJONES,,,
+`, commander.Username)
+
+	tc := []struct {
+		Name           string
+		CSV            string
+		DryRun         bool
+		ExpectedError  error
+		ExpectedErrors map[int]int
+	}{
+		{
+			Name:           "Dry run reports row errors",
+			CSV:            invalid,
+			DryRun:         true,
+			ExpectedErrors: map[int]int{1: 4, 2: 2, 3: 1, 5: 1},
+		},
+		{
+			Name:           "Invalid rows block the import",
+			CSV:            invalid,
+			ExpectedError:  backend.ErrInvalidImport,
+			ExpectedErrors: map[int]int{1: 4, 2: 2, 3: 1, 5: 1},
+		},
+		{
+			Name:          "Malformed CSV",
+			CSV:           "SSgt,\"Bob,Smith\n",
+			ExpectedError: backend.ErrInvalidImport,
+		},
+		{
+			Name:           "Dry run of a valid roster",
+			CSV:            valid,
+			DryRun:         true,
+			ExpectedErrors: map[int]int{},
+		},
+		{
+			Name:           "Successful import",
+			CSV:            valid,
+			ExpectedErrors: map[int]int{},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.Name, func(t *testing.T) {
+			report, err := b.ImportMembers(ctx, strings.NewReader(tt.CSV), tt.DryRun)
+			if tt.ExpectedError == nil && err != nil {
+				t.Fatalf("Expected no error but got: %s", err.Error())
+			}
+			if tt.ExpectedError != nil && !errors.Is(err, tt.ExpectedError) {
+				t.Fatalf("Expected error: %s, got: %v", tt.ExpectedError, err)
+			}
+			if tt.ExpectedErrors == nil {
+				return
+			}
+			for _, row := range report.Rows {
+				if len(row.Errors) != tt.ExpectedErrors[row.Line] {
+					t.Errorf("Expected %d errors on line %d, got: %v", tt.ExpectedErrors[row.Line], row.Line, row.Errors)
+				}
+			}
+			if report.Committed != (tt.ExpectedError == nil && !tt.DryRun) {
+				t.Errorf("Unexpected committed state: %+v", report)
+			}
+		})
+	}
+
+	jdoe, err := b.GetMember(ctx, "jdoe")
+	if err != nil {
+		t.Fatalf("Expected imported member to exist: %s", err.Error())
+	}
+	bsmith, err := b.GetMember(ctx, "bsmith")
+	if err != nil {
+		t.Fatalf("Expected imported member to exist: %s", err.Error())
+	}
+	asmith, err := b.GetMember(ctx, "asmith")
+	if err != nil {
+		t.Fatalf("Expected imported member to exist: %s", err.Error())
+	}
+	if jdoe.SupervisorID != bsmith.ID || bsmith.SupervisorID != commander.ID || asmith.SupervisorID != commander.ID {
+		t.Errorf("Expected supervisors to be resolved, got: %s, %s, %s", jdoe.SupervisorID, bsmith.SupervisorID, asmith.SupervisorID)
+	}
+	if jdoe.Rank != types.E4 || bsmith.Rank != types.E6 || jdoe.Email != "jdoe@example.com" || asmith.Role != types.RoleAdmin {
+		t.Errorf("Unexpected imported values: %+v, %+v, %+v", jdoe, bsmith, asmith)
+	}
+
+	// Importing the same roster again fails every row without inserting anything
+	report, err := b.ImportMembers(ctx, strings.NewReader(valid), false)
+	if !errors.Is(err, backend.ErrInvalidImport) || report.Valid() {
+		t.Errorf("Expected duplicate usernames to be reported, got: %+v, %v", report, err)
+	}
+}
+
+func TestImportedMemberCanLogin(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+
+	report, err := b.ImportMembers(ctx, strings.NewReader("MSgt,Jane,Doe,jane.doe\n"), false)
+	if err != nil {
+		t.Fatalf("Error importing members: %s", err.Error())
+	}
+	if len(report.Rows) != 1 || len(report.Rows[0].TemporaryPassword) < backend.MinimumPwLength {
+		t.Fatalf("Expected a temporary password, got: %+v", report)
+	}
+	m, err := b.Login(ctx, "jane.doe", report.Rows[0].TemporaryPassword)
+	if err != nil {
+		t.Fatalf("Error logging in with temporary password: %s", err.Error())
+	}
+	if m.ID != report.Rows[0].MemberID {
+		t.Errorf("Expected member ID: %s, got: %s", report.Rows[0].MemberID, m.ID)
+	}
+}
@@ -0,0 +1,56 @@
+package backend
+
+import (
+	"strings"
+	"unicode"
+)
+
+// RequirementMatchThreshold is the lowest score a fuzzy match can have and still be accepted without a reviewer
+// choosing the requirement by hand.
+const RequirementMatchThreshold = 0.8
+
+// matchName returns the index of the candidate most similar to name along with its score, from 0 for nothing in
+// common to 1 for names that are the same once case, punctuation and spacing are ignored.
+func matchName(name string, candidates []string) (int, float64) {
+	best, bestScore := -1, 0.0
+	normalized := normalizeName(name)
+	for i, c := range candidates {
+		if score := similarity(normalized, normalizeName(c)); score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	return best, bestScore
+}
+
+func normalizeName(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// similarity scores two strings by their Levenshtein distance relative to the longer of the two.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
@@ -8,113 +8,233 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"log/slog"
+	"slices"
 )
 
-func (b Backend) AddMember(m types.Member) (types.Member, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Adding member", slog.Any("member", m))
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Generating ID")
+func (b Backend) AddMember(ctx context.Context, m types.Member) (types.Member, error) {
+	m, err := b.newMember(ctx, m)
+	if err != nil {
+		return types.Member{}, err
+	}
+	err = b.memberProvider.AddMember(ctx, m)
+	if err != nil {
+		return types.Member{}, err
+	}
+	return m, nil
+}
+
+// newMember validates a member being created and fills in their ID, role and password hash.
+func (b Backend) newMember(ctx context.Context, m types.Member) (types.Member, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Adding member", slog.Any("member", m))
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Generating ID")
 	m.ID = uuid.NewString()
 
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Checking for missing fields")
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Checking for missing fields")
 	if err := CheckMemberForMissingArgs(m); err != nil {
-		b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Required arguments missing for user creation", slog.String("error", err.Error()))
+		b.logger.LogAttrs(ctx, slog.LevelInfo, "Required arguments missing for user creation", slog.String("error", err.Error()))
 		return types.Member{}, err
 	}
-	if len(m.Password) < MinimumPwLength {
-		b.logger.LogAttrs(context.Background(), slog.LevelInfo, fmt.Sprintf("Password length %d does not meet minimum length of %d", len(m.Password), MinimumPwLength))
-		return types.Member{}, ErrWeakPassword
+	if m.Role == "" {
+		m.Role = types.RoleMember
+		if m.Admin {
+			m.Role = types.RoleAdmin
+		}
 	}
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Hashing password")
-	hash, err := bcrypt.GenerateFromPassword([]byte(m.Password), b.config.BcryptCost)
-	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
-		b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Provided password is too long", slog.Int("length", len(m.Password)))
-		return types.Member{}, ErrPasswordTooLong
+	if !m.Role.Valid() {
+		b.logger.LogAttrs(ctx, slog.LevelInfo, "Invalid role provided for member", slog.String("role", string(m.Role)))
+		return types.Member{}, fmt.Errorf("%w: %s", ErrInvalidRole, m.Role)
 	}
+	m.Admin = m.Role == types.RoleAdmin
+	hash, err := b.hashPassword(ctx, m.Password)
 	if err != nil {
-		b.logger.LogAttrs(context.Background(), slog.LevelWarn, "Error hashing password for new user", slog.String("error", err.Error()))
 		return types.Member{}, err
 	}
-	m.Hash = string(hash)
+	m.Hash = hash
 	m.Password = ""
-	err = b.memberProvider.AddMember(m)
+	m.Version = 1
+	return m, nil
+}
+
+// hashPassword checks a new password is long enough and hashes it for storage.
+func (b Backend) hashPassword(ctx context.Context, password string) (string, error) {
+	if len(password) < MinimumPwLength {
+		b.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Password length %d does not meet minimum length of %d", len(password), MinimumPwLength))
+		return "", ErrWeakPassword
+	}
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Hashing password")
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.config.BcryptCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		b.logger.LogAttrs(ctx, slog.LevelInfo, "Provided password is too long", slog.Int("length", len(password)))
+		return "", ErrPasswordTooLong
+	}
 	if err != nil {
-		return types.Member{}, err
+		b.logger.LogAttrs(ctx, slog.LevelWarn, "Error hashing password", slog.String("error", err.Error()))
+		return "", err
 	}
-	return m, nil
+	return string(hash), nil
 }
 
-func (b Backend) GetMember(identifier string) (types.Member, error) {
+func (b Backend) GetMember(ctx context.Context, identifier string) (types.Member, error) {
 	l := b.logger.With(slog.String("identifier", identifier))
-	l.LogAttrs(context.Background(), slog.LevelInfo, "Determining method to get member with")
+	l.LogAttrs(ctx, slog.LevelInfo, "Determining method to get member with")
 	var m ProviderMethod
 	if _, err := uuid.Parse(identifier); err != nil {
-		l.LogAttrs(context.Background(), slog.LevelInfo, "Using method ByUsername")
+		l.LogAttrs(ctx, slog.LevelInfo, "Using method ByUsername")
 		m = ByUsername
 	} else {
-		l.LogAttrs(context.Background(), slog.LevelInfo, "Using method ById")
+		l.LogAttrs(ctx, slog.LevelInfo, "Using method ById")
 		m = ById
 	}
-	l.LogAttrs(context.Background(), slog.LevelInfo, "Getting member from database")
-	return b.memberProvider.GetMember(identifier, m)
+	l.LogAttrs(ctx, slog.LevelInfo, "Getting member from database")
+	return b.memberProvider.GetMember(ctx, identifier, m)
 }
 
-func (b Backend) GetAllMembers() ([]types.Member, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting all members")
-	return b.memberProvider.GetAllMembers()
+func (b Backend) GetAllMembers(ctx context.Context) ([]types.Member, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting all members")
+	return b.memberProvider.GetAllMembers(ctx)
 }
 
-func (b Backend) GetSubordinates(memberID string) ([]types.Member, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting subordinates for member", slog.String("member_id", memberID))
-	return b.memberProvider.GetSubordinates(memberID)
+// ListMembers returns a page of members, by last name unless sorted otherwise. Filtering on a unit takes in every unit
+// under it.
+func (b Backend) ListMembers(ctx context.Context, opts types.MemberListOptions) (types.Page[types.Member], error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Listing members")
+	var err error
+	if opts.ListOptions, err = CheckListOptions(opts.ListOptions, types.Member{}, types.SortByLastName); err != nil {
+		return types.Page[types.Member]{}, err
+	}
+	switch opts.Archived {
+	case "":
+		opts.Archived = types.MemberActive
+	case types.MemberActive, types.MemberArchived, types.MemberAnyArchive:
+	default:
+		return types.Page[types.Member]{}, fmt.Errorf("%w: archived must be true, false or any, got %q", ErrInvalidListOptions, opts.Archived)
+	}
+	if len(opts.UnitIDs) > 0 {
+		tree, err := b.unitTree(ctx)
+		if err != nil {
+			return types.Page[types.Member]{}, err
+		}
+		var unitIDs []string
+		for _, id := range opts.UnitIDs {
+			if _, ok := tree.units[id]; !ok {
+				return types.Page[types.Member]{}, fmt.Errorf("%w: unit_id=%s", ErrUnitNotFound, id)
+			}
+			for _, subunit := range tree.subtree(id) {
+				if !slices.Contains(unitIDs, subunit) {
+					unitIDs = append(unitIDs, subunit)
+				}
+			}
+		}
+		opts.UnitIDs = unitIDs
+	}
+	return b.memberProvider.ListMembers(ctx, opts)
+}
+
+func (b Backend) GetSubordinates(ctx context.Context, memberID string) ([]types.Member, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting subordinates for member", slog.String("member_id", memberID))
+	return b.memberProvider.GetSubordinates(ctx, memberID)
 }
 
-func (b Backend) UpdateMember(m types.Member) (types.Member, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Updating member")
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting previous member to determine updates")
-	previousMember, err := b.memberProvider.GetMember(m.ID, ById)
+// UpdateMember merges the non-empty fields of m into the stored member, see types.Member.MergeIn for which can change.
+func (b Backend) UpdateMember(ctx context.Context, m types.Member) (types.Member, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Updating member")
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting previous member to determine updates")
+	previousMember, err := b.memberProvider.GetMember(ctx, m.ID, ById)
 	if err != nil {
-		b.logger.LogAttrs(context.Background(), slog.LevelError, "Unable to get previous member to compare updates")
+		b.logger.LogAttrs(ctx, slog.LevelError, "Unable to get previous member to compare updates")
+		return types.Member{}, err
+	}
+	if err = checkVersion(previousMember.Version, m.Version); err != nil {
 		return types.Member{}, err
 	}
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Merging members to determine updates")
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Merging members to determine updates")
+	if m.Role != "" && !m.Role.Valid() {
+		b.logger.LogAttrs(ctx, slog.LevelInfo, "Invalid role provided for member", slog.String("role", string(m.Role)))
+		return types.Member{}, fmt.Errorf("%w: %s", ErrInvalidRole, m.Role)
+	}
 	updateMember := previousMember.MergeIn(m)
 	if updateMember.Password != "" {
-		b.logger.LogAttrs(context.Background(), slog.LevelInfo, "New password provided, verifying it meets requirements")
-		if len(m.Password) < MinimumPwLength {
-			b.logger.LogAttrs(context.Background(), slog.LevelInfo, fmt.Sprintf("Password length %d does not meet minimum length of %d", len(m.Password), MinimumPwLength))
-			return types.Member{}, ErrWeakPassword
-		}
-		b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Hashing new password")
-		hash, err := bcrypt.GenerateFromPassword([]byte(updateMember.Password), b.config.BcryptCost)
-		if err != nil {
-			if errors.Is(err, bcrypt.ErrPasswordTooLong) {
-				b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Provided password is too long", slog.Int("length", len(m.Password)))
-				return types.Member{}, ErrPasswordTooLong
-			}
-			if err != nil {
-				b.logger.LogAttrs(context.Background(), slog.LevelWarn, "Error hashing password for new user", slog.String("error", err.Error()))
-				return types.Member{}, err
-			}
+		b.logger.LogAttrs(ctx, slog.LevelInfo, "New password provided, verifying it meets requirements")
+		if updateMember.Hash, err = b.hashPassword(ctx, updateMember.Password); err != nil {
+			return types.Member{}, err
 		}
-		updateMember.Hash = string(hash)
 		updateMember.Password = ""
 	}
-	err = b.memberProvider.UpdateMember(updateMember)
+	err = b.memberProvider.UpdateMember(ctx, updateMember)
 	if err != nil {
 		return types.Member{}, err
 	}
+	updateMember.Version++
 	return updateMember, nil
 }
 
-func (b Backend) DeleteMember(identifier string) error {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Deleting member", slog.String("identifier", identifier))
-	var m ProviderMethod
-	if _, err := uuid.Parse(identifier); err != nil {
-		b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Setting delete method to ByUsername")
-		m = ByUsername
-	} else {
-		b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Setting delete method to ById")
-		m = ById
+// PatchMember applies a JSON merge patch to the member. Unlike UpdateMember, null clears a field, so a member's
+// supervisor or email can be removed. The same fields can change as with UpdateMember. A non-zero version has to match the member's.
+func (b Backend) PatchMember(ctx context.Context, id string, version int, patch []byte) (types.Member, error) {
+	l := b.logger.With(slog.String("member_id", id))
+	l.LogAttrs(ctx, slog.LevelInfo, "Patching member")
+	existing, err := b.memberProvider.GetMember(ctx, id, ById)
+	if err != nil {
+		return types.Member{}, err
+	}
+	if err = checkVersion(existing.Version, version); err != nil {
+		return types.Member{}, err
+	}
+	m, err := applyMergePatch(existing, patch, "first_name", "last_name", "email", "rank", "supervisor_id", "role", "password")
+	if err != nil {
+		l.LogAttrs(ctx, slog.LevelInfo, "Unable to apply patch to member", slog.String("error", err.Error()))
+		return types.Member{}, err
+	}
+	var missing []string
+	if m.FirstName == "" {
+		missing = append(missing, "FirstName")
+	}
+	if m.LastName == "" {
+		missing = append(missing, "LastName")
+	}
+	if m.Rank == "" {
+		missing = append(missing, "Rank")
 	}
-	return b.memberProvider.DeleteMember(identifier, m)
+	if m.Role == "" {
+		missing = append(missing, "Role")
+	}
+	if len(missing) > 0 {
+		return types.Member{}, MissingArgsError{Fields: missing}
+	}
+	if !m.Role.Valid() {
+		l.LogAttrs(ctx, slog.LevelInfo, "Invalid role provided for member", slog.String("role", string(m.Role)))
+		return types.Member{}, fmt.Errorf("%w: %s", ErrInvalidRole, m.Role)
+	}
+	if m.SupervisorID == m.ID {
+		return types.Member{}, fmt.Errorf("%w: members can't supervise themselves", ErrBadUpdate)
+	}
+	m.Admin = m.Role == types.RoleAdmin
+	if m.Password != "" {
+		if m.Hash, err = b.hashPassword(ctx, m.Password); err != nil {
+			return types.Member{}, err
+		}
+		m.Password = ""
+	}
+	if err = b.memberProvider.UpdateMember(ctx, m); err != nil {
+		return types.Member{}, err
+	}
+	m.Version++
+	return m, nil
+}
+
+// BindMemberCertificate associates a client certificate identifier with a member, replacing any existing binding.
+// An empty certificateID removes the binding.
+func (b Backend) BindMemberCertificate(ctx context.Context, memberID, certificateID string) (types.Member, error) {
+	l := b.logger.With(slog.String("member_id", memberID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Binding certificate to member", slog.String("certificate_id", certificateID))
+	m, err := b.memberProvider.GetMember(ctx, memberID, ById)
+	if err != nil {
+		return types.Member{}, err
+	}
+	m.CertificateID = certificateID
+	if err = b.memberProvider.UpdateMember(ctx, m); err != nil {
+		return types.Member{}, err
+	}
+	m.Version++
+	return m, nil
 }
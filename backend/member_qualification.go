@@ -6,26 +6,52 @@ import (
 	"log/slog"
 )
 
-func (b Backend) AssignMemberQualification(memberID, qualificationID string) error {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Adding qualification to member",
-		slog.String("member_id", memberID), slog.String("qualification_id", qualificationID))
-	return b.memberProvider.AssignMemberQualification(memberID, qualificationID)
+func (b Backend) AssignMemberQualification(ctx context.Context, actorID, memberID, qualificationID string) error {
+	return b.assignMemberQualification(ctx, actorID, memberID, qualificationID, "")
+}
+
+// assignMemberQualification assigns the qualification by hand, or on behalf of positionID when it isn't empty. Only
+// qualifications a position assigned are taken away with it.
+func (b Backend) assignMemberQualification(ctx context.Context, actorID, memberID, qualificationID, positionID string) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Adding qualification to member",
+		slog.String("member_id", memberID), slog.String("qualification_id", qualificationID), slog.String("position_id", positionID))
+	return b.inTx(ctx, func(tx Backend) error {
+		var err error
+		if positionID == "" {
+			err = tx.memberProvider.AssignMemberQualification(ctx, memberID, qualificationID)
+		} else {
+			err = tx.memberProvider.AssignMemberPositionQualification(ctx, memberID, qualificationID, positionID)
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.recordQualificationEvent(ctx, actorID, memberID, qualificationID, types.QualificationAssigned, tx.clock.Now()); err != nil {
+			return err
+		}
+		return tx.recordStatusChanges(ctx, memberID, qualificationID)
+	})
 }
 
-func (b Backend) GetMemberQualification(memberID, qualificationID string) (types.Qualification, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting qualification for member",
+func (b Backend) GetMemberQualification(ctx context.Context, memberID, qualificationID string) (types.Qualification, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting qualification for member",
 		slog.String("member_id", memberID), slog.String("qualification_id", qualificationID))
-	return b.memberProvider.GetMemberQualification(memberID, qualificationID)
+	return b.memberProvider.GetMemberQualification(ctx, memberID, qualificationID)
 }
 
-func (b Backend) GetMemberQualifications(memberID string) ([]types.Qualification, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting qualifications assigned to member",
+func (b Backend) GetMemberQualifications(ctx context.Context, memberID string) ([]types.Qualification, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting qualifications assigned to member",
 		slog.String("member_id", memberID))
-	return b.memberProvider.GetMemberQualifications(memberID)
+	return b.memberProvider.GetMemberQualifications(ctx, memberID)
 }
 
-func (b Backend) RemoveMemberQualification(memberID, qualificationID string) error {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Deleting member qualification",
+// RemoveMemberQualification unassigns the qualification. The member's history with it is kept.
+func (b Backend) RemoveMemberQualification(ctx context.Context, actorID, memberID, qualificationID string) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting member qualification",
 		slog.String("member_id", memberID), slog.String("qualification_id", qualificationID))
-	return b.memberProvider.RemoveMemberQualification(memberID, qualificationID)
+	return b.inTx(ctx, func(tx Backend) error {
+		if err := tx.memberProvider.RemoveMemberQualification(ctx, memberID, qualificationID); err != nil {
+			return err
+		}
+		return tx.recordQualificationEvent(ctx, actorID, memberID, qualificationID, types.QualificationRemoved, tx.clock.Now())
+	})
 }
@@ -6,6 +6,7 @@ import (
 	"PORTal/testutils"
 	"PORTal/types"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
@@ -19,6 +20,7 @@ import (
 )
 
 func TestAddGetMemberQualification(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -26,40 +28,40 @@ func TestAddGetMemberQualification(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	member1, err := b.AddMember(testutils.RandomMember(false))
+	member1, err := b.AddMember(ctx, testutils.RandomMember(false))
 	if err != nil {
 		t.Fatalf("Error adding member for TestAddGetMemberQualification: %s", err.Error())
 	}
-	qualification1, err := b.AddQualification(testutils.RandomQualification())
+	qualification1, err := b.AddQualification(ctx, testutils.RandomQualification())
 	if err != nil {
 		t.Fatalf("Error adding qualification for TestAddGetMemberQualification: %s", err.Error())
 	}
-	qualification2, err := b.AddQualification(testutils.RandomQualification())
-	err = b.AssignMemberQualification(member1.ID, qualification1.ID)
+	qualification2, err := b.AddQualification(ctx, testutils.RandomQualification())
+	err = b.AssignMemberQualification(ctx, "", member1.ID, qualification1.ID)
 	if err != nil {
 		t.Fatalf("Error adding qualification for TestAddGetMemberQualification: %s", err.Error())
 	}
 
 	// Test assigning qualification again
-	err = b.AssignMemberQualification(member1.ID, qualification1.ID)
+	err = b.AssignMemberQualification(ctx, "", member1.ID, qualification1.ID)
 	if !errors.Is(err, backend.ErrQualificationAlreadyAssigned) {
 		t.Errorf("Expected error %s, got: %s", backend.ErrQualificationAlreadyAssigned.Error(), err.Error())
 	}
 
 	// Test add member not found
-	err = b.AssignMemberQualification(uuid.NewString(), qualification1.ID)
+	err = b.AssignMemberQualification(ctx, "", uuid.NewString(), qualification1.ID)
 	if !errors.Is(err, backend.ErrMemberNotFound) {
 		t.Errorf("Expected error %s, got: %s", backend.ErrMemberNotFound.Error(), err.Error())
 	}
 
 	// Test add qualification not found
-	err = b.AssignMemberQualification(member1.ID, uuid.NewString())
+	err = b.AssignMemberQualification(ctx, "", member1.ID, uuid.NewString())
 	if !errors.Is(err, backend.ErrQualificationNotFound) {
 		t.Errorf("Expected error %s, got: %s", backend.ErrQualificationNotFound.Error(), err.Error())
 	}
@@ -100,7 +102,7 @@ func TestAddGetMemberQualification(t *testing.T) {
 
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
-			qual, err := b.GetMemberQualification(tt.MemberID, tt.Qualification.ID)
+			qual, err := b.GetMemberQualification(ctx, tt.MemberID, tt.Qualification.ID)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -117,6 +119,7 @@ func TestAddGetMemberQualification(t *testing.T) {
 }
 
 func TestGetMemberQualifications(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -124,45 +127,45 @@ func TestGetMemberQualifications(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	member1, err := b.AddMember(testutils.RandomMember(false))
+	member1, err := b.AddMember(ctx, testutils.RandomMember(false))
 	if err != nil {
 		t.Fatalf("Error adding member for TestGetMemberQualifications: %s", err.Error())
 	}
-	member2, err := b.AddMember(testutils.RandomMember(false))
+	member2, err := b.AddMember(ctx, testutils.RandomMember(false))
 	if err != nil {
 		t.Fatalf("Error adding member for TestGetMemberQualifications: %s", err.Error())
 	}
-	qualification1, err := b.AddQualification(testutils.RandomQualification())
+	qualification1, err := b.AddQualification(ctx, testutils.RandomQualification())
 	if err != nil {
 		t.Fatalf("Error adding qualification for TestGetMemberQualifications: %s", err.Error())
 	}
-	err = b.AssignMemberQualification(member2.ID, qualification1.ID)
+	err = b.AssignMemberQualification(ctx, "", member2.ID, qualification1.ID)
 	if err != nil {
 		t.Fatalf("Error adding qualification to member for TestGetMemberQualifications: %s", err.Error())
 	}
-	member3, err := b.AddMember(testutils.RandomMember(false))
+	member3, err := b.AddMember(ctx, testutils.RandomMember(false))
 	if err != nil {
 		t.Fatalf("Error adding member for TestGetMemberQualifications: %s", err.Error())
 	}
-	qualification2, err := b.AddQualification(testutils.RandomQualification())
+	qualification2, err := b.AddQualification(ctx, testutils.RandomQualification())
 	if err != nil {
 		t.Fatalf("Error adding qualification for TestGetMemberQualifications: %s", err.Error())
 	}
-	err = b.AssignMemberQualification(member3.ID, qualification2.ID)
+	err = b.AssignMemberQualification(ctx, "", member3.ID, qualification2.ID)
 	if err != nil {
 		t.Fatalf("Error adding qualification to member for TestGetMemberQualifications: %s", err.Error())
 	}
-	qualification3, err := b.AddQualification(testutils.RandomQualification())
+	qualification3, err := b.AddQualification(ctx, testutils.RandomQualification())
 	if err != nil {
 		t.Fatalf("Error adding qualification for TestGetMemberQualifications: %s", err.Error())
 	}
-	err = b.AssignMemberQualification(member3.ID, qualification3.ID)
+	err = b.AssignMemberQualification(ctx, "", member3.ID, qualification3.ID)
 	if err != nil {
 		t.Fatalf("Error adding qualification to member for TestGetMemberQualifications: %s", err.Error())
 	}
@@ -201,7 +204,7 @@ func TestGetMemberQualifications(t *testing.T) {
 
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
-			qualifications, err := b.GetMemberQualifications(tt.MemberID)
+			qualifications, err := b.GetMemberQualifications(ctx, tt.MemberID)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -224,6 +227,7 @@ func TestGetMemberQualifications(t *testing.T) {
 }
 
 func TestRemoveMemberQualification(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -231,21 +235,21 @@ func TestRemoveMemberQualification(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	member1, err := b.AddMember(testutils.RandomMember(false))
+	member1, err := b.AddMember(ctx, testutils.RandomMember(false))
 	if err != nil {
 		t.Fatalf("Error adding member for TestRemoveMemberQualification: %s", err.Error())
 	}
-	qual1, err := b.AddQualification(testutils.RandomQualification())
+	qual1, err := b.AddQualification(ctx, testutils.RandomQualification())
 	if err != nil {
 		t.Fatalf("Error adding Qualification for TestRemoveMemberQualification: %s", err.Error())
 	}
-	err = b.AssignMemberQualification(member1.ID, qual1.ID)
+	err = b.AssignMemberQualification(ctx, "", member1.ID, qual1.ID)
 	if err != nil {
 		t.Fatalf("Error assigning qualification to member for TestRemoveMemberQualification: %s", err.Error())
 	}
@@ -278,7 +282,7 @@ func TestRemoveMemberQualification(t *testing.T) {
 
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
-			err := b.RemoveMemberQualification(tt.MemberID, tt.QualificationID)
+			err := b.RemoveMemberQualification(ctx, "", tt.MemberID, tt.QualificationID)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -286,7 +290,7 @@ func TestRemoveMemberQualification(t *testing.T) {
 				t.Errorf("Expected error: %s\nGot: %s", tt.ExpectedError.Error(), err.Error())
 			}
 			if tt.ExpectedError == nil {
-				_, err := b.GetMemberQualification(tt.MemberID, tt.QualificationID)
+				_, err := b.GetMemberQualification(ctx, tt.MemberID, tt.QualificationID)
 				if !errors.Is(err, backend.ErrMemberQualificationNotFound) {
 					t.Errorf("Expected member qualification to not be found but got error: %s", err)
 				}
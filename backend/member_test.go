@@ -6,6 +6,7 @@ import (
 	"PORTal/testutils"
 	"PORTal/types"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
@@ -20,6 +21,7 @@ import (
 )
 
 func TestAddAndGetMember(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -27,13 +29,13 @@ func TestAddAndGetMember(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	supervisor, err := b.AddMember(testutils.RandomMember(true))
+	supervisor, err := b.AddMember(ctx, testutils.RandomMember(true))
 	if err != nil {
 		t.Fatalf("Error adding member for TestAddMember_Sqlite: %s", err.Error())
 	}
@@ -122,7 +124,7 @@ func TestAddAndGetMember(t *testing.T) {
 
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
-			member, err := b.AddMember(types.Member{
+			member, err := b.AddMember(ctx, types.Member{
 				ApiMember: types.ApiMember{
 					FirstName:    tt.FirstName,
 					LastName:     tt.LastName,
@@ -140,7 +142,7 @@ func TestAddAndGetMember(t *testing.T) {
 				t.Errorf("Expected error: %s, got: %s", tt.ExpectedError.Error(), err.Error())
 			}
 			if tt.ExpectedError == nil {
-				m, err := b.GetMember(member.ID)
+				m, err := b.GetMember(ctx, member.ID)
 				if err != nil {
 					t.Errorf("Error getting member that should exist: %s", err.Error())
 				}
@@ -153,6 +155,7 @@ func TestAddAndGetMember(t *testing.T) {
 }
 
 func TestGetAllMembers(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -160,7 +163,7 @@ func TestGetAllMembers(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
@@ -187,7 +190,7 @@ func TestGetAllMembers(t *testing.T) {
 			Name:            "One member",
 			ExpectedMembers: []types.Member{},
 			SetupFunc: func(t *testing.T, tc *testCase) {
-				member1, err = b.AddMember(member1)
+				member1, err = b.AddMember(ctx, member1)
 				if err != nil {
 					t.Fatalf("Error adding member for TestGetAllMembers: %s", err.Error())
 				}
@@ -199,7 +202,7 @@ func TestGetAllMembers(t *testing.T) {
 			Name:            "Two members",
 			ExpectedMembers: []types.Member{},
 			SetupFunc: func(t *testing.T, tc *testCase) {
-				member2, err = b.AddMember(member2)
+				member2, err = b.AddMember(ctx, member2)
 				if err != nil {
 					t.Fatalf("Error adding member for TestGetAllMembers: %s", err.Error())
 				}
@@ -212,7 +215,7 @@ func TestGetAllMembers(t *testing.T) {
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
 			tt.SetupFunc(t, &tt)
-			members, err := b.GetAllMembers()
+			members, err := b.GetAllMembers(ctx)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -234,7 +237,53 @@ func TestGetAllMembers(t *testing.T) {
 	}
 }
 
+func TestListMembers(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+	for range 3 {
+		if _, err = b.AddMember(ctx, testutils.RandomMember(false)); err != nil {
+			t.Fatalf("Error adding member for TestListMembers: %s", err.Error())
+		}
+	}
+
+	tc := []struct {
+		name          string
+		opts          types.MemberListOptions
+		expectedItems int
+		expectedError error
+	}{
+		{name: "Defaults", opts: types.MemberListOptions{}, expectedItems: 3},
+		{name: "Limit", opts: types.MemberListOptions{ListOptions: types.ListOptions{Limit: 2}}, expectedItems: 2},
+		{name: "Limit over the maximum", opts: types.MemberListOptions{ListOptions: types.ListOptions{Limit: types.MaxPageSize + 1}}, expectedItems: 3},
+		{name: "Negative limit", opts: types.MemberListOptions{ListOptions: types.ListOptions{Limit: -1}}, expectedError: backend.ErrInvalidListOptions},
+		{name: "Unsortable key", opts: types.MemberListOptions{ListOptions: types.ListOptions{Sort: types.SortByName}}, expectedError: backend.ErrInvalidListOptions},
+		{name: "Malformed cursor", opts: types.MemberListOptions{ListOptions: types.ListOptions{Cursor: "%%%"}}, expectedError: backend.ErrInvalidListOptions},
+		{name: "Unknown archived filter", opts: types.MemberListOptions{Archived: "retired"}, expectedError: backend.ErrInvalidListOptions},
+	}
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := b.ListMembers(ctx, tt.opts)
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("Expected error %v, got %v", tt.expectedError, err)
+			}
+			if err == nil && (len(page.Items) != tt.expectedItems || page.Total != 3) {
+				t.Errorf("Expected %d of 3 members, got %d of %d", tt.expectedItems, len(page.Items), page.Total)
+			}
+		})
+	}
+}
+
 func TestUpdateMember(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -242,17 +291,17 @@ func TestUpdateMember(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	member, err := b.AddMember(testutils.RandomMember(false))
+	member, err := b.AddMember(ctx, testutils.RandomMember(false))
 	if err != nil {
 		t.Fatalf("Error adding member for TestUpdateMember: %s", err.Error())
 	}
-	supervisor, err := b.AddMember(testutils.RandomMember(true))
+	supervisor, err := b.AddMember(ctx, testutils.RandomMember(true))
 	if err != nil {
 		t.Fatalf("Error adding member for TestUpdateMember: %s", err.Error())
 	}
@@ -322,7 +371,7 @@ func TestUpdateMember(t *testing.T) {
 
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
-			mem, err := b.UpdateMember(tt.Updates)
+			mem, err := b.UpdateMember(ctx, tt.Updates)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -335,103 +384,3 @@ func TestUpdateMember(t *testing.T) {
 		})
 	}
 }
-
-func TestDeleteMember_Sqlite(t *testing.T) {
-	dbID := uuid.NewString()
-	t.Cleanup(func() {
-		os.Remove(fmt.Sprintf("%s.db", dbID))
-	})
-	buf := &bytes.Buffer{}
-	mr := io.MultiWriter(os.Stdout, buf)
-	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
-	if err != nil {
-		t.Fatalf("Error creating provider for tests: %s", err.Error())
-	}
-	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
-
-	m1, err := b.AddMember(testutils.RandomMember(false))
-	if err != nil {
-		t.Fatalf("Error adding member for TestDeleteMember_Sqlite: %s", err.Error())
-	}
-	supervisor, err := b.AddMember(testutils.RandomMember(true))
-	if err != nil {
-		t.Fatalf("Error adding member for TestDeleteMember_Sqlite: %s", err.Error())
-	}
-	m3 := testutils.RandomMember(false)
-	m3.SupervisorID = supervisor.SupervisorID
-	m3, err = b.AddMember(m3)
-	if err != nil {
-		t.Fatalf("Error adding member for TestDeleteMember_Sqlite: %s", err.Error())
-	}
-
-	tc := []struct {
-		Name          string
-		Identifier    string
-		ExpectedError error
-		Verification  func(t *testing.T)
-	}{
-		{
-			Name:          "Successful delete by ID",
-			Identifier:    m1.ID,
-			ExpectedError: nil,
-			Verification: func(t *testing.T) {
-				_, err := b.GetMember(m1.ID)
-				if !errors.Is(err, backend.ErrMemberNotFound) {
-					t.Errorf("Expected to not find user but did")
-				}
-			},
-		},
-		{
-			Name:          "Removed supervisor",
-			Identifier:    supervisor.ID,
-			ExpectedError: nil,
-			Verification: func(t *testing.T) {
-				m, err := b.GetMember(m3.ID)
-				if err != nil {
-					t.Errorf("Error getting member for TestDeleteMember_Sqlite: %s", err.Error())
-					return
-				}
-				if m.SupervisorID != "" {
-					t.Errorf("Expected supervisor ID to be empty after deleting supervisor, but it isn't")
-				}
-			},
-		},
-		{
-			Name:          "Successful delete by username",
-			Identifier:    m3.Username,
-			ExpectedError: nil,
-			Verification: func(t *testing.T) {
-				_, err := b.GetMember(m3.Username)
-				if !errors.Is(err, backend.ErrMemberNotFound) {
-					t.Errorf("Expected to not find member, but did")
-				}
-			},
-		},
-		{
-			Name:          "Member not found by ID",
-			Identifier:    uuid.NewString(),
-			ExpectedError: backend.ErrMemberNotFound,
-			Verification:  func(_ *testing.T) {},
-		},
-		{
-			Name:          "Member not found by Username",
-			Identifier:    "notfound",
-			ExpectedError: backend.ErrMemberNotFound,
-			Verification:  func(_ *testing.T) {},
-		},
-	}
-
-	for _, tt := range tc {
-		t.Run(tt.Name, func(t *testing.T) {
-			err = b.DeleteMember(tt.Identifier)
-			if tt.ExpectedError == nil && err != nil {
-				t.Errorf("Expected no error but got: %s", err.Error())
-			}
-			if tt.ExpectedError != nil && !errors.Is(err, tt.ExpectedError) {
-				t.Errorf("Expected error: %s, got: %s", tt.ExpectedError.Error(), err.Error())
-			}
-			tt.Verification(t)
-		})
-	}
-}
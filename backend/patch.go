@@ -0,0 +1,59 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// mergePatch applies an RFC 7396 JSON merge patch to target. Objects are merged member by member, null removes a
+// member and anything else replaces it outright, including arrays.
+func mergePatch(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+	return t
+}
+
+// applyMergePatch returns v with patch applied to its JSON form, so a member set to null goes back to its zero value
+// and one left out keeps its value. Only the top level members in patchable may be patched, which keeps IDs and fields
+// with routes of their own out of reach.
+func applyMergePatch[T any](v T, patch []byte, patchable ...string) (T, error) {
+	var p map[string]any
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return v, fmt.Errorf("%w: %s", ErrInvalidPatch, err.Error())
+	}
+	for k := range p {
+		if !slices.Contains(patchable, k) {
+			return v, fmt.Errorf("%w: %s can't be patched", ErrBadUpdate, k)
+		}
+	}
+	doc, err := json.Marshal(v)
+	if err != nil {
+		return v, err
+	}
+	var target any
+	if err = json.Unmarshal(doc, &target); err != nil {
+		return v, err
+	}
+	if doc, err = json.Marshal(mergePatch(target, p)); err != nil {
+		return v, err
+	}
+	var patched T
+	if err = json.Unmarshal(doc, &patched); err != nil {
+		return v, fmt.Errorf("%w: %s", ErrInvalidPatch, err.Error())
+	}
+	return patched, nil
+}
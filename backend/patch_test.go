@@ -0,0 +1,290 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+)
+
+func newPatchBackend(t *testing.T) backend.Backend {
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	return backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+}
+
+func TestPatchMember(t *testing.T) {
+	ctx := context.Background()
+	b := newPatchBackend(t)
+	supervisor, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding supervisor for TestPatchMember: %s", err.Error())
+	}
+	m := testutils.RandomMember(false)
+	m.SupervisorID = supervisor.ID
+	m.Email = "member@example.com"
+	m, err = b.AddMember(ctx, m)
+	if err != nil {
+		t.Fatalf("Error adding member for TestPatchMember: %s", err.Error())
+	}
+
+	tc := []struct {
+		name   string
+		patch  string
+		err    error
+		verify func(types.Member) bool
+	}{
+		{
+			name:  "Absent fields are left alone",
+			patch: `{"first_name":"Patched"}`,
+			verify: func(p types.Member) bool {
+				return p.FirstName == "Patched" && p.LastName == m.LastName && p.SupervisorID == supervisor.ID
+			},
+		},
+		{
+			name:   "Null clears supervisor and email",
+			patch:  `{"supervisor_id":null,"email":null}`,
+			verify: func(p types.Member) bool { return p.SupervisorID == "" && p.Email == "" && p.FirstName == "Patched" },
+		},
+		{
+			name:   "Role change updates admin",
+			patch:  `{"role":"admin"}`,
+			verify: func(p types.Member) bool { return p.Role == types.RoleAdmin && p.Admin },
+		},
+		{name: "Required field can't be cleared", patch: `{"last_name":null}`, err: backend.ErrMissingArgs},
+		{name: "ID can't be patched", patch: `{"id":"other"}`, err: backend.ErrBadUpdate},
+		{name: "Not an object", patch: `["first_name"]`, err: backend.ErrInvalidPatch},
+		{name: "Wrong type", patch: `{"first_name":5}`, err: backend.ErrInvalidPatch},
+		{name: "Invalid role", patch: `{"role":"general"}`, err: backend.ErrInvalidRole},
+		{name: "Weak password", patch: `{"password":"short"}`, err: backend.ErrWeakPassword},
+		{name: "Missing supervisor", patch: fmt.Sprintf(`{"supervisor_id":%q}`, uuid.NewString()), err: backend.ErrSupervisorNotFound},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			patched, err := b.PatchMember(ctx, m.ID, 0, []byte(tt.patch))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if tt.err != nil {
+				return
+			}
+			stored, err := b.GetMember(ctx, m.ID)
+			if err != nil {
+				t.Fatalf("Error getting patched member: %s", err.Error())
+			}
+			if !tt.verify(patched) || !tt.verify(stored) {
+				t.Errorf("Patch %s wasn't applied, got %+v", tt.patch, stored.ApiMember)
+			}
+		})
+	}
+
+	if _, err = b.PatchMember(ctx, uuid.NewString(), 0, []byte(`{}`)); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMemberNotFound, err)
+	}
+}
+
+func TestPatchQualification(t *testing.T) {
+	ctx := context.Background()
+	b := newPatchBackend(t)
+	ref, err := b.AddReference(ctx, testutils.RandomReference())
+	if err != nil {
+		t.Fatalf("Error adding reference for TestPatchQualification: %s", err.Error())
+	}
+	req, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref))
+	if err != nil {
+		t.Fatalf("Error adding requirement for TestPatchQualification: %s", err.Error())
+	}
+	q := testutils.RandomQualification()
+	q.Expires, q.ExpirationDays = true, 365
+	q, err = b.AddQualification(ctx, q)
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestPatchQualification: %s", err.Error())
+	}
+
+	tc := []struct {
+		name   string
+		patch  string
+		err    error
+		verify func(types.Qualification) bool
+	}{
+		{
+			name:   "Null clears notes",
+			patch:  `{"notes":null}`,
+			verify: func(p types.Qualification) bool { return p.Notes == "" && p.Name == q.Name && p.ExpirationDays == 365 },
+		},
+		{
+			name:   "Expiration can be turned off",
+			patch:  `{"expires":false}`,
+			verify: func(p types.Qualification) bool { return !p.Expires && p.ExpirationDays == 0 },
+		},
+		{
+			name:  "Requirements are replaced by ID",
+			patch: fmt.Sprintf(`{"initial_requirements":[{"id":%q}]}`, req.ID),
+			verify: func(p types.Qualification) bool {
+				return len(p.InitialRequirements) == 1 && p.InitialRequirements[0].Name == req.Name
+			},
+		},
+		{
+			name:   "Null clears requirements",
+			patch:  `{"initial_requirements":null}`,
+			verify: func(p types.Qualification) bool { return len(p.InitialRequirements) == 0 },
+		},
+		{name: "Expiring without days", patch: `{"expires":true}`, err: backend.ErrBadUpdate},
+		{name: "Name can't be cleared", patch: `{"name":null}`, err: backend.ErrMissingArgs},
+		{name: "Unknown requirement", patch: fmt.Sprintf(`{"recurring_requirements":[{"id":%q}]}`, uuid.NewString()), err: backend.ErrRequirementNotFound},
+		{name: "Self prerequisite", patch: fmt.Sprintf(`{"prerequisites":[%q]}`, q.ID), err: backend.ErrPrerequisiteCycle},
+	}
+
+	for _, tt := range tc {
+		t.Run(tt.name, func(t *testing.T) {
+			patched, err := b.PatchQualification(ctx, q.ID, 0, []byte(tt.patch))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Expected error %v, got %v", tt.err, err)
+			}
+			if tt.err != nil {
+				return
+			}
+			stored, err := b.GetQualification(ctx, q.ID)
+			if err != nil {
+				t.Fatalf("Error getting patched qualification: %s", err.Error())
+			}
+			if !tt.verify(patched) || !tt.verify(stored) {
+				t.Errorf("Patch %s wasn't applied, got %+v", tt.patch, stored)
+			}
+		})
+	}
+}
+
+func TestPatchRequirementAndReference(t *testing.T) {
+	ctx := context.Background()
+	b := newPatchBackend(t)
+	ref, err := b.AddReference(ctx, testutils.RandomReference())
+	if err != nil {
+		t.Fatalf("Error adding reference for TestPatchRequirementAndReference: %s", err.Error())
+	}
+	other, err := b.AddReference(ctx, testutils.RandomReference())
+	if err != nil {
+		t.Fatalf("Error adding reference for TestPatchRequirementAndReference: %s", err.Error())
+	}
+	req, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref))
+	if err != nil {
+		t.Fatalf("Error adding requirement for TestPatchRequirementAndReference: %s", err.Error())
+	}
+
+	patched, err := b.PatchRequirement(ctx, req.ID, 0, []byte(fmt.Sprintf(`{"notes":null,"reference":{"id":%q}}`, other.ID)))
+	if err != nil {
+		t.Fatalf("Error patching requirement: %s", err.Error())
+	}
+	stored, err := b.GetRequirement(ctx, req.ID)
+	if err != nil {
+		t.Fatalf("Error getting patched requirement: %s", err.Error())
+	}
+	for _, r := range []types.Requirement{patched, stored} {
+		if r.Notes != "" || r.Reference.ID != other.ID || r.Reference.Name != other.Name || r.Name != req.Name {
+			t.Errorf("Requirement patch wasn't applied, got %+v", r)
+		}
+	}
+	for patch, expected := range map[string]error{
+		`{"reference":null}`:                                     backend.ErrMissingArgs,
+		`{"days_valid_for":null,"description":null}`:             backend.ErrMissingArgs,
+		fmt.Sprintf(`{"reference":{"id":%q}}`, uuid.NewString()): backend.ErrReferenceNotFound,
+	} {
+		if _, err = b.PatchRequirement(ctx, req.ID, 0, []byte(patch)); !errors.Is(err, expected) {
+			t.Errorf("Expected error %s patching requirement with %s, got %v", expected, patch, err)
+		}
+	}
+	_, err = b.PatchRequirement(ctx, req.ID, 0, []byte(`{"days_valid_for":null,"description":null}`))
+	var missing backend.MissingArgsError
+	if !errors.As(err, &missing) || !slices.Equal(missing.Fields, []string{"Description", "DaysValidFor"}) {
+		t.Errorf("Expected missing Description and DaysValidFor, got %v", err)
+	}
+
+	ref.Volume = 3
+	if ref, err = b.UpdateReference(ctx, ref, false); err != nil {
+		t.Fatalf("Error setting reference volume: %s", err.Error())
+	}
+	patchedRef, err := b.PatchReference(ctx, ref.ID, 0, []byte(`{"volume":null}`))
+	if err != nil {
+		t.Fatalf("Error patching reference: %s", err.Error())
+	}
+	storedRef, err := b.GetReference(ctx, ref.ID)
+	if err != nil {
+		t.Fatalf("Error getting patched reference: %s", err.Error())
+	}
+	if patchedRef != storedRef || storedRef.Volume != 0 || storedRef.Name != ref.Name {
+		t.Errorf("Reference patch wasn't applied, got %+v", storedRef)
+	}
+	if _, err = b.PatchReference(ctx, ref.ID, 0, []byte(`{"paragraph":null}`)); !errors.Is(err, backend.ErrMissingArgs) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMissingArgs, err)
+	}
+	if _, err = b.PatchReference(ctx, uuid.NewString(), 0, []byte(`{}`)); !errors.Is(err, backend.ErrReferenceNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrReferenceNotFound, err)
+	}
+}
+
+func TestQualificationVersions(t *testing.T) {
+	ctx := context.Background()
+	b := newPatchBackend(t)
+	q, err := b.AddQualification(ctx, testutils.RandomQualification())
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestQualificationVersions: %s", err.Error())
+	}
+	if q.Version != 1 {
+		t.Fatalf("Expected a new qualification at version 1, got %d", q.Version)
+	}
+	// Two supervisors read the qualification, the first one to save wins
+	first, second := q, q
+	first.Notes = "first"
+	second.Notes = "second"
+	updated, err := b.UpdateQualification(ctx, first, false)
+	if err != nil {
+		t.Fatalf("Error updating qualification: %s", err.Error())
+	}
+	if updated.Version != 2 {
+		t.Errorf("Expected updated qualification at version 2, got %d", updated.Version)
+	}
+	if _, err = b.UpdateQualification(ctx, second, false); !errors.Is(err, backend.ErrVersionConflict) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrVersionConflict, err)
+	}
+	if _, err = b.PatchQualification(ctx, q.ID, q.Version, []byte(`{"notes":"second"}`)); !errors.Is(err, backend.ErrVersionConflict) {
+		t.Errorf("Expected error: %s patching, got: %v", backend.ErrVersionConflict, err)
+	}
+	if err = b.DeleteQualification(ctx, q.ID, q.Version); !errors.Is(err, backend.ErrVersionConflict) {
+		t.Errorf("Expected error: %s deleting, got: %v", backend.ErrVersionConflict, err)
+	}
+	stored, err := b.GetQualification(ctx, q.ID)
+	if err != nil {
+		t.Fatalf("Error getting qualification: %s", err.Error())
+	}
+	if stored.Notes != "first" || stored.Version != updated.Version {
+		t.Errorf("Expected the first update at version %d to be kept, got %+v", updated.Version, stored)
+	}
+	patched, err := b.PatchQualification(ctx, q.ID, stored.Version, []byte(`{"notes":"second"}`))
+	if err != nil {
+		t.Fatalf("Error patching current version: %s", err.Error())
+	}
+	if err = b.DeleteQualification(ctx, q.ID, patched.Version); err != nil {
+		t.Errorf("Error deleting current version: %s", err.Error())
+	}
+}
@@ -0,0 +1,308 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"slices"
+	"sort"
+)
+
+func (b Backend) AddDutyPosition(ctx context.Context, d types.DutyPosition) (types.DutyPosition, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Adding duty position", slog.String("name", d.Name))
+	if d.Name == "" {
+		return types.DutyPosition{}, MissingArgsError{Fields: []string{"Name"}}
+	}
+	d.ID = uuid.NewString()
+	d.Qualifications = dedupe(d.Qualifications)
+	sort.Strings(d.Qualifications)
+	d.Version = 1
+	if err := b.qualificationProvider.AddDutyPosition(ctx, d); err != nil {
+		return types.DutyPosition{}, err
+	}
+	return d, nil
+}
+
+func (b Backend) GetDutyPosition(ctx context.Context, id string) (types.DutyPosition, error) {
+	return b.qualificationProvider.GetDutyPosition(ctx, id)
+}
+
+func (b Backend) GetDutyPositions(ctx context.Context) ([]types.DutyPosition, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting all duty positions")
+	return b.qualificationProvider.GetDutyPositions(ctx)
+}
+
+// DeleteDutyPosition removes the position from everyone holding it, as long as it's still at version when that isn't
+// zero. Qualifications it assigned are left alone.
+func (b Backend) DeleteDutyPosition(ctx context.Context, id string, version int) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting duty position", slog.String("position_id", id))
+	if version == 0 {
+		return b.qualificationProvider.DeleteDutyPosition(ctx, id)
+	}
+	return b.inTx(ctx, func(tx Backend) error {
+		d, err := tx.qualificationProvider.GetDutyPosition(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err = checkVersion(d.Version, version); err != nil {
+			return err
+		}
+		return tx.qualificationProvider.DeleteDutyPosition(ctx, id)
+	})
+}
+
+// UpdateDutyPosition merges in changes to a position and works out what changing its bundle means for every active
+// member holding it. Qualifications dropped from the bundle are only removed where a position assigned them. Nothing is
+// changed unless apply is set, so the returned diff doubles as a preview.
+func (b Backend) UpdateDutyPosition(ctx context.Context, actorID string, d types.DutyPosition, apply bool) (types.DutyPositionDiff, error) {
+	l := b.logger.With(slog.String("position_id", d.ID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Updating duty position", slog.Bool("apply", apply))
+	existing, err := b.qualificationProvider.GetDutyPosition(ctx, d.ID)
+	if err != nil {
+		return types.DutyPositionDiff{}, err
+	}
+	if err = checkVersion(existing.Version, d.Version); err != nil {
+		return types.DutyPositionDiff{}, err
+	}
+	updated := existing.MergeIn(d)
+	updated.Qualifications = dedupe(updated.Qualifications)
+	sort.Strings(updated.Qualifications)
+	for _, id := range updated.Qualifications {
+		if _, err = b.qualificationProvider.GetQualification(ctx, id); err != nil {
+			return types.DutyPositionDiff{}, err
+		}
+	}
+	diff := types.DutyPositionDiff{
+		PositionID:            d.ID,
+		AddedQualifications:   difference(updated.Qualifications, existing.Qualifications),
+		RemovedQualifications: difference(existing.Qualifications, updated.Qualifications),
+		Members:               []types.MemberQualificationDiff{},
+		UpdatedPosition:       updated,
+	}
+	memberIDs, err := b.memberProvider.GetDutyPositionMemberIDs(ctx, d.ID)
+	if err != nil {
+		return types.DutyPositionDiff{}, err
+	}
+	for _, memberID := range memberIDs {
+		assigned, err := b.memberQualificationIDs(ctx, memberID)
+		if err != nil {
+			return types.DutyPositionDiff{}, err
+		}
+		fromPositions, err := b.positionAssignedQualificationIDs(ctx, memberID)
+		if err != nil {
+			return types.DutyPositionDiff{}, err
+		}
+		otherPositions, err := b.positionQualificationIDs(ctx, memberID, d.ID)
+		if err != nil {
+			return types.DutyPositionDiff{}, err
+		}
+		memberDiff := types.MemberQualificationDiff{MemberID: memberID, Assign: []string{}, Remove: []string{}}
+		for _, id := range diff.AddedQualifications {
+			if !assigned[id] {
+				memberDiff.Assign = append(memberDiff.Assign, id)
+			}
+		}
+		for _, id := range diff.RemovedQualifications {
+			if fromPositions[id] && !otherPositions[id] {
+				memberDiff.Remove = append(memberDiff.Remove, id)
+			}
+		}
+		if len(memberDiff.Assign) > 0 || len(memberDiff.Remove) > 0 {
+			diff.Members = append(diff.Members, memberDiff)
+		}
+	}
+	if !apply {
+		return diff, nil
+	}
+	err = b.inTx(ctx, func(tx Backend) error {
+		if err := tx.qualificationProvider.UpdateDutyPosition(ctx, updated); err != nil {
+			return err
+		}
+		for _, memberDiff := range diff.Members {
+			for _, id := range memberDiff.Assign {
+				if err := tx.assignMemberQualification(ctx, actorID, memberDiff.MemberID, id, d.ID); err != nil && !errors.Is(err, ErrQualificationAlreadyAssigned) {
+					return err
+				}
+			}
+			for _, id := range memberDiff.Remove {
+				if err := tx.RemoveMemberQualification(ctx, actorID, memberDiff.MemberID, id); err != nil && !errors.Is(err, ErrMemberQualificationNotFound) {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return types.DutyPositionDiff{}, err
+	}
+	diff.Applied = true
+	diff.UpdatedPosition.Version++
+	return diff, nil
+}
+
+// AssignMemberDutyPosition puts the member in the position and assigns every qualification in its bundle they don't
+// already have. The newly assigned qualification IDs are returned.
+func (b Backend) AssignMemberDutyPosition(ctx context.Context, actorID, memberID, positionID string) ([]string, error) {
+	l := b.logger.With(slog.String("member_id", memberID), slog.String("position_id", positionID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Assigning duty position to member")
+	position, err := b.qualificationProvider.GetDutyPosition(ctx, positionID)
+	if err != nil {
+		return nil, err
+	}
+	assigned := []string{}
+	err = b.inTx(ctx, func(tx Backend) error {
+		if err := tx.memberProvider.AssignMemberDutyPosition(ctx, memberID, positionID); err != nil {
+			return err
+		}
+		for _, id := range position.Qualifications {
+			err := tx.assignMemberQualification(ctx, actorID, memberID, id, positionID)
+			if errors.Is(err, ErrQualificationAlreadyAssigned) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			assigned = append(assigned, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	l.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Assigned %d qualifications from duty position", len(assigned)))
+	return assigned, nil
+}
+
+func (b Backend) GetMemberDutyPositions(ctx context.Context, memberID string) ([]types.DutyPosition, error) {
+	ids, err := b.memberProvider.GetMemberDutyPositionIDs(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	positions := make([]types.DutyPosition, 0, len(ids))
+	for _, id := range ids {
+		position, err := b.qualificationProvider.GetDutyPosition(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, position)
+	}
+	return positions, nil
+}
+
+// RemoveMemberDutyPosition takes the member out of the position. The qualifications that only this position gave them
+// are reported as removable, and are removed as well when removeQualifications is set. Qualifications that were
+// assigned by hand are never removable, even when they're in the position's bundle.
+func (b Backend) RemoveMemberDutyPosition(ctx context.Context, actorID, memberID, positionID string, removeQualifications bool) (types.DutyPositionRemoval, error) {
+	l := b.logger.With(slog.String("member_id", memberID), slog.String("position_id", positionID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Removing duty position from member", slog.Bool("remove_qualifications", removeQualifications))
+	position, err := b.qualificationProvider.GetDutyPosition(ctx, positionID)
+	if err != nil {
+		return types.DutyPositionRemoval{}, err
+	}
+	removal := types.DutyPositionRemoval{RemovableQualifications: []string{}, RemovedQualifications: []string{}}
+	err = b.inTx(ctx, func(tx Backend) error {
+		if err := tx.memberProvider.RemoveMemberDutyPosition(ctx, memberID, positionID); err != nil {
+			return err
+		}
+		fromPositions, err := tx.positionAssignedQualificationIDs(ctx, memberID)
+		if err != nil {
+			return err
+		}
+		otherPositions, err := tx.positionQualificationIDs(ctx, memberID, positionID)
+		if err != nil {
+			return err
+		}
+		for _, id := range position.Qualifications {
+			if fromPositions[id] && !otherPositions[id] {
+				removal.RemovableQualifications = append(removal.RemovableQualifications, id)
+			}
+		}
+		if !removeQualifications {
+			return nil
+		}
+		for _, id := range removal.RemovableQualifications {
+			if err = tx.RemoveMemberQualification(ctx, actorID, memberID, id); err != nil {
+				return err
+			}
+			removal.RemovedQualifications = append(removal.RemovedQualifications, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return types.DutyPositionRemoval{}, err
+	}
+	return removal, nil
+}
+
+func (b Backend) memberQualificationIDs(ctx context.Context, memberID string) (map[string]bool, error) {
+	quals, err := b.memberProvider.GetMemberQualifications(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(quals))
+	for _, q := range quals {
+		ids[q.ID] = true
+	}
+	return ids, nil
+}
+
+// positionAssignedQualificationIDs returns the member's qualifications that a position assigned rather than someone
+// assigning them by hand.
+func (b Backend) positionAssignedQualificationIDs(ctx context.Context, memberID string) (map[string]bool, error) {
+	qualificationIDs, err := b.memberProvider.GetMemberPositionQualificationIDs(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(qualificationIDs))
+	for _, id := range qualificationIDs {
+		ids[id] = true
+	}
+	return ids, nil
+}
+
+// positionQualificationIDs returns the qualifications provided by every position the member holds other than
+// excludePositionID.
+func (b Backend) positionQualificationIDs(ctx context.Context, memberID, excludePositionID string) (map[string]bool, error) {
+	positionIDs, err := b.memberProvider.GetMemberDutyPositionIDs(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	ids := map[string]bool{}
+	for _, positionID := range positionIDs {
+		if positionID == excludePositionID {
+			continue
+		}
+		position, err := b.qualificationProvider.GetDutyPosition(ctx, positionID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range position.Qualifications {
+			ids[id] = true
+		}
+	}
+	return ids, nil
+}
+
+// difference returns the elements of a that aren't in b, keeping their order.
+func difference(a, b []string) []string {
+	out := []string{}
+	for _, s := range a {
+		if !slices.Contains(b, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func dedupe(s []string) []string {
+	out := []string{}
+	for _, v := range s {
+		if !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,246 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestDutyPositions(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+
+	quals := make([]types.Qualification, 3)
+	for i := range quals {
+		if quals[i], err = b.AddQualification(ctx, testutils.RandomQualification()); err != nil {
+			t.Fatalf("Error adding qualification for TestDutyPositions: %s", err.Error())
+		}
+	}
+	member1, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestDutyPositions: %s", err.Error())
+	}
+	member2, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestDutyPositions: %s", err.Error())
+	}
+
+	inbound, err := b.AddDutyPosition(ctx, types.DutyPosition{Name: "Inbound Cargo", Qualifications: []string{quals[0].ID, quals[1].ID}})
+	if err != nil {
+		t.Fatalf("Error adding duty position for TestDutyPositions: %s", err.Error())
+	}
+	hazmat, err := b.AddDutyPosition(ctx, types.DutyPosition{Name: "Hazmat Certifier", Qualifications: []string{quals[1].ID}})
+	if err != nil {
+		t.Fatalf("Error adding duty position for TestDutyPositions: %s", err.Error())
+	}
+	if _, err = b.AddDutyPosition(ctx, types.DutyPosition{Name: "Inbound Cargo"}); !errors.Is(err, backend.ErrDuplicateDutyPosition) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrDuplicateDutyPosition, err)
+	}
+	if _, err = b.AddDutyPosition(ctx, types.DutyPosition{}); !errors.Is(err, backend.ErrMissingArgs) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMissingArgs, err)
+	}
+	if _, err = b.AddDutyPosition(ctx, types.DutyPosition{Name: "Pickup & Delivery", Qualifications: []string{uuid.NewString()}}); !errors.Is(err, backend.ErrQualificationNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrQualificationNotFound, err)
+	}
+
+	// Assigning a position assigns its whole bundle, skipping anything already held
+	assigned, err := b.AssignMemberDutyPosition(ctx, "", member1.ID, inbound.ID)
+	if err != nil {
+		t.Fatalf("Error assigning duty position: %s", err.Error())
+	}
+	if !reflect.DeepEqual(assigned, inbound.Qualifications) {
+		t.Errorf("Expected assigned qualifications: %v, got: %v", inbound.Qualifications, assigned)
+	}
+	if assigned, err = b.AssignMemberDutyPosition(ctx, "", member1.ID, hazmat.ID); err != nil || len(assigned) != 0 {
+		t.Errorf("Expected nothing new assigned, got: %v, %v", assigned, err)
+	}
+	if _, err = b.AssignMemberDutyPosition(ctx, "", member1.ID, hazmat.ID); !errors.Is(err, backend.ErrDutyPositionAlreadyAssigned) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrDutyPositionAlreadyAssigned, err)
+	}
+	if _, err = b.AssignMemberDutyPosition(ctx, "", member2.ID, inbound.ID); err != nil {
+		t.Fatalf("Error assigning duty position: %s", err.Error())
+	}
+	positions, err := b.GetMemberDutyPositions(ctx, member1.ID)
+	if err != nil || len(positions) != 2 {
+		t.Errorf("Expected member to hold two positions, got: %v, %v", positions, err)
+	}
+
+	// Swapping quals[1] for quals[2] only removes quals[1] from the member who doesn't get it from hazmat too
+	change := types.DutyPosition{ID: inbound.ID, Qualifications: []string{quals[0].ID, quals[2].ID}}
+	sort.Strings(change.Qualifications)
+	preview, err := b.UpdateDutyPosition(ctx, "", change, false)
+	if err != nil {
+		t.Fatalf("Error previewing duty position update: %s", err.Error())
+	}
+	expected := []types.MemberQualificationDiff{
+		{MemberID: member1.ID, Assign: []string{quals[2].ID}, Remove: []string{}},
+		{MemberID: member2.ID, Assign: []string{quals[2].ID}, Remove: []string{quals[1].ID}},
+	}
+	sort.Slice(expected, func(i, j int) bool { return expected[i].MemberID < expected[j].MemberID })
+	sort.Slice(preview.Members, func(i, j int) bool { return preview.Members[i].MemberID < preview.Members[j].MemberID })
+	if preview.Applied || !reflect.DeepEqual(preview.Members, expected) {
+		t.Errorf("Expected unapplied diff: %+v\nGot: %+v", expected, preview)
+	}
+	if !reflect.DeepEqual(preview.AddedQualifications, []string{quals[2].ID}) || !reflect.DeepEqual(preview.RemovedQualifications, []string{quals[1].ID}) {
+		t.Errorf("Unexpected bundle diff: %+v", preview)
+	}
+	if _, err = b.GetMemberQualification(ctx, member2.ID, quals[2].ID); !errors.Is(err, backend.ErrMemberQualificationNotFound) {
+		t.Errorf("Expected preview to leave member qualifications alone, got: %v", err)
+	}
+
+	applied, err := b.UpdateDutyPosition(ctx, "", change, true)
+	if err != nil || !applied.Applied {
+		t.Fatalf("Error applying duty position update: %v", err)
+	}
+	for _, id := range []string{quals[0].ID, quals[1].ID, quals[2].ID} {
+		if _, err = b.GetMemberQualification(ctx, member1.ID, id); err != nil {
+			t.Errorf("Expected member1 to hold qualification %s, got: %s", id, err.Error())
+		}
+	}
+	if _, err = b.GetMemberQualification(ctx, member2.ID, quals[1].ID); !errors.Is(err, backend.ErrMemberQualificationNotFound) {
+		t.Errorf("Expected member2 to lose qualification %s, got: %v", quals[1].ID, err)
+	}
+	updated, err := b.GetDutyPosition(ctx, inbound.ID)
+	if err != nil || !slices.Equal(updated.Qualifications, change.Qualifications) || updated.Name != inbound.Name {
+		t.Errorf("Expected updated position with qualifications %v, got: %+v, %v", change.Qualifications, updated, err)
+	}
+
+	// Leaving a position only offers to remove what no other held position provides
+	removal, err := b.RemoveMemberDutyPosition(ctx, "", member1.ID, hazmat.ID, false)
+	if err != nil {
+		t.Fatalf("Error removing duty position: %s", err.Error())
+	}
+	if !reflect.DeepEqual(removal.RemovableQualifications, []string{quals[1].ID}) || len(removal.RemovedQualifications) != 0 {
+		t.Errorf("Expected quals[1] to be removable but kept, got: %+v", removal)
+	}
+	if _, err = b.GetMemberQualification(ctx, member1.ID, quals[1].ID); err != nil {
+		t.Errorf("Expected member to keep qualification, got: %s", err.Error())
+	}
+	removal, err = b.RemoveMemberDutyPosition(ctx, "", member1.ID, inbound.ID, true)
+	if err != nil {
+		t.Fatalf("Error removing duty position: %s", err.Error())
+	}
+	if !reflect.DeepEqual(removal.RemovedQualifications, change.Qualifications) {
+		t.Errorf("Expected removed qualifications: %v, got: %+v", change.Qualifications, removal)
+	}
+	memberQuals, err := b.GetMemberQualifications(ctx, member1.ID)
+	if err != nil || len(memberQuals) != 1 || memberQuals[0].ID != quals[1].ID {
+		t.Errorf("Expected member to hold only %s, got: %+v, %v", quals[1].ID, memberQuals, err)
+	}
+	if _, err = b.RemoveMemberDutyPosition(ctx, "", member1.ID, inbound.ID, false); !errors.Is(err, backend.ErrMemberDutyPositionNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMemberDutyPositionNotFound, err)
+	}
+
+	if err = b.DeleteDutyPosition(ctx, inbound.ID, 0); err != nil {
+		t.Fatalf("Error deleting duty position: %s", err.Error())
+	}
+	if _, err = b.GetDutyPosition(ctx, inbound.ID); !errors.Is(err, backend.ErrDutyPositionNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrDutyPositionNotFound, err)
+	}
+	if positions, err = b.GetMemberDutyPositions(ctx, member2.ID); err != nil || len(positions) != 0 {
+		t.Errorf("Expected deleted position to be dropped from members, got: %v, %v", positions, err)
+	}
+}
+
+func TestDutyPositionsKeepHandAssigned(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+
+	quals := make([]types.Qualification, 3)
+	for i := range quals {
+		if quals[i], err = b.AddQualification(ctx, testutils.RandomQualification()); err != nil {
+			t.Fatalf("Error adding qualification for TestDutyPositionsKeepHandAssigned: %s", err.Error())
+		}
+	}
+	member, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestDutyPositionsKeepHandAssigned: %s", err.Error())
+	}
+	archived, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestDutyPositionsKeepHandAssigned: %s", err.Error())
+	}
+	if err = b.AssignMemberQualification(ctx, "", member.ID, quals[0].ID); err != nil {
+		t.Fatalf("Error assigning qualification: %s", err.Error())
+	}
+	position, err := b.AddDutyPosition(ctx, types.DutyPosition{Name: "Loadmaster", Qualifications: []string{quals[0].ID, quals[1].ID}})
+	if err != nil {
+		t.Fatalf("Error adding duty position for TestDutyPositionsKeepHandAssigned: %s", err.Error())
+	}
+	for _, m := range []types.Member{member, archived} {
+		if _, err = b.AssignMemberDutyPosition(ctx, "", m.ID, position.ID); err != nil {
+			t.Fatalf("Error assigning duty position: %s", err.Error())
+		}
+	}
+	if _, err = b.ArchiveMember(ctx, "", archived.ID, types.MemberArchive{Reason: "PCS"}); err != nil {
+		t.Fatalf("Error archiving member: %s", err.Error())
+	}
+
+	// Dropping the hand assigned qualification from the bundle leaves it alone, and archived members aren't touched
+	diff, err := b.UpdateDutyPosition(ctx, "", types.DutyPosition{ID: position.ID, Qualifications: []string{quals[1].ID, quals[2].ID}}, true)
+	if err != nil {
+		t.Fatalf("Error applying duty position update: %s", err.Error())
+	}
+	expected := []types.MemberQualificationDiff{{MemberID: member.ID, Assign: []string{quals[2].ID}, Remove: []string{}}}
+	if !reflect.DeepEqual(diff.Members, expected) {
+		t.Errorf("Expected diff: %+v\nGot: %+v", expected, diff.Members)
+	}
+	if _, err = b.GetMemberQualification(ctx, member.ID, quals[0].ID); err != nil {
+		t.Errorf("Expected member to keep hand assigned qualification, got: %s", err.Error())
+	}
+	if _, err = b.GetMemberQualification(ctx, archived.ID, quals[2].ID); !errors.Is(err, backend.ErrMemberQualificationNotFound) {
+		t.Errorf("Expected archived member not to be assigned %s, got: %v", quals[2].ID, err)
+	}
+
+	// Qualifications in the bundle that were assigned by hand first aren't removable with the position
+	if _, err = b.UpdateDutyPosition(ctx, "", types.DutyPosition{ID: position.ID, Qualifications: []string{quals[0].ID, quals[1].ID, quals[2].ID}}, true); err != nil {
+		t.Fatalf("Error applying duty position update: %s", err.Error())
+	}
+	removal, err := b.RemoveMemberDutyPosition(ctx, "", member.ID, position.ID, true)
+	if err != nil {
+		t.Fatalf("Error removing duty position: %s", err.Error())
+	}
+	removable := []string{quals[1].ID, quals[2].ID}
+	sort.Strings(removable)
+	if !reflect.DeepEqual(removal.RemovedQualifications, removable) {
+		t.Errorf("Expected removed qualifications: %v, got: %+v", removable, removal)
+	}
+	memberQuals, err := b.GetMemberQualifications(ctx, member.ID)
+	if err != nil || len(memberQuals) != 1 || memberQuals[0].ID != quals[0].ID {
+		t.Errorf("Expected member to hold only %s, got: %+v, %v", quals[0].ID, memberQuals, err)
+	}
+}
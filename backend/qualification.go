@@ -6,52 +6,208 @@ import (
 	"fmt"
 	"github.com/google/uuid"
 	"log/slog"
+	"slices"
 )
 
-func (b Backend) AddQualification(q types.Qualification) (types.Qualification, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Generating ID for new qualification")
+func (b Backend) AddQualification(ctx context.Context, q types.Qualification) (types.Qualification, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Generating ID for new qualification")
 	q.ID = uuid.NewString()
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Checking for missing args")
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Checking for missing args")
 	if err := CheckQualificationForMissingArgs(q); err != nil {
-		b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Missing required arguments", slog.String("error", err.Error()))
+		b.logger.LogAttrs(ctx, slog.LevelInfo, "Missing required arguments", slog.String("error", err.Error()))
 		return types.Qualification{}, err
 	}
 	if q.Expires && q.ExpirationDays < 1 {
-		b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Provided expiration days is invalid", slog.Int("days", q.ExpirationDays))
+		b.logger.LogAttrs(ctx, slog.LevelInfo, "Provided expiration days is invalid", slog.Int("days", q.ExpirationDays))
 		return types.Qualification{}, ErrInvalidQualExpiration
 	}
-	return q, b.qualificationProvider.AddQualification(q)
+	if err := b.validatePrerequisites(ctx, q); err != nil {
+		return types.Qualification{}, err
+	}
+	q.Version = 1
+	return q, b.qualificationProvider.AddQualification(ctx, q)
+}
+
+func (b Backend) GetQualification(ctx context.Context, id string) (types.Qualification, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting qualification", slog.String("id", id))
+	return b.qualificationProvider.GetQualification(ctx, id)
 }
 
-func (b Backend) GetQualification(id string) (types.Qualification, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting qualification", slog.String("id", id))
-	return b.qualificationProvider.GetQualification(id)
+func (b Backend) GetAllQualifications(ctx context.Context) ([]types.Qualification, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting all qualifications")
+	return b.qualificationProvider.GetAllQualifications(ctx)
 }
 
-func (b Backend) GetAllQualifications() ([]types.Qualification, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting all qualifications")
-	return b.qualificationProvider.GetAllQualifications()
+// ListQualifications returns a page of qualifications by name.
+func (b Backend) ListQualifications(ctx context.Context, opts types.ListOptions) (types.Page[types.Qualification], error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Listing qualifications")
+	opts, err := CheckListOptions(opts, types.Qualification{}, types.SortByName)
+	if err != nil {
+		return types.Page[types.Qualification]{}, err
+	}
+	return b.qualificationProvider.ListQualifications(ctx, opts)
 }
 
-func (b Backend) UpdateQualification(q types.Qualification, forceExpirationUpdate bool) (types.Qualification, error) {
+func (b Backend) UpdateQualification(ctx context.Context, q types.Qualification, forceExpirationUpdate bool) (types.Qualification, error) {
 	if q.Expires && q.ExpirationDays == 0 {
-		b.logger.LogAttrs(context.Background(), slog.LevelWarn, "Invalid expiration days")
+		b.logger.LogAttrs(ctx, slog.LevelWarn, "Invalid expiration days")
 		return types.Qualification{}, fmt.Errorf("%w: invalid expiration days", ErrBadUpdate)
 	}
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting existing qualification to merge in updates")
-	qual, err := b.qualificationProvider.GetQualification(q.ID)
+	var qual types.Qualification
+	// The cycle check has to see the graph as it is when the update is made, or two edits could form a cycle together
+	err := b.inTx(ctx, func(tx Backend) error {
+		tx.logger.LogAttrs(ctx, slog.LevelInfo, "Getting existing qualification to merge in updates")
+		existing, err := tx.qualificationProvider.GetQualification(ctx, q.ID)
+		if err != nil {
+			return err
+		}
+		if err = checkVersion(existing.Version, q.Version); err != nil {
+			return err
+		}
+		qual = existing.MergeIn(q, forceExpirationUpdate)
+		if err = tx.validatePrerequisites(ctx, qual); err != nil {
+			return err
+		}
+		return tx.qualificationProvider.UpdateQualification(ctx, qual)
+	})
 	if err != nil {
 		return types.Qualification{}, err
 	}
-	qual = qual.MergeIn(q, forceExpirationUpdate)
-	err = b.qualificationProvider.UpdateQualification(qual)
+	qual.Version++
+	return qual, nil
+}
+
+// PatchQualification applies a JSON merge patch to the qualification. Null clears a field, so notes can be removed and
+// setting expires to false no longer needs forceExpirationUpdate. Requirement lists are replaced whole, and only their
+// IDs are read. A non-zero version has to match the qualification's.
+func (b Backend) PatchQualification(ctx context.Context, id string, version int, patch []byte) (types.Qualification, error) {
+	l := b.logger.With(slog.String("qualification_id", id))
+	l.LogAttrs(ctx, slog.LevelInfo, "Patching qualification")
+	var q types.Qualification
+	err := b.inTx(ctx, func(tx Backend) error {
+		existing, err := tx.qualificationProvider.GetQualification(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err = checkVersion(existing.Version, version); err != nil {
+			return err
+		}
+		q, err = applyMergePatch(existing, patch, "name", "notes", "expires", "expiration_days", "initial_requirements", "recurring_requirements", "prerequisites")
+		if err != nil {
+			l.LogAttrs(ctx, slog.LevelInfo, "Unable to apply patch to qualification", slog.String("error", err.Error()))
+			return err
+		}
+		if q.Name == "" {
+			return MissingArgsError{Fields: []string{"Name"}}
+		}
+		if q.Expires && q.ExpirationDays <= 0 {
+			return fmt.Errorf("%w: invalid expiration days", ErrBadUpdate)
+		} else if !q.Expires {
+			q.ExpirationDays = 0
+		}
+		for _, requirements := range [][]types.Requirement{q.InitialRequirements, q.RecurringRequirements} {
+			for i, r := range requirements {
+				if requirements[i], err = tx.requirementProvider.GetRequirement(ctx, r.ID); err != nil {
+					return err
+				}
+			}
+		}
+		if err = tx.validatePrerequisites(ctx, q); err != nil {
+			return err
+		}
+		return tx.qualificationProvider.UpdateQualification(ctx, q)
+	})
 	if err != nil {
 		return types.Qualification{}, err
 	}
-	return qual, nil
+	q.Version++
+	return q, nil
+}
+
+// DeleteQualification deletes the qualification, as long as it's still at version when that isn't zero.
+func (b Backend) DeleteQualification(ctx context.Context, id string, version int) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting qualification")
+	if version == 0 {
+		return b.qualificationProvider.DeleteQualification(ctx, id)
+	}
+	return b.inTx(ctx, func(tx Backend) error {
+		q, err := tx.qualificationProvider.GetQualification(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err = checkVersion(q.Version, version); err != nil {
+			return err
+		}
+		return tx.qualificationProvider.DeleteQualification(ctx, id)
+	})
+}
+
+// GetPrerequisites resolves every qualification a member needs before the given one, transitively. Direct
+// prerequisites come first, followed by theirs.
+func (b Backend) GetPrerequisites(ctx context.Context, id string) ([]types.Qualification, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Resolving prerequisites for qualification", slog.String("id", id))
+	if _, err := b.qualificationProvider.GetQualification(ctx, id); err != nil {
+		return nil, err
+	}
+	graph, err := b.qualificationProvider.GetPrerequisiteGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prerequisites := []types.Qualification{}
+	seen := map[string]bool{id: true}
+	queue := slices.Clone(graph[id])
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if seen[next] {
+			continue
+		}
+		seen[next] = true
+		q, err := b.qualificationProvider.GetQualification(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		prerequisites = append(prerequisites, q)
+		queue = append(queue, graph[next]...)
+	}
+	return prerequisites, nil
 }
 
-func (b Backend) DeleteQualification(id string) error {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Deleting qualification")
-	return b.qualificationProvider.DeleteQualification(id)
+// validatePrerequisites makes sure every prerequisite exists and that adding q's prerequisites to the graph doesn't
+// let a qualification require itself.
+func (b Backend) validatePrerequisites(ctx context.Context, q types.Qualification) error {
+	if len(q.Prerequisites) == 0 {
+		return nil
+	}
+	for _, id := range q.Prerequisites {
+		if id == q.ID {
+			b.logger.LogAttrs(ctx, slog.LevelWarn, "Qualification lists itself as a prerequisite", slog.String("id", q.ID))
+			return fmt.Errorf("%w: %s requires itself", ErrPrerequisiteCycle, q.ID)
+		}
+		if _, err := b.qualificationProvider.GetQualification(ctx, id); err != nil {
+			return err
+		}
+	}
+	graph, err := b.qualificationProvider.GetPrerequisiteGraph(ctx)
+	if err != nil {
+		return err
+	}
+	graph[q.ID] = q.Prerequisites
+	// The existing graph is acyclic, so any new cycle has to pass back through q
+	seen := map[string]bool{}
+	stack := slices.Clone(q.Prerequisites)
+	for len(stack) > 0 {
+		next := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if next == q.ID {
+			b.logger.LogAttrs(ctx, slog.LevelWarn, "Prerequisites would form a cycle", slog.String("id", q.ID))
+			return fmt.Errorf("%w: %s", ErrPrerequisiteCycle, q.ID)
+		}
+		if seen[next] {
+			continue
+		}
+		seen[next] = true
+		stack = append(stack, graph[next]...)
+	}
+	return nil
 }
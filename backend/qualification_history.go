@@ -0,0 +1,161 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+// GetQualificationHistory returns everything that has happened between the member and the qualification, oldest
+// first. State changes are only recorded when the member's records are written, so a lapse nobody has written since is
+// worked out and appended without an ID rather than stored.
+func (b Backend) GetQualificationHistory(ctx context.Context, memberID, qualificationID string) ([]types.QualificationEvent, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting qualification history",
+		slog.String("member_id", memberID), slog.String("qualification_id", qualificationID))
+	if _, err := b.memberProvider.GetMember(ctx, memberID, ById); err != nil {
+		return nil, err
+	}
+	history, err := b.memberProvider.GetQualificationHistory(ctx, memberID, qualificationID)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := b.statusChanges(ctx, memberID, qualificationID)
+	if err != nil {
+		return nil, err
+	}
+	return append(history, pending...), nil
+}
+
+func (b Backend) recordQualificationEvent(ctx context.Context, actorID, memberID, qualificationID string, kind types.QualificationEventKind, at time.Time) error {
+	e := types.QualificationEvent{
+		ID:              uuid.NewString(),
+		MemberID:        memberID,
+		QualificationID: qualificationID,
+		Kind:            kind,
+		ActorID:         actorID,
+		Time:            at,
+	}
+	if err := b.memberProvider.AddQualificationEvent(ctx, e); err != nil {
+		b.logger.LogAttrs(ctx, slog.LevelError, "Unable to record qualification event",
+			slog.String("member_id", memberID), slog.String("qualification_id", qualificationID), slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// recordStatusChanges records the state changes of the member's qualifications that haven't been recorded yet, see
+// statusChanges. Anything writing records that could change a member's status calls it, before the write when the
+// write could hide a lapse and after it for whatever the write changed.
+func (b Backend) recordStatusChanges(ctx context.Context, memberID string, qualificationIDs ...string) error {
+	changes, err := b.statusChanges(ctx, memberID, qualificationIDs...)
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		if err = b.recordQualificationEvent(ctx, "", memberID, c.QualificationID, c.Kind, c.Time); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prerequisiteDependents returns the qualifications that have each qualification as a direct prerequisite.
+func (b Backend) prerequisiteDependents(ctx context.Context) (map[string][]string, error) {
+	graph, err := b.qualificationProvider.GetPrerequisiteGraph(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dependents := map[string][]string{}
+	for id, prerequisites := range graph {
+		for _, prerequisiteID := range prerequisites {
+			dependents[prerequisiteID] = append(dependents[prerequisiteID], id)
+		}
+	}
+	return dependents, nil
+}
+
+// requirementQualificationIDs returns the qualifications the requirement counts towards along with everything that
+// has one of them as a prerequisite, since a prerequisite's status carries over.
+func (b Backend) requirementQualificationIDs(ctx context.Context, requirementID string, dependents map[string][]string) ([]string, error) {
+	queue, err := b.requirementProvider.GetQualificationIDsForRequirement(ctx, requirementID)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	seen := map[string]bool{}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+		queue = append(queue, dependents[id]...)
+	}
+	return ids, nil
+}
+
+// statusChanges compares the member's current status for each assigned qualification, or only the given ones, with
+// the last state recorded in their history and returns the events for any change without recording them. Lapses are
+// dated when the member stopped being qualified rather than when the lapse was noticed.
+func (b Backend) statusChanges(ctx context.Context, memberID string, qualificationIDs ...string) ([]types.QualificationEvent, error) {
+	e, err := b.newStatusEvaluator(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	assigned, err := b.memberQualificationIDs(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if len(qualificationIDs) == 0 {
+		for id := range assigned {
+			qualificationIDs = append(qualificationIDs, id)
+		}
+	}
+	now := b.clock.Now()
+	var changes []types.QualificationEvent
+	for _, id := range qualificationIDs {
+		if !assigned[id] {
+			continue
+		}
+		status, err := e.evaluate(id)
+		if err != nil {
+			return nil, err
+		}
+		history, err := b.memberProvider.GetQualificationHistory(ctx, memberID, id)
+		if err != nil {
+			return nil, err
+		}
+		var qualified, everQualified bool
+		for _, event := range history {
+			switch event.Kind {
+			case types.QualificationAssigned:
+				qualified = false
+			case types.QualificationQualified, types.QualificationRequalified:
+				qualified, everQualified = true, true
+			case types.QualificationLapsed:
+				qualified = false
+			}
+		}
+		var kind types.QualificationEventKind
+		at := now
+		switch {
+		case !qualified && status.State == types.StateQualified && everQualified:
+			kind = types.QualificationRequalified
+		case !qualified && status.State == types.StateQualified:
+			kind = types.QualificationQualified
+		case qualified && (status.State == types.StateLapsed || status.State == types.StatePrerequisiteLapsed):
+			kind = types.QualificationLapsed
+			if !status.Expires.IsZero() && status.Expires.Before(now) {
+				at = status.Expires
+			}
+		default:
+			continue
+		}
+		changes = append(changes, types.QualificationEvent{MemberID: memberID, QualificationID: id, Kind: kind, Time: at})
+	}
+	return changes, nil
+}
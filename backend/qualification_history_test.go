@@ -0,0 +1,157 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+)
+
+// movingClock lets a test move time forward between calls.
+type movingClock struct {
+	now *time.Time
+}
+
+func (c movingClock) Now() time.Time {
+	return *c.now
+}
+
+func TestQualificationHistory(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, movingClock{now: &now})
+
+	admin, err := b.AddMember(ctx, testutils.RandomMember(true))
+	if err != nil {
+		t.Fatalf("Error adding member for TestQualificationHistory: %s", err.Error())
+	}
+	member, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestQualificationHistory: %s", err.Error())
+	}
+	ref, err := b.AddReference(ctx, testutils.RandomReference())
+	if err != nil {
+		t.Fatalf("Error adding reference for TestQualificationHistory: %s", err.Error())
+	}
+	req, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref))
+	if err != nil {
+		t.Fatalf("Error adding requirement for TestQualificationHistory: %s", err.Error())
+	}
+	if err = b.AddCertifier(ctx, req.ID, admin.ID); err != nil {
+		t.Fatalf("Error designating certifier for TestQualificationHistory: %s", err.Error())
+	}
+	qual := testutils.RandomQualification()
+	qual.Expires = true
+	qual.ExpirationDays = 365
+	qual.InitialRequirements = []types.Requirement{req}
+	qual.RecurringRequirements = nil
+	qual.Prerequisites = nil
+	qual, err = b.AddQualification(ctx, qual)
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestQualificationHistory: %s", err.Error())
+	}
+	complete := func(completed time.Time) {
+		c, err := b.SubmitCompletion(ctx, member.ID, types.Completion{MemberID: member.ID, RequirementID: req.ID, CompletedDate: completed})
+		if err != nil {
+			t.Fatalf("Error submitting completion for TestQualificationHistory: %s", err.Error())
+		}
+		if _, err = b.ReviewCompletion(ctx, admin.ID, c.ID, true, ""); err != nil {
+			t.Fatalf("Error approving completion for TestQualificationHistory: %s", err.Error())
+		}
+	}
+
+	if err = b.AssignMemberQualification(ctx, admin.ID, member.ID, qual.ID); err != nil {
+		t.Fatalf("Error assigning qualification for TestQualificationHistory: %s", err.Error())
+	}
+	firstCompletion := time.Date(1999, 12, 1, 0, 0, 0, 0, time.UTC)
+	complete(firstCompletion)
+	// The qualification expired a year after it was earned, long before anyone looked
+	now = time.Date(2001, 6, 1, 0, 0, 0, 0, time.UTC)
+	lapsed := firstCompletion.Add(365 * types.Day)
+	complete(time.Date(2001, 5, 1, 0, 0, 0, 0, time.UTC))
+	if err = b.RemoveMemberQualification(ctx, admin.ID, member.ID, qual.ID); err != nil {
+		t.Fatalf("Error removing qualification for TestQualificationHistory: %s", err.Error())
+	}
+
+	history, err := b.GetQualificationHistory(ctx, member.ID, qual.ID)
+	if err != nil {
+		t.Fatalf("Error getting qualification history: %s", err.Error())
+	}
+	want := []types.QualificationEvent{
+		{Kind: types.QualificationAssigned, ActorID: admin.ID, Time: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Kind: types.QualificationQualified, Time: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Kind: types.QualificationLapsed, Time: lapsed},
+		{Kind: types.QualificationRequalified, Time: now},
+		{Kind: types.QualificationRemoved, ActorID: admin.ID, Time: now},
+	}
+	if len(history) != len(want) {
+		t.Fatalf("Expected %d events, got %+v", len(want), history)
+	}
+	for i, e := range history {
+		if e.Kind != want[i].Kind || e.ActorID != want[i].ActorID || !e.Time.Equal(want[i].Time) {
+			t.Errorf("Event %d: expected %s by %q at %s, got %s by %q at %s", i, want[i].Kind, want[i].ActorID, want[i].Time, e.Kind, e.ActorID, e.Time)
+		}
+		if e.MemberID != member.ID || e.QualificationID != qual.ID {
+			t.Errorf("Event %d belongs to the wrong member or qualification: %+v", i, e)
+		}
+	}
+
+	// Removing the qualification keeps its history, and reassigning it starts over from there
+	if err = b.AssignMemberQualification(ctx, admin.ID, member.ID, qual.ID); err != nil {
+		t.Fatalf("Error reassigning qualification for TestQualificationHistory: %s", err.Error())
+	}
+	if history, err = b.GetQualificationHistory(ctx, member.ID, qual.ID); err != nil {
+		t.Fatalf("Error getting qualification history: %s", err.Error())
+	}
+	kinds := []types.QualificationEventKind{}
+	for _, e := range history[len(want):] {
+		kinds = append(kinds, e.Kind)
+	}
+	if len(kinds) != 2 || kinds[0] != types.QualificationAssigned || kinds[1] != types.QualificationRequalified {
+		t.Errorf("Expected reassignment to be followed by requalification, got %v", kinds)
+	}
+
+	// Reading the history shows a lapse nobody has written since without recording it
+	now = time.Date(2003, 1, 1, 0, 0, 0, 0, time.UTC)
+	relapsed := time.Date(2001, 5, 1, 0, 0, 0, 0, time.UTC).Add(365 * types.Day)
+	if history, err = b.GetQualificationHistory(ctx, member.ID, qual.ID); err != nil {
+		t.Fatalf("Error getting qualification history: %s", err.Error())
+	}
+	last := history[len(history)-1]
+	if last.Kind != types.QualificationLapsed || last.ID != "" || !last.Time.Equal(relapsed) {
+		t.Errorf("Expected an unrecorded lapse at %s, got %+v", relapsed, last)
+	}
+	stored, err := provider.GetQualificationHistory(ctx, member.ID, qual.ID)
+	if err != nil {
+		t.Fatalf("Error getting stored qualification history: %s", err.Error())
+	}
+	if len(stored) != len(history)-1 {
+		t.Errorf("Expected reading the history not to record anything, got %d stored events for %d read", len(stored), len(history))
+	}
+
+	if _, err = b.GetQualificationHistory(ctx, uuid.NewString(), qual.ID); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected ErrMemberNotFound for unknown member, got %v", err)
+	}
+}
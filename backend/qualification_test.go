@@ -6,6 +6,7 @@ import (
 	"PORTal/testutils"
 	"PORTal/types"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
@@ -19,6 +20,7 @@ import (
 )
 
 func TestAddAndGetQualification(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -26,41 +28,41 @@ func TestAddAndGetQualification(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	ref1, err := b.AddReference(testutils.RandomReference())
+	ref1, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestAddAndGetQualification: %s", err.Error())
 	}
-	ref2, err := b.AddReference(testutils.RandomReference())
+	ref2, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestAddAndGetQualification: %s", err.Error())
 	}
-	ref3, err := b.AddReference(testutils.RandomReference())
+	ref3, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestAddAndGetQualification: %s", err.Error())
 	}
-	ref4, err := b.AddReference(testutils.RandomReference())
+	ref4, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestAddAndGetQualification: %s", err.Error())
 	}
-	req1, err := b.AddRequirement(testutils.RandomRequirement(ref1))
+	req1, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref1))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestAddQualification_Sqlite: %s", err.Error())
 	}
-	req2, err := b.AddRequirement(testutils.RandomRequirement(ref2))
+	req2, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref2))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestAddQualification_Sqlite: %s", err.Error())
 	}
-	req3, err := b.AddRequirement(testutils.RandomRequirement(ref3))
+	req3, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref3))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestAddQualification_Sqlite: %s", err.Error())
 	}
-	req4, err := b.AddRequirement(testutils.RandomRequirement(ref4))
+	req4, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref4))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestAddQualification_Sqlite: %s", err.Error())
 	}
@@ -127,7 +129,7 @@ func TestAddAndGetQualification(t *testing.T) {
 
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
-			qual, err := b.AddQualification(types.Qualification{Name: tt.Name, Notes: tt.Notes, Expires: tt.Expires, ExpirationDays: tt.ExpirationDays,
+			qual, err := b.AddQualification(ctx, types.Qualification{Name: tt.Name, Notes: tt.Notes, Expires: tt.Expires, ExpirationDays: tt.ExpirationDays,
 				InitialRequirements: tt.InitialReqs, RecurringRequirements: tt.RecurringReqs})
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
@@ -136,7 +138,7 @@ func TestAddAndGetQualification(t *testing.T) {
 				t.Errorf("Expected error: %s, got: %s", tt.ExpectedError.Error(), err.Error())
 			}
 			if tt.ExpectedError == nil {
-				got, err := b.GetQualification(qual.ID)
+				got, err := b.GetQualification(ctx, qual.ID)
 				if err != nil {
 					t.Errorf("Expected no error when getting inserted qual, but got: %s", err.Error())
 				}
@@ -149,6 +151,7 @@ func TestAddAndGetQualification(t *testing.T) {
 }
 
 func TestGetAllQualifications(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -156,21 +159,21 @@ func TestGetAllQualifications(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	ref1, err := b.AddReference(testutils.RandomReference())
+	ref1, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestGetAllQualifications: %s", err.Error())
 	}
-	req1, err := b.AddRequirement(testutils.RandomRequirement(ref1))
+	req1, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref1))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestGetAllQualifications: %s", err.Error())
 	}
-	req2, err := b.AddRequirement(testutils.RandomRequirement(ref1))
+	req2, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref1))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestGetAllQualifications: %s", err.Error())
 	}
@@ -198,7 +201,7 @@ func TestGetAllQualifications(t *testing.T) {
 			ExpectedQuals: []types.Qualification{},
 			ExpectedError: nil,
 			Setup: func(t *testing.T, tc *testCase) {
-				qual, err := b.AddQualification(qual1)
+				qual, err := b.AddQualification(ctx, qual1)
 				if err != nil {
 					t.Fatalf("Error adding qualification for TestGetAllQualifications: %s", err.Error())
 				}
@@ -211,7 +214,7 @@ func TestGetAllQualifications(t *testing.T) {
 			ExpectedQuals: []types.Qualification{},
 			ExpectedError: nil,
 			Setup: func(t *testing.T, tc *testCase) {
-				qual, err := b.AddQualification(qual2)
+				qual, err := b.AddQualification(ctx, qual2)
 				if err != nil {
 					t.Fatalf("Error adding qualification for TestGetAllQUalifications_Sqlite")
 				}
@@ -223,7 +226,7 @@ func TestGetAllQualifications(t *testing.T) {
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
 			tt.Setup(t, &tt)
-			quals, err := b.GetAllQualifications()
+			quals, err := b.GetAllQualifications(ctx)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -247,6 +250,7 @@ func TestGetAllQualifications(t *testing.T) {
 }
 
 func TestUpdateQualification(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -254,48 +258,48 @@ func TestUpdateQualification(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	usedRef1, err := b.AddReference(testutils.RandomReference())
+	usedRef1, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestUpdateQualification: %s", err.Error())
 	}
-	usedRef2, err := b.AddReference(testutils.RandomReference())
+	usedRef2, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestUpdateQualification: %s", err.Error())
 	}
-	newRef1, err := b.AddReference(testutils.RandomReference())
+	newRef1, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestUpdateQualification: %s", err.Error())
 	}
-	newRef2, err := b.AddReference(testutils.RandomReference())
+	newRef2, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestUpdateQualification: %s", err.Error())
 	}
-	usedReq1, err := b.AddRequirement(testutils.RandomRequirement(usedRef1))
+	usedReq1, err := b.AddRequirement(ctx, testutils.RandomRequirement(usedRef1))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestUpdateQualification: %s", err.Error())
 	}
-	usedReq2, err := b.AddRequirement(testutils.RandomRequirement(usedRef2))
+	usedReq2, err := b.AddRequirement(ctx, testutils.RandomRequirement(usedRef2))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestUpdateQualification: %s", err.Error())
 	}
-	newReq1, err := b.AddRequirement(testutils.RandomRequirement(newRef1))
+	newReq1, err := b.AddRequirement(ctx, testutils.RandomRequirement(newRef1))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestUpdateQualification: %s", err.Error())
 	}
-	newReq2, err := b.AddRequirement(testutils.RandomRequirement(newRef2))
+	newReq2, err := b.AddRequirement(ctx, testutils.RandomRequirement(newRef2))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestUpdateQualification: %s", err.Error())
 	}
 	original := testutils.RandomQualification()
 	original.InitialRequirements = []types.Requirement{usedReq1}
 	original.RecurringRequirements = []types.Requirement{usedReq2}
-	original, err = b.AddQualification(original)
+	original, err = b.AddQualification(ctx, original)
 	if err != nil {
 		t.Fatalf("Error adding qualification for TestUpdateQualification_Sqlite: %s", err.Error())
 	}
@@ -462,7 +466,7 @@ func TestUpdateQualification(t *testing.T) {
 
 	for _, tt := range tc {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := b.UpdateQualification(tt.update, tt.forceExpirationUpdate)
+			_, err := b.UpdateQualification(ctx, tt.update, tt.forceExpirationUpdate)
 			if tt.expectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -471,7 +475,7 @@ func TestUpdateQualification(t *testing.T) {
 			}
 
 			if tt.expectedError == nil {
-				updatedQual, err := b.GetQualification(tt.update.ID)
+				updatedQual, err := b.GetQualification(ctx, tt.update.ID)
 				if err != nil {
 					t.Errorf("Error getting qualification from database: %s", err.Error())
 				}
@@ -484,6 +488,7 @@ func TestUpdateQualification(t *testing.T) {
 }
 
 func TestDeleteQualification(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -491,32 +496,32 @@ func TestDeleteQualification(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	ref1, err := b.AddReference(testutils.RandomReference())
+	ref1, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestDeleteQualification")
 	}
-	ref2, err := b.AddReference(testutils.RandomReference())
+	ref2, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestDeleteQualification")
 	}
-	req1, err := b.AddRequirement(testutils.RandomRequirement(ref1))
+	req1, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref1))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestDeleteQualification")
 	}
-	req2, err := b.AddRequirement(testutils.RandomRequirement(ref2))
+	req2, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref2))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestDeleteQualification")
 	}
 	qual := testutils.RandomQualification()
 	qual.InitialRequirements = []types.Requirement{req1}
 	qual.RecurringRequirements = []types.Requirement{req2}
-	qual, err = b.AddQualification(qual)
+	qual, err = b.AddQualification(ctx, qual)
 	if err != nil {
 		t.Fatalf("Error adding qualification for TestDeleteQualification")
 	}
@@ -540,7 +545,7 @@ func TestDeleteQualification(t *testing.T) {
 
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
-			err := b.DeleteQualification(tt.ID)
+			err := b.DeleteQualification(ctx, tt.ID, 0)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -548,7 +553,7 @@ func TestDeleteQualification(t *testing.T) {
 				t.Errorf("Expected error: %s\nGot: %s", tt.ExpectedError.Error(), err.Error())
 			}
 			if tt.ExpectedError == nil {
-				_, err = b.GetQualification(tt.ID)
+				_, err = b.GetQualification(ctx, tt.ID)
 				if !errors.Is(err, backend.ErrQualificationNotFound) {
 					t.Errorf("Expected qualification to not be found, but got: %s", err.Error())
 				}
@@ -556,3 +561,135 @@ func TestDeleteQualification(t *testing.T) {
 		})
 	}
 }
+
+func TestQualificationPrerequisites(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+
+	vehicleOperator, err := b.AddQualification(ctx, testutils.RandomQualification())
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestQualificationPrerequisites: %s", err.Error())
+	}
+	forklift := testutils.RandomQualification()
+	forklift.Prerequisites = []string{vehicleOperator.ID}
+	forklift, err = b.AddQualification(ctx, forklift)
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestQualificationPrerequisites: %s", err.Error())
+	}
+	heavyForklift := testutils.RandomQualification()
+	heavyForklift.Prerequisites = []string{forklift.ID}
+	heavyForklift, err = b.AddQualification(ctx, heavyForklift)
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestQualificationPrerequisites: %s", err.Error())
+	}
+
+	got, err := b.GetQualification(ctx, heavyForklift.ID)
+	if err != nil {
+		t.Fatalf("Error getting qualification: %s", err.Error())
+	}
+	if !testutils.CompareQuals(got, heavyForklift) {
+		t.Errorf("Expected qualification: %+v\nGot: %+v", heavyForklift, got)
+	}
+
+	missing := testutils.RandomQualification()
+	missing.Prerequisites = []string{uuid.NewString()}
+	if _, err = b.AddQualification(ctx, missing); !errors.Is(err, backend.ErrQualificationNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrQualificationNotFound, err)
+	}
+
+	tc := []struct {
+		Name          string
+		ID            string
+		Prerequisites []string
+		ExpectedError error
+	}{
+		{
+			Name:          "Requires itself",
+			ID:            vehicleOperator.ID,
+			Prerequisites: []string{vehicleOperator.ID},
+			ExpectedError: backend.ErrPrerequisiteCycle,
+		},
+		{
+			Name:          "Direct cycle",
+			ID:            vehicleOperator.ID,
+			Prerequisites: []string{forklift.ID},
+			ExpectedError: backend.ErrPrerequisiteCycle,
+		},
+		{
+			Name:          "Transitive cycle",
+			ID:            vehicleOperator.ID,
+			Prerequisites: []string{heavyForklift.ID},
+			ExpectedError: backend.ErrPrerequisiteCycle,
+		},
+		{
+			Name:          "Prerequisite not found",
+			ID:            forklift.ID,
+			Prerequisites: []string{uuid.NewString()},
+			ExpectedError: backend.ErrQualificationNotFound,
+		},
+		{
+			Name:          "Skip a level",
+			ID:            heavyForklift.ID,
+			Prerequisites: []string{forklift.ID, vehicleOperator.ID},
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := b.UpdateQualification(ctx, types.Qualification{ID: tt.ID, Prerequisites: tt.Prerequisites}, false)
+			if tt.ExpectedError != nil {
+				if !errors.Is(err, tt.ExpectedError) {
+					t.Errorf("Expected error: %s, got: %v", tt.ExpectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %s", err.Error())
+			}
+			q, err := b.GetQualification(ctx, tt.ID)
+			if err != nil {
+				t.Fatalf("Error getting qualification: %s", err.Error())
+			}
+			if len(q.Prerequisites) != len(tt.Prerequisites) {
+				t.Errorf("Expected prerequisites: %v, got: %v", tt.Prerequisites, q.Prerequisites)
+			}
+		})
+	}
+
+	prerequisites, err := b.GetPrerequisites(ctx, heavyForklift.ID)
+	if err != nil {
+		t.Fatalf("Error resolving prerequisites: %s", err.Error())
+	}
+	if len(prerequisites) != 2 {
+		t.Fatalf("Expected 2 transitive prerequisites without duplicates, got: %+v", prerequisites)
+	}
+	ids := []string{prerequisites[0].ID, prerequisites[1].ID}
+	if !slices.Contains(ids, forklift.ID) || !slices.Contains(ids, vehicleOperator.ID) {
+		t.Errorf("Expected forklift and vehicle operator prerequisites, got: %v", ids)
+	}
+	if _, err = b.GetPrerequisites(ctx, uuid.NewString()); !errors.Is(err, backend.ErrQualificationNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrQualificationNotFound, err)
+	}
+
+	// Deleting a prerequisite removes it from the graph
+	if err = b.DeleteQualification(ctx, vehicleOperator.ID, 0); err != nil {
+		t.Fatalf("Error deleting qualification: %s", err.Error())
+	}
+	prerequisites, err = b.GetPrerequisites(ctx, heavyForklift.ID)
+	if err != nil {
+		t.Fatalf("Error resolving prerequisites: %s", err.Error())
+	}
+	if len(prerequisites) != 1 || prerequisites[0].ID != forklift.ID {
+		t.Errorf("Expected only forklift prerequisite after delete, got: %+v", prerequisites)
+	}
+}
@@ -3,44 +3,78 @@ package backend
 import (
 	"PORTal/types"
 	"context"
+	"fmt"
 	"github.com/google/uuid"
 	"log/slog"
 )
 
-func (b Backend) AddReference(r types.Reference) (types.Reference, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Generating ID for new reference")
+func (b Backend) AddReference(ctx context.Context, r types.Reference) (types.Reference, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Generating ID for new reference")
 	r.ID = uuid.NewString()
 	if err := CheckReferenceForMissingArgs(r); err != nil {
 		return types.Reference{}, err
 	}
-	return r, b.requirementProvider.AddReference(r)
+	r.Version = 1
+	return r, b.requirementProvider.AddReference(ctx, r)
 }
 
-func (b Backend) GetReference(id string) (types.Reference, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting reference", slog.String("reference_id", id))
-	return b.requirementProvider.GetReference(id)
+func (b Backend) GetReference(ctx context.Context, id string) (types.Reference, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting reference", slog.String("reference_id", id))
+	return b.requirementProvider.GetReference(ctx, id)
 }
 
-func (b Backend) GetReferences() ([]types.Reference, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting all references")
-	return b.requirementProvider.GetReferences()
+func (b Backend) GetReferences(ctx context.Context) ([]types.Reference, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting all references")
+	return b.requirementProvider.GetReferences(ctx)
 }
 
-func (b Backend) UpdateReference(r types.Reference, overrideNoVolume bool) (types.Reference, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting reference to determine updates")
-	ref, err := b.GetReference(r.ID)
+func (b Backend) UpdateReference(ctx context.Context, r types.Reference, overrideNoVolume bool) (types.Reference, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting reference to determine updates")
+	ref, err := b.GetReference(ctx, r.ID)
 	if err != nil {
 		return types.Reference{}, err
 	}
+	if err = checkVersion(ref.Version, r.Version); err != nil {
+		return types.Reference{}, err
+	}
 	ref = ref.MergeIn(r, overrideNoVolume)
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Updating reference", slog.Any("new_reference", ref))
-	if err := b.requirementProvider.UpdateReference(ref); err != nil {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Updating reference", slog.Any("new_reference", ref))
+	if err := b.requirementProvider.UpdateReference(ctx, ref); err != nil {
+		return types.Reference{}, err
+	}
+	ref.Version++
+	return ref, nil
+}
+
+// PatchReference applies a JSON merge patch to the reference. Null clears a field, so a volume can be removed without
+// overrideNoVolume. A non-zero version has to match the reference's.
+func (b Backend) PatchReference(ctx context.Context, id string, version int, patch []byte) (types.Reference, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Patching reference", slog.String("reference_id", id))
+	existing, err := b.GetReference(ctx, id)
+	if err != nil {
+		return types.Reference{}, err
+	}
+	if err = checkVersion(existing.Version, version); err != nil {
+		return types.Reference{}, err
+	}
+	ref, err := applyMergePatch(existing, patch, "name", "volume", "paragraph")
+	if err != nil {
+		return types.Reference{}, err
+	}
+	if err = CheckReferenceForMissingArgs(ref); err != nil {
+		return types.Reference{}, err
+	}
+	if ref.Volume < 0 {
+		return types.Reference{}, fmt.Errorf("%w: negative volume", ErrBadUpdate)
+	}
+	if err = b.requirementProvider.UpdateReference(ctx, ref); err != nil {
 		return types.Reference{}, err
 	}
+	ref.Version++
 	return ref, nil
 }
 
-func (b Backend) DeleteReference(id string) error {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Deleting reference", slog.String("reference_id", id))
-	return b.requirementProvider.DeleteReference(id)
+func (b Backend) DeleteReference(ctx context.Context, id string) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting reference", slog.String("reference_id", id))
+	return b.requirementProvider.DeleteReference(ctx, id)
 }
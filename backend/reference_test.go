@@ -6,6 +6,7 @@ import (
 	"PORTal/testutils"
 	"PORTal/types"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
@@ -19,6 +20,7 @@ import (
 )
 
 func TestAddGetReference(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -26,19 +28,19 @@ func TestAddGetReference(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	ref1, err := b.AddReference(testutils.RandomReference())
+	ref1, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestAddGetReference: %s", err.Error())
 	}
 
 	// Test add duplicate reference name
-	_, err = b.AddReference(types.Reference{
+	_, err = b.AddReference(ctx, types.Reference{
 		Name:      ref1.Name,
 		Volume:    0,
 		Paragraph: "asdf",
@@ -69,7 +71,7 @@ func TestAddGetReference(t *testing.T) {
 
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
-			ref, err := b.GetReference(tt.ReferenceID)
+			ref, err := b.GetReference(ctx, tt.ReferenceID)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -84,6 +86,7 @@ func TestAddGetReference(t *testing.T) {
 }
 
 func TestGetReferences(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -91,7 +94,7 @@ func TestGetReferences(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
@@ -117,7 +120,7 @@ func TestGetReferences(t *testing.T) {
 			Name:               "One result",
 			ExpectedReferences: []types.Reference{},
 			SetupFunc: func(t *testing.T, tt *testStruct) {
-				ref1, err = b.AddReference(ref1)
+				ref1, err = b.AddReference(ctx, ref1)
 				if err != nil {
 					t.Errorf("Error adding reference for TestGetReferences: %s", err.Error())
 				}
@@ -129,7 +132,7 @@ func TestGetReferences(t *testing.T) {
 			Name:               "Two results",
 			ExpectedReferences: []types.Reference{},
 			SetupFunc: func(t *testing.T, tt *testStruct) {
-				ref2, err = b.AddReference(ref2)
+				ref2, err = b.AddReference(ctx, ref2)
 				if err != nil {
 					t.Errorf("Error adding reference for TestGetReferences: %s", err.Error())
 				}
@@ -142,7 +145,7 @@ func TestGetReferences(t *testing.T) {
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
 			tt.SetupFunc(t, &tt)
-			refs, err := b.GetReferences()
+			refs, err := b.GetReferences(ctx)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -157,6 +160,7 @@ func TestGetReferences(t *testing.T) {
 }
 
 func TestUpdateReferences(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -164,13 +168,13 @@ func TestUpdateReferences(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	ref1, err := b.AddReference(testutils.RandomReference())
+	ref1, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestUpdateReferences: %s", err.Error())
 	}
@@ -215,7 +219,7 @@ func TestUpdateReferences(t *testing.T) {
 
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
-			ref, err := b.UpdateReference(tt.NewRef, tt.OverrideNoVolume)
+			ref, err := b.UpdateReference(ctx, tt.NewRef, tt.OverrideNoVolume)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -223,7 +227,7 @@ func TestUpdateReferences(t *testing.T) {
 				t.Errorf("Expected error: %s\nGot: %s", tt.ExpectedError.Error(), err.Error())
 			}
 			if tt.ExpectedError == nil {
-				got, err := b.GetReference(tt.NewRef.ID)
+				got, err := b.GetReference(ctx, tt.NewRef.ID)
 				if err != nil {
 					t.Errorf("Error getting reference from database: %s", err.Error())
 				}
@@ -236,6 +240,7 @@ func TestUpdateReferences(t *testing.T) {
 }
 
 func TestDeleteReference(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -243,13 +248,13 @@ func TestDeleteReference(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	ref, err := b.AddReference(testutils.RandomReference())
+	ref, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestDeleteReference: %s", err.Error())
 	}
@@ -273,7 +278,7 @@ func TestDeleteReference(t *testing.T) {
 
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
-			err := b.DeleteReference(tt.ReferenceID)
+			err := b.DeleteReference(ctx, tt.ReferenceID)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -281,7 +286,7 @@ func TestDeleteReference(t *testing.T) {
 				t.Errorf("Expected error: %s\nGot: %s", tt.ExpectedError.Error(), err.Error())
 			}
 			if tt.ExpectedError == nil {
-				_, err := b.GetReference(tt.ReferenceID)
+				_, err := b.GetReference(ctx, tt.ReferenceID)
 				if !errors.Is(err, backend.ErrReferenceNotFound) {
 					t.Errorf("Expected to get: %s\nGot: %s", backend.ErrReferenceNotFound.Error(), err)
 				}
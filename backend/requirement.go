@@ -7,46 +7,117 @@ import (
 	"log/slog"
 )
 
-func (b Backend) AddRequirement(r types.Requirement) (types.Requirement, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Checking for missing args...")
+func (b Backend) AddRequirement(ctx context.Context, r types.Requirement) (types.Requirement, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Checking for missing args...")
 	if err := CheckRequirementForMissingArgs(r); err != nil {
-		b.logger.LogAttrs(context.Background(), slog.LevelWarn, "Required arguments missing", slog.String("error", err.Error()))
+		b.logger.LogAttrs(ctx, slog.LevelWarn, "Required arguments missing", slog.String("error", err.Error()))
 		return types.Requirement{}, err
 	}
-	return r, b.requirementProvider.AddRequirement(r)
+	r.Version = 1
+	return r, b.requirementProvider.AddRequirement(ctx, r)
 }
 
-func (b Backend) GetRequirement(id string) (types.Requirement, error) {
-	return b.requirementProvider.GetRequirement(id)
+func (b Backend) GetRequirement(ctx context.Context, id string) (types.Requirement, error) {
+	return b.requirementProvider.GetRequirement(ctx, id)
 }
 
-func (b Backend) GetAllRequirements() ([]types.Requirement, error) {
-	return b.requirementProvider.GetAllRequirements()
+func (b Backend) GetAllRequirements(ctx context.Context) ([]types.Requirement, error) {
+	return b.requirementProvider.GetAllRequirements(ctx)
 }
 
-func (b Backend) UpdateRequirement(r types.Requirement) (types.Requirement, error) {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting existing requirement to determine updates")
-	existingReq, err := b.GetRequirement(r.ID)
+// ListRequirements returns a page of requirements by name.
+func (b Backend) ListRequirements(ctx context.Context, opts types.ListOptions) (types.Page[types.Requirement], error) {
+	opts, err := CheckListOptions(opts, types.Requirement{}, types.SortByName)
+	if err != nil {
+		return types.Page[types.Requirement]{}, err
+	}
+	return b.requirementProvider.ListRequirements(ctx, opts)
+}
+
+func (b Backend) UpdateRequirement(ctx context.Context, r types.Requirement) (types.Requirement, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting existing requirement to determine updates")
+	existingReq, err := b.GetRequirement(ctx, r.ID)
 	if err != nil {
 		return types.Requirement{}, err
 	}
+	if err = checkVersion(existingReq.Version, r.Version); err != nil {
+		return types.Requirement{}, err
+	}
 	existingReq = existingReq.MergeIn(r)
-	err = b.requirementProvider.UpdateRequirement(existingReq)
+	err = b.requirementProvider.UpdateRequirement(ctx, existingReq)
 	if err != nil {
 		return types.Requirement{}, err
 	}
+	r.Version = existingReq.Version + 1
 	return r, nil
 }
 
-func (b Backend) DeleteRequirement(id string) error {
-	b.logger.LogAttrs(context.Background(), slog.LevelInfo, "Checking if requirement is assigned to any qualifications")
-	quals, err := b.requirementProvider.GetQualificationIDsForRequirement(id)
+// PatchRequirement applies a JSON merge patch to the requirement, so null clears its notes. Only the ID of a patched
+// reference is read, to point the requirement at a different one; use PatchReference to change the reference itself.
+// A non-zero version has to match the requirement's.
+func (b Backend) PatchRequirement(ctx context.Context, id string, version int, patch []byte) (types.Requirement, error) {
+	l := b.logger.With(slog.String("requirement_id", id))
+	l.LogAttrs(ctx, slog.LevelInfo, "Patching requirement")
+	existing, err := b.requirementProvider.GetRequirement(ctx, id)
+	if err != nil {
+		return types.Requirement{}, err
+	}
+	if err = checkVersion(existing.Version, version); err != nil {
+		return types.Requirement{}, err
+	}
+	r, err := applyMergePatch(existing, patch, "name", "description", "notes", "days_valid_for", "reference")
+	if err != nil {
+		l.LogAttrs(ctx, slog.LevelInfo, "Unable to apply patch to requirement", slog.String("error", err.Error()))
+		return types.Requirement{}, err
+	}
+	var missing []string
+	if r.Name == "" {
+		missing = append(missing, "Name")
+	}
+	if r.Description == "" {
+		missing = append(missing, "Description")
+	}
+	if r.DaysValidFor == 0 {
+		missing = append(missing, "DaysValidFor")
+	}
+	if r.Reference.ID == "" {
+		missing = append(missing, "Reference.ID")
+	}
+	if len(missing) > 0 {
+		return types.Requirement{}, MissingArgsError{Fields: missing}
+	}
+	if r.Reference, err = b.requirementProvider.GetReference(ctx, r.Reference.ID); err != nil {
+		return types.Requirement{}, err
+	}
+	if err = b.requirementProvider.UpdateRequirement(ctx, r); err != nil {
+		return types.Requirement{}, err
+	}
+	r.Version++
+	return r, nil
+}
+
+// DeleteRequirement deletes a requirement no qualification uses, as long as it's still at version when that isn't zero.
+func (b Backend) DeleteRequirement(ctx context.Context, id string, version int) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Checking if requirement is assigned to any qualifications")
+	quals, err := b.requirementProvider.GetQualificationIDsForRequirement(ctx, id)
 	if err != nil {
 		return err
 	}
 	if len(quals) > 0 {
-		b.logger.LogAttrs(context.Background(), slog.LevelWarn, "Requirement is still assigned to qualifications", slog.Any("qualification_ids", quals))
+		b.logger.LogAttrs(ctx, slog.LevelWarn, "Requirement is still assigned to qualifications", slog.Any("qualification_ids", quals))
 		return fmt.Errorf("%w: %v", ErrRequirementInUse, quals)
 	}
-	return b.requirementProvider.DeleteRequirement(id)
+	if version == 0 {
+		return b.requirementProvider.DeleteRequirement(ctx, id)
+	}
+	return b.inTx(ctx, func(tx Backend) error {
+		r, err := tx.requirementProvider.GetRequirement(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err = checkVersion(r.Version, version); err != nil {
+			return err
+		}
+		return tx.requirementProvider.DeleteRequirement(ctx, id)
+	})
 }
@@ -6,6 +6,7 @@ import (
 	"PORTal/testutils"
 	"PORTal/types"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/google/uuid"
@@ -19,6 +20,7 @@ import (
 )
 
 func TestAddGetRequirement(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -26,17 +28,17 @@ func TestAddGetRequirement(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	ref1, err := b.AddReference(testutils.RandomReference())
+	ref1, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestAddGetRequirement: %s", err.Error())
 	}
-	req1, err := b.AddRequirement(testutils.RandomRequirement(ref1))
+	req1, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref1))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestAddGetRequirement: %s", err.Error())
 	}
@@ -44,13 +46,13 @@ func TestAddGetRequirement(t *testing.T) {
 	// Test duplicate requirement name
 	req2 := testutils.RandomRequirement(ref1)
 	req2.Name = req1.Name
-	_, err = b.AddRequirement(req2)
+	_, err = b.AddRequirement(ctx, req2)
 	if !errors.Is(err, backend.ErrDuplicateRequirement) {
 		t.Errorf("Expected error: %s\nGot: %s", backend.ErrDuplicateRequirement.Error(), err)
 	}
 
 	// Test missing values
-	_, err = b.AddRequirement(types.Requirement{})
+	_, err = b.AddRequirement(ctx, types.Requirement{})
 	if !errors.Is(err, backend.ErrMissingArgs) {
 		t.Errorf("Expected error: %s\nGot: %s", backend.ErrMissingArgs.Error(), err)
 	}
@@ -77,7 +79,7 @@ func TestAddGetRequirement(t *testing.T) {
 
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
-			req, err := b.GetRequirement(tt.RequirementID)
+			req, err := b.GetRequirement(ctx, tt.RequirementID)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -92,6 +94,7 @@ func TestAddGetRequirement(t *testing.T) {
 }
 
 func TestGetAllRequirements(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -99,19 +102,19 @@ func TestGetAllRequirements(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	ref1, err := b.AddReference(testutils.RandomReference())
+	ref1, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestGetAllRequirements: %s", err.Error())
 	}
 	req1 := testutils.RandomRequirement(ref1)
 
-	ref2, err := b.AddReference(testutils.RandomReference())
+	ref2, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestGetAllRequirements: %s", err.Error())
 	}
@@ -136,7 +139,7 @@ func TestGetAllRequirements(t *testing.T) {
 			Results:       []types.Requirement{},
 			ExpectedError: nil,
 			SetupFunc: func(t *testing.T, tt *testStruct) {
-				req1, err = b.AddRequirement(req1)
+				req1, err = b.AddRequirement(ctx, req1)
 				if err != nil {
 					t.Fatalf("Error adding requirement for TestGetAllRequirements: %s", err.Error())
 				}
@@ -148,7 +151,7 @@ func TestGetAllRequirements(t *testing.T) {
 			Results:       []types.Requirement{},
 			ExpectedError: nil,
 			SetupFunc: func(t *testing.T, tt *testStruct) {
-				req2, err = b.AddRequirement(req2)
+				req2, err = b.AddRequirement(ctx, req2)
 				if err != nil {
 					t.Fatalf("Error adding requirement for TestGetAllRequirements: %s", err.Error())
 				}
@@ -160,7 +163,7 @@ func TestGetAllRequirements(t *testing.T) {
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
 			tt.SetupFunc(t, &tt)
-			requirements, err := b.GetAllRequirements()
+			requirements, err := b.GetAllRequirements(ctx)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error, got: %s", err.Error())
 			}
@@ -177,6 +180,7 @@ func TestGetAllRequirements(t *testing.T) {
 }
 
 func TestUpdateRequirement(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -184,21 +188,21 @@ func TestUpdateRequirement(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	originalRef, err := b.AddReference(testutils.RandomReference())
+	originalRef, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error when adding reference for TestUpdateRequirement: %s", err.Error())
 	}
-	originalReq, err := b.AddRequirement(testutils.RandomRequirement(originalRef))
+	originalReq, err := b.AddRequirement(ctx, testutils.RandomRequirement(originalRef))
 	if err != nil {
 		t.Fatalf("Error when adding requirement for TestUpdateRequirement: %s", err.Error())
 	}
-	newRef, err := b.AddReference(testutils.RandomReference())
+	newRef, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error when adding reference for TestUpdateRequirement: %s", err.Error())
 	}
@@ -260,7 +264,7 @@ func TestUpdateRequirement(t *testing.T) {
 
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
-			_, err := b.UpdateRequirement(tt.Update)
+			_, err := b.UpdateRequirement(ctx, tt.Update)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -268,7 +272,7 @@ func TestUpdateRequirement(t *testing.T) {
 				t.Errorf("Expected error: %s\nGot: %s", tt.ExpectedError.Error(), err.Error())
 			}
 			if tt.ExpectedError == nil {
-				got, err := b.GetRequirement(tt.Expected.ID)
+				got, err := b.GetRequirement(ctx, tt.Expected.ID)
 				if err != nil {
 					t.Errorf("Error getting requirement for TestUpdateRequirement: %s", err.Error())
 				}
@@ -281,6 +285,7 @@ func TestUpdateRequirement(t *testing.T) {
 }
 
 func TestDeleteRequirement(t *testing.T) {
+	ctx := context.Background()
 	dbID := uuid.NewString()
 	t.Cleanup(func() {
 		os.Remove(fmt.Sprintf("%s.db", dbID))
@@ -288,29 +293,29 @@ func TestDeleteRequirement(t *testing.T) {
 	buf := &bytes.Buffer{}
 	mr := io.MultiWriter(os.Stdout, buf)
 	logger := slog.New(slog.NewTextHandler(mr, nil))
-	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), 1.0)
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
 	if err != nil {
 		t.Fatalf("Error creating provider for tests: %s", err.Error())
 	}
 	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
 
-	ref1, err := b.AddReference(testutils.RandomReference())
+	ref1, err := b.AddReference(ctx, testutils.RandomReference())
 	if err != nil {
 		t.Fatalf("Error adding reference for TestDeleteRequirement: %s", err.Error())
 	}
-	req1, err := b.AddRequirement(testutils.RandomRequirement(ref1))
+	req1, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref1))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestDeleteRequirement: %s", err.Error())
 	}
 
-	req2, err := b.AddRequirement(testutils.RandomRequirement(ref1))
+	req2, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref1))
 	if err != nil {
 		t.Fatalf("Error adding requirement for TestDeleteRequirement: %s", err.Error())
 	}
 	qual := testutils.RandomQualification()
 	qual.InitialRequirements = []types.Requirement{req2}
 
-	qual, err = b.AddQualification(qual)
+	qual, err = b.AddQualification(ctx, qual)
 	if err != nil {
 		t.Fatalf("Error adding qualification for TestDeleteRequirement: %s", err.Error())
 	}
@@ -339,7 +344,7 @@ func TestDeleteRequirement(t *testing.T) {
 
 	for _, tt := range tc {
 		t.Run(tt.Name, func(t *testing.T) {
-			err := b.DeleteRequirement(tt.RequirementID)
+			err := b.DeleteRequirement(ctx, tt.RequirementID, 0)
 			if tt.ExpectedError == nil && err != nil {
 				t.Errorf("Expected no error but got: %s", err.Error())
 			}
@@ -347,7 +352,7 @@ func TestDeleteRequirement(t *testing.T) {
 				t.Errorf("Expected error: %s\nGot: %s", tt.ExpectedError.Error(), err.Error())
 			}
 			if tt.ExpectedError == nil {
-				_, err = b.GetRequirement(tt.RequirementID)
+				_, err = b.GetRequirement(ctx, tt.RequirementID)
 				if !errors.Is(err, backend.ErrRequirementNotFound) {
 					t.Errorf("Expected error: %s\nGot: %s", backend.ErrRequirementNotFound.Error(), err.Error())
 				}
@@ -0,0 +1,50 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+func (b Backend) GetRolePermissions(ctx context.Context, role types.Role) ([]types.Permission, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting permissions for role", slog.String("role", string(role)))
+	if !role.Valid() {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRole, role)
+	}
+	return b.memberProvider.GetRolePermissions(ctx, role)
+}
+
+func (b Backend) GetRoles(ctx context.Context) ([]types.RoleDefinition, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting role permission matrix")
+	return b.memberProvider.GetRoles(ctx)
+}
+
+// UpdateRolePermissions replaces the permissions granted to a role. The admin role always holds every permission so
+// the instance can't be locked out of role management.
+func (b Backend) UpdateRolePermissions(ctx context.Context, role types.Role, permissions []types.Permission) (types.RoleDefinition, error) {
+	l := b.logger.With(slog.String("role", string(role)))
+	l.LogAttrs(ctx, slog.LevelInfo, "Updating role permissions", slog.Any("permissions", permissions))
+	if !role.Valid() {
+		return types.RoleDefinition{}, fmt.Errorf("%w: %s", ErrInvalidRole, role)
+	}
+	if role == types.RoleAdmin {
+		l.LogAttrs(ctx, slog.LevelWarn, "Attempted to change admin role permissions")
+		return types.RoleDefinition{}, fmt.Errorf("%w: admin role permissions can't be changed", ErrBadUpdate)
+	}
+	deduped := []types.Permission{}
+	for _, permission := range permissions {
+		if !permission.Valid() {
+			l.LogAttrs(ctx, slog.LevelInfo, "Invalid permission provided", slog.String("permission", string(permission)))
+			return types.RoleDefinition{}, fmt.Errorf("%w: %s", ErrInvalidPermission, permission)
+		}
+		if !slices.Contains(deduped, permission) {
+			deduped = append(deduped, permission)
+		}
+	}
+	if err := b.memberProvider.SetRolePermissions(ctx, role, deduped); err != nil {
+		return types.RoleDefinition{}, err
+	}
+	return types.RoleDefinition{Role: role, Permissions: deduped}, nil
+}
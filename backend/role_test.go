@@ -0,0 +1,166 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestRolePermissions(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+
+	for role, expected := range types.DefaultRolePermissions {
+		permissions, err := b.GetRolePermissions(ctx, role)
+		if err != nil {
+			t.Fatalf("Error getting permissions for role %s: %s", role, err.Error())
+		}
+		slices.Sort(permissions)
+		expected = slices.Clone(expected)
+		slices.Sort(expected)
+		if !reflect.DeepEqual(permissions, expected) && !(len(permissions) == 0 && len(expected) == 0) {
+			t.Errorf("Expected seeded permissions for %s: %v, got: %v", role, expected, permissions)
+		}
+	}
+
+	tc := []struct {
+		Name          string
+		Role          types.Role
+		Permissions   []types.Permission
+		ExpectedError error
+	}{
+		{
+			Name:        "Successful update",
+			Role:        types.RoleTrainer,
+			Permissions: []types.Permission{types.PermSignOffCompletions, types.PermViewReports, types.PermViewReports},
+		},
+		{
+			Name:          "Invalid role",
+			Role:          "wizard",
+			Permissions:   []types.Permission{types.PermViewReports},
+			ExpectedError: backend.ErrInvalidRole,
+		},
+		{
+			Name:          "Invalid permission",
+			Role:          types.RoleTrainer,
+			Permissions:   []types.Permission{"everything"},
+			ExpectedError: backend.ErrInvalidPermission,
+		},
+		{
+			Name:          "Admin role",
+			Role:          types.RoleAdmin,
+			Permissions:   []types.Permission{},
+			ExpectedError: backend.ErrBadUpdate,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := b.UpdateRolePermissions(ctx, tt.Role, tt.Permissions)
+			if tt.ExpectedError != nil {
+				if !errors.Is(err, tt.ExpectedError) {
+					t.Errorf("Expected error: %s, got: %v", tt.ExpectedError.Error(), err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %s", err.Error())
+			}
+			permissions, err := b.GetRolePermissions(ctx, tt.Role)
+			if err != nil {
+				t.Fatalf("Error getting role permissions: %s", err.Error())
+			}
+			if len(permissions) != 2 || !slices.Contains(permissions, types.PermViewReports) || !slices.Contains(permissions, types.PermSignOffCompletions) {
+				t.Errorf("Expected updated permissions without duplicates, got: %v", permissions)
+			}
+		})
+	}
+
+	roles, err := b.GetRoles(ctx)
+	if err != nil {
+		t.Fatalf("Error getting roles: %s", err.Error())
+	}
+	if len(roles) != len(types.Roles) {
+		t.Errorf("Expected %d roles, got %d", len(types.Roles), len(roles))
+	}
+}
+
+func TestMemberRoles(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+
+	legacyAdmin := testutils.RandomMember(true)
+	legacyAdmin.Role = ""
+	legacyAdmin, err = b.AddMember(ctx, legacyAdmin)
+	if err != nil {
+		t.Fatalf("Error adding member for TestMemberRoles: %s", err.Error())
+	}
+	if legacyAdmin.Role != types.RoleAdmin {
+		t.Errorf("Expected admin flag to map to admin role, got: %s", legacyAdmin.Role)
+	}
+
+	manager := testutils.RandomMember(false)
+	manager.Role = types.RoleTrainingManager
+	manager, err = b.AddMember(ctx, manager)
+	if err != nil {
+		t.Fatalf("Error adding member for TestMemberRoles: %s", err.Error())
+	}
+	got, err := b.GetMember(ctx, manager.ID)
+	if err != nil {
+		t.Fatalf("Error getting member for TestMemberRoles: %s", err.Error())
+	}
+	if got.Role != types.RoleTrainingManager || got.Admin {
+		t.Errorf("Expected non-admin training manager, got role %s admin %t", got.Role, got.Admin)
+	}
+
+	invalid := testutils.RandomMember(false)
+	invalid.Role = "wizard"
+	if _, err = b.AddMember(ctx, invalid); !errors.Is(err, backend.ErrInvalidRole) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrInvalidRole, err)
+	}
+	if _, err = b.UpdateMember(ctx, types.Member{ApiMember: types.ApiMember{ID: manager.ID, Role: "wizard"}}); !errors.Is(err, backend.ErrInvalidRole) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrInvalidRole, err)
+	}
+
+	promoted, err := b.UpdateMember(ctx, types.Member{ApiMember: types.ApiMember{ID: manager.ID, Role: types.RoleAdmin}})
+	if err != nil {
+		t.Fatalf("Error updating member role: %s", err.Error())
+	}
+	if !promoted.Admin {
+		t.Errorf("Expected admin flag to follow admin role")
+	}
+}
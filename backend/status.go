@@ -0,0 +1,234 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// statusEvaluator computes qualification statuses for a single member, memoizing results so shared prerequisites are
+// only evaluated once.
+type statusEvaluator struct {
+	memberID       string
+	now            time.Time
+	completions    map[string]time.Time
+	qualifications map[string]types.Qualification
+	// requirementWaivers and qualificationWaivers are the member's waivers in effect now, keyed by what they cover
+	requirementWaivers   map[string]types.Waiver
+	qualificationWaivers map[string]types.Waiver
+	statuses             map[string]types.QualificationStatus
+	visiting             map[string]bool
+}
+
+func (b Backend) newStatusEvaluator(ctx context.Context, memberID string) (statusEvaluator, error) {
+	reqs, err := b.memberProvider.GetMemberRequirements(ctx, memberID)
+	if err != nil {
+		return statusEvaluator{}, err
+	}
+	completions := make(map[string]time.Time, len(reqs))
+	for _, r := range reqs {
+		if r.Completed {
+			completions[r.Requirement.ID] = r.CompletedDate
+		}
+	}
+	quals, err := b.qualificationProvider.GetAllQualifications(ctx)
+	if err != nil {
+		return statusEvaluator{}, err
+	}
+	qualifications := make(map[string]types.Qualification, len(quals))
+	for _, q := range quals {
+		qualifications[q.ID] = q
+	}
+	waivers, err := b.memberProvider.GetMemberWaivers(ctx, memberID)
+	if err != nil {
+		return statusEvaluator{}, err
+	}
+	now := b.clock.Now()
+	requirementWaivers, qualificationWaivers := activeWaivers(waivers, now)
+	return statusEvaluator{
+		memberID:             memberID,
+		now:                  now,
+		completions:          completions,
+		qualifications:       qualifications,
+		requirementWaivers:   requirementWaivers,
+		qualificationWaivers: qualificationWaivers,
+		statuses:             map[string]types.QualificationStatus{},
+		visiting:             map[string]bool{},
+	}, nil
+}
+
+func (e statusEvaluator) evaluate(qualificationID string) (types.QualificationStatus, error) {
+	if status, ok := e.statuses[qualificationID]; ok {
+		return status, nil
+	}
+	q, ok := e.qualifications[qualificationID]
+	if !ok {
+		return types.QualificationStatus{}, fmt.Errorf("%w: %s", ErrQualificationNotFound, qualificationID)
+	}
+	if e.visiting[qualificationID] {
+		return types.QualificationStatus{}, fmt.Errorf("%w: %s", ErrPrerequisiteCycle, qualificationID)
+	}
+	e.visiting[qualificationID] = true
+	defer delete(e.visiting, qualificationID)
+
+	status := types.QualificationStatus{MemberID: e.memberID, QualificationID: q.ID, State: types.StateQualified}
+	// The qualification date is when the last initial requirement was completed
+	var qualified time.Time
+	for _, r := range q.InitialRequirements {
+		completed, ok := e.completions[r.ID]
+		if !ok {
+			if !e.waiveRequirement(&status, r.ID) {
+				status.MissingRequirements = append(status.MissingRequirements, r.ID)
+			}
+			continue
+		}
+		if completed.After(qualified) {
+			qualified = completed
+		}
+	}
+	if len(status.MissingRequirements) > 0 {
+		status.State = types.StateInTraining
+		e.waiveQualification(&status)
+		e.statuses[q.ID] = status
+		return status, nil
+	}
+
+	lastCompletion := qualified
+	for _, r := range q.RecurringRequirements {
+		// A recurring requirement that has never been done is due relative to the qualification date, or still to be
+		// trained on when there are no initial requirements to give one
+		completed, ok := e.completions[r.ID]
+		if !ok && qualified.IsZero() {
+			if !e.waiveRequirement(&status, r.ID) {
+				status.MissingRequirements = append(status.MissingRequirements, r.ID)
+			}
+			continue
+		}
+		if !ok || completed.Before(qualified) {
+			completed = qualified
+		}
+		if completed.After(lastCompletion) {
+			lastCompletion = completed
+		}
+		if r.DaysValidFor <= 0 {
+			continue
+		}
+		due := completed.Add(time.Duration(r.DaysValidFor) * types.Day)
+		if due.Before(e.now) {
+			if !e.waiveRequirement(&status, r.ID) {
+				status.LapsedRequirements = append(status.LapsedRequirements, r.ID)
+			}
+			continue
+		}
+		status.Expires = earliest(status.Expires, due)
+	}
+	if len(status.MissingRequirements) > 0 {
+		status.State = types.StateInTraining
+		e.waiveQualification(&status)
+		e.statuses[q.ID] = status
+		return status, nil
+	}
+	if q.Expires && q.ExpirationDays > 0 && !lastCompletion.IsZero() {
+		expires := lastCompletion.Add(time.Duration(q.ExpirationDays) * types.Day)
+		if expires.Before(e.now) {
+			status.State = types.StateLapsed
+		}
+		status.Expires = earliest(status.Expires, expires)
+	}
+	if len(status.LapsedRequirements) > 0 {
+		status.State = types.StateLapsed
+	}
+
+	for _, prerequisiteID := range q.Prerequisites {
+		prerequisite, err := e.evaluate(prerequisiteID)
+		if err != nil {
+			return types.QualificationStatus{}, err
+		}
+		if prerequisite.State != types.StateQualified {
+			status.LapsedPrerequisites = append(status.LapsedPrerequisites, prerequisiteID)
+			continue
+		}
+		// Anything keeping the prerequisite current keeps this qualification current too
+		status.Waivers = append(status.Waivers, prerequisite.Waivers...)
+		if !prerequisite.Expires.IsZero() {
+			status.Expires = earliest(status.Expires, prerequisite.Expires)
+		}
+	}
+	if status.State == types.StateQualified && len(status.LapsedPrerequisites) > 0 {
+		status.State = types.StatePrerequisiteLapsed
+	}
+	e.waiveQualification(&status)
+	e.statuses[q.ID] = status
+	return status, nil
+}
+
+// waiveRequirement treats a missing or lapsed requirement as satisfied when a waiver covers it, in which case the
+// status can only last until the waiver ends.
+func (e statusEvaluator) waiveRequirement(status *types.QualificationStatus, requirementID string) bool {
+	w, ok := e.requirementWaivers[requirementID]
+	if !ok {
+		return false
+	}
+	status.WaivedRequirements = append(status.WaivedRequirements, requirementID)
+	status.Waivers = append(status.Waivers, w.ID)
+	status.Expires = earliest(status.Expires, w.End)
+	return true
+}
+
+// waiveQualification marks an unqualified member as qualified until the end of a waiver covering the whole
+// qualification.
+func (e statusEvaluator) waiveQualification(status *types.QualificationStatus) {
+	w, ok := e.qualificationWaivers[status.QualificationID]
+	if !ok || status.State == types.StateQualified {
+		return
+	}
+	status.State = types.StateQualified
+	status.Waived = true
+	status.Waivers = append(status.Waivers, w.ID)
+	status.Expires = w.End
+}
+
+func earliest(current, candidate time.Time) time.Time {
+	if current.IsZero() || candidate.Before(current) {
+		return candidate
+	}
+	return current
+}
+
+// GetMemberQualificationStatus evaluates a qualification assigned to the member, including its prerequisites.
+func (b Backend) GetMemberQualificationStatus(ctx context.Context, memberID, qualificationID string) (types.QualificationStatus, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Evaluating qualification status for member",
+		slog.String("member_id", memberID), slog.String("qualification_id", qualificationID))
+	if _, err := b.memberProvider.GetMemberQualification(ctx, memberID, qualificationID); err != nil {
+		return types.QualificationStatus{}, err
+	}
+	e, err := b.newStatusEvaluator(ctx, memberID)
+	if err != nil {
+		return types.QualificationStatus{}, err
+	}
+	return e.evaluate(qualificationID)
+}
+
+// GetMemberQualificationStatuses evaluates every qualification assigned to the member.
+func (b Backend) GetMemberQualificationStatuses(ctx context.Context, memberID string) ([]types.QualificationStatus, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Evaluating qualification statuses for member", slog.String("member_id", memberID))
+	quals, err := b.memberProvider.GetMemberQualifications(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	e, err := b.newStatusEvaluator(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]types.QualificationStatus, 0, len(quals))
+	for _, q := range quals {
+		status, err := e.evaluate(q.ID)
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
@@ -0,0 +1,202 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestQualificationStatus(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	// expireClock pins now to 2000-01-01
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, expireClock{})
+
+	certifier, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestQualificationStatus: %s", err.Error())
+	}
+	ref, err := b.AddReference(ctx, testutils.RandomReference())
+	if err != nil {
+		t.Fatalf("Error adding reference for TestQualificationStatus: %s", err.Error())
+	}
+	addRequirement := func(daysValidFor int) types.Requirement {
+		r := testutils.RandomRequirement(ref)
+		r.DaysValidFor = daysValidFor
+		r, err := b.AddRequirement(ctx, r)
+		if err != nil {
+			t.Fatalf("Error adding requirement for TestQualificationStatus: %s", err.Error())
+		}
+		if err = b.AddCertifier(ctx, r.ID, certifier.ID); err != nil {
+			t.Fatalf("Error designating certifier for TestQualificationStatus: %s", err.Error())
+		}
+		return r
+	}
+	vehicleInitial := addRequirement(3650)
+	vehicleRecurring := addRequirement(365)
+	forkliftInitial := addRequirement(3650)
+
+	vehicleOperator := testutils.RandomQualification()
+	vehicleOperator.Expires = false
+	vehicleOperator.InitialRequirements = []types.Requirement{vehicleInitial}
+	vehicleOperator.RecurringRequirements = []types.Requirement{vehicleRecurring}
+	vehicleOperator, err = b.AddQualification(ctx, vehicleOperator)
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestQualificationStatus: %s", err.Error())
+	}
+	forklift := testutils.RandomQualification()
+	forklift.Expires = false
+	forklift.InitialRequirements = []types.Requirement{forkliftInitial}
+	forklift.Prerequisites = []string{vehicleOperator.ID}
+	forklift, err = b.AddQualification(ctx, forklift)
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestQualificationStatus: %s", err.Error())
+	}
+
+	refresher := testutils.RandomQualification()
+	refresher.Expires = false
+	refresher.RecurringRequirements = []types.Requirement{addRequirement(365)}
+	refresher, err = b.AddQualification(ctx, refresher)
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestQualificationStatus: %s", err.Error())
+	}
+
+	complete := func(memberID, requirementID, date string) {
+		completed, err := time.Parse(time.DateOnly, date)
+		if err != nil {
+			t.Fatalf("Error parsing completion date: %s", err.Error())
+		}
+		c, err := b.SubmitCompletion(ctx, memberID, types.Completion{MemberID: memberID, RequirementID: requirementID, CompletedDate: completed})
+		if err != nil {
+			t.Fatalf("Error submitting completion for TestQualificationStatus: %s", err.Error())
+		}
+		if _, err = b.ReviewCompletion(ctx, certifier.ID, c.ID, true, ""); err != nil {
+			t.Fatalf("Error approving completion for TestQualificationStatus: %s", err.Error())
+		}
+	}
+	addMember := func() types.Member {
+		m, err := b.AddMember(ctx, testutils.RandomMember(false))
+		if err != nil {
+			t.Fatalf("Error adding member for TestQualificationStatus: %s", err.Error())
+		}
+		if err = b.AssignMemberQualification(ctx, "", m.ID, forklift.ID); err != nil {
+			t.Fatalf("Error assigning qualification for TestQualificationStatus: %s", err.Error())
+		}
+		return m
+	}
+
+	qualified := addMember()
+	complete(qualified.ID, vehicleInitial.ID, "1999-06-01")
+	complete(qualified.ID, forkliftInitial.ID, "1999-07-01")
+
+	lapsedPrerequisite := addMember()
+	complete(lapsedPrerequisite.ID, vehicleInitial.ID, "1998-01-01")
+	complete(lapsedPrerequisite.ID, forkliftInitial.ID, "1999-07-01")
+
+	recertified := addMember()
+	complete(recertified.ID, vehicleInitial.ID, "1998-01-01")
+	complete(recertified.ID, vehicleRecurring.ID, "1999-10-01")
+	complete(recertified.ID, forkliftInitial.ID, "1999-07-01")
+
+	inTraining := addMember()
+	complete(inTraining.ID, vehicleInitial.ID, "1999-06-01")
+
+	neverRefreshed := addMember()
+	if err = b.AssignMemberQualification(ctx, "", neverRefreshed.ID, refresher.ID); err != nil {
+		t.Fatalf("Error assigning qualification for TestQualificationStatus: %s", err.Error())
+	}
+
+	tc := []struct {
+		Name                string
+		MemberID            string
+		QualificationID     string
+		ExpectedState       types.QualificationState
+		ExpectedExpiration  string
+		LapsedPrerequisites []string
+	}{
+		{
+			Name:               "Qualified",
+			MemberID:           qualified.ID,
+			ExpectedState:      types.StateQualified,
+			ExpectedExpiration: "2000-05-31",
+		},
+		{
+			Name:                "Prerequisite lapsed",
+			MemberID:            lapsedPrerequisite.ID,
+			ExpectedState:       types.StatePrerequisiteLapsed,
+			LapsedPrerequisites: []string{vehicleOperator.ID},
+		},
+		{
+			Name:               "Recurring requirement renewed",
+			MemberID:           recertified.ID,
+			ExpectedState:      types.StateQualified,
+			ExpectedExpiration: "2000-09-30",
+		},
+		{
+			Name:          "Initial requirement missing",
+			MemberID:      inTraining.ID,
+			ExpectedState: types.StateInTraining,
+		},
+		{
+			Name:            "Recurring requirement never completed",
+			MemberID:        neverRefreshed.ID,
+			QualificationID: refresher.ID,
+			ExpectedState:   types.StateInTraining,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.Name, func(t *testing.T) {
+			qualificationID := tt.QualificationID
+			if qualificationID == "" {
+				qualificationID = forklift.ID
+			}
+			status, err := b.GetMemberQualificationStatus(ctx, tt.MemberID, qualificationID)
+			if err != nil {
+				t.Fatalf("Expected no error but got: %s", err.Error())
+			}
+			if status.State != tt.ExpectedState {
+				t.Errorf("Expected state: %s, got: %s", tt.ExpectedState, status.State)
+			}
+			if !slices.Equal(status.LapsedPrerequisites, tt.LapsedPrerequisites) {
+				t.Errorf("Expected lapsed prerequisites: %v, got: %v", tt.LapsedPrerequisites, status.LapsedPrerequisites)
+			}
+			if tt.ExpectedExpiration != "" && status.Expires.Format(time.DateOnly) != tt.ExpectedExpiration {
+				t.Errorf("Expected expiration: %s, got: %s", tt.ExpectedExpiration, status.Expires.Format(time.DateOnly))
+			}
+		})
+	}
+
+	statuses, err := b.GetMemberQualificationStatuses(ctx, qualified.ID)
+	if err != nil {
+		t.Fatalf("Error getting qualification statuses: %s", err.Error())
+	}
+	if len(statuses) != 1 || statuses[0].QualificationID != forklift.ID {
+		t.Errorf("Expected status for the assigned qualification only, got: %+v", statuses)
+	}
+	if _, err = b.GetMemberQualificationStatus(ctx, qualified.ID, vehicleOperator.ID); !errors.Is(err, backend.ErrMemberQualificationNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMemberQualificationNotFound, err)
+	}
+}
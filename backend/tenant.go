@@ -0,0 +1,79 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+)
+
+// WithTenants enables multi-tenant mode. The backend keeps working against the providers it was created with, which
+// should be scoped to types.DefaultTenantID, until ForTenant scopes it to another tenant.
+func (b Backend) WithTenants(tenantProvider TenantProvider) Backend {
+	b.tenantProvider = tenantProvider
+	return b
+}
+
+// ForTenant returns a backend that only sees the given tenant's data. Single tenant backends are returned unchanged.
+func (b Backend) ForTenant(tenantID string) Backend {
+	if b.tenantProvider == nil || tenantID == b.tenantID {
+		return b
+	}
+	b.memberProvider, b.qualificationProvider, b.requirementProvider = b.tenantProvider.Scoped(tenantID)
+	b.tenantID = tenantID
+	b.logger = b.logger.With(slog.String("tenant_id", tenantID))
+	return b
+}
+
+// TenantID is the tenant the backend is scoped to.
+func (b Backend) TenantID() string {
+	return b.tenantID
+}
+
+// AddTenant creates a tenant along with its first admin, who manages the tenant's members and roles the same way the
+// admins of a single tenant deployment do. Admins of one tenant have no access to any other.
+func (b Backend) AddTenant(ctx context.Context, t types.Tenant, admin types.Member) (types.Tenant, types.Member, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Adding tenant", slog.String("name", t.Name), slog.String("subdomain", t.Subdomain))
+	if b.tenantProvider == nil {
+		return types.Tenant{}, types.Member{}, ErrTenantsNotEnabled
+	}
+	if t.Name == "" {
+		return types.Tenant{}, types.Member{}, MissingArgsError{Fields: []string{"Name"}}
+	}
+	if !t.ValidSubdomain() {
+		b.logger.LogAttrs(ctx, slog.LevelInfo, "Invalid tenant subdomain", slog.String("subdomain", t.Subdomain))
+		return types.Tenant{}, types.Member{}, fmt.Errorf("%w: %q", ErrInvalidTenant, t.Subdomain)
+	}
+	t.ID = uuid.NewString()
+	admin.Role = types.RoleAdmin
+	admin, err := b.newMember(ctx, admin)
+	if err != nil {
+		return types.Tenant{}, types.Member{}, err
+	}
+	if err = b.tenantProvider.AddTenant(ctx, t, admin); err != nil {
+		return types.Tenant{}, types.Member{}, err
+	}
+	return t, admin, nil
+}
+
+func (b Backend) GetTenant(ctx context.Context, id string) (types.Tenant, error) {
+	if b.tenantProvider == nil {
+		return types.Tenant{}, ErrTenantsNotEnabled
+	}
+	return b.tenantProvider.GetTenant(ctx, id)
+}
+
+func (b Backend) GetTenantBySubdomain(ctx context.Context, subdomain string) (types.Tenant, error) {
+	if b.tenantProvider == nil {
+		return types.Tenant{}, ErrTenantsNotEnabled
+	}
+	return b.tenantProvider.GetTenantBySubdomain(ctx, subdomain)
+}
+
+func (b Backend) GetTenants(ctx context.Context) ([]types.Tenant, error) {
+	if b.tenantProvider == nil {
+		return nil, ErrTenantsNotEnabled
+	}
+	return b.tenantProvider.GetTenants(ctx)
+}
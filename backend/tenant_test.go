@@ -0,0 +1,281 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTenants(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+
+	if _, _, err = b.AddTenant(ctx, types.Tenant{Name: "62nd APS", Subdomain: "62aps"}, testutils.RandomMember(false)); !errors.Is(err, backend.ErrTenantsNotEnabled) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrTenantsNotEnabled, err)
+	}
+	b = b.WithTenants(provider)
+	if b.TenantID() != types.DefaultTenantID {
+		t.Errorf("Expected backend to start scoped to %s, got: %s", types.DefaultTenantID, b.TenantID())
+	}
+
+	admin := testutils.RandomMember(false)
+	password := admin.Password
+	tenant, tenantAdmin, err := b.AddTenant(ctx, types.Tenant{Name: "62nd APS", Subdomain: "62aps"}, admin)
+	if err != nil {
+		t.Fatalf("Error adding tenant: %s", err.Error())
+	}
+	if !tenantAdmin.Admin || tenantAdmin.Role != types.RoleAdmin {
+		t.Errorf("Expected tenant's first member to be an admin, got: %+v", tenantAdmin)
+	}
+	if _, _, err = b.AddTenant(ctx, types.Tenant{Name: "Duplicate", Subdomain: "62aps"}, testutils.RandomMember(false)); !errors.Is(err, backend.ErrDuplicateTenant) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrDuplicateTenant, err)
+	}
+	if _, _, err = b.AddTenant(ctx, types.Tenant{Name: "Bad", Subdomain: "62.aps"}, testutils.RandomMember(false)); !errors.Is(err, backend.ErrInvalidTenant) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrInvalidTenant, err)
+	}
+	if _, _, err = b.AddTenant(ctx, types.Tenant{Name: "Weak", Subdomain: "weak"}, types.Member{}); !errors.Is(err, backend.ErrMissingArgs) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMissingArgs, err)
+	}
+	if _, err = b.GetTenantBySubdomain(ctx, "weak"); !errors.Is(err, backend.ErrTenantNotFound) {
+		t.Errorf("Expected a tenant whose admin failed validation not to exist, got: %v", err)
+	}
+	if found, err := b.GetTenantBySubdomain(ctx, "62aps"); err != nil || found != tenant {
+		t.Errorf("Expected tenant %+v, got: %+v, %v", tenant, found, err)
+	}
+	tenants, err := b.GetTenants(ctx)
+	if err != nil || len(tenants) != 2 {
+		t.Errorf("Expected the default and new tenants, got: %+v, %v", tenants, err)
+	}
+
+	// Tenant A is the default tenant and gets one of everything
+	a := b
+	other := b.ForTenant(tenant.ID)
+	if _, err = other.Login(ctx, admin.Username, password); err != nil {
+		t.Errorf("Expected tenant admin to log in to their tenant, got: %s", err.Error())
+	}
+	if _, err = a.Login(ctx, admin.Username, password); err == nil {
+		t.Errorf("Expected tenant admin not to log in to the default tenant")
+	}
+	member := testutils.RandomMember(false)
+	member.CertificateID = "1234567890@mil"
+	memberPassword := member.Password
+	member, err = a.AddMember(ctx, member)
+	if err != nil {
+		t.Fatalf("Error adding member for TestTenants: %s", err.Error())
+	}
+	certifier, err := a.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestTenants: %s", err.Error())
+	}
+	qual, err := a.AddQualification(ctx, testutils.RandomQualification())
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestTenants: %s", err.Error())
+	}
+	reference, err := a.AddReference(ctx, testutils.RandomReference())
+	if err != nil {
+		t.Fatalf("Error adding reference for TestTenants: %s", err.Error())
+	}
+	req, err := a.AddRequirement(ctx, testutils.RandomRequirement(reference))
+	if err != nil {
+		t.Fatalf("Error adding requirement for TestTenants: %s", err.Error())
+	}
+	if err = a.AssignMemberQualification(ctx, "", member.ID, qual.ID); err != nil {
+		t.Fatalf("Error assigning qualification for TestTenants: %s", err.Error())
+	}
+	if err = a.AddCertifier(ctx, req.ID, certifier.ID); err != nil {
+		t.Fatalf("Error adding certifier for TestTenants: %s", err.Error())
+	}
+	completion, err := a.SubmitCompletion(ctx, member.ID, types.Completion{MemberID: member.ID, RequirementID: req.ID, CompletedDate: time.Now()})
+	if err != nil {
+		t.Fatalf("Error submitting completion for TestTenants: %s", err.Error())
+	}
+	waiver, err := a.GrantWaiver(ctx, certifier.ID, types.Waiver{MemberID: member.ID, QualificationID: qual.ID, Reason: "Deployed", End: time.Now().Add(types.Day)})
+	if err != nil {
+		t.Fatalf("Error granting waiver for TestTenants: %s", err.Error())
+	}
+	position, err := a.AddDutyPosition(ctx, types.DutyPosition{Name: "Ramp", Qualifications: []string{qual.ID}})
+	if err != nil {
+		t.Fatalf("Error adding duty position for TestTenants: %s", err.Error())
+	}
+	unit, err := a.AddUnit(ctx, types.Unit{Name: "1st APS", Kind: types.UnitSquadron, MandatoryQualifications: []string{qual.ID}})
+	if err != nil {
+		t.Fatalf("Error adding unit for TestTenants: %s", err.Error())
+	}
+	if err = a.AddUnitAdmin(ctx, unit.ID, certifier.ID); err != nil {
+		t.Fatalf("Error adding unit admin for TestTenants: %s", err.Error())
+	}
+	profile, err := a.AddImportProfile(ctx, types.ImportProfile{Name: "Legacy", MemberColumn: "Username", RequirementColumn: "Course", DateColumn: "Completed"})
+	if err != nil {
+		t.Fatalf("Error adding import profile for TestTenants: %s", err.Error())
+	}
+	token, err := a.CreateAPIToken(ctx, member.ID, "sync", types.ScopeReadOnly)
+	if err != nil {
+		t.Fatalf("Error creating api token for TestTenants: %s", err.Error())
+	}
+	if _, err = a.UpdateRolePermissions(ctx, types.RoleMember, []types.Permission{types.PermViewReports}); err != nil {
+		t.Fatalf("Error updating role permissions for TestTenants: %s", err.Error())
+	}
+
+	// Nothing of tenant A's is visible from tenant B
+	members, err := other.GetAllMembers(ctx)
+	if err != nil || len(members) != 1 || members[0].ID != tenantAdmin.ID {
+		t.Errorf("Expected only the tenant admin, got: %+v, %v", members, err)
+	}
+	if _, err = other.GetMember(ctx, member.ID); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMemberNotFound, err)
+	}
+	if _, err = other.Login(ctx, member.Username, memberPassword); err == nil {
+		t.Errorf("Expected member of tenant A not to log in to tenant B")
+	}
+	if _, err = other.LoginWithCertificate(ctx, member.CertificateID); err == nil {
+		t.Errorf("Expected certificate bound in tenant A not to log in to tenant B")
+	}
+	if _, _, err = other.AuthenticateAPIToken(ctx, token.Token); err == nil {
+		t.Errorf("Expected api token from tenant A not to authenticate in tenant B")
+	}
+	if quals, err := other.GetAllQualifications(ctx); err != nil || len(quals) != 0 {
+		t.Errorf("Expected no qualifications, got: %+v, %v", quals, err)
+	}
+	if _, err = other.GetQualification(ctx, qual.ID); !errors.Is(err, backend.ErrQualificationNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrQualificationNotFound, err)
+	}
+	if reqs, err := other.GetAllRequirements(ctx); err != nil || len(reqs) != 0 {
+		t.Errorf("Expected no requirements, got: %+v, %v", reqs, err)
+	}
+	if _, err = other.GetRequirement(ctx, req.ID); !errors.Is(err, backend.ErrRequirementNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrRequirementNotFound, err)
+	}
+	if refs, err := other.GetReferences(ctx); err != nil || len(refs) != 0 {
+		t.Errorf("Expected no references, got: %+v, %v", refs, err)
+	}
+	if _, err = other.GetReference(ctx, reference.ID); !errors.Is(err, backend.ErrReferenceNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrReferenceNotFound, err)
+	}
+	if _, err = other.GetCompletion(ctx, completion.ID); !errors.Is(err, backend.ErrCompletionNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrCompletionNotFound, err)
+	}
+	if _, err = other.GetWaiver(ctx, waiver.ID); !errors.Is(err, backend.ErrWaiverNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrWaiverNotFound, err)
+	}
+	if positions, err := other.GetDutyPositions(ctx); err != nil || len(positions) != 0 {
+		t.Errorf("Expected no duty positions, got: %+v, %v", positions, err)
+	}
+	if _, err = other.GetDutyPosition(ctx, position.ID); !errors.Is(err, backend.ErrDutyPositionNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrDutyPositionNotFound, err)
+	}
+	if units, err := other.GetUnits(ctx); err != nil || len(units) != 0 {
+		t.Errorf("Expected no units, got: %+v, %v", units, err)
+	}
+	if _, err = other.GetUnit(ctx, unit.ID); !errors.Is(err, backend.ErrUnitNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrUnitNotFound, err)
+	}
+	if admin, err := other.IsUnitAdmin(ctx, certifier.ID, unit.ID); err == nil && admin {
+		t.Errorf("Expected unit admin of tenant A not to administer anything in tenant B")
+	}
+	if profiles, err := other.GetImportProfiles(ctx); err != nil || len(profiles) != 0 {
+		t.Errorf("Expected no import profiles, got: %+v, %v", profiles, err)
+	}
+	if _, err = other.GetImportProfile(ctx, profile.ID); !errors.Is(err, backend.ErrImportProfileNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrImportProfileNotFound, err)
+	}
+	if entries, err := other.GetAuditEntries(ctx, "waiver", waiver.ID); err != nil || len(entries) != 0 {
+		t.Errorf("Expected no audit entries, got: %+v, %v", entries, err)
+	}
+	if archived, err := other.GetArchivedMembers(ctx); err != nil || len(archived) != 0 {
+		t.Errorf("Expected no archived members, got: %+v, %v", archived, err)
+	}
+	permissions, err := other.GetRolePermissions(ctx, types.RoleMember)
+	if err != nil || !slices.Equal(permissions, types.DefaultRolePermissions[types.RoleMember]) {
+		t.Errorf("Expected tenant B to keep the default member permissions, got: %v, %v", permissions, err)
+	}
+
+	// Nor can tenant B change any of it
+	otherMember, err := other.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestTenants: %s", err.Error())
+	}
+	if err = other.AssignMemberQualification(ctx, "", otherMember.ID, qual.ID); !errors.Is(err, backend.ErrQualificationNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrQualificationNotFound, err)
+	}
+	if err = other.AddCertifier(ctx, req.ID, otherMember.ID); !errors.Is(err, backend.ErrRequirementNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrRequirementNotFound, err)
+	}
+	if _, err = other.SetMemberUnit(ctx, otherMember.ID, unit.ID); !errors.Is(err, backend.ErrUnitNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrUnitNotFound, err)
+	}
+	if _, err = other.AddUnit(ctx, types.Unit{Name: "Flight", Kind: types.UnitFlight, ParentID: unit.ID}); !errors.Is(err, backend.ErrInvalidUnit) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrInvalidUnit, err)
+	}
+	if _, err = other.UpdateMember(ctx, types.Member{ApiMember: types.ApiMember{ID: member.ID, FirstName: "Changed"}}); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMemberNotFound, err)
+	}
+	if err = other.DeleteQualification(ctx, qual.ID, 0); !errors.Is(err, backend.ErrQualificationNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrQualificationNotFound, err)
+	}
+	if err = other.DeleteRequirement(ctx, req.ID, 0); !errors.Is(err, backend.ErrRequirementNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrRequirementNotFound, err)
+	}
+	if err = other.DeleteDutyPosition(ctx, position.ID, 0); !errors.Is(err, backend.ErrDutyPositionNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrDutyPositionNotFound, err)
+	}
+	if err = other.DeleteUnit(ctx, unit.ID, 0); !errors.Is(err, backend.ErrUnitNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrUnitNotFound, err)
+	}
+	if err = other.RevokeAPIToken(ctx, member.ID, token.ID); err == nil {
+		t.Errorf("Expected tenant B not to revoke tenant A's api token")
+	}
+	if _, err = other.ReviewCompletion(ctx, otherMember.ID, completion.ID, true, ""); !errors.Is(err, backend.ErrCompletionNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrCompletionNotFound, err)
+	}
+	if _, err = other.RevokeWaiver(ctx, otherMember.ID, waiver.ID, "Returned"); !errors.Is(err, backend.ErrWaiverNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrWaiverNotFound, err)
+	}
+
+	// Names only have to be unique within a tenant
+	duplicate := testutils.RandomMember(false)
+	duplicate.Username = member.Username
+	if _, err = other.AddMember(ctx, duplicate); err != nil {
+		t.Errorf("Expected username taken in tenant A to be free in tenant B, got: %s", err.Error())
+	}
+	if _, err = other.AddQualification(ctx, types.Qualification{Name: qual.Name}); err != nil {
+		t.Errorf("Expected qualification name taken in tenant A to be free in tenant B, got: %s", err.Error())
+	}
+
+	// Tenant A still has everything
+	if _, err = a.GetMember(ctx, member.ID); err != nil {
+		t.Errorf("Expected member to still exist in tenant A, got: %s", err.Error())
+	}
+	if held, err := a.GetMemberQualifications(ctx, member.ID); err != nil || len(held) != 1 {
+		t.Errorf("Expected member to still hold their qualification, got: %+v, %v", held, err)
+	}
+	if _, err = a.GetUnit(ctx, unit.ID); err != nil {
+		t.Errorf("Expected unit to still exist in tenant A, got: %s", err.Error())
+	}
+	if _, _, err = a.AuthenticateAPIToken(ctx, token.Token); err != nil {
+		t.Errorf("Expected api token to still authenticate in tenant A, got: %s", err.Error())
+	}
+}
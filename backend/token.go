@@ -0,0 +1,91 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+)
+
+const apiTokenPrefix = "portal_"
+
+// CreateAPIToken generates a new token for the member. The plaintext token is only available on the returned value,
+// the provider only ever sees its hash.
+func (b Backend) CreateAPIToken(ctx context.Context, memberID, name string, scope types.TokenScope) (types.APIToken, error) {
+	l := b.logger.With(slog.String("member_id", memberID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Creating api token", slog.String("name", name), slog.String("scope", string(scope)))
+	if name == "" {
+		return types.APIToken{}, MissingArgsError{Fields: []string{"Name"}}
+	}
+	if !scope.Valid() {
+		l.LogAttrs(ctx, slog.LevelInfo, "Invalid scope requested for api token")
+		return types.APIToken{}, fmt.Errorf("%w: %s", ErrInvalidTokenScope, scope)
+	}
+	member, err := b.memberProvider.GetMember(ctx, memberID, ById)
+	if err != nil {
+		return types.APIToken{}, err
+	}
+	if scope == types.ScopeAdmin && !member.Admin {
+		l.LogAttrs(ctx, slog.LevelWarn, "Non-admin member requested admin scoped api token")
+		return types.APIToken{}, ErrInsufficientPermissions
+	}
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Error generating api token", slog.String("error", err.Error()))
+		return types.APIToken{}, err
+	}
+	plaintext := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	t := types.APIToken{
+		ID:       uuid.NewString(),
+		MemberID: memberID,
+		Name:     name,
+		Scope:    scope,
+		Hash:     hashAPIToken(plaintext),
+		Created:  b.clock.Now(),
+	}
+	if err = b.memberProvider.AddAPIToken(ctx, t); err != nil {
+		return types.APIToken{}, err
+	}
+	t.Token = plaintext
+	return t, nil
+}
+
+func (b Backend) GetAPITokens(ctx context.Context, memberID string) ([]types.APIToken, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting api tokens for member", slog.String("member_id", memberID))
+	return b.memberProvider.GetAPITokens(ctx, memberID)
+}
+
+func (b Backend) RevokeAPIToken(ctx context.Context, memberID, tokenID string) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Revoking api token", slog.String("member_id", memberID), slog.String("token_id", tokenID))
+	return b.memberProvider.DeleteAPIToken(ctx, memberID, tokenID)
+}
+
+// AuthenticateAPIToken resolves a plaintext token to the token record and the member that owns it, recording the time
+// it was used.
+func (b Backend) AuthenticateAPIToken(ctx context.Context, token string) (types.APIToken, types.Member, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Authenticating api token")
+	t, err := b.memberProvider.GetAPITokenByHash(ctx, hashAPIToken(token))
+	if err != nil {
+		return types.APIToken{}, types.Member{}, ErrAuthenticationFailed
+	}
+	member, err := b.memberProvider.GetMember(ctx, t.MemberID, ById)
+	if err != nil || member.Archive != nil {
+		return types.APIToken{}, types.Member{}, ErrAuthenticationFailed
+	}
+	now := b.clock.Now()
+	t.LastUsed = &now
+	if err = b.memberProvider.UpdateAPITokenLastUsed(ctx, t.ID, now); err != nil {
+		b.logger.LogAttrs(ctx, slog.LevelWarn, "Unable to record api token usage", slog.String("error", err.Error()))
+	}
+	return t, member, nil
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,172 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCreateAndAuthenticateAPIToken(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, expireClock{})
+
+	admin, err := b.AddMember(ctx, testutils.RandomMember(true))
+	if err != nil {
+		t.Fatalf("Error adding member for TestCreateAndAuthenticateAPIToken: %s", err.Error())
+	}
+	member, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestCreateAndAuthenticateAPIToken: %s", err.Error())
+	}
+
+	tc := []struct {
+		Name          string
+		MemberID      string
+		TokenName     string
+		Scope         types.TokenScope
+		ExpectedError error
+	}{
+		{
+			Name:      "Read only token",
+			MemberID:  member.ID,
+			TokenName: "roster spreadsheet",
+			Scope:     types.ScopeReadOnly,
+		},
+		{
+			Name:      "Admin token",
+			MemberID:  admin.ID,
+			TokenName: "sync script",
+			Scope:     types.ScopeAdmin,
+		},
+		{
+			Name:          "Admin token for non-admin",
+			MemberID:      member.ID,
+			TokenName:     "sync script",
+			Scope:         types.ScopeAdmin,
+			ExpectedError: backend.ErrInsufficientPermissions,
+		},
+		{
+			Name:          "Invalid scope",
+			MemberID:      member.ID,
+			TokenName:     "sync script",
+			Scope:         "everything",
+			ExpectedError: backend.ErrInvalidTokenScope,
+		},
+		{
+			Name:          "Missing name",
+			MemberID:      member.ID,
+			Scope:         types.ScopeReadWrite,
+			ExpectedError: backend.ErrMissingArgs,
+		},
+		{
+			Name:          "Member not found",
+			MemberID:      uuid.NewString(),
+			TokenName:     "sync script",
+			Scope:         types.ScopeReadWrite,
+			ExpectedError: backend.ErrMemberNotFound,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.Name, func(t *testing.T) {
+			token, err := b.CreateAPIToken(ctx, tt.MemberID, tt.TokenName, tt.Scope)
+			if tt.ExpectedError != nil {
+				if !errors.Is(err, tt.ExpectedError) {
+					t.Errorf("Expected error: %s, got: %v", tt.ExpectedError.Error(), err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error but got: %s", err.Error())
+			}
+			if !strings.HasPrefix(token.Token, "portal_") {
+				t.Errorf("Expected plaintext token to be returned, got: %q", token.Token)
+			}
+			authenticated, m, err := b.AuthenticateAPIToken(ctx, token.Token)
+			if err != nil {
+				t.Fatalf("Error authenticating api token: %s", err.Error())
+			}
+			if m.ID != tt.MemberID || authenticated.ID != token.ID || authenticated.Scope != tt.Scope {
+				t.Errorf("Expected token %s for member %s, got token %s for member %s", token.ID, tt.MemberID, authenticated.ID, m.ID)
+			}
+			tokens, err := b.GetAPITokens(ctx, tt.MemberID)
+			if err != nil {
+				t.Fatalf("Error getting api tokens: %s", err.Error())
+			}
+			if len(tokens) != 1 || tokens[0].LastUsed == nil || !tokens[0].LastUsed.Equal(expireClock{}.Now()) {
+				t.Errorf("Expected 1 token with last used time recorded, got: %+v", tokens)
+			}
+			if tokens[0].Token != "" || tokens[0].Hash == token.Token {
+				t.Errorf("Expected stored token to be hashed")
+			}
+		})
+	}
+
+	if _, _, err = b.AuthenticateAPIToken(ctx, "portal_notarealtoken"); !errors.Is(err, backend.ErrAuthenticationFailed) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrAuthenticationFailed, err)
+	}
+}
+
+func TestRevokeAPIToken(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil)
+
+	member, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestRevokeAPIToken: %s", err.Error())
+	}
+	other, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestRevokeAPIToken: %s", err.Error())
+	}
+	token, err := b.CreateAPIToken(ctx, member.ID, "script", types.ScopeReadWrite)
+	if err != nil {
+		t.Fatalf("Error creating api token for TestRevokeAPIToken: %s", err.Error())
+	}
+
+	if err = b.RevokeAPIToken(ctx, other.ID, token.ID); !errors.Is(err, backend.ErrAPITokenNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrAPITokenNotFound, err)
+	}
+	if err = b.RevokeAPIToken(ctx, member.ID, token.ID); err != nil {
+		t.Errorf("Expected no error revoking token but got: %s", err.Error())
+	}
+	if _, _, err = b.AuthenticateAPIToken(ctx, token.Token); !errors.Is(err, backend.ErrAuthenticationFailed) {
+		t.Errorf("Expected revoked token to fail authentication, got: %v", err)
+	}
+	if err = b.RevokeAPIToken(ctx, member.ID, token.ID); !errors.Is(err, backend.ErrAPITokenNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrAPITokenNotFound, err)
+	}
+}
@@ -0,0 +1,473 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// transferPackageLifetime is how long an exported package can be imported for.
+var transferPackageLifetime = 30 * types.Day
+
+// ExportMemberTransfer bundles the member's profile, assigned qualifications with their history and approved
+// completions into a package signed as this instance.
+func (b Backend) ExportMemberTransfer(ctx context.Context, actorID, memberID string) (types.SignedTransferPackage, error) {
+	l := b.logger.With(slog.String("member_id", memberID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Exporting member transfer package", slog.String("actor_id", actorID))
+	if b.config.InstanceName == "" {
+		l.LogAttrs(ctx, slog.LevelWarn, "No instance name configured to sign transfer packages as")
+		return types.SignedTransferPackage{}, ErrTransferKeyNotConfigured
+	}
+	key, err := b.transferKey(ctx, b.config.InstanceName)
+	if err != nil {
+		return types.SignedTransferPackage{}, err
+	}
+	m, err := b.memberProvider.GetMember(ctx, memberID, ById)
+	if err != nil {
+		return types.SignedTransferPackage{}, err
+	}
+	origins, err := b.memberProvider.GetMemberTransferOrigins(ctx, memberID)
+	if err != nil {
+		return types.SignedTransferPackage{}, err
+	}
+	now := b.clock.Now()
+	pkg := types.TransferPackage{
+		Version:        types.TransferPackageVersion,
+		ID:             uuid.NewString(),
+		Issuer:         b.config.InstanceName,
+		Exported:       now,
+		Expires:        now.Add(transferPackageLifetime),
+		Member:         m.ToApiMember(),
+		MemberIDs:      origins,
+		Qualifications: []types.Qualification{},
+		Requirements:   []types.Requirement{},
+		Completions:    []types.Completion{},
+		History:        []types.QualificationEvent{},
+	}
+	// Where the member sits at the losing unit means nothing at the gaining one
+	pkg.Member.SupervisorID = ""
+	pkg.Member.UnitID = ""
+	pkg.Member.Archive = nil
+
+	requirements := map[string]bool{}
+	addRequirement := func(r types.Requirement) {
+		if !requirements[r.ID] {
+			requirements[r.ID] = true
+			pkg.Requirements = append(pkg.Requirements, r)
+		}
+	}
+	if pkg.Qualifications, err = b.memberProvider.GetMemberQualifications(ctx, memberID); err != nil {
+		return types.SignedTransferPackage{}, err
+	}
+	for _, q := range pkg.Qualifications {
+		for _, r := range append(q.InitialRequirements, q.RecurringRequirements...) {
+			addRequirement(r)
+		}
+		history, err := b.memberProvider.GetQualificationHistory(ctx, memberID, q.ID)
+		if err != nil {
+			return types.SignedTransferPackage{}, err
+		}
+		pkg.History = append(pkg.History, history...)
+	}
+	completions, err := b.requirementProvider.GetMemberCompletions(ctx, memberID)
+	if err != nil {
+		return types.SignedTransferPackage{}, err
+	}
+	for _, c := range completions {
+		if c.Status != types.CompletionApproved {
+			continue
+		}
+		if !requirements[c.RequirementID] {
+			r, err := b.requirementProvider.GetRequirement(ctx, c.RequirementID)
+			if err != nil {
+				return types.SignedTransferPackage{}, err
+			}
+			addRequirement(r)
+		}
+		pkg.Completions = append(pkg.Completions, c)
+	}
+
+	raw, err := json.Marshal(pkg)
+	if err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Error serializing transfer package", slog.String("error", err.Error()))
+		return types.SignedTransferPackage{}, err
+	}
+	if err = b.audit(ctx, actorID, "transfer_export", AuditEntityMember, memberID, fmt.Sprintf("Exported %d completions", len(pkg.Completions))); err != nil {
+		return types.SignedTransferPackage{}, err
+	}
+	return types.SignedTransferPackage{Package: raw, Signature: signTransfer(key, raw)}, nil
+}
+
+// ImportMemberTransfer verifies a package against its issuer's key and brings the member and their records in under new
+// IDs. Requirements, references and qualifications are matched to existing definitions by name and created when there's
+// no match. A member coming back to an instance that archived them has that record restored; importing over an active
+// member fails with ErrMemberNotArchived. Each package can be imported once, until it expires.
+func (b Backend) ImportMemberTransfer(ctx context.Context, actorID string, signed types.SignedTransferPackage) (types.TransferImportReport, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Importing member transfer package", slog.String("actor_id", actorID))
+	var pkg types.TransferPackage
+	if err := json.Unmarshal(signed.Package, &pkg); err != nil {
+		b.logger.LogAttrs(ctx, slog.LevelWarn, "Unable to parse transfer package", slog.String("error", err.Error()))
+		return types.TransferImportReport{}, fmt.Errorf("%w: %w", ErrInvalidTransfer, err)
+	}
+	l := b.logger.With(slog.String("issuer", pkg.Issuer), slog.String("member_id", pkg.Member.ID))
+	key, err := b.transferKey(ctx, pkg.Issuer)
+	if err != nil {
+		return types.TransferImportReport{}, err
+	}
+	signature, err := hex.DecodeString(signed.Signature)
+	if err != nil || !hmac.Equal(signature, transferMAC(key, signed.Package)) {
+		l.LogAttrs(ctx, slog.LevelWarn, "Transfer package signature doesn't match")
+		return types.TransferImportReport{}, ErrInvalidTransferSignature
+	}
+	if pkg.Version != types.TransferPackageVersion {
+		return types.TransferImportReport{}, fmt.Errorf("%w: unsupported version %d", ErrInvalidTransfer, pkg.Version)
+	}
+	if _, err = uuid.Parse(pkg.ID); err != nil {
+		return types.TransferImportReport{}, fmt.Errorf("%w: invalid package id %s", ErrInvalidTransfer, pkg.ID)
+	}
+	if _, err = uuid.Parse(pkg.Member.ID); err != nil {
+		return types.TransferImportReport{}, fmt.Errorf("%w: invalid member id %s", ErrInvalidTransfer, pkg.Member.ID)
+	}
+	if !b.clock.Now().Before(pkg.Expires) {
+		l.LogAttrs(ctx, slog.LevelWarn, "Transfer package has expired", slog.Time("expires", pkg.Expires))
+		return types.TransferImportReport{}, fmt.Errorf("%w: package expired %s", ErrInvalidTransfer, pkg.Expires.Format(time.DateOnly))
+	}
+	imported, err := b.memberProvider.IsTransferImported(ctx, pkg.ID)
+	if err != nil {
+		return types.TransferImportReport{}, err
+	} else if imported {
+		l.LogAttrs(ctx, slog.LevelWarn, "Transfer package was already imported", slog.String("package_id", pkg.ID))
+		return types.TransferImportReport{}, fmt.Errorf("%w: package_id=%s", ErrTransferAlreadyImported, pkg.ID)
+	}
+
+	report := types.TransferImportReport{
+		Issuer:                pkg.Issuer,
+		CreatedReferences:     []string{},
+		MatchedRequirements:   []string{},
+		CreatedRequirements:   []string{},
+		MatchedQualifications: []string{},
+		CreatedQualifications: []string{},
+	}
+	transfer := types.TransferImport{PackageID: pkg.ID, Issuer: pkg.Issuer, IssuerMemberID: pkg.Member.ID}
+	if transfer.Member, transfer.Returning, report.TemporaryPassword, err = b.transferredMember(ctx, pkg); err != nil {
+		return types.TransferImportReport{}, err
+	}
+	// Definitions created for the package go away again if the import fails
+	err = b.inTx(ctx, func(tx Backend) error {
+		requirements, err := tx.resolveTransferRequirements(ctx, pkg.Requirements, &report)
+		if err != nil {
+			return err
+		}
+		qualifications, err := tx.resolveTransferQualifications(ctx, pkg.Qualifications, requirements, &report)
+		if err != nil {
+			return err
+		}
+		for _, q := range pkg.Qualifications {
+			transfer.QualificationIDs = append(transfer.QualificationIDs, qualifications[q.ID])
+		}
+		// A returning member brings back the records they left with, which only match what's here by their contents
+		var completed []types.Completion
+		var history []types.QualificationEvent
+		if transfer.Returning {
+			if completed, err = tx.requirementProvider.GetMemberCompletions(ctx, transfer.Member.ID); err != nil {
+				return err
+			}
+			for _, id := range transfer.QualificationIDs {
+				events, err := tx.memberProvider.GetQualificationHistory(ctx, transfer.Member.ID, id)
+				if err != nil {
+					return err
+				}
+				history = append(history, events...)
+			}
+		}
+		for _, c := range pkg.Completions {
+			r, ok := requirements[c.RequirementID]
+			if !ok || c.Status != types.CompletionApproved {
+				continue
+			}
+			if slices.ContainsFunc(completed, func(local types.Completion) bool {
+				return local.RequirementID == r.ID && local.CompletedDate.Equal(c.CompletedDate)
+			}) {
+				continue
+			}
+			c.ID = uuid.NewString()
+			c.MemberID = transfer.Member.ID
+			c.RequirementID = r.ID
+			// Trainers and certifiers from the losing unit are only kept if they're known here too
+			if c.TrainerID != "" && !tx.memberExists(ctx, c.TrainerID) {
+				c.TrainerID = ""
+			}
+			if c.CertifierID != "" && !tx.memberExists(ctx, c.CertifierID) {
+				c.CertifierID = ""
+			}
+			transfer.Completions = append(transfer.Completions, c)
+		}
+		for _, e := range pkg.History {
+			id, ok := qualifications[e.QualificationID]
+			if !ok {
+				continue
+			}
+			if slices.ContainsFunc(history, func(local types.QualificationEvent) bool {
+				return local.QualificationID == id && local.Kind == e.Kind && local.Time.Equal(e.Time)
+			}) {
+				continue
+			}
+			e.ID = uuid.NewString()
+			e.MemberID = transfer.Member.ID
+			e.QualificationID = id
+			transfer.History = append(transfer.History, e)
+		}
+		if err = tx.memberProvider.ImportTransfer(ctx, transfer); err != nil {
+			return err
+		}
+		report.Member = transfer.Member.ToApiMember()
+		report.Returning = transfer.Returning
+		report.ImportedCompletions = len(transfer.Completions)
+		report.ImportedHistoryEntries = len(transfer.History)
+		if err = tx.audit(ctx, actorID, "transfer_import", AuditEntityMember, transfer.Member.ID, fmt.Sprintf("Imported from %s", pkg.Issuer)); err != nil {
+			return err
+		}
+		return tx.recordStatusChanges(ctx, transfer.Member.ID)
+	})
+	if err != nil {
+		return types.TransferImportReport{}, err
+	}
+	l.LogAttrs(ctx, slog.LevelInfo, "Imported member transfer package", slog.Int("completions", report.ImportedCompletions))
+	return report, nil
+}
+
+// transferredMember works out the member record to import, which is new unless the member has been here before. Roles
+// never transfer, the gaining unit assigns them.
+func (b Backend) transferredMember(ctx context.Context, pkg types.TransferPackage) (types.Member, bool, string, error) {
+	incoming := pkg.Member
+	existing, err := b.returningMember(ctx, pkg)
+	if err == nil {
+		if existing.Archive == nil {
+			return types.Member{}, false, "", fmt.Errorf("%w: member_id=%s", ErrMemberNotArchived, existing.ID)
+		}
+		// Promotions and name changes at the losing unit come along, everything else stays as it was here
+		update := types.Member{}
+		update.FirstName, update.LastName, update.Rank, update.Email = incoming.FirstName, incoming.LastName, incoming.Rank, incoming.Email
+		m := existing.MergeIn(update)
+		m.Archive = nil
+		return m, true, "", nil
+	} else if !errors.Is(err, ErrMemberNotFound) {
+		return types.Member{}, false, "", err
+	}
+	m := types.Member{ApiMember: incoming}
+	m.ID = uuid.NewString()
+	m.SupervisorID = ""
+	m.UnitID = ""
+	m.Archive = nil
+	m.Role = types.RoleMember
+	m.Admin = false
+	password, err := temporaryPassword()
+	if err != nil {
+		return types.Member{}, false, "", err
+	}
+	m.Password = password
+	if err = CheckMemberForMissingArgs(m); err != nil {
+		return types.Member{}, false, "", fmt.Errorf("%w: %w", ErrInvalidTransfer, err)
+	}
+	if m.CertificateID != "" {
+		if _, err = b.memberProvider.GetMember(ctx, m.CertificateID, ByCertificateID); err == nil {
+			b.logger.LogAttrs(ctx, slog.LevelWarn, "Transferred certificate is already bound here, leaving it unbound")
+			m.CertificateID = ""
+		}
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.config.BcryptCost)
+	if err != nil {
+		return types.Member{}, false, "", err
+	}
+	m.Hash = string(hash)
+	m.Password = ""
+	return m, false, password, nil
+}
+
+// returningMember finds the member's record here by their ID in a package this instance issued, their ID here that the
+// issuer passed along, or their ID at the issuer if they were transferred in from it before.
+func (b Backend) returningMember(ctx context.Context, pkg types.TransferPackage) (types.Member, error) {
+	var ids []string
+	if b.config.InstanceName != "" && pkg.Issuer == b.config.InstanceName {
+		ids = append(ids, pkg.Member.ID)
+	}
+	if id, ok := pkg.MemberIDs[b.config.InstanceName]; ok && b.config.InstanceName != "" {
+		ids = append(ids, id)
+	}
+	id, err := b.memberProvider.GetTransferredMemberID(ctx, pkg.Issuer, pkg.Member.ID)
+	if err == nil {
+		ids = append(ids, id)
+	} else if !errors.Is(err, ErrMemberNotFound) {
+		return types.Member{}, err
+	}
+	for _, id := range ids {
+		m, err := b.memberProvider.GetMember(ctx, id, ById)
+		if !errors.Is(err, ErrMemberNotFound) {
+			return m, err
+		}
+	}
+	return types.Member{}, ErrMemberNotFound
+}
+
+// resolveTransferRequirements maps each requirement in the package, keyed by its ID at the issuer, to a local one with
+// the same name, creating it along with its reference when there isn't one.
+func (b Backend) resolveTransferRequirements(ctx context.Context, incoming []types.Requirement, report *types.TransferImportReport) (map[string]types.Requirement, error) {
+	existing, err := b.requirementProvider.GetAllRequirements(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]types.Requirement{}
+	for _, r := range existing {
+		byName[normalizeName(r.Name)] = r
+	}
+	references, err := b.requirementProvider.GetReferences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolved := map[string]types.Requirement{}
+	for _, r := range incoming {
+		if local, ok := byName[normalizeName(r.Name)]; ok {
+			resolved[r.ID] = local
+			report.MatchedRequirements = append(report.MatchedRequirements, local.Name)
+			continue
+		}
+		reference, created, err := b.resolveTransferReference(ctx, r.Reference, &references)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			report.CreatedReferences = append(report.CreatedReferences, reference.Name)
+		}
+		local := r
+		local.ID = uuid.NewString()
+		local.Reference = reference
+		if local, err = b.AddRequirement(ctx, local); err != nil {
+			return nil, fmt.Errorf("%w: requirement %s: %w", ErrInvalidTransfer, r.Name, err)
+		}
+		byName[normalizeName(local.Name)] = local
+		resolved[r.ID] = local
+		report.CreatedRequirements = append(report.CreatedRequirements, local.Name)
+	}
+	return resolved, nil
+}
+
+// resolveTransferReference finds a local reference to the same publication, volume and paragraph or creates one.
+func (b Backend) resolveTransferReference(ctx context.Context, incoming types.Reference, references *[]types.Reference) (types.Reference, bool, error) {
+	for _, r := range *references {
+		if normalizeName(r.Name) == normalizeName(incoming.Name) && r.Volume == incoming.Volume && r.Paragraph == incoming.Paragraph {
+			return r, false, nil
+		}
+	}
+	created, err := b.AddReference(ctx, incoming)
+	if err != nil {
+		return types.Reference{}, false, fmt.Errorf("%w: reference %s: %w", ErrInvalidTransfer, incoming.Name, err)
+	}
+	*references = append(*references, created)
+	return created, true, nil
+}
+
+// resolveTransferQualifications maps each qualification in the package, keyed by its ID at the issuer, to the ID of a
+// local one with the same name, creating it when there isn't one. Prerequisites of created qualifications are kept when
+// they were also transferred.
+func (b Backend) resolveTransferQualifications(ctx context.Context, incoming []types.Qualification, requirements map[string]types.Requirement, report *types.TransferImportReport) (map[string]string, error) {
+	existing, err := b.qualificationProvider.GetAllQualifications(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]string{}
+	for _, q := range existing {
+		byName[normalizeName(q.Name)] = q.ID
+	}
+	resolved := map[string]string{}
+	var created []types.Qualification
+	for _, q := range incoming {
+		if id, ok := byName[normalizeName(q.Name)]; ok {
+			resolved[q.ID] = id
+			report.MatchedQualifications = append(report.MatchedQualifications, q.Name)
+			continue
+		}
+		local := q
+		local.ID = uuid.NewString()
+		local.InitialRequirements = localRequirements(q.InitialRequirements, requirements)
+		local.RecurringRequirements = localRequirements(q.RecurringRequirements, requirements)
+		local.Prerequisites = nil
+		if err = CheckQualificationForMissingArgs(local); err != nil {
+			return nil, fmt.Errorf("%w: qualification %s: %w", ErrInvalidTransfer, q.Name, err)
+		}
+		// Prerequisites are added once everything exists, since they can point at each other in any order
+		if err = b.qualificationProvider.AddQualification(ctx, local); err != nil {
+			return nil, err
+		}
+		byName[normalizeName(local.Name)] = local.ID
+		resolved[q.ID] = local.ID
+		created = append(created, q)
+		report.CreatedQualifications = append(report.CreatedQualifications, local.Name)
+	}
+	for _, q := range created {
+		var prerequisites []string
+		for _, id := range q.Prerequisites {
+			if localID, ok := resolved[id]; ok {
+				prerequisites = append(prerequisites, localID)
+			}
+		}
+		if len(prerequisites) == 0 {
+			continue
+		}
+		local, err := b.qualificationProvider.GetQualification(ctx, resolved[q.ID])
+		if err != nil {
+			return nil, err
+		}
+		local.Prerequisites = prerequisites
+		if err = b.qualificationProvider.UpdateQualification(ctx, local); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+func localRequirements(incoming []types.Requirement, requirements map[string]types.Requirement) []types.Requirement {
+	local := make([]types.Requirement, 0, len(incoming))
+	for _, r := range incoming {
+		if l, ok := requirements[r.ID]; ok {
+			local = append(local, l)
+		}
+	}
+	return local
+}
+
+func (b Backend) memberExists(ctx context.Context, id string) bool {
+	_, err := b.memberProvider.GetMember(ctx, id, ById)
+	return err == nil
+}
+
+// transferKey is the key shared with issuer, preferring one set up for that instance over the shared key.
+func (b Backend) transferKey(ctx context.Context, issuer string) ([]byte, error) {
+	if key, ok := b.config.TransferKeys[issuer]; ok && key != "" {
+		return []byte(key), nil
+	}
+	if b.config.TransferKey != "" {
+		return []byte(b.config.TransferKey), nil
+	}
+	b.logger.LogAttrs(ctx, slog.LevelWarn, "No transfer key configured", slog.String("issuer", issuer))
+	return nil, fmt.Errorf("%w: %s", ErrTransferKeyNotConfigured, issuer)
+}
+
+func transferMAC(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func signTransfer(key, data []byte) string {
+	return hex.EncodeToString(transferMAC(key, data))
+}
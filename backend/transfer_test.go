@@ -0,0 +1,228 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func newTransferBackend(t *testing.T, logger *slog.Logger, config backend.Config, clock backend.Clock) backend.Backend {
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	config.BcryptCost = bcrypt.MinCost
+	return backend.New(logger, provider, provider, provider, config, clock).WithTenants(provider)
+}
+
+func TestMemberTransfer(t *testing.T) {
+	ctx := context.Background()
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	losing := newTransferBackend(t, logger, backend.Config{InstanceName: "losing", TransferKey: "shared"}, expireClock{})
+	gaining := newTransferBackend(t, logger, backend.Config{InstanceName: "gaining", TransferKey: "shared"}, expireClock{})
+
+	admin, err := losing.AddMember(ctx, testutils.RandomMember(true))
+	if err != nil {
+		t.Fatalf("Error adding member for TestMemberTransfer: %s", err.Error())
+	}
+	newMember := testutils.RandomMember(false)
+	password := newMember.Password
+	member, err := losing.AddMember(ctx, newMember)
+	if err != nil {
+		t.Fatalf("Error adding member for TestMemberTransfer: %s", err.Error())
+	}
+	ref, err := losing.AddReference(ctx, testutils.RandomReference())
+	if err != nil {
+		t.Fatalf("Error adding reference for TestMemberTransfer: %s", err.Error())
+	}
+	shared, err := losing.AddRequirement(ctx, testutils.RandomRequirement(ref))
+	if err != nil {
+		t.Fatalf("Error adding requirement for TestMemberTransfer: %s", err.Error())
+	}
+	local, err := losing.AddRequirement(ctx, testutils.RandomRequirement(ref))
+	if err != nil {
+		t.Fatalf("Error adding requirement for TestMemberTransfer: %s", err.Error())
+	}
+	qual := testutils.RandomQualification()
+	qual.Expires = false
+	qual.ExpirationDays = 0
+	qual.InitialRequirements = []types.Requirement{shared, local}
+	if qual, err = losing.AddQualification(ctx, qual); err != nil {
+		t.Fatalf("Error adding qualification for TestMemberTransfer: %s", err.Error())
+	}
+	if err = losing.AssignMemberQualification(ctx, admin.ID, member.ID, qual.ID); err != nil {
+		t.Fatalf("Error assigning qualification for TestMemberTransfer: %s", err.Error())
+	}
+	for _, req := range []types.Requirement{shared, local} {
+		if err = losing.AddCertifier(ctx, req.ID, admin.ID); err != nil {
+			t.Fatalf("Error designating certifier for TestMemberTransfer: %s", err.Error())
+		}
+		c, err := losing.SubmitCompletion(ctx, member.ID, types.Completion{MemberID: member.ID, RequirementID: req.ID})
+		if err != nil {
+			t.Fatalf("Error submitting completion for TestMemberTransfer: %s", err.Error())
+		}
+		if _, err = losing.ReviewCompletion(ctx, admin.ID, c.ID, true, ""); err != nil {
+			t.Fatalf("Error approving completion for TestMemberTransfer: %s", err.Error())
+		}
+	}
+	losingCompletions, err := losing.GetMemberCompletions(ctx, member.ID)
+	if err != nil {
+		t.Fatalf("Error getting completions for TestMemberTransfer: %s", err.Error())
+	}
+
+	// The gaining unit already tracks one of the requirements under a slightly different spelling
+	gainingRef, err := gaining.AddReference(ctx, testutils.RandomReference())
+	if err != nil {
+		t.Fatalf("Error adding reference for TestMemberTransfer: %s", err.Error())
+	}
+	existing := testutils.RandomRequirement(gainingRef)
+	existing.Name = strings.ToUpper(shared.Name)
+	if existing, err = gaining.AddRequirement(ctx, existing); err != nil {
+		t.Fatalf("Error adding requirement for TestMemberTransfer: %s", err.Error())
+	}
+
+	signed, err := losing.ExportMemberTransfer(ctx, admin.ID, member.ID)
+	if err != nil {
+		t.Fatalf("Error exporting transfer package: %s", err.Error())
+	}
+
+	t.Run("Tampered package", func(t *testing.T) {
+		tampered := signed
+		tampered.Package = bytes.Replace(signed.Package, []byte(member.LastName), []byte("Tampered"), 1)
+		if _, err := gaining.ImportMemberTransfer(ctx, "", tampered); !errors.Is(err, backend.ErrInvalidTransferSignature) {
+			t.Errorf("Expected ErrInvalidTransferSignature, got %v", err)
+		}
+	})
+
+	t.Run("Unknown issuer", func(t *testing.T) {
+		other := newTransferBackend(t, logger, backend.Config{InstanceName: "other", TransferKeys: map[string]string{"elsewhere": "shared"}}, expireClock{})
+		if _, err := other.ImportMemberTransfer(ctx, "", signed); !errors.Is(err, backend.ErrTransferKeyNotConfigured) {
+			t.Errorf("Expected ErrTransferKeyNotConfigured, got %v", err)
+		}
+	})
+
+	t.Run("Expired package", func(t *testing.T) {
+		later := expireClock{}.Now().Add(31 * types.Day)
+		other := newTransferBackend(t, logger, backend.Config{InstanceName: "other", TransferKey: "shared"}, movingClock{now: &later})
+		if _, err := other.ImportMemberTransfer(ctx, "", signed); !errors.Is(err, backend.ErrInvalidTransfer) {
+			t.Errorf("Expected ErrInvalidTransfer, got %v", err)
+		}
+	})
+
+	report, err := gaining.ImportMemberTransfer(ctx, "", signed)
+	if err != nil {
+		t.Fatalf("Error importing transfer package: %s", err.Error())
+	}
+	if report.Issuer != "losing" || report.Returning || report.TemporaryPassword == "" {
+		t.Errorf("Expected a new member from losing with a temporary password, got %+v", report)
+	}
+	if report.Member.ID == member.ID {
+		t.Errorf("Expected the member to get a new ID, got the issuer's %s", member.ID)
+	}
+	transferred := report.Member
+	if len(report.MatchedRequirements) != 1 || report.MatchedRequirements[0] != existing.Name {
+		t.Errorf("Expected %s to be matched, got %v", existing.Name, report.MatchedRequirements)
+	}
+	if len(report.CreatedRequirements) != 1 || report.CreatedRequirements[0] != local.Name {
+		t.Errorf("Expected %s to be created, got %v", local.Name, report.CreatedRequirements)
+	}
+	if len(report.CreatedQualifications) != 1 || report.ImportedCompletions != 2 || report.ImportedHistoryEntries == 0 {
+		t.Errorf("Expected one qualification, two completions and some history to be imported, got %+v", report)
+	}
+	if _, err = gaining.Login(ctx, member.Username, report.TemporaryPassword); err != nil {
+		t.Errorf("Expected the temporary password to log in, got %v", err)
+	}
+	quals, err := gaining.GetMemberQualifications(ctx, transferred.ID)
+	if err != nil {
+		t.Fatalf("Error getting transferred qualifications: %s", err.Error())
+	}
+	if len(quals) != 1 || quals[0].Name != qual.Name {
+		t.Fatalf("Expected %s to be assigned, got %+v", qual.Name, quals)
+	}
+	completions, err := gaining.GetMemberCompletions(ctx, transferred.ID)
+	if err != nil {
+		t.Fatalf("Error getting transferred completions: %s", err.Error())
+	}
+	for _, c := range completions {
+		if c.Status != types.CompletionApproved || c.CertifierID != "" {
+			t.Errorf("Expected approved completion without the losing unit's certifier, got %+v", c)
+		}
+		if c.RequirementID == shared.ID || c.RequirementID == local.ID {
+			t.Errorf("Expected completion to point at the gaining unit's requirement, got %s", c.RequirementID)
+		}
+	}
+
+	for _, c := range completions {
+		if slices.ContainsFunc(losingCompletions, func(l types.Completion) bool { return l.ID == c.ID }) {
+			t.Errorf("Expected completion to get a new ID, got the issuer's %s", c.ID)
+		}
+	}
+
+	if _, err = gaining.ImportMemberTransfer(ctx, "", signed); !errors.Is(err, backend.ErrTransferAlreadyImported) {
+		t.Errorf("Expected ErrTransferAlreadyImported importing a package twice, got %v", err)
+	}
+	again, err := losing.ExportMemberTransfer(ctx, admin.ID, member.ID)
+	if err != nil {
+		t.Fatalf("Error exporting transfer package: %s", err.Error())
+	}
+	if _, err = gaining.ImportMemberTransfer(ctx, "", again); !errors.Is(err, backend.ErrMemberNotArchived) {
+		t.Errorf("Expected ErrMemberNotArchived importing over an active member, got %v", err)
+	}
+
+	// Another tenant on the same deployment gets its own copy of the member
+	tenant, _, err := gaining.AddTenant(ctx, types.Tenant{Name: "62nd APS", Subdomain: "62aps"}, testutils.RandomMember(true))
+	if err != nil {
+		t.Fatalf("Error adding tenant: %s", err.Error())
+	}
+	tenantReport, err := gaining.ForTenant(tenant.ID).ImportMemberTransfer(ctx, "", signed)
+	if err != nil {
+		t.Fatalf("Error importing transfer package into another tenant: %s", err.Error())
+	}
+	if tenantReport.Returning || tenantReport.Member.ID == transferred.ID || tenantReport.ImportedCompletions != 2 {
+		t.Errorf("Expected a new member in the other tenant, got %+v", tenantReport)
+	}
+
+	// Coming back to the losing unit restores the archived record and keeps the old password
+	if _, err = losing.ArchiveMember(ctx, admin.ID, member.ID, types.MemberArchive{Reason: "PCS"}); err != nil {
+		t.Fatalf("Error archiving member: %s", err.Error())
+	}
+	back, err := gaining.ExportMemberTransfer(ctx, "", transferred.ID)
+	if err != nil {
+		t.Fatalf("Error exporting transfer package: %s", err.Error())
+	}
+	report, err = losing.ImportMemberTransfer(ctx, admin.ID, back)
+	if err != nil {
+		t.Fatalf("Error importing returning member: %s", err.Error())
+	}
+	if !report.Returning || report.TemporaryPassword != "" || report.Member.Archive != nil || report.Member.ID != member.ID {
+		t.Errorf("Expected %s to be restored, got %+v", member.ID, report)
+	}
+	if report.ImportedCompletions != 0 {
+		t.Errorf("Expected the completions the member left with not to be imported again, got %d", report.ImportedCompletions)
+	}
+	if len(report.CreatedRequirements) != 0 || len(report.CreatedQualifications) != 0 {
+		t.Errorf("Expected everything to match on the way back, got %+v", report)
+	}
+	if _, err = losing.Login(ctx, member.Username, password); err != nil {
+		t.Errorf("Expected the returning member's password to still work, got %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"slices"
+	"sort"
+)
+
+func (b Backend) AddUnit(ctx context.Context, u types.Unit) (types.Unit, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Adding unit", slog.String("name", u.Name), slog.String("kind", string(u.Kind)))
+	var missing []string
+	if u.Name == "" {
+		missing = append(missing, "Name")
+	}
+	if u.Kind == "" {
+		missing = append(missing, "Kind")
+	}
+	if len(missing) > 0 {
+		return types.Unit{}, MissingArgsError{Fields: missing}
+	}
+	if err := b.validateUnitParent(ctx, u); err != nil {
+		return types.Unit{}, err
+	}
+	u.ID = uuid.NewString()
+	u.MandatoryQualifications = dedupe(u.MandatoryQualifications)
+	sort.Strings(u.MandatoryQualifications)
+	u.Version = 1
+	if err := b.memberProvider.AddUnit(ctx, u); err != nil {
+		return types.Unit{}, err
+	}
+	return u, nil
+}
+
+func (b Backend) GetUnit(ctx context.Context, id string) (types.Unit, error) {
+	return b.memberProvider.GetUnit(ctx, id)
+}
+
+func (b Backend) GetUnits(ctx context.Context) ([]types.Unit, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting all units")
+	return b.memberProvider.GetUnits(ctx)
+}
+
+// UpdateUnit merges in changes to a unit's name, parent or mandatory qualifications. Units can move to another parent of
+// the right kind but can't change kind.
+func (b Backend) UpdateUnit(ctx context.Context, u types.Unit) (types.Unit, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Updating unit", slog.String("unit_id", u.ID))
+	existing, err := b.memberProvider.GetUnit(ctx, u.ID)
+	if err != nil {
+		return types.Unit{}, err
+	}
+	if err = checkVersion(existing.Version, u.Version); err != nil {
+		return types.Unit{}, err
+	}
+	if u.Kind != "" && u.Kind != existing.Kind {
+		return types.Unit{}, fmt.Errorf("%w: can't change a %s into a %s", ErrBadUpdate, existing.Kind, u.Kind)
+	}
+	updated := existing.MergeIn(u)
+	if err = b.validateUnitParent(ctx, updated); err != nil {
+		return types.Unit{}, err
+	}
+	updated.MandatoryQualifications = dedupe(updated.MandatoryQualifications)
+	sort.Strings(updated.MandatoryQualifications)
+	if err = b.memberProvider.UpdateUnit(ctx, updated); err != nil {
+		return types.Unit{}, err
+	}
+	updated.Version++
+	return updated, nil
+}
+
+// DeleteUnit removes a unit once its subunits are gone, as long as it's still at version when that isn't zero. Its
+// members are left without a unit.
+func (b Backend) DeleteUnit(ctx context.Context, id string, version int) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting unit", slog.String("unit_id", id))
+	if version == 0 {
+		return b.memberProvider.DeleteUnit(ctx, id)
+	}
+	return b.inTx(ctx, func(tx Backend) error {
+		u, err := tx.memberProvider.GetUnit(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err = checkVersion(u.Version, version); err != nil {
+			return err
+		}
+		return tx.memberProvider.DeleteUnit(ctx, id)
+	})
+}
+
+// validateUnitParent checks the unit sits directly under a unit of the kind above it, or at the top for squadrons.
+func (b Backend) validateUnitParent(ctx context.Context, u types.Unit) error {
+	if !u.Kind.Valid() {
+		return fmt.Errorf("%w: unknown kind %s", ErrInvalidUnit, u.Kind)
+	}
+	parentKind := u.Kind.ParentKind()
+	if parentKind == "" {
+		if u.ParentID != "" {
+			return fmt.Errorf("%w: a %s can't have a parent", ErrInvalidUnit, u.Kind)
+		}
+		return nil
+	}
+	if u.ParentID == "" {
+		return fmt.Errorf("%w: a %s must be under a %s", ErrInvalidUnit, u.Kind, parentKind)
+	}
+	parent, err := b.memberProvider.GetUnit(ctx, u.ParentID)
+	if errors.Is(err, ErrUnitNotFound) {
+		return fmt.Errorf("%w: parent unit %s not found", ErrInvalidUnit, u.ParentID)
+	} else if err != nil {
+		return err
+	}
+	if parent.Kind != parentKind {
+		return fmt.Errorf("%w: a %s must be under a %s, not a %s", ErrInvalidUnit, u.Kind, parentKind, parent.Kind)
+	}
+	return nil
+}
+
+// SetMemberUnit moves a member into a unit. An empty unitID takes them out of every unit.
+func (b Backend) SetMemberUnit(ctx context.Context, memberID, unitID string) (types.Member, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Setting member unit", slog.String("member_id", memberID), slog.String("unit_id", unitID))
+	if unitID != "" {
+		if _, err := b.memberProvider.GetUnit(ctx, unitID); err != nil {
+			return types.Member{}, err
+		}
+	}
+	if err := b.memberProvider.SetMemberUnit(ctx, memberID, unitID); err != nil {
+		return types.Member{}, err
+	}
+	return b.memberProvider.GetMember(ctx, memberID, ById)
+}
+
+// GetUnitMembers returns the active members of the unit and every unit under it.
+func (b Backend) GetUnitMembers(ctx context.Context, unitID string) ([]types.Member, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting unit members", slog.String("unit_id", unitID))
+	tree, err := b.unitTree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := tree.units[unitID]; !ok {
+		return nil, fmt.Errorf("%w: unit_id=%s", ErrUnitNotFound, unitID)
+	}
+	members := []types.Member{}
+	for _, id := range tree.subtree(unitID) {
+		unitMembers, err := b.memberProvider.GetUnitMembers(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, unitMembers...)
+	}
+	return members, nil
+}
+
+func (b Backend) AddUnitAdmin(ctx context.Context, unitID, memberID string) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Adding unit admin", slog.String("unit_id", unitID), slog.String("member_id", memberID))
+	return b.memberProvider.AddUnitAdmin(ctx, unitID, memberID)
+}
+
+func (b Backend) GetUnitAdmins(ctx context.Context, unitID string) ([]types.Member, error) {
+	if _, err := b.memberProvider.GetUnit(ctx, unitID); err != nil {
+		return nil, err
+	}
+	ids, err := b.memberProvider.GetUnitAdminIDs(ctx, unitID)
+	if err != nil {
+		return nil, err
+	}
+	admins := []types.Member{}
+	for _, id := range ids {
+		m, err := b.memberProvider.GetMember(ctx, id, ById)
+		if err != nil {
+			return nil, err
+		}
+		admins = append(admins, m)
+	}
+	return admins, nil
+}
+
+func (b Backend) RemoveUnitAdmin(ctx context.Context, unitID, memberID string) error {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Removing unit admin", slog.String("unit_id", unitID), slog.String("member_id", memberID))
+	return b.memberProvider.RemoveUnitAdmin(ctx, unitID, memberID)
+}
+
+// IsUnitAdmin reports whether the member administers the unit, either directly or through a unit above it.
+func (b Backend) IsUnitAdmin(ctx context.Context, memberID, unitID string) (bool, error) {
+	administered, err := b.memberProvider.GetAdministeredUnitIDs(ctx, memberID)
+	if err != nil || len(administered) == 0 {
+		return false, err
+	}
+	tree, err := b.unitTree(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range tree.ancestry(unitID) {
+		if slices.Contains(administered, id) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetUnitReport evaluates every member of the unit and its subunits against the qualifications mandatory for them, which
+// are those of their own unit and every unit above it.
+func (b Backend) GetUnitReport(ctx context.Context, unitID string) (types.UnitReport, error) {
+	l := b.logger.With(slog.String("unit_id", unitID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Building unit report")
+	tree, err := b.unitTree(ctx)
+	if err != nil {
+		return types.UnitReport{}, err
+	}
+	if _, ok := tree.units[unitID]; !ok {
+		return types.UnitReport{}, fmt.Errorf("%w: unit_id=%s", ErrUnitNotFound, unitID)
+	}
+	members, err := b.GetUnitMembers(ctx, unitID)
+	if err != nil {
+		return types.UnitReport{}, err
+	}
+	report := types.UnitReport{UnitID: unitID, Members: []types.UnitMemberReport{}}
+	for _, m := range members {
+		memberReport := types.UnitMemberReport{
+			MemberID:   m.ID,
+			UnitID:     m.UnitID,
+			Statuses:   []types.QualificationStatus{},
+			Unassigned: []string{},
+		}
+		assigned, err := b.memberQualificationIDs(ctx, m.ID)
+		if err != nil {
+			return types.UnitReport{}, err
+		}
+		e, err := b.newStatusEvaluator(ctx, m.ID)
+		if err != nil {
+			return types.UnitReport{}, err
+		}
+		for _, qualID := range tree.mandatoryQualifications(m.UnitID) {
+			if !assigned[qualID] {
+				memberReport.Unassigned = append(memberReport.Unassigned, qualID)
+				continue
+			}
+			status, err := e.evaluate(qualID)
+			if err != nil {
+				return types.UnitReport{}, err
+			}
+			memberReport.Statuses = append(memberReport.Statuses, status)
+		}
+		report.Members = append(report.Members, memberReport)
+	}
+	l.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Reported on %d members", len(report.Members)))
+	return report, nil
+}
+
+// unitTree holds every unit so the hierarchy can be walked without going back to the provider for each level.
+type unitTree struct {
+	units    map[string]types.Unit
+	children map[string][]string
+}
+
+func (b Backend) unitTree(ctx context.Context) (unitTree, error) {
+	units, err := b.memberProvider.GetUnits(ctx)
+	if err != nil {
+		return unitTree{}, err
+	}
+	tree := unitTree{units: make(map[string]types.Unit, len(units)), children: map[string][]string{}}
+	for _, u := range units {
+		tree.units[u.ID] = u
+		if u.ParentID != "" {
+			tree.children[u.ParentID] = append(tree.children[u.ParentID], u.ID)
+		}
+	}
+	return tree, nil
+}
+
+// subtree returns the unit and everything under it, parents before their children.
+func (t unitTree) subtree(unitID string) []string {
+	ids := []string{unitID}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, t.children[ids[i]]...)
+	}
+	return ids
+}
+
+// ancestry returns the unit and every unit above it, starting from the unit itself.
+func (t unitTree) ancestry(unitID string) []string {
+	var ids []string
+	for u, ok := t.units[unitID]; ok; u, ok = t.units[u.ParentID] {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
+
+func (t unitTree) mandatoryQualifications(unitID string) []string {
+	var ids []string
+	for _, id := range t.ancestry(unitID) {
+		ids = append(ids, t.units[id].MandatoryQualifications...)
+	}
+	ids = dedupe(ids)
+	sort.Strings(ids)
+	return ids
+}
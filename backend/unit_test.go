@@ -0,0 +1,230 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestUnits(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, expireClock{})
+
+	squadron, err := b.AddUnit(ctx, types.Unit{Name: "1st Aerial Port Squadron", Kind: types.UnitSquadron})
+	if err != nil {
+		t.Fatalf("Error adding unit for TestUnits: %s", err.Error())
+	}
+	flight, err := b.AddUnit(ctx, types.Unit{Name: "Air Terminal Operations Flight", Kind: types.UnitFlight, ParentID: squadron.ID})
+	if err != nil {
+		t.Fatalf("Error adding unit for TestUnits: %s", err.Error())
+	}
+	section, err := b.AddUnit(ctx, types.Unit{Name: "Special Handling", Kind: types.UnitSection, ParentID: flight.ID})
+	if err != nil {
+		t.Fatalf("Error adding unit for TestUnits: %s", err.Error())
+	}
+
+	t.Run("Invalid hierarchy", func(t *testing.T) {
+		tc := []struct {
+			name string
+			unit types.Unit
+			err  error
+		}{
+			{name: "Missing name", unit: types.Unit{Kind: types.UnitSquadron}, err: backend.ErrMissingArgs},
+			{name: "Unknown kind", unit: types.Unit{Name: "Wing", Kind: "wing"}, err: backend.ErrInvalidUnit},
+			{name: "Squadron with parent", unit: types.Unit{Name: "Nested", Kind: types.UnitSquadron, ParentID: squadron.ID}, err: backend.ErrInvalidUnit},
+			{name: "Flight without parent", unit: types.Unit{Name: "Orphan", Kind: types.UnitFlight}, err: backend.ErrInvalidUnit},
+			{name: "Section under squadron", unit: types.Unit{Name: "Skip", Kind: types.UnitSection, ParentID: squadron.ID}, err: backend.ErrInvalidUnit},
+			{name: "Parent not found", unit: types.Unit{Name: "Lost", Kind: types.UnitFlight, ParentID: uuid.NewString()}, err: backend.ErrInvalidUnit},
+		}
+		for _, tt := range tc {
+			if _, err := b.AddUnit(ctx, tt.unit); !errors.Is(err, tt.err) {
+				t.Errorf("%s: expected %s, got %v", tt.name, tt.err, err)
+			}
+		}
+		if _, err := b.UpdateUnit(ctx, types.Unit{ID: flight.ID, Kind: types.UnitSection}); !errors.Is(err, backend.ErrBadUpdate) {
+			t.Errorf("Expected ErrBadUpdate changing a unit's kind, got %v", err)
+		}
+	})
+
+	quals := make([]types.Qualification, 2)
+	for i := range quals {
+		q := testutils.RandomQualification()
+		q.Expires, q.ExpirationDays = false, 0
+		if quals[i], err = b.AddQualification(ctx, q); err != nil {
+			t.Fatalf("Error adding qualification for TestUnits: %s", err.Error())
+		}
+	}
+	if _, err = b.UpdateUnit(ctx, types.Unit{ID: squadron.ID, MandatoryQualifications: []string{quals[0].ID}}); err != nil {
+		t.Fatalf("Error setting squadron mandatory qualifications: %s", err.Error())
+	}
+	if section, err = b.UpdateUnit(ctx, types.Unit{ID: section.ID, MandatoryQualifications: []string{quals[1].ID, quals[1].ID}}); err != nil {
+		t.Fatalf("Error setting section mandatory qualifications: %s", err.Error())
+	}
+	if !reflect.DeepEqual(section.MandatoryQualifications, []string{quals[1].ID}) || section.Name != "Special Handling" {
+		t.Errorf("Expected only the mandatory qualifications to change, got %+v", section)
+	}
+	if _, err = b.UpdateUnit(ctx, types.Unit{ID: section.ID, MandatoryQualifications: []string{uuid.NewString()}}); !errors.Is(err, backend.ErrQualificationNotFound) {
+		t.Errorf("Expected ErrQualificationNotFound, got %v", err)
+	}
+
+	members := make([]types.Member, 4)
+	for i := range members {
+		if members[i], err = b.AddMember(ctx, testutils.RandomMember(false)); err != nil {
+			t.Fatalf("Error adding member for TestUnits: %s", err.Error())
+		}
+	}
+	for i, unitID := range []string{squadron.ID, section.ID, flight.ID} {
+		if members[i], err = b.SetMemberUnit(ctx, members[i].ID, unitID); err != nil {
+			t.Fatalf("Error setting member unit: %s", err.Error())
+		}
+		if members[i].UnitID != unitID {
+			t.Errorf("Expected member to be in unit %s, got %s", unitID, members[i].UnitID)
+		}
+	}
+	if _, err = b.SetMemberUnit(ctx, members[3].ID, uuid.NewString()); !errors.Is(err, backend.ErrUnitNotFound) {
+		t.Errorf("Expected ErrUnitNotFound, got %v", err)
+	}
+	if _, err = b.ArchiveMember(ctx, "", members[2].ID, types.MemberArchive{Reason: "PCS"}); err != nil {
+		t.Fatalf("Error archiving member: %s", err.Error())
+	}
+
+	t.Run("Members include subunits", func(t *testing.T) {
+		tc := []struct {
+			unitID   string
+			expected []string
+		}{
+			{unitID: squadron.ID, expected: []string{members[0].ID, members[1].ID}},
+			{unitID: flight.ID, expected: []string{members[1].ID}},
+			{unitID: section.ID, expected: []string{members[1].ID}},
+		}
+		for _, tt := range tc {
+			unitMembers, err := b.GetUnitMembers(ctx, tt.unitID)
+			if err != nil {
+				t.Fatalf("Error getting unit members: %s", err.Error())
+			}
+			var ids []string
+			for _, m := range unitMembers {
+				ids = append(ids, m.ID)
+			}
+			slices.Sort(ids)
+			slices.Sort(tt.expected)
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Expected members %v, got %v", tt.expected, ids)
+			}
+			page, err := b.ListMembers(ctx, types.MemberListOptions{UnitIDs: []string{tt.unitID}})
+			if err != nil {
+				t.Fatalf("Error listing unit members: %s", err.Error())
+			}
+			ids = nil
+			for _, m := range page.Items {
+				ids = append(ids, m.ID)
+			}
+			slices.Sort(ids)
+			if !reflect.DeepEqual(ids, tt.expected) || page.Total != len(tt.expected) {
+				t.Errorf("Expected to list members %v, got %v of %d", tt.expected, ids, page.Total)
+			}
+		}
+		if _, err := b.GetUnitMembers(ctx, uuid.NewString()); !errors.Is(err, backend.ErrUnitNotFound) {
+			t.Errorf("Expected ErrUnitNotFound, got %v", err)
+		}
+		if _, err := b.ListMembers(ctx, types.MemberListOptions{UnitIDs: []string{uuid.NewString()}}); !errors.Is(err, backend.ErrUnitNotFound) {
+			t.Errorf("Expected ErrUnitNotFound listing an unknown unit, got %v", err)
+		}
+	})
+
+	t.Run("Unit admins", func(t *testing.T) {
+		if err := b.AddUnitAdmin(ctx, flight.ID, members[3].ID); err != nil {
+			t.Fatalf("Error adding unit admin: %s", err.Error())
+		}
+		if err := b.AddUnitAdmin(ctx, flight.ID, members[3].ID); !errors.Is(err, backend.ErrUnitAdminAlreadyDesignated) {
+			t.Errorf("Expected ErrUnitAdminAlreadyDesignated, got %v", err)
+		}
+		for unitID, expected := range map[string]bool{squadron.ID: false, flight.ID: true, section.ID: true} {
+			admin, err := b.IsUnitAdmin(ctx, members[3].ID, unitID)
+			if err != nil {
+				t.Fatalf("Error checking unit admin: %s", err.Error())
+			}
+			if admin != expected {
+				t.Errorf("Expected unit admin of %s to be %t", unitID, expected)
+			}
+		}
+		admins, err := b.GetUnitAdmins(ctx, flight.ID)
+		if err != nil || len(admins) != 1 || admins[0].ID != members[3].ID {
+			t.Errorf("Expected the flight's admin to be listed, got %+v, %v", admins, err)
+		}
+		if err = b.RemoveUnitAdmin(ctx, flight.ID, members[3].ID); err != nil {
+			t.Fatalf("Error removing unit admin: %s", err.Error())
+		}
+		if err = b.RemoveUnitAdmin(ctx, flight.ID, members[3].ID); !errors.Is(err, backend.ErrUnitAdminNotFound) {
+			t.Errorf("Expected ErrUnitAdminNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Mandatory qualification report", func(t *testing.T) {
+		if err := b.AssignMemberQualification(ctx, "", members[1].ID, quals[0].ID); err != nil {
+			t.Fatalf("Error assigning qualification: %s", err.Error())
+		}
+		report, err := b.GetUnitReport(ctx, squadron.ID)
+		if err != nil {
+			t.Fatalf("Error getting unit report: %s", err.Error())
+		}
+		if len(report.Members) != 2 {
+			t.Fatalf("Expected both active members in the report, got %+v", report.Members)
+		}
+		for _, m := range report.Members {
+			switch m.MemberID {
+			case members[0].ID:
+				if !reflect.DeepEqual(m.Unassigned, []string{quals[0].ID}) || len(m.Statuses) != 0 {
+					t.Errorf("Expected only the squadron qualification to be missing, got %+v", m)
+				}
+			case members[1].ID:
+				if !reflect.DeepEqual(m.Unassigned, []string{quals[1].ID}) {
+					t.Errorf("Expected the section qualification to be missing, got %+v", m.Unassigned)
+				}
+				if len(m.Statuses) != 1 || m.Statuses[0].QualificationID != quals[0].ID || m.Statuses[0].State != types.StateQualified {
+					t.Errorf("Expected the squadron qualification to be evaluated, got %+v", m.Statuses)
+				}
+			default:
+				t.Errorf("Unexpected member in report: %s", m.MemberID)
+			}
+		}
+	})
+
+	if err = b.DeleteUnit(ctx, flight.ID, 0); !errors.Is(err, backend.ErrUnitHasSubunits) {
+		t.Errorf("Expected ErrUnitHasSubunits, got %v", err)
+	}
+	if err = b.DeleteUnit(ctx, section.ID, 0); err != nil {
+		t.Fatalf("Error deleting unit: %s", err.Error())
+	}
+	m, err := b.GetMember(ctx, members[1].ID)
+	if err != nil {
+		t.Fatalf("Error getting member: %s", err.Error())
+	}
+	if m.UnitID != "" {
+		t.Errorf("Expected member to be left without a unit, got %s", m.UnitID)
+	}
+}
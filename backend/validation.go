@@ -26,7 +26,7 @@ func CheckMemberForMissingArgs(m types.Member) error {
 		errors = append(errors, "Password")
 	}
 	if len(errors) > 0 {
-		return fmt.Errorf("%w: %s", ErrMissingArgs, errors)
+		return MissingArgsError{Fields: errors}
 	}
 	return nil
 }
@@ -43,7 +43,7 @@ func CheckQualificationForMissingArgs(q types.Qualification) error {
 		missing = append(missing, "ExpirationDays")
 	}
 	if len(missing) > 0 {
-		return fmt.Errorf("%w: %s", ErrMissingArgs, missing)
+		return MissingArgsError{Fields: missing}
 	}
 	return nil
 }
@@ -60,10 +60,12 @@ func CheckRequirementForMissingArgs(r types.Requirement) error {
 		errors = append(errors, "Description")
 	}
 	if err := CheckReferenceForMissingArgs(r.Reference); err != nil {
-		errors = append(errors, fmt.Sprintf("Reference: %s", err.Error()))
+		for _, field := range err.(MissingArgsError).Fields {
+			errors = append(errors, "Reference."+field)
+		}
 	}
 	if len(errors) > 0 {
-		return fmt.Errorf("%w: %s", ErrMissingArgs, errors)
+		return MissingArgsError{Fields: errors}
 	}
 	return nil
 }
@@ -80,7 +82,35 @@ func CheckReferenceForMissingArgs(r types.Reference) error {
 		errors = append(errors, "Paragraph")
 	}
 	if len(errors) > 0 {
-		return fmt.Errorf("%w: %s", ErrMissingArgs, errors)
+		return MissingArgsError{Fields: errors}
+	}
+	return nil
+}
+
+// CheckListOptions fills in the default sort and page size, checking items like like can be sorted by the key asked
+// for.
+func CheckListOptions(opts types.ListOptions, like types.Sortable, defaultSort types.SortKey) (types.ListOptions, error) {
+	if opts.Sort == "" {
+		opts.Sort = defaultSort
+	}
+	if like.SortValues(opts.Sort) == nil {
+		return opts, fmt.Errorf("%w: can't sort by %q", ErrInvalidListOptions, opts.Sort)
+	}
+	if opts.Limit < 0 {
+		return opts, fmt.Errorf("%w: negative limit", ErrInvalidListOptions)
+	}
+	if opts.Limit == 0 {
+		opts.Limit = types.DefaultPageSize
+	}
+	opts.Limit = min(opts.Limit, types.MaxPageSize)
+	return opts, nil
+}
+
+// checkVersion makes sure a change made against version was made against the stored version. Version zero skips the
+// check.
+func checkVersion(stored, version int) error {
+	if version != 0 && version != stored {
+		return fmt.Errorf("%w: at version %d, not %d", ErrVersionConflict, stored, version)
 	}
 	return nil
 }
@@ -0,0 +1,139 @@
+package backend
+
+import (
+	"PORTal/types"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+// GrantWaiver records a waiver or extension approved by approverID. The start date defaults to now.
+func (b Backend) GrantWaiver(ctx context.Context, approverID string, w types.Waiver) (types.Waiver, error) {
+	l := b.logger.With(slog.String("member_id", w.MemberID), slog.String("approver_id", approverID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Granting waiver", slog.Any("waiver", w))
+	var missing []string
+	if w.MemberID == "" {
+		missing = append(missing, "MemberID")
+	}
+	if w.Reason == "" {
+		missing = append(missing, "Reason")
+	}
+	if w.End.IsZero() {
+		missing = append(missing, "End")
+	}
+	if len(missing) > 0 {
+		return types.Waiver{}, MissingArgsError{Fields: missing}
+	}
+	if w.Kind == "" {
+		w.Kind = types.WaiverKindWaiver
+	}
+	if !w.Kind.Valid() {
+		return types.Waiver{}, fmt.Errorf("%w: unknown kind %s", ErrInvalidWaiver, w.Kind)
+	}
+	if (w.RequirementID == "") == (w.QualificationID == "") {
+		l.LogAttrs(ctx, slog.LevelWarn, "Waiver must cover exactly one requirement or qualification")
+		return types.Waiver{}, fmt.Errorf("%w: must cover exactly one requirement or qualification", ErrInvalidWaiver)
+	}
+	w.ID = uuid.NewString()
+	w.ApproverID = approverID
+	w.Created = b.clock.Now()
+	if w.Start.IsZero() {
+		w.Start = w.Created
+	}
+	if !w.End.After(w.Start) {
+		return types.Waiver{}, fmt.Errorf("%w: end must be after start", ErrInvalidWaiver)
+	}
+	if w.Memo != nil && (w.Memo.Name == "" || len(w.Memo.Data) == 0) {
+		return types.Waiver{}, fmt.Errorf("%w: memo must have a name and content", ErrInvalidWaiver)
+	}
+	err := b.inTx(ctx, func(tx Backend) error {
+		// Catch any lapse before the waiver hides it
+		if err := tx.recordStatusChanges(ctx, w.MemberID); err != nil {
+			return err
+		}
+		if err := tx.memberProvider.AddWaiver(ctx, w); err != nil {
+			return err
+		}
+		if err := tx.audit(ctx, approverID, "waiver.grant", AuditEntityWaiver, w.ID, w.Reason); err != nil {
+			return err
+		}
+		return tx.recordStatusChanges(ctx, w.MemberID)
+	})
+	if err != nil {
+		return types.Waiver{}, err
+	}
+	if w.Memo != nil {
+		w.MemoName = w.Memo.Name
+		w.Memo = nil
+	}
+	return w, nil
+}
+
+func (b Backend) GetWaiver(ctx context.Context, id string) (types.Waiver, error) {
+	return b.memberProvider.GetWaiver(ctx, id)
+}
+
+func (b Backend) GetMemberWaivers(ctx context.Context, memberID string) ([]types.Waiver, error) {
+	b.logger.LogAttrs(ctx, slog.LevelInfo, "Getting waivers for member", slog.String("member_id", memberID))
+	return b.memberProvider.GetMemberWaivers(ctx, memberID)
+}
+
+func (b Backend) GetWaiverMemo(ctx context.Context, id string) (types.WaiverMemo, error) {
+	return b.memberProvider.GetWaiverMemo(ctx, id)
+}
+
+// RevokeWaiver ends a waiver immediately. The record itself is kept for the audit trail.
+func (b Backend) RevokeWaiver(ctx context.Context, actorID, id, reason string) (types.Waiver, error) {
+	l := b.logger.With(slog.String("waiver_id", id), slog.String("actor_id", actorID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Revoking waiver")
+	w, err := b.memberProvider.GetWaiver(ctx, id)
+	if err != nil {
+		return types.Waiver{}, err
+	}
+	now := b.clock.Now()
+	if !now.Before(w.End) {
+		l.LogAttrs(ctx, slog.LevelWarn, "Waiver has already ended")
+		return types.Waiver{}, fmt.Errorf("%w: waiver already ended", ErrInvalidWaiver)
+	}
+	// A waiver that hasn't started yet ends before it begins
+	end := now
+	if end.Before(w.Start) {
+		end = w.Start
+	}
+	err = b.inTx(ctx, func(tx Backend) error {
+		if err := tx.memberProvider.UpdateWaiverEnd(ctx, id, end); err != nil {
+			return err
+		}
+		if err := tx.audit(ctx, actorID, "waiver.revoke", AuditEntityWaiver, id, reason); err != nil {
+			return err
+		}
+		return tx.recordStatusChanges(ctx, w.MemberID)
+	})
+	if err != nil {
+		return types.Waiver{}, err
+	}
+	w.End = end
+	return w, nil
+}
+
+// activeWaivers splits the member's waivers in effect at now by what they cover.
+func activeWaivers(waivers []types.Waiver, now time.Time) (requirements, qualifications map[string]types.Waiver) {
+	requirements = map[string]types.Waiver{}
+	qualifications = map[string]types.Waiver{}
+	for _, w := range waivers {
+		if !w.Active(now) {
+			continue
+		}
+		target, id := requirements, w.RequirementID
+		if w.QualificationID != "" {
+			target, id = qualifications, w.QualificationID
+		}
+		// Keep whichever waiver lasts longest when more than one covers the same item
+		if existing, ok := target[id]; !ok || w.End.After(existing.End) {
+			target[id] = w
+		}
+	}
+	return requirements, qualifications
+}
@@ -0,0 +1,222 @@
+package backend_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWaivers(t *testing.T) {
+	ctx := context.Background()
+	dbID := uuid.NewString()
+	t.Cleanup(func() {
+		os.Remove(fmt.Sprintf("%s.db", dbID))
+	})
+	buf := &bytes.Buffer{}
+	mr := io.MultiWriter(os.Stdout, buf)
+	logger := slog.New(slog.NewTextHandler(mr, nil))
+	provider, err := sqlite.New(logger, fmt.Sprintf("%s.db", dbID), sqlite.SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	// expireClock pins now to 2000-01-01
+	b := backend.New(logger, provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, expireClock{})
+	now := expireClock{}.Now()
+
+	approver, err := b.AddMember(ctx, testutils.RandomMember(true))
+	if err != nil {
+		t.Fatalf("Error adding member for TestWaivers: %s", err.Error())
+	}
+	member, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestWaivers: %s", err.Error())
+	}
+	ref, err := b.AddReference(ctx, testutils.RandomReference())
+	if err != nil {
+		t.Fatalf("Error adding reference for TestWaivers: %s", err.Error())
+	}
+	initial, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref))
+	if err != nil {
+		t.Fatalf("Error adding requirement for TestWaivers: %s", err.Error())
+	}
+	recurring := testutils.RandomRequirement(ref)
+	recurring.DaysValidFor = 30
+	recurring, err = b.AddRequirement(ctx, recurring)
+	if err != nil {
+		t.Fatalf("Error adding requirement for TestWaivers: %s", err.Error())
+	}
+	qual := testutils.RandomQualification()
+	qual.Expires = false
+	qual.InitialRequirements = []types.Requirement{initial}
+	qual.RecurringRequirements = []types.Requirement{recurring}
+	qual, err = b.AddQualification(ctx, qual)
+	if err != nil {
+		t.Fatalf("Error adding qualification for TestWaivers: %s", err.Error())
+	}
+	if err = b.AssignMemberQualification(ctx, "", member.ID, qual.ID); err != nil {
+		t.Fatalf("Error assigning qualification for TestWaivers: %s", err.Error())
+	}
+
+	tc := []struct {
+		Name          string
+		Waiver        types.Waiver
+		ExpectedError error
+	}{
+		{
+			Name:          "Missing reason",
+			Waiver:        types.Waiver{MemberID: member.ID, RequirementID: recurring.ID, End: now.Add(time.Hour)},
+			ExpectedError: backend.ErrMissingArgs,
+		},
+		{
+			Name:          "Covers nothing",
+			Waiver:        types.Waiver{MemberID: member.ID, Reason: "Deployed", End: now.Add(time.Hour)},
+			ExpectedError: backend.ErrInvalidWaiver,
+		},
+		{
+			Name:          "Covers both",
+			Waiver:        types.Waiver{MemberID: member.ID, RequirementID: recurring.ID, QualificationID: qual.ID, Reason: "Deployed", End: now.Add(time.Hour)},
+			ExpectedError: backend.ErrInvalidWaiver,
+		},
+		{
+			Name:          "Ends before it starts",
+			Waiver:        types.Waiver{MemberID: member.ID, RequirementID: recurring.ID, Reason: "Deployed", Start: now, End: now.Add(-time.Hour)},
+			ExpectedError: backend.ErrInvalidWaiver,
+		},
+		{
+			Name:          "Unknown kind",
+			Waiver:        types.Waiver{MemberID: member.ID, RequirementID: recurring.ID, Kind: "pardon", Reason: "Deployed", End: now.Add(time.Hour)},
+			ExpectedError: backend.ErrInvalidWaiver,
+		},
+		{
+			Name:          "Requirement not found",
+			Waiver:        types.Waiver{MemberID: member.ID, RequirementID: uuid.NewString(), Reason: "Deployed", End: now.Add(time.Hour)},
+			ExpectedError: backend.ErrRequirementNotFound,
+		},
+		{
+			Name:          "Member not found",
+			Waiver:        types.Waiver{MemberID: uuid.NewString(), RequirementID: recurring.ID, Reason: "Deployed", End: now.Add(time.Hour)},
+			ExpectedError: backend.ErrMemberNotFound,
+		},
+	}
+	for _, tt := range tc {
+		t.Run(tt.Name, func(t *testing.T) {
+			if _, err := b.GrantWaiver(ctx, approver.ID, tt.Waiver); !errors.Is(err, tt.ExpectedError) {
+				t.Errorf("Expected error: %s, got: %v", tt.ExpectedError, err)
+			}
+		})
+	}
+
+	// A qualification waiver covers a member who hasn't finished training
+	qualWaiver, err := b.GrantWaiver(ctx, approver.ID, types.Waiver{
+		MemberID:        member.ID,
+		QualificationID: qual.ID,
+		Reason:          "Mission essential, training scheduled",
+		End:             now.Add(10 * types.Day),
+		Memo:            &types.WaiverMemo{Name: "memo.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4")},
+	})
+	if err != nil {
+		t.Fatalf("Error granting waiver: %s", err.Error())
+	}
+	if qualWaiver.Kind != types.WaiverKindWaiver || qualWaiver.ApproverID != approver.ID || qualWaiver.MemoName != "memo.pdf" {
+		t.Errorf("Expected waiver approved by %s with memo, got: %+v", approver.ID, qualWaiver)
+	}
+	memo, err := b.GetWaiverMemo(ctx, qualWaiver.ID)
+	if err != nil {
+		t.Fatalf("Error getting waiver memo: %s", err.Error())
+	}
+	if memo.Name != "memo.pdf" || memo.ContentType != "application/pdf" || string(memo.Data) != "%PDF-1.4" {
+		t.Errorf("Expected stored memo, got: %+v", memo)
+	}
+	status, err := b.GetMemberQualificationStatus(ctx, member.ID, qual.ID)
+	if err != nil {
+		t.Fatalf("Error getting qualification status: %s", err.Error())
+	}
+	if status.State != types.StateQualified || !status.Waived || !status.Expires.Equal(qualWaiver.End) || !slices.Contains(status.Waivers, qualWaiver.ID) {
+		t.Errorf("Expected waived qualification until %s, got: %+v", qualWaiver.End, status)
+	}
+
+	// Revoking the waiver puts the member back in training
+	if _, err = b.RevokeWaiver(ctx, approver.ID, qualWaiver.ID, "Training complete"); err != nil {
+		t.Fatalf("Error revoking waiver: %s", err.Error())
+	}
+	if _, err = b.RevokeWaiver(ctx, approver.ID, qualWaiver.ID, "Again"); !errors.Is(err, backend.ErrInvalidWaiver) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrInvalidWaiver, err)
+	}
+	status, err = b.GetMemberQualificationStatus(ctx, member.ID, qual.ID)
+	if err != nil {
+		t.Fatalf("Error getting qualification status: %s", err.Error())
+	}
+	if status.State != types.StateInTraining || status.Waived {
+		t.Errorf("Expected member to be in training after revocation, got: %+v", status)
+	}
+	entries, err := b.GetAuditEntries(ctx, backend.AuditEntityWaiver, qualWaiver.ID)
+	if err != nil {
+		t.Fatalf("Error getting audit entries: %s", err.Error())
+	}
+	if len(entries) != 2 || entries[0].Action != "waiver.grant" || entries[1].Action != "waiver.revoke" || entries[1].ActorID != approver.ID {
+		t.Errorf("Expected grant and revoke audit entries, got: %+v", entries)
+	}
+
+	// An extension covers a lapsed recurring requirement
+	certifier, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member for TestWaivers: %s", err.Error())
+	}
+	if err = b.AddCertifier(ctx, initial.ID, certifier.ID); err != nil {
+		t.Fatalf("Error designating certifier for TestWaivers: %s", err.Error())
+	}
+	c, err := b.SubmitCompletion(ctx, member.ID, types.Completion{MemberID: member.ID, RequirementID: initial.ID, CompletedDate: now.Add(-60 * types.Day)})
+	if err != nil {
+		t.Fatalf("Error submitting completion for TestWaivers: %s", err.Error())
+	}
+	if _, err = b.ReviewCompletion(ctx, certifier.ID, c.ID, true, ""); err != nil {
+		t.Fatalf("Error approving completion for TestWaivers: %s", err.Error())
+	}
+	status, err = b.GetMemberQualificationStatus(ctx, member.ID, qual.ID)
+	if err != nil {
+		t.Fatalf("Error getting qualification status: %s", err.Error())
+	}
+	if status.State != types.StateLapsed {
+		t.Errorf("Expected recurring requirement to be lapsed, got: %+v", status)
+	}
+	extension, err := b.GrantWaiver(ctx, approver.ID, types.Waiver{
+		MemberID:      member.ID,
+		RequirementID: recurring.ID,
+		Kind:          types.WaiverKindExtension,
+		Reason:        "Deployed",
+		Start:         now.Add(-types.Day),
+		End:           now.Add(30 * types.Day),
+	})
+	if err != nil {
+		t.Fatalf("Error granting extension: %s", err.Error())
+	}
+	status, err = b.GetMemberQualificationStatus(ctx, member.ID, qual.ID)
+	if err != nil {
+		t.Fatalf("Error getting qualification status: %s", err.Error())
+	}
+	if status.State != types.StateQualified || status.Waived || !slices.Equal(status.WaivedRequirements, []string{recurring.ID}) ||
+		!status.Expires.Equal(extension.End) {
+		t.Errorf("Expected qualified status with the waived requirement flagged until %s, got: %+v", extension.End, status)
+	}
+
+	waivers, err := b.GetMemberWaivers(ctx, member.ID)
+	if err != nil {
+		t.Fatalf("Error getting member waivers: %s", err.Error())
+	}
+	if len(waivers) != 2 {
+		t.Errorf("Expected 2 waivers for member, got %d", len(waivers))
+	}
+}
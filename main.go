@@ -2,25 +2,90 @@ package main
 
 import (
 	"PORTal/app"
+	"PORTal/types"
+	"context"
 	"flag"
+	"fmt"
 	"gopkg.in/yaml.v2"
+	"io"
 	"os"
+	"strings"
+	"text/tabwriter"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import-members" {
+		importMembers(os.Args[2:])
+		return
+	}
 	dev := flag.Bool("dev", false, "development mode")
 	configPath := flag.String("config", "config.yml", "Path to the yaml config file")
 	flag.Parse()
-	f, err := os.Open(*configPath)
+	a, err := app.New(loadConfig(*configPath), *dev, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	a.Run()
+}
+
+func loadConfig(path string) app.Config {
+	f, err := os.Open(path)
 	if err != nil {
 		panic(err)
 	}
+	defer f.Close()
 	var config app.Config
 	decoder := yaml.NewDecoder(f)
 	err = decoder.Decode(&config)
 	if err != nil {
 		panic(err)
 	}
-	a := app.New(config, *dev, os.Stdout)
-	a.Run()
+	return config
+}
+
+// importMembers implements `PORTal import-members [-config config.yml] [-dry-run] roster.csv`. The report, including
+// temporary passwords, goes to stdout and logs go to stderr.
+func importMembers(args []string) {
+	fs := flag.NewFlagSet("import-members", flag.ExitOnError)
+	configPath := fs.String("config", "config.yml", "Path to the yaml config file")
+	dryRun := fs.Bool("dry-run", false, "validate the roster without importing it")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: PORTal import-members [-config config.yml] [-dry-run] roster.csv")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	report, err := app.ImportMembers(context.Background(), loadConfig(*configPath), f, *dryRun, os.Stderr)
+	printImportReport(os.Stdout, report)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func printImportReport(w io.Writer, report types.MemberImportReport) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE\tUSERNAME\tTEMPORARY PASSWORD\tERRORS")
+	for _, row := range report.Rows {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", row.Line, row.Username, row.TemporaryPassword, strings.Join(row.Errors, "; "))
+	}
+	tw.Flush()
+	switch {
+	case report.Committed:
+		fmt.Fprintf(w, "Imported %d members\n", len(report.Rows))
+	case report.DryRun && report.Valid():
+		fmt.Fprintf(w, "Dry run: %d members would be imported\n", len(report.Rows))
+	default:
+		fmt.Fprintln(w, "Nothing was imported")
+	}
 }
@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"PORTal/types"
+	"context"
+	"slices"
+)
+
+func (p Provider) AddAuditEntry(ctx context.Context, e types.AuditEntry) error {
+	return p.update(func(d *tenantData) error {
+		d.auditEntries = append(d.auditEntries, e)
+		return nil
+	})
+}
+
+func (p Provider) GetAuditEntries(ctx context.Context, entityType, entityID string) ([]types.AuditEntry, error) {
+	entries := []types.AuditEntry{}
+	err := p.view(func(d *tenantData) error {
+		for _, e := range d.auditEntries {
+			if e.EntityType == entityType && e.EntityID == entityID {
+				entries = append(entries, e)
+			}
+		}
+		return nil
+	})
+	slices.SortStableFunc(entries, func(a, b types.AuditEntry) int { return a.Time.Compare(b.Time) })
+	return entries, err
+}
@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+func (d *tenantData) completion(id string) int {
+	return slices.IndexFunc(d.completions, func(c types.Completion) bool { return c.ID == id })
+}
+
+func (p Provider) AddCertifier(ctx context.Context, requirementID, memberID string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Designating certifier for requirement",
+		slog.String("requirement_id", requirementID), slog.String("member_id", memberID))
+	return p.update(func(d *tenantData) error {
+		if d.certifiers.has(requirementID, memberID) {
+			return fmt.Errorf("%w: requirement_id=%s member_id=%s", backend.ErrCertifierAlreadyDesignated, requirementID, memberID)
+		}
+		if d.member(memberID) == -1 {
+			return backend.ErrMemberNotFound
+		}
+		if d.requirement(requirementID) == -1 {
+			return fmt.Errorf("%w: requirement_id=%s", backend.ErrRequirementNotFound, requirementID)
+		}
+		d.certifiers.add(requirementID, memberID)
+		return nil
+	})
+}
+
+func (p Provider) GetCertifierIDs(ctx context.Context, requirementID string) ([]string, error) {
+	var ids []string
+	err := p.view(func(d *tenantData) error {
+		ids = d.certifiers.rights(requirementID)
+		return nil
+	})
+	return ids, err
+}
+
+func (p Provider) IsCertifier(ctx context.Context, requirementID, memberID string) (bool, error) {
+	var certifier bool
+	err := p.view(func(d *tenantData) error {
+		certifier = d.certifiers.has(requirementID, memberID)
+		return nil
+	})
+	return certifier, err
+}
+
+func (p Provider) RemoveCertifier(ctx context.Context, requirementID, memberID string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Removing certifier from requirement",
+		slog.String("requirement_id", requirementID), slog.String("member_id", memberID))
+	return p.update(func(d *tenantData) error {
+		if !d.certifiers.remove(requirementID, memberID) {
+			return fmt.Errorf("%w: requirement_id=%s member_id=%s", backend.ErrCertifierNotFound, requirementID, memberID)
+		}
+		return nil
+	})
+}
+
+// AddCompletion stores a completion awaiting review, so it never has a certifier or review time yet.
+func (p Provider) AddCompletion(ctx context.Context, c types.Completion) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Inserting completion into memory", slog.Any("completion", c))
+	return p.update(func(d *tenantData) error {
+		if d.completion(c.ID) != -1 {
+			return fmt.Errorf("completion %s already exists", c.ID)
+		}
+		if err := d.checkCompletionMembers(c); err != nil {
+			return err
+		}
+		c.CertifierID = ""
+		c.Reviewed = time.Time{}
+		d.completions = append(d.completions, c)
+		return nil
+	})
+}
+
+// checkCompletionMembers checks everyone and everything the completion refers to exists.
+func (d *tenantData) checkCompletionMembers(c types.Completion) error {
+	for _, id := range []string{c.MemberID, c.TrainerID, c.CertifierID} {
+		if id != "" && d.member(id) == -1 {
+			return fmt.Errorf("%w: %s", backend.ErrMemberNotFound, id)
+		}
+	}
+	if d.requirement(c.RequirementID) == -1 {
+		return fmt.Errorf("%w: requirement_id=%s", backend.ErrRequirementNotFound, c.RequirementID)
+	}
+	return nil
+}
+
+func (p Provider) GetCompletion(ctx context.Context, id string) (types.Completion, error) {
+	var c types.Completion
+	err := p.view(func(d *tenantData) error {
+		i := d.completion(id)
+		if i == -1 {
+			return fmt.Errorf("%w: completion_id=%s", backend.ErrCompletionNotFound, id)
+		}
+		c = d.completions[i]
+		return nil
+	})
+	return c, err
+}
+
+func (p Provider) GetMemberCompletions(ctx context.Context, memberID string) ([]types.Completion, error) {
+	return p.completions(func(d *tenantData, c types.Completion) bool { return c.MemberID == memberID })
+}
+
+func (p Provider) GetPendingCompletions(ctx context.Context, certifierID string) ([]types.Completion, error) {
+	return p.completions(func(d *tenantData, c types.Completion) bool {
+		return c.Status == types.CompletionPending && d.certifiers.has(c.RequirementID, certifierID)
+	})
+}
+
+// completions returns the completions matching, oldest submission first.
+func (p Provider) completions(match func(d *tenantData, c types.Completion) bool) ([]types.Completion, error) {
+	completions := []types.Completion{}
+	err := p.view(func(d *tenantData) error {
+		for _, c := range d.completions {
+			if match(d, c) {
+				completions = append(completions, c)
+			}
+		}
+		return nil
+	})
+	slices.SortStableFunc(completions, func(a, b types.Completion) int { return a.Submitted.Compare(b.Submitted) })
+	return completions, err
+}
+
+// ReviewCompletion records the certifier's decision on a pending completion. Approved completions are applied to the
+// member's requirement record at the same time so the two never disagree.
+func (p Provider) ReviewCompletion(ctx context.Context, c types.Completion) error {
+	l := p.logger.With(slog.String("completion_id", c.ID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Recording completion review", slog.String("status", string(c.Status)))
+	return p.update(func(d *tenantData) error {
+		i := d.completion(c.ID)
+		if i == -1 || d.completions[i].Status != types.CompletionPending {
+			return fmt.Errorf("%w: completion_id=%s", backend.ErrCompletionAlreadyReviewed, c.ID)
+		}
+		d.completions[i].CertifierID = c.CertifierID
+		d.completions[i].Status = c.Status
+		d.completions[i].Reviewed = c.Reviewed
+		d.completions[i].Comments = c.Comments
+		if c.Status == types.CompletionApproved {
+			d.upsertMemberRequirement(c.MemberID, c.RequirementID, c.CompletedDate)
+		}
+		return nil
+	})
+}
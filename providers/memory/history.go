@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+func (d *tenantData) importBatch(id string) int {
+	return slices.IndexFunc(d.importBatches, func(b types.ImportBatch) bool { return b.ID == id })
+}
+
+func (p Provider) AddImportProfile(ctx context.Context, profile types.ImportProfile) error {
+	return p.update(func(d *tenantData) error {
+		if slices.ContainsFunc(d.importProfiles, func(existing types.ImportProfile) bool { return existing.ID == profile.ID }) {
+			return fmt.Errorf("import profile %s already exists", profile.ID)
+		}
+		if slices.ContainsFunc(d.importProfiles, func(existing types.ImportProfile) bool { return existing.Name == profile.Name }) {
+			p.logger.LogAttrs(ctx, slog.LevelWarn, "Import profile name already taken", slog.String("name", profile.Name))
+			return fmt.Errorf("%w: %s", backend.ErrDuplicateImportProfile, profile.Name)
+		}
+		d.importProfiles = append(d.importProfiles, profile)
+		return nil
+	})
+}
+
+func (p Provider) GetImportProfile(ctx context.Context, id string) (types.ImportProfile, error) {
+	var profile types.ImportProfile
+	err := p.view(func(d *tenantData) error {
+		i := slices.IndexFunc(d.importProfiles, func(profile types.ImportProfile) bool { return profile.ID == id })
+		if i == -1 {
+			p.logger.LogAttrs(ctx, slog.LevelWarn, "No import profile found with given id", slog.String("profile_id", id))
+			return fmt.Errorf("%w: profile_id=%s", backend.ErrImportProfileNotFound, id)
+		}
+		profile = d.importProfiles[i]
+		return nil
+	})
+	return profile, err
+}
+
+func (p Provider) GetImportProfiles(ctx context.Context) ([]types.ImportProfile, error) {
+	profiles := []types.ImportProfile{}
+	err := p.view(func(d *tenantData) error {
+		profiles = append(profiles, d.importProfiles...)
+		return nil
+	})
+	slices.SortStableFunc(profiles, func(a, b types.ImportProfile) int { return strings.Compare(a.Name, b.Name) })
+	return profiles, err
+}
+
+// DeleteImportProfile removes the profile. Batches uploaded with it are kept without one.
+func (p Provider) DeleteImportProfile(ctx context.Context, id string) error {
+	return p.update(func(d *tenantData) error {
+		n := len(d.importProfiles)
+		d.importProfiles = slices.DeleteFunc(d.importProfiles, func(profile types.ImportProfile) bool { return profile.ID == id })
+		if len(d.importProfiles) == n {
+			return fmt.Errorf("%w: profile_id=%s", backend.ErrImportProfileNotFound, id)
+		}
+		for i := range d.importBatches {
+			if d.importBatches[i].ProfileID == id {
+				d.importBatches[i].ProfileID = ""
+			}
+		}
+		return nil
+	})
+}
+
+// AddImportBatch stores a batch and all of its staged rows.
+func (p Provider) AddImportBatch(ctx context.Context, batch types.ImportBatch) error {
+	return p.update(func(d *tenantData) error {
+		if d.importBatch(batch.ID) != -1 {
+			return fmt.Errorf("import batch %s already exists", batch.ID)
+		}
+		if batch.ProfileID != "" && !slices.ContainsFunc(d.importProfiles, func(profile types.ImportProfile) bool { return profile.ID == batch.ProfileID }) {
+			return fmt.Errorf("%w: profile_id=%s", backend.ErrImportProfileNotFound, batch.ProfileID)
+		}
+		if batch.UploadedBy != "" && d.member(batch.UploadedBy) == -1 {
+			return fmt.Errorf("%w: %s", backend.ErrMemberNotFound, batch.UploadedBy)
+		}
+		for _, row := range batch.Rows {
+			if slices.ContainsFunc(d.importRows, func(existing importRow) bool { return existing.ID == row.ID }) {
+				return fmt.Errorf("staged row %s already exists", row.ID)
+			}
+			row.Errors = slices.Clone(row.Errors)
+			if len(row.Errors) == 0 {
+				row.Errors = nil
+			}
+			d.importRows = append(d.importRows, importRow{batchID: batch.ID, StagedRow: row})
+		}
+		batch.Rows = nil
+		d.importBatches = append(d.importBatches, batch)
+		return nil
+	})
+}
+
+func (p Provider) GetImportBatch(ctx context.Context, id string) (types.ImportBatch, error) {
+	var batch types.ImportBatch
+	err := p.view(func(d *tenantData) error {
+		i := d.importBatch(id)
+		if i == -1 {
+			p.logger.LogAttrs(ctx, slog.LevelWarn, "No import batch found with given id", slog.String("batch_id", id))
+			return fmt.Errorf("%w: batch_id=%s", backend.ErrImportBatchNotFound, id)
+		}
+		batch = d.importBatches[i]
+		batch.Rows = []types.StagedRow{}
+		for _, row := range d.importRows {
+			if row.batchID == id {
+				batch.Rows = append(batch.Rows, row.StagedRow)
+			}
+		}
+		return nil
+	})
+	slices.SortStableFunc(batch.Rows, func(a, b types.StagedRow) int { return a.Line - b.Line })
+	return batch, err
+}
+
+func (p Provider) UpdateImportRow(ctx context.Context, batchID string, row types.StagedRow) error {
+	return p.update(func(d *tenantData) error {
+		i := slices.IndexFunc(d.importRows, func(existing importRow) bool { return existing.ID == row.ID && existing.batchID == batchID })
+		if i == -1 {
+			return fmt.Errorf("%w: batch_id=%s row_id=%s", backend.ErrImportRowNotFound, batchID, row.ID)
+		}
+		stored := &d.importRows[i].StagedRow
+		stored.MemberID = row.MemberID
+		stored.RequirementID = row.RequirementID
+		stored.MatchScore = row.MatchScore
+		stored.CompletedDate = row.CompletedDate
+		stored.Excluded = row.Excluded
+		stored.Errors = nil
+		if len(row.Errors) > 0 {
+			stored.Errors = slices.Clone(row.Errors)
+		}
+		return nil
+	})
+}
+
+// CommitImportBatch writes the batch's completions as already approved and records them against each member's
+// requirements, marking the batch committed at the same time.
+func (p Provider) CommitImportBatch(ctx context.Context, batchID string, completions []types.Completion) error {
+	return p.update(func(d *tenantData) error {
+		i := d.importBatch(batchID)
+		if i == -1 || d.importBatches[i].Status != types.ImportBatchStaged {
+			return fmt.Errorf("%w: batch_id=%s", backend.ErrImportBatchCommitted, batchID)
+		}
+		d.importBatches[i].Status = types.ImportBatchCommitted
+		for _, c := range completions {
+			if d.completion(c.ID) != -1 {
+				return fmt.Errorf("completion %s already exists", c.ID)
+			}
+			c.TrainerID = ""
+			if err := d.checkCompletionMembers(c); err != nil {
+				return fmt.Errorf("%w: member_id=%s requirement_id=%s", backend.ErrInvalidImport, c.MemberID, c.RequirementID)
+			}
+			d.completions = append(d.completions, c)
+			d.upsertMemberRequirement(c.MemberID, c.RequirementID, c.CompletedDate)
+		}
+		return nil
+	})
+}
+
+func (p Provider) DeleteImportBatch(ctx context.Context, id string) error {
+	return p.update(func(d *tenantData) error {
+		if d.importBatch(id) == -1 {
+			return fmt.Errorf("%w: batch_id=%s", backend.ErrImportBatchNotFound, id)
+		}
+		d.importBatches = slices.DeleteFunc(d.importBatches, func(b types.ImportBatch) bool { return b.ID == id })
+		d.importRows = slices.DeleteFunc(d.importRows, func(row importRow) bool { return row.batchID == id })
+		return nil
+	})
+}
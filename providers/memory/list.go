@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// page sorts the items, then returns the page of them opts asks for, see types.ListOptions.
+func page[T types.Sortable](items []T, opts types.ListOptions) (types.Page[T], error) {
+	var like T
+	zero := like.SortValues(opts.Sort)
+	if zero == nil {
+		return types.Page[T]{}, fmt.Errorf("%w: can't sort by %q", backend.ErrInvalidListOptions, opts.Sort)
+	}
+	after, err := types.DecodeCursor(opts.Cursor, zero)
+	if err != nil {
+		return types.Page[T]{}, fmt.Errorf("%w: %s", backend.ErrInvalidListOptions, err.Error())
+	}
+	direction := 1
+	if opts.Descending {
+		direction = -1
+	}
+	slices.SortFunc(items, func(a, b T) int {
+		return direction * compareSortValues(a.SortValues(opts.Sort), b.SortValues(opts.Sort))
+	})
+	start := 0
+	if after != nil {
+		start = len(items)
+		for i, item := range items {
+			if direction*compareSortValues(item.SortValues(opts.Sort), after) > 0 {
+				start = i
+				break
+			}
+		}
+	}
+	return types.NewPage(items[start:min(len(items), start+opts.Limit+1)], len(items), opts), nil
+}
+
+// compareSortValues compares the sort values of two items sorted by the same key.
+func compareSortValues(a, b []any) int {
+	for i := range a {
+		var c int
+		switch v := a[i].(type) {
+		case string:
+			c = strings.Compare(v, b[i].(string))
+		case int64:
+			c = cmp.Compare(v, b[i].(int64))
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
@@ -0,0 +1,321 @@
+package memory
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+)
+
+func (d *tenantData) member(id string) int {
+	return slices.IndexFunc(d.members, func(m types.Member) bool { return m.ID == id })
+}
+
+// checkMemberUnique returns the error the databases' unique constraints would for m, ignoring the member m replaces.
+func (d *tenantData) checkMemberUnique(m types.Member) error {
+	for _, existing := range d.members {
+		if existing.ID == m.ID {
+			continue
+		}
+		if existing.Username == m.Username {
+			return fmt.Errorf("%w: %s", backend.ErrDuplicateUsername, m.Username)
+		}
+		if m.CertificateID != "" && existing.CertificateID == m.CertificateID {
+			return fmt.Errorf("%w: %s", backend.ErrDuplicateCertificate, m.CertificateID)
+		}
+	}
+	return nil
+}
+
+// insertMember stores a new member the way the databases' insert does, without a unit or archive.
+func (d *tenantData) insertMember(m types.Member) error {
+	if d.member(m.ID) != -1 {
+		return fmt.Errorf("member %s already exists", m.ID)
+	}
+	if err := d.checkMemberUnique(m); err != nil {
+		return err
+	}
+	m.Password = ""
+	m.UnitID = ""
+	m.Archive = nil
+	m.Version = 1
+	d.members = append(d.members, m)
+	return nil
+}
+
+func (p Provider) AddMember(ctx context.Context, m types.Member) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Inserting member into memory", slog.Any("member", m))
+	return p.update(func(d *tenantData) error {
+		if err := d.insertMember(m); err != nil {
+			return err
+		}
+		if m.SupervisorID != "" && d.member(m.SupervisorID) == -1 {
+			return fmt.Errorf("%w: %s", backend.ErrSupervisorNotFound, m.SupervisorID)
+		}
+		return nil
+	})
+}
+
+// AddMembers inserts every member or none of them. Supervisors are set once everyone is inserted so members can be
+// supervised by others in the same batch regardless of order.
+func (p Provider) AddMembers(ctx context.Context, members []types.Member) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Inserting members into memory", slog.Int("count", len(members)))
+	return p.update(func(d *tenantData) error {
+		for _, m := range members {
+			supervisorID := m.SupervisorID
+			m.SupervisorID = ""
+			if err := d.insertMember(m); err != nil {
+				return err
+			}
+			m.SupervisorID = supervisorID
+		}
+		for _, m := range members {
+			if m.SupervisorID == "" {
+				continue
+			}
+			if d.member(m.SupervisorID) == -1 {
+				return fmt.Errorf("%w: %s", backend.ErrSupervisorNotFound, m.SupervisorID)
+			}
+			d.members[d.member(m.ID)].SupervisorID = m.SupervisorID
+		}
+		return nil
+	})
+}
+
+func (p Provider) GetMember(ctx context.Context, identifier string, method backend.ProviderMethod) (types.Member, error) {
+	var match func(types.Member) bool
+	switch method {
+	case backend.ById:
+		match = func(m types.Member) bool { return m.ID == identifier }
+	case backend.ByUsername:
+		match = func(m types.Member) bool { return m.Username == identifier }
+	case backend.ByCertificateID:
+		match = func(m types.Member) bool { return m.CertificateID != "" && m.CertificateID == identifier }
+	default:
+		return types.Member{}, errors.New(fmt.Sprintf("unexpected retrieval method: %d", method))
+	}
+	var m types.Member
+	err := p.view(func(d *tenantData) error {
+		i := slices.IndexFunc(d.members, match)
+		if i == -1 {
+			return backend.ErrMemberNotFound
+		}
+		m = copyMember(d.members[i])
+		return nil
+	})
+	return m, err
+}
+
+func (p Provider) GetAllMembers(ctx context.Context) ([]types.Member, error) {
+	return p.members(func(m types.Member) bool { return m.Archive == nil }, false)
+}
+
+func (p Provider) ListMembers(ctx context.Context, opts types.MemberListOptions) (types.Page[types.Member], error) {
+	var members []types.Member
+	err := p.view(func(d *tenantData) error {
+		for _, m := range d.members {
+			if d.listed(m, opts) {
+				members = append(members, copyMember(m))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return types.Page[types.Member]{}, err
+	}
+	return page(members, opts.ListOptions)
+}
+
+// listed tells whether the member passes every filter in opts.
+func (d *tenantData) listed(m types.Member, opts types.MemberListOptions) bool {
+	switch {
+	case opts.Archived == types.MemberActive && m.Archive != nil, opts.Archived == types.MemberArchived && m.Archive == nil:
+		return false
+	case opts.Rank != "" && m.Rank != opts.Rank, opts.SupervisorID != "" && m.SupervisorID != opts.SupervisorID:
+		return false
+	case opts.Admin != nil && m.Admin != *opts.Admin:
+		return false
+	case opts.QualificationID != "" && !d.memberQualifications.has(m.ID, opts.QualificationID):
+		return false
+	case len(opts.UnitIDs) > 0 && !slices.Contains(opts.UnitIDs, m.UnitID):
+		return false
+	}
+	return true
+}
+
+func (p Provider) GetSubordinates(ctx context.Context, memberID string) ([]types.Member, error) {
+	return p.members(func(m types.Member) bool { return m.SupervisorID == memberID && m.Archive == nil }, false)
+}
+
+func (p Provider) GetArchivedMembers(ctx context.Context) ([]types.Member, error) {
+	members, err := p.members(func(m types.Member) bool { return m.Archive != nil }, true)
+	slices.SortStableFunc(members, func(a, b types.Member) int { return a.Archive.Date.Compare(b.Archive.Date) })
+	return members, err
+}
+
+// members returns copies of the members matching, nil if there aren't any unless empty is set.
+func (p Provider) members(match func(types.Member) bool, empty bool) ([]types.Member, error) {
+	var members []types.Member
+	if empty {
+		members = []types.Member{}
+	}
+	err := p.view(func(d *tenantData) error {
+		for _, m := range d.members {
+			if match(m) {
+				members = append(members, copyMember(m))
+			}
+		}
+		return nil
+	})
+	return members, err
+}
+
+// UpdateMember replaces the member's details. Their username, unit and archive are left alone.
+func (p Provider) UpdateMember(ctx context.Context, m types.Member) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Updating member", slog.Any("member", m))
+	return p.update(func(d *tenantData) error {
+		return d.updateMember(m)
+	})
+}
+
+func (d *tenantData) updateMember(m types.Member) error {
+	i := d.member(m.ID)
+	if i == -1 {
+		return backend.ErrMemberNotFound
+	}
+	m.Username = d.members[i].Username
+	if err := d.checkMemberUnique(m); err != nil {
+		return err
+	}
+	version, err := nextVersion(d.members[i].Version, m.Version)
+	if err != nil {
+		return err
+	}
+	if m.SupervisorID != "" && d.member(m.SupervisorID) == -1 {
+		return backend.ErrSupervisorNotFound
+	}
+	existing := &d.members[i]
+	existing.FirstName = m.FirstName
+	existing.LastName = m.LastName
+	existing.Rank = m.Rank
+	existing.SupervisorID = m.SupervisorID
+	existing.Admin = m.Admin
+	existing.Role = m.Role
+	existing.Hash = m.Hash
+	existing.CertificateID = m.CertificateID
+	existing.Email = m.Email
+	existing.Version = version
+	return nil
+}
+
+// DeleteMember removes the member along with everything recorded about them, including qualification history.
+// References to them from other members' records are cleared.
+func (p Provider) DeleteMember(ctx context.Context, identifier string, method backend.ProviderMethod) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting member", slog.String("identifier", identifier))
+	m, err := p.GetMember(ctx, identifier, method)
+	if err != nil {
+		return err
+	}
+	return p.update(func(d *tenantData) error {
+		if d.member(m.ID) == -1 {
+			return backend.ErrMemberNotFound
+		}
+		d.members = slices.DeleteFunc(d.members, func(existing types.Member) bool { return existing.ID == m.ID })
+		for i := range d.members {
+			if d.members[i].SupervisorID == m.ID {
+				d.members[i].SupervisorID = ""
+			}
+		}
+		d.history = slices.DeleteFunc(d.history, func(e types.QualificationEvent) bool { return e.MemberID == m.ID })
+		d.memberRequirements = slices.DeleteFunc(d.memberRequirements, func(r memberRequirement) bool { return r.memberID == m.ID })
+		d.apiTokens = slices.DeleteFunc(d.apiTokens, func(t types.APIToken) bool { return t.MemberID == m.ID })
+		d.completions = slices.DeleteFunc(d.completions, func(c types.Completion) bool { return c.MemberID == m.ID })
+		for i := range d.completions {
+			if d.completions[i].TrainerID == m.ID {
+				d.completions[i].TrainerID = ""
+			}
+			if d.completions[i].CertifierID == m.ID {
+				d.completions[i].CertifierID = ""
+			}
+		}
+		d.waivers = slices.DeleteFunc(d.waivers, func(w waiver) bool { return w.MemberID == m.ID })
+		for i := range d.waivers {
+			if d.waivers[i].ApproverID == m.ID {
+				d.waivers[i].ApproverID = ""
+			}
+		}
+		for i := range d.importBatches {
+			if d.importBatches[i].UploadedBy == m.ID {
+				d.importBatches[i].UploadedBy = ""
+			}
+		}
+		d.memberQualifications.removeLeft(m.ID)
+		d.transfers = slices.DeleteFunc(d.transfers, func(mt memberTransfer) bool { return mt.memberID == m.ID })
+		maps.DeleteFunc(d.qualificationSources, func(l link, _ string) bool { return l.left == m.ID })
+		d.certifiers.removeRight(m.ID)
+		d.memberDutyPositions.removeLeft(m.ID)
+		d.unitAdmins.removeRight(m.ID)
+		return nil
+	})
+}
+
+// ArchiveMember marks the member archived and revokes their api tokens so archived members can't keep using them.
+func (p Provider) ArchiveMember(ctx context.Context, memberID string, archive types.MemberArchive) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Archiving member", slog.String("member_id", memberID))
+	return p.update(func(d *tenantData) error {
+		i := d.member(memberID)
+		if i == -1 {
+			return backend.ErrMemberNotFound
+		}
+		d.members[i].Archive = &archive
+		d.members[i].Version++
+		d.apiTokens = slices.DeleteFunc(d.apiTokens, func(t types.APIToken) bool { return t.MemberID == memberID })
+		return nil
+	})
+}
+
+func (p Provider) RestoreMember(ctx context.Context, memberID string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Restoring archived member", slog.String("member_id", memberID))
+	return p.update(func(d *tenantData) error {
+		i := d.member(memberID)
+		if i == -1 {
+			return backend.ErrMemberNotFound
+		}
+		d.members[i].Archive = nil
+		d.members[i].Version++
+		return nil
+	})
+}
+
+// copyMember copies the member's archive too, so callers can't change the stored one through it.
+func copyMember(m types.Member) types.Member {
+	if m.Archive != nil {
+		archive := *m.Archive
+		m.Archive = &archive
+	}
+	return m
+}
+
+// upsertMemberRequirement records a completion of the requirement, keeping the most recent completion date.
+func (d *tenantData) upsertMemberRequirement(memberID, requirementID string, completed time.Time) {
+	i := slices.IndexFunc(d.memberRequirements, func(r memberRequirement) bool {
+		return r.memberID == memberID && r.requirementID == requirementID
+	})
+	if i == -1 {
+		d.memberRequirements = append(d.memberRequirements, memberRequirement{
+			memberID:          memberID,
+			requirementID:     requirementID,
+			initialCompletion: completed,
+			mostRecent:        completed,
+		})
+		return
+	}
+	if completed.After(d.memberRequirements[i].mostRecent) {
+		d.memberRequirements[i].mostRecent = completed
+	}
+}
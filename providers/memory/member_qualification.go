@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"slices"
+)
+
+func (p Provider) AssignMemberQualification(ctx context.Context, memberID, qualificationID string) error {
+	return p.update(func(d *tenantData) error {
+		return d.assignMemberQualification(memberID, qualificationID)
+	})
+}
+
+// AssignMemberPositionQualification assigns the qualification on behalf of the duty position.
+func (p Provider) AssignMemberPositionQualification(ctx context.Context, memberID, qualificationID, positionID string) error {
+	return p.update(func(d *tenantData) error {
+		if err := d.assignMemberQualification(memberID, qualificationID); err != nil {
+			return err
+		}
+		d.qualificationSources[link{memberID, qualificationID}] = positionID
+		return nil
+	})
+}
+
+// GetMemberPositionQualificationIDs returns the member's qualifications that were assigned by a duty position.
+func (p Provider) GetMemberPositionQualificationIDs(ctx context.Context, memberID string) ([]string, error) {
+	ids := []string{}
+	err := p.view(func(d *tenantData) error {
+		for l := range d.qualificationSources {
+			if l.left == memberID {
+				ids = append(ids, l.right)
+			}
+		}
+		return nil
+	})
+	slices.Sort(ids)
+	return ids, err
+}
+
+func (d *tenantData) assignMemberQualification(memberID, qualificationID string) error {
+	if d.memberQualifications.has(memberID, qualificationID) {
+		return fmt.Errorf("%w: member_id=%s qualification_id=%s", backend.ErrQualificationAlreadyAssigned, memberID, qualificationID)
+	}
+	if d.member(memberID) == -1 {
+		return fmt.Errorf("%w: %s", backend.ErrMemberNotFound, memberID)
+	}
+	if d.qualification(qualificationID) == -1 {
+		return fmt.Errorf("%w: %s", backend.ErrQualificationNotFound, qualificationID)
+	}
+	d.memberQualifications.add(memberID, qualificationID)
+	return nil
+}
+
+func (p Provider) GetMemberQualification(ctx context.Context, memberID, qualificationID string) (types.Qualification, error) {
+	var q types.Qualification
+	err := p.view(func(d *tenantData) error {
+		if !d.memberQualifications.has(memberID, qualificationID) {
+			return backend.ErrMemberQualificationNotFound
+		}
+		var err error
+		q, err = d.getQualification(qualificationID)
+		return err
+	})
+	return q, err
+}
+
+func (p Provider) GetMemberQualifications(ctx context.Context, memberID string) ([]types.Qualification, error) {
+	var quals []types.Qualification
+	err := p.view(func(d *tenantData) error {
+		ids := d.memberQualifications.rights(memberID)
+		quals = make([]types.Qualification, 0, len(ids))
+		for _, id := range ids {
+			q, err := d.getQualification(id)
+			if err != nil {
+				return err
+			}
+			quals = append(quals, q)
+		}
+		return nil
+	})
+	sortQualifications(quals)
+	return quals, err
+}
+
+func (p Provider) RemoveMemberQualification(ctx context.Context, memberID, qualificationID string) error {
+	return p.update(func(d *tenantData) error {
+		return d.removeMemberQualification(memberID, qualificationID)
+	})
+}
+
+func (d *tenantData) removeMemberQualification(memberID, qualificationID string) error {
+	if !d.memberQualifications.remove(memberID, qualificationID) {
+		return fmt.Errorf("%w: member_id: %s, qualification_id: %s", backend.ErrMemberQualificationNotFound, memberID, qualificationID)
+	}
+	delete(d.qualificationSources, link{memberID, qualificationID})
+	return nil
+}
+
+// AssignMemberQualifications assigns every pair it can. A pair that can't be assigned doesn't fail the batch, its
+// error is returned at the same index instead.
+func (p Provider) AssignMemberQualifications(ctx context.Context, pairs []types.MemberQualificationPair) ([]error, error) {
+	return p.bulkMemberQualifications(pairs, (*tenantData).assignMemberQualification)
+}
+
+// RemoveMemberQualifications is the bulk counterpart to RemoveMemberQualification, see AssignMemberQualifications.
+func (p Provider) RemoveMemberQualifications(ctx context.Context, pairs []types.MemberQualificationPair) ([]error, error) {
+	return p.bulkMemberQualifications(pairs, (*tenantData).removeMemberQualification)
+}
+
+func (p Provider) bulkMemberQualifications(pairs []types.MemberQualificationPair, apply func(d *tenantData, memberID, qualificationID string) error) ([]error, error) {
+	errs := make([]error, len(pairs))
+	err := p.update(func(d *tenantData) error {
+		for i, pair := range pairs {
+			errs[i] = apply(d, pair.MemberID, pair.QualificationID)
+		}
+		return nil
+	})
+	return errs, err
+}
+
+// GetMemberRequirements returns the requirements the member has an approved completion for, dated by the most recent
+// completion.
+func (p Provider) GetMemberRequirements(ctx context.Context, memberID string) ([]types.MemberRequirement, error) {
+	reqs := []types.MemberRequirement{}
+	err := p.view(func(d *tenantData) error {
+		for _, r := range d.memberRequirements {
+			if r.memberID != memberID {
+				continue
+			}
+			req, err := d.getRequirement(r.requirementID)
+			if err != nil {
+				return err
+			}
+			reqs = append(reqs, types.MemberRequirement{MemberID: memberID, Requirement: req, Completed: true, CompletedDate: r.mostRecent})
+		}
+		return nil
+	})
+	return reqs, err
+}
+
+func (p Provider) AddQualificationEvent(ctx context.Context, e types.QualificationEvent) error {
+	return p.update(func(d *tenantData) error {
+		d.history = append(d.history, e)
+		return nil
+	})
+}
+
+// GetQualificationHistory returns the member's events for the qualification, oldest first.
+func (p Provider) GetQualificationHistory(ctx context.Context, memberID, qualificationID string) ([]types.QualificationEvent, error) {
+	events := []types.QualificationEvent{}
+	err := p.view(func(d *tenantData) error {
+		for _, e := range d.history {
+			if e.MemberID == memberID && e.QualificationID == qualificationID {
+				events = append(events, e)
+			}
+		}
+		return nil
+	})
+	slices.SortStableFunc(events, func(a, b types.QualificationEvent) int { return a.Time.Compare(b.Time) })
+	return events, err
+}
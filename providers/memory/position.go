@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+func (d *tenantData) dutyPosition(id string) int {
+	return slices.IndexFunc(d.dutyPositions, func(p types.DutyPosition) bool { return p.ID == id })
+}
+
+func (d *tenantData) getDutyPosition(i int) types.DutyPosition {
+	position := d.dutyPositions[i]
+	position.Qualifications = d.positionQualifications.rights(position.ID)
+	slices.Sort(position.Qualifications)
+	return position
+}
+
+// setDutyPositionQualifications replaces the position's qualification bundle.
+func (d *tenantData) setDutyPositionQualifications(position types.DutyPosition) error {
+	d.positionQualifications.removeLeft(position.ID)
+	for _, qualificationID := range position.Qualifications {
+		if d.qualification(qualificationID) == -1 {
+			return fmt.Errorf("%w: %s", backend.ErrQualificationNotFound, qualificationID)
+		}
+		d.positionQualifications.add(position.ID, qualificationID)
+	}
+	return nil
+}
+
+func (d *tenantData) checkDutyPositionName(position types.DutyPosition) error {
+	if slices.ContainsFunc(d.dutyPositions, func(existing types.DutyPosition) bool {
+		return existing.Name == position.Name && existing.ID != position.ID
+	}) {
+		return fmt.Errorf("%w: %s", backend.ErrDuplicateDutyPosition, position.Name)
+	}
+	return nil
+}
+
+func (p Provider) AddDutyPosition(ctx context.Context, position types.DutyPosition) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding duty position to memory", slog.String("position_id", position.ID), slog.String("name", position.Name))
+	return p.update(func(d *tenantData) error {
+		if d.dutyPosition(position.ID) != -1 {
+			return fmt.Errorf("duty position %s already exists", position.ID)
+		}
+		if err := d.checkDutyPositionName(position); err != nil {
+			return err
+		}
+		d.dutyPositions = append(d.dutyPositions, types.DutyPosition{ID: position.ID, Name: position.Name, Description: position.Description, Version: 1})
+		return d.setDutyPositionQualifications(position)
+	})
+}
+
+func (p Provider) GetDutyPosition(ctx context.Context, id string) (types.DutyPosition, error) {
+	var position types.DutyPosition
+	err := p.view(func(d *tenantData) error {
+		i := d.dutyPosition(id)
+		if i == -1 {
+			return fmt.Errorf("%w: position_id=%s", backend.ErrDutyPositionNotFound, id)
+		}
+		position = d.getDutyPosition(i)
+		return nil
+	})
+	return position, err
+}
+
+func (p Provider) GetDutyPositions(ctx context.Context) ([]types.DutyPosition, error) {
+	positions := []types.DutyPosition{}
+	err := p.view(func(d *tenantData) error {
+		for i := range d.dutyPositions {
+			positions = append(positions, d.getDutyPosition(i))
+		}
+		return nil
+	})
+	slices.SortStableFunc(positions, func(a, b types.DutyPosition) int { return strings.Compare(a.Name, b.Name) })
+	return positions, err
+}
+
+// UpdateDutyPosition replaces the position's details and qualification bundle. Propagating the bundle to members is
+// left to the caller.
+func (p Provider) UpdateDutyPosition(ctx context.Context, position types.DutyPosition) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Updating duty position", slog.String("position_id", position.ID))
+	return p.update(func(d *tenantData) error {
+		i := d.dutyPosition(position.ID)
+		if i == -1 {
+			return fmt.Errorf("%w: position_id=%s", backend.ErrDutyPositionNotFound, position.ID)
+		}
+		if err := d.checkDutyPositionName(position); err != nil {
+			return err
+		}
+		version, err := nextVersion(d.dutyPositions[i].Version, position.Version)
+		if err != nil {
+			return err
+		}
+		d.dutyPositions[i].Version = version
+		d.dutyPositions[i].Name = position.Name
+		d.dutyPositions[i].Description = position.Description
+		return d.setDutyPositionQualifications(position)
+	})
+}
+
+func (p Provider) DeleteDutyPosition(ctx context.Context, id string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting duty position", slog.String("position_id", id))
+	return p.update(func(d *tenantData) error {
+		if d.dutyPosition(id) == -1 {
+			return fmt.Errorf("%w: position_id=%s", backend.ErrDutyPositionNotFound, id)
+		}
+		d.dutyPositions = slices.DeleteFunc(d.dutyPositions, func(position types.DutyPosition) bool { return position.ID == id })
+		d.positionQualifications.removeLeft(id)
+		d.memberDutyPositions.removeRight(id)
+		return nil
+	})
+}
+
+func (p Provider) AssignMemberDutyPosition(ctx context.Context, memberID, positionID string) error {
+	return p.update(func(d *tenantData) error {
+		if d.memberDutyPositions.has(memberID, positionID) {
+			return fmt.Errorf("%w: member_id=%s position_id=%s", backend.ErrDutyPositionAlreadyAssigned, memberID, positionID)
+		}
+		if d.member(memberID) == -1 {
+			return backend.ErrMemberNotFound
+		}
+		if d.dutyPosition(positionID) == -1 {
+			return fmt.Errorf("%w: position_id=%s", backend.ErrDutyPositionNotFound, positionID)
+		}
+		d.memberDutyPositions.add(memberID, positionID)
+		return nil
+	})
+}
+
+func (p Provider) GetMemberDutyPositionIDs(ctx context.Context, memberID string) ([]string, error) {
+	var ids []string
+	err := p.view(func(d *tenantData) error {
+		ids = d.memberDutyPositions.rights(memberID)
+		return nil
+	})
+	return ids, err
+}
+
+// GetDutyPositionMemberIDs returns the members holding the position, leaving out archived members.
+func (p Provider) GetDutyPositionMemberIDs(ctx context.Context, positionID string) ([]string, error) {
+	var ids []string
+	err := p.view(func(d *tenantData) error {
+		ids = slices.DeleteFunc(d.memberDutyPositions.lefts(positionID), func(id string) bool {
+			return d.members[d.member(id)].Archive != nil
+		})
+		return nil
+	})
+	return ids, err
+}
+
+func (p Provider) RemoveMemberDutyPosition(ctx context.Context, memberID, positionID string) error {
+	return p.update(func(d *tenantData) error {
+		if !d.memberDutyPositions.remove(memberID, positionID) {
+			return fmt.Errorf("%w: member_id=%s position_id=%s", backend.ErrMemberDutyPositionNotFound, memberID, positionID)
+		}
+		return nil
+	})
+}
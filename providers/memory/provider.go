@@ -0,0 +1,284 @@
+package memory
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Provider keeps everything in memory, for tests and demo deployments that don't need anything to outlive the process.
+// It enforces the same uniqueness, foreign keys and cascades as the database providers so the backend can't tell them
+// apart. Like them, each Provider only sees the data of the tenant it's scoped to, see ForTenant.
+type Provider struct {
+	logger *slog.Logger
+	store  *store
+	tenant string
+	work   *unitOfWork
+}
+
+// unitOfWork is the working copy of a tenant's data a transaction reads and changes, see WithTx. It replaces the
+// tenant's data when the transaction commits.
+type unitOfWork struct {
+	data *tenantData
+}
+
+// store is shared by every Provider scoped from the same New.
+type store struct {
+	mu      sync.Mutex
+	tenants []types.Tenant
+	data    map[string]*tenantData
+}
+
+// link is a row of a join table, e.g. a member and a qualification they hold.
+type link struct {
+	left, right string
+}
+
+type links []link
+
+func (l links) has(left, right string) bool {
+	return slices.Contains(l, link{left, right})
+}
+
+// rights returns what's linked to left, in the order the links were added.
+func (l links) rights(left string) []string {
+	ids := []string{}
+	for _, row := range l {
+		if row.left == left {
+			ids = append(ids, row.right)
+		}
+	}
+	return ids
+}
+
+// lefts returns what's linked to right, in the order the links were added.
+func (l links) lefts(right string) []string {
+	ids := []string{}
+	for _, row := range l {
+		if row.right == right {
+			ids = append(ids, row.left)
+		}
+	}
+	return ids
+}
+
+func (l *links) add(left, right string) {
+	*l = append(*l, link{left, right})
+}
+
+// remove deletes the link, reporting whether there was one.
+func (l *links) remove(left, right string) bool {
+	n := len(*l)
+	*l = slices.DeleteFunc(*l, func(row link) bool { return row == link{left, right} })
+	return len(*l) != n
+}
+
+func (l *links) removeLeft(left string) {
+	*l = slices.DeleteFunc(*l, func(row link) bool { return row.left == left })
+}
+
+func (l *links) removeRight(right string) {
+	*l = slices.DeleteFunc(*l, func(row link) bool { return row.right == right })
+}
+
+type memberRequirement struct {
+	memberID          string
+	requirementID     string
+	initialCompletion time.Time
+	mostRecent        time.Time
+}
+
+type waiver struct {
+	types.Waiver
+	memo *types.WaiverMemo
+}
+
+type importRow struct {
+	batchID string
+	types.StagedRow
+}
+
+type memberTransfer struct {
+	packageID, issuer, issuerMemberID, memberID string
+}
+
+// tenantData holds one tenant's rows. Slices keep rows in the order they were added, which is the order the database
+// providers return them in when a query doesn't sort.
+type tenantData struct {
+	members            []types.Member
+	memberRequirements []memberRequirement
+	apiTokens          []types.APIToken
+	rolePermissions    map[types.Role][]types.Permission
+	waivers            []waiver
+	auditEntries       []types.AuditEntry
+	history            []types.QualificationEvent
+	transfers          []memberTransfer
+
+	// Qualifications and requirements are stored without the requirements, references and prerequisites they're
+	// linked to, which are joined in when they're read.
+	qualifications         []types.Qualification
+	requirements           []types.Requirement
+	references             []types.Reference
+	completions            []types.Completion
+	importProfiles         []types.ImportProfile
+	importBatches          []types.ImportBatch
+	importRows             []importRow
+	dutyPositions          []types.DutyPosition
+	units                  []types.Unit
+	memberQualifications   links
+	initialRequirements    links
+	recurringRequirements  links
+	prerequisites          links
+	certifiers             links
+	positionQualifications links
+	memberDutyPositions    links
+	unitQualifications     links
+	unitAdmins             links
+	// qualificationSources holds the position that assigned each member qualification a position assigned
+	qualificationSources map[link]string
+}
+
+func newTenantData() *tenantData {
+	d := &tenantData{rolePermissions: map[types.Role][]types.Permission{}, qualificationSources: map[link]string{}}
+	for role, permissions := range types.DefaultRolePermissions {
+		d.rolePermissions[role] = slices.Clone(permissions)
+	}
+	return d
+}
+
+// clone copies every table so changes to the copy don't show through to d.
+func (d *tenantData) clone() *tenantData {
+	c := *d
+	c.members = slices.Clone(d.members)
+	c.memberRequirements = slices.Clone(d.memberRequirements)
+	c.apiTokens = slices.Clone(d.apiTokens)
+	c.rolePermissions = maps.Clone(d.rolePermissions)
+	c.waivers = slices.Clone(d.waivers)
+	c.auditEntries = slices.Clone(d.auditEntries)
+	c.history = slices.Clone(d.history)
+	c.transfers = slices.Clone(d.transfers)
+	c.qualifications = slices.Clone(d.qualifications)
+	c.requirements = slices.Clone(d.requirements)
+	c.references = slices.Clone(d.references)
+	c.completions = slices.Clone(d.completions)
+	c.importProfiles = slices.Clone(d.importProfiles)
+	c.importBatches = slices.Clone(d.importBatches)
+	c.importRows = slices.Clone(d.importRows)
+	c.dutyPositions = slices.Clone(d.dutyPositions)
+	c.units = slices.Clone(d.units)
+	c.memberQualifications = slices.Clone(d.memberQualifications)
+	c.initialRequirements = slices.Clone(d.initialRequirements)
+	c.recurringRequirements = slices.Clone(d.recurringRequirements)
+	c.prerequisites = slices.Clone(d.prerequisites)
+	c.certifiers = slices.Clone(d.certifiers)
+	c.positionQualifications = slices.Clone(d.positionQualifications)
+	c.memberDutyPositions = slices.Clone(d.memberDutyPositions)
+	c.unitQualifications = slices.Clone(d.unitQualifications)
+	c.unitAdmins = slices.Clone(d.unitAdmins)
+	c.qualificationSources = maps.Clone(d.qualificationSources)
+	return &c
+}
+
+// New returns an empty provider with just the default tenant everything belongs to in single tenant deployments.
+func New(logger *slog.Logger) Provider {
+	l := logger.With(slog.String("source", "memory_backend"))
+	l.LogAttrs(context.Background(), slog.LevelInfo, "Storing everything in memory, nothing will be kept after shutdown")
+	return Provider{
+		logger: l,
+		store: &store{
+			tenants: []types.Tenant{{ID: types.DefaultTenantID, Name: "Default"}},
+			data:    map[string]*tenantData{types.DefaultTenantID: newTenantData()},
+		},
+		tenant: types.DefaultTenantID,
+	}
+}
+
+// ForTenant returns a provider sharing the same store that only sees the given tenant's data.
+func (p Provider) ForTenant(tenantID string) Provider {
+	p.logger = p.logger.With(slog.String("tenant_id", tenantID))
+	p.tenant = tenantID
+	return p
+}
+
+// Scoped is ForTenant for the backend, which keeps members, qualifications and requirements behind separate providers.
+func (p Provider) Scoped(tenantID string) (backend.MemberProvider, backend.QualificationProvider, backend.RequirementProvider) {
+	scoped := p.ForTenant(tenantID)
+	return scoped, scoped, scoped
+}
+
+// WithTx runs fn against a copy of the provider working on its own copy of the tenant's data, see backend.Transactor.
+// The store stays locked until fn returns, so fn mustn't use providers outside the transaction.
+func (p Provider) WithTx(ctx context.Context, fn func(tx backend.Providers) error) error {
+	if p.work != nil {
+		saved := p.work.data
+		if err := fn(backend.Providers{Members: p, Qualifications: p, Requirements: p}); err != nil {
+			p.work.data = saved
+			return err
+		}
+		return nil
+	}
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+	p.work = &unitOfWork{data: p.data()}
+	if err := fn(backend.Providers{Members: p, Qualifications: p, Requirements: p}); err != nil {
+		return err
+	}
+	p.store.data[p.tenant] = p.work.data
+	return nil
+}
+
+// view runs fn with the tenant's data locked, or on the working copy within a transaction.
+func (p Provider) view(fn func(d *tenantData) error) error {
+	if p.work != nil {
+		return fn(p.work.data)
+	}
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+	return fn(p.data())
+}
+
+// update runs fn with the tenant's data locked. If fn fails every change it made is undone, so multi-step changes are
+// all-or-nothing like the database providers' transactions.
+func (p Provider) update(fn func(d *tenantData) error) error {
+	if p.work != nil {
+		d := p.work.data.clone()
+		if err := fn(d); err != nil {
+			return err
+		}
+		p.work.data = d
+		return nil
+	}
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+	d := p.data().clone()
+	if err := fn(d); err != nil {
+		return err
+	}
+	p.store.data[p.tenant] = d
+	return nil
+}
+
+// data returns the tenant's data, which is empty for tenants nothing has been stored for. The store must be locked.
+func (p Provider) data() *tenantData {
+	d, ok := p.store.data[p.tenant]
+	if !ok {
+		d = &tenantData{rolePermissions: map[types.Role][]types.Permission{}}
+		p.store.data[p.tenant] = d
+	}
+	return d
+}
+
+// nextVersion returns the version a record at stored moves to when updated, failing if the update was made against
+// another version, unless that version is zero.
+func nextVersion(stored, version int) (int, error) {
+	if version != 0 && version != stored {
+		return 0, fmt.Errorf("%w: at version %d, not %d", backend.ErrVersionConflict, stored, version)
+	}
+	return stored + 1, nil
+}
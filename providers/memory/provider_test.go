@@ -0,0 +1,14 @@
+package memory_test
+
+import (
+	"PORTal/providers/memory"
+	"PORTal/providers/providertest"
+	"log/slog"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	providertest.Run(t, func(t *testing.T) providertest.Provider {
+		return memory.New(slog.Default())
+	})
+}
@@ -0,0 +1,193 @@
+package memory
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+)
+
+func (d *tenantData) qualification(id string) int {
+	return slices.IndexFunc(d.qualifications, func(q types.Qualification) bool { return q.ID == id })
+}
+
+// getQualification joins in the qualification's requirements and prerequisites.
+func (d *tenantData) getQualification(id string) (types.Qualification, error) {
+	i := d.qualification(id)
+	if i == -1 {
+		return types.Qualification{}, backend.ErrQualificationNotFound
+	}
+	q := d.qualifications[i]
+	for _, requirementID := range d.initialRequirements.rights(id) {
+		req, err := d.getRequirement(requirementID)
+		if err != nil {
+			return types.Qualification{}, err
+		}
+		q.InitialRequirements = append(q.InitialRequirements, req)
+	}
+	for _, requirementID := range d.recurringRequirements.rights(id) {
+		req, err := d.getRequirement(requirementID)
+		if err != nil {
+			return types.Qualification{}, err
+		}
+		q.RecurringRequirements = append(q.RecurringRequirements, req)
+	}
+	slices.SortStableFunc(q.InitialRequirements, func(a, b types.Requirement) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortStableFunc(q.RecurringRequirements, func(a, b types.Requirement) int { return strings.Compare(a.Name, b.Name) })
+	if prerequisites := d.prerequisites.rights(id); len(prerequisites) > 0 {
+		slices.Sort(prerequisites)
+		q.Prerequisites = prerequisites
+	}
+	return q, nil
+}
+
+// setQualificationLinks replaces the qualification's requirements and prerequisites. Requirements it already has keep
+// their place.
+func (d *tenantData) setQualificationLinks(q types.Qualification) error {
+	for _, set := range []struct {
+		links        *links
+		requirements []types.Requirement
+	}{{&d.initialRequirements, q.InitialRequirements}, {&d.recurringRequirements, q.RecurringRequirements}} {
+		*set.links = slices.DeleteFunc(*set.links, func(row link) bool {
+			return row.left == q.ID && !slices.ContainsFunc(set.requirements, func(r types.Requirement) bool { return r.ID == row.right })
+		})
+		for _, r := range set.requirements {
+			if set.links.has(q.ID, r.ID) {
+				continue
+			}
+			if d.requirement(r.ID) == -1 {
+				return fmt.Errorf("%w: %s", backend.ErrRequirementNotFound, r.ID)
+			}
+			set.links.add(q.ID, r.ID)
+		}
+	}
+	d.prerequisites.removeLeft(q.ID)
+	for _, prerequisiteID := range q.Prerequisites {
+		if d.qualification(prerequisiteID) == -1 {
+			return fmt.Errorf("%w: %s", backend.ErrQualificationNotFound, prerequisiteID)
+		}
+		d.prerequisites.add(q.ID, prerequisiteID)
+	}
+	return nil
+}
+
+func (p Provider) AddQualification(ctx context.Context, q types.Qualification) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding qualification to memory", slog.String("qualification_id", q.ID))
+	return p.update(func(d *tenantData) error {
+		if d.qualification(q.ID) != -1 {
+			return fmt.Errorf("qualification %s already exists", q.ID)
+		}
+		if slices.ContainsFunc(d.qualifications, func(existing types.Qualification) bool { return existing.Name == q.Name }) {
+			return fmt.Errorf("qualification named %s already exists", q.Name)
+		}
+		d.qualifications = append(d.qualifications, types.Qualification{
+			ID:             q.ID,
+			Name:           q.Name,
+			Notes:          q.Notes,
+			Expires:        q.Expires,
+			ExpirationDays: q.ExpirationDays,
+			Version:        1,
+		})
+		return d.setQualificationLinks(q)
+	})
+}
+
+func (p Provider) GetQualification(ctx context.Context, id string) (types.Qualification, error) {
+	var q types.Qualification
+	err := p.view(func(d *tenantData) error {
+		var err error
+		q, err = d.getQualification(id)
+		return err
+	})
+	return q, err
+}
+
+func (p Provider) GetAllQualifications(ctx context.Context) ([]types.Qualification, error) {
+	var quals []types.Qualification
+	err := p.view(func(d *tenantData) error {
+		for _, stored := range d.qualifications {
+			q, err := d.getQualification(stored.ID)
+			if err != nil {
+				return err
+			}
+			quals = append(quals, q)
+		}
+		return nil
+	})
+	sortQualifications(quals)
+	return quals, err
+}
+
+func (p Provider) ListQualifications(ctx context.Context, opts types.ListOptions) (types.Page[types.Qualification], error) {
+	quals, err := p.GetAllQualifications(ctx)
+	if err != nil {
+		return types.Page[types.Qualification]{}, err
+	}
+	return page(quals, opts)
+}
+
+// sortQualifications puts qualifications in name order, like the database providers list them.
+func sortQualifications(quals []types.Qualification) {
+	slices.SortStableFunc(quals, func(a, b types.Qualification) int { return strings.Compare(a.Name, b.Name) })
+}
+
+// GetPrerequisiteGraph returns the direct prerequisites of every qualification that has any, keyed by qualification ID.
+func (p Provider) GetPrerequisiteGraph(ctx context.Context) (map[string][]string, error) {
+	graph := map[string][]string{}
+	err := p.view(func(d *tenantData) error {
+		for _, row := range d.prerequisites {
+			graph[row.left] = append(graph[row.left], row.right)
+		}
+		return nil
+	})
+	return graph, err
+}
+
+func (p Provider) UpdateQualification(ctx context.Context, q types.Qualification) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Updating qualification", slog.Any("qualification", q))
+	return p.update(func(d *tenantData) error {
+		i := d.qualification(q.ID)
+		if i == -1 {
+			return backend.ErrQualificationNotFound
+		}
+		if slices.ContainsFunc(d.qualifications, func(existing types.Qualification) bool { return existing.Name == q.Name && existing.ID != q.ID }) {
+			return fmt.Errorf("qualification named %s already exists", q.Name)
+		}
+		version, err := nextVersion(d.qualifications[i].Version, q.Version)
+		if err != nil {
+			return err
+		}
+		d.qualifications[i].Version = version
+		d.qualifications[i].Name = q.Name
+		d.qualifications[i].Notes = q.Notes
+		d.qualifications[i].Expires = q.Expires
+		d.qualifications[i].ExpirationDays = q.ExpirationDays
+		return d.setQualificationLinks(q)
+	})
+}
+
+// DeleteQualification removes the qualification from everything it's part of: members, requirements, other
+// qualifications' prerequisites, duty positions, units and waivers.
+func (p Provider) DeleteQualification(ctx context.Context, id string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting qualification from memory", slog.String("id", id))
+	return p.update(func(d *tenantData) error {
+		if d.qualification(id) == -1 {
+			return backend.ErrQualificationNotFound
+		}
+		d.qualifications = slices.DeleteFunc(d.qualifications, func(q types.Qualification) bool { return q.ID == id })
+		d.memberQualifications.removeRight(id)
+		maps.DeleteFunc(d.qualificationSources, func(l link, _ string) bool { return l.right == id })
+		d.initialRequirements.removeLeft(id)
+		d.recurringRequirements.removeLeft(id)
+		d.prerequisites.removeLeft(id)
+		d.prerequisites.removeRight(id)
+		d.positionQualifications.removeRight(id)
+		d.unitQualifications.removeRight(id)
+		d.waivers = slices.DeleteFunc(d.waivers, func(w waiver) bool { return w.QualificationID == id })
+		return nil
+	})
+}
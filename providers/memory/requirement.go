@@ -0,0 +1,209 @@
+package memory
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+func (d *tenantData) requirement(id string) int {
+	return slices.IndexFunc(d.requirements, func(r types.Requirement) bool { return r.ID == id })
+}
+
+func (d *tenantData) reference(id string) int {
+	return slices.IndexFunc(d.references, func(r types.Reference) bool { return r.ID == id })
+}
+
+// getRequirement joins in the requirement's reference, which is empty if the reference has been deleted.
+func (d *tenantData) getRequirement(id string) (types.Requirement, error) {
+	i := d.requirement(id)
+	if i == -1 {
+		return types.Requirement{}, fmt.Errorf("%w: requirement_id=%s", backend.ErrRequirementNotFound, id)
+	}
+	return d.joinReference(d.requirements[i]), nil
+}
+
+func (d *tenantData) joinReference(r types.Requirement) types.Requirement {
+	if i := d.reference(r.Reference.ID); i != -1 {
+		r.Reference = d.references[i]
+	} else {
+		r.Reference = types.Reference{}
+	}
+	return r
+}
+
+func (p Provider) AddRequirement(ctx context.Context, r types.Requirement) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding requirement to memory", slog.Any("requirement", r))
+	return p.update(func(d *tenantData) error {
+		if d.requirement(r.ID) != -1 {
+			return fmt.Errorf("requirement %s already exists", r.ID)
+		}
+		if slices.ContainsFunc(d.requirements, func(existing types.Requirement) bool { return existing.Name == r.Name }) {
+			return backend.ErrDuplicateRequirement
+		}
+		if d.reference(r.Reference.ID) == -1 {
+			return fmt.Errorf("%w: %s", backend.ErrReferenceNotFound, r.Reference.ID)
+		}
+		r.Reference = types.Reference{ID: r.Reference.ID}
+		r.Version = 1
+		d.requirements = append(d.requirements, r)
+		return nil
+	})
+}
+
+func (p Provider) GetRequirement(ctx context.Context, id string) (types.Requirement, error) {
+	var r types.Requirement
+	err := p.view(func(d *tenantData) error {
+		var err error
+		r, err = d.getRequirement(id)
+		return err
+	})
+	return r, err
+}
+
+func (p Provider) GetAllRequirements(ctx context.Context) ([]types.Requirement, error) {
+	var reqs []types.Requirement
+	err := p.view(func(d *tenantData) error {
+		for _, r := range d.requirements {
+			reqs = append(reqs, d.joinReference(r))
+		}
+		return nil
+	})
+	return reqs, err
+}
+
+func (p Provider) ListRequirements(ctx context.Context, opts types.ListOptions) (types.Page[types.Requirement], error) {
+	reqs, err := p.GetAllRequirements(ctx)
+	if err != nil {
+		return types.Page[types.Requirement]{}, err
+	}
+	return page(reqs, opts)
+}
+
+func (p Provider) GetQualificationIDsForRequirement(ctx context.Context, requirementID string) ([]string, error) {
+	var ids []string
+	err := p.view(func(d *tenantData) error {
+		for _, id := range append(d.initialRequirements.lefts(requirementID), d.recurringRequirements.lefts(requirementID)...) {
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		return nil
+	})
+	slices.Sort(ids)
+	return ids, err
+}
+
+func (p Provider) UpdateRequirement(ctx context.Context, r types.Requirement) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Updating requirement", slog.Any("new_requirement", r))
+	return p.update(func(d *tenantData) error {
+		i := d.requirement(r.ID)
+		if i == -1 {
+			return fmt.Errorf("%w: requirement_id=%s", backend.ErrRequirementNotFound, r.ID)
+		}
+		if slices.ContainsFunc(d.requirements, func(existing types.Requirement) bool { return existing.Name == r.Name && existing.ID != r.ID }) {
+			return backend.ErrDuplicateRequirement
+		}
+		if d.reference(r.Reference.ID) == -1 {
+			return backend.ErrReferenceNotFound
+		}
+		var err error
+		if r.Version, err = nextVersion(d.requirements[i].Version, r.Version); err != nil {
+			return err
+		}
+		r.Reference = types.Reference{ID: r.Reference.ID}
+		d.requirements[i] = r
+		return nil
+	})
+}
+
+// DeleteRequirement removes the requirement along with its certifiers, completions and waivers, and takes it out of
+// every qualification.
+func (p Provider) DeleteRequirement(ctx context.Context, id string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting requirement from memory", slog.String("requirement_id", id))
+	return p.update(func(d *tenantData) error {
+		if d.requirement(id) == -1 {
+			return backend.ErrRequirementNotFound
+		}
+		d.requirements = slices.DeleteFunc(d.requirements, func(r types.Requirement) bool { return r.ID == id })
+		d.initialRequirements.removeRight(id)
+		d.recurringRequirements.removeRight(id)
+		d.certifiers.removeLeft(id)
+		d.completions = slices.DeleteFunc(d.completions, func(c types.Completion) bool { return c.RequirementID == id })
+		d.memberRequirements = slices.DeleteFunc(d.memberRequirements, func(r memberRequirement) bool { return r.requirementID == id })
+		d.waivers = slices.DeleteFunc(d.waivers, func(w waiver) bool { return w.RequirementID == id })
+		return nil
+	})
+}
+
+func (p Provider) AddReference(ctx context.Context, r types.Reference) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding reference to memory", slog.Any("reference", r))
+	return p.update(func(d *tenantData) error {
+		if d.reference(r.ID) != -1 {
+			return fmt.Errorf("reference %s already exists", r.ID)
+		}
+		if slices.ContainsFunc(d.references, func(existing types.Reference) bool { return existing.Name == r.Name }) {
+			return backend.ErrDuplicateReference
+		}
+		r.Version = 1
+		d.references = append(d.references, r)
+		return nil
+	})
+}
+
+func (p Provider) GetReference(ctx context.Context, id string) (types.Reference, error) {
+	var r types.Reference
+	err := p.view(func(d *tenantData) error {
+		i := d.reference(id)
+		if i == -1 {
+			return backend.ErrReferenceNotFound
+		}
+		r = d.references[i]
+		return nil
+	})
+	return r, err
+}
+
+func (p Provider) GetReferences(ctx context.Context) ([]types.Reference, error) {
+	var refs []types.Reference
+	err := p.view(func(d *tenantData) error {
+		refs = append(refs, d.references...)
+		return nil
+	})
+	return refs, err
+}
+
+func (p Provider) UpdateReference(ctx context.Context, r types.Reference) error {
+	return p.update(func(d *tenantData) error {
+		if slices.ContainsFunc(d.references, func(existing types.Reference) bool { return existing.Name == r.Name && existing.ID != r.ID }) {
+			return backend.ErrDuplicateReference
+		}
+		if i := d.reference(r.ID); i != -1 {
+			var err error
+			if r.Version, err = nextVersion(d.references[i].Version, r.Version); err != nil {
+				return err
+			}
+			d.references[i] = r
+		}
+		return nil
+	})
+}
+
+// DeleteReference removes the reference, leaving the requirements citing it without one.
+func (p Provider) DeleteReference(ctx context.Context, id string) error {
+	return p.update(func(d *tenantData) error {
+		if d.reference(id) == -1 {
+			return backend.ErrReferenceNotFound
+		}
+		d.references = slices.DeleteFunc(d.references, func(r types.Reference) bool { return r.ID == id })
+		for i := range d.requirements {
+			if d.requirements[i].Reference.ID == id {
+				d.requirements[i].Reference.ID = ""
+			}
+		}
+		return nil
+	})
+}
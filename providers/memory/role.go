@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"PORTal/types"
+	"context"
+	"log/slog"
+	"slices"
+)
+
+func (p Provider) GetRolePermissions(ctx context.Context, role types.Role) ([]types.Permission, error) {
+	permissions := []types.Permission{}
+	err := p.view(func(d *tenantData) error {
+		permissions = append(permissions, d.rolePermissions[role]...)
+		return nil
+	})
+	slices.Sort(permissions)
+	return permissions, err
+}
+
+func (p Provider) GetRoles(ctx context.Context) ([]types.RoleDefinition, error) {
+	roles := make([]types.RoleDefinition, 0, len(types.Roles))
+	for _, role := range types.Roles {
+		permissions, err := p.GetRolePermissions(ctx, role)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, types.RoleDefinition{Role: role, Permissions: permissions})
+	}
+	return roles, nil
+}
+
+func (p Provider) SetRolePermissions(ctx context.Context, role types.Role, permissions []types.Permission) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Setting permissions for role", slog.String("role", string(role)), slog.Any("permissions", permissions))
+	return p.update(func(d *tenantData) error {
+		d.rolePermissions[role] = slices.Clone(permissions)
+		return nil
+	})
+}
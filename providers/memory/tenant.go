@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// AddTenant registers the tenant with its roles seeded and its first admin inserted, so a tenant never exists without
+// someone who can manage it.
+func (p Provider) AddTenant(ctx context.Context, t types.Tenant, admin types.Member) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding tenant to memory", slog.String("tenant_id", t.ID), slog.String("subdomain", t.Subdomain))
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+	if slices.ContainsFunc(p.store.tenants, func(existing types.Tenant) bool {
+		return existing.ID == t.ID || (t.Subdomain != "" && existing.Subdomain == t.Subdomain)
+	}) {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Tenant subdomain is taken")
+		return fmt.Errorf("%w: %s", backend.ErrDuplicateTenant, t.Subdomain)
+	}
+	d := newTenantData()
+	admin.SupervisorID = ""
+	if err := d.insertMember(admin); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting tenant admin", slog.String("error", err.Error()))
+		return err
+	}
+	p.store.tenants = append(p.store.tenants, t)
+	p.store.data[t.ID] = d
+	return nil
+}
+
+func (p Provider) GetTenant(ctx context.Context, id string) (types.Tenant, error) {
+	return p.getTenant(ctx, func(t types.Tenant) bool { return t.ID == id }, id)
+}
+
+func (p Provider) GetTenantBySubdomain(ctx context.Context, subdomain string) (types.Tenant, error) {
+	return p.getTenant(ctx, func(t types.Tenant) bool { return t.Subdomain != "" && t.Subdomain == subdomain }, subdomain)
+}
+
+func (p Provider) getTenant(ctx context.Context, match func(types.Tenant) bool, identifier string) (types.Tenant, error) {
+	p.store.mu.Lock()
+	defer p.store.mu.Unlock()
+	i := slices.IndexFunc(p.store.tenants, match)
+	if i == -1 {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "No tenant found with given identifier", slog.String("identifier", identifier))
+		return types.Tenant{}, fmt.Errorf("%w: %s", backend.ErrTenantNotFound, identifier)
+	}
+	return p.store.tenants[i], nil
+}
+
+func (p Provider) GetTenants(ctx context.Context) ([]types.Tenant, error) {
+	p.store.mu.Lock()
+	tenants := slices.Clone(p.store.tenants)
+	p.store.mu.Unlock()
+	slices.SortStableFunc(tenants, func(a, b types.Tenant) int { return strings.Compare(a.Name, b.Name) })
+	return tenants, nil
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+func (p Provider) AddAPIToken(ctx context.Context, t types.APIToken) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding api token to memory", slog.Any("token", t))
+	return p.update(func(d *tenantData) error {
+		if slices.ContainsFunc(d.apiTokens, func(existing types.APIToken) bool { return existing.ID == t.ID }) {
+			return fmt.Errorf("api token %s already exists", t.ID)
+		}
+		if d.member(t.MemberID) == -1 {
+			p.logger.LogAttrs(ctx, slog.LevelWarn, "Member for api token doesn't exist", slog.String("member_id", t.MemberID))
+			return fmt.Errorf("%w: %s", backend.ErrMemberNotFound, t.MemberID)
+		}
+		t.LastUsed = nil
+		d.apiTokens = append(d.apiTokens, t)
+		return nil
+	})
+}
+
+func (p Provider) GetAPITokenByHash(ctx context.Context, hash string) (types.APIToken, error) {
+	var t types.APIToken
+	err := p.view(func(d *tenantData) error {
+		i := slices.IndexFunc(d.apiTokens, func(t types.APIToken) bool { return t.Hash == hash })
+		if i == -1 {
+			return backend.ErrAPITokenNotFound
+		}
+		t = d.apiTokens[i]
+		return nil
+	})
+	return t, err
+}
+
+func (p Provider) GetAPITokens(ctx context.Context, memberID string) ([]types.APIToken, error) {
+	var tokens []types.APIToken
+	err := p.view(func(d *tenantData) error {
+		for _, t := range d.apiTokens {
+			if t.MemberID == memberID {
+				tokens = append(tokens, t)
+			}
+		}
+		return nil
+	})
+	slices.SortStableFunc(tokens, func(a, b types.APIToken) int { return a.Created.Compare(b.Created) })
+	return tokens, err
+}
+
+func (p Provider) UpdateAPITokenLastUsed(ctx context.Context, id string, lastUsed time.Time) error {
+	return p.update(func(d *tenantData) error {
+		i := slices.IndexFunc(d.apiTokens, func(t types.APIToken) bool { return t.ID == id })
+		if i == -1 {
+			return backend.ErrAPITokenNotFound
+		}
+		d.apiTokens[i].LastUsed = &lastUsed
+		return nil
+	})
+}
+
+func (p Provider) DeleteAPIToken(ctx context.Context, memberID, id string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting api token", slog.String("member_id", memberID), slog.String("token_id", id))
+	return p.update(func(d *tenantData) error {
+		n := len(d.apiTokens)
+		d.apiTokens = slices.DeleteFunc(d.apiTokens, func(t types.APIToken) bool { return t.ID == id && t.MemberID == memberID })
+		if len(d.apiTokens) == n {
+			p.logger.LogAttrs(ctx, slog.LevelWarn, "No api token with that ID exists for member")
+			return backend.ErrAPITokenNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// ImportTransfer writes a transferred member and their records all at once. A returning member's archived record is
+// updated and restored instead of inserting a new one. Records the member already has are skipped.
+func (p Provider) ImportTransfer(ctx context.Context, t types.TransferImport) error {
+	l := p.logger.With(slog.String("member_id", t.Member.ID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Importing transferred member", slog.Bool("returning", t.Returning))
+	return p.update(func(d *tenantData) error {
+		if slices.ContainsFunc(d.transfers, func(mt memberTransfer) bool { return mt.packageID == t.PackageID }) {
+			return fmt.Errorf("%w: package_id=%s", backend.ErrTransferAlreadyImported, t.PackageID)
+		}
+		if err := d.transferMember(t); err != nil {
+			return err
+		}
+		d.transfers = append(d.transfers, memberTransfer{packageID: t.PackageID, issuer: t.Issuer, issuerMemberID: t.IssuerMemberID, memberID: t.Member.ID})
+		for _, id := range t.QualificationIDs {
+			if d.qualification(id) == -1 {
+				return fmt.Errorf("%w: %s", backend.ErrQualificationNotFound, id)
+			}
+			if !d.memberQualifications.has(t.Member.ID, id) {
+				d.memberQualifications.add(t.Member.ID, id)
+			}
+		}
+		for _, c := range t.Completions {
+			if d.requirement(c.RequirementID) == -1 {
+				return fmt.Errorf("%w: requirement_id=%s", backend.ErrRequirementNotFound, c.RequirementID)
+			}
+			if d.completion(c.ID) == -1 {
+				c.MemberID = t.Member.ID
+				d.completions = append(d.completions, c)
+			}
+			d.upsertMemberRequirement(t.Member.ID, c.RequirementID, c.CompletedDate)
+		}
+		for _, e := range t.History {
+			if slices.ContainsFunc(d.history, func(existing types.QualificationEvent) bool { return existing.ID == e.ID }) {
+				continue
+			}
+			e.MemberID = t.Member.ID
+			d.history = append(d.history, e)
+		}
+		return nil
+	})
+}
+
+func (d *tenantData) transferMember(t types.TransferImport) error {
+	if !t.Returning {
+		m := t.Member
+		m.SupervisorID = ""
+		return d.insertMember(m)
+	}
+	// The member's version in the other tenant means nothing here
+	m := t.Member
+	m.Version = 0
+	if err := d.updateMember(m); err != nil {
+		return err
+	}
+	i := d.member(m.ID)
+	d.members[i].Archive = nil
+	d.members[i].Version++
+	return nil
+}
+
+func (p Provider) IsTransferImported(ctx context.Context, packageID string) (bool, error) {
+	var imported bool
+	err := p.view(func(d *tenantData) error {
+		imported = slices.ContainsFunc(d.transfers, func(mt memberTransfer) bool { return mt.packageID == packageID })
+		return nil
+	})
+	return imported, err
+}
+
+// GetTransferredMemberID returns the ID here of the member a package from issuer brought in as issuerMemberID.
+func (p Provider) GetTransferredMemberID(ctx context.Context, issuer, issuerMemberID string) (string, error) {
+	var id string
+	err := p.view(func(d *tenantData) error {
+		for _, mt := range d.transfers {
+			if mt.issuer == issuer && mt.issuerMemberID == issuerMemberID {
+				id = mt.memberID
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: issuer=%s issuer_member_id=%s", backend.ErrMemberNotFound, issuer, issuerMemberID)
+	})
+	return id, err
+}
+
+// GetMemberTransferOrigins returns the member's IDs at the instances they were transferred here from, by instance name.
+func (p Provider) GetMemberTransferOrigins(ctx context.Context, memberID string) (map[string]string, error) {
+	origins := map[string]string{}
+	err := p.view(func(d *tenantData) error {
+		for _, mt := range d.transfers {
+			if mt.memberID == memberID {
+				origins[mt.issuer] = mt.issuerMemberID
+			}
+		}
+		return nil
+	})
+	return origins, err
+}
@@ -0,0 +1,181 @@
+package memory
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+func (d *tenantData) unit(id string) int {
+	return slices.IndexFunc(d.units, func(u types.Unit) bool { return u.ID == id })
+}
+
+func (d *tenantData) getUnit(i int) types.Unit {
+	u := d.units[i]
+	u.MandatoryQualifications = d.unitQualifications.rights(u.ID)
+	slices.Sort(u.MandatoryQualifications)
+	return u
+}
+
+// setUnitMandatoryQualifications replaces the qualifications mandatory for the unit's members.
+func (d *tenantData) setUnitMandatoryQualifications(u types.Unit) error {
+	d.unitQualifications.removeLeft(u.ID)
+	for _, qualificationID := range u.MandatoryQualifications {
+		if d.qualification(qualificationID) == -1 {
+			return fmt.Errorf("%w: %s", backend.ErrQualificationNotFound, qualificationID)
+		}
+		d.unitQualifications.add(u.ID, qualificationID)
+	}
+	return nil
+}
+
+func (p Provider) AddUnit(ctx context.Context, u types.Unit) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding unit to memory", slog.String("unit_id", u.ID), slog.String("name", u.Name))
+	return p.update(func(d *tenantData) error {
+		if d.unit(u.ID) != -1 {
+			return fmt.Errorf("unit %s already exists", u.ID)
+		}
+		if u.ParentID != "" && d.unit(u.ParentID) == -1 {
+			return fmt.Errorf("%w: parent unit %s not found", backend.ErrInvalidUnit, u.ParentID)
+		}
+		d.units = append(d.units, types.Unit{ID: u.ID, Name: u.Name, Kind: u.Kind, ParentID: u.ParentID, Version: 1})
+		return d.setUnitMandatoryQualifications(u)
+	})
+}
+
+func (p Provider) GetUnit(ctx context.Context, id string) (types.Unit, error) {
+	var u types.Unit
+	err := p.view(func(d *tenantData) error {
+		i := d.unit(id)
+		if i == -1 {
+			return fmt.Errorf("%w: unit_id=%s", backend.ErrUnitNotFound, id)
+		}
+		u = d.getUnit(i)
+		return nil
+	})
+	return u, err
+}
+
+func (p Provider) GetUnits(ctx context.Context) ([]types.Unit, error) {
+	units := []types.Unit{}
+	err := p.view(func(d *tenantData) error {
+		for i := range d.units {
+			units = append(units, d.getUnit(i))
+		}
+		return nil
+	})
+	slices.SortStableFunc(units, func(a, b types.Unit) int { return strings.Compare(a.Name, b.Name) })
+	return units, err
+}
+
+// UpdateUnit replaces the unit's name, parent and mandatory qualifications. A unit's kind never changes.
+func (p Provider) UpdateUnit(ctx context.Context, u types.Unit) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Updating unit", slog.String("unit_id", u.ID))
+	return p.update(func(d *tenantData) error {
+		i := d.unit(u.ID)
+		if i == -1 {
+			return fmt.Errorf("%w: unit_id=%s", backend.ErrUnitNotFound, u.ID)
+		}
+		if u.ParentID != "" && d.unit(u.ParentID) == -1 {
+			return fmt.Errorf("%w: parent unit %s not found", backend.ErrInvalidUnit, u.ParentID)
+		}
+		version, err := nextVersion(d.units[i].Version, u.Version)
+		if err != nil {
+			return err
+		}
+		d.units[i].Version = version
+		d.units[i].Name = u.Name
+		d.units[i].ParentID = u.ParentID
+		return d.setUnitMandatoryQualifications(u)
+	})
+}
+
+// DeleteUnit removes a unit without subunits. Its members are left without a unit.
+func (p Provider) DeleteUnit(ctx context.Context, id string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting unit", slog.String("unit_id", id))
+	return p.update(func(d *tenantData) error {
+		if slices.ContainsFunc(d.units, func(u types.Unit) bool { return u.ParentID == id }) {
+			return fmt.Errorf("%w: unit_id=%s", backend.ErrUnitHasSubunits, id)
+		}
+		if d.unit(id) == -1 {
+			return fmt.Errorf("%w: unit_id=%s", backend.ErrUnitNotFound, id)
+		}
+		d.units = slices.DeleteFunc(d.units, func(u types.Unit) bool { return u.ID == id })
+		for i := range d.members {
+			if d.members[i].UnitID == id {
+				d.members[i].UnitID = ""
+			}
+		}
+		d.unitQualifications.removeLeft(id)
+		d.unitAdmins.removeLeft(id)
+		return nil
+	})
+}
+
+// SetMemberUnit moves a member into a unit, or out of every unit when unitID is empty.
+func (p Provider) SetMemberUnit(ctx context.Context, memberID, unitID string) error {
+	return p.update(func(d *tenantData) error {
+		i := d.member(memberID)
+		if i == -1 {
+			return fmt.Errorf("%w: member_id=%s", backend.ErrMemberNotFound, memberID)
+		}
+		if unitID != "" && d.unit(unitID) == -1 {
+			return fmt.Errorf("%w: unit_id=%s", backend.ErrUnitNotFound, unitID)
+		}
+		d.members[i].UnitID = unitID
+		d.members[i].Version++
+		return nil
+	})
+}
+
+// GetUnitMembers returns the active members directly in the unit, not those in its subunits.
+func (p Provider) GetUnitMembers(ctx context.Context, unitID string) ([]types.Member, error) {
+	return p.members(func(m types.Member) bool { return m.UnitID == unitID && m.Archive == nil }, true)
+}
+
+func (p Provider) AddUnitAdmin(ctx context.Context, unitID, memberID string) error {
+	return p.update(func(d *tenantData) error {
+		if d.unitAdmins.has(unitID, memberID) {
+			return fmt.Errorf("%w: unit_id=%s member_id=%s", backend.ErrUnitAdminAlreadyDesignated, unitID, memberID)
+		}
+		if d.member(memberID) == -1 {
+			return backend.ErrMemberNotFound
+		}
+		if d.unit(unitID) == -1 {
+			return fmt.Errorf("%w: unit_id=%s", backend.ErrUnitNotFound, unitID)
+		}
+		d.unitAdmins.add(unitID, memberID)
+		return nil
+	})
+}
+
+func (p Provider) GetUnitAdminIDs(ctx context.Context, unitID string) ([]string, error) {
+	var ids []string
+	err := p.view(func(d *tenantData) error {
+		ids = d.unitAdmins.rights(unitID)
+		return nil
+	})
+	return ids, err
+}
+
+func (p Provider) GetAdministeredUnitIDs(ctx context.Context, memberID string) ([]string, error) {
+	var ids []string
+	err := p.view(func(d *tenantData) error {
+		ids = d.unitAdmins.lefts(memberID)
+		return nil
+	})
+	return ids, err
+}
+
+func (p Provider) RemoveUnitAdmin(ctx context.Context, unitID, memberID string) error {
+	return p.update(func(d *tenantData) error {
+		if !d.unitAdmins.remove(unitID, memberID) {
+			return fmt.Errorf("%w: unit_id=%s member_id=%s", backend.ErrUnitAdminNotFound, unitID, memberID)
+		}
+		return nil
+	})
+}
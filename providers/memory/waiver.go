@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+func (d *tenantData) waiver(id string) int {
+	return slices.IndexFunc(d.waivers, func(w waiver) bool { return w.ID == id })
+}
+
+// getWaiver returns the waiver with just the name of its memo, which is fetched separately with GetWaiverMemo.
+func (w waiver) getWaiver() types.Waiver {
+	result := w.Waiver
+	result.Memo = nil
+	result.MemoName = ""
+	if w.memo != nil {
+		result.MemoName = w.memo.Name
+	}
+	return result
+}
+
+func (p Provider) AddWaiver(ctx context.Context, w types.Waiver) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding waiver to memory", slog.Any("waiver", w))
+	return p.update(func(d *tenantData) error {
+		if d.waiver(w.ID) != -1 {
+			return fmt.Errorf("waiver %s already exists", w.ID)
+		}
+		if d.member(w.MemberID) == -1 {
+			return backend.ErrMemberNotFound
+		}
+		if w.RequirementID != "" && d.requirement(w.RequirementID) == -1 {
+			return fmt.Errorf("%w: requirement_id=%s", backend.ErrRequirementNotFound, w.RequirementID)
+		}
+		if w.QualificationID != "" && d.qualification(w.QualificationID) == -1 {
+			return backend.ErrQualificationNotFound
+		}
+		if d.member(w.ApproverID) == -1 {
+			return fmt.Errorf("%w: approver_id=%s", backend.ErrMemberNotFound, w.ApproverID)
+		}
+		stored := waiver{Waiver: w}
+		if w.Memo != nil {
+			memo := *w.Memo
+			stored.memo = &memo
+		}
+		d.waivers = append(d.waivers, stored)
+		return nil
+	})
+}
+
+func (p Provider) GetWaiver(ctx context.Context, id string) (types.Waiver, error) {
+	var w types.Waiver
+	err := p.view(func(d *tenantData) error {
+		i := d.waiver(id)
+		if i == -1 {
+			p.logger.LogAttrs(ctx, slog.LevelWarn, "No waiver found with given id", slog.String("waiver_id", id))
+			return fmt.Errorf("%w: waiver_id=%s", backend.ErrWaiverNotFound, id)
+		}
+		w = d.waivers[i].getWaiver()
+		return nil
+	})
+	return w, err
+}
+
+func (p Provider) GetMemberWaivers(ctx context.Context, memberID string) ([]types.Waiver, error) {
+	waivers := []types.Waiver{}
+	err := p.view(func(d *tenantData) error {
+		for _, w := range d.waivers {
+			if w.MemberID == memberID {
+				waivers = append(waivers, w.getWaiver())
+			}
+		}
+		return nil
+	})
+	slices.SortStableFunc(waivers, func(a, b types.Waiver) int { return a.Start.Compare(b.Start) })
+	return waivers, err
+}
+
+func (p Provider) GetWaiverMemo(ctx context.Context, id string) (types.WaiverMemo, error) {
+	var memo types.WaiverMemo
+	err := p.view(func(d *tenantData) error {
+		i := d.waiver(id)
+		if i == -1 {
+			return fmt.Errorf("%w: waiver_id=%s", backend.ErrWaiverNotFound, id)
+		}
+		if d.waivers[i].memo == nil {
+			return fmt.Errorf("%w: waiver_id=%s", backend.ErrWaiverMemoNotFound, id)
+		}
+		memo = *d.waivers[i].memo
+		return nil
+	})
+	return memo, err
+}
+
+func (p Provider) UpdateWaiverEnd(ctx context.Context, id string, end time.Time) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Updating waiver end date", slog.String("waiver_id", id), slog.Time("end", end))
+	return p.update(func(d *tenantData) error {
+		i := d.waiver(id)
+		if i == -1 {
+			return fmt.Errorf("%w: waiver_id=%s", backend.ErrWaiverNotFound, id)
+		}
+		d.waivers[i].End = end
+		return nil
+	})
+}
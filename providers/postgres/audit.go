@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"PORTal/types"
+	"context"
+	"log/slog"
+)
+
+func (p Provider) AddAuditEntry(ctx context.Context, e types.AuditEntry) error {
+	_, err := p.Db.ExecContext(ctx, insertAuditEntryQuery, e.ID, e.ActorID, e.Action, e.EntityType, e.EntityID, e.Time, e.Details)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting audit entry", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) GetAuditEntries(ctx context.Context, entityType, entityID string) ([]types.AuditEntry, error) {
+	rows, err := p.Db.QueryContext(ctx, getAuditEntriesQuery, entityType, entityID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting audit entries", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	entries := []types.AuditEntry{}
+	for rows.Next() {
+		var e types.AuditEntry
+		if err = rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.EntityType, &e.EntityID, &e.Time, &e.Details); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning audit entry", slog.String("error", err.Error()))
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,222 @@
+package postgres
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+func (p Provider) AddImportProfile(ctx context.Context, profile types.ImportProfile) error {
+	_, err := p.Db.ExecContext(ctx, insertImportProfileQuery, profile.ID, profile.Name, profile.MemberColumn, profile.RequirementColumn,
+		profile.DateColumn, profile.DateFormat)
+	if uniqueViolation(err, "import_profile_name_key") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Import profile name already taken", slog.String("name", profile.Name))
+		return fmt.Errorf("%w: %s", backend.ErrDuplicateImportProfile, profile.Name)
+	} else if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting import profile", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) GetImportProfile(ctx context.Context, id string) (types.ImportProfile, error) {
+	profile, err := scanImportProfile(p.Db.QueryRowContext(ctx, getImportProfileQuery, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "No import profile found with given id", slog.String("profile_id", id))
+		return types.ImportProfile{}, fmt.Errorf("%w: profile_id=%s", backend.ErrImportProfileNotFound, id)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning import profile into struct", slog.String("error", err.Error()))
+		return types.ImportProfile{}, err
+	}
+	return profile, nil
+}
+
+func (p Provider) GetImportProfiles(ctx context.Context) ([]types.ImportProfile, error) {
+	rows, err := p.Db.QueryContext(ctx, getImportProfilesQuery)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting import profiles", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	profiles := []types.ImportProfile{}
+	for rows.Next() {
+		profile, err := scanImportProfile(rows)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning import profile into struct", slog.String("error", err.Error()))
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+func (p Provider) DeleteImportProfile(ctx context.Context, id string) error {
+	res, err := p.Db.ExecContext(ctx, deleteImportProfileQuery, id)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error deleting import profile", slog.String("error", err.Error()))
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		return fmt.Errorf("%w: profile_id=%s", backend.ErrImportProfileNotFound, id)
+	}
+	return nil
+}
+
+// AddImportBatch stores a batch and all of its staged rows in one transaction.
+func (p Provider) AddImportBatch(ctx context.Context, batch types.ImportBatch) error {
+	l := p.logger.With(slog.String("batch_id", batch.ID))
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Error creating transaction for AddImportBatch", slog.String("error", err.Error()))
+		return err
+	}
+	_, err = tx.ExecContext(ctx, insertImportBatchQuery, batch.ID, nullString(batch.ProfileID), batch.FileName, nullString(batch.UploadedBy), batch.Uploaded, batch.Status)
+	if err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Error inserting import batch", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	for _, row := range batch.Rows {
+		_, err = tx.ExecContext(ctx, insertImportRowQuery, row.ID, batch.ID, row.Line, row.MemberIdentifier, row.MemberID, row.RequirementName,
+			row.RequirementID, row.MatchScore, nullTime(row.CompletedDate), row.Excluded, strings.Join(row.Errors, "\n"))
+		if err != nil {
+			l.LogAttrs(ctx, slog.LevelError, "Error inserting staged row", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) GetImportBatch(ctx context.Context, id string) (types.ImportBatch, error) {
+	var batch types.ImportBatch
+	var profileID, uploadedBy sql.NullString
+	err := p.Db.QueryRowContext(ctx, getImportBatchQuery, id).Scan(&batch.ID, &profileID, &batch.FileName, &uploadedBy, &batch.Uploaded, &batch.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "No import batch found with given id", slog.String("batch_id", id))
+		return types.ImportBatch{}, fmt.Errorf("%w: batch_id=%s", backend.ErrImportBatchNotFound, id)
+	} else if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning import batch into struct", slog.String("error", err.Error()))
+		return types.ImportBatch{}, err
+	}
+	batch.ProfileID = profileID.String
+	batch.UploadedBy = uploadedBy.String
+
+	rows, err := p.Db.QueryContext(ctx, getImportRowsQuery, id)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting staged rows", slog.String("error", err.Error()))
+		return types.ImportBatch{}, err
+	}
+	defer rows.Close()
+	batch.Rows = []types.StagedRow{}
+	for rows.Next() {
+		var row types.StagedRow
+		var completed sql.NullTime
+		var errs string
+		err = rows.Scan(&row.ID, &row.Line, &row.MemberIdentifier, &row.MemberID, &row.RequirementName, &row.RequirementID,
+			&row.MatchScore, &completed, &row.Excluded, &errs)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning staged row into struct", slog.String("error", err.Error()))
+			return types.ImportBatch{}, err
+		}
+		row.CompletedDate = completed.Time
+		if errs != "" {
+			row.Errors = strings.Split(errs, "\n")
+		}
+		batch.Rows = append(batch.Rows, row)
+	}
+	return batch, nil
+}
+
+func (p Provider) UpdateImportRow(ctx context.Context, batchID string, row types.StagedRow) error {
+	res, err := p.Db.ExecContext(ctx, updateImportRowQuery, row.MemberID, row.RequirementID, row.MatchScore, nullTime(row.CompletedDate),
+		row.Excluded, strings.Join(row.Errors, "\n"), row.ID, batchID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error updating staged row", slog.String("error", err.Error()))
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		return fmt.Errorf("%w: batch_id=%s row_id=%s", backend.ErrImportRowNotFound, batchID, row.ID)
+	}
+	return nil
+}
+
+// CommitImportBatch writes the batch's completions as already approved and records them against each member's
+// requirements, marking the batch committed in the same transaction.
+func (p Provider) CommitImportBatch(ctx context.Context, batchID string, completions []types.Completion) error {
+	l := p.logger.With(slog.String("batch_id", batchID))
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Error creating transaction for CommitImportBatch", slog.String("error", err.Error()))
+		return err
+	}
+	res, err := tx.ExecContext(ctx, updateImportBatchStatusQuery, types.ImportBatchCommitted, batchID, types.ImportBatchStaged)
+	if err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Error updating import batch status", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		tx.Rollback()
+		return fmt.Errorf("%w: batch_id=%s", backend.ErrImportBatchCommitted, batchID)
+	}
+	for _, c := range completions {
+		_, err = tx.ExecContext(ctx, insertReviewedCompletionQuery, c.ID, c.MemberID, c.RequirementID, nullString(c.CertifierID), c.SubmittedBy,
+			c.Status, c.CompletedDate, c.Submitted, c.Reviewed, c.Comments)
+		if foreignKeyViolation(err) {
+			tx.Rollback()
+			return fmt.Errorf("%w: member_id=%s requirement_id=%s", backend.ErrInvalidImport, c.MemberID, c.RequirementID)
+		} else if err != nil {
+			l.LogAttrs(ctx, slog.LevelError, "Error inserting imported completion", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, upsertMemberRequirementQuery, c.MemberID, c.RequirementID, c.CompletedDate); err != nil {
+			l.LogAttrs(ctx, slog.LevelError, "Error recording member requirement completion", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) DeleteImportBatch(ctx context.Context, id string) error {
+	res, err := p.Db.ExecContext(ctx, deleteImportBatchQuery, id)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error deleting import batch", slog.String("error", err.Error()))
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		return fmt.Errorf("%w: batch_id=%s", backend.ErrImportBatchNotFound, id)
+	}
+	return nil
+}
+
+func scanImportProfile(s scanner) (types.ImportProfile, error) {
+	var profile types.ImportProfile
+	err := s.Scan(&profile.ID, &profile.Name, &profile.MemberColumn, &profile.RequirementColumn, &profile.DateColumn, &profile.DateFormat)
+	return profile, err
+}
+
+// nullTime stores zero times as NULL.
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// rankOrder sorts members by the seniority of their rank rather than alphabetically, see types.Rank.Grade.
+var rankOrder = func() string {
+	var b strings.Builder
+	b.WriteString("CASE rank")
+	for _, r := range types.Ranks {
+		fmt.Fprintf(&b, " WHEN '%s' THEN %d", r, r.Grade())
+	}
+	b.WriteString(" ELSE 0 END")
+	return b.String()
+}()
+
+// listQuery builds the queries for a page of a list. Conditions and the keyset the page starts after are numbered in
+// the order they're added, the tenant is bound after all of them, see withTenant.
+type listQuery struct {
+	// from is the table listed along with anything joined to it, tenant is the table's tenant_id column
+	from, tenant string
+	conditions   []string
+	args         []any
+}
+
+// where adds a condition, each ? in it standing for the next of args.
+func (q *listQuery) where(condition string, args ...any) {
+	for _, arg := range args {
+		q.args = append(q.args, arg)
+		condition = strings.Replace(condition, "?", "$"+strconv.Itoa(len(q.args)), 1)
+	}
+	q.conditions = append(q.conditions, condition)
+}
+
+func (q listQuery) whereClause() string {
+	return " WHERE " + strings.Join(append(slices.Clone(q.conditions), q.tenant+"=$tenant"), " AND ")
+}
+
+// count returns how many rows match across every page.
+func (q listQuery) count() (string, []any) {
+	return "SELECT COUNT(*) FROM " + q.from + q.whereClause(), q.args
+}
+
+// page selects the columns of the rows after the keyset, or the first rows when after is nil, sorted by order. It asks
+// for one more row than the limit, see types.NewPage.
+func (q listQuery) page(columns string, order []string, opts types.ListOptions, after []any) (string, []any) {
+	q.conditions, q.args = slices.Clone(q.conditions), slices.Clone(q.args)
+	direction, comparison := " ASC", " > "
+	if opts.Descending {
+		direction, comparison = " DESC", " < "
+	}
+	if after != nil {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(after)), ", ")
+		q.where("("+strings.Join(order, ", ")+")"+comparison+"("+placeholders+")", after...)
+	}
+	orderBy := make([]string, len(order))
+	for i, column := range order {
+		orderBy[i] = column + direction
+	}
+	return "SELECT " + columns + " FROM " + q.from + q.whereClause() + " ORDER BY " + strings.Join(orderBy, ", ") + " LIMIT " + strconv.Itoa(opts.Limit+1), q.args
+}
+
+// countRows runs the query's count.
+func (p Provider) countRows(ctx context.Context, q listQuery) (int, error) {
+	query, args := q.count()
+	var total int
+	if err := p.Db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error counting rows for list", slog.String("error", err.Error()))
+		return 0, err
+	}
+	return total, nil
+}
+
+// decodeCursor returns the sort values the page starts after, nil for the first page.
+func decodeCursor(opts types.ListOptions, like types.Sortable) ([]any, error) {
+	zero := like.SortValues(opts.Sort)
+	if zero == nil {
+		return nil, fmt.Errorf("%w: can't sort by %q", backend.ErrInvalidListOptions, opts.Sort)
+	}
+	after, err := types.DecodeCursor(opts.Cursor, zero)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", backend.ErrInvalidListOptions, err.Error())
+	}
+	return after, nil
+}
@@ -0,0 +1,233 @@
+package postgres
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+func (p Provider) AssignMemberQualification(ctx context.Context, memberID, qualificationID string) error {
+	return p.assignMemberQualification(ctx, memberID, qualificationID, nil)
+}
+
+// AssignMemberPositionQualification assigns the qualification on behalf of the duty position.
+func (p Provider) AssignMemberPositionQualification(ctx context.Context, memberID, qualificationID, positionID string) error {
+	return p.assignMemberQualification(ctx, memberID, qualificationID, positionID)
+}
+
+// GetMemberPositionQualificationIDs returns the member's qualifications that were assigned by a duty position.
+func (p Provider) GetMemberPositionQualificationIDs(ctx context.Context, memberID string) ([]string, error) {
+	return p.queryIDs(ctx, getMemberPositionQualificationIDsQuery, memberID)
+}
+
+func (p Provider) assignMemberQualification(ctx context.Context, memberID, qualificationID string, positionID any) error {
+	_, err := p.Db.ExecContext(ctx, addMemberQualificationQuery, memberID, qualificationID, positionID)
+	if uniqueViolation(err, "member_qualification_pkey") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Member already assigned qualification")
+		return fmt.Errorf("%w: member_id=%s qualification_id=%s", backend.ErrQualificationAlreadyAssigned, memberID, qualificationID)
+	} else if foreignKeyViolation(err) {
+		if _, err = p.GetMember(ctx, memberID, backend.ById); err != nil {
+			return err
+		}
+		if _, err = p.GetQualification(ctx, qualificationID); err != nil {
+			return err
+		}
+	} else if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error adding qualification to member", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) GetMemberQualification(ctx context.Context, memberID, qualificationID string) (types.Qualification, error) {
+	// Verify that member has qualification assigned
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Checking to see if member has qualification assigned")
+	row := p.Db.QueryRowContext(ctx, checkMemberQualificationQuery, memberID, qualificationID)
+	var count int
+	err := row.Scan(&count)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Error checking if member has qualification assigned", slog.String("error", err.Error()))
+		return types.Qualification{}, err
+	}
+	if count == 0 {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Member with given qualification not found")
+		return types.Qualification{}, backend.ErrMemberQualificationNotFound
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "User has qualification assigned")
+	return p.GetQualification(ctx, qualificationID)
+}
+
+func (p Provider) GetMemberQualifications(ctx context.Context, memberID string) ([]types.Qualification, error) {
+	quals, err := p.loadQualifications(ctx, memberQualifications, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if quals == nil {
+		quals = []types.Qualification{}
+	}
+	return quals, nil
+}
+
+func (p Provider) RemoveMemberQualification(ctx context.Context, memberId, qualificationId string) error {
+	res, err := p.Db.ExecContext(ctx, removeMemberQualificationQuery, memberId, qualificationId)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error removing qualification from member", slog.String("error", err.Error()))
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected != 1 {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Could not find member qualification to remove")
+		return fmt.Errorf("%w: member_id: %s, qualification_id: %s", backend.ErrMemberQualificationNotFound, memberId, qualificationId)
+	}
+	return nil
+}
+
+// GetMemberRequirements returns the requirements the member has an approved completion for, dated by the most recent
+// completion.
+func (p Provider) GetMemberRequirements(ctx context.Context, memberID string) ([]types.MemberRequirement, error) {
+	rows, err := p.Db.QueryContext(ctx, getMemberRequirementsQuery, memberID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting requirements for member", slog.String("error", err.Error()))
+		return nil, err
+	}
+	type completion struct {
+		requirementID string
+		date          time.Time
+	}
+	var completions []completion
+	for rows.Next() {
+		var c completion
+		if err = rows.Scan(&c.requirementID, &c.date); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning member requirement", slog.String("error", err.Error()))
+			rows.Close()
+			return nil, err
+		}
+		completions = append(completions, c)
+	}
+	rows.Close()
+	reqs := make([]types.MemberRequirement, 0, len(completions))
+	for _, c := range completions {
+		req, err := p.GetRequirement(ctx, c.requirementID)
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, types.MemberRequirement{MemberID: memberID, Requirement: req, Completed: true, CompletedDate: c.date})
+	}
+	return reqs, nil
+}
+
+// AssignMemberQualifications assigns every pair in a single transaction. A pair that can't be assigned doesn't fail
+// the batch, its error is returned at the same index instead. The returned error is only set if the transaction
+// itself fails.
+func (p Provider) AssignMemberQualifications(ctx context.Context, pairs []types.MemberQualificationPair) ([]error, error) {
+	return p.bulkMemberQualifications(ctx, pairs, func(tx tenantTx, pair types.MemberQualificationPair) error {
+		// A failed statement aborts the rest of the transaction, so missing members and qualifications are found first
+		if err := missingMemberOrQualification(ctx, tx, pair); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, addMemberQualificationQuery, pair.MemberID, pair.QualificationID, nil)
+		if uniqueViolation(err, "member_qualification_pkey") {
+			return fmt.Errorf("%w: member_id=%s qualification_id=%s", backend.ErrQualificationAlreadyAssigned, pair.MemberID, pair.QualificationID)
+		}
+		return err
+	})
+}
+
+// RemoveMemberQualifications is the bulk counterpart to RemoveMemberQualification, see AssignMemberQualifications.
+func (p Provider) RemoveMemberQualifications(ctx context.Context, pairs []types.MemberQualificationPair) ([]error, error) {
+	return p.bulkMemberQualifications(ctx, pairs, func(tx tenantTx, pair types.MemberQualificationPair) error {
+		res, err := tx.ExecContext(ctx, removeMemberQualificationQuery, pair.MemberID, pair.QualificationID)
+		if err != nil {
+			return err
+		}
+		if affected, _ := res.RowsAffected(); affected != 1 {
+			return fmt.Errorf("%w: member_id: %s, qualification_id: %s", backend.ErrMemberQualificationNotFound, pair.MemberID, pair.QualificationID)
+		}
+		return nil
+	})
+}
+
+// bulkMemberQualifications applies each pair under its own savepoint so a pair that fails is rolled back on its own
+// rather than aborting the whole transaction.
+func (p Provider) bulkMemberQualifications(ctx context.Context, pairs []types.MemberQualificationPair, apply func(tenantTx, types.MemberQualificationPair) error) ([]error, error) {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error starting transaction", slog.String("error", err.Error()))
+		return nil, err
+	}
+	errs := make([]error, len(pairs))
+	for i, pair := range pairs {
+		if _, err = tx.ExecContext(ctx, savepointQuery); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error creating savepoint", slog.String("error", err.Error()))
+			tx.Rollback()
+			return nil, err
+		}
+		if errs[i] = apply(tx, pair); errs[i] != nil {
+			_, err = tx.ExecContext(ctx, rollbackToSavepointQuery)
+		} else {
+			_, err = tx.ExecContext(ctx, releaseSavepointQuery)
+		}
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error ending savepoint", slog.String("error", err.Error()))
+			tx.Rollback()
+			return nil, err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing bulk member qualification changes", slog.String("error", err.Error()))
+		return nil, err
+	}
+	return errs, nil
+}
+
+// missingMemberOrQualification returns the not found error for whichever side of a pair doesn't exist, if either.
+func missingMemberOrQualification(ctx context.Context, tx tenantTx, pair types.MemberQualificationPair) error {
+	var count int
+	if err := tx.QueryRowContext(ctx, countMemberQuery, pair.MemberID).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: %s", backend.ErrMemberNotFound, pair.MemberID)
+	}
+	if err := tx.QueryRowContext(ctx, countQualificationQuery, pair.QualificationID).Scan(&count); err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: %s", backend.ErrQualificationNotFound, pair.QualificationID)
+	}
+	return nil
+}
+
+func (p Provider) AddQualificationEvent(ctx context.Context, e types.QualificationEvent) error {
+	_, err := p.Db.ExecContext(ctx, insertQualificationEventQuery, e.ID, e.MemberID, e.QualificationID, e.Kind, nullString(e.ActorID), e.Time)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting qualification event", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// GetQualificationHistory returns the member's events for the qualification, oldest first.
+func (p Provider) GetQualificationHistory(ctx context.Context, memberID, qualificationID string) ([]types.QualificationEvent, error) {
+	rows, err := p.Db.QueryContext(ctx, getQualificationHistoryQuery, memberID, qualificationID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting qualification history", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	events := []types.QualificationEvent{}
+	for rows.Next() {
+		var e types.QualificationEvent
+		var actorID sql.NullString
+		if err = rows.Scan(&e.ID, &e.MemberID, &e.QualificationID, &e.Kind, &actorID, &e.Time); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning qualification event", slog.String("error", err.Error()))
+			return nil, err
+		}
+		e.ActorID = actorID.String
+		events = append(events, e)
+	}
+	return events, nil
+}
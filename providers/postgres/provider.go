@@ -0,0 +1,269 @@
+package postgres
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Provider stores every tenant's data in the same Postgres database, for larger deployments that want a managed
+// database with concurrent writers. Each Provider only sees the rows of the tenant it's scoped to, see ForTenant.
+type Provider struct {
+	logger *slog.Logger
+	Db     tenantDB
+}
+
+// tenantDB binds the tenant every query in queries.go filters on. Postgres only numbers its parameters, so $tenant is
+// rewritten to the parameter following the positional ones. Queries that don't use it are left alone. A tenantDB bound
+// to a transaction runs every query in it, see WithTx.
+type tenantDB struct {
+	*sql.DB
+	tenant string
+	tx     *sql.Tx
+}
+
+// querier is what tenantDB runs its queries on, the database or the transaction it's bound to.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (db tenantDB) querier() querier {
+	if db.tx != nil {
+		return db.tx
+	}
+	return db.DB
+}
+
+func (db tenantDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	query, args = withTenant(db.tenant, query, args)
+	return db.querier().ExecContext(ctx, query, args...)
+}
+
+func (db tenantDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	query, args = withTenant(db.tenant, query, args)
+	return db.querier().QueryContext(ctx, query, args...)
+}
+
+func (db tenantDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	query, args = withTenant(db.tenant, query, args)
+	return db.querier().QueryRowContext(ctx, query, args...)
+}
+
+// BeginTx begins a transaction, or a savepoint in the transaction the tenantDB is bound to so callers can still commit
+// or roll back their own changes without aborting the whole transaction.
+func (db tenantDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (tenantTx, error) {
+	if db.tx != nil {
+		_, err := db.tx.ExecContext(ctx, savepointNestedQuery)
+		return tenantTx{Tx: db.tx, tenant: db.tenant, nested: true}, err
+	}
+	tx, err := db.DB.BeginTx(ctx, opts)
+	return tenantTx{Tx: tx, tenant: db.tenant}, err
+}
+
+// tenantTx is a transaction scoped the same way as the tenantDB that began it.
+type tenantTx struct {
+	*sql.Tx
+	tenant string
+	// nested transactions are savepoints, committed or rolled back along with the transaction they're in
+	nested bool
+}
+
+func (tx tenantTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	query, args = withTenant(tx.tenant, query, args)
+	return tx.Tx.ExecContext(ctx, query, args...)
+}
+
+func (tx tenantTx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	query, args = withTenant(tx.tenant, query, args)
+	return tx.Tx.QueryContext(ctx, query, args...)
+}
+
+func (tx tenantTx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	query, args = withTenant(tx.tenant, query, args)
+	return tx.Tx.QueryRowContext(ctx, query, args...)
+}
+
+func (tx tenantTx) Commit() error {
+	if tx.nested {
+		_, err := tx.Tx.Exec(releaseSavepointNestedQuery)
+		return err
+	}
+	return tx.Tx.Commit()
+}
+
+func (tx tenantTx) Rollback() error {
+	if tx.nested {
+		if _, err := tx.Tx.Exec(rollbackToSavepointNestedQuery); err != nil {
+			return err
+		}
+		_, err := tx.Tx.Exec(releaseSavepointNestedQuery)
+		return err
+	}
+	return tx.Tx.Rollback()
+}
+
+// missedUpdate works out why an update made against version changed nothing, given versionQuery reading the version of
+// the row id: either the row is gone, giving notFound, or someone else updated it first.
+func (p Provider) missedUpdate(ctx context.Context, db querier, version int, notFound error, versionQuery, id string) error {
+	var current int
+	err := db.QueryRowContext(ctx, versionQuery, id).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound
+	} else if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting current version", slog.String("error", err.Error()))
+		return err
+	}
+	p.logger.LogAttrs(ctx, slog.LevelWarn, "Update was made against an old version", slog.Int("version", version), slog.Int("current_version", current))
+	return fmt.Errorf("%w: at version %d, not %d", backend.ErrVersionConflict, current, version)
+}
+
+func withTenant(tenant, query string, args []any) (string, []any) {
+	if !strings.Contains(query, "$tenant") {
+		return query, args
+	}
+	query = strings.ReplaceAll(query, "$tenant", fmt.Sprintf("$%d", len(args)+1))
+	return query, append(args[:len(args):len(args)], tenant)
+}
+
+// New connects to the database at the given connection string, see github.com/lib/pq for its format, and applies any
+// migrations it hasn't had yet.
+func New(logger *slog.Logger, dsn string) (Provider, error) {
+	l := logger.With(slog.String("source", "postgres_backend"))
+	b := Provider{
+		logger: l,
+		Db:     tenantDB{tenant: types.DefaultTenantID},
+	}
+	l.LogAttrs(context.Background(), slog.LevelInfo, "Connecting to database...")
+	db, err := sql.Open("postgres", dsn)
+	if err == nil {
+		err = db.Ping()
+	}
+	if err != nil {
+		l.LogAttrs(context.Background(), slog.LevelError, "Error connecting to postgres database", slog.String("error", err.Error()))
+		return Provider{}, err
+	}
+	l.LogAttrs(context.Background(), slog.LevelInfo, "Successfully connected to database")
+	l.LogAttrs(context.Background(), slog.LevelInfo, "Checking database structure...")
+	applied, err := migrate(context.Background(), db)
+	if err != nil {
+		l.LogAttrs(context.Background(), slog.LevelError, "Error migrating database", slog.String("error", err.Error()))
+		db.Close()
+		return Provider{}, err
+	}
+	l.LogAttrs(context.Background(), slog.LevelInfo, fmt.Sprintf("Applied %d migrations, database is at version %d", applied, len(migrations)))
+	b.Db.DB = db
+	return b, nil
+}
+
+// ForTenant returns a provider sharing the same database that only sees the given tenant's rows.
+func (p Provider) ForTenant(tenantID string) Provider {
+	p.logger = p.logger.With(slog.String("tenant_id", tenantID))
+	p.Db.tenant = tenantID
+	return p
+}
+
+// Scoped is ForTenant for the backend, which keeps members, qualifications and requirements behind separate providers.
+func (p Provider) Scoped(tenantID string) (backend.MemberProvider, backend.QualificationProvider, backend.RequirementProvider) {
+	scoped := p.ForTenant(tenantID)
+	return scoped, scoped, scoped
+}
+
+// WithTx runs fn against a copy of the provider bound to one transaction, see backend.Transactor.
+func (p Provider) WithTx(ctx context.Context, fn func(tx backend.Providers) error) error {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error starting transaction", slog.String("error", err.Error()))
+		return err
+	}
+	bound := p
+	bound.Db.tx = tx.Tx
+	if err = fn(backend.Providers{Members: bound, Qualifications: bound, Requirements: bound}); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", rollbackErr.Error()))
+		}
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// migrate brings the database up to the latest migration in a single transaction, returning how many were applied. The
+// migration table stays locked until it commits so instances starting together don't apply the same migration twice.
+func migrate(ctx context.Context, db *sql.DB) (int, error) {
+	if _, err := db.Exec(createMigrationTableQuery); err != nil {
+		return 0, fmt.Errorf("error creating migration table: %w", err)
+	}
+	tx, err := tenantDB{DB: db, tenant: types.DefaultTenantID}.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	if _, err = tx.ExecContext(ctx, lockMigrationsQuery); err != nil {
+		return 0, fmt.Errorf("error locking migration table: %w", err)
+	}
+	var version int
+	if err = tx.QueryRowContext(ctx, getMigrationVersionQuery).Scan(&version); err != nil {
+		return 0, fmt.Errorf("error getting migration version: %w", err)
+	}
+	if version > len(migrations) {
+		return 0, fmt.Errorf("database is at migration %d but only %d are known, is this instance out of date?", version, len(migrations))
+	}
+	for i := version; i < len(migrations); i++ {
+		if _, err = tx.ExecContext(ctx, migrations[i]); err != nil {
+			return 0, fmt.Errorf("error applying migration %d: %w", i+1, err)
+		}
+		if i == 0 {
+			if err = seedDefaultTenant(ctx, tx); err != nil {
+				return 0, err
+			}
+		}
+		if _, err = tx.ExecContext(ctx, insertMigrationQuery, i+1, time.Now()); err != nil {
+			return 0, fmt.Errorf("error recording migration %d: %w", i+1, err)
+		}
+	}
+	return len(migrations) - version, tx.Commit()
+}
+
+// seedDefaultTenant adds the default tenant everything belongs to in single tenant deployments.
+func seedDefaultTenant(ctx context.Context, tx tenantTx) error {
+	if _, err := tx.ExecContext(ctx, insertTenantQuery, types.DefaultTenantID, "Default", nil); err != nil {
+		return fmt.Errorf("error adding default tenant: %w", err)
+	}
+	return seedRolePermissions(ctx, tx)
+}
+
+// uniqueViolation reports whether err is a violation of the named unique constraint.
+func uniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+// foreignKeyViolation reports whether err is a violation of any foreign key.
+func foreignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
+
+// seedRolePermissions gives a new tenant the default permissions for every role.
+func seedRolePermissions(ctx context.Context, tx tenantTx) error {
+	for role, permissions := range types.DefaultRolePermissions {
+		for _, permission := range permissions {
+			if _, err := tx.ExecContext(ctx, insertRolePermissionQuery, role, permission); err != nil {
+				return fmt.Errorf("error seeding role permissions: %w", err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package postgres_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/postgres"
+	"PORTal/providers/providertest"
+	"PORTal/testutils"
+	"PORTal/types"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+// newTestDSN returns a connection string for a fresh schema in the database at PORTAL_TEST_POSTGRES_DSN, dropped once
+// the test finishes. Tests are skipped when it isn't set.
+func newTestDSN(t *testing.T) string {
+	t.Helper()
+	dsn := os.Getenv("PORTAL_TEST_POSTGRES_DSN")
+	if dsn == "" && os.Getenv("CI") != "" {
+		t.Fatalf("PORTAL_TEST_POSTGRES_DSN has to be set in CI, see .github/workflows/test.yml")
+	} else if dsn == "" {
+		t.Skip("PORTAL_TEST_POSTGRES_DSN isn't set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("Error connecting to test database: %s", err.Error())
+	}
+	schema := "portal_test_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	if _, err = db.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("Error creating test schema: %s", err.Error())
+	}
+	t.Cleanup(func() {
+		db.Exec("DROP SCHEMA " + schema + " CASCADE")
+		db.Close()
+	})
+	// Connection parameters pq doesn't know are sent to the server as run-time parameters
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+		return u.String()
+	}
+	return fmt.Sprintf("%s search_path=%s", dsn, schema)
+}
+
+func newTestBackend(t *testing.T) (backend.Backend, postgres.Provider) {
+	t.Helper()
+	provider, err := postgres.New(slog.Default(), newTestDSN(t))
+	if err != nil {
+		t.Fatalf("Error creating provider for tests: %s", err.Error())
+	}
+	t.Cleanup(func() { provider.Db.Close() })
+	b := backend.New(slog.Default(), provider, provider, provider, backend.Config{BcryptCost: bcrypt.MinCost}, nil).WithTenants(provider)
+	return b, provider
+}
+
+func TestConformance(t *testing.T) {
+	providertest.Run(t, func(t *testing.T) providertest.Provider {
+		provider, err := postgres.New(slog.Default(), newTestDSN(t))
+		if err != nil {
+			t.Fatalf("Error creating provider for tests: %s", err.Error())
+		}
+		t.Cleanup(func() { provider.Db.Close() })
+		return provider
+	})
+}
+
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+	dsn := newTestDSN(t)
+	for i := 0; i < 2; i++ {
+		provider, err := postgres.New(slog.Default(), dsn)
+		if err != nil {
+			t.Fatalf("Error creating provider on attempt %d: %s", i+1, err.Error())
+		}
+		var versions int
+		if err = provider.Db.QueryRowContext(ctx, "SELECT COUNT(*) FROM schema_migration;").Scan(&versions); err != nil {
+			t.Fatalf("Error counting migrations: %s", err.Error())
+		}
+		if versions != 5 {
+			t.Errorf("Expected 5 migrations to be recorded after connecting %d times, got: %d", i+1, versions)
+		}
+		if _, err = provider.GetTenant(ctx, types.DefaultTenantID); err != nil {
+			t.Errorf("Expected default tenant to exist, got: %s", err.Error())
+		}
+		provider.Db.Close()
+	}
+}
+
+func TestMembers(t *testing.T) {
+	ctx := context.Background()
+	b, _ := newTestBackend(t)
+	supervisor, err := b.AddMember(ctx, testutils.RandomMember(true))
+	if err != nil {
+		t.Fatalf("Error adding member: %s", err.Error())
+	}
+	m := testutils.RandomMember(false)
+	m.SupervisorID = supervisor.ID
+	m.CertificateID = testutils.RandomString()
+	m, err = b.AddMember(ctx, m)
+	if err != nil {
+		t.Fatalf("Error adding supervised member: %s", err.Error())
+	}
+	got, err := b.GetMember(ctx, m.Username)
+	if err != nil || got.ID != m.ID || got.SupervisorID != supervisor.ID || got.Admin {
+		t.Errorf("Expected member %+v, got: %+v, %v", m, got, err)
+	}
+	subordinates, err := b.GetSubordinates(ctx, supervisor.ID)
+	if err != nil || len(subordinates) != 1 || subordinates[0].ID != m.ID {
+		t.Errorf("Expected %s to be the only subordinate, got: %+v, %v", m.ID, subordinates, err)
+	}
+
+	duplicate := testutils.RandomMember(false)
+	duplicate.Username = m.Username
+	if _, err = b.AddMember(ctx, duplicate); !errors.Is(err, backend.ErrDuplicateUsername) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrDuplicateUsername, err)
+	}
+	duplicate = testutils.RandomMember(false)
+	duplicate.CertificateID = m.CertificateID
+	if _, err = b.AddMember(ctx, duplicate); !errors.Is(err, backend.ErrDuplicateCertificate) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrDuplicateCertificate, err)
+	}
+	orphan := testutils.RandomMember(false)
+	orphan.SupervisorID = uuid.NewString()
+	if _, err = b.AddMember(ctx, orphan); !errors.Is(err, backend.ErrSupervisorNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrSupervisorNotFound, err)
+	}
+	if _, err = b.GetMember(ctx, uuid.NewString()); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMemberNotFound, err)
+	}
+}
+
+func TestQualifications(t *testing.T) {
+	ctx := context.Background()
+	b, provider := newTestBackend(t)
+	ref, err := b.AddReference(ctx, testutils.RandomReference())
+	if err != nil {
+		t.Fatalf("Error adding reference: %s", err.Error())
+	}
+	req, err := b.AddRequirement(ctx, testutils.RandomRequirement(ref))
+	if err != nil {
+		t.Fatalf("Error adding requirement: %s", err.Error())
+	}
+	q := testutils.RandomQualification()
+	q.InitialRequirements = []types.Requirement{req}
+	q, err = b.AddQualification(ctx, q)
+	if err != nil {
+		t.Fatalf("Error adding qualification: %s", err.Error())
+	}
+	// Updating with a requirement the qualification already has is fine
+	q.Notes = testutils.RandomString()
+	q.RecurringRequirements = []types.Requirement{req}
+	if _, err = b.UpdateQualification(ctx, q, true); err != nil {
+		t.Fatalf("Error updating qualification: %s", err.Error())
+	}
+	got, err := b.GetQualification(ctx, q.ID)
+	if err != nil || !testutils.CompareQuals(got, q) {
+		t.Errorf("Expected qualification %+v, got: %+v, %v", q, got, err)
+	}
+
+	m, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member: %s", err.Error())
+	}
+	if err = b.AssignMemberQualification(ctx, "", m.ID, q.ID); err != nil {
+		t.Fatalf("Error assigning qualification: %s", err.Error())
+	}
+	// A failing pair rolls back on its own without aborting the rest of the batch
+	other, err := b.AddQualification(ctx, testutils.RandomQualification())
+	if err != nil {
+		t.Fatalf("Error adding qualification: %s", err.Error())
+	}
+	errs, err := provider.AssignMemberQualifications(ctx, []types.MemberQualificationPair{
+		{MemberID: m.ID, QualificationID: q.ID},
+		{MemberID: uuid.NewString(), QualificationID: q.ID},
+		{MemberID: m.ID, QualificationID: uuid.NewString()},
+		{MemberID: m.ID, QualificationID: other.ID},
+	})
+	if err != nil {
+		t.Fatalf("Error assigning qualifications: %s", err.Error())
+	}
+	expected := []error{backend.ErrQualificationAlreadyAssigned, backend.ErrMemberNotFound, backend.ErrQualificationNotFound, nil}
+	for i := range expected {
+		if !errors.Is(errs[i], expected[i]) {
+			t.Errorf("Expected error %v for pair %d, got: %v", expected[i], i, errs[i])
+		}
+	}
+	quals, err := b.GetMemberQualifications(ctx, m.ID)
+	if err != nil || len(quals) != 2 {
+		t.Errorf("Expected member to have 2 qualifications, got: %+v, %v", quals, err)
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	ctx := context.Background()
+	b, _ := newTestBackend(t)
+	m, err := b.AddMember(ctx, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding member: %s", err.Error())
+	}
+	tenant, admin, err := b.AddTenant(ctx, types.Tenant{Name: "62nd APS", Subdomain: "62aps"}, testutils.RandomMember(false))
+	if err != nil {
+		t.Fatalf("Error adding tenant: %s", err.Error())
+	}
+	if _, _, err = b.AddTenant(ctx, types.Tenant{Name: "Duplicate", Subdomain: "62aps"}, testutils.RandomMember(false)); !errors.Is(err, backend.ErrDuplicateTenant) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrDuplicateTenant, err)
+	}
+	scoped := b.ForTenant(tenant.ID)
+	if _, err = scoped.GetMember(ctx, m.ID); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected error: %s, got: %v", backend.ErrMemberNotFound, err)
+	}
+	members, err := scoped.GetAllMembers(ctx)
+	if err != nil || len(members) != 1 || members[0].ID != admin.ID {
+		t.Errorf("Expected tenant to only have its admin, got: %+v, %v", members, err)
+	}
+	// Usernames are only unique within a tenant
+	reused := testutils.RandomMember(false)
+	reused.Username = m.Username
+	if _, err = scoped.AddMember(ctx, reused); err != nil {
+		t.Errorf("Expected username to be free in another tenant, got: %s", err.Error())
+	}
+}
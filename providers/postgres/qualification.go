@@ -0,0 +1,494 @@
+package postgres
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+func (p Provider) AddQualification(ctx context.Context, q types.Qualification) error {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for AddQualification", slog.String("error", err.Error()))
+		return err
+	}
+	_, err = tx.ExecContext(ctx, insertQualificationQuery, q.ID, q.Name, q.Notes, q.Expires, q.ExpirationDays)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting qualification into database", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	for _, initialRequirement := range q.InitialRequirements {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding initial requirement to Qualification",
+			slog.String("qualification_id", q.ID), slog.String("requirement_id", initialRequirement.ID))
+		_, err = tx.ExecContext(ctx, insertQualificationInitialRequirementQuery, q.ID, initialRequirement.ID)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error adding requirement to qualification", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, recurringRequirement := range q.RecurringRequirements {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding recurring requirement to Qualification",
+			slog.String("qualification_id", q.ID), slog.String("requirement_id", recurringRequirement.ID))
+		_, err = tx.ExecContext(ctx, insertQualificationRecurringRequirementQuery, q.ID, recurringRequirement.ID)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error adding requirement to qualification", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, prerequisiteID := range q.Prerequisites {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding prerequisite to Qualification",
+			slog.String("qualification_id", q.ID), slog.String("prerequisite_id", prerequisiteID))
+		_, err = tx.ExecContext(ctx, insertQualificationPrerequisiteQuery, q.ID, prerequisiteID)
+		if foreignKeyViolation(err) {
+			p.logger.LogAttrs(ctx, slog.LevelWarn, "Prerequisite qualification not found")
+			tx.Rollback()
+			return fmt.Errorf("%w: %s", backend.ErrQualificationNotFound, prerequisiteID)
+		}
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error adding prerequisite to qualification", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing qualification", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// qualificationQueries read the qualifications in some scope, then their requirements and prerequisites, so loading
+// them takes the same number of queries however many there are.
+type qualificationQueries struct {
+	qualifications, initialRequirements, recurringRequirements, prerequisites string
+}
+
+var (
+	qualificationByID = qualificationQueries{
+		qualifications:        getQualificationQuery,
+		initialRequirements:   getInitialRequirementsQuery,
+		recurringRequirements: getRecurringRequirementsQuery,
+		prerequisites:         getPrerequisitesQuery,
+	}
+	allQualifications = qualificationQueries{
+		qualifications:        getAllQualificationsQuery,
+		initialRequirements:   getAllInitialRequirementsQuery,
+		recurringRequirements: getAllRecurringRequirementsQuery,
+		prerequisites:         getAllPrerequisitesQuery,
+	}
+	memberQualifications = qualificationQueries{
+		qualifications:        getMemberQualificationsQuery,
+		initialRequirements:   getMemberInitialRequirementsQuery,
+		recurringRequirements: getMemberRecurringRequirementsQuery,
+		prerequisites:         getMemberQualificationPrerequisitesQuery,
+	}
+)
+
+// loadQualifications runs queries with the same arguments and assembles the qualifications they return.
+func (p Provider) loadQualifications(ctx context.Context, queries qualificationQueries, args ...any) ([]types.Qualification, error) {
+	rows, err := p.Db.QueryContext(ctx, queries.qualifications, args...)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting qualifications from database", slog.String("error", err.Error()))
+		return nil, err
+	}
+	var quals []types.Qualification
+	index := map[string]int{}
+	for rows.Next() {
+		var q types.Qualification
+		if err = rows.Scan(&q.ID, &q.Name, &q.Notes, &q.Expires, &q.ExpirationDays, &q.Version); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning qualification into struct", slog.String("error", err.Error()))
+			rows.Close()
+			return nil, err
+		}
+		index[q.ID] = len(quals)
+		quals = append(quals, q)
+	}
+	rows.Close()
+	if len(quals) == 0 {
+		return nil, nil
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Retrieving initial requirements")
+	initial, err := p.loadQualificationRequirements(ctx, queries.initialRequirements, args...)
+	if err != nil {
+		return nil, err
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Retrieving recurring requirements")
+	recurring, err := p.loadQualificationRequirements(ctx, queries.recurringRequirements, args...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range quals {
+		quals[i].InitialRequirements = initial[quals[i].ID]
+		quals[i].RecurringRequirements = recurring[quals[i].ID]
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Retrieving prerequisites")
+	rows, err = p.Db.QueryContext(ctx, queries.prerequisites, args...)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting prerequisites for qualifications", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	var qualificationID, prerequisiteID string
+	for rows.Next() {
+		if err = rows.Scan(&qualificationID, &prerequisiteID); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning prerequisite", slog.String("error", err.Error()))
+			return nil, err
+		}
+		if i, ok := index[qualificationID]; ok {
+			quals[i].Prerequisites = append(quals[i].Prerequisites, prerequisiteID)
+		}
+	}
+	return quals, nil
+}
+
+// loadQualificationRequirements returns the requirements linked by query, keyed by qualification ID.
+func (p Provider) loadQualificationRequirements(ctx context.Context, query string, args ...any) (map[string][]types.Requirement, error) {
+	rows, err := p.Db.QueryContext(ctx, query, args...)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting requirements for qualifications", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	reqs := map[string][]types.Requirement{}
+	var qualificationID string
+	for rows.Next() {
+		var r types.Requirement
+		err = rows.Scan(&qualificationID, &r.ID, &r.Name, &r.Description, &r.Notes, &r.DaysValidFor, &r.Version, &r.Reference.ID, &r.Reference.Name, &r.Reference.Volume, &r.Reference.Paragraph, &r.Reference.Version)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning requirement into struct", slog.String("error", err.Error()))
+			return nil, err
+		}
+		reqs[qualificationID] = append(reqs[qualificationID], r)
+	}
+	return reqs, nil
+}
+
+func (p Provider) GetQualification(ctx context.Context, id string) (types.Qualification, error) {
+	quals, err := p.loadQualifications(ctx, qualificationByID, id)
+	if err != nil {
+		return types.Qualification{}, err
+	}
+	if len(quals) == 0 {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Could not find qualification with given id")
+		return types.Qualification{}, backend.ErrQualificationNotFound
+	}
+	return quals[0], nil
+}
+
+// GetPrerequisiteGraph returns the direct prerequisites of every qualification that has any, keyed by qualification ID.
+// In a transaction it first takes the tenant's prerequisite lock, which is held until the transaction ends.
+func (p Provider) GetPrerequisiteGraph(ctx context.Context) (map[string][]string, error) {
+	if p.Db.tx != nil {
+		if _, err := p.Db.ExecContext(ctx, lockPrerequisitesQuery); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error locking qualification prerequisites", slog.String("error", err.Error()))
+			return nil, err
+		}
+	}
+	rows, err := p.Db.QueryContext(ctx, getAllPrerequisitesQuery)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting qualification prerequisites", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	graph := map[string][]string{}
+	var qualificationID, prerequisiteID string
+	for rows.Next() {
+		if err = rows.Scan(&qualificationID, &prerequisiteID); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning prerequisite", slog.String("error", err.Error()))
+			return nil, err
+		}
+		graph[qualificationID] = append(graph[qualificationID], prerequisiteID)
+	}
+	return graph, nil
+}
+
+func (p Provider) GetAllQualifications(ctx context.Context) ([]types.Qualification, error) {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Getting all qualifications from database")
+	quals, err := p.loadQualifications(ctx, allQualifications)
+	if err != nil {
+		return nil, err
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Found %d qualifications in database", len(quals)))
+	return quals, nil
+}
+
+// ListQualifications loads a page of qualifications the same way as GetAllQualifications, only for the qualifications
+// on the page.
+func (p Provider) ListQualifications(ctx context.Context, opts types.ListOptions) (types.Page[types.Qualification], error) {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Listing qualifications from database")
+	after, err := decodeCursor(opts, types.Qualification{})
+	if err != nil {
+		return types.Page[types.Qualification]{}, err
+	}
+	q := listQuery{from: "qualification", tenant: "tenant_id"}
+	total, err := p.countRows(ctx, q)
+	if err != nil {
+		return types.Page[types.Qualification]{}, err
+	}
+	page, args := q.page("id", []string{"name", "id"}, opts, after)
+	orderBy := " ORDER BY name, id"
+	if opts.Descending {
+		orderBy = " ORDER BY name DESC, id DESC"
+	}
+	quals, err := p.loadQualifications(ctx, qualificationQueries{
+		qualifications:        "SELECT " + qualificationColumns + " FROM qualification WHERE id IN (" + page + ") AND tenant_id=$tenant" + orderBy,
+		initialRequirements:   "SELECT " + qualificationRequirementColumns + " FROM qualification_initial_requirement" + qualificationRequirementJoin + " WHERE l.qualification_id IN (" + page + ") AND l.tenant_id=$tenant ORDER BY r.name",
+		recurringRequirements: "SELECT " + qualificationRequirementColumns + " FROM qualification_recurring_requirement" + qualificationRequirementJoin + " WHERE l.qualification_id IN (" + page + ") AND l.tenant_id=$tenant ORDER BY r.name",
+		prerequisites:         "SELECT qualification_id, prerequisite_id FROM qualification_prerequisite WHERE qualification_id IN (" + page + ") AND tenant_id=$tenant ORDER BY prerequisite_id",
+	}, args...)
+	if err != nil {
+		return types.Page[types.Qualification]{}, err
+	}
+	return types.NewPage(quals, total, opts), nil
+}
+
+func (p Provider) UpdateQualification(ctx context.Context, q types.Qualification) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Updating qualification", slog.Any("qualification", q))
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for UpdateQualification", slog.String("error", err.Error()))
+		return err
+	}
+	res, err := tx.ExecContext(ctx, updateQualificationQuery, q.Name, q.Notes, q.Expires, q.ExpirationDays, q.ID, q.Version)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error updating qualification in database", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
+		if err = tx.Rollback(); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+			return err
+		}
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
+		return err
+	}
+	if count, _ := res.RowsAffected(); count == 0 {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Expected to update 1 qualification but got 0")
+		missed := p.missedUpdate(ctx, tx, q.Version, backend.ErrQualificationNotFound, getQualificationVersionQuery, q.ID)
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
+		if err = tx.Rollback(); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+			return err
+		}
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
+		return missed
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Getting existing initial requirement IDs")
+	var existingInitialIDs []string
+	rows, err := tx.QueryContext(ctx, getInitialRequirementIdsQuery, q.ID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting existing initial requirements for qualification", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
+		if err = tx.Rollback(); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+			return err
+		}
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
+		return err
+	}
+	var id string
+	for rows.Next() {
+		err = rows.Scan(&id)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning ID into string", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
+			if err = tx.Rollback(); err != nil {
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+				return err
+			}
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
+			return err
+		}
+		existingInitialIDs = append(existingInitialIDs, id)
+	}
+	rows.Close()
+	p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Found %d existing initial requirements", len(existingInitialIDs)))
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Getting existing recurring requirement IDs")
+	var existingRecurringIds []string
+	rows, err = tx.QueryContext(ctx, getRecurringRequirementIdsQuery, q.ID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting existing recurring requirements for qualification", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
+		if err = tx.Rollback(); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+			return err
+		}
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
+		return err
+	}
+	for rows.Next() {
+		err = rows.Scan(&id)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning ID into string", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
+			if err = tx.Rollback(); err != nil {
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+				return err
+			}
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
+			return err
+		}
+		existingRecurringIds = append(existingRecurringIds, id)
+	}
+	rows.Close()
+	p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Found %d existing recurring requirements", len(existingRecurringIds)))
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Determining initial qualifications to be removed")
+	var initialIdsToBeRemoved []string
+	for _, id := range existingInitialIDs {
+		found := false
+		for _, newReq := range q.InitialRequirements {
+			if id == newReq.ID {
+				found = true
+			}
+		}
+		if !found {
+			initialIdsToBeRemoved = append(initialIdsToBeRemoved, id)
+		}
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Initial requirements set to be removed: %s", initialIdsToBeRemoved))
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Determining recurring qualifications to be removed")
+	var recurringIdsToBeRemoved []string
+	for _, id := range existingRecurringIds {
+		found := false
+		for _, newReq := range q.RecurringRequirements {
+			if id == newReq.ID {
+				found = true
+			}
+		}
+		if !found {
+			recurringIdsToBeRemoved = append(recurringIdsToBeRemoved, id)
+		}
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Recurring requirements set to be removed: %s", recurringIdsToBeRemoved))
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Removing initial requirements")
+	for _, id := range initialIdsToBeRemoved {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Removing initial requirement: %s", id))
+		_, err = tx.ExecContext(ctx, deleteQualificationInitialRequirementQuery, id)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error deleting initial requirement from qualification", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
+			if err = tx.Rollback(); err != nil {
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+				return err
+			}
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
+			return err
+		}
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully removed initial requirement")
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Removing recurring requirements")
+	for _, id := range recurringIdsToBeRemoved {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Removing recurring requirement: %s", id))
+		_, err = tx.ExecContext(ctx, deleteQualificationRecurringRequirementQuery, id)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error deleting recurring requirement from qualification", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
+			if err = tx.Rollback(); err != nil {
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+				return err
+			}
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
+			return err
+		}
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully removed recurring requirement")
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding new initial requirements")
+	for _, newReq := range q.InitialRequirements {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Adding initial requirement: %s", newReq.ID))
+		_, err := tx.ExecContext(ctx, insertQualificationInitialRequirementQuery, q.ID, newReq.ID)
+		if err != nil {
+			var errToReturn error
+			if foreignKeyViolation(err) {
+				p.logger.LogAttrs(ctx, slog.LevelError, "Initial requirement to be added not found")
+				errToReturn = fmt.Errorf("%w: %s", backend.ErrRequirementNotFound, newReq.ID)
+			} else {
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error adding new initial requirement to query", slog.String("error", err.Error()))
+				p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
+				errToReturn = err
+			}
+			if err := tx.Rollback(); err != nil {
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction",
+					slog.String("error", err.Error()), slog.String("original_error", err.Error()))
+				return err
+			}
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
+			return errToReturn
+		}
+	}
+	for _, newReq := range q.RecurringRequirements {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Adding recurring requirement: %s", newReq.ID))
+		_, err := tx.ExecContext(ctx, insertQualificationRecurringRequirementQuery, q.ID, newReq.ID)
+		if err != nil {
+			var errToReturn error
+			if foreignKeyViolation(err) {
+				p.logger.LogAttrs(ctx, slog.LevelError, "Recurring requirement to be added not found")
+				errToReturn = fmt.Errorf("%w: %s", backend.ErrRequirementNotFound, newReq.ID)
+			} else {
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error adding new recurring requirement to query", slog.String("error", err.Error()))
+				p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
+				errToReturn = err
+			}
+			if err := tx.Rollback(); err != nil {
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction",
+					slog.String("error", err.Error()), slog.String("original_error", err.Error()))
+				return err
+			}
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
+			return errToReturn
+		}
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Replacing prerequisites")
+	if _, err = tx.ExecContext(ctx, deleteQualificationPrerequisitesQuery, q.ID); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error removing existing prerequisites", slog.String("error", err.Error()))
+		if rbErr := tx.Rollback(); rbErr != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", rbErr.Error()))
+		}
+		return err
+	}
+	for _, prerequisiteID := range q.Prerequisites {
+		_, err = tx.ExecContext(ctx, insertQualificationPrerequisiteQuery, q.ID, prerequisiteID)
+		if err != nil {
+			errToReturn := err
+			if foreignKeyViolation(err) {
+				p.logger.LogAttrs(ctx, slog.LevelWarn, "Prerequisite qualification not found")
+				errToReturn = fmt.Errorf("%w: %s", backend.ErrQualificationNotFound, prerequisiteID)
+			} else {
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error adding prerequisite to qualification", slog.String("error", err.Error()))
+			}
+			if rbErr := tx.Rollback(); rbErr != nil {
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", rbErr.Error()))
+			}
+			return errToReturn
+		}
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Committing transaction")
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
+		if err = tx.Rollback(); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+			return err
+		}
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
+		return err
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully committed transaction")
+	return nil
+}
+
+func (p Provider) DeleteQualification(ctx context.Context, id string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting qualification from database", slog.String("id", id))
+	res, err := p.Db.ExecContext(ctx, deleteQualificationQuery, id)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "error deleting qualification from database", slog.String("error", err.Error()))
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "no qualification with that ID exists to be deleted")
+		return backend.ErrQualificationNotFound
+	}
+	return nil
+}
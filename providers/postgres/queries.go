@@ -0,0 +1,564 @@
+package postgres
+
+// migrations are applied in order and recorded in schema_migration by their position, starting at 1. Applied migrations
+// must never change, add a new one instead.
+var migrations = []string{
+	createStructureQuery,
+	addVersionColumnsQuery,
+	addTenantLinksQuery,
+	addQualificationSourceQuery,
+	addMemberTransferQuery,
+}
+
+const (
+	createMigrationTableQuery = "CREATE TABLE IF NOT EXISTS schema_migration(version integer PRIMARY KEY, applied timestamptz NOT NULL);"
+	lockMigrationsQuery       = "LOCK TABLE schema_migration IN EXCLUSIVE MODE;"
+	getMigrationVersionQuery  = "SELECT COALESCE(MAX(version), 0) FROM schema_migration;"
+	insertMigrationQuery      = "INSERT INTO schema_migration(version, applied) VALUES($1, $2);"
+
+	savepointQuery           = "SAVEPOINT pair;"
+	rollbackToSavepointQuery = "ROLLBACK TO SAVEPOINT pair;"
+	releaseSavepointQuery    = "RELEASE SAVEPOINT pair;"
+
+	// A transaction begun within another runs under a savepoint, see tenantDB.BeginTx.
+	savepointNestedQuery           = "SAVEPOINT nested;"
+	rollbackToSavepointNestedQuery = "ROLLBACK TO SAVEPOINT nested;"
+	releaseSavepointNestedQuery    = "RELEASE SAVEPOINT nested;"
+
+	createStructureQuery = `CREATE TABLE tenant(
+    id text PRIMARY KEY,
+    name text,
+    subdomain text,
+    CONSTRAINT tenant_subdomain_key UNIQUE (subdomain)
+);
+
+CREATE TABLE unit(
+    id text PRIMARY KEY,
+    name text,
+    kind text,
+    parent_id text,
+    tenant_id text NOT NULL,
+    UNIQUE (id, tenant_id),
+    FOREIGN KEY (parent_id, tenant_id) REFERENCES unit(id, tenant_id)
+);
+
+CREATE TABLE member(
+    id text PRIMARY KEY,
+    first_name text,
+    last_name text,
+    rank text,
+    user_name text,
+    supervisor_id text,
+    admin boolean,
+    role text,
+    hash text,
+    certificate_id text,
+    email text,
+    archived timestamptz,
+    archive_reason text,
+    archived_by text,
+    unit_id text,
+    tenant_id text NOT NULL,
+    UNIQUE (id, tenant_id),
+    CONSTRAINT member_user_name_key UNIQUE (user_name, tenant_id),
+    CONSTRAINT member_certificate_id_key UNIQUE (certificate_id, tenant_id),
+    FOREIGN KEY (supervisor_id) REFERENCES member(id) ON DELETE SET NULL,
+    FOREIGN KEY (unit_id) REFERENCES unit(id) ON DELETE SET NULL
+);
+
+CREATE TABLE qualification(
+    id text PRIMARY KEY,
+    name text,
+    notes text,
+    expires boolean,
+    expiration_days integer,
+    tenant_id text NOT NULL,
+    UNIQUE (id, tenant_id),
+    CONSTRAINT qualification_name_key UNIQUE (name, tenant_id)
+);
+
+CREATE TABLE member_qualification(
+    member_id text,
+    qualification_id text,
+    tenant_id text NOT NULL,
+    CONSTRAINT member_qualification_pkey PRIMARY KEY (member_id, qualification_id),
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (qualification_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE
+);
+
+CREATE TABLE reference(
+    id text PRIMARY KEY,
+    name text,
+    volume integer,
+    paragraph text,
+    tenant_id text NOT NULL,
+    CONSTRAINT reference_name_key UNIQUE (name, tenant_id)
+);
+
+CREATE TABLE requirement(
+    id text PRIMARY KEY,
+    name text,
+    description text,
+    notes text,
+    days_valid_for integer,
+    reference_id text,
+    tenant_id text NOT NULL,
+    UNIQUE (id, tenant_id),
+    CONSTRAINT requirement_name_key UNIQUE (name, tenant_id),
+    FOREIGN KEY (reference_id) REFERENCES reference(id) ON DELETE SET NULL
+);
+
+CREATE TABLE member_requirement(
+    member_id text,
+    requirement_id text,
+    initial_completion timestamptz,
+    most_recent_completion timestamptz,
+    tenant_id text NOT NULL,
+    PRIMARY KEY (member_id, requirement_id),
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (requirement_id, tenant_id) REFERENCES requirement(id, tenant_id) ON DELETE CASCADE
+);
+
+CREATE TABLE qualification_initial_requirement(
+    qualification_id text,
+    requirement_id text,
+    tenant_id text NOT NULL,
+    PRIMARY KEY (qualification_id, requirement_id),
+    FOREIGN KEY (qualification_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (requirement_id, tenant_id) REFERENCES requirement(id, tenant_id) ON DELETE CASCADE
+);
+
+CREATE TABLE qualification_recurring_requirement(
+    qualification_id text,
+    requirement_id text,
+    tenant_id text NOT NULL,
+    PRIMARY KEY (qualification_id, requirement_id),
+    FOREIGN KEY (qualification_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (requirement_id, tenant_id) REFERENCES requirement(id, tenant_id) ON DELETE CASCADE
+);
+
+CREATE TABLE qualification_prerequisite(
+    qualification_id text,
+    prerequisite_id text,
+    tenant_id text NOT NULL,
+    PRIMARY KEY (qualification_id, prerequisite_id),
+    FOREIGN KEY (qualification_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (prerequisite_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE
+);
+
+CREATE TABLE session(
+    id text PRIMARY KEY,
+    expiration timestamptz,
+    user_agent text,
+    tenant_id text NOT NULL,
+    UNIQUE (id, tenant_id)
+);
+
+CREATE TABLE member_session(
+    member_id text,
+    session_id text,
+    tenant_id text NOT NULL,
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (session_id, tenant_id) REFERENCES session(id, tenant_id) ON DELETE CASCADE,
+    PRIMARY KEY (member_id, session_id)
+);
+
+CREATE TABLE api_token(
+    id text PRIMARY KEY,
+    member_id text,
+    name text,
+    scope text,
+    hash text UNIQUE,
+    created timestamptz,
+    last_used timestamptz,
+    tenant_id text NOT NULL,
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE
+);
+
+CREATE TABLE role_permission(
+    role text,
+    permission text,
+    tenant_id text NOT NULL,
+    PRIMARY KEY (role, permission, tenant_id)
+);
+
+CREATE TABLE requirement_certifier(
+    requirement_id text,
+    member_id text,
+    tenant_id text NOT NULL,
+    CONSTRAINT requirement_certifier_pkey PRIMARY KEY (requirement_id, member_id),
+    FOREIGN KEY (requirement_id, tenant_id) REFERENCES requirement(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE
+);
+
+CREATE TABLE completion(
+    id text PRIMARY KEY,
+    member_id text,
+    requirement_id text,
+    trainer_id text,
+    certifier_id text,
+    submitted_by text,
+    status text,
+    completed_date timestamptz,
+    submitted timestamptz,
+    reviewed timestamptz,
+    comments text,
+    tenant_id text NOT NULL,
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (requirement_id, tenant_id) REFERENCES requirement(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (trainer_id) REFERENCES member(id) ON DELETE SET NULL,
+    FOREIGN KEY (certifier_id) REFERENCES member(id) ON DELETE SET NULL
+);
+
+CREATE TABLE waiver(
+    id text PRIMARY KEY,
+    member_id text,
+    requirement_id text,
+    qualification_id text,
+    kind text,
+    approver_id text,
+    reason text,
+    start_date timestamptz,
+    end_date timestamptz,
+    created timestamptz,
+    memo_name text,
+    memo_type text,
+    memo bytea,
+    tenant_id text NOT NULL,
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (requirement_id, tenant_id) REFERENCES requirement(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (qualification_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (approver_id) REFERENCES member(id) ON DELETE SET NULL
+);
+
+CREATE TABLE audit_log(
+    id text PRIMARY KEY,
+    actor_id text,
+    action text,
+    entity_type text,
+    entity_id text,
+    time timestamptz,
+    details text,
+    tenant_id text NOT NULL
+);
+
+CREATE TABLE duty_position(
+    id text PRIMARY KEY,
+    name text,
+    description text,
+    tenant_id text NOT NULL,
+    UNIQUE (id, tenant_id),
+    CONSTRAINT duty_position_name_key UNIQUE (name, tenant_id)
+);
+
+CREATE TABLE duty_position_qualification(
+    position_id text,
+    qualification_id text,
+    tenant_id text NOT NULL,
+    PRIMARY KEY (position_id, qualification_id),
+    FOREIGN KEY (position_id, tenant_id) REFERENCES duty_position(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (qualification_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE
+);
+
+CREATE TABLE member_duty_position(
+    member_id text,
+    position_id text,
+    tenant_id text NOT NULL,
+    CONSTRAINT member_duty_position_pkey PRIMARY KEY (member_id, position_id),
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (position_id, tenant_id) REFERENCES duty_position(id, tenant_id) ON DELETE CASCADE
+);
+
+CREATE TABLE import_profile(
+    id text PRIMARY KEY,
+    name text,
+    member_column text,
+    requirement_column text,
+    date_column text,
+    date_format text,
+    tenant_id text NOT NULL,
+    CONSTRAINT import_profile_name_key UNIQUE (name, tenant_id)
+);
+
+CREATE TABLE import_batch(
+    id text PRIMARY KEY,
+    profile_id text,
+    file_name text,
+    uploaded_by text,
+    uploaded timestamptz,
+    status text,
+    tenant_id text NOT NULL,
+    UNIQUE (id, tenant_id),
+    FOREIGN KEY (profile_id) REFERENCES import_profile(id) ON DELETE SET NULL,
+    FOREIGN KEY (uploaded_by) REFERENCES member(id) ON DELETE SET NULL
+);
+
+CREATE TABLE import_row(
+    id text PRIMARY KEY,
+    batch_id text,
+    line integer,
+    member_identifier text,
+    member_id text,
+    requirement_name text,
+    requirement_id text,
+    match_score double precision,
+    completed_date timestamptz,
+    excluded boolean,
+    errors text,
+    tenant_id text NOT NULL,
+    FOREIGN KEY (batch_id, tenant_id) REFERENCES import_batch(id, tenant_id) ON DELETE CASCADE
+);
+
+CREATE TABLE member_qualification_history(
+    id text PRIMARY KEY,
+    seq bigserial,
+    member_id text,
+    qualification_id text,
+    kind text,
+    actor_id text,
+    time timestamptz,
+    tenant_id text NOT NULL
+);
+
+CREATE TABLE unit_mandatory_qualification(
+    unit_id text,
+    qualification_id text,
+    tenant_id text NOT NULL,
+    PRIMARY KEY (unit_id, qualification_id),
+    FOREIGN KEY (unit_id, tenant_id) REFERENCES unit(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (qualification_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE
+);
+
+CREATE TABLE unit_admin(
+    unit_id text,
+    member_id text,
+    tenant_id text NOT NULL,
+    CONSTRAINT unit_admin_pkey PRIMARY KEY (unit_id, member_id),
+    FOREIGN KEY (unit_id, tenant_id) REFERENCES unit(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE
+);`
+
+	// Versions let updates check nobody else changed a record since it was read, see types.ApiMember.Version
+	addVersionColumnsQuery = `ALTER TABLE member ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE qualification ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE requirement ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE reference ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE duty_position ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE unit ADD COLUMN version integer NOT NULL DEFAULT 1;`
+
+	// addTenantLinksQuery keeps members' supervisors and units and requirements' references in their own tenant. Links
+	// already crossing tenants are dropped. ON DELETE SET NULL would clear tenant_id too, so providers unlink rows
+	// themselves before deleting what they point at.
+	addTenantLinksQuery = `UPDATE member m SET supervisor_id = NULL WHERE supervisor_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM member s WHERE s.id = m.supervisor_id AND s.tenant_id = m.tenant_id);
+UPDATE member m SET unit_id = NULL WHERE unit_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM unit u WHERE u.id = m.unit_id AND u.tenant_id = m.tenant_id);
+UPDATE requirement r SET reference_id = NULL WHERE reference_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM reference re WHERE re.id = r.reference_id AND re.tenant_id = r.tenant_id);
+ALTER TABLE reference ADD UNIQUE (id, tenant_id);
+ALTER TABLE member DROP CONSTRAINT member_supervisor_id_fkey,
+    DROP CONSTRAINT member_unit_id_fkey,
+    ADD FOREIGN KEY (supervisor_id, tenant_id) REFERENCES member(id, tenant_id),
+    ADD FOREIGN KEY (unit_id, tenant_id) REFERENCES unit(id, tenant_id);
+ALTER TABLE requirement DROP CONSTRAINT requirement_reference_id_fkey,
+    ADD FOREIGN KEY (reference_id, tenant_id) REFERENCES reference(id, tenant_id);`
+
+	// addQualificationSourceQuery records which position assigned a member's qualification, so removing the position
+	// leaves qualifications assigned by hand alone. Assignments from before it are treated as assigned by hand.
+	addQualificationSourceQuery = "ALTER TABLE member_qualification ADD COLUMN source_position_id text;"
+
+	// addMemberTransferQuery records the transfer packages imported, so each is only imported once and members coming
+	// back from an instance they were transferred from are recognized by their ID there.
+	addMemberTransferQuery = `CREATE TABLE member_transfer(
+    package_id text,
+    issuer text,
+    issuer_member_id text,
+    member_id text,
+    tenant_id text NOT NULL,
+    CONSTRAINT member_transfer_pkey PRIMARY KEY (package_id, tenant_id),
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE
+);`
+
+	// Every query below is scoped to the provider's tenant through the $tenant parameter, which Provider.Db rewrites to
+	// the parameter after the positional ones and binds on every statement.
+	insertTenantQuery         = "INSERT INTO tenant(id, name, subdomain) VALUES($1, $2, $3);"
+	getTenantQuery            = "SELECT id, name, subdomain FROM tenant WHERE id=$1;"
+	getTenantBySubdomainQuery = "SELECT id, name, subdomain FROM tenant WHERE subdomain=$1;"
+	getTenantsQuery           = "SELECT id, name, subdomain FROM tenant ORDER BY name;"
+
+	memberColumns                 = "id, first_name, last_name, rank, user_name, supervisor_id, admin, role, hash, certificate_id, email, archived, archive_reason, archived_by, unit_id, version"
+	insertMemberQuery             = "INSERT INTO member(id, first_name, last_name, rank, user_name, supervisor_id, admin, role, hash, certificate_id, email, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $tenant);"
+	getMemberQuery                = "SELECT " + memberColumns + " FROM member WHERE id=$1 AND tenant_id=$tenant;"
+	getMemberVersionQuery         = "SELECT version FROM member WHERE id=$1 AND tenant_id=$tenant;"
+	getMemberByUsernameQuery      = "SELECT " + memberColumns + " FROM member where user_name=$1 AND tenant_id=$tenant;"
+	getMemberByCertificateIDQuery = "SELECT " + memberColumns + " FROM member WHERE certificate_id=$1 AND tenant_id=$tenant;"
+	getAllMembersQuery            = "SELECT " + memberColumns + " FROM member WHERE archived IS NULL AND tenant_id=$tenant;"
+	getArchivedMembersQuery       = "SELECT " + memberColumns + " FROM member WHERE archived IS NOT NULL AND tenant_id=$tenant ORDER BY archived;"
+	getSubordinatesQuery          = "SELECT " + memberColumns + " FROM member WHERE supervisor_id=$1 AND archived IS NULL AND tenant_id=$tenant;"
+	updateMemberQuery             = "UPDATE member SET first_name=$1, last_name=$2, rank=$3, supervisor_id=$4, admin=$5, role=$6, hash=$7, certificate_id=$8, email=$9, version=version+1 WHERE ID=$10 AND ($11=0 OR version=$11) AND tenant_id=$tenant;"
+	setMemberSupervisorQuery      = "UPDATE member SET supervisor_id=$1 WHERE id=$2 AND tenant_id=$tenant;"
+	setMemberUnitQuery            = "UPDATE member SET unit_id=$1, version=version+1 WHERE id=$2 AND tenant_id=$tenant;"
+	getUnitMembersQuery           = "SELECT " + memberColumns + " FROM member WHERE unit_id=$1 AND archived IS NULL AND tenant_id=$tenant;"
+	clearSubordinatesQuery        = "UPDATE member SET supervisor_id=NULL WHERE supervisor_id=$1 AND tenant_id=$tenant;"
+	deleteMemberQuery             = "DELETE FROM member WHERE id=$1 AND tenant_id=$tenant;"
+	deleteMemberByUsernameQuery   = "DELETE FROM member WHERE user_name=$1 AND tenant_id=$tenant;"
+	archiveMemberQuery            = "UPDATE member SET archived=$1, archive_reason=$2, archived_by=$3, version=version+1 WHERE id=$4 AND tenant_id=$tenant;"
+	restoreMemberQuery            = "UPDATE member SET archived=NULL, archive_reason=NULL, archived_by=NULL, version=version+1 WHERE id=$1 AND tenant_id=$tenant;"
+	deleteMemberAPITokensQuery    = "DELETE FROM api_token WHERE member_id=$1 AND tenant_id=$tenant;"
+	deleteMemberHistoryQuery      = "DELETE FROM member_qualification_history WHERE member_id=$1 AND tenant_id=$tenant;"
+
+	// Transfers can bring back records a returning member already has, so anything keyed by ID is skipped if present
+	transferMemberQualificationQuery = "INSERT INTO member_qualification(member_id, qualification_id, tenant_id) VALUES($1, $2, $tenant) ON CONFLICT DO NOTHING;"
+	transferCompletionQuery          = "INSERT INTO completion(id, member_id, requirement_id, trainer_id, certifier_id, submitted_by, status, completed_date, submitted, reviewed, comments, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $tenant) ON CONFLICT DO NOTHING;"
+	transferQualificationEventQuery  = "INSERT INTO member_qualification_history(id, member_id, qualification_id, kind, actor_id, time, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $tenant) ON CONFLICT DO NOTHING;"
+	insertMemberTransferQuery        = "INSERT INTO member_transfer(package_id, issuer, issuer_member_id, member_id, tenant_id) VALUES($1, $2, $3, $4, $tenant);"
+	checkMemberTransferQuery         = "SELECT COUNT(*) FROM member_transfer WHERE package_id=$1 AND tenant_id=$tenant;"
+	getTransferredMemberIDQuery      = "SELECT member_id FROM member_transfer WHERE issuer=$1 AND issuer_member_id=$2 AND tenant_id=$tenant LIMIT 1;"
+	getMemberTransferOriginsQuery    = "SELECT issuer, issuer_member_id FROM member_transfer WHERE member_id=$1 AND tenant_id=$tenant;"
+
+	// Requirements already on a qualification are skipped on insert, a failed insert would abort the transaction
+	// updating it
+	qualificationColumns                         = "id, name, notes, expires, expiration_days, version"
+	insertQualificationQuery                     = "INSERT INTO qualification(id, name, notes, expires, expiration_days, tenant_id) VALUES($1, $2, $3, $4, $5, $tenant);"
+	getQualificationQuery                        = "SELECT " + qualificationColumns + " FROM qualification WHERE id=$1 AND tenant_id=$tenant;"
+	getAllQualificationsQuery                    = "SELECT " + qualificationColumns + " FROM qualification WHERE tenant_id=$tenant ORDER BY name;"
+	updateQualificationQuery                     = "UPDATE qualification SET name=$1, notes=$2, expires=$3, expiration_days=$4, version=version+1 WHERE id=$5 AND ($6=0 OR version=$6) AND tenant_id=$tenant;"
+	getQualificationVersionQuery                 = "SELECT version FROM qualification WHERE id=$1 AND tenant_id=$tenant;"
+	deleteQualificationQuery                     = "DELETE FROM qualification WHERE id=$1 AND tenant_id=$tenant;"
+	insertQualificationInitialRequirementQuery   = "INSERT INTO qualification_initial_requirement(qualification_id, requirement_id, tenant_id) VALUES($1, $2, $tenant) ON CONFLICT DO NOTHING;"
+	insertQualificationRecurringRequirementQuery = "INSERT INTO qualification_recurring_requirement(qualification_id, requirement_id, tenant_id) VALUES($1, $2, $tenant) ON CONFLICT DO NOTHING;"
+	getInitialRequirementIdsQuery                = "SELECT requirement_id FROM qualification_initial_requirement WHERE qualification_id=$1 AND tenant_id=$tenant;"
+	getRecurringRequirementIdsQuery              = "SELECT requirement_id FROM qualification_recurring_requirement WHERE qualification_id=$1 AND tenant_id=$tenant;"
+	deleteQualificationRecurringRequirementQuery = "DELETE FROM qualification_recurring_requirement WHERE requirement_id=$1 AND tenant_id=$tenant;"
+	deleteQualificationInitialRequirementQuery   = "DELETE FROM qualification_initial_requirement WHERE requirement_id=$1 AND tenant_id=$tenant;"
+	insertQualificationPrerequisiteQuery         = "INSERT INTO qualification_prerequisite(qualification_id, prerequisite_id, tenant_id) VALUES($1, $2, $tenant);"
+	getPrerequisitesQuery                        = "SELECT qualification_id, prerequisite_id FROM qualification_prerequisite WHERE qualification_id=$1 AND tenant_id=$tenant ORDER BY prerequisite_id;"
+	getAllPrerequisitesQuery                     = "SELECT qualification_id, prerequisite_id FROM qualification_prerequisite WHERE tenant_id=$tenant ORDER BY qualification_id, prerequisite_id;"
+	deleteQualificationPrerequisitesQuery        = "DELETE FROM qualification_prerequisite WHERE qualification_id=$1 AND tenant_id=$tenant;"
+	// Edits that check the prerequisites for cycles hold this until their transaction ends, so they see each other's
+	// changes instead of each passing on its own snapshot
+	lockPrerequisitesQuery = "SELECT pg_advisory_xact_lock(hashtext('qualification_prerequisite:' || $tenant));"
+
+	// Qualifications are read with all of their requirements and prerequisites in one query each, scoped to a single
+	// qualification, every qualification or the ones a member holds. Requirements whose reference was deleted come
+	// back with an empty reference.
+	qualificationRequirementColumns          = "l.qualification_id, " + listedRequirementColumns
+	qualificationRequirementJoin             = " l JOIN requirement r ON r.id = l.requirement_id AND r.tenant_id = l.tenant_id LEFT JOIN reference re ON re.id = r.reference_id AND re.tenant_id = r.tenant_id"
+	memberQualificationIDs                   = "(SELECT qualification_id FROM member_qualification WHERE member_id=$1 AND tenant_id=$tenant)"
+	getInitialRequirementsQuery              = "SELECT " + qualificationRequirementColumns + " FROM qualification_initial_requirement" + qualificationRequirementJoin + " WHERE l.qualification_id=$1 AND l.tenant_id=$tenant ORDER BY r.name;"
+	getRecurringRequirementsQuery            = "SELECT " + qualificationRequirementColumns + " FROM qualification_recurring_requirement" + qualificationRequirementJoin + " WHERE l.qualification_id=$1 AND l.tenant_id=$tenant ORDER BY r.name;"
+	getAllInitialRequirementsQuery           = "SELECT " + qualificationRequirementColumns + " FROM qualification_initial_requirement" + qualificationRequirementJoin + " WHERE l.tenant_id=$tenant ORDER BY r.name;"
+	getAllRecurringRequirementsQuery         = "SELECT " + qualificationRequirementColumns + " FROM qualification_recurring_requirement" + qualificationRequirementJoin + " WHERE l.tenant_id=$tenant ORDER BY r.name;"
+	getMemberQualificationsQuery             = "SELECT " + qualificationColumns + " FROM qualification WHERE id IN " + memberQualificationIDs + " AND tenant_id=$tenant ORDER BY name;"
+	getMemberInitialRequirementsQuery        = "SELECT " + qualificationRequirementColumns + " FROM qualification_initial_requirement" + qualificationRequirementJoin + " WHERE l.qualification_id IN " + memberQualificationIDs + " AND l.tenant_id=$tenant ORDER BY r.name;"
+	getMemberRecurringRequirementsQuery      = "SELECT " + qualificationRequirementColumns + " FROM qualification_recurring_requirement" + qualificationRequirementJoin + " WHERE l.qualification_id IN " + memberQualificationIDs + " AND l.tenant_id=$tenant ORDER BY r.name;"
+	getMemberQualificationPrerequisitesQuery = "SELECT qualification_id, prerequisite_id FROM qualification_prerequisite WHERE qualification_id IN " + memberQualificationIDs + " AND tenant_id=$tenant ORDER BY prerequisite_id;"
+
+	addMemberQualificationQuery            = "INSERT INTO member_qualification(member_id, qualification_id, source_position_id, tenant_id) VALUES($1, $2, $3, $tenant);"
+	checkMemberQualificationQuery          = "SELECT COUNT(*) FROM member_qualification WHERE member_id=$1 AND qualification_id=$2 AND tenant_id=$tenant;"
+	removeMemberQualificationQuery         = "DELETE FROM member_qualification WHERE member_id=$1 AND qualification_ID=$2 AND tenant_id=$tenant;"
+	getMemberPositionQualificationIDsQuery = "SELECT qualification_id FROM member_qualification WHERE member_id=$1 AND source_position_id IS NOT NULL AND tenant_id=$tenant ORDER BY qualification_id;"
+	countMemberQuery                       = "SELECT COUNT(*) FROM member WHERE id=$1 AND tenant_id=$tenant;"
+	countQualificationQuery                = "SELECT COUNT(*) FROM qualification WHERE id=$1 AND tenant_id=$tenant;"
+	insertQualificationEventQuery          = "INSERT INTO member_qualification_history(id, member_id, qualification_id, kind, actor_id, time, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $tenant);"
+	getQualificationHistoryQuery           = "SELECT id, member_id, qualification_id, kind, actor_id, time FROM member_qualification_history WHERE member_id=$1 AND qualification_id=$2 AND tenant_id=$tenant ORDER BY time, seq;"
+	getMemberRequirementsQuery             = "SELECT requirement_id, most_recent_completion FROM member_requirement WHERE member_id=$1 AND tenant_id=$tenant;"
+
+	// requirementColumns and listedRequirementColumns leave the reference empty if it was deleted
+	requirementColumns                   = "r.id, r.name, r.description, r.notes, r.days_valid_for, r.version, COALESCE(r.reference_id, ''), COALESCE(re.id, ''), COALESCE(re.name, ''), COALESCE(re.volume, 0), COALESCE(re.paragraph, ''), COALESCE(re.version, 0)"
+	listedRequirementColumns             = "r.id, r.name, r.description, r.notes, r.days_valid_for, r.version, COALESCE(re.id, ''), COALESCE(re.name, ''), COALESCE(re.volume, 0), COALESCE(re.paragraph, ''), COALESCE(re.version, 0)"
+	addRequirementQuery                  = "INSERT INTO requirement(id, name, description, notes, days_valid_for, reference_id, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $tenant);"
+	getRequirementQuery                  = "SELECT " + requirementColumns + " FROM requirement r LEFT JOIN reference re ON r.reference_id = re.id AND re.tenant_id = r.tenant_id WHERE r.id = $1 AND r.tenant_id=$tenant;"
+	getAllRequirementsQuery              = "SELECT " + requirementColumns + " FROM requirement r LEFT JOIN reference re ON r.reference_id = re.id AND re.tenant_id = r.tenant_id WHERE r.tenant_id=$tenant;"
+	getQualificationsForRequirementQuery = "SELECT qualification_id FROM qualification_initial_requirement  WHERE requirement_id=$1 AND tenant_id=$tenant UNION SELECT qualification_id FROM qualification_recurring_requirement WHERE requirement_id=$1 AND tenant_id=$tenant;"
+	updateRequirementQuery               = "UPDATE requirement SET name=$1, description=$2, notes=$3, days_valid_for=$4, reference_id=$5, version=version+1 WHERE id=$6 AND ($7=0 OR version=$7) AND tenant_id=$tenant;"
+	getRequirementVersionQuery           = "SELECT version FROM requirement WHERE id=$1 AND tenant_id=$tenant;"
+	deleteRequirementQuery               = "DELETE FROM requirement WHERE id=$1 AND tenant_id=$tenant;"
+
+	addReferenceQuery        = "INSERT INTO reference(id, name, volume, paragraph, tenant_id) VALUES($1, $2, $3, $4, $tenant);"
+	getReferenceQuery        = "SELECT id, name, volume, paragraph, version FROM reference WHERE id=$1 AND tenant_id=$tenant;"
+	getReferencesQuery       = "SELECT id, name, volume, paragraph, version FROM reference WHERE tenant_id=$tenant;"
+	getReferenceVersionQuery = "SELECT version FROM reference WHERE id=$1 AND tenant_id=$tenant;"
+	updateReferenceQuery     = "UPDATE reference SET name=$1, volume=$2, paragraph=$3, version=version+1 WHERE id=$4 AND ($5=0 OR version=$5) AND tenant_id=$tenant;"
+	clearReferenceQuery      = "UPDATE requirement SET reference_id=NULL WHERE reference_id=$1 AND tenant_id=$tenant;"
+	deleteReferenceQuery     = "DELETE FROM reference WHERE id=$1 AND tenant_id=$tenant;"
+
+	apiTokenColumns             = "id, member_id, name, scope, hash, created, last_used"
+	insertAPITokenQuery         = "INSERT INTO api_token(id, member_id, name, scope, hash, created, last_used, tenant_id) VALUES($1, $2, $3, $4, $5, $6, NULL, $tenant);"
+	getAPITokenByHashQuery      = "SELECT " + apiTokenColumns + " FROM api_token WHERE hash=$1 AND tenant_id=$tenant;"
+	getAPITokensForMemberQuery  = "SELECT " + apiTokenColumns + " FROM api_token WHERE member_id=$1 AND tenant_id=$tenant ORDER BY created;"
+	updateAPITokenLastUsedQuery = "UPDATE api_token SET last_used=$1 WHERE id=$2 AND tenant_id=$tenant;"
+	deleteAPITokenQuery         = "DELETE FROM api_token WHERE id=$1 AND member_id=$2 AND tenant_id=$tenant;"
+
+	insertRolePermissionQuery  = "INSERT INTO role_permission(role, permission, tenant_id) VALUES($1, $2, $tenant);"
+	getRolePermissionsQuery    = "SELECT permission FROM role_permission WHERE role=$1 AND tenant_id=$tenant ORDER BY permission;"
+	getAllRolePermissionsQuery = "SELECT role, permission FROM role_permission WHERE tenant_id=$tenant ORDER BY role, permission;"
+	deleteRolePermissionsQuery = "DELETE FROM role_permission WHERE role=$1 AND tenant_id=$tenant;"
+
+	completionColumns                      = "id, member_id, requirement_id, trainer_id, certifier_id, submitted_by, status, completed_date, submitted, reviewed, comments"
+	addCertifierQuery                      = "INSERT INTO requirement_certifier(requirement_id, member_id, tenant_id) VALUES($1, $2, $tenant);"
+	getCertifierIDsQuery                   = "SELECT member_id FROM requirement_certifier WHERE requirement_id=$1 AND tenant_id=$tenant;"
+	checkCertifierQuery                    = "SELECT COUNT(*) FROM requirement_certifier WHERE requirement_id=$1 AND member_id=$2 AND tenant_id=$tenant;"
+	removeCertifierQuery                   = "DELETE FROM requirement_certifier WHERE requirement_id=$1 AND member_id=$2 AND tenant_id=$tenant;"
+	insertCompletionQuery                  = "INSERT INTO completion(" + completionColumns + ", tenant_id) VALUES($1, $2, $3, $4, NULL, $5, $6, $7, $8, NULL, $9, $tenant);"
+	getCompletionQuery                     = "SELECT " + completionColumns + " FROM completion WHERE id=$1 AND tenant_id=$tenant;"
+	getMemberCompletionsQuery              = "SELECT " + completionColumns + " FROM completion WHERE member_id=$1 AND tenant_id=$tenant ORDER BY submitted;"
+	getPendingCompletionsForCertifierQuery = "SELECT c.id, c.member_id, c.requirement_id, c.trainer_id, c.certifier_id, c.submitted_by, c.status, c.completed_date, c.submitted, c.reviewed, c.comments FROM completion c JOIN requirement_certifier rc ON c.requirement_id = rc.requirement_id WHERE rc.member_id=$1 AND c.status=$2 AND c.tenant_id=$tenant AND rc.tenant_id=$tenant ORDER BY c.submitted;"
+	reviewCompletionQuery                  = "UPDATE completion SET certifier_id=$1, status=$2, reviewed=$3, comments=$4 WHERE id=$5 AND status=$6 AND tenant_id=$tenant;"
+	upsertMemberRequirementQuery           = "INSERT INTO member_requirement(member_id, requirement_id, initial_completion, most_recent_completion, tenant_id) VALUES($1, $2, $3, $3, $tenant) ON CONFLICT(member_id, requirement_id) DO UPDATE SET most_recent_completion=excluded.most_recent_completion WHERE excluded.most_recent_completion > member_requirement.most_recent_completion;"
+
+	waiverColumns         = "id, member_id, requirement_id, qualification_id, kind, approver_id, reason, start_date, end_date, created, memo_name"
+	insertWaiverQuery     = "INSERT INTO waiver(" + waiverColumns + ", memo_type, memo, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $tenant);"
+	getWaiverQuery        = "SELECT " + waiverColumns + " FROM waiver WHERE id=$1 AND tenant_id=$tenant;"
+	getMemberWaiversQuery = "SELECT " + waiverColumns + " FROM waiver WHERE member_id=$1 AND tenant_id=$tenant ORDER BY start_date;"
+	getWaiverMemoQuery    = "SELECT memo_name, memo_type, memo FROM waiver WHERE id=$1 AND tenant_id=$tenant;"
+	updateWaiverEndQuery  = "UPDATE waiver SET end_date=$1 WHERE id=$2 AND tenant_id=$tenant;"
+	insertAuditEntryQuery = "INSERT INTO audit_log(id, actor_id, action, entity_type, entity_id, time, details, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $7, $tenant);"
+	getAuditEntriesQuery  = "SELECT id, actor_id, action, entity_type, entity_id, time, details FROM audit_log WHERE entity_type=$1 AND entity_id=$2 AND tenant_id=$tenant ORDER BY time;"
+
+	insertDutyPositionQuery               = "INSERT INTO duty_position(id, name, description, tenant_id) VALUES($1, $2, $3, $tenant);"
+	getDutyPositionQuery                  = "SELECT id, name, description, version FROM duty_position WHERE id=$1 AND tenant_id=$tenant;"
+	getDutyPositionVersionQuery           = "SELECT version FROM duty_position WHERE id=$1 AND tenant_id=$tenant;"
+	getDutyPositionsQuery                 = "SELECT id, name, description, version FROM duty_position WHERE tenant_id=$tenant ORDER BY name;"
+	updateDutyPositionQuery               = "UPDATE duty_position SET name=$1, description=$2, version=version+1 WHERE id=$3 AND ($4=0 OR version=$4) AND tenant_id=$tenant;"
+	deleteDutyPositionQuery               = "DELETE FROM duty_position WHERE id=$1 AND tenant_id=$tenant;"
+	insertDutyPositionQualificationQuery  = "INSERT INTO duty_position_qualification(position_id, qualification_id, tenant_id) VALUES($1, $2, $tenant);"
+	getDutyPositionQualificationsQuery    = "SELECT qualification_id FROM duty_position_qualification WHERE position_id=$1 AND tenant_id=$tenant ORDER BY qualification_id;"
+	deleteDutyPositionQualificationsQuery = "DELETE FROM duty_position_qualification WHERE position_id=$1 AND tenant_id=$tenant;"
+	assignMemberDutyPositionQuery         = "INSERT INTO member_duty_position(member_id, position_id, tenant_id) VALUES($1, $2, $tenant);"
+	getMemberDutyPositionIDsQuery         = "SELECT position_id FROM member_duty_position WHERE member_id=$1 AND tenant_id=$tenant;"
+	getDutyPositionMemberIDsQuery         = "SELECT d.member_id FROM member_duty_position d JOIN member m ON m.id = d.member_id AND m.tenant_id = d.tenant_id WHERE d.position_id=$1 AND m.archived IS NULL AND d.tenant_id=$tenant;"
+	removeMemberDutyPositionQuery         = "DELETE FROM member_duty_position WHERE member_id=$1 AND position_id=$2 AND tenant_id=$tenant;"
+
+	insertUnitQuery                        = "INSERT INTO unit(id, name, kind, parent_id, tenant_id) VALUES($1, $2, $3, $4, $tenant);"
+	getUnitQuery                           = "SELECT id, name, kind, parent_id, version FROM unit WHERE id=$1 AND tenant_id=$tenant;"
+	getUnitVersionQuery                    = "SELECT version FROM unit WHERE id=$1 AND tenant_id=$tenant;"
+	getUnitsQuery                          = "SELECT id, name, kind, parent_id, version FROM unit WHERE tenant_id=$tenant ORDER BY name;"
+	updateUnitQuery                        = "UPDATE unit SET name=$1, parent_id=$2, version=version+1 WHERE id=$3 AND ($4=0 OR version=$4) AND tenant_id=$tenant;"
+	clearUnitMembersQuery                  = "UPDATE member SET unit_id=NULL WHERE unit_id=$1 AND tenant_id=$tenant;"
+	deleteUnitQuery                        = "DELETE FROM unit WHERE id=$1 AND tenant_id=$tenant;"
+	insertUnitMandatoryQualificationQuery  = "INSERT INTO unit_mandatory_qualification(unit_id, qualification_id, tenant_id) VALUES($1, $2, $tenant);"
+	getUnitMandatoryQualificationsQuery    = "SELECT qualification_id FROM unit_mandatory_qualification WHERE unit_id=$1 AND tenant_id=$tenant ORDER BY qualification_id;"
+	deleteUnitMandatoryQualificationsQuery = "DELETE FROM unit_mandatory_qualification WHERE unit_id=$1 AND tenant_id=$tenant;"
+	addUnitAdminQuery                      = "INSERT INTO unit_admin(unit_id, member_id, tenant_id) VALUES($1, $2, $tenant);"
+	getUnitAdminIDsQuery                   = "SELECT member_id FROM unit_admin WHERE unit_id=$1 AND tenant_id=$tenant;"
+	getAdministeredUnitIDsQuery            = "SELECT unit_id FROM unit_admin WHERE member_id=$1 AND tenant_id=$tenant;"
+	removeUnitAdminQuery                   = "DELETE FROM unit_admin WHERE unit_id=$1 AND member_id=$2 AND tenant_id=$tenant;"
+
+	insertSessionQuery       = "INSERT INTO session(id, expiration, user_agent, tenant_id) VALUES($1, $2, $3, $tenant);"
+	insertMemberSessionQuery = "INSERT INTO member_session(member_id, session_id, tenant_id) VALUES($1, $2, $tenant);"
+	getSessionQuery          = "SELECT id, expiration, user_agent FROM session WHERE id=$1 AND tenant_id=$tenant;"
+	deleteSessionQuery       = "DELETE FROM session WHERE id=$1 AND tenant_id=$tenant;"
+	getMemberSessionQuery    = "SELECT member_id, session_id FROM member_session WHERE member_id=$1 AND session_id=$2 AND tenant_id=$tenant;"
+
+	importProfileColumns          = "id, name, member_column, requirement_column, date_column, date_format"
+	insertImportProfileQuery      = "INSERT INTO import_profile(" + importProfileColumns + ", tenant_id) VALUES($1, $2, $3, $4, $5, $6, $tenant);"
+	getImportProfileQuery         = "SELECT " + importProfileColumns + " FROM import_profile WHERE id=$1 AND tenant_id=$tenant;"
+	getImportProfilesQuery        = "SELECT " + importProfileColumns + " FROM import_profile WHERE tenant_id=$tenant ORDER BY name;"
+	deleteImportProfileQuery      = "DELETE FROM import_profile WHERE id=$1 AND tenant_id=$tenant;"
+	insertImportBatchQuery        = "INSERT INTO import_batch(id, profile_id, file_name, uploaded_by, uploaded, status, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $tenant);"
+	getImportBatchQuery           = "SELECT id, profile_id, file_name, uploaded_by, uploaded, status FROM import_batch WHERE id=$1 AND tenant_id=$tenant;"
+	updateImportBatchStatusQuery  = "UPDATE import_batch SET status=$1 WHERE id=$2 AND status=$3 AND tenant_id=$tenant;"
+	deleteImportBatchQuery        = "DELETE FROM import_batch WHERE id=$1 AND tenant_id=$tenant;"
+	insertImportRowQuery          = "INSERT INTO import_row(id, batch_id, line, member_identifier, member_id, requirement_name, requirement_id, match_score, completed_date, excluded, errors, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $tenant);"
+	getImportRowsQuery            = "SELECT id, line, member_identifier, member_id, requirement_name, requirement_id, match_score, completed_date, excluded, errors FROM import_row WHERE batch_id=$1 AND tenant_id=$tenant ORDER BY line;"
+	updateImportRowQuery          = "UPDATE import_row SET member_id=$1, requirement_id=$2, match_score=$3, completed_date=$4, excluded=$5, errors=$6 WHERE id=$7 AND batch_id=$8 AND tenant_id=$tenant;"
+	insertReviewedCompletionQuery = "INSERT INTO completion(" + completionColumns + ", tenant_id) VALUES($1, $2, $3, NULL, $4, $5, $6, $7, $8, $9, $10, $tenant);"
+)
@@ -0,0 +1,182 @@
+package postgres
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// registryQueries work on the tenant and migration tables, which aren't owned by any tenant, change the schema, or only
+// take a lock.
+var registryQueries = map[string]bool{
+	"createStructureQuery":           true,
+	"addVersionColumnsQuery":         true,
+	"addTenantLinksQuery":            true,
+	"addQualificationSourceQuery":    true,
+	"addMemberTransferQuery":         true,
+	"createMigrationTableQuery":      true,
+	"lockMigrationsQuery":            true,
+	"getMigrationVersionQuery":       true,
+	"insertMigrationQuery":           true,
+	"savepointQuery":                 true,
+	"rollbackToSavepointQuery":       true,
+	"releaseSavepointQuery":          true,
+	"savepointNestedQuery":           true,
+	"rollbackToSavepointNestedQuery": true,
+	"releaseSavepointNestedQuery":    true,
+	"insertTenantQuery":              true,
+	"getTenantQuery":                 true,
+	"getTenantBySubdomainQuery":      true,
+	"getTenantsQuery":                true,
+	"lockPrerequisitesQuery":         true,
+}
+
+var (
+	createTablePattern = regexp.MustCompile(`(?s)CREATE TABLE (\w+)\((.*?)\);`)
+	tableRefPattern    = regexp.MustCompile(`(?i)\b(?:FROM|JOIN|UPDATE|INTO)\s+(\w+)(?:\s+(\w+))?`)
+	positionalPattern  = regexp.MustCompile(`\$(\d+)`)
+	sqlKeywords        = map[string]bool{"WHERE": true, "SET": true, "ON": true, "ORDER": true, "JOIN": true, "LEFT": true, "UNION": true, "VALUES": true}
+)
+
+// queryConstants evaluates every constant in queries.go ending in Query.
+func queryConstants(t *testing.T) map[string]string {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "queries.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Error parsing queries.go: %s", err.Error())
+	}
+	pkg, err := (&types.Config{}).Check("postgres", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatalf("Error type checking queries.go: %s", err.Error())
+	}
+	queries := map[string]string{}
+	for _, name := range pkg.Scope().Names() {
+		c, ok := pkg.Scope().Lookup(name).(*types.Const)
+		if ok && strings.HasSuffix(name, "Query") {
+			queries[name] = constant.StringVal(c.Val())
+		}
+	}
+	if len(queries) == 0 {
+		t.Fatalf("Found no queries in queries.go")
+	}
+	return queries
+}
+
+// positionalCount returns the highest positional parameter in the query, failing if any below it are never used since
+// Postgres can't work out their types.
+func positionalCount(t *testing.T, name, query string) int {
+	t.Helper()
+	used := map[int]bool{}
+	highest := 0
+	for _, match := range positionalPattern.FindAllStringSubmatch(query, -1) {
+		n, _ := strconv.Atoi(match[1])
+		used[n] = true
+		highest = max(highest, n)
+	}
+	for n := 1; n <= highest; n++ {
+		if !used[n] {
+			t.Errorf("%s skips parameter $%d", name, n)
+		}
+	}
+	return highest
+}
+
+// TestTablesHaveTenant checks every table besides the tenant registry belongs to a tenant.
+func TestTablesHaveTenant(t *testing.T) {
+	tables := createTablePattern.FindAllStringSubmatch(createStructureQuery, -1)
+	if len(tables) == 0 {
+		t.Fatalf("Found no tables in createStructureQuery")
+	}
+	for _, table := range tables {
+		if table[1] == "tenant" {
+			continue
+		}
+		if !strings.Contains(table[2], "tenant_id text NOT NULL") {
+			t.Errorf("Table %s has no tenant_id column", table[1])
+		}
+	}
+}
+
+// TestQueriesScopedToTenant checks every query touching tenant owned tables filters or stamps every row with the
+// tenant, so no query can read or change another tenant's rows.
+func TestQueriesScopedToTenant(t *testing.T) {
+	for name, query := range queryConstants(t) {
+		if registryQueries[name] {
+			continue
+		}
+		// Each half of a UNION selects on its own
+		for _, part := range strings.Split(query, " UNION ") {
+			if !strings.Contains(part, "$tenant") {
+				t.Errorf("%s doesn't bind $tenant in: %s", name, part)
+				continue
+			}
+			for _, ref := range tableRefPattern.FindAllStringSubmatch(part, -1) {
+				// ON CONFLICT ... DO UPDATE SET only ever updates the row being inserted
+				alias := ref[2]
+				if alias == "" || sqlKeywords[strings.ToUpper(ref[1])] || sqlKeywords[strings.ToUpper(alias)] {
+					continue
+				}
+				if !strings.Contains(part, alias+".tenant_id") {
+					t.Errorf("%s doesn't scope %s %s to the tenant", name, ref[1], alias)
+				}
+			}
+			if strings.HasPrefix(part, "INSERT") && !strings.Contains(part, "tenant_id)") {
+				t.Errorf("%s doesn't insert tenant_id", name)
+			} else if !strings.HasPrefix(part, "INSERT") && !strings.Contains(part, "tenant_id=$tenant") {
+				t.Errorf("%s doesn't filter on tenant_id=$tenant", name)
+			}
+		}
+	}
+}
+
+// TestQueryArguments checks every call passing a query from queries.go passes exactly as many arguments as the query
+// has positional parameters. $tenant is bound to the parameter after the last argument, so a mismatch would bind the
+// tenant to the wrong parameter or fail outright.
+func TestQueryArguments(t *testing.T) {
+	queries := queryConstants(t)
+	counts := map[string]int{}
+	for name, query := range queries {
+		counts[name] = positionalCount(t, name, query)
+	}
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("Error parsing package: %s", err.Error())
+	}
+	calls := 0
+	for _, file := range pkgs["postgres"].Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || call.Ellipsis.IsValid() {
+				return true
+			}
+			for i, arg := range call.Args {
+				ident, ok := arg.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				if want, ok := counts[ident.Name]; ok {
+					calls++
+					if got := len(call.Args) - i - 1; got != want {
+						t.Errorf("%s: %s takes %d arguments, got %d", fset.Position(call.Pos()), ident.Name, want, got)
+					}
+					break
+				}
+			}
+			return true
+		})
+	}
+	if calls == 0 {
+		t.Fatalf("Found no calls passing queries")
+	}
+}
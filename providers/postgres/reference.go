@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+)
+
+func (p Provider) AddReference(ctx context.Context, r types.Reference) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding reference to database", slog.Any("reference", r))
+	_, err := p.Db.ExecContext(ctx, addReferenceQuery, r.ID, r.Name, r.Volume, r.Paragraph)
+	if uniqueViolation(err, "reference_name_key") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Reference with that name already exists")
+		return backend.ErrDuplicateReference
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting reference into database: %s", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) GetReference(ctx context.Context, id string) (types.Reference, error) {
+	row := p.Db.QueryRowContext(ctx, getReferenceQuery, id)
+	var ref types.Reference
+	err := row.Scan(&ref.ID, &ref.Name, &ref.Volume, &ref.Paragraph, &ref.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Unable to find reference with given ID")
+		return types.Reference{}, backend.ErrReferenceNotFound
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting reference from database", slog.String("error", err.Error()))
+		return types.Reference{}, err
+	}
+	return ref, nil
+}
+
+func (p Provider) GetReferences(ctx context.Context) ([]types.Reference, error) {
+	rows, err := p.Db.QueryContext(ctx, getReferencesQuery)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting references from database", slog.String("error", err.Error()))
+		return nil, err
+	}
+	var refs []types.Reference
+	var ref types.Reference
+	for rows.Next() {
+		err = rows.Scan(&ref.ID, &ref.Name, &ref.Volume, &ref.Paragraph, &ref.Version)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning reference into struct", slog.String("error", err.Error()))
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+func (p Provider) UpdateReference(ctx context.Context, r types.Reference) error {
+	res, err := p.Db.ExecContext(ctx, updateReferenceQuery, r.Name, r.Volume, r.Paragraph, r.ID, r.Version)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error updating reference", slog.String("error", err.Error()))
+		return err
+	}
+	// Updating a reference that doesn't exist isn't an error, but one changed since it was read is
+	if updated, _ := res.RowsAffected(); updated == 0 {
+		return p.missedUpdate(ctx, p.Db, r.Version, nil, getReferenceVersionQuery, r.ID)
+	}
+	return nil
+}
+
+// DeleteReference removes a reference, leaving the requirements citing it without one.
+func (p Provider) DeleteReference(ctx context.Context, id string) error {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for DeleteReference", slog.String("error", err.Error()))
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, clearReferenceQuery, id); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error removing reference from requirements", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	res, err := tx.ExecContext(ctx, deleteReferenceQuery, id)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error deleting reference from database", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if updated, _ := res.RowsAffected(); updated != 1 {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Didn't get expected 1 row updated, qualification mostly not found")
+		tx.Rollback()
+		return backend.ErrReferenceNotFound
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
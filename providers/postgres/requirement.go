@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+func (p Provider) AddRequirement(ctx context.Context, r types.Requirement) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding requirement to database", slog.Any("requirement", r))
+	_, err := p.Db.ExecContext(ctx, addRequirementQuery, r.ID, r.Name, r.Description, r.Notes, r.DaysValidFor, r.Reference.ID)
+	if uniqueViolation(err, "requirement_name_key") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Requirement with given name already exists")
+		return backend.ErrDuplicateRequirement
+	}
+	if foreignKeyViolation(err) {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Provided reference doesn't exist", slog.String("reference_id", r.Reference.ID))
+		return fmt.Errorf("%w: %s", backend.ErrReferenceNotFound, r.Reference.ID)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting requirement into database", slog.String("error", err.Error()))
+		return err
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Mapping reference to requirement")
+	return nil
+}
+
+func (p Provider) GetRequirement(ctx context.Context, id string) (types.Requirement, error) {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Getting requirement from database", slog.String("requirement_id", id))
+	row := p.Db.QueryRowContext(ctx, getRequirementQuery, id)
+	r := types.Requirement{}
+	unUsedRefId := ""
+	err := row.Scan(&r.ID, &r.Name, &r.Description, &r.Notes, &r.DaysValidFor, &r.Version, &unUsedRefId, &r.Reference.ID, &r.Reference.Name, &r.Reference.Volume, &r.Reference.Paragraph, &r.Reference.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "No results found for requirement with given id")
+		return types.Requirement{}, fmt.Errorf("%w: requirement_id=%s", backend.ErrRequirementNotFound, id)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning requirement into struct", slog.String("error", err.Error()))
+		return types.Requirement{}, err
+	}
+	return r, nil
+}
+
+func (p Provider) GetAllRequirements(ctx context.Context) ([]types.Requirement, error) {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Getting all requirements from database")
+	rows, err := p.Db.QueryContext(ctx, getAllRequirementsQuery)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting all requirements from database: %s", slog.String("error", err.Error()))
+		return nil, err
+	}
+	var reqs []types.Requirement
+	var r types.Requirement
+	var unUsedRefId string
+	for rows.Next() {
+		err := rows.Scan(&r.ID, &r.Name, &r.Description, &r.Notes, &r.DaysValidFor, &r.Version, &unUsedRefId, &r.Reference.ID, &r.Reference.Name, &r.Reference.Volume, &r.Reference.Paragraph, &r.Reference.Version)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning requirement into struct", slog.String("error", err.Error()))
+			continue
+		}
+		reqs = append(reqs, r)
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Found %d requirements", len(reqs)))
+	return reqs, nil
+}
+
+func (p Provider) ListRequirements(ctx context.Context, opts types.ListOptions) (types.Page[types.Requirement], error) {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Listing requirements from database")
+	after, err := decodeCursor(opts, types.Requirement{})
+	if err != nil {
+		return types.Page[types.Requirement]{}, err
+	}
+	q := listQuery{from: "requirement r LEFT JOIN reference re ON r.reference_id = re.id AND re.tenant_id = r.tenant_id", tenant: "r.tenant_id"}
+	total, err := p.countRows(ctx, q)
+	if err != nil {
+		return types.Page[types.Requirement]{}, err
+	}
+	query, args := q.page(listedRequirementColumns, []string{"r.name", "r.id"}, opts, after)
+	rows, err := p.Db.QueryContext(ctx, query, args...)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error listing requirements from database", slog.String("error", err.Error()))
+		return types.Page[types.Requirement]{}, err
+	}
+	defer rows.Close()
+	var reqs []types.Requirement
+	for rows.Next() {
+		var r types.Requirement
+		err := rows.Scan(&r.ID, &r.Name, &r.Description, &r.Notes, &r.DaysValidFor, &r.Version, &r.Reference.ID, &r.Reference.Name, &r.Reference.Volume, &r.Reference.Paragraph, &r.Reference.Version)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning requirement into struct", slog.String("error", err.Error()))
+			return types.Page[types.Requirement]{}, err
+		}
+		reqs = append(reqs, r)
+	}
+	return types.NewPage(reqs, total, opts), nil
+}
+
+func (p Provider) GetQualificationIDsForRequirement(ctx context.Context, requirementID string) ([]string, error) {
+	rows, err := p.Db.QueryContext(ctx, getQualificationsForRequirementQuery, requirementID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting qualifications for requirement", slog.String("error", err.Error()))
+		return nil, err
+	}
+	var ids []string
+	var id string
+	for rows.Next() {
+		err = rows.Scan(&id)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning id into string", slog.String("error", err.Error()))
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (p Provider) UpdateRequirement(ctx context.Context, r types.Requirement) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Updating requirement", slog.Any("new_requirement", r))
+	res, err := p.Db.ExecContext(ctx, updateRequirementQuery, r.Name, r.Description, r.Notes, r.DaysValidFor, r.Reference.ID, r.ID, r.Version)
+	if foreignKeyViolation(err) {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Provided reference doesn't exist")
+		return backend.ErrReferenceNotFound
+	}
+	if uniqueViolation(err, "requirement_name_key") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Requirement with given name already exists")
+		return backend.ErrDuplicateRequirement
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error updating requirement in database", slog.String("error", err.Error()))
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Expected 1 row to be updated but didn't get that")
+		return p.missedUpdate(ctx, p.Db, r.Version, fmt.Errorf("%w: requirement_id=%s", backend.ErrRequirementNotFound, r.ID), getRequirementVersionQuery, r.ID)
+	}
+	return nil
+}
+
+func (p Provider) DeleteRequirement(ctx context.Context, id string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting requirement from database", slog.String("requirement_id", id))
+	res, err := p.Db.ExecContext(ctx, deleteRequirementQuery, id)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error deleting requirement from database", slog.String("error", err.Error()))
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, fmt.Sprintf("Expected 1 row to be updated, but got %d", count))
+		if count == 0 {
+			return backend.ErrRequirementNotFound
+		}
+	}
+	return nil
+}
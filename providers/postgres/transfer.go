@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// ImportTransfer writes a transferred member and their records in a single transaction. A returning member's archived
+// record is updated and restored instead of inserting a new one.
+func (p Provider) ImportTransfer(ctx context.Context, t types.TransferImport) error {
+	l := p.logger.With(slog.String("member_id", t.Member.ID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Importing transferred member", slog.Bool("returning", t.Returning))
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Error creating transaction for ImportTransfer", slog.String("error", err.Error()))
+		return err
+	}
+	if err = p.transferMember(ctx, tx, t); err != nil {
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.ExecContext(ctx, insertMemberTransferQuery, t.PackageID, t.Issuer, t.IssuerMemberID, t.Member.ID)
+	if uniqueViolation(err, "member_transfer_pkey") {
+		tx.Rollback()
+		return fmt.Errorf("%w: package_id=%s", backend.ErrTransferAlreadyImported, t.PackageID)
+	} else if err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Error recording transfer package", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	for _, id := range t.QualificationIDs {
+		if _, err = tx.ExecContext(ctx, transferMemberQualificationQuery, t.Member.ID, id); err != nil {
+			l.LogAttrs(ctx, slog.LevelError, "Error assigning transferred qualification", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, c := range t.Completions {
+		_, err = tx.ExecContext(ctx, transferCompletionQuery, c.ID, t.Member.ID, c.RequirementID, nullString(c.TrainerID), nullString(c.CertifierID),
+			c.SubmittedBy, c.Status, c.CompletedDate, c.Submitted, nullTime(c.Reviewed), c.Comments)
+		if err != nil {
+			l.LogAttrs(ctx, slog.LevelError, "Error inserting transferred completion", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, upsertMemberRequirementQuery, t.Member.ID, c.RequirementID, c.CompletedDate); err != nil {
+			l.LogAttrs(ctx, slog.LevelError, "Error recording member requirement completion", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, e := range t.History {
+		if _, err = tx.ExecContext(ctx, transferQualificationEventQuery, e.ID, t.Member.ID, e.QualificationID, e.Kind, nullString(e.ActorID), e.Time); err != nil {
+			l.LogAttrs(ctx, slog.LevelError, "Error inserting transferred qualification history", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) transferMember(ctx context.Context, tx tenantTx, t types.TransferImport) error {
+	m := t.Member
+	if t.Returning {
+		if _, err := tx.ExecContext(ctx, updateMemberQuery, m.FirstName, m.LastName, m.Rank, nullString(m.SupervisorID), m.Admin, m.Role, m.Hash,
+			nullString(m.CertificateID), nullString(m.Email), m.ID, 0); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error updating returning member", slog.String("error", err.Error()))
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, restoreMemberQuery, m.ID); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error restoring returning member", slog.String("error", err.Error()))
+			return err
+		}
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, insertMemberQuery, m.ID, m.FirstName, m.LastName, m.Rank, m.Username, nil, m.Admin, m.Role, m.Hash, nullString(m.CertificateID), nullString(m.Email))
+	if uniqueViolation(err, "member_user_name_key") {
+		return fmt.Errorf("%w: %s", backend.ErrDuplicateUsername, m.Username)
+	} else if uniqueViolation(err, "member_certificate_id_key") {
+		return fmt.Errorf("%w: %s", backend.ErrDuplicateCertificate, m.CertificateID)
+	} else if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting transferred member", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) IsTransferImported(ctx context.Context, packageID string) (bool, error) {
+	var count int
+	if err := p.Db.QueryRowContext(ctx, checkMemberTransferQuery, packageID).Scan(&count); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error checking for imported transfer package", slog.String("error", err.Error()))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetTransferredMemberID returns the ID here of the member a package from issuer brought in as issuerMemberID.
+func (p Provider) GetTransferredMemberID(ctx context.Context, issuer, issuerMemberID string) (string, error) {
+	var id string
+	err := p.Db.QueryRowContext(ctx, getTransferredMemberIDQuery, issuer, issuerMemberID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: issuer=%s issuer_member_id=%s", backend.ErrMemberNotFound, issuer, issuerMemberID)
+	} else if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting transferred member", slog.String("error", err.Error()))
+		return "", err
+	}
+	return id, nil
+}
+
+// GetMemberTransferOrigins returns the member's IDs at the instances they were transferred here from, by instance name.
+func (p Provider) GetMemberTransferOrigins(ctx context.Context, memberID string) (map[string]string, error) {
+	rows, err := p.Db.QueryContext(ctx, getMemberTransferOriginsQuery, memberID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting member transfer origins", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	origins := map[string]string{}
+	var issuer, issuerMemberID string
+	for rows.Next() {
+		if err = rows.Scan(&issuer, &issuerMemberID); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning member transfer origin", slog.String("error", err.Error()))
+			return nil, err
+		}
+		origins[issuer] = issuerMemberID
+	}
+	return origins, nil
+}
@@ -0,0 +1,255 @@
+package postgres
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+func (p Provider) AddUnit(ctx context.Context, u types.Unit) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding unit to database", slog.String("unit_id", u.ID), slog.String("name", u.Name))
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for AddUnit", slog.String("error", err.Error()))
+		return err
+	}
+	_, err = tx.ExecContext(ctx, insertUnitQuery, u.ID, u.Name, u.Kind, nullString(u.ParentID))
+	if foreignKeyViolation(err) {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Parent unit not found", slog.String("parent_id", u.ParentID))
+		tx.Rollback()
+		return fmt.Errorf("%w: parent unit %s not found", backend.ErrInvalidUnit, u.ParentID)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting unit", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if err = p.insertUnitMandatoryQualifications(ctx, tx, u); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func scanUnit(s scanner) (types.Unit, error) {
+	var u types.Unit
+	var parentID sql.NullString
+	if err := s.Scan(&u.ID, &u.Name, &u.Kind, &parentID, &u.Version); err != nil {
+		return types.Unit{}, err
+	}
+	u.ParentID = parentID.String
+	return u, nil
+}
+
+func (p Provider) GetUnit(ctx context.Context, id string) (types.Unit, error) {
+	u, err := scanUnit(p.Db.QueryRowContext(ctx, getUnitQuery, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "No unit found with given id", slog.String("unit_id", id))
+		return types.Unit{}, fmt.Errorf("%w: unit_id=%s", backend.ErrUnitNotFound, id)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning unit into struct", slog.String("error", err.Error()))
+		return types.Unit{}, err
+	}
+	if u.MandatoryQualifications, err = p.queryIDs(ctx, getUnitMandatoryQualificationsQuery, id); err != nil {
+		return types.Unit{}, err
+	}
+	return u, nil
+}
+
+func (p Provider) GetUnits(ctx context.Context) ([]types.Unit, error) {
+	rows, err := p.Db.QueryContext(ctx, getUnitsQuery)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting units", slog.String("error", err.Error()))
+		return nil, err
+	}
+	units := []types.Unit{}
+	for rows.Next() {
+		u, err := scanUnit(rows)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning unit into struct", slog.String("error", err.Error()))
+			rows.Close()
+			return nil, err
+		}
+		units = append(units, u)
+	}
+	rows.Close()
+	for i := range units {
+		if units[i].MandatoryQualifications, err = p.queryIDs(ctx, getUnitMandatoryQualificationsQuery, units[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return units, nil
+}
+
+// UpdateUnit replaces the unit's name, parent and mandatory qualifications. A unit's kind never changes.
+func (p Provider) UpdateUnit(ctx context.Context, u types.Unit) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Updating unit", slog.String("unit_id", u.ID))
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for UpdateUnit", slog.String("error", err.Error()))
+		return err
+	}
+	res, err := tx.ExecContext(ctx, updateUnitQuery, u.Name, nullString(u.ParentID), u.ID, u.Version)
+	if foreignKeyViolation(err) {
+		tx.Rollback()
+		return fmt.Errorf("%w: parent unit %s not found", backend.ErrInvalidUnit, u.ParentID)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error updating unit", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		err = p.missedUpdate(ctx, tx, u.Version, fmt.Errorf("%w: unit_id=%s", backend.ErrUnitNotFound, u.ID), getUnitVersionQuery, u.ID)
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, deleteUnitMandatoryQualificationsQuery, u.ID); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error removing unit mandatory qualifications", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if err = p.insertUnitMandatoryQualifications(ctx, tx, u); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// DeleteUnit removes a unit without subunits. Its members are left without a unit.
+func (p Provider) DeleteUnit(ctx context.Context, id string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting unit", slog.String("unit_id", id))
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for DeleteUnit", slog.String("error", err.Error()))
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, clearUnitMembersQuery, id); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error removing members from unit", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	res, err := tx.ExecContext(ctx, deleteUnitQuery, id)
+	if foreignKeyViolation(err) {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Unit still has subunits", slog.String("unit_id", id))
+		tx.Rollback()
+		return fmt.Errorf("%w: unit_id=%s", backend.ErrUnitHasSubunits, id)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error deleting unit", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		tx.Rollback()
+		return fmt.Errorf("%w: unit_id=%s", backend.ErrUnitNotFound, id)
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// SetMemberUnit moves a member into a unit, or out of every unit when unitID is empty.
+func (p Provider) SetMemberUnit(ctx context.Context, memberID, unitID string) error {
+	res, err := p.Db.ExecContext(ctx, setMemberUnitQuery, nullString(unitID), memberID)
+	if foreignKeyViolation(err) {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Unit for member not found", slog.String("unit_id", unitID))
+		return fmt.Errorf("%w: unit_id=%s", backend.ErrUnitNotFound, unitID)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error setting member unit", slog.String("error", err.Error()))
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		return fmt.Errorf("%w: member_id=%s", backend.ErrMemberNotFound, memberID)
+	}
+	return nil
+}
+
+// GetUnitMembers returns the active members directly in the unit, not those in its subunits.
+func (p Provider) GetUnitMembers(ctx context.Context, unitID string) ([]types.Member, error) {
+	rows, err := p.Db.QueryContext(ctx, getUnitMembersQuery, unitID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting unit members", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	members := []types.Member{}
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning unit member into struct", slog.String("error", err.Error()))
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+func (p Provider) AddUnitAdmin(ctx context.Context, unitID, memberID string) error {
+	_, err := p.Db.ExecContext(ctx, addUnitAdminQuery, unitID, memberID)
+	if uniqueViolation(err, "unit_admin_pkey") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Member is already an admin of unit")
+		return fmt.Errorf("%w: unit_id=%s member_id=%s", backend.ErrUnitAdminAlreadyDesignated, unitID, memberID)
+	} else if foreignKeyViolation(err) {
+		if _, err = p.GetMember(ctx, memberID, backend.ById); err != nil {
+			return err
+		}
+		_, err = p.GetUnit(ctx, unitID)
+		return err
+	} else if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error adding unit admin", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) GetUnitAdminIDs(ctx context.Context, unitID string) ([]string, error) {
+	return p.queryIDs(ctx, getUnitAdminIDsQuery, unitID)
+}
+
+func (p Provider) GetAdministeredUnitIDs(ctx context.Context, memberID string) ([]string, error) {
+	return p.queryIDs(ctx, getAdministeredUnitIDsQuery, memberID)
+}
+
+func (p Provider) RemoveUnitAdmin(ctx context.Context, unitID, memberID string) error {
+	res, err := p.Db.ExecContext(ctx, removeUnitAdminQuery, unitID, memberID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error removing unit admin", slog.String("error", err.Error()))
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		return fmt.Errorf("%w: unit_id=%s member_id=%s", backend.ErrUnitAdminNotFound, unitID, memberID)
+	}
+	return nil
+}
+
+func (p Provider) insertUnitMandatoryQualifications(ctx context.Context, tx tenantTx, u types.Unit) error {
+	for _, qualificationID := range u.MandatoryQualifications {
+		_, err := tx.ExecContext(ctx, insertUnitMandatoryQualificationQuery, u.ID, qualificationID)
+		if foreignKeyViolation(err) {
+			p.logger.LogAttrs(ctx, slog.LevelWarn, "Mandatory qualification for unit not found", slog.String("qualification_id", qualificationID))
+			return fmt.Errorf("%w: %s", backend.ErrQualificationNotFound, qualificationID)
+		}
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error adding mandatory qualification to unit", slog.String("error", err.Error()))
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,1250 @@
+// Package providertest is the conformance suite every storage provider has to pass. The backend only looks at the
+// sentinel errors in backend/errors.go and relies on providers to enforce uniqueness and clean up after deletes, so
+// providers that pass can be swapped for one another without the backend noticing.
+package providertest
+
+import (
+	"PORTal/backend"
+	"PORTal/testutils"
+	"PORTal/types"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Provider is everything a storage provider implements.
+type Provider interface {
+	backend.MemberProvider
+	backend.QualificationProvider
+	backend.RequirementProvider
+	backend.TenantProvider
+}
+
+// Run runs the suite against providers from newProvider, which must return an empty provider each time it's called.
+func Run(t *testing.T, newProvider func(t *testing.T) Provider) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, p Provider)
+	}{
+		{"Members", testMembers},
+		{"AddMembersRollsBack", testAddMembersRollsBack},
+		{"Transactions", testTransactions},
+		{"DeleteMemberCascades", testDeleteMemberCascades},
+		{"Archive", testArchive},
+		{"MemberQualifications", testMemberQualifications},
+		{"AddQualificationRollsBack", testAddQualificationRollsBack},
+		{"QualificationOrder", testQualificationOrder},
+		{"ListMembers", testListMembers},
+		{"ListQualifications", testListQualifications},
+		{"DeleteQualificationCascades", testDeleteQualificationCascades},
+		{"Requirements", testRequirements},
+		{"DeleteRequirementCascades", testDeleteRequirementCascades},
+		{"Completions", testCompletions},
+		{"DutyPositions", testDutyPositions},
+		{"Units", testUnits},
+		{"APITokens", testAPITokens},
+		{"Waivers", testWaivers},
+		{"Imports", testImports},
+		{"Transfers", testTransfers},
+		{"Roles", testRoles},
+		{"Tenants", testTenants},
+		{"CrossTenantLinks", testCrossTenantLinks},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newProvider(t))
+		})
+	}
+}
+
+// now is truncated to the second since not every provider keeps more precision than that.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+func addMember(t *testing.T, p backend.MemberProvider) types.Member {
+	t.Helper()
+	ctx := context.Background()
+	m := testutils.RandomMember(false)
+	m.ID = uuid.NewString()
+	m.Password = ""
+	m.Hash = testutils.RandomString()
+	if err := p.AddMember(ctx, m); err != nil {
+		t.Fatalf("Error adding member: %s", err.Error())
+	}
+	return m
+}
+
+func addQualification(t *testing.T, p Provider) types.Qualification {
+	t.Helper()
+	ctx := context.Background()
+	q := testutils.RandomQualification()
+	q.ID = uuid.NewString()
+	if err := p.AddQualification(ctx, q); err != nil {
+		t.Fatalf("Error adding qualification: %s", err.Error())
+	}
+	return q
+}
+
+func addRequirement(t *testing.T, p backend.RequirementProvider) types.Requirement {
+	t.Helper()
+	ctx := context.Background()
+	ref := testutils.RandomReference()
+	ref.ID = uuid.NewString()
+	if err := p.AddReference(ctx, ref); err != nil {
+		t.Fatalf("Error adding reference: %s", err.Error())
+	}
+	r := testutils.RandomRequirement(ref)
+	if err := p.AddRequirement(ctx, r); err != nil {
+		t.Fatalf("Error adding requirement: %s", err.Error())
+	}
+	return r
+}
+
+func addCompletion(t *testing.T, p Provider, memberID, requirementID string, completed time.Time) types.Completion {
+	t.Helper()
+	ctx := context.Background()
+	c := types.Completion{
+		ID:            uuid.NewString(),
+		MemberID:      memberID,
+		RequirementID: requirementID,
+		SubmittedBy:   memberID,
+		Status:        types.CompletionPending,
+		CompletedDate: completed,
+		Submitted:     now(),
+	}
+	if err := p.AddCompletion(ctx, c); err != nil {
+		t.Fatalf("Error adding completion: %s", err.Error())
+	}
+	return c
+}
+
+// expectErr fails the test unless err is target.
+func expectErr(t *testing.T, action string, err, target error) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Errorf("Expected %q when %s, got: %v", target, action, err)
+	}
+}
+
+func testMembers(t *testing.T, p Provider) {
+	ctx := context.Background()
+	supervisor := addMember(t, p)
+	m := testutils.RandomMember(false)
+	m.ID = uuid.NewString()
+	m.Password = ""
+	m.SupervisorID = supervisor.ID
+	m.CertificateID = testutils.RandomString()
+	if err := p.AddMember(ctx, m); err != nil {
+		t.Fatalf("Error adding supervised member: %s", err.Error())
+	}
+	for method, identifier := range map[backend.ProviderMethod]string{backend.ById: m.ID, backend.ByUsername: m.Username, backend.ByCertificateID: m.CertificateID} {
+		got, err := p.GetMember(ctx, identifier, method)
+		if err != nil || got.ID != m.ID || got.SupervisorID != supervisor.ID {
+			t.Errorf("Expected member %s from %s, got: %+v, %v", m.ID, identifier, got, err)
+		}
+	}
+	_, err := p.GetMember(ctx, uuid.NewString(), backend.ById)
+	expectErr(t, "getting a member that doesn't exist", err, backend.ErrMemberNotFound)
+	subordinates, err := p.GetSubordinates(ctx, supervisor.ID)
+	if err != nil || len(subordinates) != 1 || subordinates[0].ID != m.ID {
+		t.Errorf("Expected %s to be the only subordinate, got: %+v, %v", m.ID, subordinates, err)
+	}
+
+	duplicate := testutils.RandomMember(false)
+	duplicate.ID = uuid.NewString()
+	duplicate.Username = m.Username
+	expectErr(t, "adding a member with a taken username", p.AddMember(ctx, duplicate), backend.ErrDuplicateUsername)
+	duplicate.Username = testutils.RandomString()
+	duplicate.CertificateID = m.CertificateID
+	expectErr(t, "adding a member with a bound certificate", p.AddMember(ctx, duplicate), backend.ErrDuplicateCertificate)
+	duplicate.CertificateID = ""
+	duplicate.SupervisorID = uuid.NewString()
+	expectErr(t, "adding a member with a missing supervisor", p.AddMember(ctx, duplicate), backend.ErrSupervisorNotFound)
+	if _, err = p.GetMember(ctx, duplicate.ID, backend.ById); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected rejected member not to be stored, got: %v", err)
+	}
+
+	other := addMember(t, p)
+	other.CertificateID = m.CertificateID
+	expectErr(t, "updating a member to a bound certificate", p.UpdateMember(ctx, other), backend.ErrDuplicateCertificate)
+	other.CertificateID = ""
+	other.SupervisorID = uuid.NewString()
+	expectErr(t, "updating a member to a missing supervisor", p.UpdateMember(ctx, other), backend.ErrSupervisorNotFound)
+	missing := testutils.RandomMember(false)
+	missing.ID = uuid.NewString()
+	expectErr(t, "updating a member that doesn't exist", p.UpdateMember(ctx, missing), backend.ErrMemberNotFound)
+
+	other.SupervisorID = supervisor.ID
+	other.FirstName = testutils.RandomString()
+	if err = p.UpdateMember(ctx, other); err != nil {
+		t.Fatalf("Error updating member: %s", err.Error())
+	}
+	got, err := p.GetMember(ctx, other.ID, backend.ById)
+	if err != nil || got.FirstName != other.FirstName || got.SupervisorID != supervisor.ID {
+		t.Errorf("Expected updated member %+v, got: %+v, %v", other, got, err)
+	}
+}
+
+func testAddMembersRollsBack(t *testing.T, p Provider) {
+	ctx := context.Background()
+	existing := addMember(t, p)
+	first := testutils.RandomMember(false)
+	first.ID = uuid.NewString()
+	second := testutils.RandomMember(false)
+	second.ID = uuid.NewString()
+	second.Username = existing.Username
+	if err := p.AddMembers(ctx, []types.Member{first, second}); err == nil {
+		t.Fatal("Expected an error adding members with a taken username")
+	}
+	if _, err := p.GetMember(ctx, first.ID, backend.ById); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected no members to be added when one fails, got: %v", err)
+	}
+
+	second.Username = testutils.RandomString()
+	first.SupervisorID = second.ID
+	if err := p.AddMembers(ctx, []types.Member{first, second}); err != nil {
+		t.Fatalf("Error adding members supervised within the batch: %s", err.Error())
+	}
+	if got, err := p.GetMember(ctx, first.ID, backend.ById); err != nil || got.SupervisorID != second.ID {
+		t.Errorf("Expected %s to be supervised by %s, got: %+v, %v", first.ID, second.ID, got, err)
+	}
+}
+
+// testTransactions checks WithTx keeps everything done through it only if it succeeds, and that a transaction within
+// another only undoes its own changes.
+func testTransactions(t *testing.T, p Provider) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+	var kept, discarded types.Member
+	err := p.WithTx(ctx, func(tx backend.Providers) error {
+		kept = addMember(t, tx.Members)
+		err := tx.Members.WithTx(ctx, func(tx backend.Providers) error {
+			discarded = addMember(t, tx.Members)
+			return errAbort
+		})
+		expectErr(t, "a nested transaction fails", err, errAbort)
+		_, err = tx.Members.GetMember(ctx, discarded.ID, backend.ById)
+		expectErr(t, "getting a member added in a failed nested transaction", err, backend.ErrMemberNotFound)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Error committing transaction: %s", err.Error())
+	}
+	if _, err = p.GetMember(ctx, kept.ID, backend.ById); err != nil {
+		t.Errorf("Expected member added in a committed transaction to be kept, got: %s", err.Error())
+	}
+	_, err = p.GetMember(ctx, discarded.ID, backend.ById)
+	expectErr(t, "getting a member added in a failed nested transaction", err, backend.ErrMemberNotFound)
+
+	var member types.Member
+	q := testutils.RandomQualification()
+	q.ID = uuid.NewString()
+	err = p.WithTx(ctx, func(tx backend.Providers) error {
+		member = addMember(t, tx.Members)
+		if err := tx.Qualifications.AddQualification(ctx, q); err != nil {
+			return err
+		}
+		if err := tx.Members.AssignMemberQualification(ctx, member.ID, q.ID); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	expectErr(t, "a transaction fails", err, errAbort)
+	_, err = p.GetMember(ctx, member.ID, backend.ById)
+	expectErr(t, "getting a member added in a failed transaction", err, backend.ErrMemberNotFound)
+	_, err = p.GetQualification(ctx, q.ID)
+	expectErr(t, "getting a qualification added in a failed transaction", err, backend.ErrQualificationNotFound)
+}
+
+func testDeleteMemberCascades(t *testing.T, p Provider) {
+	ctx := context.Background()
+	supervisor := addMember(t, p)
+	m := addMember(t, p)
+	subordinate := addMember(t, p)
+	subordinate.SupervisorID = m.ID
+	if err := p.UpdateMember(ctx, subordinate); err != nil {
+		t.Fatalf("Error setting supervisor: %s", err.Error())
+	}
+	q := addQualification(t, p)
+	r := addRequirement(t, p)
+	if err := p.AssignMemberQualification(ctx, m.ID, q.ID); err != nil {
+		t.Fatalf("Error assigning qualification: %s", err.Error())
+	}
+	if err := p.AddCertifier(ctx, r.ID, m.ID); err != nil {
+		t.Fatalf("Error designating certifier: %s", err.Error())
+	}
+	c := addCompletion(t, p, m.ID, r.ID, now())
+	trained := addCompletion(t, p, supervisor.ID, r.ID, now())
+	token := types.APIToken{ID: uuid.NewString(), MemberID: m.ID, Name: "ci", Scope: types.ScopeReadOnly, Hash: uuid.NewString(), Created: now()}
+	if err := p.AddAPIToken(ctx, token); err != nil {
+		t.Fatalf("Error adding api token: %s", err.Error())
+	}
+
+	if err := p.DeleteMember(ctx, m.ID, backend.ById); err != nil {
+		t.Fatalf("Error deleting member: %s", err.Error())
+	}
+	expectErr(t, "deleting a member twice", p.DeleteMember(ctx, m.ID, backend.ById), backend.ErrMemberNotFound)
+	if got, err := p.GetMember(ctx, subordinate.ID, backend.ById); err != nil || got.SupervisorID != "" {
+		t.Errorf("Expected subordinate to be left without a supervisor, got: %+v, %v", got, err)
+	}
+	if quals, err := p.GetMemberQualifications(ctx, m.ID); err != nil || len(quals) != 0 {
+		t.Errorf("Expected deleted member's qualifications to be removed, got: %+v, %v", quals, err)
+	}
+	if certifier, err := p.IsCertifier(ctx, r.ID, m.ID); err != nil || certifier {
+		t.Errorf("Expected deleted member to no longer be a certifier, got: %t, %v", certifier, err)
+	}
+	_, err := p.GetCompletion(ctx, c.ID)
+	expectErr(t, "getting a deleted member's completion", err, backend.ErrCompletionNotFound)
+	if _, err = p.GetCompletion(ctx, trained.ID); err != nil {
+		t.Errorf("Expected other members' completions to be kept, got: %v", err)
+	}
+	_, err = p.GetAPITokenByHash(ctx, token.Hash)
+	expectErr(t, "getting a deleted member's api token", err, backend.ErrAPITokenNotFound)
+}
+
+func testArchive(t *testing.T, p Provider) {
+	ctx := context.Background()
+	admin := addMember(t, p)
+	m := addMember(t, p)
+	token := types.APIToken{ID: uuid.NewString(), MemberID: m.ID, Name: "ci", Scope: types.ScopeReadOnly, Hash: uuid.NewString(), Created: now()}
+	if err := p.AddAPIToken(ctx, token); err != nil {
+		t.Fatalf("Error adding api token: %s", err.Error())
+	}
+	archive := types.MemberArchive{Reason: testutils.RandomString(), Date: now(), ArchivedBy: admin.ID}
+	expectErr(t, "archiving a member that doesn't exist", p.ArchiveMember(ctx, uuid.NewString(), archive), backend.ErrMemberNotFound)
+	if err := p.ArchiveMember(ctx, m.ID, archive); err != nil {
+		t.Fatalf("Error archiving member: %s", err.Error())
+	}
+	got, err := p.GetMember(ctx, m.ID, backend.ById)
+	if err != nil || got.Archive == nil || got.Archive.Reason != archive.Reason || !got.Archive.Date.Equal(archive.Date) || got.Archive.ArchivedBy != admin.ID {
+		t.Errorf("Expected member to be archived with %+v, got: %+v, %v", archive, got.Archive, err)
+	}
+	archived, err := p.GetArchivedMembers(ctx)
+	if err != nil || len(archived) != 1 || archived[0].ID != m.ID {
+		t.Errorf("Expected %s to be the only archived member, got: %+v, %v", m.ID, archived, err)
+	}
+	_, err = p.GetAPITokenByHash(ctx, token.Hash)
+	expectErr(t, "getting an archived member's api token", err, backend.ErrAPITokenNotFound)
+
+	expectErr(t, "restoring a member that doesn't exist", p.RestoreMember(ctx, uuid.NewString()), backend.ErrMemberNotFound)
+	if err = p.RestoreMember(ctx, m.ID); err != nil {
+		t.Fatalf("Error restoring member: %s", err.Error())
+	}
+	if got, err = p.GetMember(ctx, m.ID, backend.ById); err != nil || got.Archive != nil {
+		t.Errorf("Expected restored member not to be archived, got: %+v, %v", got.Archive, err)
+	}
+	if archived, err = p.GetArchivedMembers(ctx); err != nil || archived == nil || len(archived) != 0 {
+		t.Errorf("Expected no archived members, got: %+v, %v", archived, err)
+	}
+}
+
+func testMemberQualifications(t *testing.T, p Provider) {
+	ctx := context.Background()
+	m := addMember(t, p)
+	q := addQualification(t, p)
+	expectErr(t, "assigning to a missing member", p.AssignMemberQualification(ctx, uuid.NewString(), q.ID), backend.ErrMemberNotFound)
+	expectErr(t, "assigning a missing qualification", p.AssignMemberQualification(ctx, m.ID, uuid.NewString()), backend.ErrQualificationNotFound)
+	_, err := p.GetMemberQualification(ctx, m.ID, q.ID)
+	expectErr(t, "getting an unassigned qualification", err, backend.ErrMemberQualificationNotFound)
+	expectErr(t, "removing an unassigned qualification", p.RemoveMemberQualification(ctx, m.ID, q.ID), backend.ErrMemberQualificationNotFound)
+	if err = p.AssignMemberQualification(ctx, m.ID, q.ID); err != nil {
+		t.Fatalf("Error assigning qualification: %s", err.Error())
+	}
+	expectErr(t, "assigning a qualification twice", p.AssignMemberQualification(ctx, m.ID, q.ID), backend.ErrQualificationAlreadyAssigned)
+	if got, err := p.GetMemberQualification(ctx, m.ID, q.ID); err != nil || got.ID != q.ID {
+		t.Errorf("Expected member to hold %s, got: %+v, %v", q.ID, got, err)
+	}
+
+	other := addQualification(t, p)
+	errs, err := p.AssignMemberQualifications(ctx, []types.MemberQualificationPair{
+		{MemberID: m.ID, QualificationID: other.ID},
+		{MemberID: m.ID, QualificationID: q.ID},
+		{MemberID: m.ID, QualificationID: uuid.NewString()},
+		{MemberID: uuid.NewString(), QualificationID: q.ID},
+	})
+	if err != nil || len(errs) != 4 {
+		t.Fatalf("Expected an error per pair, got: %v, %v", errs, err)
+	}
+	if errs[0] != nil {
+		t.Errorf("Expected first pair to be assigned, got: %v", errs[0])
+	}
+	expectErr(t, "bulk assigning a qualification twice", errs[1], backend.ErrQualificationAlreadyAssigned)
+	expectErr(t, "bulk assigning a missing qualification", errs[2], backend.ErrQualificationNotFound)
+	expectErr(t, "bulk assigning to a missing member", errs[3], backend.ErrMemberNotFound)
+	errs, err = p.RemoveMemberQualifications(ctx, []types.MemberQualificationPair{
+		{MemberID: m.ID, QualificationID: other.ID},
+		{MemberID: m.ID, QualificationID: other.ID},
+	})
+	if err != nil || len(errs) != 2 || errs[0] != nil {
+		t.Fatalf("Expected first pair to be removed, got: %v, %v", errs, err)
+	}
+	expectErr(t, "bulk removing a qualification twice", errs[1], backend.ErrMemberQualificationNotFound)
+	if quals, err := p.GetMemberQualifications(ctx, m.ID); err != nil || len(quals) != 1 || quals[0].ID != q.ID {
+		t.Errorf("Expected member to only hold %s, got: %+v, %v", q.ID, quals, err)
+	}
+}
+
+// testAddQualificationRollsBack checks a qualification that can't be linked to everything it refers to isn't left
+// half-written.
+func testAddQualificationRollsBack(t *testing.T, p Provider) {
+	ctx := context.Background()
+	r := addRequirement(t, p)
+	q := testutils.RandomQualification()
+	q.ID = uuid.NewString()
+	q.InitialRequirements = []types.Requirement{r}
+	q.Prerequisites = []string{uuid.NewString()}
+	err := p.AddQualification(ctx, q)
+	expectErr(t, "adding a qualification with a missing prerequisite", err, backend.ErrQualificationNotFound)
+	_, err = p.GetQualification(ctx, q.ID)
+	expectErr(t, "getting a qualification that failed to add", err, backend.ErrQualificationNotFound)
+	if ids, err := p.GetQualificationIDsForRequirement(ctx, r.ID); err != nil || len(ids) != 0 {
+		t.Errorf("Expected no qualifications to be linked to the requirement, got: %v, %v", ids, err)
+	}
+}
+
+// testQualificationOrder checks qualifications and the requirements in them are listed by name, whatever order they
+// were added in. Names are lowercase so every database collates them the same way.
+func testQualificationOrder(t *testing.T, p Provider) {
+	ctx := context.Background()
+	m := addMember(t, p)
+	var reqs []types.Requirement
+	for _, name := range []string{"charlie", "alpha", "bravo"} {
+		r := addRequirement(t, p)
+		r.Name = name
+		if err := p.UpdateRequirement(ctx, r); err != nil {
+			t.Fatalf("Error renaming requirement: %s", err.Error())
+		}
+		reqs = append(reqs, r)
+	}
+	for _, name := range []string{"zulu", "xray", "yankee"} {
+		q := testutils.RandomQualification()
+		q.ID = uuid.NewString()
+		q.Name = name
+		q.InitialRequirements = reqs
+		if err := p.AddQualification(ctx, q); err != nil {
+			t.Fatalf("Error adding qualification: %s", err.Error())
+		}
+		if err := p.AssignMemberQualification(ctx, m.ID, q.ID); err != nil {
+			t.Fatalf("Error assigning qualification: %s", err.Error())
+		}
+	}
+	all, err := p.GetAllQualifications(ctx)
+	if err != nil {
+		t.Fatalf("Error getting qualifications: %s", err.Error())
+	}
+	held, err := p.GetMemberQualifications(ctx, m.ID)
+	if err != nil {
+		t.Fatalf("Error getting member qualifications: %s", err.Error())
+	}
+	for _, quals := range [][]types.Qualification{all, held} {
+		var names []string
+		for _, q := range quals {
+			names = append(names, q.Name)
+			var reqNames []string
+			for _, r := range q.InitialRequirements {
+				reqNames = append(reqNames, r.Name)
+			}
+			if !slices.Equal(reqNames, []string{"alpha", "bravo", "charlie"}) {
+				t.Errorf("Expected requirements of %s in name order, got: %v", q.Name, reqNames)
+			}
+		}
+		if !slices.Equal(names, []string{"xray", "yankee", "zulu"}) {
+			t.Errorf("Expected qualifications in name order, got: %v", names)
+		}
+	}
+}
+
+// listAll follows the cursors from the first page to the last, checking every page has the total.
+func listAll[T any](t *testing.T, total int, list func(cursor string) (types.Page[T], error)) []T {
+	t.Helper()
+	var items []T
+	cursor := ""
+	for {
+		page, err := list(cursor)
+		if err != nil {
+			t.Fatalf("Error listing page after %q: %s", cursor, err.Error())
+		}
+		if page.Total != total {
+			t.Errorf("Expected a total of %d, got: %d", total, page.Total)
+		}
+		items = append(items, page.Items...)
+		if page.NextCursor == "" {
+			return items
+		}
+		if len(items) > total {
+			t.Fatalf("Listed %d items past the total of %d", len(items), total)
+		}
+		cursor = page.NextCursor
+	}
+}
+
+func testListMembers(t *testing.T, p Provider) {
+	ctx := context.Background()
+	add := func(lastName string, rank types.Rank, admin bool, supervisorID string) types.Member {
+		t.Helper()
+		m := testutils.RandomMember(false)
+		m.ID = uuid.NewString()
+		m.Password = ""
+		m.Hash = testutils.RandomString()
+		m.LastName, m.Rank, m.Admin, m.SupervisorID = lastName, rank, admin, supervisorID
+		if err := p.AddMember(ctx, m); err != nil {
+			t.Fatalf("Error adding member: %s", err.Error())
+		}
+		return m
+	}
+	adams := add("Adams", types.E9, false, "")
+	smith := add("Smith", types.E5, false, adams.ID)
+	jones := add("Jones", types.E1, false, adams.ID)
+	brown := add("Brown", types.E5, true, "")
+	archived := add("Allen", types.E3, false, "")
+	if err := p.ArchiveMember(ctx, archived.ID, types.MemberArchive{Reason: testutils.RandomString(), Date: now(), ArchivedBy: adams.ID}); err != nil {
+		t.Fatalf("Error archiving member: %s", err.Error())
+	}
+	q := addQualification(t, p)
+	if err := p.AssignMemberQualification(ctx, smith.ID, q.ID); err != nil {
+		t.Fatalf("Error assigning qualification: %s", err.Error())
+	}
+	admin := true
+	tests := []struct {
+		name string
+		opts types.MemberListOptions
+		want []types.Member
+	}{
+		{"ByRank", types.MemberListOptions{ListOptions: types.ListOptions{Sort: types.SortByRank}}, []types.Member{jones, brown, smith, adams}},
+		{"ByLastNameDescending", types.MemberListOptions{ListOptions: types.ListOptions{Sort: types.SortByLastName, Descending: true}}, []types.Member{smith, jones, brown, adams}},
+		{"Rank", types.MemberListOptions{Rank: types.E5}, []types.Member{brown, smith}},
+		{"Supervisor", types.MemberListOptions{SupervisorID: adams.ID}, []types.Member{jones, smith}},
+		{"Admin", types.MemberListOptions{Admin: &admin}, []types.Member{brown}},
+		{"Qualification", types.MemberListOptions{QualificationID: q.ID}, []types.Member{smith}},
+		{"Archived", types.MemberListOptions{Archived: types.MemberArchived}, []types.Member{archived}},
+		{"AnyArchive", types.MemberListOptions{Archived: types.MemberAnyArchive}, []types.Member{adams, archived, brown, jones, smith}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.opts.Sort == "" {
+				tt.opts.Sort = types.SortByLastName
+			}
+			if tt.opts.Archived == "" {
+				tt.opts.Archived = types.MemberActive
+			}
+			tt.opts.Limit = 2
+			got := listAll(t, len(tt.want), func(cursor string) (types.Page[types.Member], error) {
+				tt.opts.Cursor = cursor
+				return p.ListMembers(ctx, tt.opts)
+			})
+			var gotNames, wantNames []string
+			for _, m := range got {
+				gotNames = append(gotNames, m.LastName)
+			}
+			for _, m := range tt.want {
+				wantNames = append(wantNames, m.LastName)
+			}
+			if !slices.Equal(gotNames, wantNames) {
+				t.Errorf("Expected %v, got: %v", wantNames, gotNames)
+			}
+		})
+	}
+
+	_, err := p.ListMembers(ctx, types.MemberListOptions{ListOptions: types.ListOptions{Sort: types.SortByLastName, Limit: 2, Cursor: "not a cursor"}, Archived: types.MemberActive})
+	expectErr(t, "listing members after a malformed cursor", err, backend.ErrInvalidListOptions)
+	cursor := types.EncodeCursor(smith.SortValues(types.SortByLastName))
+	_, err = p.ListMembers(ctx, types.MemberListOptions{ListOptions: types.ListOptions{Sort: types.SortByRank, Limit: 2, Cursor: cursor}, Archived: types.MemberActive})
+	expectErr(t, "listing members by rank after a last name cursor", err, backend.ErrInvalidListOptions)
+}
+
+func testListQualifications(t *testing.T, p Provider) {
+	ctx := context.Background()
+	for _, name := range []string{"charlie", "alpha", "bravo"} {
+		r := addRequirement(t, p)
+		r.Name = name
+		if err := p.UpdateRequirement(ctx, r); err != nil {
+			t.Fatalf("Error renaming requirement: %s", err.Error())
+		}
+		q := testutils.RandomQualification()
+		q.ID = uuid.NewString()
+		q.Name = name
+		q.InitialRequirements = []types.Requirement{r}
+		if err := p.AddQualification(ctx, q); err != nil {
+			t.Fatalf("Error adding qualification: %s", err.Error())
+		}
+	}
+	for _, descending := range []bool{false, true} {
+		want := []string{"alpha", "bravo", "charlie"}
+		if descending {
+			slices.Reverse(want)
+		}
+		quals := listAll(t, 3, func(cursor string) (types.Page[types.Qualification], error) {
+			return p.ListQualifications(ctx, types.ListOptions{Sort: types.SortByName, Descending: descending, Limit: 2, Cursor: cursor})
+		})
+		var names []string
+		for _, q := range quals {
+			names = append(names, q.Name)
+			if len(q.InitialRequirements) != 1 || q.InitialRequirements[0].Name != q.Name {
+				t.Errorf("Expected %s to keep its requirement, got: %+v", q.Name, q.InitialRequirements)
+			}
+		}
+		if !slices.Equal(names, want) {
+			t.Errorf("Expected qualifications %v, got: %v", want, names)
+		}
+		reqs := listAll(t, 3, func(cursor string) (types.Page[types.Requirement], error) {
+			return p.ListRequirements(ctx, types.ListOptions{Sort: types.SortByName, Descending: descending, Limit: 2, Cursor: cursor})
+		})
+		names = nil
+		for _, r := range reqs {
+			names = append(names, r.Name)
+			if r.Reference.ID == "" {
+				t.Errorf("Expected %s to keep its reference", r.Name)
+			}
+		}
+		if !slices.Equal(names, want) {
+			t.Errorf("Expected requirements %v, got: %v", want, names)
+		}
+	}
+	_, err := p.ListQualifications(ctx, types.ListOptions{Sort: types.SortByLastName, Limit: 2})
+	expectErr(t, "listing qualifications by last name", err, backend.ErrInvalidListOptions)
+}
+
+func testDeleteQualificationCascades(t *testing.T, p Provider) {
+	ctx := context.Background()
+	m := addMember(t, p)
+	q := addQualification(t, p)
+	dependent := testutils.RandomQualification()
+	dependent.ID = uuid.NewString()
+	dependent.Prerequisites = []string{q.ID}
+	if err := p.AddQualification(ctx, dependent); err != nil {
+		t.Fatalf("Error adding qualification with prerequisite: %s", err.Error())
+	}
+	if err := p.AssignMemberQualification(ctx, m.ID, q.ID); err != nil {
+		t.Fatalf("Error assigning qualification: %s", err.Error())
+	}
+	position := types.DutyPosition{ID: uuid.NewString(), Name: testutils.RandomString(), Qualifications: []string{q.ID}}
+	if err := p.AddDutyPosition(ctx, position); err != nil {
+		t.Fatalf("Error adding duty position: %s", err.Error())
+	}
+
+	if err := p.DeleteQualification(ctx, q.ID); err != nil {
+		t.Fatalf("Error deleting qualification: %s", err.Error())
+	}
+	expectErr(t, "deleting a qualification twice", p.DeleteQualification(ctx, q.ID), backend.ErrQualificationNotFound)
+	_, err := p.GetQualification(ctx, q.ID)
+	expectErr(t, "getting a deleted qualification", err, backend.ErrQualificationNotFound)
+	if quals, err := p.GetMemberQualifications(ctx, m.ID); err != nil || len(quals) != 0 {
+		t.Errorf("Expected deleted qualification to be removed from members, got: %+v, %v", quals, err)
+	}
+	if got, err := p.GetQualification(ctx, dependent.ID); err != nil || len(got.Prerequisites) != 0 {
+		t.Errorf("Expected deleted qualification to be removed from prerequisites, got: %+v, %v", got.Prerequisites, err)
+	}
+	if got, err := p.GetDutyPosition(ctx, position.ID); err != nil || len(got.Qualifications) != 0 {
+		t.Errorf("Expected deleted qualification to be removed from duty positions, got: %+v, %v", got.Qualifications, err)
+	}
+	graph, err := p.GetPrerequisiteGraph(ctx)
+	if err != nil || len(graph) != 0 {
+		t.Errorf("Expected an empty prerequisite graph, got: %v, %v", graph, err)
+	}
+}
+
+func testRequirements(t *testing.T, p Provider) {
+	ctx := context.Background()
+	r := addRequirement(t, p)
+	ref := testutils.RandomReference()
+	ref.ID = uuid.NewString()
+	ref.Name = r.Reference.Name
+	expectErr(t, "adding a reference with a taken name", p.AddReference(ctx, ref), backend.ErrDuplicateReference)
+	_, err := p.GetReference(ctx, uuid.NewString())
+	expectErr(t, "getting a missing reference", err, backend.ErrReferenceNotFound)
+	expectErr(t, "deleting a missing reference", p.DeleteReference(ctx, uuid.NewString()), backend.ErrReferenceNotFound)
+
+	duplicate := testutils.RandomRequirement(r.Reference)
+	duplicate.Name = r.Name
+	expectErr(t, "adding a requirement with a taken name", p.AddRequirement(ctx, duplicate), backend.ErrDuplicateRequirement)
+	missingRef := testutils.RandomRequirement(types.Reference{ID: uuid.NewString()})
+	expectErr(t, "adding a requirement with a missing reference", p.AddRequirement(ctx, missingRef), backend.ErrReferenceNotFound)
+	_, err = p.GetRequirement(ctx, uuid.NewString())
+	expectErr(t, "getting a missing requirement", err, backend.ErrRequirementNotFound)
+	expectErr(t, "updating a missing requirement", p.UpdateRequirement(ctx, testutils.RandomRequirement(r.Reference)), backend.ErrRequirementNotFound)
+	expectErr(t, "deleting a missing requirement", p.DeleteRequirement(ctx, uuid.NewString()), backend.ErrRequirementNotFound)
+
+	other := addRequirement(t, p)
+	other.Name = r.Name
+	expectErr(t, "renaming a requirement to a taken name", p.UpdateRequirement(ctx, other), backend.ErrDuplicateRequirement)
+	other.Name = testutils.RandomString()
+	other.Reference = r.Reference
+	if err = p.UpdateRequirement(ctx, other); err != nil {
+		t.Fatalf("Error updating requirement: %s", err.Error())
+	}
+	got, err := p.GetRequirement(ctx, other.ID)
+	if err != nil || !testutils.CompareRequirements(got, other) {
+		t.Errorf("Expected requirement %+v, got: %+v, %v", other, got, err)
+	}
+	reqs, err := p.GetAllRequirements(ctx)
+	if err != nil || len(reqs) != 2 {
+		t.Errorf("Expected 2 requirements, got: %+v, %v", reqs, err)
+	}
+}
+
+func testDeleteRequirementCascades(t *testing.T, p Provider) {
+	ctx := context.Background()
+	m := addMember(t, p)
+	certifier := addMember(t, p)
+	r := addRequirement(t, p)
+	kept := addRequirement(t, p)
+	q := testutils.RandomQualification()
+	q.ID = uuid.NewString()
+	q.InitialRequirements = []types.Requirement{r, kept}
+	if err := p.AddQualification(ctx, q); err != nil {
+		t.Fatalf("Error adding qualification: %s", err.Error())
+	}
+	if ids, err := p.GetQualificationIDsForRequirement(ctx, r.ID); err != nil || !slices.Equal(ids, []string{q.ID}) {
+		t.Errorf("Expected %s to be required by %s, got: %v, %v", r.ID, q.ID, ids, err)
+	}
+	if err := p.AddCertifier(ctx, r.ID, certifier.ID); err != nil {
+		t.Fatalf("Error designating certifier: %s", err.Error())
+	}
+	c := addCompletion(t, p, m.ID, r.ID, now())
+
+	if err := p.DeleteRequirement(ctx, r.ID); err != nil {
+		t.Fatalf("Error deleting requirement: %s", err.Error())
+	}
+	got, err := p.GetQualification(ctx, q.ID)
+	if err != nil || len(got.InitialRequirements) != 1 || got.InitialRequirements[0].ID != kept.ID {
+		t.Errorf("Expected %s to only require %s, got: %+v, %v", q.ID, kept.ID, got.InitialRequirements, err)
+	}
+	if ids, err := p.GetCertifierIDs(ctx, r.ID); err != nil || len(ids) != 0 {
+		t.Errorf("Expected deleted requirement to have no certifiers, got: %v, %v", ids, err)
+	}
+	_, err = p.GetCompletion(ctx, c.ID)
+	expectErr(t, "getting a deleted requirement's completion", err, backend.ErrCompletionNotFound)
+	if ids, err := p.GetQualificationIDsForRequirement(ctx, r.ID); err != nil || len(ids) != 0 {
+		t.Errorf("Expected deleted requirement not to be required by anything, got: %v, %v", ids, err)
+	}
+}
+
+func testCompletions(t *testing.T, p Provider) {
+	ctx := context.Background()
+	m := addMember(t, p)
+	certifier := addMember(t, p)
+	r := addRequirement(t, p)
+	expectErr(t, "designating a missing certifier", p.AddCertifier(ctx, r.ID, uuid.NewString()), backend.ErrMemberNotFound)
+	expectErr(t, "designating a certifier for a missing requirement", p.AddCertifier(ctx, uuid.NewString(), certifier.ID), backend.ErrRequirementNotFound)
+	expectErr(t, "removing a certifier that isn't one", p.RemoveCertifier(ctx, r.ID, certifier.ID), backend.ErrCertifierNotFound)
+	if err := p.AddCertifier(ctx, r.ID, certifier.ID); err != nil {
+		t.Fatalf("Error designating certifier: %s", err.Error())
+	}
+	expectErr(t, "designating a certifier twice", p.AddCertifier(ctx, r.ID, certifier.ID), backend.ErrCertifierAlreadyDesignated)
+	_, err := p.GetCompletion(ctx, uuid.NewString())
+	expectErr(t, "getting a missing completion", err, backend.ErrCompletionNotFound)
+
+	recent := now().Add(-24 * time.Hour)
+	older := addCompletion(t, p, m.ID, r.ID, recent.Add(-30*24*time.Hour))
+	newer := addCompletion(t, p, m.ID, r.ID, recent)
+	pending, err := p.GetPendingCompletions(ctx, certifier.ID)
+	if err != nil || len(pending) != 2 {
+		t.Fatalf("Expected 2 pending completions, got: %+v, %v", pending, err)
+	}
+	for _, c := range []types.Completion{newer, older} {
+		c.CertifierID = certifier.ID
+		c.Status = types.CompletionApproved
+		c.Reviewed = now()
+		if err = p.ReviewCompletion(ctx, c); err != nil {
+			t.Fatalf("Error reviewing completion: %s", err.Error())
+		}
+	}
+	newer.Status = types.CompletionRejected
+	expectErr(t, "reviewing a completion twice", p.ReviewCompletion(ctx, newer), backend.ErrCompletionAlreadyReviewed)
+	if got, err := p.GetCompletion(ctx, newer.ID); err != nil || got.Status != types.CompletionApproved || got.CertifierID != certifier.ID {
+		t.Errorf("Expected completion to stay approved by %s, got: %+v, %v", certifier.ID, got, err)
+	}
+	reqs, err := p.GetMemberRequirements(ctx, m.ID)
+	if err != nil || len(reqs) != 1 || !reqs[0].CompletedDate.Equal(recent) {
+		t.Errorf("Expected requirement to be dated by the most recent completion %s, got: %+v, %v", recent, reqs, err)
+	}
+	if pending, err = p.GetPendingCompletions(ctx, certifier.ID); err != nil || pending == nil || len(pending) != 0 {
+		t.Errorf("Expected no pending completions, got: %+v, %v", pending, err)
+	}
+}
+
+func testDutyPositions(t *testing.T, p Provider) {
+	ctx := context.Background()
+	m := addMember(t, p)
+	q := addQualification(t, p)
+	position := types.DutyPosition{ID: uuid.NewString(), Name: testutils.RandomString(), Qualifications: []string{q.ID}}
+	if err := p.AddDutyPosition(ctx, position); err != nil {
+		t.Fatalf("Error adding duty position: %s", err.Error())
+	}
+	duplicate := types.DutyPosition{ID: uuid.NewString(), Name: position.Name}
+	expectErr(t, "adding a duty position with a taken name", p.AddDutyPosition(ctx, duplicate), backend.ErrDuplicateDutyPosition)
+	missingQual := types.DutyPosition{ID: uuid.NewString(), Name: testutils.RandomString(), Qualifications: []string{uuid.NewString()}}
+	expectErr(t, "adding a duty position with a missing qualification", p.AddDutyPosition(ctx, missingQual), backend.ErrQualificationNotFound)
+	_, err := p.GetDutyPosition(ctx, missingQual.ID)
+	expectErr(t, "getting a duty position that failed to add", err, backend.ErrDutyPositionNotFound)
+	expectErr(t, "updating a missing duty position", p.UpdateDutyPosition(ctx, missingQual), backend.ErrDutyPositionNotFound)
+
+	expectErr(t, "assigning a missing duty position", p.AssignMemberDutyPosition(ctx, m.ID, uuid.NewString()), backend.ErrDutyPositionNotFound)
+	expectErr(t, "assigning a duty position to a missing member", p.AssignMemberDutyPosition(ctx, uuid.NewString(), position.ID), backend.ErrMemberNotFound)
+	expectErr(t, "removing an unassigned duty position", p.RemoveMemberDutyPosition(ctx, m.ID, position.ID), backend.ErrMemberDutyPositionNotFound)
+	if err = p.AssignMemberDutyPosition(ctx, m.ID, position.ID); err != nil {
+		t.Fatalf("Error assigning duty position: %s", err.Error())
+	}
+	expectErr(t, "assigning a duty position twice", p.AssignMemberDutyPosition(ctx, m.ID, position.ID), backend.ErrDutyPositionAlreadyAssigned)
+	if ids, err := p.GetDutyPositionMemberIDs(ctx, position.ID); err != nil || !slices.Equal(ids, []string{m.ID}) {
+		t.Errorf("Expected %s to hold the duty position, got: %v, %v", m.ID, ids, err)
+	}
+	archived := addMember(t, p)
+	if err = p.AssignMemberDutyPosition(ctx, archived.ID, position.ID); err != nil {
+		t.Fatalf("Error assigning duty position: %s", err.Error())
+	}
+	if err = p.ArchiveMember(ctx, archived.ID, types.MemberArchive{Reason: testutils.RandomString(), Date: now()}); err != nil {
+		t.Fatalf("Error archiving member: %s", err.Error())
+	}
+	if ids, err := p.GetDutyPositionMemberIDs(ctx, position.ID); err != nil || !slices.Equal(ids, []string{m.ID}) {
+		t.Errorf("Expected archived members to be left out of the duty position's members, got: %v, %v", ids, err)
+	}
+
+	handAssigned := addQualification(t, p)
+	if err = p.AssignMemberQualification(ctx, m.ID, handAssigned.ID); err != nil {
+		t.Fatalf("Error assigning qualification: %s", err.Error())
+	}
+	expectErr(t, "assigning a held qualification from a duty position", p.AssignMemberPositionQualification(ctx, m.ID, handAssigned.ID, position.ID), backend.ErrQualificationAlreadyAssigned)
+	if err = p.AssignMemberPositionQualification(ctx, m.ID, q.ID, position.ID); err != nil {
+		t.Fatalf("Error assigning qualification from duty position: %s", err.Error())
+	}
+	if ids, err := p.GetMemberPositionQualificationIDs(ctx, m.ID); err != nil || !slices.Equal(ids, []string{q.ID}) {
+		t.Errorf("Expected only %s to be assigned by a duty position, got: %v, %v", q.ID, ids, err)
+	}
+	if err = p.RemoveMemberQualification(ctx, m.ID, q.ID); err != nil {
+		t.Fatalf("Error removing qualification: %s", err.Error())
+	}
+	if err = p.AssignMemberQualification(ctx, m.ID, q.ID); err != nil {
+		t.Fatalf("Error assigning qualification: %s", err.Error())
+	}
+	if ids, err := p.GetMemberPositionQualificationIDs(ctx, m.ID); err != nil || ids == nil || len(ids) != 0 {
+		t.Errorf("Expected reassigning by hand to drop the duty position, got: %v, %v", ids, err)
+	}
+
+	if err = p.DeleteDutyPosition(ctx, position.ID); err != nil {
+		t.Fatalf("Error deleting duty position: %s", err.Error())
+	}
+	expectErr(t, "deleting a duty position twice", p.DeleteDutyPosition(ctx, position.ID), backend.ErrDutyPositionNotFound)
+	if ids, err := p.GetMemberDutyPositionIDs(ctx, m.ID); err != nil || len(ids) != 0 {
+		t.Errorf("Expected deleted duty position to be removed from members, got: %v, %v", ids, err)
+	}
+	if positions, err := p.GetDutyPositions(ctx); err != nil || positions == nil || len(positions) != 0 {
+		t.Errorf("Expected no duty positions, got: %+v, %v", positions, err)
+	}
+}
+
+func testUnits(t *testing.T, p Provider) {
+	ctx := context.Background()
+	m := addMember(t, p)
+	squadron := types.Unit{ID: uuid.NewString(), Name: testutils.RandomString(), Kind: types.UnitSquadron}
+	if err := p.AddUnit(ctx, squadron); err != nil {
+		t.Fatalf("Error adding unit: %s", err.Error())
+	}
+	orphan := types.Unit{ID: uuid.NewString(), Name: testutils.RandomString(), Kind: types.UnitFlight, ParentID: uuid.NewString()}
+	expectErr(t, "adding a unit with a missing parent", p.AddUnit(ctx, orphan), backend.ErrInvalidUnit)
+	flight := types.Unit{ID: uuid.NewString(), Name: testutils.RandomString(), Kind: types.UnitFlight, ParentID: squadron.ID}
+	if err := p.AddUnit(ctx, flight); err != nil {
+		t.Fatalf("Error adding subunit: %s", err.Error())
+	}
+	_, err := p.GetUnit(ctx, orphan.ID)
+	expectErr(t, "getting a missing unit", err, backend.ErrUnitNotFound)
+	expectErr(t, "updating a missing unit", p.UpdateUnit(ctx, orphan), backend.ErrUnitNotFound)
+	expectErr(t, "deleting a unit with subunits", p.DeleteUnit(ctx, squadron.ID), backend.ErrUnitHasSubunits)
+
+	expectErr(t, "moving a member into a missing unit", p.SetMemberUnit(ctx, m.ID, uuid.NewString()), backend.ErrUnitNotFound)
+	expectErr(t, "moving a missing member", p.SetMemberUnit(ctx, uuid.NewString(), flight.ID), backend.ErrMemberNotFound)
+	if err = p.SetMemberUnit(ctx, m.ID, flight.ID); err != nil {
+		t.Fatalf("Error moving member into unit: %s", err.Error())
+	}
+	if members, err := p.GetUnitMembers(ctx, flight.ID); err != nil || len(members) != 1 || members[0].ID != m.ID {
+		t.Errorf("Expected %s to be the only member of the unit, got: %+v, %v", m.ID, members, err)
+	}
+
+	expectErr(t, "designating an admin of a missing unit", p.AddUnitAdmin(ctx, uuid.NewString(), m.ID), backend.ErrUnitNotFound)
+	expectErr(t, "designating a missing admin", p.AddUnitAdmin(ctx, flight.ID, uuid.NewString()), backend.ErrMemberNotFound)
+	expectErr(t, "removing an admin who isn't one", p.RemoveUnitAdmin(ctx, flight.ID, m.ID), backend.ErrUnitAdminNotFound)
+	if err = p.AddUnitAdmin(ctx, flight.ID, m.ID); err != nil {
+		t.Fatalf("Error designating unit admin: %s", err.Error())
+	}
+	expectErr(t, "designating an admin twice", p.AddUnitAdmin(ctx, flight.ID, m.ID), backend.ErrUnitAdminAlreadyDesignated)
+
+	if err = p.DeleteUnit(ctx, flight.ID); err != nil {
+		t.Fatalf("Error deleting unit: %s", err.Error())
+	}
+	if got, err := p.GetMember(ctx, m.ID, backend.ById); err != nil || got.UnitID != "" {
+		t.Errorf("Expected member to be left without a unit, got: %q, %v", got.UnitID, err)
+	}
+	if ids, err := p.GetAdministeredUnitIDs(ctx, m.ID); err != nil || len(ids) != 0 {
+		t.Errorf("Expected member to no longer administer the deleted unit, got: %v, %v", ids, err)
+	}
+	if err = p.DeleteUnit(ctx, squadron.ID); err != nil {
+		t.Errorf("Expected unit to be deletable once its subunits are gone, got: %s", err.Error())
+	}
+}
+
+func testAPITokens(t *testing.T, p Provider) {
+	ctx := context.Background()
+	m := addMember(t, p)
+	token := types.APIToken{ID: uuid.NewString(), MemberID: m.ID, Name: "ci", Scope: types.ScopeReadWrite, Hash: uuid.NewString(), Created: now()}
+	missing := token
+	missing.ID = uuid.NewString()
+	missing.MemberID = uuid.NewString()
+	missing.Hash = uuid.NewString()
+	expectErr(t, "adding a token for a missing member", p.AddAPIToken(ctx, missing), backend.ErrMemberNotFound)
+	if err := p.AddAPIToken(ctx, token); err != nil {
+		t.Fatalf("Error adding api token: %s", err.Error())
+	}
+	got, err := p.GetAPITokenByHash(ctx, token.Hash)
+	if err != nil || got.ID != token.ID || got.MemberID != m.ID || got.Scope != token.Scope || got.LastUsed != nil {
+		t.Errorf("Expected api token %+v, got: %+v, %v", token, got, err)
+	}
+	_, err = p.GetAPITokenByHash(ctx, uuid.NewString())
+	expectErr(t, "getting a token by an unknown hash", err, backend.ErrAPITokenNotFound)
+	expectErr(t, "using a missing token", p.UpdateAPITokenLastUsed(ctx, uuid.NewString(), now()), backend.ErrAPITokenNotFound)
+	used := now()
+	if err = p.UpdateAPITokenLastUsed(ctx, token.ID, used); err != nil {
+		t.Fatalf("Error updating api token last used: %s", err.Error())
+	}
+	if tokens, err := p.GetAPITokens(ctx, m.ID); err != nil || len(tokens) != 1 || tokens[0].LastUsed == nil || !tokens[0].LastUsed.Equal(used) {
+		t.Errorf("Expected token last used at %s, got: %+v, %v", used, tokens, err)
+	}
+
+	other := addMember(t, p)
+	expectErr(t, "deleting another member's token", p.DeleteAPIToken(ctx, other.ID, token.ID), backend.ErrAPITokenNotFound)
+	if err = p.DeleteAPIToken(ctx, m.ID, token.ID); err != nil {
+		t.Fatalf("Error deleting api token: %s", err.Error())
+	}
+	if tokens, err := p.GetAPITokens(ctx, m.ID); err != nil || len(tokens) != 0 {
+		t.Errorf("Expected no tokens left, got: %+v, %v", tokens, err)
+	}
+}
+
+func testWaivers(t *testing.T, p Provider) {
+	ctx := context.Background()
+	m := addMember(t, p)
+	approver := addMember(t, p)
+	r := addRequirement(t, p)
+	w := types.Waiver{
+		ID:            uuid.NewString(),
+		MemberID:      m.ID,
+		RequirementID: r.ID,
+		Kind:          types.WaiverKindWaiver,
+		ApproverID:    approver.ID,
+		Reason:        testutils.RandomString(),
+		Start:         now(),
+		End:           now().Add(30 * 24 * time.Hour),
+		Created:       now(),
+	}
+	missing := w
+	missing.ID = uuid.NewString()
+	missing.MemberID = uuid.NewString()
+	expectErr(t, "adding a waiver for a missing member", p.AddWaiver(ctx, missing), backend.ErrMemberNotFound)
+	missing.MemberID = m.ID
+	missing.RequirementID = uuid.NewString()
+	expectErr(t, "adding a waiver for a missing requirement", p.AddWaiver(ctx, missing), backend.ErrRequirementNotFound)
+	if err := p.AddWaiver(ctx, w); err != nil {
+		t.Fatalf("Error adding waiver: %s", err.Error())
+	}
+	_, err := p.GetWaiverMemo(ctx, w.ID)
+	expectErr(t, "getting a memo that wasn't attached", err, backend.ErrWaiverMemoNotFound)
+	_, err = p.GetWaiver(ctx, uuid.NewString())
+	expectErr(t, "getting a missing waiver", err, backend.ErrWaiverNotFound)
+	expectErr(t, "ending a missing waiver", p.UpdateWaiverEnd(ctx, uuid.NewString(), now()), backend.ErrWaiverNotFound)
+
+	memo := types.WaiverMemo{Name: "memo.pdf", ContentType: "application/pdf", Data: []byte(testutils.RandomString())}
+	withMemo := w
+	withMemo.ID = uuid.NewString()
+	withMemo.Start = w.Start.Add(time.Hour)
+	withMemo.Memo = &memo
+	if err = p.AddWaiver(ctx, withMemo); err != nil {
+		t.Fatalf("Error adding waiver with memo: %s", err.Error())
+	}
+	got, err := p.GetWaiver(ctx, withMemo.ID)
+	if err != nil || got.MemoName != memo.Name || got.Memo != nil || !got.End.Equal(withMemo.End) {
+		t.Errorf("Expected waiver with memo named %s and no memo contents, got: %+v, %v", memo.Name, got, err)
+	}
+	gotMemo, err := p.GetWaiverMemo(ctx, withMemo.ID)
+	if err != nil || gotMemo.Name != memo.Name || gotMemo.ContentType != memo.ContentType || string(gotMemo.Data) != string(memo.Data) {
+		t.Errorf("Expected memo %+v, got: %+v, %v", memo, gotMemo, err)
+	}
+	end := now()
+	if err = p.UpdateWaiverEnd(ctx, w.ID, end); err != nil {
+		t.Fatalf("Error ending waiver: %s", err.Error())
+	}
+	waivers, err := p.GetMemberWaivers(ctx, m.ID)
+	if err != nil || len(waivers) != 2 || waivers[0].ID != w.ID || !waivers[0].End.Equal(end) || waivers[1].ID != withMemo.ID {
+		t.Errorf("Expected both waivers oldest first with the first ended at %s, got: %+v, %v", end, waivers, err)
+	}
+}
+
+func testImports(t *testing.T, p Provider) {
+	ctx := context.Background()
+	m := addMember(t, p)
+	r := addRequirement(t, p)
+	profile := types.ImportProfile{ID: uuid.NewString(), Name: testutils.RandomString(), MemberColumn: "member", RequirementColumn: "course", DateColumn: "date"}
+	if err := p.AddImportProfile(ctx, profile); err != nil {
+		t.Fatalf("Error adding import profile: %s", err.Error())
+	}
+	duplicate := profile
+	duplicate.ID = uuid.NewString()
+	expectErr(t, "adding an import profile with a taken name", p.AddImportProfile(ctx, duplicate), backend.ErrDuplicateImportProfile)
+	_, err := p.GetImportProfile(ctx, duplicate.ID)
+	expectErr(t, "getting a missing import profile", err, backend.ErrImportProfileNotFound)
+
+	completed := now().Add(-24 * time.Hour)
+	batch := types.ImportBatch{
+		ID:         uuid.NewString(),
+		ProfileID:  profile.ID,
+		FileName:   "export.csv",
+		UploadedBy: m.ID,
+		Uploaded:   now(),
+		Status:     types.ImportBatchStaged,
+		Rows: []types.StagedRow{
+			{ID: uuid.NewString(), Line: 3, MemberIdentifier: m.Username, MemberID: m.ID, RequirementName: r.Name, RequirementID: r.ID, MatchScore: 1, CompletedDate: completed},
+			{ID: uuid.NewString(), Line: 2, MemberIdentifier: "nobody", RequirementName: r.Name, Errors: []string{"member not found"}},
+		},
+	}
+	if err = p.AddImportBatch(ctx, batch); err != nil {
+		t.Fatalf("Error adding import batch: %s", err.Error())
+	}
+	got, err := p.GetImportBatch(ctx, batch.ID)
+	if err != nil || len(got.Rows) != 2 || got.Rows[0].Line != 2 || len(got.Rows[0].Errors) != 1 || got.Rows[1].Errors != nil {
+		t.Errorf("Expected rows ordered by line with their errors, got: %+v, %v", got.Rows, err)
+	}
+	_, err = p.GetImportBatch(ctx, uuid.NewString())
+	expectErr(t, "getting a missing import batch", err, backend.ErrImportBatchNotFound)
+	expectErr(t, "updating a row in another batch", p.UpdateImportRow(ctx, uuid.NewString(), batch.Rows[1]), backend.ErrImportRowNotFound)
+	excluded := batch.Rows[1]
+	excluded.Excluded = true
+	excluded.Errors = nil
+	if err = p.UpdateImportRow(ctx, batch.ID, excluded); err != nil {
+		t.Fatalf("Error excluding row: %s", err.Error())
+	}
+
+	c := types.Completion{
+		ID:            uuid.NewString(),
+		MemberID:      m.ID,
+		RequirementID: r.ID,
+		SubmittedBy:   m.ID,
+		Status:        types.CompletionApproved,
+		CompletedDate: completed,
+		Submitted:     now(),
+		Reviewed:      now(),
+	}
+	invalid := c
+	invalid.ID = uuid.NewString()
+	invalid.MemberID = uuid.NewString()
+	expectErr(t, "committing a completion for a missing member", p.CommitImportBatch(ctx, batch.ID, []types.Completion{c, invalid}), backend.ErrInvalidImport)
+	if got, err = p.GetImportBatch(ctx, batch.ID); err != nil || got.Status != types.ImportBatchStaged {
+		t.Errorf("Expected failed commit to leave the batch staged, got: %s, %v", got.Status, err)
+	}
+	_, err = p.GetCompletion(ctx, c.ID)
+	expectErr(t, "getting a completion from a failed commit", err, backend.ErrCompletionNotFound)
+	if err = p.CommitImportBatch(ctx, batch.ID, []types.Completion{c}); err != nil {
+		t.Fatalf("Error committing import batch: %s", err.Error())
+	}
+	expectErr(t, "committing a batch twice", p.CommitImportBatch(ctx, batch.ID, nil), backend.ErrImportBatchCommitted)
+	if reqs, err := p.GetMemberRequirements(ctx, m.ID); err != nil || len(reqs) != 1 || !reqs[0].CompletedDate.Equal(completed) {
+		t.Errorf("Expected imported completion to be recorded, got: %+v, %v", reqs, err)
+	}
+
+	if err = p.DeleteImportProfile(ctx, profile.ID); err != nil {
+		t.Fatalf("Error deleting import profile: %s", err.Error())
+	}
+	expectErr(t, "deleting an import profile twice", p.DeleteImportProfile(ctx, profile.ID), backend.ErrImportProfileNotFound)
+	if got, err = p.GetImportBatch(ctx, batch.ID); err != nil || got.ProfileID != "" {
+		t.Errorf("Expected batch to be kept without its profile, got: %q, %v", got.ProfileID, err)
+	}
+	if err = p.DeleteImportBatch(ctx, batch.ID); err != nil {
+		t.Fatalf("Error deleting import batch: %s", err.Error())
+	}
+	expectErr(t, "deleting an import batch twice", p.DeleteImportBatch(ctx, batch.ID), backend.ErrImportBatchNotFound)
+}
+
+func testTransfers(t *testing.T, p Provider) {
+	ctx := context.Background()
+	m := testutils.RandomMember(false)
+	m.ID = uuid.NewString()
+	transfer := types.TransferImport{PackageID: uuid.NewString(), Issuer: "losing", IssuerMemberID: uuid.NewString(), Member: m}
+	if imported, err := p.IsTransferImported(ctx, transfer.PackageID); err != nil || imported {
+		t.Errorf("Expected package not to be imported yet, got: %v, %v", imported, err)
+	}
+	_, err := p.GetTransferredMemberID(ctx, transfer.Issuer, transfer.IssuerMemberID)
+	expectErr(t, "getting a member that wasn't transferred", err, backend.ErrMemberNotFound)
+	if err = p.ImportTransfer(ctx, transfer); err != nil {
+		t.Fatalf("Error importing transfer: %s", err.Error())
+	}
+	if imported, err := p.IsTransferImported(ctx, transfer.PackageID); err != nil || !imported {
+		t.Errorf("Expected package to be imported, got: %v, %v", imported, err)
+	}
+	if id, err := p.GetTransferredMemberID(ctx, transfer.Issuer, transfer.IssuerMemberID); err != nil || id != m.ID {
+		t.Errorf("Expected transferred member %s, got: %s, %v", m.ID, id, err)
+	}
+	if origins, err := p.GetMemberTransferOrigins(ctx, m.ID); err != nil || len(origins) != 1 || origins[transfer.Issuer] != transfer.IssuerMemberID {
+		t.Errorf("Expected member to come from %s as %s, got: %v, %v", transfer.Issuer, transfer.IssuerMemberID, origins, err)
+	}
+
+	replay := transfer
+	replay.Member = testutils.RandomMember(false)
+	replay.Member.ID = uuid.NewString()
+	expectErr(t, "importing a package twice", p.ImportTransfer(ctx, replay), backend.ErrTransferAlreadyImported)
+	if _, err = p.GetMember(ctx, replay.Member.ID, backend.ById); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected replayed import to be rolled back, got: %v", err)
+	}
+
+	if err = p.DeleteMember(ctx, m.ID, backend.ById); err != nil {
+		t.Fatalf("Error deleting member: %s", err.Error())
+	}
+	_, err = p.GetTransferredMemberID(ctx, transfer.Issuer, transfer.IssuerMemberID)
+	expectErr(t, "getting a deleted transferred member", err, backend.ErrMemberNotFound)
+}
+
+func testRoles(t *testing.T, p Provider) {
+	ctx := context.Background()
+	roles, err := p.GetRoles(ctx)
+	if err != nil || len(roles) != len(types.Roles) {
+		t.Fatalf("Expected every role, got: %+v, %v", roles, err)
+	}
+	for _, role := range roles {
+		want := slices.Clone(types.DefaultRolePermissions[role.Role])
+		slices.Sort(want)
+		if role.Permissions == nil || !slices.Equal(role.Permissions, want) {
+			t.Errorf("Expected %s to be seeded with %v, got: %v", role.Role, want, role.Permissions)
+		}
+	}
+	permissions := []types.Permission{types.PermViewReports, types.PermAssignQualifications}
+	if err = p.SetRolePermissions(ctx, types.RoleTrainer, permissions); err != nil {
+		t.Fatalf("Error setting role permissions: %s", err.Error())
+	}
+	got, err := p.GetRolePermissions(ctx, types.RoleTrainer)
+	if err != nil || !slices.Equal(got, []types.Permission{types.PermAssignQualifications, types.PermViewReports}) {
+		t.Errorf("Expected permissions %v, got: %v, %v", permissions, got, err)
+	}
+	if err = p.SetRolePermissions(ctx, types.RoleTrainer, nil); err != nil {
+		t.Fatalf("Error clearing role permissions: %s", err.Error())
+	}
+	if got, err = p.GetRolePermissions(ctx, types.RoleTrainer); err != nil || got == nil || len(got) != 0 {
+		t.Errorf("Expected no permissions, got: %v, %v", got, err)
+	}
+}
+
+func testTenants(t *testing.T, p Provider) {
+	ctx := context.Background()
+	defaultMember := addMember(t, p)
+	tenant := types.Tenant{ID: uuid.NewString(), Name: testutils.RandomString(), Subdomain: strings.ToLower(testutils.RandomString())}
+	admin := testutils.RandomMember(true)
+	admin.ID = uuid.NewString()
+	admin.Username = defaultMember.Username
+	if err := p.AddTenant(ctx, tenant, admin); err != nil {
+		t.Fatalf("Error adding tenant: %s", err.Error())
+	}
+	duplicate := types.Tenant{ID: uuid.NewString(), Name: testutils.RandomString(), Subdomain: tenant.Subdomain}
+	other := testutils.RandomMember(true)
+	other.ID = uuid.NewString()
+	expectErr(t, "adding a tenant with a taken subdomain", p.AddTenant(ctx, duplicate, other), backend.ErrDuplicateTenant)
+	_, err := p.GetTenant(ctx, duplicate.ID)
+	expectErr(t, "getting a missing tenant", err, backend.ErrTenantNotFound)
+	_, err = p.GetTenantBySubdomain(ctx, strings.ToLower(testutils.RandomString()))
+	expectErr(t, "getting a tenant by an unknown subdomain", err, backend.ErrTenantNotFound)
+	if got, err := p.GetTenantBySubdomain(ctx, tenant.Subdomain); err != nil || got != tenant {
+		t.Errorf("Expected tenant %+v, got: %+v, %v", tenant, got, err)
+	}
+	if tenants, err := p.GetTenants(ctx); err != nil || len(tenants) != 2 {
+		t.Errorf("Expected the default tenant and %s, got: %+v, %v", tenant.ID, tenants, err)
+	}
+
+	members, _, _ := p.Scoped(tenant.ID)
+	if got, err := members.GetMember(ctx, admin.Username, backend.ByUsername); err != nil || got.ID != admin.ID {
+		t.Errorf("Expected tenant's admin to share a username with another tenant's member, got: %+v, %v", got, err)
+	}
+	if _, err = members.GetMember(ctx, defaultMember.ID, backend.ById); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected other tenants' members to be invisible, got: %v", err)
+	}
+	if _, err = p.GetMember(ctx, admin.ID, backend.ById); !errors.Is(err, backend.ErrMemberNotFound) {
+		t.Errorf("Expected tenant's members to be invisible to the default tenant, got: %v", err)
+	}
+	if all, err := members.GetAllMembers(ctx); err != nil || len(all) != 1 {
+		t.Errorf("Expected tenant to only have its admin, got: %+v, %v", all, err)
+	}
+	taken := testutils.RandomMember(false)
+	taken.ID = uuid.NewString()
+	taken.Username = admin.Username
+	expectErr(t, "adding a member with a username taken in the tenant", members.AddMember(ctx, taken), backend.ErrDuplicateUsername)
+	if permissions, err := members.GetRolePermissions(ctx, types.RoleAdmin); err != nil || len(permissions) == 0 {
+		t.Errorf("Expected tenant's roles to be seeded, got: %v, %v", permissions, err)
+	}
+}
+
+// testCrossTenantLinks checks members and requirements can't point at another tenant's supervisors, units or references
+// even knowing their IDs, and that deleting what they point at in their own tenant still unlinks them.
+func testCrossTenantLinks(t *testing.T, p Provider) {
+	ctx := context.Background()
+	supervisor := addMember(t, p)
+	unit := types.Unit{ID: uuid.NewString(), Name: testutils.RandomString(), Kind: types.UnitSquadron}
+	if err := p.AddUnit(ctx, unit); err != nil {
+		t.Fatalf("Error adding unit: %s", err.Error())
+	}
+	ref := testutils.RandomReference()
+	ref.ID = uuid.NewString()
+	if err := p.AddReference(ctx, ref); err != nil {
+		t.Fatalf("Error adding reference: %s", err.Error())
+	}
+	tenant := types.Tenant{ID: uuid.NewString(), Name: testutils.RandomString(), Subdomain: strings.ToLower(testutils.RandomString())}
+	admin := testutils.RandomMember(true)
+	admin.ID = uuid.NewString()
+	if err := p.AddTenant(ctx, tenant, admin); err != nil {
+		t.Fatalf("Error adding tenant: %s", err.Error())
+	}
+	members, _, requirements := p.Scoped(tenant.ID)
+
+	m := testutils.RandomMember(false)
+	m.ID = uuid.NewString()
+	m.Password = ""
+	m.Hash = testutils.RandomString()
+	m.SupervisorID = supervisor.ID
+	expectErr(t, "adding a member supervised by another tenant's member", members.AddMember(ctx, m), backend.ErrSupervisorNotFound)
+	local := addMember(t, members)
+	m.SupervisorID = local.ID
+	if err := members.AddMember(ctx, m); err != nil {
+		t.Fatalf("Error adding member: %s", err.Error())
+	}
+	m.SupervisorID = supervisor.ID
+	expectErr(t, "updating a member to be supervised by another tenant's member", members.UpdateMember(ctx, m), backend.ErrSupervisorNotFound)
+	expectErr(t, "moving a member into another tenant's unit", members.SetMemberUnit(ctx, m.ID, unit.ID), backend.ErrUnitNotFound)
+	expectErr(t, "adding a requirement with another tenant's reference", requirements.AddRequirement(ctx, testutils.RandomRequirement(ref)), backend.ErrReferenceNotFound)
+	r := addRequirement(t, requirements)
+	other := r
+	other.Reference = ref
+	expectErr(t, "updating a requirement to another tenant's reference", requirements.UpdateRequirement(ctx, other), backend.ErrReferenceNotFound)
+
+	localUnit := types.Unit{ID: uuid.NewString(), Name: testutils.RandomString(), Kind: types.UnitSquadron}
+	if err := members.AddUnit(ctx, localUnit); err != nil {
+		t.Fatalf("Error adding unit: %s", err.Error())
+	}
+	if err := members.SetMemberUnit(ctx, m.ID, localUnit.ID); err != nil {
+		t.Fatalf("Error moving member into unit: %s", err.Error())
+	}
+	if err := members.DeleteMember(ctx, local.ID, backend.ById); err != nil {
+		t.Fatalf("Error deleting supervisor: %s", err.Error())
+	}
+	if err := members.DeleteUnit(ctx, localUnit.ID); err != nil {
+		t.Fatalf("Error deleting unit: %s", err.Error())
+	}
+	if got, err := members.GetMember(ctx, m.ID, backend.ById); err != nil || got.SupervisorID != "" || got.UnitID != "" {
+		t.Errorf("Expected member to be left without a supervisor or unit, got: %+v, %v", got, err)
+	}
+	if err := requirements.DeleteReference(ctx, r.Reference.ID); err != nil {
+		t.Fatalf("Error deleting reference: %s", err.Error())
+	}
+	if got, err := requirements.GetRequirement(ctx, r.ID); err != nil || got.Reference.ID != "" {
+		t.Errorf("Expected requirement to be left without a reference, got: %+v, %v", got.Reference, err)
+	}
+	if _, err := p.GetMember(ctx, supervisor.ID, backend.ById); err != nil {
+		t.Errorf("Expected other tenant's supervisor to be untouched, got: %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package sqlite
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+func (p Provider) AddCertifier(ctx context.Context, requirementID, memberID string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Designating certifier for requirement",
+		slog.String("requirement_id", requirementID), slog.String("member_id", memberID))
+	_, err := p.Db.ExecContext(ctx, addCertifierQuery, requirementID, memberID)
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Member already designated as certifier for requirement")
+		return fmt.Errorf("%w: requirement_id=%s member_id=%s", backend.ErrCertifierAlreadyDesignated, requirementID, memberID)
+	} else if err != nil && strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+		if _, lookupErr := p.GetMember(ctx, memberID, backend.ById); lookupErr != nil {
+			return lookupErr
+		}
+		if _, lookupErr := p.GetRequirement(ctx, requirementID); lookupErr != nil {
+			return lookupErr
+		}
+		// Both exist, so something else the insert depends on is missing
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error designating certifier", slog.String("error", err.Error()))
+		return err
+	} else if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error designating certifier", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) GetCertifierIDs(ctx context.Context, requirementID string) ([]string, error) {
+	rows, err := p.Db.QueryContext(ctx, getCertifierIDsQuery, requirementID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting certifiers for requirement", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []string{}
+	var id string
+	for rows.Next() {
+		if err = rows.Scan(&id); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning id into string", slog.String("error", err.Error()))
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (p Provider) IsCertifier(ctx context.Context, requirementID, memberID string) (bool, error) {
+	var count int
+	if err := p.Db.QueryRowContext(ctx, checkCertifierQuery, requirementID, memberID).Scan(&count); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error checking certifier designation", slog.String("error", err.Error()))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (p Provider) RemoveCertifier(ctx context.Context, requirementID, memberID string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Removing certifier from requirement",
+		slog.String("requirement_id", requirementID), slog.String("member_id", memberID))
+	res, err := p.Db.ExecContext(ctx, removeCertifierQuery, requirementID, memberID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error removing certifier", slog.String("error", err.Error()))
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Could not find certifier to remove")
+		return fmt.Errorf("%w: requirement_id=%s member_id=%s", backend.ErrCertifierNotFound, requirementID, memberID)
+	}
+	return nil
+}
+
+func (p Provider) AddCompletion(ctx context.Context, c types.Completion) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Inserting completion into database", slog.Any("completion", c))
+	_, err := p.Db.ExecContext(ctx, insertCompletionQuery, c.ID, c.MemberID, c.RequirementID, nullString(c.TrainerID), c.SubmittedBy,
+		c.Status, c.CompletedDate, c.Submitted, c.Comments)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting completion into database", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) GetCompletion(ctx context.Context, id string) (types.Completion, error) {
+	c, err := scanCompletion(p.Db.QueryRowContext(ctx, getCompletionQuery, id))
+	if err != nil && strings.Contains(err.Error(), "no rows in result set") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "No completion found with given id", slog.String("completion_id", id))
+		return types.Completion{}, fmt.Errorf("%w: completion_id=%s", backend.ErrCompletionNotFound, id)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning completion into struct", slog.String("error", err.Error()))
+		return types.Completion{}, err
+	}
+	return c, nil
+}
+
+func (p Provider) GetMemberCompletions(ctx context.Context, memberID string) ([]types.Completion, error) {
+	return p.queryCompletions(ctx, getMemberCompletionsQuery, memberID)
+}
+
+func (p Provider) GetPendingCompletions(ctx context.Context, certifierID string) ([]types.Completion, error) {
+	return p.queryCompletions(ctx, getPendingCompletionsForCertifierQuery, certifierID, types.CompletionPending)
+}
+
+// ReviewCompletion records the certifier's decision on a pending completion. Approved completions are applied to the
+// member's requirement record in the same transaction so the two never disagree.
+func (p Provider) ReviewCompletion(ctx context.Context, c types.Completion) error {
+	l := p.logger.With(slog.String("completion_id", c.ID))
+	l.LogAttrs(ctx, slog.LevelInfo, "Recording completion review", slog.String("status", string(c.Status)))
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Error creating transaction for ReviewCompletion", slog.String("error", err.Error()))
+		return err
+	}
+	res, err := tx.ExecContext(ctx, reviewCompletionQuery, c.CertifierID, c.Status, c.Reviewed, c.Comments, c.ID, types.CompletionPending)
+	if err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Error updating completion", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		l.LogAttrs(ctx, slog.LevelWarn, "Completion is no longer pending")
+		tx.Rollback()
+		return fmt.Errorf("%w: completion_id=%s", backend.ErrCompletionAlreadyReviewed, c.ID)
+	}
+	if c.Status == types.CompletionApproved {
+		if _, err = tx.ExecContext(ctx, upsertMemberRequirementQuery, c.MemberID, c.RequirementID, c.CompletedDate); err != nil {
+			l.LogAttrs(ctx, slog.LevelError, "Error recording member requirement completion", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		l.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) queryCompletions(ctx context.Context, query string, args ...any) ([]types.Completion, error) {
+	rows, err := p.Db.QueryContext(ctx, query, args...)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting completions", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	completions := []types.Completion{}
+	for rows.Next() {
+		c, err := scanCompletion(rows)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning completion into struct", slog.String("error", err.Error()))
+			return nil, err
+		}
+		completions = append(completions, c)
+	}
+	return completions, nil
+}
+
+func scanCompletion(s scanner) (types.Completion, error) {
+	var c types.Completion
+	var trainerID, certifierID sql.NullString
+	var reviewed sql.NullTime
+	err := s.Scan(&c.ID, &c.MemberID, &c.RequirementID, &trainerID, &certifierID, &c.SubmittedBy, &c.Status,
+		&c.CompletedDate, &c.Submitted, &reviewed, &c.Comments)
+	if err != nil {
+		return types.Completion{}, err
+	}
+	c.TrainerID = trainerID.String
+	c.CertifierID = certifierID.String
+	c.Reviewed = reviewed.Time
+	return c, nil
+}
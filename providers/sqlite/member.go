@@ -11,144 +11,348 @@ import (
 	"strings"
 )
 
-func (p Provider) AddMember(m types.Member) error {
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Inserting member into database", slog.Any("member", m))
-	var err error
-	if m.SupervisorID == "" {
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "No supervisor provided, setting to null in database")
-		_, err = p.Db.Exec(insertMemberQuery, m.ID, m.FirstName, m.LastName, m.Rank, m.Username, nil, m.Admin, m.Hash)
-	} else {
-		_, err = p.Db.Exec(insertMemberQuery, m.ID, m.FirstName, m.LastName, m.Rank, m.Username, m.SupervisorID, m.Admin, m.Hash)
-	}
+func (p Provider) AddMember(ctx context.Context, m types.Member) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Inserting member into database", slog.Any("member", m))
+	_, err := p.Db.ExecContext(ctx, insertMemberQuery, m.ID, m.FirstName, m.LastName, m.Rank, m.Username, nullString(m.SupervisorID), m.Admin, m.Role, m.Hash, nullString(m.CertificateID), nullString(m.Email))
 	if err != nil && strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
-		p.logger.LogAttrs(context.Background(), slog.LevelWarn, "Provided supervisor id doesn't exist", slog.String("supervisor_id", m.ID))
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Provided supervisor id doesn't exist", slog.String("supervisor_id", m.ID))
 		return fmt.Errorf("%w: %s", backend.ErrSupervisorNotFound, m.SupervisorID)
 	} else if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed: member.user_name") {
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Provided username is taken")
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Provided username is taken")
 		return backend.ErrDuplicateUsername
+	} else if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed: member.certificate_id") {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Provided certificate ID is already bound to a member")
+		return backend.ErrDuplicateCertificate
 	} else if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error inserting member into database", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting member into database", slog.String("error", err.Error()))
 		return err
 	}
 	return nil
 }
 
-func (p Provider) GetMember(identifier string, method backend.ProviderMethod) (types.Member, error) {
+func (p Provider) GetMember(ctx context.Context, identifier string, method backend.ProviderMethod) (types.Member, error) {
 	var row *sql.Row
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting member from database")
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Getting member from database")
 	switch method {
 	case backend.ById:
-		row = p.Db.QueryRow(getMemberQuery, identifier)
+		row = p.Db.QueryRowContext(ctx, getMemberQuery, identifier)
 		break
 	case backend.ByUsername:
-		row = p.Db.QueryRow(getMemberByUsernameQuery, identifier)
+		row = p.Db.QueryRowContext(ctx, getMemberByUsernameQuery, identifier)
+	case backend.ByCertificateID:
+		row = p.Db.QueryRowContext(ctx, getMemberByCertificateIDQuery, identifier)
 	default:
 		return types.Member{}, errors.New(fmt.Sprintf("unexpected retrieval method: %d", method))
 	}
-	var m types.Member
-	supervisorId := sql.NullString{}
-	err := row.Scan(&m.ID, &m.FirstName, &m.LastName, &m.Rank, &m.Username, &supervisorId, &m.Admin, &m.Hash)
+	m, err := scanMember(row)
 	if err != nil && strings.Contains(err.Error(), "no rows in result set") {
-		p.logger.LogAttrs(context.Background(), slog.LevelWarn, "No user found with given identifier")
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "No user found with given identifier")
 		return types.Member{}, backend.ErrMemberNotFound
 	} else if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error scanning member into struct", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning member into struct", slog.String("error", err.Error()))
 		return types.Member{}, err
 	}
-	if supervisorId.Valid {
-		m.SupervisorID = supervisorId.String
-	}
 	return m, nil
 }
 
-func (p Provider) GetAllMembers() ([]types.Member, error) {
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting all members from database")
-	rows, err := p.Db.Query(getAllMembersQuery)
+func (p Provider) GetAllMembers(ctx context.Context) ([]types.Member, error) {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Getting all members from database")
+	rows, err := p.Db.QueryContext(ctx, getAllMembersQuery)
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error getting all members from database", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting all members from database", slog.String("error", err.Error()))
 		return nil, err
 	}
 	defer rows.Close()
 	var members []types.Member
 	for rows.Next() {
-		m := types.Member{}
-		supervisorId := sql.NullString{}
-		err = rows.Scan(&m.ID, &m.FirstName, &m.LastName, &m.Rank, &m.Username, &supervisorId, &m.Admin, &m.Hash)
+		m, err := scanMember(rows)
 		if err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error scanning member into struct", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning member into struct", slog.String("error", err.Error()))
 			continue
 		}
-		if supervisorId.Valid {
-			m.SupervisorID = supervisorId.String
-		}
 		members = append(members, m)
 	}
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, fmt.Sprintf("Retreived %d members from database", len(members)))
+	p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Retreived %d members from database", len(members)))
 	return members, nil
 }
 
-func (p Provider) GetSubordinates(memberID string) ([]types.Member, error) {
-	rows, err := p.Db.Query(getSubordinatesQuery, memberID)
+// memberOrder is what members are sorted by for each key, in the same order as types.Member.SortValues.
+var memberOrder = map[types.SortKey][]string{
+	types.SortByLastName: {"last_name", "first_name", "id"},
+	types.SortByRank:     {rankOrder, "last_name", "first_name", "id"},
+}
+
+func (p Provider) ListMembers(ctx context.Context, opts types.MemberListOptions) (types.Page[types.Member], error) {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Listing members from database")
+	after, err := decodeCursor(opts.ListOptions, types.Member{})
+	if err != nil {
+		return types.Page[types.Member]{}, err
+	}
+	q := listQuery{from: "member", tenant: "tenant_id"}
+	switch opts.Archived {
+	case types.MemberActive:
+		q.where("archived IS NULL")
+	case types.MemberArchived:
+		q.where("archived IS NOT NULL")
+	}
+	if opts.Rank != "" {
+		q.where("rank=?", opts.Rank)
+	}
+	if opts.SupervisorID != "" {
+		q.where("supervisor_id=?", opts.SupervisorID)
+	}
+	if opts.Admin != nil {
+		q.where("admin=?", *opts.Admin)
+	}
+	if opts.QualificationID != "" {
+		q.where("EXISTS (SELECT 1 FROM member_qualification mq WHERE mq.member_id = member.id AND mq.tenant_id = member.tenant_id AND mq.qualification_id=?)", opts.QualificationID)
+	}
+	if len(opts.UnitIDs) > 0 {
+		unitIDs := make([]any, len(opts.UnitIDs))
+		for i, id := range opts.UnitIDs {
+			unitIDs[i] = id
+		}
+		q.where("unit_id IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(unitIDs)), ", ")+")", unitIDs...)
+	}
+	total, err := p.countRows(ctx, q)
+	if err != nil {
+		return types.Page[types.Member]{}, err
+	}
+	query, args := q.page(memberColumns, memberOrder[opts.Sort], opts.ListOptions, after)
+	rows, err := p.Db.QueryContext(ctx, query, args...)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error listing members from database", slog.String("error", err.Error()))
+		return types.Page[types.Member]{}, err
+	}
+	defer rows.Close()
+	var members []types.Member
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning member into struct", slog.String("error", err.Error()))
+			return types.Page[types.Member]{}, err
+		}
+		members = append(members, m)
+	}
+	return types.NewPage(members, total, opts.ListOptions), nil
+}
+
+func (p Provider) GetSubordinates(ctx context.Context, memberID string) ([]types.Member, error) {
+	rows, err := p.Db.QueryContext(ctx, getSubordinatesQuery, memberID)
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error getting subordinates for member", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting subordinates for member", slog.String("error", err.Error()))
 		return nil, err
 	}
+	defer rows.Close()
 	var subordinates []types.Member
-	var subordinate types.Member
 	for rows.Next() {
-		err = rows.Scan(&subordinate.ID, &subordinate.FirstName, &subordinate.LastName, &subordinate.Rank, &subordinate.Username, &subordinate.SupervisorID, &subordinate.Admin, &subordinate.Hash)
+		subordinate, err := scanMember(rows)
 		if err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error when scanning subordinate into struct", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error when scanning subordinate into struct", slog.String("error", err.Error()))
 			return nil, err
 		}
 		subordinates = append(subordinates, subordinate)
 	}
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, fmt.Sprintf("Found %d subordinates for member", len(subordinates)))
+	p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Found %d subordinates for member", len(subordinates)))
 	return subordinates, nil
 }
 
-func (p Provider) UpdateMember(m types.Member) error {
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Updating member", slog.Any("member", m))
-	var res sql.Result
-	var err error
-	if m.SupervisorID == "" {
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Supervisory ID is empty, inserting as null in database")
-		res, err = p.Db.Exec(updateMemberQuery, m.FirstName, m.LastName, m.Rank, nil, m.Admin, m.Hash, m.ID)
-	} else {
-		res, err = p.Db.Exec(updateMemberQuery, m.FirstName, m.LastName, m.Rank, m.SupervisorID, m.Admin, m.Hash, m.ID)
-	}
+func (p Provider) UpdateMember(ctx context.Context, m types.Member) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Updating member", slog.Any("member", m))
+	res, err := p.Db.ExecContext(ctx, updateMemberQuery, m.FirstName, m.LastName, m.Rank, nullString(m.SupervisorID), m.Admin, m.Role, m.Hash, nullString(m.CertificateID), nullString(m.Email), m.ID, m.Version)
 	if err != nil && strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
-		p.logger.LogAttrs(context.Background(), slog.LevelWarn, "Attempting to update member with non-existent supervisor")
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Attempting to update member with non-existent supervisor")
 		return backend.ErrSupervisorNotFound
 	}
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed: member.certificate_id") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Attempting to bind a certificate ID already in use")
+		return backend.ErrDuplicateCertificate
+	}
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error updating member", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error updating member", slog.String("error", err.Error()))
 		return err
 	}
 	if n, _ := res.RowsAffected(); n == 0 {
-		p.logger.LogAttrs(context.Background(), slog.LevelWarn, "Expected 1 row to be updated for member, got 0")
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Expected 1 row to be updated for member, got 0")
+		return p.missedUpdate(ctx, p.Db, m.Version, backend.ErrMemberNotFound, getMemberVersionQuery, m.ID)
+	}
+	return nil
+}
+
+// DeleteMember removes the member along with everything recorded about them, including qualification history.
+func (p Provider) DeleteMember(ctx context.Context, identifier string, method backend.ProviderMethod) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting member", slog.String("identifier", identifier))
+	m, err := p.GetMember(ctx, identifier, method)
+	if err != nil {
+		return err
+	}
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for DeleteMember", slog.String("error", err.Error()))
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, deleteMemberHistoryQuery, m.ID); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error deleting member qualification history", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, clearSubordinatesQuery, m.ID); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error removing member as their subordinates' supervisor", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	res, err := tx.ExecContext(ctx, deleteMemberQuery, m.ID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error deleting member", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Expected 1 row to be updated for member, got 0")
+		tx.Rollback()
 		return backend.ErrMemberNotFound
 	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
 	return nil
 }
 
-func (p Provider) DeleteMember(identifier string, method backend.ProviderMethod) error {
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Deleting member", slog.String("identifier", identifier))
-	var res sql.Result
-	var err error
-	switch method {
-	case backend.ById:
-		res, err = p.Db.Exec(deleteMemberQuery, identifier)
-	case backend.ByUsername:
-		res, err = p.Db.Exec(deleteMemberByUsernameQuery, identifier)
+// ArchiveMember marks the member archived and revokes their api tokens so archived members can't keep using them.
+func (p Provider) ArchiveMember(ctx context.Context, memberID string, archive types.MemberArchive) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Archiving member", slog.String("member_id", memberID))
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for ArchiveMember", slog.String("error", err.Error()))
+		return err
+	}
+	res, err := tx.ExecContext(ctx, archiveMemberQuery, archive.Date, archive.Reason, nullString(archive.ArchivedBy), memberID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error archiving member", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		tx.Rollback()
+		return backend.ErrMemberNotFound
 	}
+	if _, err = tx.ExecContext(ctx, deleteMemberAPITokensQuery, memberID); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error revoking api tokens for archived member", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) RestoreMember(ctx context.Context, memberID string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Restoring archived member", slog.String("member_id", memberID))
+	res, err := p.Db.ExecContext(ctx, restoreMemberQuery, memberID)
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error deleting member", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error restoring member", slog.String("error", err.Error()))
 		return err
 	}
 	if n, _ := res.RowsAffected(); n == 0 {
-		p.logger.LogAttrs(context.Background(), slog.LevelWarn, "Expected 1 row to be updated for member, got 0")
 		return backend.ErrMemberNotFound
 	}
 	return nil
 }
+
+func (p Provider) GetArchivedMembers(ctx context.Context) ([]types.Member, error) {
+	rows, err := p.Db.QueryContext(ctx, getArchivedMembersQuery)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting archived members from database", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	members := []types.Member{}
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning member into struct", slog.String("error", err.Error()))
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanMember scans a full member row, converting nullable columns to their zero values.
+func scanMember(s scanner) (types.Member, error) {
+	var m types.Member
+	var supervisorID, certificateID, email, archiveReason, archivedBy, unitID sql.NullString
+	var archived sql.NullTime
+	err := s.Scan(&m.ID, &m.FirstName, &m.LastName, &m.Rank, &m.Username, &supervisorID, &m.Admin, &m.Role, &m.Hash, &certificateID, &email,
+		&archived, &archiveReason, &archivedBy, &unitID, &m.Version)
+	if err != nil {
+		return types.Member{}, err
+	}
+	m.SupervisorID = supervisorID.String
+	m.CertificateID = certificateID.String
+	m.Email = email.String
+	m.UnitID = unitID.String
+	if archived.Valid {
+		m.Archive = &types.MemberArchive{Reason: archiveReason.String, Date: archived.Time, ArchivedBy: archivedBy.String}
+	}
+	return m, nil
+}
+
+// nullString converts empty strings to NULL so optional foreign keys and unique columns aren't violated.
+func nullString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// AddMembers inserts every member in a single transaction. Supervisors are set once everyone is inserted so members can
+// be supervised by others in the same batch regardless of order.
+func (p Provider) AddMembers(ctx context.Context, members []types.Member) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Inserting members into database", slog.Int("count", len(members)))
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error starting transaction", slog.String("error", err.Error()))
+		return err
+	}
+	for _, m := range members {
+		_, err = tx.ExecContext(ctx, insertMemberQuery, m.ID, m.FirstName, m.LastName, m.Rank, m.Username, nil, m.Admin, m.Role, m.Hash, nullString(m.CertificateID), nullString(m.Email))
+		if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed: member.user_name") {
+			tx.Rollback()
+			return fmt.Errorf("%w: %s", backend.ErrDuplicateUsername, m.Username)
+		} else if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed: member.certificate_id") {
+			tx.Rollback()
+			return fmt.Errorf("%w: %s", backend.ErrDuplicateCertificate, m.CertificateID)
+		} else if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting member into database", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+	}
+	for _, m := range members {
+		if m.SupervisorID == "" {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, setMemberSupervisorQuery, m.SupervisorID, m.ID)
+		if err != nil && strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+			tx.Rollback()
+			return fmt.Errorf("%w: %s", backend.ErrSupervisorNotFound, m.SupervisorID)
+		} else if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error setting member supervisor", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing members", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
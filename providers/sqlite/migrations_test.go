@@ -0,0 +1,82 @@
+package sqlite
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"database/sql"
+	"fmt"
+	_ "github.com/mattn/go-sqlite3"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestMigrations checks a database made before any migration keeps its rows once upgraded, all of them belonging to the
+// default tenant, and starts with the default role
+// permissions.
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+	dbFile := filepath.Join(t.TempDir(), "migrations.db")
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_foreign_keys=on", dbFile))
+	if err != nil {
+		t.Fatalf("Error opening database: %s", err.Error())
+	}
+	for _, query := range []string{
+		createStructureQuery,
+		"INSERT INTO reference(id, name, volume, paragraph) VALUES('ref', 'AFI 1-1', 1, '1.1');",
+		"INSERT INTO requirement(id, name, description, notes, days_valid_for, reference_id) VALUES('req', 'Safety', '', '', 365, 'ref');",
+		"INSERT INTO member(id, first_name, last_name, rank, user_name, supervisor_id, admin, hash) VALUES('sup', 'A', 'B', 'MSgt', 'sup', NULL, 1, 'hash');",
+		"INSERT INTO member(id, first_name, last_name, rank, user_name, supervisor_id, admin, hash) VALUES('mem', 'C', 'D', 'SrA', 'mem', 'sup', 0, 'hash');",
+		"INSERT INTO qualification(id, name, notes, expires, expiration_days) VALUES('qual', 'Forklift', '', 0, 0);",
+		"INSERT INTO qualification_initial_requirement(qualification_id, requirement_id) VALUES('qual', 'req');",
+		"INSERT INTO member_qualification(member_id, qualification_id) VALUES('mem', 'qual');",
+	} {
+		if _, err = db.Exec(query); err != nil {
+			t.Fatalf("Error setting up unmigrated database: %s", err.Error())
+		}
+	}
+	db.Close()
+
+	provider, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), dbFile, SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error migrating database: %s", err.Error())
+	}
+	defer provider.Db.Close()
+	version, err := checkDB(provider.Db.DB)
+	if err != nil || version != SchemaVersion {
+		t.Errorf("Expected database to be at version %v, got: %v, %v", SchemaVersion, version, err)
+	}
+	member, err := provider.GetMember(ctx, "mem", backend.ById)
+	if err != nil {
+		t.Fatalf("Error getting migrated member: %s", err.Error())
+	}
+	if member.SupervisorID != "sup" || member.Role != "member" || member.Version != 1 {
+		t.Errorf("Expected migrated member with its supervisor, the member role and version 1, got: %+v", member)
+	}
+	supervisor, err := provider.GetMember(ctx, "sup", backend.ById)
+	if err != nil || supervisor.Role != "admin" {
+		t.Errorf("Expected admin to get the admin role, got: %+v, %v", supervisor, err)
+	}
+	for role, want := range types.DefaultRolePermissions {
+		want = slices.Clone(want)
+		slices.Sort(want)
+		permissions, err := provider.GetRolePermissions(ctx, role)
+		if err != nil || !slices.Equal(permissions, want) {
+			t.Errorf("Expected %s to start with %v, got: %v, %v", role, want, permissions, err)
+		}
+	}
+	requirement, err := provider.GetRequirement(ctx, "req")
+	if err != nil || requirement.Reference.ID != "ref" {
+		t.Errorf("Expected migrated requirement with its reference, got: %+v, %v", requirement, err)
+	}
+	qualifications, err := provider.GetMemberQualifications(ctx, "mem")
+	if err != nil || len(qualifications) != 1 || len(qualifications[0].InitialRequirements) != 1 {
+		t.Errorf("Expected migrated member qualification with its requirement, got: %+v, %v", qualifications, err)
+	}
+	if _, err = New(slog.New(slog.NewTextHandler(io.Discard, nil)), dbFile, SchemaVersion-1); err == nil {
+		t.Errorf("Expected error opening a database newer than expected")
+	}
+}
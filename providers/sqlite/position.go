@@ -0,0 +1,208 @@
+package sqlite
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+func (p Provider) AddDutyPosition(ctx context.Context, d types.DutyPosition) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding duty position to database", slog.String("position_id", d.ID), slog.String("name", d.Name))
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for AddDutyPosition", slog.String("error", err.Error()))
+		return err
+	}
+	_, err = tx.ExecContext(ctx, insertDutyPositionQuery, d.ID, d.Name, d.Description)
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed: duty_position.name") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Duty position with given name already exists")
+		tx.Rollback()
+		return fmt.Errorf("%w: %s", backend.ErrDuplicateDutyPosition, d.Name)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting duty position", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if err = p.insertDutyPositionQualifications(ctx, tx, d); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) GetDutyPosition(ctx context.Context, id string) (types.DutyPosition, error) {
+	var d types.DutyPosition
+	err := p.Db.QueryRowContext(ctx, getDutyPositionQuery, id).Scan(&d.ID, &d.Name, &d.Description, &d.Version)
+	if err != nil && strings.Contains(err.Error(), "no rows in result set") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "No duty position found with given id", slog.String("position_id", id))
+		return types.DutyPosition{}, fmt.Errorf("%w: position_id=%s", backend.ErrDutyPositionNotFound, id)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning duty position into struct", slog.String("error", err.Error()))
+		return types.DutyPosition{}, err
+	}
+	if d.Qualifications, err = p.queryIDs(ctx, getDutyPositionQualificationsQuery, id); err != nil {
+		return types.DutyPosition{}, err
+	}
+	return d, nil
+}
+
+func (p Provider) GetDutyPositions(ctx context.Context) ([]types.DutyPosition, error) {
+	rows, err := p.Db.QueryContext(ctx, getDutyPositionsQuery)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting duty positions", slog.String("error", err.Error()))
+		return nil, err
+	}
+	positions := []types.DutyPosition{}
+	for rows.Next() {
+		var d types.DutyPosition
+		if err = rows.Scan(&d.ID, &d.Name, &d.Description, &d.Version); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning duty position into struct", slog.String("error", err.Error()))
+			rows.Close()
+			return nil, err
+		}
+		positions = append(positions, d)
+	}
+	rows.Close()
+	for i := range positions {
+		if positions[i].Qualifications, err = p.queryIDs(ctx, getDutyPositionQualificationsQuery, positions[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return positions, nil
+}
+
+// UpdateDutyPosition replaces the position's details and qualification bundle. Propagating the bundle to members is
+// left to the caller.
+func (p Provider) UpdateDutyPosition(ctx context.Context, d types.DutyPosition) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Updating duty position", slog.String("position_id", d.ID))
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for UpdateDutyPosition", slog.String("error", err.Error()))
+		return err
+	}
+	res, err := tx.ExecContext(ctx, updateDutyPositionQuery, d.Name, d.Description, d.ID, d.Version)
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed: duty_position.name") {
+		tx.Rollback()
+		return fmt.Errorf("%w: %s", backend.ErrDuplicateDutyPosition, d.Name)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error updating duty position", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		err = p.missedUpdate(ctx, tx, d.Version, fmt.Errorf("%w: position_id=%s", backend.ErrDutyPositionNotFound, d.ID), getDutyPositionVersionQuery, d.ID)
+		tx.Rollback()
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, deleteDutyPositionQualificationsQuery, d.ID); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error removing duty position qualifications", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if err = p.insertDutyPositionQualifications(ctx, tx, d); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) DeleteDutyPosition(ctx context.Context, id string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting duty position", slog.String("position_id", id))
+	res, err := p.Db.ExecContext(ctx, deleteDutyPositionQuery, id)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error deleting duty position", slog.String("error", err.Error()))
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		return fmt.Errorf("%w: position_id=%s", backend.ErrDutyPositionNotFound, id)
+	}
+	return nil
+}
+
+func (p Provider) AssignMemberDutyPosition(ctx context.Context, memberID, positionID string) error {
+	_, err := p.Db.ExecContext(ctx, assignMemberDutyPositionQuery, memberID, positionID)
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Member already holds duty position")
+		return fmt.Errorf("%w: member_id=%s position_id=%s", backend.ErrDutyPositionAlreadyAssigned, memberID, positionID)
+	} else if err != nil && strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+		if _, err = p.GetMember(ctx, memberID, backend.ById); err != nil {
+			return err
+		}
+		_, err = p.GetDutyPosition(ctx, positionID)
+		return err
+	} else if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error assigning duty position to member", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) GetMemberDutyPositionIDs(ctx context.Context, memberID string) ([]string, error) {
+	return p.queryIDs(ctx, getMemberDutyPositionIDsQuery, memberID)
+}
+
+// GetDutyPositionMemberIDs returns the members holding the position, leaving out archived members.
+func (p Provider) GetDutyPositionMemberIDs(ctx context.Context, positionID string) ([]string, error) {
+	return p.queryIDs(ctx, getDutyPositionMemberIDsQuery, positionID)
+}
+
+func (p Provider) RemoveMemberDutyPosition(ctx context.Context, memberID, positionID string) error {
+	res, err := p.Db.ExecContext(ctx, removeMemberDutyPositionQuery, memberID, positionID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error removing duty position from member", slog.String("error", err.Error()))
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		return fmt.Errorf("%w: member_id=%s position_id=%s", backend.ErrMemberDutyPositionNotFound, memberID, positionID)
+	}
+	return nil
+}
+
+func (p Provider) insertDutyPositionQualifications(ctx context.Context, tx tenantTx, d types.DutyPosition) error {
+	for _, qualificationID := range d.Qualifications {
+		_, err := tx.ExecContext(ctx, insertDutyPositionQualificationQuery, d.ID, qualificationID)
+		if err != nil && strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+			p.logger.LogAttrs(ctx, slog.LevelWarn, "Qualification for duty position not found", slog.String("qualification_id", qualificationID))
+			return fmt.Errorf("%w: %s", backend.ErrQualificationNotFound, qualificationID)
+		}
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error adding qualification to duty position", slog.String("error", err.Error()))
+			return err
+		}
+	}
+	return nil
+}
+
+// queryIDs runs a query selecting a single string column and collects the results.
+func (p Provider) queryIDs(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := p.Db.QueryContext(ctx, query, args...)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error querying ids", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	ids := []string{}
+	var id string
+	for rows.Next() {
+		if err = rows.Scan(&id); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning id into string", slog.String("error", err.Error()))
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
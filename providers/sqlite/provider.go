@@ -1,23 +1,135 @@
 package sqlite
 
 import (
+	"PORTal/backend"
+	"PORTal/types"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 )
 
+// Provider stores every tenant's data in the same database. Each Provider only sees the rows of the tenant it's scoped
+// to, see ForTenant.
 type Provider struct {
 	logger *slog.Logger
-	Db     *sql.DB
+	Db     tenantDB
 }
 
+// tenantDB binds the tenant every query in queries.go filters on as the named $tenant parameter. Queries that don't use
+// it ignore it. A tenantDB bound to a transaction runs every query in it, see WithTx.
+type tenantDB struct {
+	*sql.DB
+	tenant string
+	tx     *sql.Tx
+}
+
+// querier is what tenantDB runs its queries on, the database or the transaction it's bound to.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (db tenantDB) querier() querier {
+	if db.tx != nil {
+		return db.tx
+	}
+	return db.DB
+}
+
+func (db tenantDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return db.querier().ExecContext(ctx, query, withTenant(db.tenant, args)...)
+}
+
+func (db tenantDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return db.querier().QueryContext(ctx, query, withTenant(db.tenant, args)...)
+}
+
+func (db tenantDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return db.querier().QueryRowContext(ctx, query, withTenant(db.tenant, args)...)
+}
+
+// BeginTx begins a transaction, or a savepoint in the transaction the tenantDB is bound to so callers can still commit
+// or roll back their own changes.
+func (db tenantDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (tenantTx, error) {
+	if db.tx != nil {
+		_, err := db.tx.ExecContext(ctx, savepointNestedQuery)
+		return tenantTx{Tx: db.tx, tenant: db.tenant, nested: true}, err
+	}
+	tx, err := db.DB.BeginTx(ctx, opts)
+	return tenantTx{Tx: tx, tenant: db.tenant}, err
+}
+
+// tenantTx is a transaction scoped the same way as the tenantDB that began it.
+type tenantTx struct {
+	*sql.Tx
+	tenant string
+	// nested transactions are savepoints, committed or rolled back along with the transaction they're in
+	nested bool
+}
+
+func (tx tenantTx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return tx.Tx.ExecContext(ctx, query, withTenant(tx.tenant, args)...)
+}
+
+func (tx tenantTx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return tx.Tx.QueryContext(ctx, query, withTenant(tx.tenant, args)...)
+}
+
+func (tx tenantTx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return tx.Tx.QueryRowContext(ctx, query, withTenant(tx.tenant, args)...)
+}
+
+func (tx tenantTx) Commit() error {
+	if tx.nested {
+		_, err := tx.Tx.Exec(releaseSavepointNestedQuery)
+		return err
+	}
+	return tx.Tx.Commit()
+}
+
+func (tx tenantTx) Rollback() error {
+	if tx.nested {
+		if _, err := tx.Tx.Exec(rollbackToSavepointNestedQuery); err != nil {
+			return err
+		}
+		_, err := tx.Tx.Exec(releaseSavepointNestedQuery)
+		return err
+	}
+	return tx.Tx.Rollback()
+}
+
+// missedUpdate works out why an update made against version changed nothing, given versionQuery reading the version of
+// the row id: either the row is gone, giving notFound, or someone else updated it first.
+func (p Provider) missedUpdate(ctx context.Context, db querier, version int, notFound error, versionQuery, id string) error {
+	var current int
+	err := db.QueryRowContext(ctx, versionQuery, id).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notFound
+	} else if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting current version", slog.String("error", err.Error()))
+		return err
+	}
+	p.logger.LogAttrs(ctx, slog.LevelWarn, "Update was made against an old version", slog.Int("version", version), slog.Int("current_version", current))
+	return fmt.Errorf("%w: at version %d, not %d", backend.ErrVersionConflict, current, version)
+}
+
+func withTenant(tenant string, args []any) []any {
+	return append(args[:len(args):len(args)], sql.Named("tenant", tenant))
+}
+
+// SchemaVersion is the version of a database made with createStructureQuery and upgraded with every migration.
+const SchemaVersion = float64(len(migrations) + 1)
+
+// New opens the database in dbFile, creating it if needed, and upgrades it to expectedVersion, usually SchemaVersion.
 func New(logger *slog.Logger, dbFile string, expectedVersion float64) (Provider, error) {
 	l := logger.With(slog.String("source", "sqlite3_backend"))
 	b := Provider{
 		logger: l,
-		Db:     nil,
+		Db:     tenantDB{tenant: types.DefaultTenantID},
 	}
 	l.LogAttrs(context.Background(), slog.LevelInfo, "Connecting to database...")
 	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_foreign_keys=on", dbFile))
@@ -36,20 +148,66 @@ func New(logger *slog.Logger, dbFile string, expectedVersion float64) (Provider,
 			return Provider{}, err
 		}
 		l.LogAttrs(context.Background(), slog.LevelInfo, "Successfully created database structure")
+		version = 1
 	} else if err != nil {
 		l.LogAttrs(context.Background(), slog.LevelError, "Error checking database", slog.String("error", err.Error()))
 		return Provider{}, err
 	}
-	if version != expectedVersion {
-		l.LogAttrs(context.Background(), slog.LevelWarn, "Different Db version detected vs expected, upgrades not implemented yet")
-		b.Db = db
-		return b, nil
+	if version > expectedVersion {
+		err = fmt.Errorf("database is at version %v but only version %v is expected, is this instance out of date?", version, expectedVersion)
+		l.LogAttrs(context.Background(), slog.LevelError, "Error checking database", slog.String("error", err.Error()))
+		db.Close()
+		return Provider{}, err
+	}
+	if version < expectedVersion {
+		l.LogAttrs(context.Background(), slog.LevelInfo, "Upgrading database...", slog.Float64("version", version), slog.Float64("expected_version", expectedVersion))
+		if err = migrate(context.Background(), db, version, expectedVersion); err != nil {
+			l.LogAttrs(context.Background(), slog.LevelError, "Error upgrading database", slog.String("error", err.Error()))
+			db.Close()
+			return Provider{}, err
+		}
+		l.LogAttrs(context.Background(), slog.LevelInfo, "Successfully upgraded database")
 	}
 	l.LogAttrs(context.Background(), slog.LevelInfo, "Found correct structure and version")
-	b.Db = db
+	b.Db.DB = db
 	return b, nil
 }
 
+// ForTenant returns a provider sharing the same database that only sees the given tenant's rows.
+func (p Provider) ForTenant(tenantID string) Provider {
+	p.logger = p.logger.With(slog.String("tenant_id", tenantID))
+	p.Db.tenant = tenantID
+	return p
+}
+
+// Scoped is ForTenant for the backend, which keeps members, qualifications and requirements behind separate providers.
+func (p Provider) Scoped(tenantID string) (backend.MemberProvider, backend.QualificationProvider, backend.RequirementProvider) {
+	scoped := p.ForTenant(tenantID)
+	return scoped, scoped, scoped
+}
+
+// WithTx runs fn against a copy of the provider bound to one transaction, see backend.Transactor.
+func (p Provider) WithTx(ctx context.Context, fn func(tx backend.Providers) error) error {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error starting transaction", slog.String("error", err.Error()))
+		return err
+	}
+	bound := p
+	bound.Db.tx = tx.Tx
+	if err = fn(backend.Providers{Members: bound, Qualifications: bound, Requirements: bound}); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", rollbackErr.Error()))
+		}
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
 func checkDB(db *sql.DB) (float64, error) {
 	rows, err := db.Query("SELECT * FROM versions;")
 	if err != nil {
@@ -75,8 +233,58 @@ func checkDB(db *sql.DB) (float64, error) {
 
 func createDBStructure(db *sql.DB) error {
 	_, err := db.Exec(createStructureQuery)
+	return err
+}
+
+// migrate applies the migrations taking the database from version to target in one transaction. It runs with foreign
+// keys off so migrations can rebuild tables, checking them before it commits instead.
+func migrate(ctx context.Context, db *sql.DB, version, target float64) error {
+	if target > SchemaVersion {
+		return fmt.Errorf("no migrations up to version %v, the latest is %v", target, SchemaVersion)
+	}
+	// Foreign keys can't be turned off inside a transaction, or for anything but a single connection
+	conn, err := db.Conn(ctx)
 	if err != nil {
 		return err
 	}
+	defer conn.Close()
+	if _, err = conn.ExecContext(ctx, disableForeignKeysQuery); err != nil {
+		return fmt.Errorf("error disabling foreign keys: %w", err)
+	}
+	defer conn.ExecContext(ctx, enableForeignKeysQuery)
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for v := int(version); v < int(target); v++ {
+		if _, err = tx.ExecContext(ctx, migrations[v-1]); err != nil {
+			return fmt.Errorf("error migrating to version %d: %w", v+1, err)
+		}
+		if _, err = tx.ExecContext(ctx, insertVersionQuery, v+1); err != nil {
+			return fmt.Errorf("error recording version %d: %w", v+1, err)
+		}
+	}
+	rows, err := tx.QueryContext(ctx, foreignKeyCheckQuery)
+	if err != nil {
+		return fmt.Errorf("error checking foreign keys: %w", err)
+	}
+	violated := rows.Next()
+	rows.Close()
+	if violated {
+		return errors.New("migrations left rows referencing missing rows")
+	}
+	return tx.Commit()
+}
+
+// seedRolePermissions gives a new tenant the default permissions for every role.
+func seedRolePermissions(ctx context.Context, tx tenantTx) error {
+	for role, permissions := range types.DefaultRolePermissions {
+		for _, permission := range permissions {
+			if _, err := tx.ExecContext(ctx, insertRolePermissionQuery, role, permission); err != nil {
+				return fmt.Errorf("error seeding role permissions: %w", err)
+			}
+		}
+	}
 	return nil
 }
@@ -0,0 +1,139 @@
+package sqlite_test
+
+import (
+	"PORTal/backend"
+	"PORTal/providers/providertest"
+	"PORTal/providers/sqlite"
+	"PORTal/testutils"
+	"PORTal/types"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	providertest.Run(t, func(t *testing.T) providertest.Provider {
+		dbFile := fmt.Sprintf("%s.db", uuid.NewString())
+		provider, err := sqlite.New(slog.Default(), dbFile, sqlite.SchemaVersion)
+		if err != nil {
+			t.Fatalf("Error creating provider for tests: %s", err.Error())
+		}
+		t.Cleanup(func() {
+			provider.Db.Close()
+			os.Remove(dbFile)
+		})
+		return provider
+	})
+}
+
+// Sizes of the unit the benchmarks read from, roughly a squadron's worth of training records.
+const (
+	benchmarkMembers        = 200
+	benchmarkQualifications = 80
+	benchmarkRequirements   = 400
+	benchmarkReferences     = 20
+	// benchmarkHeld is how many qualifications each member holds
+	benchmarkHeld = 25
+)
+
+// newBenchmarkProvider seeds a provider with the benchmark unit. Each qualification has five initial requirements, two
+// of which recur, and every fourth qualification requires the one before it.
+func newBenchmarkProvider(b *testing.B) (sqlite.Provider, []types.Member, []types.Qualification) {
+	b.Helper()
+	ctx := context.Background()
+	dbFile := fmt.Sprintf("%s.db", uuid.NewString())
+	provider, err := sqlite.New(slog.New(slog.NewTextHandler(io.Discard, nil)), dbFile, sqlite.SchemaVersion)
+	if err != nil {
+		b.Fatalf("Error creating provider for benchmarks: %s", err.Error())
+	}
+	b.Cleanup(func() {
+		provider.Db.Close()
+		os.Remove(dbFile)
+	})
+	members := make([]types.Member, benchmarkMembers)
+	quals := make([]types.Qualification, benchmarkQualifications)
+	err = provider.WithTx(ctx, func(tx backend.Providers) error {
+		refs := make([]types.Reference, benchmarkReferences)
+		for i := range refs {
+			refs[i] = testutils.RandomReference()
+			refs[i].ID = uuid.NewString()
+			if err := tx.Requirements.AddReference(ctx, refs[i]); err != nil {
+				return err
+			}
+		}
+		reqs := make([]types.Requirement, benchmarkRequirements)
+		for i := range reqs {
+			reqs[i] = testutils.RandomRequirement(refs[i%len(refs)])
+			if err := tx.Requirements.AddRequirement(ctx, reqs[i]); err != nil {
+				return err
+			}
+		}
+		perQual := benchmarkRequirements / benchmarkQualifications
+		for i := range quals {
+			quals[i] = testutils.RandomQualification()
+			quals[i].ID = uuid.NewString()
+			quals[i].InitialRequirements = reqs[i*perQual : (i+1)*perQual]
+			quals[i].RecurringRequirements = reqs[i*perQual : i*perQual+2]
+			if i%4 == 3 {
+				quals[i].Prerequisites = []string{quals[i-1].ID}
+			}
+			if err := tx.Qualifications.AddQualification(ctx, quals[i]); err != nil {
+				return err
+			}
+		}
+		var pairs []types.MemberQualificationPair
+		for i := range members {
+			members[i] = testutils.RandomMember(false)
+			members[i].ID = uuid.NewString()
+			if err := tx.Members.AddMember(ctx, members[i]); err != nil {
+				return err
+			}
+			for j := range benchmarkHeld {
+				pairs = append(pairs, types.MemberQualificationPair{MemberID: members[i].ID, QualificationID: quals[(i+j)%len(quals)].ID})
+			}
+		}
+		_, err := tx.Members.AssignMemberQualifications(ctx, pairs)
+		return err
+	})
+	if err != nil {
+		b.Fatalf("Error seeding provider for benchmarks: %s", err.Error())
+	}
+	return provider, members, quals
+}
+
+func BenchmarkGetAllQualifications(b *testing.B) {
+	ctx := context.Background()
+	provider, _, _ := newBenchmarkProvider(b)
+	b.ResetTimer()
+	for range b.N {
+		if quals, err := provider.GetAllQualifications(ctx); err != nil || len(quals) != benchmarkQualifications {
+			b.Fatalf("Expected %d qualifications, got: %d, %v", benchmarkQualifications, len(quals), err)
+		}
+	}
+}
+
+func BenchmarkGetQualification(b *testing.B) {
+	ctx := context.Background()
+	provider, _, quals := newBenchmarkProvider(b)
+	b.ResetTimer()
+	for i := range b.N {
+		if _, err := provider.GetQualification(ctx, quals[i%len(quals)].ID); err != nil {
+			b.Fatalf("Error getting qualification: %s", err.Error())
+		}
+	}
+}
+
+func BenchmarkGetMemberQualifications(b *testing.B) {
+	ctx := context.Background()
+	provider, members, _ := newBenchmarkProvider(b)
+	b.ResetTimer()
+	for i := range b.N {
+		if quals, err := provider.GetMemberQualifications(ctx, members[i%len(members)].ID); err != nil || len(quals) != benchmarkHeld {
+			b.Fatalf("Expected %d qualifications, got: %d, %v", benchmarkHeld, len(quals), err)
+		}
+	}
+}
@@ -9,204 +9,326 @@ import (
 	"strings"
 )
 
-func (p Provider) AddQualification(q types.Qualification) error {
-	_, err := p.Db.Exec(insertQualificationQuery, q.ID, q.Name, q.Notes, q.Expires, q.ExpirationDays)
+func (p Provider) AddQualification(ctx context.Context, q types.Qualification) error {
+	tx, err := p.Db.BeginTx(ctx, nil)
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error inserting qualification into database", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for AddQualification", slog.String("error", err.Error()))
+		return err
+	}
+	_, err = tx.ExecContext(ctx, insertQualificationQuery, q.ID, q.Name, q.Notes, q.Expires, q.ExpirationDays)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting qualification into database", slog.String("error", err.Error()))
+		tx.Rollback()
 		return err
 	}
 	for _, initialRequirement := range q.InitialRequirements {
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Adding initial requirement to Qualification",
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding initial requirement to Qualification",
 			slog.String("qualification_id", q.ID), slog.String("requirement_id", initialRequirement.ID))
-		_, err = p.Db.Exec(insertQualificationInitialRequirementQuery, q.ID, initialRequirement.ID)
+		_, err = tx.ExecContext(ctx, insertQualificationInitialRequirementQuery, q.ID, initialRequirement.ID)
 		if err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error adding requirement to qualification", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error adding requirement to qualification", slog.String("error", err.Error()))
+			tx.Rollback()
 			return err
 		}
 	}
 	for _, recurringRequirement := range q.RecurringRequirements {
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Adding recurring requirement to Qualification",
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding recurring requirement to Qualification",
 			slog.String("qualification_id", q.ID), slog.String("requirement_id", recurringRequirement.ID))
-		_, err = p.Db.Exec(insertQualificationRecurringRequirementQuery, q.ID, recurringRequirement.ID)
+		_, err = tx.ExecContext(ctx, insertQualificationRecurringRequirementQuery, q.ID, recurringRequirement.ID)
 		if err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error adding requirement to qualification", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error adding requirement to qualification", slog.String("error", err.Error()))
+			tx.Rollback()
 			return err
 		}
 	}
+	for _, prerequisiteID := range q.Prerequisites {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding prerequisite to Qualification",
+			slog.String("qualification_id", q.ID), slog.String("prerequisite_id", prerequisiteID))
+		_, err = tx.ExecContext(ctx, insertQualificationPrerequisiteQuery, q.ID, prerequisiteID)
+		if err != nil && strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+			p.logger.LogAttrs(ctx, slog.LevelWarn, "Prerequisite qualification not found")
+			tx.Rollback()
+			return fmt.Errorf("%w: %s", backend.ErrQualificationNotFound, prerequisiteID)
+		}
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error adding prerequisite to qualification", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing qualification", slog.String("error", err.Error()))
+		return err
+	}
 	return nil
 }
 
-func (p Provider) GetQualification(id string) (types.Qualification, error) {
-	row := p.Db.QueryRow(getQualificationQuery, id)
-	var q types.Qualification
-	err := row.Scan(&q.ID, &q.Name, &q.Notes, &q.Expires, &q.ExpirationDays)
-	if err != nil && strings.Contains(err.Error(), "no rows in result set") {
-		p.logger.LogAttrs(context.Background(), slog.LevelWarn, "Could not find qualification with given id")
-		return types.Qualification{}, backend.ErrQualificationNotFound
+// qualificationQueries read the qualifications in some scope, then their requirements and prerequisites, so loading
+// them takes the same number of queries however many there are.
+type qualificationQueries struct {
+	qualifications, initialRequirements, recurringRequirements, prerequisites string
+}
+
+var (
+	qualificationByID = qualificationQueries{
+		qualifications:        getQualificationQuery,
+		initialRequirements:   getInitialRequirementsQuery,
+		recurringRequirements: getRecurringRequirementsQuery,
+		prerequisites:         getPrerequisitesQuery,
 	}
-	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Error scanning qualification into struct", slog.String("error", err.Error()))
-		return types.Qualification{}, err
+	allQualifications = qualificationQueries{
+		qualifications:        getAllQualificationsQuery,
+		initialRequirements:   getAllInitialRequirementsQuery,
+		recurringRequirements: getAllRecurringRequirementsQuery,
+		prerequisites:         getAllPrerequisitesQuery,
+	}
+	memberQualifications = qualificationQueries{
+		qualifications:        getMemberQualificationsQuery,
+		initialRequirements:   getMemberInitialRequirementsQuery,
+		recurringRequirements: getMemberRecurringRequirementsQuery,
+		prerequisites:         getMemberQualificationPrerequisitesQuery,
 	}
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Retrieving initial requirements")
-	rows, err := p.Db.Query(getInitialRequirementIdsQuery, q.ID)
+)
+
+// loadQualifications runs queries with the same arguments and assembles the qualifications they return.
+func (p Provider) loadQualifications(ctx context.Context, queries qualificationQueries, args ...any) ([]types.Qualification, error) {
+	rows, err := p.Db.QueryContext(ctx, queries.qualifications, args...)
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error getting initial requirement IDs for qualification", slog.String("error", err.Error()))
-		return types.Qualification{}, err
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting qualifications from database", slog.String("error", err.Error()))
+		return nil, err
 	}
-	var initialRequirements []types.Requirement
+	var quals []types.Qualification
+	index := map[string]int{}
 	for rows.Next() {
-		var id string
-		err = rows.Scan(&id)
-		if err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error scanning initial requirement ID string", slog.String("error", err.Error()))
-			return types.Qualification{}, err
-		}
-		req, err := p.GetRequirement(id)
-		if err != nil {
-			return types.Qualification{}, err
+		var q types.Qualification
+		if err = rows.Scan(&q.ID, &q.Name, &q.Notes, &q.Expires, &q.ExpirationDays, &q.Version); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning qualification into struct", slog.String("error", err.Error()))
+			rows.Close()
+			return nil, err
 		}
-		initialRequirements = append(initialRequirements, req)
+		index[q.ID] = len(quals)
+		quals = append(quals, q)
 	}
 	rows.Close()
-	q.InitialRequirements = initialRequirements
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Retreiving recurring requirements")
-	rows, err = p.Db.Query(getRecurringRequirementIdsQuery, q.ID)
+	if len(quals) == 0 {
+		return nil, nil
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Retrieving initial requirements")
+	initial, err := p.loadQualificationRequirements(ctx, queries.initialRequirements, args...)
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error getting recurring requirement IDs for qualification", slog.String("error", err.Error()))
-		return types.Qualification{}, err
+		return nil, err
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Retrieving recurring requirements")
+	recurring, err := p.loadQualificationRequirements(ctx, queries.recurringRequirements, args...)
+	if err != nil {
+		return nil, err
 	}
-	var recurringRequirements []types.Requirement
+	for i := range quals {
+		quals[i].InitialRequirements = initial[quals[i].ID]
+		quals[i].RecurringRequirements = recurring[quals[i].ID]
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Retrieving prerequisites")
+	rows, err = p.Db.QueryContext(ctx, queries.prerequisites, args...)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting prerequisites for qualifications", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	var qualificationID, prerequisiteID string
 	for rows.Next() {
-		var id string
-		err = rows.Scan(&id)
-		if err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error scanning recurring requirement ID string", slog.String("error", err.Error()))
-			return types.Qualification{}, err
+		if err = rows.Scan(&qualificationID, &prerequisiteID); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning prerequisite", slog.String("error", err.Error()))
+			return nil, err
 		}
-		req, err := p.GetRequirement(id)
-		if err != nil {
-			return types.Qualification{}, err
+		if i, ok := index[qualificationID]; ok {
+			quals[i].Prerequisites = append(quals[i].Prerequisites, prerequisiteID)
 		}
-		recurringRequirements = append(recurringRequirements, req)
 	}
-	rows.Close()
-	q.RecurringRequirements = recurringRequirements
-	return q, nil
+	return quals, nil
 }
 
-func (p Provider) GetAllQualifications() ([]types.Qualification, error) {
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting all qualification IDs from database")
-	rows, err := p.Db.Query(getAllQualificationIDsQuery)
+// loadQualificationRequirements returns the requirements linked by query, keyed by qualification ID.
+func (p Provider) loadQualificationRequirements(ctx context.Context, query string, args ...any) (map[string][]types.Requirement, error) {
+	rows, err := p.Db.QueryContext(ctx, query, args...)
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error getting all qualifications from database", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting requirements for qualifications", slog.String("error", err.Error()))
 		return nil, err
 	}
-	var quals []types.Qualification
+	defer rows.Close()
+	reqs := map[string][]types.Requirement{}
+	var qualificationID string
 	for rows.Next() {
-		var id string
-		err := rows.Scan(&id)
+		var r types.Requirement
+		err = rows.Scan(&qualificationID, &r.ID, &r.Name, &r.Description, &r.Notes, &r.DaysValidFor, &r.Version, &r.Reference.ID, &r.Reference.Name, &r.Reference.Volume, &r.Reference.Paragraph, &r.Reference.Version)
 		if err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error scanning id into string", slog.String("error", err.Error()))
-			continue
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning requirement into struct", slog.String("error", err.Error()))
+			return nil, err
 		}
-		qual, err := p.GetQualification(id)
-		if err != nil {
+		reqs[qualificationID] = append(reqs[qualificationID], r)
+	}
+	return reqs, nil
+}
+
+func (p Provider) GetQualification(ctx context.Context, id string) (types.Qualification, error) {
+	quals, err := p.loadQualifications(ctx, qualificationByID, id)
+	if err != nil {
+		return types.Qualification{}, err
+	}
+	if len(quals) == 0 {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Could not find qualification with given id")
+		return types.Qualification{}, backend.ErrQualificationNotFound
+	}
+	return quals[0], nil
+}
+
+// GetPrerequisiteGraph returns the direct prerequisites of every qualification that has any, keyed by qualification ID.
+func (p Provider) GetPrerequisiteGraph(ctx context.Context) (map[string][]string, error) {
+	rows, err := p.Db.QueryContext(ctx, getAllPrerequisitesQuery)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting qualification prerequisites", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	graph := map[string][]string{}
+	var qualificationID, prerequisiteID string
+	for rows.Next() {
+		if err = rows.Scan(&qualificationID, &prerequisiteID); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning prerequisite", slog.String("error", err.Error()))
 			return nil, err
 		}
-		quals = append(quals, qual)
+		graph[qualificationID] = append(graph[qualificationID], prerequisiteID)
+	}
+	return graph, nil
+}
+
+func (p Provider) GetAllQualifications(ctx context.Context) ([]types.Qualification, error) {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Getting all qualifications from database")
+	quals, err := p.loadQualifications(ctx, allQualifications)
+	if err != nil {
+		return nil, err
 	}
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, fmt.Sprintf("Found %d qualifications in database", len(quals)))
+	p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Found %d qualifications in database", len(quals)))
 	return quals, nil
 }
 
-func (p Provider) UpdateQualification(q types.Qualification) error {
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Updating qualification", slog.Any("qualification", q))
-	tx, err := p.Db.Begin()
+// ListQualifications loads a page of qualifications the same way as GetAllQualifications, only for the qualifications
+// on the page.
+func (p Provider) ListQualifications(ctx context.Context, opts types.ListOptions) (types.Page[types.Qualification], error) {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Listing qualifications from database")
+	after, err := decodeCursor(opts, types.Qualification{})
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error creating transaction for UpdateQualification", slog.String("error", err.Error()))
+		return types.Page[types.Qualification]{}, err
 	}
-	res, err := tx.Exec(updateQualificationQuery, q.Name, q.Notes, q.Expires, q.ExpirationDays, q.ID)
+	q := listQuery{from: "qualification", tenant: "tenant_id"}
+	total, err := p.countRows(ctx, q)
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error updating qualification in database", slog.String("error", err.Error()))
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Rolling back transaction")
+		return types.Page[types.Qualification]{}, err
+	}
+	page, args := q.page("id", []string{"name", "id"}, opts, after)
+	orderBy := " ORDER BY name, id"
+	if opts.Descending {
+		orderBy = " ORDER BY name DESC, id DESC"
+	}
+	quals, err := p.loadQualifications(ctx, qualificationQueries{
+		qualifications:        "SELECT " + qualificationColumns + " FROM qualification WHERE id IN (" + page + ") AND tenant_id=$tenant" + orderBy,
+		initialRequirements:   "SELECT " + qualificationRequirementColumns + " FROM qualification_initial_requirement" + qualificationRequirementJoin + " WHERE l.qualification_id IN (" + page + ") AND l.tenant_id=$tenant ORDER BY r.name",
+		recurringRequirements: "SELECT " + qualificationRequirementColumns + " FROM qualification_recurring_requirement" + qualificationRequirementJoin + " WHERE l.qualification_id IN (" + page + ") AND l.tenant_id=$tenant ORDER BY r.name",
+		prerequisites:         "SELECT qualification_id, prerequisite_id FROM qualification_prerequisite WHERE qualification_id IN (" + page + ") AND tenant_id=$tenant ORDER BY prerequisite_id",
+	}, args...)
+	if err != nil {
+		return types.Page[types.Qualification]{}, err
+	}
+	return types.NewPage(quals, total, opts), nil
+}
+
+func (p Provider) UpdateQualification(ctx context.Context, q types.Qualification) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Updating qualification", slog.Any("qualification", q))
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for UpdateQualification", slog.String("error", err.Error()))
+	}
+	res, err := tx.ExecContext(ctx, updateQualificationQuery, q.Name, q.Notes, q.Expires, q.ExpirationDays, q.ID, q.Version)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error updating qualification in database", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
 		if err = tx.Rollback(); err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
 			return err
 		}
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully rolled back transaction")
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
 		return err
 	}
 	if count, _ := res.RowsAffected(); count == 0 {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Expected to update 1 qualification but got 0")
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Rolling back transaction")
+		p.logger.LogAttrs(ctx, slog.LevelError, "Expected to update 1 qualification but got 0")
+		missed := p.missedUpdate(ctx, tx, q.Version, backend.ErrQualificationNotFound, getQualificationVersionQuery, q.ID)
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
 		if err = tx.Rollback(); err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
 			return err
 		}
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully rolled back transaction")
-		return backend.ErrQualificationNotFound
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
+		return missed
 	}
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting existing initial requirement IDs")
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Getting existing initial requirement IDs")
 	var existingInitialIDs []string
-	rows, err := p.Db.Query(getInitialRequirementIdsQuery, q.ID)
+	rows, err := p.Db.QueryContext(ctx, getInitialRequirementIdsQuery, q.ID)
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error getting existing initial requirements for qualification", slog.String("error", err.Error()))
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Rolling back transaction")
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting existing initial requirements for qualification", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
 		if err = tx.Rollback(); err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
 			return err
 		}
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully rolled back transaction")
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
 		return err
 	}
 	var id string
 	for rows.Next() {
 		err = rows.Scan(&id)
 		if err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error scanning ID into string", slog.String("error", err.Error()))
-			p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Rolling back transaction")
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning ID into string", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
 			if err = tx.Rollback(); err != nil {
-				p.logger.LogAttrs(context.Background(), slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
 				return err
 			}
-			p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully rolled back transaction")
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
 			return err
 		}
 		existingInitialIDs = append(existingInitialIDs, id)
 	}
 	rows.Close()
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, fmt.Sprintf("Found %d existing initial requirements", len(existingInitialIDs)))
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Getting existing recurring requirement IDs")
+	p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Found %d existing initial requirements", len(existingInitialIDs)))
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Getting existing recurring requirement IDs")
 	var existingRecurringIds []string
-	rows, err = p.Db.Query(getRecurringRequirementIdsQuery, q.ID)
+	rows, err = p.Db.QueryContext(ctx, getRecurringRequirementIdsQuery, q.ID)
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error getting existing recurring requirements for qualification", slog.String("error", err.Error()))
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Rolling back transaction")
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting existing recurring requirements for qualification", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
 		if err = tx.Rollback(); err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
 			return err
 		}
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully rolled back transaction")
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
 		return err
 	}
 	for rows.Next() {
 		err = rows.Scan(&id)
 		if err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error scanning ID into string", slog.String("error", err.Error()))
-			p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Rolling back transaction")
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning ID into string", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
 			if err = tx.Rollback(); err != nil {
-				p.logger.LogAttrs(context.Background(), slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
 				return err
 			}
-			p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully rolled back transaction")
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
 			return err
 		}
 		existingRecurringIds = append(existingRecurringIds, id)
 	}
 	rows.Close()
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, fmt.Sprintf("Found %d existing recurring requirements", len(existingRecurringIds)))
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Determining initial qualifications to be removed")
+	p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Found %d existing recurring requirements", len(existingRecurringIds)))
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Determining initial qualifications to be removed")
 	var initialIdsToBeRemoved []string
 	for _, id := range existingInitialIDs {
 		found := false
@@ -219,8 +341,8 @@ func (p Provider) UpdateQualification(q types.Qualification) error {
 			initialIdsToBeRemoved = append(initialIdsToBeRemoved, id)
 		}
 	}
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, fmt.Sprintf("Initial requirements set to be removed: %s", initialIdsToBeRemoved))
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Determining recurring qualifications to be removed")
+	p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Initial requirements set to be removed: %s", initialIdsToBeRemoved))
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Determining recurring qualifications to be removed")
 	var recurringIdsToBeRemoved []string
 	for _, id := range existingRecurringIds {
 		found := false
@@ -233,116 +355,140 @@ func (p Provider) UpdateQualification(q types.Qualification) error {
 			recurringIdsToBeRemoved = append(recurringIdsToBeRemoved, id)
 		}
 	}
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, fmt.Sprintf("Recurring requirements set to be removed: %s", recurringIdsToBeRemoved))
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Removing initial requirements")
+	p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Recurring requirements set to be removed: %s", recurringIdsToBeRemoved))
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Removing initial requirements")
 	for _, id := range initialIdsToBeRemoved {
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, fmt.Sprintf("Removing initial requirement: %s", id))
-		_, err = tx.Exec(deleteQualificationInitialRequirementQuery, id)
+		p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Removing initial requirement: %s", id))
+		_, err = tx.ExecContext(ctx, deleteQualificationInitialRequirementQuery, id)
 		if err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error deleting initial requirement from qualification", slog.String("error", err.Error()))
-			p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Rolling back transaction")
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error deleting initial requirement from qualification", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
 			if err = tx.Rollback(); err != nil {
-				p.logger.LogAttrs(context.Background(), slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
 				return err
 			}
-			p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully rolled back transaction")
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
 			return err
 		}
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully removed initial requirement")
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully removed initial requirement")
 	}
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Removing recurring requirements")
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Removing recurring requirements")
 	for _, id := range recurringIdsToBeRemoved {
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, fmt.Sprintf("Removing recurring requirement: %s", id))
-		_, err = tx.Exec(deleteQualificationRecurringRequirementQuery, id)
+		p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Removing recurring requirement: %s", id))
+		_, err = tx.ExecContext(ctx, deleteQualificationRecurringRequirementQuery, id)
 		if err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error deleting recurring requirement from qualification", slog.String("error", err.Error()))
-			p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Rolling back transaction")
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error deleting recurring requirement from qualification", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
 			if err = tx.Rollback(); err != nil {
-				p.logger.LogAttrs(context.Background(), slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
 				return err
 			}
-			p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully rolled back transaction")
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
 			return err
 		}
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully removed recurring requirement")
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully removed recurring requirement")
 	}
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Adding new initial requirements")
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding new initial requirements")
 	for _, newReq := range q.InitialRequirements {
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, fmt.Sprintf("Adding initial requirement: %s", newReq.ID))
-		_, err := tx.Exec(insertQualificationInitialRequirementQuery, q.ID, newReq.ID)
+		p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Adding initial requirement: %s", newReq.ID))
+		_, err := tx.ExecContext(ctx, insertQualificationInitialRequirementQuery, q.ID, newReq.ID)
 		if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
-			p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Requirement already assigned to qualification, skipping")
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Requirement already assigned to qualification, skipping")
 			continue
 		}
 		if err != nil {
 			var errToReturn error
 			if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
-				p.logger.LogAttrs(context.Background(), slog.LevelError, "Initial requirement to be added not found")
+				p.logger.LogAttrs(ctx, slog.LevelError, "Initial requirement to be added not found")
 				errToReturn = fmt.Errorf("%w: %s", backend.ErrRequirementNotFound, newReq.ID)
 			} else {
-				p.logger.LogAttrs(context.Background(), slog.LevelError, "Error adding new initial requirement to query", slog.String("error", err.Error()))
-				p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Rolling back transaction")
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error adding new initial requirement to query", slog.String("error", err.Error()))
+				p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
 				errToReturn = err
 			}
 			if err := tx.Rollback(); err != nil {
-				p.logger.LogAttrs(context.Background(), slog.LevelError, "Error rolling back transaction",
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction",
 					slog.String("error", err.Error()), slog.String("original_error", err.Error()))
 				return err
 			}
-			p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully rolled back transaction")
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
 			return errToReturn
 		}
 	}
 	for _, newReq := range q.RecurringRequirements {
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, fmt.Sprintf("Adding recurring requirement: %s", newReq.ID))
-		_, err := tx.Exec(insertQualificationRecurringRequirementQuery, q.ID, newReq.ID)
+		p.logger.LogAttrs(ctx, slog.LevelInfo, fmt.Sprintf("Adding recurring requirement: %s", newReq.ID))
+		_, err := tx.ExecContext(ctx, insertQualificationRecurringRequirementQuery, q.ID, newReq.ID)
 		if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
-			p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Requirement already assigned to qualification, skipping")
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Requirement already assigned to qualification, skipping")
 			continue
 		}
 		if err != nil {
 			var errToReturn error
 			if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
-				p.logger.LogAttrs(context.Background(), slog.LevelError, "Recurring requirement to be added not found")
+				p.logger.LogAttrs(ctx, slog.LevelError, "Recurring requirement to be added not found")
 				errToReturn = fmt.Errorf("%w: %s", backend.ErrRequirementNotFound, newReq.ID)
 			} else {
-				p.logger.LogAttrs(context.Background(), slog.LevelError, "Error adding new recurring requirement to query", slog.String("error", err.Error()))
-				p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Rolling back transaction")
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error adding new recurring requirement to query", slog.String("error", err.Error()))
+				p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
 				errToReturn = err
 			}
 			if err := tx.Rollback(); err != nil {
-				p.logger.LogAttrs(context.Background(), slog.LevelError, "Error rolling back transaction",
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction",
 					slog.String("error", err.Error()), slog.String("original_error", err.Error()))
 				return err
 			}
-			p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully rolled back transaction")
+			p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
+			return errToReturn
+		}
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Replacing prerequisites")
+	if _, err = tx.ExecContext(ctx, deleteQualificationPrerequisitesQuery, q.ID); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error removing existing prerequisites", slog.String("error", err.Error()))
+		if rbErr := tx.Rollback(); rbErr != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", rbErr.Error()))
+		}
+		return err
+	}
+	for _, prerequisiteID := range q.Prerequisites {
+		_, err = tx.ExecContext(ctx, insertQualificationPrerequisiteQuery, q.ID, prerequisiteID)
+		if err != nil {
+			errToReturn := err
+			if strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+				p.logger.LogAttrs(ctx, slog.LevelWarn, "Prerequisite qualification not found")
+				errToReturn = fmt.Errorf("%w: %s", backend.ErrQualificationNotFound, prerequisiteID)
+			} else {
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error adding prerequisite to qualification", slog.String("error", err.Error()))
+			}
+			if rbErr := tx.Rollback(); rbErr != nil {
+				p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", rbErr.Error()))
+			}
 			return errToReturn
 		}
 	}
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Committing transaction")
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Committing transaction")
 	if err = tx.Commit(); err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Rolling back transaction")
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Rolling back transaction")
 		if err = tx.Rollback(); err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error rolling back transaction", slog.String("error", err.Error()))
 			return err
 		}
-		p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully rolled back transaction")
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully rolled back transaction")
 		return err
 	}
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Successfully committed transaction")
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Successfully committed transaction")
 	return nil
 }
 
-func (p Provider) DeleteQualification(id string) error {
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Deleting qualification from database", slog.String("id", id))
-	res, err := p.Db.Exec(deleteQualificationQuery, id)
+func (p Provider) DeleteQualification(ctx context.Context, id string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting qualification from database", slog.String("id", id))
+	res, err := p.Db.ExecContext(ctx, deleteQualificationQuery, id)
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "error deleting qualification from database", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelError, "error deleting qualification from database", slog.String("error", err.Error()))
 		return err
 	}
 	if count, _ := res.RowsAffected(); count != 1 {
-		p.logger.LogAttrs(context.Background(), slog.LevelWarn, "no qualification with that ID exists to be deleted")
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "no qualification with that ID exists to be deleted")
 		return backend.ErrQualificationNotFound
 	}
 	return nil
@@ -1,5 +1,27 @@
 package sqlite
 
+// migrations upgrade a database made with createStructureQuery, which is version 1, in order. Applying migrations[i]
+// brings it to version i+2. Applied migrations must never change, add a new one instead.
+var migrations = [...]string{
+	addCertificateIDQuery,
+	addAPITokenQuery,
+	addRolesQuery,
+	addCompletionQuery,
+	addPrerequisiteQuery,
+	addWaiverQuery,
+	addDutyPositionQuery,
+	addEmailQuery,
+	addImportBatchQuery,
+	addQualificationHistoryQuery,
+	addArchiveQuery,
+	addUnitQuery,
+	addTenantQuery,
+	addVersionColumnsQuery,
+	addTenantLinksQuery,
+	addQualificationSourceQuery,
+	addMemberTransferQuery,
+}
+
 const (
 	createStructureQuery = `CREATE TABLE versions(version float PRIMARY KEY);
 CREATE TABLE member(
@@ -89,48 +111,899 @@ CREATE TABLE reference(
 
 INSERT INTO versions VALUES(1);`
 
-	insertMemberQuery           = "INSERT INTO member(id, first_name, last_name, rank, user_name, supervisor_id, admin, hash) VALUES($1, $2, $3, $4, $5, $6, $7, $8);"
-	getMemberQuery              = "SELECT * FROM member WHERE id=$1;"
-	getMemberByUsernameQuery    = "SELECT * FROM member where user_name=$1;"
-	getAllMembersQuery          = "SELECT * FROM member;"
-	getSubordinatesQuery        = "SELECT * FROM member WHERE supervisor_id=$1;"
-	updateMemberQuery           = "UPDATE member SET first_name=$1, last_name=$2, rank=$3, supervisor_id=$4, admin=$5, hash=$6 WHERE ID=$7;"
-	deleteMemberQuery           = "DELETE FROM member WHERE id=$1;"
-	deleteMemberByUsernameQuery = "DELETE FROM member WHERE user_name=$1;"
-
-	insertQualificationQuery                     = "INSERT INTO qualification(id, name, notes, expires, expiration_days) VALUES($1, $2, $3, $4, $5);"
-	getQualificationQuery                        = "SELECT * FROM qualification WHERE id=$1;"
-	getAllQualificationIDsQuery                  = "SELECT id FROM qualification;"
-	updateQualificationQuery                     = "UPDATE qualification SET name=$1, notes=$2, expires=$3, expiration_days=$4 WHERE ID=$6;"
-	deleteQualificationQuery                     = "DELETE FROM qualification WHERE id=$1;"
-	insertQualificationInitialRequirementQuery   = "INSERT INTO qualification_initial_requirement(qualification_id, requirement_id) VALUES($1, $2);"
-	insertQualificationRecurringRequirementQuery = "INSERT INTO qualification_recurring_requirement(qualification_id, requirement_id) VALUES($1, $2);"
-	getInitialRequirementIdsQuery                = "SELECT requirement_id FROM qualification_initial_requirement WHERE qualification_id=$1;"
-	getRecurringRequirementIdsQuery              = "SELECT requirement_id FROM qualification_recurring_requirement WHERE qualification_id=$1;"
-	deleteQualificationRecurringRequirementQuery = "DELETE FROM qualification_recurring_requirement WHERE requirement_id=$1;"
-	deleteQualificationInitialRequirementQuery   = "DELETE FROM qualification_initial_requirement WHERE requirement_id=$1;"
-
-	addMemberQualificationQuery    = "INSERT INTO member_qualification(member_id, qualification_id) VALUES($1, $2);"
-	checkMemberQualificationQuery  = "SELECT COUNT(*) FROM member_qualification WHERE member_id=$1 AND qualification_id=$2;"
-	getMemberQualificationIDsQuery = "SELECT qualification_id FROM member_qualification WHERE member_id=$1;"
-	removeMemberQualificationQuery = "DELETE FROM member_qualification WHERE member_id=$1 AND qualification_ID=$2;"
-
-	addRequirementQuery                  = "INSERT INTO requirement(id, name, description, notes, days_valid_for, reference_id) VALUES($1, $2, $3, $4, $5, $6);"
-	getRequirementQuery                  = "SELECT * FROM requirement r FULL JOIN reference re ON r.reference_id = re.id WHERE r.id = $1;"
-	getAllRequirementsQuery              = "SELECT * FROM requirement r FULL JOIN reference re ON r.reference_id = re.id;"
-	getQualificationsForRequirementQuery = "SELECT qualification_id FROM qualification_initial_requirement  WHERE requirement_id=$1 UNION SELECT qualification_id FROM qualification_recurring_requirement WHERE requirement_id=$1;"
-	updateRequirementQuery               = "UPDATE requirement SET name=$1, description=$2, notes=$3, days_valid_for=$4, reference_id=$5 WHERE id=$6;"
-	deleteRequirementQuery               = "DELETE FROM requirement WHERE id=$1;"
-
-	addReferenceQuery    = "INSERT INTO reference(id, name, volume, paragraph) VALUES($1, $2, $3, $4);"
-	getReferenceQuery    = "SELECT * FROM reference WHERE id=$1;"
-	getReferencesQuery   = "SELECT * FROM reference;"
-	updateReferenceQuery = "UPDATE reference SET name=$1, volume=$2, paragraph=$3 WHERE id=$4;"
-	deleteReferenceQuery = "DELETE FROM reference WHERE id=$1;"
-
-	insertSessionQuery       = "INSERT INTO session(id, expiration, user_agent) VALUES($1, $2, $3);"
-	insertMemberSessionQuery = "INSERT INTO member_session(member_id, session_id) VALUES($1, $2);"
-	getSessionQuery          = "SELECT * FROM session WHERE id=$1;"
-	deleteSessionQuery       = "DELETE FROM session WHERE id=$1;"
-	getMemberSessionQuery    = "SELECT * FROM member_session WHERE member_id=$1 AND session_id=$2;"
+	addCertificateIDQuery = `ALTER TABLE member ADD COLUMN certificate_id string;
+CREATE UNIQUE INDEX member_certificate_id ON member(certificate_id);`
+
+	addAPITokenQuery = `CREATE TABLE api_token(
+    id string PRIMARY KEY,
+    member_id string,
+    name string,
+    scope string,
+    hash string UNIQUE,
+    created datetime,
+    last_used datetime,
+    FOREIGN KEY (member_id) REFERENCES member(id) ON DELETE CASCADE
+);`
+
+	// addRolesQuery gives every member the role matching their admin flag and every role the permissions it starts with.
+	// member is rebuilt to keep role next to admin, where the member queries read it.
+	addRolesQuery = `CREATE TABLE role_member(
+    id string PRIMARY KEY,
+    first_name string,
+    last_name string,
+    rank string,
+    user_name string UNIQUE,
+    supervisor_id string,
+    admin integer,
+    role string,
+    hash string,
+    certificate_id string,
+    FOREIGN KEY (supervisor_id) REFERENCES member(id) ON DELETE SET NULL
+);
+INSERT INTO role_member(id, first_name, last_name, rank, user_name, supervisor_id, admin, role, hash, certificate_id) SELECT id, first_name, last_name, rank, user_name, supervisor_id, admin, CASE WHEN admin THEN 'admin' ELSE 'member' END, hash, certificate_id FROM member;
+DROP TABLE member;
+ALTER TABLE role_member RENAME TO member;
+CREATE UNIQUE INDEX member_certificate_id ON member(certificate_id);
+
+CREATE TABLE role_permission(
+    role string,
+    permission string,
+    PRIMARY KEY (role, permission)
+);
+INSERT INTO role_permission(role, permission) VALUES
+    ('admin', 'members:manage'),
+    ('admin', 'members:delete'),
+    ('admin', 'qualifications:manage'),
+    ('admin', 'qualifications:assign'),
+    ('admin', 'completions:sign_off'),
+    ('admin', 'reports:view'),
+    ('admin', 'roles:manage'),
+    ('training_manager', 'members:manage'),
+    ('training_manager', 'qualifications:manage'),
+    ('training_manager', 'qualifications:assign'),
+    ('training_manager', 'completions:sign_off'),
+    ('training_manager', 'reports:view'),
+    ('supervisor', 'qualifications:assign'),
+    ('supervisor', 'reports:view'),
+    ('trainer', 'completions:sign_off');`
+
+	addCompletionQuery = `CREATE TABLE requirement_certifier(
+    requirement_id string,
+    member_id string,
+    PRIMARY KEY (requirement_id, member_id),
+    FOREIGN KEY (requirement_id) REFERENCES requirement(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES member(id) ON DELETE CASCADE
+);
+
+CREATE TABLE completion(
+    id string PRIMARY KEY,
+    member_id string,
+    requirement_id string,
+    trainer_id string,
+    certifier_id string,
+    submitted_by string,
+    status string,
+    completed_date datetime,
+    submitted datetime,
+    reviewed datetime,
+    comments string,
+    FOREIGN KEY (member_id) REFERENCES member(id) ON DELETE CASCADE,
+    FOREIGN KEY (requirement_id) REFERENCES requirement(id) ON DELETE CASCADE,
+    FOREIGN KEY (trainer_id) REFERENCES member(id) ON DELETE SET NULL,
+    FOREIGN KEY (certifier_id) REFERENCES member(id) ON DELETE SET NULL
+);`
+
+	addPrerequisiteQuery = `CREATE TABLE qualification_prerequisite(
+    qualification_id string,
+    prerequisite_id string,
+    PRIMARY KEY (qualification_id, prerequisite_id),
+    FOREIGN KEY (qualification_id) REFERENCES qualification(id) ON DELETE CASCADE,
+    FOREIGN KEY (prerequisite_id) REFERENCES qualification(id) ON DELETE CASCADE
+);`
+
+	// addWaiverQuery also gives admins and training managers, who start with it, the permission to grant waivers.
+	addWaiverQuery = `CREATE TABLE waiver(
+    id string PRIMARY KEY,
+    member_id string,
+    requirement_id string,
+    qualification_id string,
+    kind string,
+    approver_id string,
+    reason string,
+    start_date datetime,
+    end_date datetime,
+    created datetime,
+    memo_name string,
+    memo_type string,
+    memo blob,
+    FOREIGN KEY (member_id) REFERENCES member(id) ON DELETE CASCADE,
+    FOREIGN KEY (requirement_id) REFERENCES requirement(id) ON DELETE CASCADE,
+    FOREIGN KEY (qualification_id) REFERENCES qualification(id) ON DELETE CASCADE,
+    FOREIGN KEY (approver_id) REFERENCES member(id) ON DELETE SET NULL
+);
+
+CREATE TABLE audit_log(
+    id string PRIMARY KEY,
+    actor_id string,
+    action string,
+    entity_type string,
+    entity_id string,
+    time datetime,
+    details string
+);
+INSERT INTO role_permission(role, permission) VALUES ('admin', 'waivers:grant'), ('training_manager', 'waivers:grant');`
+
+	addDutyPositionQuery = `CREATE TABLE duty_position(
+    id string PRIMARY KEY,
+    name string UNIQUE,
+    description string
+);
+
+CREATE TABLE duty_position_qualification(
+    position_id string,
+    qualification_id string,
+    PRIMARY KEY (position_id, qualification_id),
+    FOREIGN KEY (position_id) REFERENCES duty_position(id) ON DELETE CASCADE,
+    FOREIGN KEY (qualification_id) REFERENCES qualification(id) ON DELETE CASCADE
+);
+
+CREATE TABLE member_duty_position(
+    member_id string,
+    position_id string,
+    PRIMARY KEY (member_id, position_id),
+    FOREIGN KEY (member_id) REFERENCES member(id) ON DELETE CASCADE,
+    FOREIGN KEY (position_id) REFERENCES duty_position(id) ON DELETE CASCADE
+);`
+
+	addEmailQuery = "ALTER TABLE member ADD COLUMN email string;"
+
+	addImportBatchQuery = `CREATE TABLE import_profile(
+    id string PRIMARY KEY,
+    name string UNIQUE,
+    member_column string,
+    requirement_column string,
+    date_column string,
+    date_format string
+);
+
+CREATE TABLE import_batch(
+    id string PRIMARY KEY,
+    profile_id string,
+    file_name string,
+    uploaded_by string,
+    uploaded datetime,
+    status string,
+    FOREIGN KEY (profile_id) REFERENCES import_profile(id) ON DELETE SET NULL,
+    FOREIGN KEY (uploaded_by) REFERENCES member(id) ON DELETE SET NULL
+);
+
+CREATE TABLE import_row(
+    id string PRIMARY KEY,
+    batch_id string,
+    line integer,
+    member_identifier string,
+    member_id string,
+    requirement_name string,
+    requirement_id string,
+    match_score real,
+    completed_date datetime,
+    excluded integer,
+    errors string,
+    FOREIGN KEY (batch_id) REFERENCES import_batch(id) ON DELETE CASCADE
+);`
+
+	addQualificationHistoryQuery = `CREATE TABLE member_qualification_history(
+    id string PRIMARY KEY,
+    member_id string,
+    qualification_id string,
+    kind string,
+    actor_id string,
+    time datetime
+);`
+
+	addArchiveQuery = `ALTER TABLE member ADD COLUMN archived datetime;
+ALTER TABLE member ADD COLUMN archive_reason string;
+ALTER TABLE member ADD COLUMN archived_by string;`
+
+	addUnitQuery = `CREATE TABLE unit(
+    id string PRIMARY KEY,
+    name string,
+    kind string,
+    parent_id string,
+    FOREIGN KEY (parent_id) REFERENCES unit(id)
+);
+
+ALTER TABLE member ADD COLUMN unit_id string REFERENCES unit(id) ON DELETE SET NULL;
+
+CREATE TABLE unit_mandatory_qualification(
+    unit_id string,
+    qualification_id string,
+    PRIMARY KEY (unit_id, qualification_id),
+    FOREIGN KEY (unit_id) REFERENCES unit(id) ON DELETE CASCADE,
+    FOREIGN KEY (qualification_id) REFERENCES qualification(id) ON DELETE CASCADE
+);
+
+CREATE TABLE unit_admin(
+    unit_id string,
+    member_id string,
+    PRIMARY KEY (unit_id, member_id),
+    FOREIGN KEY (unit_id) REFERENCES unit(id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id) REFERENCES member(id) ON DELETE CASCADE
+);`
+
+	// addTenantQuery rebuilds every table with a tenant_id, sqlite can't add the constraints that come with it to an
+	// existing table. Everything already in the database belongs to the default tenant, types.DefaultTenantID.
+	addTenantQuery = `CREATE TABLE tenant(
+    id string PRIMARY KEY,
+    name string,
+    subdomain string UNIQUE
+);
+INSERT INTO tenant(id, name, subdomain) VALUES('default', 'Default', NULL);
+
+CREATE TABLE tenant_member(
+    id string PRIMARY KEY,
+    first_name string,
+    last_name string,
+    rank string,
+    user_name string,
+    supervisor_id string,
+    admin integer,
+    role string,
+    hash string,
+    certificate_id string,
+    email string,
+    archived datetime,
+    archive_reason string,
+    archived_by string,
+    unit_id string,
+    tenant_id string,
+    UNIQUE (id, tenant_id),
+    UNIQUE (user_name, tenant_id),
+    UNIQUE (certificate_id, tenant_id),
+    FOREIGN KEY (supervisor_id) REFERENCES member(id) ON DELETE SET NULL,
+    FOREIGN KEY (unit_id) REFERENCES unit(id) ON DELETE SET NULL
+);
+INSERT INTO tenant_member(id, first_name, last_name, rank, user_name, supervisor_id, admin, role, hash, certificate_id, email, archived, archive_reason, archived_by, unit_id, tenant_id) SELECT id, first_name, last_name, rank, user_name, supervisor_id, admin, role, hash, certificate_id, email, archived, archive_reason, archived_by, unit_id, 'default' FROM member;
+DROP TABLE member;
+ALTER TABLE tenant_member RENAME TO member;
+
+CREATE TABLE tenant_qualification(
+    id string PRIMARY KEY,
+    name string,
+    notes string,
+    expires integer,
+    expiration_days integer,
+    tenant_id string,
+    UNIQUE (id, tenant_id),
+    UNIQUE (name, tenant_id)
+);
+INSERT INTO tenant_qualification(id, name, notes, expires, expiration_days, tenant_id) SELECT id, name, notes, expires, expiration_days, 'default' FROM qualification;
+DROP TABLE qualification;
+ALTER TABLE tenant_qualification RENAME TO qualification;
+
+CREATE TABLE tenant_member_qualification(
+    member_id string,
+    qualification_id string,
+    tenant_id string,
+    PRIMARY KEY (member_id, qualification_id),
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (qualification_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE
+);
+INSERT INTO tenant_member_qualification(member_id, qualification_id, tenant_id) SELECT member_id, qualification_id, 'default' FROM member_qualification;
+DROP TABLE member_qualification;
+ALTER TABLE tenant_member_qualification RENAME TO member_qualification;
+
+CREATE TABLE tenant_requirement(
+    id string PRIMARY KEY,
+    name string,
+    description string,
+    notes string,
+    days_valid_for integer,
+    reference_id string,
+    tenant_id string,
+    UNIQUE (id, tenant_id),
+    UNIQUE (name, tenant_id),
+    FOREIGN KEY (reference_id) REFERENCES reference(id) ON DELETE SET NULL
+);
+INSERT INTO tenant_requirement(id, name, description, notes, days_valid_for, reference_id, tenant_id) SELECT id, name, description, notes, days_valid_for, reference_id, 'default' FROM requirement;
+DROP TABLE requirement;
+ALTER TABLE tenant_requirement RENAME TO requirement;
+
+CREATE TABLE tenant_member_requirement(
+    member_id string,
+    requirement_id string,
+    initial_completion datetime,
+    most_recent_completion datetime,
+    tenant_id string,
+    PRIMARY KEY (member_id, requirement_id),
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (requirement_id, tenant_id) REFERENCES requirement(id, tenant_id) ON DELETE CASCADE
+);
+INSERT INTO tenant_member_requirement(member_id, requirement_id, initial_completion, most_recent_completion, tenant_id) SELECT member_id, requirement_id, initial_completion, most_recent_completion, 'default' FROM member_requirement;
+DROP TABLE member_requirement;
+ALTER TABLE tenant_member_requirement RENAME TO member_requirement;
+
+CREATE TABLE tenant_qualification_initial_requirement(
+    qualification_id string,
+    requirement_id string,
+    tenant_id string,
+    PRIMARY KEY (qualification_id, requirement_id),
+    FOREIGN KEY (qualification_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (requirement_id, tenant_id) REFERENCES requirement(id, tenant_id) ON DELETE CASCADE
+);
+INSERT INTO tenant_qualification_initial_requirement(qualification_id, requirement_id, tenant_id) SELECT qualification_id, requirement_id, 'default' FROM qualification_initial_requirement;
+DROP TABLE qualification_initial_requirement;
+ALTER TABLE tenant_qualification_initial_requirement RENAME TO qualification_initial_requirement;
+
+CREATE TABLE tenant_qualification_recurring_requirement(
+    qualification_id string,
+    requirement_id string,
+    tenant_id string,
+    PRIMARY KEY (qualification_id, requirement_id),
+    FOREIGN KEY (qualification_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (requirement_id, tenant_id) REFERENCES requirement(id, tenant_id) ON DELETE CASCADE
+);
+INSERT INTO tenant_qualification_recurring_requirement(qualification_id, requirement_id, tenant_id) SELECT qualification_id, requirement_id, 'default' FROM qualification_recurring_requirement;
+DROP TABLE qualification_recurring_requirement;
+ALTER TABLE tenant_qualification_recurring_requirement RENAME TO qualification_recurring_requirement;
+
+CREATE TABLE tenant_qualification_prerequisite(
+    qualification_id string,
+    prerequisite_id string,
+    tenant_id string,
+    PRIMARY KEY (qualification_id, prerequisite_id),
+    FOREIGN KEY (qualification_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (prerequisite_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE
+);
+INSERT INTO tenant_qualification_prerequisite(qualification_id, prerequisite_id, tenant_id) SELECT qualification_id, prerequisite_id, 'default' FROM qualification_prerequisite;
+DROP TABLE qualification_prerequisite;
+ALTER TABLE tenant_qualification_prerequisite RENAME TO qualification_prerequisite;
+
+CREATE TABLE tenant_session(
+    id string PRIMARY KEY,
+    expiration datetime,
+    user_agent string,
+    tenant_id string,
+    UNIQUE (id, tenant_id)
+);
+INSERT INTO tenant_session(id, expiration, user_agent, tenant_id) SELECT id, expiration, user_agent, 'default' FROM session;
+DROP TABLE session;
+ALTER TABLE tenant_session RENAME TO session;
+
+CREATE TABLE tenant_member_session(
+    member_id string,
+    session_id string,
+    tenant_id string,
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (session_id, tenant_id) REFERENCES session(id, tenant_id) ON DELETE CASCADE,
+    PRIMARY KEY (member_id, session_id)
+);
+INSERT INTO tenant_member_session(member_id, session_id, tenant_id) SELECT member_id, session_id, 'default' FROM member_session;
+DROP TABLE member_session;
+ALTER TABLE tenant_member_session RENAME TO member_session;
+
+CREATE TABLE tenant_reference(
+    id string PRIMARY KEY,
+    name string,
+    volume int,
+    paragraph string,
+    tenant_id string,
+    UNIQUE (name, tenant_id)
+);
+INSERT INTO tenant_reference(id, name, volume, paragraph, tenant_id) SELECT id, name, volume, paragraph, 'default' FROM reference;
+DROP TABLE reference;
+ALTER TABLE tenant_reference RENAME TO reference;
+
+CREATE TABLE tenant_api_token(
+    id string PRIMARY KEY,
+    member_id string,
+    name string,
+    scope string,
+    hash string UNIQUE,
+    created datetime,
+    last_used datetime,
+    tenant_id string,
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE
+);
+INSERT INTO tenant_api_token(id, member_id, name, scope, hash, created, last_used, tenant_id) SELECT id, member_id, name, scope, hash, created, last_used, 'default' FROM api_token;
+DROP TABLE api_token;
+ALTER TABLE tenant_api_token RENAME TO api_token;
+
+CREATE TABLE tenant_role_permission(
+    role string,
+    permission string,
+    tenant_id string,
+    PRIMARY KEY (role, permission, tenant_id)
+);
+INSERT INTO tenant_role_permission(role, permission, tenant_id) SELECT role, permission, 'default' FROM role_permission;
+DROP TABLE role_permission;
+ALTER TABLE tenant_role_permission RENAME TO role_permission;
+
+CREATE TABLE tenant_requirement_certifier(
+    requirement_id string,
+    member_id string,
+    tenant_id string,
+    PRIMARY KEY (requirement_id, member_id),
+    FOREIGN KEY (requirement_id, tenant_id) REFERENCES requirement(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE
+);
+INSERT INTO tenant_requirement_certifier(requirement_id, member_id, tenant_id) SELECT requirement_id, member_id, 'default' FROM requirement_certifier;
+DROP TABLE requirement_certifier;
+ALTER TABLE tenant_requirement_certifier RENAME TO requirement_certifier;
+
+CREATE TABLE tenant_completion(
+    id string PRIMARY KEY,
+    member_id string,
+    requirement_id string,
+    trainer_id string,
+    certifier_id string,
+    submitted_by string,
+    status string,
+    completed_date datetime,
+    submitted datetime,
+    reviewed datetime,
+    comments string,
+    tenant_id string,
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (requirement_id, tenant_id) REFERENCES requirement(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (trainer_id) REFERENCES member(id) ON DELETE SET NULL,
+    FOREIGN KEY (certifier_id) REFERENCES member(id) ON DELETE SET NULL
+);
+INSERT INTO tenant_completion(id, member_id, requirement_id, trainer_id, certifier_id, submitted_by, status, completed_date, submitted, reviewed, comments, tenant_id) SELECT id, member_id, requirement_id, trainer_id, certifier_id, submitted_by, status, completed_date, submitted, reviewed, comments, 'default' FROM completion;
+DROP TABLE completion;
+ALTER TABLE tenant_completion RENAME TO completion;
+
+CREATE TABLE tenant_waiver(
+    id string PRIMARY KEY,
+    member_id string,
+    requirement_id string,
+    qualification_id string,
+    kind string,
+    approver_id string,
+    reason string,
+    start_date datetime,
+    end_date datetime,
+    created datetime,
+    memo_name string,
+    memo_type string,
+    memo blob,
+    tenant_id string,
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (requirement_id, tenant_id) REFERENCES requirement(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (qualification_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (approver_id) REFERENCES member(id) ON DELETE SET NULL
+);
+INSERT INTO tenant_waiver(id, member_id, requirement_id, qualification_id, kind, approver_id, reason, start_date, end_date, created, memo_name, memo_type, memo, tenant_id) SELECT id, member_id, requirement_id, qualification_id, kind, approver_id, reason, start_date, end_date, created, memo_name, memo_type, memo, 'default' FROM waiver;
+DROP TABLE waiver;
+ALTER TABLE tenant_waiver RENAME TO waiver;
+
+CREATE TABLE tenant_audit_log(
+    id string PRIMARY KEY,
+    actor_id string,
+    action string,
+    entity_type string,
+    entity_id string,
+    time datetime,
+    details string,
+    tenant_id string
+);
+INSERT INTO tenant_audit_log(id, actor_id, action, entity_type, entity_id, time, details, tenant_id) SELECT id, actor_id, action, entity_type, entity_id, time, details, 'default' FROM audit_log;
+DROP TABLE audit_log;
+ALTER TABLE tenant_audit_log RENAME TO audit_log;
+
+CREATE TABLE tenant_duty_position(
+    id string PRIMARY KEY,
+    name string,
+    description string,
+    tenant_id string,
+    UNIQUE (id, tenant_id),
+    UNIQUE (name, tenant_id)
+);
+INSERT INTO tenant_duty_position(id, name, description, tenant_id) SELECT id, name, description, 'default' FROM duty_position;
+DROP TABLE duty_position;
+ALTER TABLE tenant_duty_position RENAME TO duty_position;
+
+CREATE TABLE tenant_duty_position_qualification(
+    position_id string,
+    qualification_id string,
+    tenant_id string,
+    PRIMARY KEY (position_id, qualification_id),
+    FOREIGN KEY (position_id, tenant_id) REFERENCES duty_position(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (qualification_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE
+);
+INSERT INTO tenant_duty_position_qualification(position_id, qualification_id, tenant_id) SELECT position_id, qualification_id, 'default' FROM duty_position_qualification;
+DROP TABLE duty_position_qualification;
+ALTER TABLE tenant_duty_position_qualification RENAME TO duty_position_qualification;
+
+CREATE TABLE tenant_member_duty_position(
+    member_id string,
+    position_id string,
+    tenant_id string,
+    PRIMARY KEY (member_id, position_id),
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (position_id, tenant_id) REFERENCES duty_position(id, tenant_id) ON DELETE CASCADE
+);
+INSERT INTO tenant_member_duty_position(member_id, position_id, tenant_id) SELECT member_id, position_id, 'default' FROM member_duty_position;
+DROP TABLE member_duty_position;
+ALTER TABLE tenant_member_duty_position RENAME TO member_duty_position;
+
+CREATE TABLE tenant_import_profile(
+    id string PRIMARY KEY,
+    name string,
+    member_column string,
+    requirement_column string,
+    date_column string,
+    date_format string,
+    tenant_id string,
+    UNIQUE (name, tenant_id)
+);
+INSERT INTO tenant_import_profile(id, name, member_column, requirement_column, date_column, date_format, tenant_id) SELECT id, name, member_column, requirement_column, date_column, date_format, 'default' FROM import_profile;
+DROP TABLE import_profile;
+ALTER TABLE tenant_import_profile RENAME TO import_profile;
+
+CREATE TABLE tenant_import_batch(
+    id string PRIMARY KEY,
+    profile_id string,
+    file_name string,
+    uploaded_by string,
+    uploaded datetime,
+    status string,
+    tenant_id string,
+    UNIQUE (id, tenant_id),
+    FOREIGN KEY (profile_id) REFERENCES import_profile(id) ON DELETE SET NULL,
+    FOREIGN KEY (uploaded_by) REFERENCES member(id) ON DELETE SET NULL
+);
+INSERT INTO tenant_import_batch(id, profile_id, file_name, uploaded_by, uploaded, status, tenant_id) SELECT id, profile_id, file_name, uploaded_by, uploaded, status, 'default' FROM import_batch;
+DROP TABLE import_batch;
+ALTER TABLE tenant_import_batch RENAME TO import_batch;
+
+CREATE TABLE tenant_import_row(
+    id string PRIMARY KEY,
+    batch_id string,
+    line integer,
+    member_identifier string,
+    member_id string,
+    requirement_name string,
+    requirement_id string,
+    match_score real,
+    completed_date datetime,
+    excluded integer,
+    errors string,
+    tenant_id string,
+    FOREIGN KEY (batch_id, tenant_id) REFERENCES import_batch(id, tenant_id) ON DELETE CASCADE
+);
+INSERT INTO tenant_import_row(id, batch_id, line, member_identifier, member_id, requirement_name, requirement_id, match_score, completed_date, excluded, errors, tenant_id) SELECT id, batch_id, line, member_identifier, member_id, requirement_name, requirement_id, match_score, completed_date, excluded, errors, 'default' FROM import_row;
+DROP TABLE import_row;
+ALTER TABLE tenant_import_row RENAME TO import_row;
+
+CREATE TABLE tenant_member_qualification_history(
+    id string PRIMARY KEY,
+    member_id string,
+    qualification_id string,
+    kind string,
+    actor_id string,
+    time datetime,
+    tenant_id string
+);
+INSERT INTO tenant_member_qualification_history(id, member_id, qualification_id, kind, actor_id, time, tenant_id) SELECT id, member_id, qualification_id, kind, actor_id, time, 'default' FROM member_qualification_history;
+DROP TABLE member_qualification_history;
+ALTER TABLE tenant_member_qualification_history RENAME TO member_qualification_history;
+
+CREATE TABLE tenant_unit(
+    id string PRIMARY KEY,
+    name string,
+    kind string,
+    parent_id string,
+    tenant_id string,
+    UNIQUE (id, tenant_id),
+    FOREIGN KEY (parent_id, tenant_id) REFERENCES unit(id, tenant_id)
+);
+INSERT INTO tenant_unit(id, name, kind, parent_id, tenant_id) SELECT id, name, kind, parent_id, 'default' FROM unit;
+DROP TABLE unit;
+ALTER TABLE tenant_unit RENAME TO unit;
+
+CREATE TABLE tenant_unit_mandatory_qualification(
+    unit_id string,
+    qualification_id string,
+    tenant_id string,
+    PRIMARY KEY (unit_id, qualification_id),
+    FOREIGN KEY (unit_id, tenant_id) REFERENCES unit(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (qualification_id, tenant_id) REFERENCES qualification(id, tenant_id) ON DELETE CASCADE
+);
+INSERT INTO tenant_unit_mandatory_qualification(unit_id, qualification_id, tenant_id) SELECT unit_id, qualification_id, 'default' FROM unit_mandatory_qualification;
+DROP TABLE unit_mandatory_qualification;
+ALTER TABLE tenant_unit_mandatory_qualification RENAME TO unit_mandatory_qualification;
+
+CREATE TABLE tenant_unit_admin(
+    unit_id string,
+    member_id string,
+    tenant_id string,
+    PRIMARY KEY (unit_id, member_id),
+    FOREIGN KEY (unit_id, tenant_id) REFERENCES unit(id, tenant_id) ON DELETE CASCADE,
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE
+);
+INSERT INTO tenant_unit_admin(unit_id, member_id, tenant_id) SELECT unit_id, member_id, 'default' FROM unit_admin;
+DROP TABLE unit_admin;
+ALTER TABLE tenant_unit_admin RENAME TO unit_admin;`
+
+	addVersionColumnsQuery = `ALTER TABLE member ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE qualification ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE requirement ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE reference ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE duty_position ADD COLUMN version integer NOT NULL DEFAULT 1;
+ALTER TABLE unit ADD COLUMN version integer NOT NULL DEFAULT 1;`
+
+	// addTenantLinksQuery keeps members' supervisors and units and requirements' references in their own tenant. Links
+	// already crossing tenants are dropped. ON DELETE SET NULL would clear tenant_id too, so providers unlink rows
+	// themselves before deleting what they point at.
+	addTenantLinksQuery = `UPDATE member SET supervisor_id = NULL WHERE supervisor_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM member s WHERE s.id = member.supervisor_id AND s.tenant_id = member.tenant_id);
+UPDATE member SET unit_id = NULL WHERE unit_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM unit u WHERE u.id = member.unit_id AND u.tenant_id = member.tenant_id);
+UPDATE requirement SET reference_id = NULL WHERE reference_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM reference re WHERE re.id = requirement.reference_id AND re.tenant_id = requirement.tenant_id);
+
+CREATE TABLE tenant_reference(
+    id string PRIMARY KEY,
+    name string,
+    volume int,
+    paragraph string,
+    version integer NOT NULL DEFAULT 1,
+    tenant_id string,
+    UNIQUE (id, tenant_id),
+    UNIQUE (name, tenant_id)
+);
+INSERT INTO tenant_reference(id, name, volume, paragraph, version, tenant_id) SELECT id, name, volume, paragraph, version, tenant_id FROM reference;
+DROP TABLE reference;
+ALTER TABLE tenant_reference RENAME TO reference;
+
+CREATE TABLE tenant_requirement(
+    id string PRIMARY KEY,
+    name string,
+    description string,
+    notes string,
+    days_valid_for integer,
+    reference_id string,
+    version integer NOT NULL DEFAULT 1,
+    tenant_id string,
+    UNIQUE (id, tenant_id),
+    UNIQUE (name, tenant_id),
+    FOREIGN KEY (reference_id, tenant_id) REFERENCES reference(id, tenant_id)
+);
+INSERT INTO tenant_requirement(id, name, description, notes, days_valid_for, reference_id, version, tenant_id) SELECT id, name, description, notes, days_valid_for, reference_id, version, tenant_id FROM requirement;
+DROP TABLE requirement;
+ALTER TABLE tenant_requirement RENAME TO requirement;
+
+CREATE TABLE tenant_member(
+    id string PRIMARY KEY,
+    first_name string,
+    last_name string,
+    rank string,
+    user_name string,
+    supervisor_id string,
+    admin integer,
+    role string,
+    hash string,
+    certificate_id string,
+    email string,
+    archived datetime,
+    archive_reason string,
+    archived_by string,
+    unit_id string,
+    version integer NOT NULL DEFAULT 1,
+    tenant_id string,
+    UNIQUE (id, tenant_id),
+    UNIQUE (user_name, tenant_id),
+    UNIQUE (certificate_id, tenant_id),
+    FOREIGN KEY (supervisor_id, tenant_id) REFERENCES member(id, tenant_id),
+    FOREIGN KEY (unit_id, tenant_id) REFERENCES unit(id, tenant_id)
+);
+INSERT INTO tenant_member(id, first_name, last_name, rank, user_name, supervisor_id, admin, role, hash, certificate_id, email, archived, archive_reason, archived_by, unit_id, version, tenant_id) SELECT id, first_name, last_name, rank, user_name, supervisor_id, admin, role, hash, certificate_id, email, archived, archive_reason, archived_by, unit_id, version, tenant_id FROM member;
+DROP TABLE member;
+ALTER TABLE tenant_member RENAME TO member;`
+
+	// addQualificationSourceQuery records which position assigned a member's qualification, so removing the position
+	// leaves qualifications assigned by hand alone. Assignments from before it are treated as assigned by hand.
+	addQualificationSourceQuery = "ALTER TABLE member_qualification ADD COLUMN source_position_id string;"
+
+	// addMemberTransferQuery records the transfer packages imported, so each is only imported once and members coming
+	// back from an instance they were transferred from are recognized by their ID there.
+	addMemberTransferQuery = `CREATE TABLE member_transfer(
+    package_id string,
+    issuer string,
+    issuer_member_id string,
+    member_id string,
+    tenant_id string,
+    PRIMARY KEY (package_id, tenant_id),
+    FOREIGN KEY (member_id, tenant_id) REFERENCES member(id, tenant_id) ON DELETE CASCADE
+);`
+
+	insertVersionQuery      = "INSERT INTO versions(version) VALUES($1);"
+	disableForeignKeysQuery = "PRAGMA foreign_keys = OFF;"
+	enableForeignKeysQuery  = "PRAGMA foreign_keys = ON;"
+	foreignKeyCheckQuery    = "PRAGMA foreign_key_check;"
+
+	// A transaction begun within another runs under a savepoint, see tenantDB.BeginTx.
+	savepointNestedQuery           = "SAVEPOINT nested;"
+	rollbackToSavepointNestedQuery = "ROLLBACK TO SAVEPOINT nested;"
+	releaseSavepointNestedQuery    = "RELEASE SAVEPOINT nested;"
+
+	// Every query below is scoped to the provider's tenant through the $tenant parameter, which Provider.Db binds on
+	// every statement. sqlite numbers parameters in the order they first appear, so $tenant always comes after the
+	// positional ones.
+	insertTenantQuery         = "INSERT INTO tenant(id, name, subdomain) VALUES($1, $2, $3);"
+	getTenantQuery            = "SELECT id, name, subdomain FROM tenant WHERE id=$1;"
+	getTenantBySubdomainQuery = "SELECT id, name, subdomain FROM tenant WHERE subdomain=$1;"
+	getTenantsQuery           = "SELECT id, name, subdomain FROM tenant ORDER BY name;"
+
+	memberColumns                 = "id, first_name, last_name, rank, user_name, supervisor_id, admin, role, hash, certificate_id, email, archived, archive_reason, archived_by, unit_id, version"
+	insertMemberQuery             = "INSERT INTO member(id, first_name, last_name, rank, user_name, supervisor_id, admin, role, hash, certificate_id, email, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $tenant);"
+	getMemberQuery                = "SELECT " + memberColumns + " FROM member WHERE id=$1 AND tenant_id=$tenant;"
+	getMemberVersionQuery         = "SELECT version FROM member WHERE id=$1 AND tenant_id=$tenant;"
+	getMemberByUsernameQuery      = "SELECT " + memberColumns + " FROM member where user_name=$1 AND tenant_id=$tenant;"
+	getMemberByCertificateIDQuery = "SELECT " + memberColumns + " FROM member WHERE certificate_id=$1 AND tenant_id=$tenant;"
+	getAllMembersQuery            = "SELECT " + memberColumns + " FROM member WHERE archived IS NULL AND tenant_id=$tenant;"
+	getArchivedMembersQuery       = "SELECT " + memberColumns + " FROM member WHERE archived IS NOT NULL AND tenant_id=$tenant ORDER BY archived;"
+	getSubordinatesQuery          = "SELECT " + memberColumns + " FROM member WHERE supervisor_id=$1 AND archived IS NULL AND tenant_id=$tenant;"
+	updateMemberQuery             = "UPDATE member SET first_name=$1, last_name=$2, rank=$3, supervisor_id=$4, admin=$5, role=$6, hash=$7, certificate_id=$8, email=$9, version=version+1 WHERE ID=$10 AND ($11=0 OR version=$11) AND tenant_id=$tenant;"
+	setMemberSupervisorQuery      = "UPDATE member SET supervisor_id=$1 WHERE id=$2 AND tenant_id=$tenant;"
+	setMemberUnitQuery            = "UPDATE member SET unit_id=$1, version=version+1 WHERE id=$2 AND tenant_id=$tenant;"
+	getUnitMembersQuery           = "SELECT " + memberColumns + " FROM member WHERE unit_id=$1 AND archived IS NULL AND tenant_id=$tenant;"
+	clearSubordinatesQuery        = "UPDATE member SET supervisor_id=NULL WHERE supervisor_id=$1 AND tenant_id=$tenant;"
+	deleteMemberQuery             = "DELETE FROM member WHERE id=$1 AND tenant_id=$tenant;"
+	deleteMemberByUsernameQuery   = "DELETE FROM member WHERE user_name=$1 AND tenant_id=$tenant;"
+	archiveMemberQuery            = "UPDATE member SET archived=$1, archive_reason=$2, archived_by=$3, version=version+1 WHERE id=$4 AND tenant_id=$tenant;"
+	restoreMemberQuery            = "UPDATE member SET archived=NULL, archive_reason=NULL, archived_by=NULL, version=version+1 WHERE id=$1 AND tenant_id=$tenant;"
+	deleteMemberAPITokensQuery    = "DELETE FROM api_token WHERE member_id=$1 AND tenant_id=$tenant;"
+	deleteMemberHistoryQuery      = "DELETE FROM member_qualification_history WHERE member_id=$1 AND tenant_id=$tenant;"
+
+	// Transfers can bring back records a returning member already has, so anything keyed by ID is skipped if present
+	transferMemberQualificationQuery = "INSERT OR IGNORE INTO member_qualification(member_id, qualification_id, tenant_id) VALUES($1, $2, $tenant);"
+	transferCompletionQuery          = "INSERT OR IGNORE INTO completion(id, member_id, requirement_id, trainer_id, certifier_id, submitted_by, status, completed_date, submitted, reviewed, comments, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $tenant);"
+	transferQualificationEventQuery  = "INSERT OR IGNORE INTO member_qualification_history(id, member_id, qualification_id, kind, actor_id, time, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $tenant);"
+	insertMemberTransferQuery        = "INSERT INTO member_transfer(package_id, issuer, issuer_member_id, member_id, tenant_id) VALUES($1, $2, $3, $4, $tenant);"
+	checkMemberTransferQuery         = "SELECT COUNT(*) FROM member_transfer WHERE package_id=$1 AND tenant_id=$tenant;"
+	getTransferredMemberIDQuery      = "SELECT member_id FROM member_transfer WHERE issuer=$1 AND issuer_member_id=$2 AND tenant_id=$tenant LIMIT 1;"
+	getMemberTransferOriginsQuery    = "SELECT issuer, issuer_member_id FROM member_transfer WHERE member_id=$1 AND tenant_id=$tenant;"
+
+	qualificationColumns                         = "id, name, notes, expires, expiration_days, version"
+	insertQualificationQuery                     = "INSERT INTO qualification(id, name, notes, expires, expiration_days, tenant_id) VALUES($1, $2, $3, $4, $5, $tenant);"
+	getQualificationQuery                        = "SELECT " + qualificationColumns + " FROM qualification WHERE id=$1 AND tenant_id=$tenant;"
+	getAllQualificationsQuery                    = "SELECT " + qualificationColumns + " FROM qualification WHERE tenant_id=$tenant ORDER BY name;"
+	updateQualificationQuery                     = "UPDATE qualification SET name=$1, notes=$2, expires=$3, expiration_days=$4, version=version+1 WHERE ID=$5 AND ($6=0 OR version=$6) AND tenant_id=$tenant;"
+	getQualificationVersionQuery                 = "SELECT version FROM qualification WHERE id=$1 AND tenant_id=$tenant;"
+	deleteQualificationQuery                     = "DELETE FROM qualification WHERE id=$1 AND tenant_id=$tenant;"
+	insertQualificationInitialRequirementQuery   = "INSERT INTO qualification_initial_requirement(qualification_id, requirement_id, tenant_id) VALUES($1, $2, $tenant);"
+	insertQualificationRecurringRequirementQuery = "INSERT INTO qualification_recurring_requirement(qualification_id, requirement_id, tenant_id) VALUES($1, $2, $tenant);"
+	getInitialRequirementIdsQuery                = "SELECT requirement_id FROM qualification_initial_requirement WHERE qualification_id=$1 AND tenant_id=$tenant;"
+	getRecurringRequirementIdsQuery              = "SELECT requirement_id FROM qualification_recurring_requirement WHERE qualification_id=$1 AND tenant_id=$tenant;"
+	deleteQualificationRecurringRequirementQuery = "DELETE FROM qualification_recurring_requirement WHERE requirement_id=$1 AND tenant_id=$tenant;"
+	deleteQualificationInitialRequirementQuery   = "DELETE FROM qualification_initial_requirement WHERE requirement_id=$1 AND tenant_id=$tenant;"
+	insertQualificationPrerequisiteQuery         = "INSERT INTO qualification_prerequisite(qualification_id, prerequisite_id, tenant_id) VALUES($1, $2, $tenant);"
+	getPrerequisitesQuery                        = "SELECT qualification_id, prerequisite_id FROM qualification_prerequisite WHERE qualification_id=$1 AND tenant_id=$tenant ORDER BY prerequisite_id;"
+	getAllPrerequisitesQuery                     = "SELECT qualification_id, prerequisite_id FROM qualification_prerequisite WHERE tenant_id=$tenant ORDER BY qualification_id, prerequisite_id;"
+	deleteQualificationPrerequisitesQuery        = "DELETE FROM qualification_prerequisite WHERE qualification_id=$1 AND tenant_id=$tenant;"
+
+	// Qualifications are read with all of their requirements and prerequisites in one query each, scoped to a single
+	// qualification, every qualification or the ones a member holds. Requirements whose reference was deleted come
+	// back with an empty reference.
+	qualificationRequirementColumns          = "l.qualification_id, " + listedRequirementColumns
+	qualificationRequirementJoin             = " l JOIN requirement r ON r.id = l.requirement_id AND r.tenant_id = l.tenant_id LEFT JOIN reference re ON re.id = r.reference_id AND re.tenant_id = r.tenant_id"
+	memberQualificationIDs                   = "(SELECT qualification_id FROM member_qualification WHERE member_id=$1 AND tenant_id=$tenant)"
+	getInitialRequirementsQuery              = "SELECT " + qualificationRequirementColumns + " FROM qualification_initial_requirement" + qualificationRequirementJoin + " WHERE l.qualification_id=$1 AND l.tenant_id=$tenant ORDER BY r.name;"
+	getRecurringRequirementsQuery            = "SELECT " + qualificationRequirementColumns + " FROM qualification_recurring_requirement" + qualificationRequirementJoin + " WHERE l.qualification_id=$1 AND l.tenant_id=$tenant ORDER BY r.name;"
+	getAllInitialRequirementsQuery           = "SELECT " + qualificationRequirementColumns + " FROM qualification_initial_requirement" + qualificationRequirementJoin + " WHERE l.tenant_id=$tenant ORDER BY r.name;"
+	getAllRecurringRequirementsQuery         = "SELECT " + qualificationRequirementColumns + " FROM qualification_recurring_requirement" + qualificationRequirementJoin + " WHERE l.tenant_id=$tenant ORDER BY r.name;"
+	getMemberQualificationsQuery             = "SELECT " + qualificationColumns + " FROM qualification WHERE id IN " + memberQualificationIDs + " AND tenant_id=$tenant ORDER BY name;"
+	getMemberInitialRequirementsQuery        = "SELECT " + qualificationRequirementColumns + " FROM qualification_initial_requirement" + qualificationRequirementJoin + " WHERE l.qualification_id IN " + memberQualificationIDs + " AND l.tenant_id=$tenant ORDER BY r.name;"
+	getMemberRecurringRequirementsQuery      = "SELECT " + qualificationRequirementColumns + " FROM qualification_recurring_requirement" + qualificationRequirementJoin + " WHERE l.qualification_id IN " + memberQualificationIDs + " AND l.tenant_id=$tenant ORDER BY r.name;"
+	getMemberQualificationPrerequisitesQuery = "SELECT qualification_id, prerequisite_id FROM qualification_prerequisite WHERE qualification_id IN " + memberQualificationIDs + " AND tenant_id=$tenant ORDER BY prerequisite_id;"
+
+	addMemberQualificationQuery            = "INSERT INTO member_qualification(member_id, qualification_id, source_position_id, tenant_id) VALUES($1, $2, $3, $tenant);"
+	checkMemberQualificationQuery          = "SELECT COUNT(*) FROM member_qualification WHERE member_id=$1 AND qualification_id=$2 AND tenant_id=$tenant;"
+	removeMemberQualificationQuery         = "DELETE FROM member_qualification WHERE member_id=$1 AND qualification_ID=$2 AND tenant_id=$tenant;"
+	getMemberPositionQualificationIDsQuery = "SELECT qualification_id FROM member_qualification WHERE member_id=$1 AND source_position_id IS NOT NULL AND tenant_id=$tenant ORDER BY qualification_id;"
+	countMemberQuery                       = "SELECT COUNT(*) FROM member WHERE id=$1 AND tenant_id=$tenant;"
+	countQualificationQuery                = "SELECT COUNT(*) FROM qualification WHERE id=$1 AND tenant_id=$tenant;"
+	insertQualificationEventQuery          = "INSERT INTO member_qualification_history(id, member_id, qualification_id, kind, actor_id, time, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $tenant);"
+	getQualificationHistoryQuery           = "SELECT id, member_id, qualification_id, kind, actor_id, time FROM member_qualification_history WHERE member_id=$1 AND qualification_id=$2 AND tenant_id=$tenant ORDER BY time, rowid;"
+	getMemberRequirementsQuery             = "SELECT requirement_id, most_recent_completion FROM member_requirement WHERE member_id=$1 AND tenant_id=$tenant;"
+
+	// requirementColumns and listedRequirementColumns leave the reference empty if it was deleted
+	requirementColumns                   = "r.id, r.name, r.description, r.notes, r.days_valid_for, r.version, COALESCE(r.reference_id, ''), COALESCE(re.id, ''), COALESCE(re.name, ''), COALESCE(re.volume, 0), COALESCE(re.paragraph, ''), COALESCE(re.version, 0)"
+	listedRequirementColumns             = "r.id, r.name, r.description, r.notes, r.days_valid_for, r.version, COALESCE(re.id, ''), COALESCE(re.name, ''), COALESCE(re.volume, 0), COALESCE(re.paragraph, ''), COALESCE(re.version, 0)"
+	addRequirementQuery                  = "INSERT INTO requirement(id, name, description, notes, days_valid_for, reference_id, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $tenant);"
+	getRequirementQuery                  = "SELECT " + requirementColumns + " FROM requirement r FULL JOIN reference re ON r.reference_id = re.id AND re.tenant_id = r.tenant_id WHERE r.id = $1 AND r.tenant_id=$tenant;"
+	getAllRequirementsQuery              = "SELECT " + requirementColumns + " FROM requirement r FULL JOIN reference re ON r.reference_id = re.id AND re.tenant_id = r.tenant_id WHERE r.tenant_id=$tenant;"
+	getQualificationsForRequirementQuery = "SELECT qualification_id FROM qualification_initial_requirement  WHERE requirement_id=$1 AND tenant_id=$tenant UNION SELECT qualification_id FROM qualification_recurring_requirement WHERE requirement_id=$1 AND tenant_id=$tenant;"
+	updateRequirementQuery               = "UPDATE requirement SET name=$1, description=$2, notes=$3, days_valid_for=$4, reference_id=$5, version=version+1 WHERE id=$6 AND ($7=0 OR version=$7) AND tenant_id=$tenant;"
+	getRequirementVersionQuery           = "SELECT version FROM requirement WHERE id=$1 AND tenant_id=$tenant;"
+	deleteRequirementQuery               = "DELETE FROM requirement WHERE id=$1 AND tenant_id=$tenant;"
+
+	addReferenceQuery        = "INSERT INTO reference(id, name, volume, paragraph, tenant_id) VALUES($1, $2, $3, $4, $tenant);"
+	getReferenceQuery        = "SELECT id, name, volume, paragraph, version FROM reference WHERE id=$1 AND tenant_id=$tenant;"
+	getReferencesQuery       = "SELECT id, name, volume, paragraph, version FROM reference WHERE tenant_id=$tenant;"
+	getReferenceVersionQuery = "SELECT version FROM reference WHERE id=$1 AND tenant_id=$tenant;"
+	updateReferenceQuery     = "UPDATE reference SET name=$1, volume=$2, paragraph=$3, version=version+1 WHERE id=$4 AND ($5=0 OR version=$5) AND tenant_id=$tenant;"
+	clearReferenceQuery      = "UPDATE requirement SET reference_id=NULL WHERE reference_id=$1 AND tenant_id=$tenant;"
+	deleteReferenceQuery     = "DELETE FROM reference WHERE id=$1 AND tenant_id=$tenant;"
+
+	apiTokenColumns             = "id, member_id, name, scope, hash, created, last_used"
+	insertAPITokenQuery         = "INSERT INTO api_token(id, member_id, name, scope, hash, created, last_used, tenant_id) VALUES($1, $2, $3, $4, $5, $6, NULL, $tenant);"
+	getAPITokenByHashQuery      = "SELECT " + apiTokenColumns + " FROM api_token WHERE hash=$1 AND tenant_id=$tenant;"
+	getAPITokensForMemberQuery  = "SELECT " + apiTokenColumns + " FROM api_token WHERE member_id=$1 AND tenant_id=$tenant ORDER BY created;"
+	updateAPITokenLastUsedQuery = "UPDATE api_token SET last_used=$1 WHERE id=$2 AND tenant_id=$tenant;"
+	deleteAPITokenQuery         = "DELETE FROM api_token WHERE id=$1 AND member_id=$2 AND tenant_id=$tenant;"
+
+	insertRolePermissionQuery  = "INSERT INTO role_permission(role, permission, tenant_id) VALUES($1, $2, $tenant);"
+	getRolePermissionsQuery    = "SELECT permission FROM role_permission WHERE role=$1 AND tenant_id=$tenant ORDER BY permission;"
+	getAllRolePermissionsQuery = "SELECT role, permission FROM role_permission WHERE tenant_id=$tenant ORDER BY role, permission;"
+	deleteRolePermissionsQuery = "DELETE FROM role_permission WHERE role=$1 AND tenant_id=$tenant;"
+
+	completionColumns                      = "id, member_id, requirement_id, trainer_id, certifier_id, submitted_by, status, completed_date, submitted, reviewed, comments"
+	addCertifierQuery                      = "INSERT INTO requirement_certifier(requirement_id, member_id, tenant_id) VALUES($1, $2, $tenant);"
+	getCertifierIDsQuery                   = "SELECT member_id FROM requirement_certifier WHERE requirement_id=$1 AND tenant_id=$tenant;"
+	checkCertifierQuery                    = "SELECT COUNT(*) FROM requirement_certifier WHERE requirement_id=$1 AND member_id=$2 AND tenant_id=$tenant;"
+	removeCertifierQuery                   = "DELETE FROM requirement_certifier WHERE requirement_id=$1 AND member_id=$2 AND tenant_id=$tenant;"
+	insertCompletionQuery                  = "INSERT INTO completion(" + completionColumns + ", tenant_id) VALUES($1, $2, $3, $4, NULL, $5, $6, $7, $8, NULL, $9, $tenant);"
+	getCompletionQuery                     = "SELECT " + completionColumns + " FROM completion WHERE id=$1 AND tenant_id=$tenant;"
+	getMemberCompletionsQuery              = "SELECT " + completionColumns + " FROM completion WHERE member_id=$1 AND tenant_id=$tenant ORDER BY submitted;"
+	getPendingCompletionsForCertifierQuery = "SELECT c.id, c.member_id, c.requirement_id, c.trainer_id, c.certifier_id, c.submitted_by, c.status, c.completed_date, c.submitted, c.reviewed, c.comments FROM completion c JOIN requirement_certifier rc ON c.requirement_id = rc.requirement_id WHERE rc.member_id=$1 AND c.status=$2 AND c.tenant_id=$tenant AND rc.tenant_id=$tenant ORDER BY c.submitted;"
+	reviewCompletionQuery                  = "UPDATE completion SET certifier_id=$1, status=$2, reviewed=$3, comments=$4 WHERE id=$5 AND status=$6 AND tenant_id=$tenant;"
+	upsertMemberRequirementQuery           = "INSERT INTO member_requirement(member_id, requirement_id, initial_completion, most_recent_completion, tenant_id) VALUES($1, $2, $3, $3, $tenant) ON CONFLICT(member_id, requirement_id) DO UPDATE SET most_recent_completion=excluded.most_recent_completion WHERE excluded.most_recent_completion > member_requirement.most_recent_completion;"
+
+	waiverColumns         = "id, member_id, requirement_id, qualification_id, kind, approver_id, reason, start_date, end_date, created, memo_name"
+	insertWaiverQuery     = "INSERT INTO waiver(" + waiverColumns + ", memo_type, memo, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $tenant);"
+	getWaiverQuery        = "SELECT " + waiverColumns + " FROM waiver WHERE id=$1 AND tenant_id=$tenant;"
+	getMemberWaiversQuery = "SELECT " + waiverColumns + " FROM waiver WHERE member_id=$1 AND tenant_id=$tenant ORDER BY start_date;"
+	getWaiverMemoQuery    = "SELECT memo_name, memo_type, memo FROM waiver WHERE id=$1 AND tenant_id=$tenant;"
+	updateWaiverEndQuery  = "UPDATE waiver SET end_date=$1 WHERE id=$2 AND tenant_id=$tenant;"
+	insertAuditEntryQuery = "INSERT INTO audit_log(id, actor_id, action, entity_type, entity_id, time, details, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $7, $tenant);"
+	getAuditEntriesQuery  = "SELECT id, actor_id, action, entity_type, entity_id, time, details FROM audit_log WHERE entity_type=$1 AND entity_id=$2 AND tenant_id=$tenant ORDER BY time;"
+
+	insertDutyPositionQuery               = "INSERT INTO duty_position(id, name, description, tenant_id) VALUES($1, $2, $3, $tenant);"
+	getDutyPositionQuery                  = "SELECT id, name, description, version FROM duty_position WHERE id=$1 AND tenant_id=$tenant;"
+	getDutyPositionVersionQuery           = "SELECT version FROM duty_position WHERE id=$1 AND tenant_id=$tenant;"
+	getDutyPositionsQuery                 = "SELECT id, name, description, version FROM duty_position WHERE tenant_id=$tenant ORDER BY name;"
+	updateDutyPositionQuery               = "UPDATE duty_position SET name=$1, description=$2, version=version+1 WHERE id=$3 AND ($4=0 OR version=$4) AND tenant_id=$tenant;"
+	deleteDutyPositionQuery               = "DELETE FROM duty_position WHERE id=$1 AND tenant_id=$tenant;"
+	insertDutyPositionQualificationQuery  = "INSERT INTO duty_position_qualification(position_id, qualification_id, tenant_id) VALUES($1, $2, $tenant);"
+	getDutyPositionQualificationsQuery    = "SELECT qualification_id FROM duty_position_qualification WHERE position_id=$1 AND tenant_id=$tenant ORDER BY qualification_id;"
+	deleteDutyPositionQualificationsQuery = "DELETE FROM duty_position_qualification WHERE position_id=$1 AND tenant_id=$tenant;"
+	assignMemberDutyPositionQuery         = "INSERT INTO member_duty_position(member_id, position_id, tenant_id) VALUES($1, $2, $tenant);"
+	getMemberDutyPositionIDsQuery         = "SELECT position_id FROM member_duty_position WHERE member_id=$1 AND tenant_id=$tenant;"
+	getDutyPositionMemberIDsQuery         = "SELECT d.member_id FROM member_duty_position d JOIN member m ON m.id = d.member_id AND m.tenant_id = d.tenant_id WHERE d.position_id=$1 AND m.archived IS NULL AND d.tenant_id=$tenant;"
+	removeMemberDutyPositionQuery         = "DELETE FROM member_duty_position WHERE member_id=$1 AND position_id=$2 AND tenant_id=$tenant;"
+
+	insertUnitQuery                        = "INSERT INTO unit(id, name, kind, parent_id, tenant_id) VALUES($1, $2, $3, $4, $tenant);"
+	getUnitQuery                           = "SELECT id, name, kind, parent_id, version FROM unit WHERE id=$1 AND tenant_id=$tenant;"
+	getUnitVersionQuery                    = "SELECT version FROM unit WHERE id=$1 AND tenant_id=$tenant;"
+	getUnitsQuery                          = "SELECT id, name, kind, parent_id, version FROM unit WHERE tenant_id=$tenant ORDER BY name;"
+	updateUnitQuery                        = "UPDATE unit SET name=$1, parent_id=$2, version=version+1 WHERE id=$3 AND ($4=0 OR version=$4) AND tenant_id=$tenant;"
+	clearUnitMembersQuery                  = "UPDATE member SET unit_id=NULL WHERE unit_id=$1 AND tenant_id=$tenant;"
+	deleteUnitQuery                        = "DELETE FROM unit WHERE id=$1 AND tenant_id=$tenant;"
+	insertUnitMandatoryQualificationQuery  = "INSERT INTO unit_mandatory_qualification(unit_id, qualification_id, tenant_id) VALUES($1, $2, $tenant);"
+	getUnitMandatoryQualificationsQuery    = "SELECT qualification_id FROM unit_mandatory_qualification WHERE unit_id=$1 AND tenant_id=$tenant ORDER BY qualification_id;"
+	deleteUnitMandatoryQualificationsQuery = "DELETE FROM unit_mandatory_qualification WHERE unit_id=$1 AND tenant_id=$tenant;"
+	addUnitAdminQuery                      = "INSERT INTO unit_admin(unit_id, member_id, tenant_id) VALUES($1, $2, $tenant);"
+	getUnitAdminIDsQuery                   = "SELECT member_id FROM unit_admin WHERE unit_id=$1 AND tenant_id=$tenant;"
+	getAdministeredUnitIDsQuery            = "SELECT unit_id FROM unit_admin WHERE member_id=$1 AND tenant_id=$tenant;"
+	removeUnitAdminQuery                   = "DELETE FROM unit_admin WHERE unit_id=$1 AND member_id=$2 AND tenant_id=$tenant;"
+
+	insertSessionQuery       = "INSERT INTO session(id, expiration, user_agent, tenant_id) VALUES($1, $2, $3, $tenant);"
+	insertMemberSessionQuery = "INSERT INTO member_session(member_id, session_id, tenant_id) VALUES($1, $2, $tenant);"
+	getSessionQuery          = "SELECT id, expiration, user_agent FROM session WHERE id=$1 AND tenant_id=$tenant;"
+	deleteSessionQuery       = "DELETE FROM session WHERE id=$1 AND tenant_id=$tenant;"
+	getMemberSessionQuery    = "SELECT member_id, session_id FROM member_session WHERE member_id=$1 AND session_id=$2 AND tenant_id=$tenant;"
+
+	importProfileColumns          = "id, name, member_column, requirement_column, date_column, date_format"
+	insertImportProfileQuery      = "INSERT INTO import_profile(" + importProfileColumns + ", tenant_id) VALUES($1, $2, $3, $4, $5, $6, $tenant);"
+	getImportProfileQuery         = "SELECT " + importProfileColumns + " FROM import_profile WHERE id=$1 AND tenant_id=$tenant;"
+	getImportProfilesQuery        = "SELECT " + importProfileColumns + " FROM import_profile WHERE tenant_id=$tenant ORDER BY name;"
+	deleteImportProfileQuery      = "DELETE FROM import_profile WHERE id=$1 AND tenant_id=$tenant;"
+	insertImportBatchQuery        = "INSERT INTO import_batch(id, profile_id, file_name, uploaded_by, uploaded, status, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $tenant);"
+	getImportBatchQuery           = "SELECT id, profile_id, file_name, uploaded_by, uploaded, status FROM import_batch WHERE id=$1 AND tenant_id=$tenant;"
+	updateImportBatchStatusQuery  = "UPDATE import_batch SET status=$1 WHERE id=$2 AND status=$3 AND tenant_id=$tenant;"
+	deleteImportBatchQuery        = "DELETE FROM import_batch WHERE id=$1 AND tenant_id=$tenant;"
+	insertImportRowQuery          = "INSERT INTO import_row(id, batch_id, line, member_identifier, member_id, requirement_name, requirement_id, match_score, completed_date, excluded, errors, tenant_id) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $tenant);"
+	getImportRowsQuery            = "SELECT id, line, member_identifier, member_id, requirement_name, requirement_id, match_score, completed_date, excluded, errors FROM import_row WHERE batch_id=$1 AND tenant_id=$tenant ORDER BY line;"
+	updateImportRowQuery          = "UPDATE import_row SET member_id=$1, requirement_id=$2, match_score=$3, completed_date=$4, excluded=$5, errors=$6 WHERE id=$7 AND batch_id=$8 AND tenant_id=$tenant;"
+	insertReviewedCompletionQuery = "INSERT INTO completion(" + completionColumns + ", tenant_id) VALUES($1, $2, $3, NULL, $4, $5, $6, $7, $8, $9, $10, $tenant);"
 )
@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// registryQueries work on the tenant and version tables, which aren't owned by any tenant, on no table at all or change
+// the schema. Every migration changes the schema too.
+var registryQueries = map[string]bool{
+	"createStructureQuery":           true,
+	"insertVersionQuery":             true,
+	"disableForeignKeysQuery":        true,
+	"enableForeignKeysQuery":         true,
+	"foreignKeyCheckQuery":           true,
+	"savepointNestedQuery":           true,
+	"rollbackToSavepointNestedQuery": true,
+	"releaseSavepointNestedQuery":    true,
+	"insertTenantQuery":              true,
+	"getTenantQuery":                 true,
+	"getTenantBySubdomainQuery":      true,
+	"getTenantsQuery":                true,
+}
+
+var (
+	tableRefPattern   = regexp.MustCompile(`(?i)\b(?:FROM|JOIN|UPDATE|INTO)\s+(\w+)(?:\s+(\w+))?`)
+	positionalPattern = regexp.MustCompile(`\$\d+`)
+	sqlKeywords       = map[string]bool{"WHERE": true, "SET": true, "ON": true, "ORDER": true, "JOIN": true, "FULL": true, "UNION": true, "VALUES": true}
+)
+
+// queryConstants evaluates every constant in queries.go ending in Query.
+func queryConstants(t *testing.T) map[string]string {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "queries.go", nil, 0)
+	if err != nil {
+		t.Fatalf("Error parsing queries.go: %s", err.Error())
+	}
+	pkg, err := (&types.Config{}).Check("sqlite", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatalf("Error type checking queries.go: %s", err.Error())
+	}
+	queries := map[string]string{}
+	for _, name := range pkg.Scope().Names() {
+		c, ok := pkg.Scope().Lookup(name).(*types.Const)
+		if ok && strings.HasSuffix(name, "Query") {
+			queries[name] = constant.StringVal(c.Val())
+		}
+	}
+	if len(queries) == 0 {
+		t.Fatalf("Found no queries in queries.go")
+	}
+	return queries
+}
+
+// TestTablesHaveTenant checks every table besides the version and tenant registries belongs to a tenant once the
+// database is fully migrated.
+func TestTablesHaveTenant(t *testing.T) {
+	dbFile := filepath.Join(t.TempDir(), "tables.db")
+	provider, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), dbFile, SchemaVersion)
+	if err != nil {
+		t.Fatalf("Error creating provider: %s", err.Error())
+	}
+	defer provider.Db.Close()
+	rows, err := provider.Db.DB.Query("SELECT m.name, COUNT(c.name) FROM sqlite_master m LEFT JOIN pragma_table_info(m.name) c ON c.name='tenant_id' WHERE m.type='table' GROUP BY m.name;")
+	if err != nil {
+		t.Fatalf("Error listing tables: %s", err.Error())
+	}
+	defer rows.Close()
+	tables := 0
+	for rows.Next() {
+		var table string
+		var tenantColumns int
+		if err = rows.Scan(&table, &tenantColumns); err != nil {
+			t.Fatalf("Error scanning table: %s", err.Error())
+		}
+		tables++
+		if table == "versions" || table == "tenant" {
+			continue
+		}
+		if tenantColumns == 0 {
+			t.Errorf("Table %s has no tenant_id column", table)
+		}
+	}
+	if tables == 0 {
+		t.Fatalf("Found no tables in the database")
+	}
+}
+
+// TestQueriesScopedToTenant checks every query touching tenant owned tables filters or stamps every row with the
+// tenant, so no query can read or change another tenant's rows.
+func TestQueriesScopedToTenant(t *testing.T) {
+	for name, query := range queryConstants(t) {
+		if registryQueries[name] || slices.Contains(migrations[:], query) {
+			continue
+		}
+		// Each half of a UNION selects on its own
+		for _, part := range strings.Split(query, " UNION ") {
+			if !strings.Contains(part, "$tenant") {
+				t.Errorf("%s doesn't bind $tenant in: %s", name, part)
+				continue
+			}
+			for _, ref := range tableRefPattern.FindAllStringSubmatch(part, -1) {
+				// ON CONFLICT ... DO UPDATE SET only ever updates the row being inserted
+				alias := ref[2]
+				if alias == "" || sqlKeywords[strings.ToUpper(ref[1])] || sqlKeywords[strings.ToUpper(alias)] {
+					continue
+				}
+				if !strings.Contains(part, alias+".tenant_id") {
+					t.Errorf("%s doesn't scope %s %s to the tenant", name, ref[1], alias)
+				}
+			}
+			if strings.HasPrefix(part, "INSERT") && !strings.Contains(part, "tenant_id)") {
+				t.Errorf("%s doesn't insert tenant_id", name)
+			} else if !strings.HasPrefix(part, "INSERT") && !strings.Contains(part, "tenant_id=$tenant") {
+				t.Errorf("%s doesn't filter on tenant_id=$tenant", name)
+			}
+		}
+		// sqlite numbers parameters in the order they first appear, $tenant before a positional parameter would take
+		// its place
+		tenant := strings.Index(query, "$tenant")
+		if tenant < 0 {
+			continue
+		}
+		for _, positional := range positionalPattern.FindAllStringIndex(query, -1) {
+			if first := strings.Index(query, query[positional[0]:positional[1]]); first > tenant {
+				t.Errorf("%s uses %s for the first time after $tenant", name, query[positional[0]:positional[1]])
+			}
+		}
+	}
+}
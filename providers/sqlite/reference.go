@@ -8,47 +8,47 @@ import (
 	"strings"
 )
 
-func (p Provider) AddReference(r types.Reference) error {
-	p.logger.LogAttrs(context.Background(), slog.LevelInfo, "Adding reference to database", slog.Any("reference", r))
-	_, err := p.Db.Exec(addReferenceQuery, r.ID, r.Name, r.Volume, r.Paragraph)
+func (p Provider) AddReference(ctx context.Context, r types.Reference) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding reference to database", slog.Any("reference", r))
+	_, err := p.Db.ExecContext(ctx, addReferenceQuery, r.ID, r.Name, r.Volume, r.Paragraph)
 	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed: reference.name") {
-		p.logger.LogAttrs(context.Background(), slog.LevelWarn, "Reference with that name already exists")
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Reference with that name already exists")
 		return backend.ErrDuplicateReference
 	}
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error inserting reference into database: %s", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting reference into database: %s", slog.String("error", err.Error()))
 		return err
 	}
 	return nil
 }
 
-func (p Provider) GetReference(id string) (types.Reference, error) {
-	row := p.Db.QueryRow(getReferenceQuery, id)
+func (p Provider) GetReference(ctx context.Context, id string) (types.Reference, error) {
+	row := p.Db.QueryRowContext(ctx, getReferenceQuery, id)
 	var ref types.Reference
-	err := row.Scan(&ref.ID, &ref.Name, &ref.Volume, &ref.Paragraph)
+	err := row.Scan(&ref.ID, &ref.Name, &ref.Volume, &ref.Paragraph, &ref.Version)
 	if err != nil && strings.Contains(err.Error(), "no rows in result set") {
-		p.logger.LogAttrs(context.Background(), slog.LevelWarn, "Unable to find reference with given ID")
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Unable to find reference with given ID")
 		return types.Reference{}, backend.ErrReferenceNotFound
 	}
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error getting reference from database", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting reference from database", slog.String("error", err.Error()))
 		return types.Reference{}, err
 	}
 	return ref, nil
 }
 
-func (p Provider) GetReferences() ([]types.Reference, error) {
-	rows, err := p.Db.Query(getReferencesQuery)
+func (p Provider) GetReferences(ctx context.Context) ([]types.Reference, error) {
+	rows, err := p.Db.QueryContext(ctx, getReferencesQuery)
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error getting references from database", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting references from database", slog.String("error", err.Error()))
 		return nil, err
 	}
 	var refs []types.Reference
 	var ref types.Reference
 	for rows.Next() {
-		err = rows.Scan(&ref.ID, &ref.Name, &ref.Volume, &ref.Paragraph)
+		err = rows.Scan(&ref.ID, &ref.Name, &ref.Volume, &ref.Paragraph, &ref.Version)
 		if err != nil {
-			p.logger.LogAttrs(context.Background(), slog.LevelError, "Error scanning reference into struct", slog.String("error", err.Error()))
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning reference into struct", slog.String("error", err.Error()))
 			return nil, err
 		}
 		refs = append(refs, ref)
@@ -56,24 +56,45 @@ func (p Provider) GetReferences() ([]types.Reference, error) {
 	return refs, nil
 }
 
-func (p Provider) UpdateReference(r types.Reference) error {
-	_, err := p.Db.Exec(updateReferenceQuery, r.Name, r.Volume, r.Paragraph, r.ID)
+func (p Provider) UpdateReference(ctx context.Context, r types.Reference) error {
+	res, err := p.Db.ExecContext(ctx, updateReferenceQuery, r.Name, r.Volume, r.Paragraph, r.ID, r.Version)
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error updating reference", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error updating reference", slog.String("error", err.Error()))
 		return err
 	}
+	// Updating a reference that doesn't exist isn't an error, but one changed since it was read is
+	if updated, _ := res.RowsAffected(); updated == 0 {
+		return p.missedUpdate(ctx, p.Db, r.Version, nil, getReferenceVersionQuery, r.ID)
+	}
 	return nil
 }
 
-func (p Provider) DeleteReference(id string) error {
-	res, err := p.Db.Exec(deleteReferenceQuery, id)
+// DeleteReference removes a reference, leaving the requirements citing it without one.
+func (p Provider) DeleteReference(ctx context.Context, id string) error {
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for DeleteReference", slog.String("error", err.Error()))
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, clearReferenceQuery, id); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error removing reference from requirements", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	res, err := tx.ExecContext(ctx, deleteReferenceQuery, id)
 	if err != nil {
-		p.logger.LogAttrs(context.Background(), slog.LevelError, "Error deleting reference from database", slog.String("error", err.Error()))
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error deleting reference from database", slog.String("error", err.Error()))
+		tx.Rollback()
 		return err
 	}
 	if updated, _ := res.RowsAffected(); updated != 1 {
-		p.logger.LogAttrs(context.Background(), slog.LevelWarn, "Didn't get expected 1 row updated, qualification mostly not found")
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Didn't get expected 1 row updated, qualification mostly not found")
+		tx.Rollback()
 		return backend.ErrReferenceNotFound
 	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
 	return nil
 }
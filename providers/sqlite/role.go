@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"PORTal/types"
+	"context"
+	"log/slog"
+)
+
+func (p Provider) GetRolePermissions(ctx context.Context, role types.Role) ([]types.Permission, error) {
+	rows, err := p.Db.QueryContext(ctx, getRolePermissionsQuery, role)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting permissions for role", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	permissions := []types.Permission{}
+	for rows.Next() {
+		var permission types.Permission
+		if err = rows.Scan(&permission); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning permission into string", slog.String("error", err.Error()))
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, nil
+}
+
+func (p Provider) GetRoles(ctx context.Context) ([]types.RoleDefinition, error) {
+	rows, err := p.Db.QueryContext(ctx, getAllRolePermissionsQuery)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting role permissions", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	permissions := map[types.Role][]types.Permission{}
+	for rows.Next() {
+		var role types.Role
+		var permission types.Permission
+		if err = rows.Scan(&role, &permission); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning role permission", slog.String("error", err.Error()))
+			return nil, err
+		}
+		permissions[role] = append(permissions[role], permission)
+	}
+	roles := make([]types.RoleDefinition, 0, len(types.Roles))
+	for _, role := range types.Roles {
+		rolePermissions := permissions[role]
+		if rolePermissions == nil {
+			rolePermissions = []types.Permission{}
+		}
+		roles = append(roles, types.RoleDefinition{Role: role, Permissions: rolePermissions})
+	}
+	return roles, nil
+}
+
+func (p Provider) SetRolePermissions(ctx context.Context, role types.Role, permissions []types.Permission) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Setting permissions for role", slog.String("role", string(role)), slog.Any("permissions", permissions))
+	tx, err := p.Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for SetRolePermissions", slog.String("error", err.Error()))
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, deleteRolePermissionsQuery, role); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error removing existing role permissions", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	for _, permission := range permissions {
+		if _, err = tx.ExecContext(ctx, insertRolePermissionQuery, role, permission); err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting role permission", slog.String("error", err.Error()))
+			tx.Rollback()
+			return err
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
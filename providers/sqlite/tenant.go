@@ -0,0 +1,98 @@
+package sqlite
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// AddTenant registers the tenant and, in the same transaction, seeds its roles and inserts its first admin so a tenant
+// never exists without someone who can manage it.
+func (p Provider) AddTenant(ctx context.Context, t types.Tenant, admin types.Member) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Adding tenant to database", slog.String("tenant_id", t.ID), slog.String("subdomain", t.Subdomain))
+	tx, err := p.ForTenant(t.ID).Db.BeginTx(ctx, nil)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error creating transaction for AddTenant", slog.String("error", err.Error()))
+		return err
+	}
+	_, err = tx.ExecContext(ctx, insertTenantQuery, t.ID, t.Name, nullString(t.Subdomain))
+	if err != nil && strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "Tenant subdomain is taken")
+		tx.Rollback()
+		return fmt.Errorf("%w: %s", backend.ErrDuplicateTenant, t.Subdomain)
+	} else if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting tenant", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if err = seedRolePermissions(ctx, tx); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error seeding tenant roles", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	_, err = tx.ExecContext(ctx, insertMemberQuery, admin.ID, admin.FirstName, admin.LastName, admin.Rank, admin.Username, nil, admin.Admin, admin.Role, admin.Hash,
+		nullString(admin.CertificateID), nullString(admin.Email))
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting tenant admin", slog.String("error", err.Error()))
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error committing transaction", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) GetTenant(ctx context.Context, id string) (types.Tenant, error) {
+	return p.getTenant(ctx, getTenantQuery, id)
+}
+
+func (p Provider) GetTenantBySubdomain(ctx context.Context, subdomain string) (types.Tenant, error) {
+	return p.getTenant(ctx, getTenantBySubdomainQuery, subdomain)
+}
+
+func (p Provider) getTenant(ctx context.Context, query, identifier string) (types.Tenant, error) {
+	t, err := scanTenant(p.Db.QueryRowContext(ctx, query, identifier))
+	if err != nil && strings.Contains(err.Error(), "no rows in result set") {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "No tenant found with given identifier", slog.String("identifier", identifier))
+		return types.Tenant{}, fmt.Errorf("%w: %s", backend.ErrTenantNotFound, identifier)
+	} else if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning tenant into struct", slog.String("error", err.Error()))
+		return types.Tenant{}, err
+	}
+	return t, nil
+}
+
+func (p Provider) GetTenants(ctx context.Context) ([]types.Tenant, error) {
+	rows, err := p.Db.QueryContext(ctx, getTenantsQuery)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting tenants", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	tenants := []types.Tenant{}
+	for rows.Next() {
+		t, err := scanTenant(rows)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning tenant into struct", slog.String("error", err.Error()))
+			return nil, err
+		}
+		tenants = append(tenants, t)
+	}
+	return tenants, nil
+}
+
+func scanTenant(s scanner) (types.Tenant, error) {
+	var t types.Tenant
+	var subdomain sql.NullString
+	if err := s.Scan(&t.ID, &t.Name, &subdomain); err != nil {
+		return types.Tenant{}, err
+	}
+	t.Subdomain = subdomain.String
+	return t, nil
+}
@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+func (p Provider) AddAPIToken(ctx context.Context, t types.APIToken) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Inserting api token into database", slog.Any("token", t))
+	_, err := p.Db.ExecContext(ctx, insertAPITokenQuery, t.ID, t.MemberID, t.Name, t.Scope, t.Hash, t.Created)
+	if err != nil && strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Member for api token doesn't exist", slog.String("member_id", t.MemberID))
+		return fmt.Errorf("%w: %s", backend.ErrMemberNotFound, t.MemberID)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting api token into database", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) GetAPITokenByHash(ctx context.Context, hash string) (types.APIToken, error) {
+	t, err := scanAPIToken(p.Db.QueryRowContext(ctx, getAPITokenByHashQuery, hash))
+	if err != nil && strings.Contains(err.Error(), "no rows in result set") {
+		p.logger.LogAttrs(ctx, slog.LevelInfo, "No api token found with given hash")
+		return types.APIToken{}, backend.ErrAPITokenNotFound
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning api token into struct", slog.String("error", err.Error()))
+		return types.APIToken{}, err
+	}
+	return t, nil
+}
+
+func (p Provider) GetAPITokens(ctx context.Context, memberID string) ([]types.APIToken, error) {
+	rows, err := p.Db.QueryContext(ctx, getAPITokensForMemberQuery, memberID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting api tokens for member", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	var tokens []types.APIToken
+	for rows.Next() {
+		t, err := scanAPIToken(rows)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning api token into struct", slog.String("error", err.Error()))
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, nil
+}
+
+func (p Provider) UpdateAPITokenLastUsed(ctx context.Context, id string, lastUsed time.Time) error {
+	res, err := p.Db.ExecContext(ctx, updateAPITokenLastUsedQuery, lastUsed, id)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error updating api token last used time", slog.String("error", err.Error()))
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		return backend.ErrAPITokenNotFound
+	}
+	return nil
+}
+
+func (p Provider) DeleteAPIToken(ctx context.Context, memberID, id string) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Deleting api token", slog.String("member_id", memberID), slog.String("token_id", id))
+	res, err := p.Db.ExecContext(ctx, deleteAPITokenQuery, id, memberID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error deleting api token", slog.String("error", err.Error()))
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "No api token with that ID exists for member")
+		return backend.ErrAPITokenNotFound
+	}
+	return nil
+}
+
+func scanAPIToken(s scanner) (types.APIToken, error) {
+	var t types.APIToken
+	var lastUsed sql.NullTime
+	if err := s.Scan(&t.ID, &t.MemberID, &t.Name, &t.Scope, &t.Hash, &t.Created, &lastUsed); err != nil {
+		return types.APIToken{}, err
+	}
+	if lastUsed.Valid {
+		t.LastUsed = &lastUsed.Time
+	}
+	return t, nil
+}
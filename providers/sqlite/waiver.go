@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"PORTal/backend"
+	"PORTal/types"
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+func (p Provider) AddWaiver(ctx context.Context, w types.Waiver) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Inserting waiver into database", slog.Any("waiver", w))
+	var memoName, memoType any
+	var memo []byte
+	if w.Memo != nil {
+		memoName, memoType, memo = w.Memo.Name, w.Memo.ContentType, w.Memo.Data
+	}
+	_, err := p.Db.ExecContext(ctx, insertWaiverQuery, w.ID, w.MemberID, nullString(w.RequirementID), nullString(w.QualificationID), w.Kind,
+		w.ApproverID, w.Reason, w.Start, w.End, w.Created, memoName, memoType, memo)
+	if err != nil && strings.Contains(err.Error(), "FOREIGN KEY constraint failed") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "Member, requirement or qualification for waiver doesn't exist")
+		if _, err = p.GetMember(ctx, w.MemberID, backend.ById); err != nil {
+			return err
+		}
+		if w.RequirementID != "" {
+			if _, err = p.GetRequirement(ctx, w.RequirementID); err != nil {
+				return err
+			}
+		}
+		if w.QualificationID != "" {
+			if _, err = p.GetQualification(ctx, w.QualificationID); err != nil {
+				return err
+			}
+		}
+		return fmt.Errorf("%w: approver_id=%s", backend.ErrMemberNotFound, w.ApproverID)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error inserting waiver into database", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (p Provider) GetWaiver(ctx context.Context, id string) (types.Waiver, error) {
+	w, err := scanWaiver(p.Db.QueryRowContext(ctx, getWaiverQuery, id))
+	if err != nil && strings.Contains(err.Error(), "no rows in result set") {
+		p.logger.LogAttrs(ctx, slog.LevelWarn, "No waiver found with given id", slog.String("waiver_id", id))
+		return types.Waiver{}, fmt.Errorf("%w: waiver_id=%s", backend.ErrWaiverNotFound, id)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning waiver into struct", slog.String("error", err.Error()))
+		return types.Waiver{}, err
+	}
+	return w, nil
+}
+
+func (p Provider) GetMemberWaivers(ctx context.Context, memberID string) ([]types.Waiver, error) {
+	rows, err := p.Db.QueryContext(ctx, getMemberWaiversQuery, memberID)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting waivers for member", slog.String("error", err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+	waivers := []types.Waiver{}
+	for rows.Next() {
+		w, err := scanWaiver(rows)
+		if err != nil {
+			p.logger.LogAttrs(ctx, slog.LevelError, "Error scanning waiver into struct", slog.String("error", err.Error()))
+			return nil, err
+		}
+		waivers = append(waivers, w)
+	}
+	return waivers, nil
+}
+
+func (p Provider) GetWaiverMemo(ctx context.Context, id string) (types.WaiverMemo, error) {
+	var name, contentType sql.NullString
+	var memo types.WaiverMemo
+	err := p.Db.QueryRowContext(ctx, getWaiverMemoQuery, id).Scan(&name, &contentType, &memo.Data)
+	if err != nil && strings.Contains(err.Error(), "no rows in result set") {
+		return types.WaiverMemo{}, fmt.Errorf("%w: waiver_id=%s", backend.ErrWaiverNotFound, id)
+	}
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error getting waiver memo", slog.String("error", err.Error()))
+		return types.WaiverMemo{}, err
+	}
+	if !name.Valid {
+		return types.WaiverMemo{}, fmt.Errorf("%w: waiver_id=%s", backend.ErrWaiverMemoNotFound, id)
+	}
+	memo.Name = name.String
+	memo.ContentType = contentType.String
+	return memo, nil
+}
+
+func (p Provider) UpdateWaiverEnd(ctx context.Context, id string, end time.Time) error {
+	p.logger.LogAttrs(ctx, slog.LevelInfo, "Updating waiver end date", slog.String("waiver_id", id), slog.Time("end", end))
+	res, err := p.Db.ExecContext(ctx, updateWaiverEndQuery, end, id)
+	if err != nil {
+		p.logger.LogAttrs(ctx, slog.LevelError, "Error updating waiver", slog.String("error", err.Error()))
+		return err
+	}
+	if count, _ := res.RowsAffected(); count != 1 {
+		return fmt.Errorf("%w: waiver_id=%s", backend.ErrWaiverNotFound, id)
+	}
+	return nil
+}
+
+func scanWaiver(s scanner) (types.Waiver, error) {
+	var w types.Waiver
+	var requirementID, qualificationID, approverID, memoName sql.NullString
+	err := s.Scan(&w.ID, &w.MemberID, &requirementID, &qualificationID, &w.Kind, &approverID, &w.Reason, &w.Start, &w.End,
+		&w.Created, &memoName)
+	if err != nil {
+		return types.Waiver{}, err
+	}
+	w.RequirementID = requirementID.String
+	w.QualificationID = qualificationID.String
+	w.ApproverID = approverID.String
+	w.MemoName = memoName.String
+	return w, nil
+}
@@ -0,0 +1,90 @@
+package testutils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// GenerateClientCertificate creates a throwaway CA and a client certificate signed by it. When upn is not empty it is
+// added to the subject alternative name as a user principal name, the way CACs carry the EDIPI.
+func GenerateClientCertificate(t *testing.T, commonName, upn string) (client *x509.Certificate, ca *x509.Certificate) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating CA key: %s", err.Error())
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "PORTal Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("Error creating CA certificate: %s", err.Error())
+	}
+	ca, err = x509.ParseCertificate(caDer)
+	if err != nil {
+		t.Fatalf("Error parsing CA certificate: %s", err.Error())
+	}
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Error generating client key: %s", err.Error())
+	}
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if upn != "" {
+		clientTemplate.ExtraExtensions = []pkix.Extension{{Id: asn1.ObjectIdentifier{2, 5, 29, 17}, Value: upnSubjectAltName(t, upn)}}
+	}
+	clientDer, err := x509.CreateCertificate(rand.Reader, clientTemplate, ca, &clientKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("Error creating client certificate: %s", err.Error())
+	}
+	client, err = x509.ParseCertificate(clientDer)
+	if err != nil {
+		t.Fatalf("Error parsing client certificate: %s", err.Error())
+	}
+	return client, ca
+}
+
+func upnSubjectAltName(t *testing.T, upn string) []byte {
+	t.Helper()
+	oid, err := asn1.Marshal(asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 20, 2, 3})
+	if err != nil {
+		t.Fatalf("Error marshalling UPN OID: %s", err.Error())
+	}
+	value, err := asn1.MarshalWithParams(upn, "utf8")
+	if err != nil {
+		t.Fatalf("Error marshalling UPN value: %s", err.Error())
+	}
+	explicitValue, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: value})
+	if err != nil {
+		t.Fatalf("Error marshalling UPN explicit value: %s", err.Error())
+	}
+	otherName, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: append(oid, explicitValue...)})
+	if err != nil {
+		t.Fatalf("Error marshalling other name: %s", err.Error())
+	}
+	san, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: otherName})
+	if err != nil {
+		t.Fatalf("Error marshalling subject alternative name: %s", err.Error())
+	}
+	return san
+}
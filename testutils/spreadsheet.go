@@ -0,0 +1,70 @@
+package testutils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// XLSX builds a minimal single sheet workbook. Cells that parse as numbers are stored as numbers, the way Excel stores
+// dates, and everything else goes in the shared strings table.
+func XLSX(t *testing.T, rows [][]string) []byte {
+	t.Helper()
+	var shared []string
+	var sheet strings.Builder
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8"?><worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, value := range row {
+			ref := fmt.Sprintf("%c%d", 'A'+j, i+1)
+			if value == "" {
+				continue
+			}
+			if _, err := strconv.ParseFloat(value, 64); err == nil {
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%s</v></c>`, ref, value)
+				continue
+			}
+			fmt.Fprintf(&sheet, `<c r="%s" t="s"><v>%d</v></c>`, ref, len(shared))
+			shared = append(shared, value)
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	var strs strings.Builder
+	strs.WriteString(`<?xml version="1.0" encoding="UTF-8"?><sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	for _, s := range shared {
+		strs.WriteString(`<si><t>`)
+		xml.EscapeText(&strs, []byte(s))
+		strs.WriteString(`</t></si>`)
+	}
+	strs.WriteString(`</sst>`)
+
+	files := map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?><workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="History" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/history.xml"/></Relationships>`,
+		"xl/worksheets/history.xml": sheet.String(),
+		"xl/sharedStrings.xml":      strs.String(),
+	}
+	buf := &bytes.Buffer{}
+	zw := zip.NewWriter(buf)
+	for name, content := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("Error creating %s in xlsx: %s", name, err.Error())
+		}
+		if _, err = f.Write([]byte(content)); err != nil {
+			t.Fatalf("Error writing %s in xlsx: %s", name, err.Error())
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("Error closing xlsx: %s", err.Error())
+	}
+	return buf.Bytes()
+}
@@ -6,6 +6,7 @@ import (
 	"golang.org/x/crypto/bcrypt"
 	"math/rand/v2"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"testing"
@@ -21,6 +22,10 @@ func RandomString() string {
 }
 
 func RandomMember(admin bool) types.Member {
+	role := types.RoleMember
+	if admin {
+		role = types.RoleAdmin
+	}
 	return types.Member{
 		ApiMember: types.ApiMember{
 			ID:           "",
@@ -30,6 +35,7 @@ func RandomMember(admin bool) types.Member {
 			Rank:         types.E4,
 			SupervisorID: "",
 			Admin:        admin,
+			Role:         role,
 		},
 		Password: RandomString(),
 		Hash:     "",
@@ -82,9 +88,6 @@ func VerifyUpdatedUser(original, updates, returned types.Member, t *testing.T) {
 	if updates.Rank != "" {
 		original.Rank = updates.Rank
 	}
-	if updates.Username != "" {
-		original.Username = updates.Username
-	}
 	if updates.SupervisorID != "" {
 		original.SupervisorID = updates.SupervisorID
 	}
@@ -93,6 +96,7 @@ func VerifyUpdatedUser(original, updates, returned types.Member, t *testing.T) {
 			t.Errorf("Updated password does not match returned hash")
 		}
 	}
+	original.Version = returned.Version
 	if !reflect.DeepEqual(original.ApiMember, returned.ApiMember) {
 		t.Errorf("Expected updated member: %+v\nGot: %+v", original, returned)
 	}
@@ -100,14 +104,19 @@ func VerifyUpdatedUser(original, updates, returned types.Member, t *testing.T) {
 
 func CompareQuals(got, wanted types.Qualification) bool {
 	// Pull out slices to sort later and set passed values to nil so reflect.DeepEqual and determine the rest of the fields.
-	gotInitialReqs := got.InitialRequirements
-	wantInitialReqs := wanted.InitialRequirements
-	gotRecurringReqs := got.RecurringRequirements
-	wantRecurringReqs := wanted.RecurringRequirements
+	gotInitialReqs := withoutVersions(got.InitialRequirements)
+	wantInitialReqs := withoutVersions(wanted.InitialRequirements)
+	gotRecurringReqs := withoutVersions(got.RecurringRequirements)
+	wantRecurringReqs := withoutVersions(wanted.RecurringRequirements)
+	got.Version, wanted.Version = 0, 0
 	got.InitialRequirements = nil
 	wanted.InitialRequirements = nil
 	got.RecurringRequirements = nil
 	wanted.RecurringRequirements = nil
+	got.Prerequisites = slices.Clone(got.Prerequisites)
+	wanted.Prerequisites = slices.Clone(wanted.Prerequisites)
+	sort.Strings(got.Prerequisites)
+	sort.Strings(wanted.Prerequisites)
 	sort.Slice(gotInitialReqs, func(i, j int) bool {
 		return gotInitialReqs[i].ID < gotInitialReqs[j].ID
 	})
@@ -140,8 +149,19 @@ func CompareRequirements(r1, r2 types.Requirement) bool {
 	if r1.DaysValidFor != r2.DaysValidFor {
 		return false
 	}
+	r1.Reference.Version, r2.Reference.Version = 0, 0
 	if r1.Reference != r2.Reference {
 		return false
 	}
 	return true
 }
+
+// withoutVersions copies the requirements without their versions, which go up with every update, so they can be
+// compared with the ones they were stored from.
+func withoutVersions(reqs []types.Requirement) []types.Requirement {
+	reqs = slices.Clone(reqs)
+	for i := range reqs {
+		reqs[i].Version, reqs[i].Reference.Version = 0, 0
+	}
+	return reqs
+}
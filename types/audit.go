@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+// AuditEntry records who changed what and when for actions that need a paper trail.
+type AuditEntry struct {
+	ID         string    `json:"id"`
+	ActorID    string    `json:"actor_id"`
+	Action     string    `json:"action"`
+	EntityType string    `json:"entity_type"`
+	EntityID   string    `json:"entity_id"`
+	Time       time.Time `json:"time"`
+	Details    string    `json:"details,omitempty"`
+}
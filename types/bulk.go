@@ -0,0 +1,30 @@
+package types
+
+// BulkQualificationRequest assigns or removes every listed qualification for every listed member and, when
+// SupervisorID is set, everyone below that supervisor in the chain.
+type BulkQualificationRequest struct {
+	MemberIDs        []string `json:"member_ids"`
+	SupervisorID     string   `json:"supervisor_id,omitempty"`
+	QualificationIDs []string `json:"qualification_ids"`
+}
+
+type MemberQualificationPair struct {
+	MemberID        string `json:"member_id"`
+	QualificationID string `json:"qualification_id"`
+}
+
+type BulkItemStatus string
+
+const (
+	BulkItemAssigned BulkItemStatus = "assigned"
+	BulkItemRemoved  BulkItemStatus = "removed"
+	// BulkItemSkipped is used when there was nothing to do, e.g. the qualification was already assigned
+	BulkItemSkipped BulkItemStatus = "skipped"
+	BulkItemFailed  BulkItemStatus = "failed"
+)
+
+type BulkItemResult struct {
+	MemberQualificationPair
+	Status BulkItemStatus `json:"status"`
+	Error  string         `json:"error,omitempty"`
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type CompletionStatus string
+
+const (
+	CompletionPending  CompletionStatus = "pending"
+	CompletionApproved CompletionStatus = "approved"
+	CompletionRejected CompletionStatus = "rejected"
+)
+
+// Completion is a record of a member completing a requirement. Completions start out pending and only count toward a
+// member's requirements once a designated certifier approves them.
+type Completion struct {
+	ID            string           `json:"id"`
+	MemberID      string           `json:"member_id"`
+	RequirementID string           `json:"requirement_id"`
+	TrainerID     string           `json:"trainer_id,omitempty"`
+	CertifierID   string           `json:"certifier_id,omitempty"`
+	SubmittedBy   string           `json:"submitted_by"`
+	Status        CompletionStatus `json:"status"`
+	CompletedDate time.Time        `json:"completed_date"`
+	Submitted     time.Time        `json:"submitted"`
+	Reviewed      time.Time        `json:"reviewed,omitempty"`
+	Comments      string           `json:"comments,omitempty"`
+}
+
+func (c Completion) LogValue() slog.Value {
+	return slog.StringValue(fmt.Sprintf("ID: %s MemberID: %s RequirementID: %s TrainerID: %s CertifierID: %s Status: %s",
+		c.ID, c.MemberID, c.RequirementID, c.TrainerID, c.CertifierID, c.Status))
+}
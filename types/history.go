@@ -0,0 +1,58 @@
+package types
+
+import "time"
+
+// ImportProfile maps the headers of a legacy training spreadsheet to the fields PORTal needs, so the same export can
+// be imported repeatedly without remapping it.
+type ImportProfile struct {
+	ID                string `json:"id"`
+	Name              string `json:"name"`
+	MemberColumn      string `json:"member_column"`
+	RequirementColumn string `json:"requirement_column"`
+	DateColumn        string `json:"date_column"`
+	// DateFormat is a Go time layout, defaulting to 2006-01-02. Excel date cells are always understood.
+	DateFormat string `json:"date_format,omitempty"`
+}
+
+type ImportBatchStatus string
+
+const (
+	ImportBatchStaged    ImportBatchStatus = "staged"
+	ImportBatchCommitted ImportBatchStatus = "committed"
+)
+
+// ImportBatch is a spreadsheet of historical completions staged for review. Nothing is written as a completion until
+// the batch is committed.
+type ImportBatch struct {
+	ID         string            `json:"id"`
+	ProfileID  string            `json:"profile_id"`
+	FileName   string            `json:"file_name"`
+	UploadedBy string            `json:"uploaded_by"`
+	Uploaded   time.Time         `json:"uploaded"`
+	Status     ImportBatchStatus `json:"status"`
+	Rows       []StagedRow       `json:"rows"`
+}
+
+// StagedRow is one spreadsheet row of an ImportBatch. Rows with errors have to be corrected or excluded before the
+// batch can be committed.
+type StagedRow struct {
+	ID               string `json:"id"`
+	Line             int    `json:"line"`
+	MemberIdentifier string `json:"member_identifier"`
+	MemberID         string `json:"member_id,omitempty"`
+	RequirementName  string `json:"requirement_name"`
+	RequirementID    string `json:"requirement_id,omitempty"`
+	// MatchScore is how closely RequirementName matched the chosen requirement, 1 being an exact match
+	MatchScore    float64   `json:"match_score"`
+	CompletedDate time.Time `json:"completed_date"`
+	Excluded      bool      `json:"excluded"`
+	Errors        []string  `json:"errors,omitempty"`
+}
+
+// StagedRowUpdate is a reviewer's correction to a StagedRow. Empty fields are left as they are.
+type StagedRowUpdate struct {
+	MemberID      string    `json:"member_id,omitempty"`
+	RequirementID string    `json:"requirement_id,omitempty"`
+	CompletedDate time.Time `json:"completed_date,omitempty"`
+	Excluded      *bool     `json:"excluded,omitempty"`
+}
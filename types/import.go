@@ -0,0 +1,27 @@
+package types
+
+// MemberImportReport is the outcome of a roster import, one row per CSV record.
+type MemberImportReport struct {
+	DryRun    bool              `json:"dry_run"`
+	Committed bool              `json:"committed"`
+	Rows      []MemberImportRow `json:"rows"`
+}
+
+// Valid reports whether every row passed validation.
+func (r MemberImportReport) Valid() bool {
+	for _, row := range r.Rows {
+		if len(row.Errors) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+type MemberImportRow struct {
+	Line     int    `json:"line"`
+	Username string `json:"username"`
+	// MemberID and TemporaryPassword are only set once the import is committed
+	MemberID          string   `json:"member_id,omitempty"`
+	TemporaryPassword string   `json:"temporary_password,omitempty"`
+	Errors            []string `json:"errors,omitempty"`
+}
@@ -0,0 +1,130 @@
+package types
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SortKey is what a list is ordered by. Each kind of item supports its own keys, see Sortable.
+type SortKey string
+
+const (
+	SortByName     SortKey = "name"
+	SortByLastName SortKey = "last_name"
+	SortByRank     SortKey = "rank"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
+// ListOptions pages through a list. Pages start after the cursor of the last item on the previous page rather than at
+// an offset, so items added or removed between requests don't shift others onto the wrong page.
+type ListOptions struct {
+	Sort       SortKey
+	Descending bool
+	// Limit is the most items on a page, DefaultPageSize when zero
+	Limit int
+	// Cursor is the previous page's NextCursor, empty for the first page
+	Cursor string
+}
+
+// ArchiveFilter filters members on whether they're archived.
+type ArchiveFilter string
+
+const (
+	MemberActive     ArchiveFilter = "false"
+	MemberArchived   ArchiveFilter = "true"
+	MemberAnyArchive ArchiveFilter = "any"
+)
+
+// MemberListOptions narrows the members listed. Filters left empty match every member.
+type MemberListOptions struct {
+	ListOptions
+	Rank         Rank
+	SupervisorID string
+	Admin        *bool
+	// QualificationID only lists members holding the qualification
+	QualificationID string
+	// UnitIDs only lists members of any of the units
+	UnitIDs []string
+	// Archived is MemberActive when empty
+	Archived ArchiveFilter
+}
+
+// Page is one page of a list.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// Total counts the items on every page
+	Total int `json:"total"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// Sortable is anything listed in pages.
+type Sortable interface {
+	// SortValues are what the item is ordered by under the key, ending in its ID so no two items tie. They're nil if it
+	// can't be sorted by the key.
+	SortValues(key SortKey) []any
+}
+
+// NewPage makes a page out of the items fetched for it. Fetching one more item than the limit tells whether there's a
+// next page.
+func NewPage[T Sortable](items []T, total int, opts ListOptions) Page[T] {
+	page := Page[T]{Items: items, Total: total}
+	if len(items) > opts.Limit {
+		page.Items = items[:opts.Limit]
+		page.NextCursor = EncodeCursor(page.Items[opts.Limit-1].SortValues(opts.Sort))
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	return page
+}
+
+var errMalformedCursor = errors.New("malformed cursor")
+
+// EncodeCursor turns an item's sort values into an opaque cursor.
+func EncodeCursor(values []any) string {
+	b, _ := json.Marshal(values)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor returns the sort values in the cursor, checking they're the same kind as like's. An empty cursor has
+// none.
+func DecodeCursor(cursor string, like []any) ([]any, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errMalformedCursor
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var values []any
+	if err = d.Decode(&values); err != nil || len(values) != len(like) {
+		return nil, errMalformedCursor
+	}
+	for i, v := range values {
+		switch like[i].(type) {
+		case string:
+			if _, ok := v.(string); !ok {
+				return nil, fmt.Errorf("%w: value %d isn't a string", errMalformedCursor, i)
+			}
+		case int64:
+			n, ok := v.(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("%w: value %d isn't a number", errMalformedCursor, i)
+			}
+			if values[i], err = n.Int64(); err != nil {
+				return nil, fmt.Errorf("%w: value %d isn't an integer", errMalformedCursor, i)
+			}
+		}
+	}
+	return values, nil
+}
@@ -3,6 +3,8 @@ package types
 import (
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -20,6 +22,26 @@ const (
 	E9 Rank = "CMSgt"
 )
 
+// Ranks lists every rank in order of seniority, E1 first.
+var Ranks = []Rank{E1, E2, E3, E4, E5, E6, E7, E8, E9}
+
+// ParseRank accepts either the abbreviation ("SSgt", case-insensitive) or the pay grade ("E5" or "E-5").
+func ParseRank(s string) (Rank, bool) {
+	s = strings.TrimSpace(s)
+	for i, r := range Ranks {
+		grade := fmt.Sprintf("E%d", i+1)
+		if strings.EqualFold(s, string(r)) || strings.EqualFold(s, grade) || strings.EqualFold(s, fmt.Sprintf("E-%d", i+1)) {
+			return r, true
+		}
+	}
+	return "", false
+}
+
+// Grade is the rank's pay grade, 5 for E-5, or 0 if it isn't one of Ranks.
+func (r Rank) Grade() int {
+	return slices.Index(Ranks, r) + 1
+}
+
 type Member struct {
 	ApiMember
 	Password string `json:"password,omitempty"`
@@ -27,13 +49,26 @@ type Member struct {
 }
 
 func (m Member) LogValue() slog.Value {
-	return slog.StringValue(fmt.Sprintf("ID: %s Member: %s %s %s Username: %s Supervisor ID: %s Admin: %t", m.ID, m.Rank, m.FirstName, m.LastName, m.Username, m.SupervisorID, m.Admin))
+	return slog.StringValue(fmt.Sprintf("ID: %s Member: %s %s %s Username: %s Supervisor ID: %s Admin: %t Role: %s", m.ID, m.Rank, m.FirstName, m.LastName, m.Username, m.SupervisorID, m.Admin, m.Role))
+}
+
+// SortValues orders members by last name, or by rank and then last name. Ranks sort by seniority, not alphabetically.
+func (m Member) SortValues(key SortKey) []any {
+	switch key {
+	case SortByLastName:
+		return []any{m.LastName, m.FirstName, m.ID}
+	case SortByRank:
+		return []any{int64(m.Rank.Grade()), m.LastName, m.FirstName, m.ID}
+	}
+	return nil
 }
 
 func (m Member) ToApiMember() ApiMember {
 	return m.ApiMember
 }
 
+// MergeIn applies the non-empty fields of new to m. Usernames can't be changed once a member is created, so new's is
+// ignored, the same as in a PATCH.
 func (m Member) MergeIn(new Member) Member {
 	if new.FirstName != "" {
 		m.FirstName = new.FirstName
@@ -47,8 +82,12 @@ func (m Member) MergeIn(new Member) Member {
 	if new.SupervisorID != "" {
 		m.SupervisorID = new.SupervisorID
 	}
-	if new.Username != "" {
-		m.Username = new.Username
+	if new.Role != "" {
+		m.Role = new.Role
+		m.Admin = new.Role == RoleAdmin
+	}
+	if new.Email != "" {
+		m.Email = new.Email
 	}
 	if new.Password != "" {
 		m.Password = new.Password
@@ -57,13 +96,30 @@ func (m Member) MergeIn(new Member) Member {
 }
 
 type ApiMember struct {
-	ID           string `json:"id"`
-	FirstName    string `json:"first_name"`
-	LastName     string `json:"last_name"`
-	Username     string `json:"username"`
-	Rank         Rank   `json:"rank"`
-	SupervisorID string `json:"supervisor_id"`
-	Admin        bool   `json:"admin"`
+	ID            string `json:"id"`
+	FirstName     string `json:"first_name"`
+	LastName      string `json:"last_name"`
+	Username      string `json:"username"`
+	Email         string `json:"email,omitempty"`
+	Rank          Rank   `json:"rank"`
+	SupervisorID  string `json:"supervisor_id"`
+	UnitID        string `json:"unit_id,omitempty"`
+	Admin         bool   `json:"admin"`
+	Role          Role   `json:"role"`
+	CertificateID string `json:"certificate_id,omitempty"`
+	// Archive is set while the member is archived, nil for active members.
+	Archive *MemberArchive `json:"archive,omitempty"`
+	// Version goes up with every change to the member. Updates carrying a version are only applied if nobody changed the
+	// member since, zero skips the check.
+	Version int `json:"version,omitempty"`
+}
+
+// MemberArchive records why and when a member who PCSed or separated was archived. Archived members keep their training
+// records but don't appear on rosters and can't log in.
+type MemberArchive struct {
+	Reason     string    `json:"reason"`
+	Date       time.Time `json:"date"`
+	ArchivedBy string    `json:"archived_by,omitempty"`
 }
 
 type Session struct {
@@ -0,0 +1,48 @@
+package types
+
+// DutyPosition bundles the qualifications everyone working the position needs.
+type DutyPosition struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	Description    string   `json:"description,omitempty"`
+	Qualifications []string `json:"qualifications"`
+	// Version goes up with every change, see ApiMember.Version.
+	Version int `json:"version,omitempty"`
+}
+
+func (p DutyPosition) MergeIn(incoming DutyPosition) DutyPosition {
+	if incoming.Name != "" {
+		p.Name = incoming.Name
+	}
+	if incoming.Description != "" {
+		p.Description = incoming.Description
+	}
+	if incoming.Qualifications != nil {
+		p.Qualifications = incoming.Qualifications
+	}
+	return p
+}
+
+// DutyPositionDiff describes what changing a position's bundle does to the members holding it.
+type DutyPositionDiff struct {
+	PositionID            string                    `json:"position_id"`
+	AddedQualifications   []string                  `json:"added_qualifications"`
+	RemovedQualifications []string                  `json:"removed_qualifications"`
+	Members               []MemberQualificationDiff `json:"members"`
+	Applied               bool                      `json:"applied"`
+	UpdatedPosition       DutyPosition              `json:"position"`
+}
+
+// MemberQualificationDiff is the set of qualifications assigned to and removed from a single member.
+type MemberQualificationDiff struct {
+	MemberID string   `json:"member_id"`
+	Assign   []string `json:"assign"`
+	Remove   []string `json:"remove"`
+}
+
+// DutyPositionRemoval reports the qualifications that came with a position when a member leaves it. Removable
+// qualifications aren't provided by any other position the member still holds.
+type DutyPositionRemoval struct {
+	RemovableQualifications []string `json:"removable_qualifications"`
+	RemovedQualifications   []string `json:"removed_qualifications"`
+}
@@ -30,6 +30,18 @@ type Qualification struct {
 	Notes                 string        `json:"notes,omitempty"`
 	Expires               bool          `json:"expires"`
 	ExpirationDays        int           `json:"expiration_days,omitempty"`
+	// Prerequisites are the IDs of the qualifications a member must hold before this one counts.
+	Prerequisites []string `json:"prerequisites,omitempty"`
+	// Version goes up with every change, see ApiMember.Version.
+	Version int `json:"version,omitempty"`
+}
+
+// SortValues orders qualifications by name.
+func (q Qualification) SortValues(key SortKey) []any {
+	if key != SortByName {
+		return nil
+	}
+	return []any{q.Name, q.ID}
 }
 
 func (q Qualification) MergeIn(incoming Qualification, forceUpdateExpiration bool) Qualification {
@@ -45,6 +57,9 @@ func (q Qualification) MergeIn(incoming Qualification, forceUpdateExpiration boo
 	if incoming.Notes != "" {
 		q.Notes = incoming.Notes
 	}
+	if incoming.Prerequisites != nil {
+		q.Prerequisites = incoming.Prerequisites
+	}
 	if incoming.Expires == false && incoming.ExpirationDays == 0 && forceUpdateExpiration {
 		q.Expires = false
 		q.ExpirationDays = 0
@@ -64,12 +79,22 @@ type Requirement struct {
 	Description  string    `json:"description"`
 	Notes        string    `json:"notes,omitempty"`
 	DaysValidFor int       `json:"days_valid_for,omitempty"`
+	// Version goes up with every change, see ApiMember.Version.
+	Version int `json:"version,omitempty"`
 }
 
 func (r Requirement) LogValue() slog.Value {
 	return slog.StringValue(fmt.Sprintf("ID: %s Name: %s Description: %s Notes: %s DaysValidFor: %d", r.ID, r.Name, r.Description, r.Notes, r.DaysValidFor))
 }
 
+// SortValues orders requirements by name.
+func (r Requirement) SortValues(key SortKey) []any {
+	if key != SortByName {
+		return nil
+	}
+	return []any{r.Name, r.ID}
+}
+
 func (r Requirement) MergeIn(incoming Requirement) Requirement {
 	if incoming.Name != "" {
 		r.Name = incoming.Name
@@ -101,6 +126,8 @@ type Reference struct {
 	Name      string `json:"name"`
 	Volume    int    `json:"volume"`
 	Paragraph string `json:"paragraph"`
+	// Version goes up with every change, see ApiMember.Version.
+	Version int `json:"version,omitempty"`
 }
 
 func (r Reference) LogValue() slog.Value {
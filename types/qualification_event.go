@@ -0,0 +1,25 @@
+package types
+
+import "time"
+
+type QualificationEventKind string
+
+const (
+	QualificationAssigned    QualificationEventKind = "assigned"
+	QualificationRemoved     QualificationEventKind = "removed"
+	QualificationQualified   QualificationEventKind = "qualified"
+	QualificationLapsed      QualificationEventKind = "lapsed"
+	QualificationRequalified QualificationEventKind = "requalified"
+)
+
+// QualificationEvent is an entry in a member's history with a qualification. Events are never deleted, so the history
+// outlives the qualification being removed from the member. A state change that hasn't been recorded yet has no ID.
+type QualificationEvent struct {
+	ID              string                 `json:"id"`
+	MemberID        string                 `json:"member_id"`
+	QualificationID string                 `json:"qualification_id"`
+	Kind            QualificationEventKind `json:"kind"`
+	// ActorID is who assigned or removed the qualification. State changes worked out by PORTal don't have one.
+	ActorID string    `json:"actor_id,omitempty"`
+	Time    time.Time `json:"time"`
+}
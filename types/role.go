@@ -0,0 +1,62 @@
+package types
+
+import "slices"
+
+type Role string
+
+const (
+	RoleAdmin           Role = "admin"
+	RoleTrainingManager Role = "training_manager"
+	RoleSupervisor      Role = "supervisor"
+	RoleTrainer         Role = "trainer"
+	RoleMember          Role = "member"
+)
+
+var Roles = []Role{RoleAdmin, RoleTrainingManager, RoleSupervisor, RoleTrainer, RoleMember}
+
+func (r Role) Valid() bool {
+	return slices.Contains(Roles, r)
+}
+
+type Permission string
+
+const (
+	PermManageMembers        Permission = "members:manage"
+	PermDeleteMembers        Permission = "members:delete"
+	PermManageQualifications Permission = "qualifications:manage"
+	PermAssignQualifications Permission = "qualifications:assign"
+	PermSignOffCompletions   Permission = "completions:sign_off"
+	PermGrantWaivers         Permission = "waivers:grant"
+	PermViewReports          Permission = "reports:view"
+	PermManageRoles          Permission = "roles:manage"
+)
+
+var Permissions = []Permission{
+	PermManageMembers,
+	PermDeleteMembers,
+	PermManageQualifications,
+	PermAssignQualifications,
+	PermSignOffCompletions,
+	PermGrantWaivers,
+	PermViewReports,
+	PermManageRoles,
+}
+
+func (p Permission) Valid() bool {
+	return slices.Contains(Permissions, p)
+}
+
+// DefaultRolePermissions is the permission matrix seeded into a new database. Admins may change every role except
+// their own afterward.
+var DefaultRolePermissions = map[Role][]Permission{
+	RoleAdmin:           Permissions,
+	RoleTrainingManager: {PermManageMembers, PermManageQualifications, PermAssignQualifications, PermSignOffCompletions, PermGrantWaivers, PermViewReports},
+	RoleSupervisor:      {PermAssignQualifications, PermViewReports},
+	RoleTrainer:         {PermSignOffCompletions},
+	RoleMember:          {},
+}
+
+type RoleDefinition struct {
+	Role        Role         `json:"role"`
+	Permissions []Permission `json:"permissions"`
+}
@@ -0,0 +1,34 @@
+package types
+
+import "time"
+
+type QualificationState string
+
+const (
+	// StateQualified means every requirement is complete and current and every prerequisite is held.
+	StateQualified QualificationState = "qualified"
+	// StateInTraining means at least one initial requirement hasn't been completed yet.
+	StateInTraining QualificationState = "in_training"
+	// StateLapsed means the qualification expired or a recurring requirement wasn't completed in time.
+	StateLapsed QualificationState = "lapsed"
+	// StatePrerequisiteLapsed means the member would be qualified, but a prerequisite qualification isn't held.
+	StatePrerequisiteLapsed QualificationState = "prerequisite_lapsed"
+)
+
+// QualificationStatus is the evaluated state of a qualification for a single member.
+type QualificationStatus struct {
+	MemberID        string             `json:"member_id"`
+	QualificationID string             `json:"qualification_id"`
+	State           QualificationState `json:"state"`
+	// Expires is when the member stops being qualified unless they complete something, zero if never.
+	Expires             time.Time `json:"expires,omitempty"`
+	MissingRequirements []string  `json:"missing_requirements,omitempty"`
+	LapsedRequirements  []string  `json:"lapsed_requirements,omitempty"`
+	LapsedPrerequisites []string  `json:"lapsed_prerequisites,omitempty"`
+	// Waived is set when the member only counts as qualified because a waiver covers the whole qualification.
+	Waived bool `json:"waived,omitempty"`
+	// WaivedRequirements are requirements treated as satisfied because a waiver covers them.
+	WaivedRequirements []string `json:"waived_requirements,omitempty"`
+	// Waivers are the IDs of every waiver the status depends on.
+	Waivers []string `json:"waivers,omitempty"`
+}
@@ -0,0 +1,23 @@
+package types
+
+import "regexp"
+
+// DefaultTenantID is the tenant everything belongs to in single tenant deployments, and in multi-tenant deployments
+// when a request doesn't name a tenant.
+const DefaultTenantID = "default"
+
+var subdomainPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Tenant is an organization hosted on a shared deployment. Its members, qualifications and everything else they own
+// are invisible to every other tenant.
+type Tenant struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Subdomain is the label the tenant is reached at under the deployment's domain, e.g. "vr62" for vr62.example.com.
+	Subdomain string `json:"subdomain"`
+}
+
+// ValidSubdomain reports whether the tenant's subdomain is a single lowercase DNS label.
+func (t Tenant) ValidSubdomain() bool {
+	return subdomainPattern.MatchString(t.Subdomain)
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type TokenScope string
+
+const (
+	ScopeReadOnly  TokenScope = "read-only"
+	ScopeReadWrite TokenScope = "read-write"
+	ScopeAdmin     TokenScope = "admin"
+)
+
+func (s TokenScope) Valid() bool {
+	return s == ScopeReadOnly || s == ScopeReadWrite || s == ScopeAdmin
+}
+
+// ScopePermissions is the most a token of each scope may do, whatever its member's role grants. Read-write tokens are
+// for keeping training records in sync, so administering or deleting members and changing roles needs an admin token.
+var ScopePermissions = map[TokenScope][]Permission{
+	ScopeReadOnly:  {PermViewReports},
+	ScopeReadWrite: {PermManageQualifications, PermAssignQualifications, PermSignOffCompletions, PermGrantWaivers, PermViewReports},
+	ScopeAdmin:     Permissions,
+}
+
+// APIToken is a long-lived credential a member can use in place of the identity cookie. Only the hash is stored,
+// Token is populated once when the token is created.
+type APIToken struct {
+	ID       string     `json:"id"`
+	MemberID string     `json:"member_id"`
+	Name     string     `json:"name"`
+	Scope    TokenScope `json:"scope"`
+	Token    string     `json:"token,omitempty"`
+	Hash     string     `json:"-"`
+	Created  time.Time  `json:"created"`
+	// LastUsed is nil until the token is first used.
+	LastUsed *time.Time `json:"last_used,omitempty"`
+}
+
+func (t APIToken) LogValue() slog.Value {
+	return slog.StringValue(fmt.Sprintf("ID: %s MemberID: %s Name: %s Scope: %s", t.ID, t.MemberID, t.Name, t.Scope))
+}
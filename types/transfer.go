@@ -0,0 +1,66 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TransferPackageVersion is bumped whenever the layout of TransferPackage changes incompatibly.
+const TransferPackageVersion = 2
+
+// TransferPackage carries a member's training records from the instance they're leaving to the one they're joining.
+// Requirements are included with their references so the gaining instance can match or create the definitions. IDs are
+// the issuer's, the gaining instance mints its own.
+type TransferPackage struct {
+	Version int `json:"version"`
+	// ID is new for every export, a package can only be imported once.
+	ID       string    `json:"id"`
+	Issuer   string    `json:"issuer"`
+	Exported time.Time `json:"exported"`
+	Expires  time.Time `json:"expires"`
+	Member   ApiMember `json:"member"`
+	// MemberIDs are the member's IDs at the instances they were transferred to the issuer from, by instance name, so an
+	// instance they come back to can find their archived record.
+	MemberIDs      map[string]string    `json:"member_ids"`
+	Qualifications []Qualification      `json:"qualifications"`
+	Requirements   []Requirement        `json:"requirements"`
+	Completions    []Completion         `json:"completions"`
+	History        []QualificationEvent `json:"history"`
+}
+
+// SignedTransferPackage is a TransferPackage exactly as it was signed. Signature is the hex encoded HMAC-SHA256 of
+// Package using the issuer's transfer key.
+type SignedTransferPackage struct {
+	Package   json.RawMessage `json:"package"`
+	Signature string          `json:"signature"`
+}
+
+// TransferImport is a verified package with every ID resolved to the gaining instance's definitions. Returning is set
+// when the member already has an archived record to restore rather than being new. The package is recorded under its
+// ID, along with the member's ID at the issuer, so it can't be imported twice.
+type TransferImport struct {
+	PackageID        string
+	Issuer           string
+	IssuerMemberID   string
+	Member           Member
+	Returning        bool
+	QualificationIDs []string
+	Completions      []Completion
+	History          []QualificationEvent
+}
+
+// TransferImportReport describes what importing a package did. Definitions are listed by name.
+type TransferImportReport struct {
+	Issuer    string    `json:"issuer"`
+	Member    ApiMember `json:"member"`
+	Returning bool      `json:"returning"`
+	// TemporaryPassword is only set for new members, returning members keep their old password.
+	TemporaryPassword      string   `json:"temporary_password,omitempty"`
+	CreatedReferences      []string `json:"created_references"`
+	MatchedRequirements    []string `json:"matched_requirements"`
+	CreatedRequirements    []string `json:"created_requirements"`
+	MatchedQualifications  []string `json:"matched_qualifications"`
+	CreatedQualifications  []string `json:"created_qualifications"`
+	ImportedCompletions    int      `json:"imported_completions"`
+	ImportedHistoryEntries int      `json:"imported_history_entries"`
+}
@@ -0,0 +1,67 @@
+package types
+
+import "slices"
+
+type UnitKind string
+
+const (
+	UnitSquadron UnitKind = "squadron"
+	UnitFlight   UnitKind = "flight"
+	UnitSection  UnitKind = "section"
+)
+
+// UnitKinds are ordered from the top of the tree down. Each kind of unit sits directly under the kind before it.
+var UnitKinds = []UnitKind{UnitSquadron, UnitFlight, UnitSection}
+
+func (k UnitKind) Valid() bool {
+	return slices.Contains(UnitKinds, k)
+}
+
+// ParentKind is the kind of unit this kind sits under, empty for squadrons.
+func (k UnitKind) ParentKind() UnitKind {
+	i := slices.Index(UnitKinds, k)
+	if i < 1 {
+		return ""
+	}
+	return UnitKinds[i-1]
+}
+
+// Unit is a squadron, flight or section. Members belong to at most one unit and are also part of every unit above it.
+type Unit struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Kind     UnitKind `json:"kind"`
+	ParentID string   `json:"parent_id,omitempty"`
+	// MandatoryQualifications are the IDs of qualifications every member of the unit, including its subunits, must hold.
+	MandatoryQualifications []string `json:"mandatory_qualifications"`
+	// Version goes up with every change, see ApiMember.Version.
+	Version int `json:"version,omitempty"`
+}
+
+func (u Unit) MergeIn(incoming Unit) Unit {
+	if incoming.Name != "" {
+		u.Name = incoming.Name
+	}
+	if incoming.ParentID != "" {
+		u.ParentID = incoming.ParentID
+	}
+	if incoming.MandatoryQualifications != nil {
+		u.MandatoryQualifications = incoming.MandatoryQualifications
+	}
+	return u
+}
+
+// UnitReport is how every member of a unit and its subunits stands against the qualifications mandatory for them.
+type UnitReport struct {
+	UnitID  string             `json:"unit_id"`
+	Members []UnitMemberReport `json:"members"`
+}
+
+// UnitMemberReport evaluates each qualification mandatory for the member's unit or any unit above it. Unassigned lists
+// the mandatory qualifications the member hasn't been assigned at all.
+type UnitMemberReport struct {
+	MemberID   string                `json:"member_id"`
+	UnitID     string                `json:"unit_id"`
+	Statuses   []QualificationStatus `json:"statuses"`
+	Unassigned []string              `json:"unassigned"`
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type WaiverKind string
+
+const (
+	// WaiverKindWaiver excuses a member from a requirement or qualification outright, e.g. for a medical profile.
+	WaiverKindWaiver WaiverKind = "waiver"
+	// WaiverKindExtension gives a member a grace period to get current, e.g. while deployed.
+	WaiverKindExtension WaiverKind = "extension"
+)
+
+func (k WaiverKind) Valid() bool {
+	return k == WaiverKindWaiver || k == WaiverKindExtension
+}
+
+// Waiver treats a member's requirement or qualification as satisfied between Start and End. Exactly one of
+// RequirementID and QualificationID is set.
+type Waiver struct {
+	ID              string     `json:"id"`
+	MemberID        string     `json:"member_id"`
+	RequirementID   string     `json:"requirement_id,omitempty"`
+	QualificationID string     `json:"qualification_id,omitempty"`
+	Kind            WaiverKind `json:"kind"`
+	ApproverID      string     `json:"approver_id"`
+	Reason          string     `json:"reason"`
+	Start           time.Time  `json:"start"`
+	End             time.Time  `json:"end"`
+	Created         time.Time  `json:"created"`
+	// Memo is only populated when creating a waiver, it's fetched separately afterward.
+	Memo *WaiverMemo `json:"memo,omitempty"`
+	// MemoName is the file name of the attached memo, empty if there isn't one.
+	MemoName string `json:"memo_name,omitempty"`
+}
+
+func (w Waiver) Active(now time.Time) bool {
+	return !now.Before(w.Start) && now.Before(w.End)
+}
+
+func (w Waiver) LogValue() slog.Value {
+	return slog.StringValue(fmt.Sprintf("ID: %s MemberID: %s RequirementID: %s QualificationID: %s Kind: %s Start: %s End: %s",
+		w.ID, w.MemberID, w.RequirementID, w.QualificationID, w.Kind, w.Start.Format(time.DateOnly), w.End.Format(time.DateOnly)))
+}
+
+// WaiverMemo is the signed memo documenting a waiver.
+type WaiverMemo struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"data"`
+}
@@ -112,7 +112,8 @@ async function addMember(m: Member, setAddedMember: React.Dispatch<React.SetStat
         body: JSON.stringify(m)
     })
     if (res.status !== 201) {
-        console.log("it failed")
+        const problem = await res.json() as Problem
+        console.log(`Unable to add member: ${problem.detail ?? problem.title}`)
         return
     }
     const memberJson = await res.json() as Member
@@ -1,7 +1,7 @@
 import { useState, useEffect } from "react";
 import AdminMemberEditor from "./AdminMemberEditor";
 import AdminMemberList from "./AdminMemberList";
-import { Member } from "../..";
+import { Member, Page } from "../..";
 import { getEmptyMember, getBaseUrl } from "../../lib/utils";
 
 
@@ -13,14 +13,22 @@ export default function AdminMemberPane() {
 
     useEffect(() => {
         const fetchMembers = async () => {
-            const res = await fetch(`${getBaseUrl()}/api/members`, {
-                credentials: "include",
-            })
-            if (!res.ok) {
-                console.error("error getting members")
+            // The list is paged, follow the cursors until every member is loaded
+            const all: Member[] = []
+            let cursor: string | undefined = ""
+            while (cursor !== undefined) {
+                const res = await fetch(`${getBaseUrl()}/api/members?limit=200&cursor=${encodeURIComponent(cursor)}`, {
+                    credentials: "include",
+                })
+                if (!res.ok) {
+                    console.error("error getting members")
+                    return
+                }
+                const page: Page<Member> = await res.json()
+                all.push(...page.items)
+                cursor = page.next_cursor
             }
-            const membersJson = await res.json()
-            setMembers(membersJson)
+            setMembers(all)
         }
         fetchMembers()
     }, [addedMember])
@@ -15,6 +15,21 @@ interface Member {
     admin: boolean
     username: string
     supervisor_id: string
+    version?: number
+}
+
+interface Problem {
+    code: string
+    title: string
+    status: number
+    detail?: string
+    fields?: string[]
+}
+
+interface Page<T> {
+    items: T[]
+    total: number
+    next_cursor?: string
 }
 
 interface Qualification {
@@ -25,6 +40,7 @@ interface Qualification {
     notes: string
     expires: bool
     expiration_days: number
+    version?: number
 }
 
 interface Requirement {
@@ -34,6 +50,7 @@ interface Requirement {
     description: string
     notes: string
     days_valid_for: number
+    version?: number
 }
 
 interface Reference {
@@ -41,4 +58,5 @@ interface Reference {
     name: string
     volume: number
     paragraph: string
+    version?: number
 }
\ No newline at end of file